@@ -33,6 +33,26 @@ var (
 	ProxiesPortRangeTo   = flag.Int("proxies-port-to", 60250, "last tcp port in a range of binding reverse proxies for service apps")
 	pprofListenStr       = flag.String("pprofListenStr", "",
 		"pprof listen str host:port")
+	helmRepositories = flag.String("helm-repositories", "",
+		"comma separated name=url pairs of chart repositories that replace default ones, e.g. stable=http://charts.local")
+	externalURL = flag.String("external-url", "",
+		"url machines reach supergiant with, required by cloud-init bootstrap mode, e.g. https://control.example.com")
+	autoscaleInterval = flag.Int("autoscale-interval", 60,
+		"interval in seconds between checks of autoscaled node pools, 0 disables autoscaling")
+	reconcileInterval = flag.Int("reconcile-interval", 30,
+		"interval in seconds between applying desired state of kubes, 0 disables reconciliation")
+	healthCheckInterval = flag.Int("health-check-interval", 60,
+		"interval in seconds between health checks of kubes, 0 disables health monitoring")
+	certCheckInterval = flag.Int("cert-check-interval", 86400,
+		"interval in seconds between checks of certificate expiry dates of kubes, 0 disables checks")
+	catalogFile = flag.String("catalog-file", "",
+		"json file the version catalog is seeded from on first start, bundled catalog is used if empty")
+	catalogMirror = flag.String("catalog-mirror", "",
+		"url of json version catalog it can be refreshed from, e.g. http://mirror.local/catalog.json")
+	backupStore = flag.String("backup-store", "backups",
+		"directory or s3 uri etcd snapshots of kubes are kept in, e.g. s3://access:secret@minio.local:9000/bucket/prefix?region=us-east-1")
+	backupScheduleInterval = flag.Int("backup-schedule-interval", 300,
+		"interval in seconds between checks of backup policies of kubes, 0 disables scheduled backups")
 )
 
 func main() {
@@ -55,10 +75,23 @@ func main() {
 		IdleTimeout:   time.Second * 120,
 		SpawnInterval: time.Second * time.Duration(*spawnInterval),
 
+		AutoscaleInterval: time.Second * time.Duration(*autoscaleInterval),
+		ReconcileInterval: time.Second * time.Duration(*reconcileInterval),
+
+		HealthCheckInterval: time.Second * time.Duration(*healthCheckInterval),
+		CertCheckInterval:   time.Second * time.Duration(*certCheckInterval),
+
 		PprofListenStr: *pprofListenStr,
 
 		ProxiesPortRange: proxy.PortRange{int32(*ProxiesPortRangeFrom), int32(*ProxiesPortRangeTo)},
+		HelmRepositories: parseRepositories(*helmRepositories),
+		ExternalURL:      *externalURL,
+		CatalogFile:      *catalogFile,
+		CatalogMirror:    *catalogMirror,
+		BackupStore:      *backupStore,
 		Version:          version,
+
+		BackupScheduleInterval: time.Second * time.Duration(*backupScheduleInterval),
 	}
 
 	server, err := controlplane.New(cfg)
@@ -79,6 +112,20 @@ func main() {
 	server.Start()
 }
 
+// parseRepositories turns name=url pairs into a map.
+func parseRepositories(s string) map[string]string {
+	repos := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+		repos[parts[0]] = parts[1]
+	}
+
+	return repos
+}
+
 // TODO: create sglog package
 func configureLogging(level, format string) {
 	l, err := logrus.ParseLevel(level)
@@ -1,10 +1,12 @@
 export const CLUSTER_OPTIONS = {
   archs: ['amd64'],
   networkProviders: ['Flannel', 'Calico', 'Weave'],
-  operatingSystems: ['linux'],
+  operatingSystems: ['linux', 'ubuntu', 'debian', 'centos', 'rhel'],
   networkTypes: ['vxlan'],
-  ubuntuVersions: ['xenial'],
+  ubuntuVersions: ['xenial', 'bionic', 'stretch', 'buster', '7'],
   helmVersions: ['2.11.0'],
   dockerVersions: ['18.06.3'],
+  containerRuntimes: ['docker', 'containerd', 'cri-o'],
+  bootstrapModes: ['ssh', 'cloud-init'],
   K8sVersions: ['1.12.10', '1.13.9', '1.14.5', '1.15.2']
 };
@@ -106,6 +106,8 @@ export class NewClusterComponent implements OnInit, OnDestroy {
       networkProvider: ['Flannel', Validators.required],
       helmVersion: ['2.11.0', Validators.required],
       dockerVersion: ['18.06.3', Validators.required],
+      containerRuntime: ['docker', Validators.required],
+      bootstrapMode: ['ssh', Validators.required],
       ubuntuVersion: ['xenial', Validators.required],
       networkType: ['vxlan', Validators.required],
       cidr: ['10.100.0.0/16', [Validators.required, this.validCidr()]],
@@ -179,6 +181,8 @@ export class NewClusterComponent implements OnInit, OnDestroy {
       newClusterData.profile.arch = this.clusterConfig.value.arch;
       newClusterData.profile.cidr = this.clusterConfig.value.cidr;
       newClusterData.profile.dockerVersion = this.clusterConfig.value.dockerVersion;
+      newClusterData.profile.containerRuntime = this.clusterConfig.value.containerRuntime;
+      newClusterData.profile.bootstrapMode = this.clusterConfig.value.bootstrapMode;
       newClusterData.profile.helmVersion = this.clusterConfig.value.helmVersion;
       newClusterData.profile.networkProvider = this.clusterConfig.value.networkProvider;
       newClusterData.profile.networkType = this.clusterConfig.value.networkType;
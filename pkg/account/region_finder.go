@@ -16,11 +16,16 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/digitalocean/godo"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
+	"github.com/packethost/packngo"
 	"github.com/pkg/errors"
 	gcecomputev1 "google.golang.org/api/compute/v1"
 
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/clouds/digitaloceansdk"
+	"github.com/supergiant/control/pkg/clouds/openstacksdk"
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/util"
@@ -92,6 +97,12 @@ func NewRegionsGetter(account *model.CloudAccount, config *steps.Config) (Region
 		return NewGCEFinder(account, config)
 	case clouds.Azure:
 		return NewAzureFinder(account, config)
+	case clouds.OpenStack:
+		return NewOpenStackFinder(account)
+	case clouds.Packet:
+		return NewPacketFinder(account)
+	case clouds.Simulator:
+		return NewSimulatorFinder(account)
 	}
 	return nil, ErrUnsupportedProvider
 }
@@ -108,6 +119,8 @@ func NewZonesGetter(account *model.CloudAccount, config *steps.Config) (ZonesGet
 		return NewAWSFinder(account, config)
 	case clouds.GCE:
 		return NewGCEFinder(account, config)
+	case clouds.OpenStack:
+		return NewOpenStackFinder(account)
 	}
 	return nil, ErrUnsupportedProvider
 }
@@ -126,6 +139,12 @@ func NewTypesGetter(account *model.CloudAccount, config *steps.Config) (TypesGet
 		return NewGCEFinder(account, config)
 	case clouds.Azure:
 		return NewAzureFinder(account, config)
+	case clouds.OpenStack:
+		return NewOpenStackFinder(account)
+	case clouds.Packet:
+		return NewPacketFinder(account)
+	case clouds.Simulator:
+		return NewSimulatorFinder(account)
 	}
 	return nil, ErrUnsupportedProvider
 }
@@ -584,3 +603,324 @@ func hasRestriction(s skus.ResourceSku, restr skus.ResourceSkuRestrictionsType)
 	}
 	return false
 }
+
+// OpenStackFinder looks up regions of the catalog and flavors of compute
+// service in them, region of zones and types comes with account credentials.
+type OpenStackFinder struct {
+	creds openstacksdk.Credentials
+}
+
+func NewOpenStackFinder(acc *model.CloudAccount) (*OpenStackFinder, error) {
+	if acc.Provider != clouds.OpenStack {
+		return nil, ErrUnsupportedProvider
+	}
+
+	creds := openstacksdk.CredentialsFrom(acc.Credentials)
+	creds.Region = acc.Credentials["region"]
+
+	return &OpenStackFinder{
+		creds: creds,
+	}, nil
+}
+
+func (f *OpenStackFinder) GetRegions(ctx context.Context) (*RegionSizes, error) {
+	client, err := openstacksdk.New(ctx, f.creds)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := client.Regions()
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]interface{})
+	regions := make([]*Region, 0)
+
+	for _, name := range names {
+		list, err := listFlavors(client.WithRegion(name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "region %s", name)
+		}
+
+		region := &Region{
+			ID:             name,
+			Name:           name,
+			AvailableSizes: make([]string, 0, len(list)),
+		}
+
+		for _, flavor := range list {
+			sizes[flavor.Name] = Size{
+				RAM: strconv.Itoa(flavor.RAM),
+				CPU: strconv.Itoa(flavor.VCPUs),
+			}
+			region.AvailableSizes = append(region.AvailableSizes, flavor.Name)
+		}
+
+		regions = append(regions, region)
+	}
+
+	return &RegionSizes{
+		Provider: clouds.OpenStack,
+		Regions:  regions,
+		Sizes:    sizes,
+	}, nil
+}
+
+func (f *OpenStackFinder) GetZones(ctx context.Context, config steps.Config) ([]string, error) {
+	client, err := openstacksdk.New(ctx, f.creds)
+	if err != nil {
+		return nil, err
+	}
+
+	compute, err := client.Compute()
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := availabilityzones.List(compute).AllPages()
+	if err != nil {
+		return nil, errors.Wrap(err, "list availability zones")
+	}
+
+	list, err := availabilityzones.ExtractAvailabilityZones(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "list availability zones")
+	}
+
+	zones := make([]string, 0, len(list))
+	for _, zone := range list {
+		if zone.ZoneState.Available {
+			zones = append(zones, zone.ZoneName)
+		}
+	}
+
+	return zones, nil
+}
+
+func (f *OpenStackFinder) GetTypes(ctx context.Context, config steps.Config) ([]string, error) {
+	client, err := openstacksdk.New(ctx, f.creds)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := listFlavors(client)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]string, 0, len(list))
+	for _, flavor := range list {
+		types = append(types, flavor.Name)
+	}
+
+	return types, nil
+}
+
+func listFlavors(client *openstacksdk.Client) ([]flavors.Flavor, error) {
+	compute, err := client.Compute()
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := flavors.ListDetail(compute, nil).AllPages()
+	if err != nil {
+		return nil, errors.Wrap(err, "list flavors")
+	}
+
+	return flavors.ExtractFlavors(pages)
+}
+
+// PacketFinder looks up facilities and plans of servers available in
+// them, facility of types comes with account credentials.
+type PacketFinder struct {
+	facility    string
+	getServices func() (packngo.FacilityService, packngo.PlanService)
+}
+
+func NewPacketFinder(acc *model.CloudAccount) (*PacketFinder, error) {
+	if acc.Provider != clouds.Packet {
+		return nil, ErrUnsupportedProvider
+	}
+
+	return &PacketFinder{
+		facility: acc.Credentials["region"],
+		getServices: func() (packngo.FacilityService, packngo.PlanService) {
+			client := packetsdk.New(acc.Credentials[clouds.PacketAPIKey])
+			return client.Facilities, client.Plans
+		},
+	}, nil
+}
+
+func (f *PacketFinder) GetRegions(ctx context.Context) (*RegionSizes, error) {
+	facilities, plans, err := f.list()
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]interface{})
+	for _, plan := range plans {
+		sizes[plan.Slug] = packetSize(plan)
+	}
+
+	regions := make([]*Region, 0, len(facilities))
+	for _, facility := range facilities {
+		region := &Region{
+			ID:             facility.Code,
+			Name:           facility.Name,
+			AvailableSizes: make([]string, 0),
+		}
+
+		for _, plan := range plans {
+			if availableIn(plan, facility) {
+				region.AvailableSizes = append(region.AvailableSizes, plan.Slug)
+			}
+		}
+
+		regions = append(regions, region)
+	}
+
+	return &RegionSizes{
+		Provider: clouds.Packet,
+		Regions:  regions,
+		Sizes:    sizes,
+	}, nil
+}
+
+func (f *PacketFinder) GetTypes(ctx context.Context, config steps.Config) ([]string, error) {
+	facilities, plans, err := f.list()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]string, 0, len(plans))
+	for _, facility := range facilities {
+		if f.facility != "" && facility.Code != f.facility {
+			continue
+		}
+
+		for _, plan := range plans {
+			if availableIn(plan, facility) && !contains(types, plan.Slug) {
+				types = append(types, plan.Slug)
+			}
+		}
+	}
+
+	return types, nil
+}
+
+// list returns facilities and plans of servers, plans of storage are skipped.
+func (f *PacketFinder) list() ([]packngo.Facility, []packngo.Plan, error) {
+	facilitySvc, planSvc := f.getServices()
+
+	facilities, _, err := facilitySvc.List(nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "list facilities")
+	}
+
+	all, _, err := planSvc.List(nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "list plans")
+	}
+
+	plans := make([]packngo.Plan, 0, len(all))
+	for _, plan := range all {
+		if plan.Line == "baremetal" || plan.Line == "" {
+			plans = append(plans, plan)
+		}
+	}
+
+	return facilities, plans, nil
+}
+
+// packetSize sums processors of the plan, memory of the plan is in gigabytes.
+func packetSize(plan packngo.Plan) Size {
+	var cpus, gb int
+	if plan.Specs != nil {
+		for _, cpu := range plan.Specs.Cpus {
+			if cpu != nil {
+				cpus += cpu.Count
+			}
+		}
+		if plan.Specs.Memory != nil {
+			gb, _ = strconv.Atoi(strings.TrimSuffix(strings.ToUpper(plan.Specs.Memory.Total), "GB"))
+		}
+	}
+
+	return Size{
+		RAM: strconv.Itoa(gb * 1024),
+		CPU: strconv.Itoa(cpus),
+	}
+}
+
+// availableIn tells whether plan can be deployed in the facility, api
+// refers to facilities of the plan by links.
+func availableIn(plan packngo.Plan, facility packngo.Facility) bool {
+	for _, f := range plan.AvailableIn {
+		if f.ID == facility.ID || f.URL == "/facilities/"+facility.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// simulatorSizes are sizes of simulated machines, they are the same
+// in every region.
+var simulatorSizes = []struct {
+	name string
+	size Size
+}{
+	{"small", Size{RAM: "2048", CPU: "2"}},
+	{"medium", Size{RAM: "8192", CPU: "4"}},
+	{"large", Size{RAM: "16384", CPU: "8"}},
+}
+
+var simulatorRegions = []*Region{
+	{ID: "sim-east-1", Name: "Simulator East 1"},
+	{ID: "sim-west-1", Name: "Simulator West 1"},
+}
+
+// SimulatorFinder returns regions and sizes of simulated machines,
+// they are made up so that kube profiles look like real ones.
+type SimulatorFinder struct{}
+
+func NewSimulatorFinder(acc *model.CloudAccount) (*SimulatorFinder, error) {
+	if acc.Provider != clouds.Simulator {
+		return nil, ErrUnsupportedProvider
+	}
+
+	return &SimulatorFinder{}, nil
+}
+
+func (f *SimulatorFinder) GetRegions(ctx context.Context) (*RegionSizes, error) {
+	sizes := make(map[string]interface{}, len(simulatorSizes))
+	for _, s := range simulatorSizes {
+		sizes[s.name] = s.size
+	}
+
+	types, _ := f.GetTypes(ctx, steps.Config{})
+
+	regions := make([]*Region, 0, len(simulatorRegions))
+	for _, r := range simulatorRegions {
+		regions = append(regions, &Region{
+			ID:             r.ID,
+			Name:           r.Name,
+			AvailableSizes: types,
+		})
+	}
+
+	return &RegionSizes{
+		Provider: clouds.Simulator,
+		Regions:  regions,
+		Sizes:    sizes,
+	}, nil
+}
+
+func (f *SimulatorFinder) GetTypes(ctx context.Context, config steps.Config) ([]string, error) {
+	types := make([]string, 0, len(simulatorSizes))
+	for _, s := range simulatorSizes {
+		types = append(types, s.name)
+	}
+
+	return types, nil
+}
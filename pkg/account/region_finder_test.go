@@ -11,12 +11,15 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/digitalocean/godo"
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/packethost/packngo"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/api/compute/v1"
 
 	"github.com/supergiant/control/pkg/clouds"
+	osfixtures "github.com/supergiant/control/pkg/clouds/openstacksdk/fixtures"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows/steps"
@@ -832,3 +835,264 @@ func TestAzureFinder_GetTypes(t *testing.T) {
 		require.Equalf(t, tc.expectedErr, errors.Cause(err), "TC: %s: check error", tc.name)
 	}
 }
+
+func TestOpenStackFinder(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	osfixtures.HandleAuth(t, "RegionOne", "RegionTwo")
+	osfixtures.HandleListFlavors(t, osfixtures.ComputePrefix)
+	osfixtures.HandleListAvailabilityZones(t, osfixtures.ComputePrefix)
+
+	acc := &model.CloudAccount{
+		Provider: clouds.OpenStack,
+		Credentials: map[string]string{
+			clouds.OpenStackAuthURL:     osfixtures.AuthURL(),
+			clouds.OpenStackUserName:    osfixtures.UserName,
+			clouds.OpenStackPassword:    osfixtures.Password,
+			clouds.OpenStackProjectName: osfixtures.ProjectName,
+			"region":                    "RegionTwo",
+		},
+	}
+
+	rf, err := NewRegionsGetter(acc, &steps.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	regions, err := rf.GetRegions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(regions.Regions) != 2 || regions.Regions[1].ID != "RegionTwo" {
+		t.Errorf("wrong regions %v", regions.Regions)
+	}
+
+	if len(regions.Regions[0].AvailableSizes) != 2 {
+		t.Errorf("wrong sizes %v", regions.Regions[0].AvailableSizes)
+	}
+
+	if size, ok := regions.Sizes["m1.medium"].(Size); !ok || size.CPU != "2" || size.RAM != "4096" {
+		t.Errorf("wrong size of m1.medium %v", regions.Sizes["m1.medium"])
+	}
+
+	zg, err := NewZonesGetter(acc, &steps.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	zones, err := zg.GetZones(context.Background(), steps.Config{})
+	if err != nil || len(zones) != 1 || zones[0] != "nova" {
+		t.Errorf("wrong zones %v error %v", zones, err)
+	}
+
+	tg, err := NewTypesGetter(acc, &steps.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	types, err := tg.GetTypes(context.Background(), steps.Config{})
+	if err != nil || len(types) != 2 {
+		t.Errorf("wrong types %v error %v", types, err)
+	}
+
+	acc.Credentials[clouds.OpenStackPassword] = "wrong"
+	rf, _ = NewRegionsGetter(acc, &steps.Config{})
+	if _, err := rf.GetRegions(context.Background()); !sgerrors.IsInvalidCredentials(err) {
+		t.Errorf("wrong error expected %v actual %v", sgerrors.ErrInvalidCredentials, err)
+	}
+}
+
+type mockFacilityService struct {
+	mock.Mock
+}
+
+func (m *mockFacilityService) List(options *packngo.ListOptions) ([]packngo.Facility, *packngo.Response, error) {
+	args := m.Called(options)
+	val, ok := args.Get(0).([]packngo.Facility)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+type mockPlanService struct {
+	mock.Mock
+}
+
+func (m *mockPlanService) List(options *packngo.ListOptions) ([]packngo.Plan, *packngo.Response, error) {
+	args := m.Called(options)
+	val, ok := args.Get(0).([]packngo.Plan)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func TestNewPacketFinder(t *testing.T) {
+	if _, err := NewPacketFinder(&model.CloudAccount{Provider: clouds.AWS}); err != ErrUnsupportedProvider {
+		t.Errorf("wrong error expected %v actual %v", ErrUnsupportedProvider, err)
+	}
+
+	f, err := NewPacketFinder(&model.CloudAccount{
+		Provider: clouds.Packet,
+		Credentials: map[string]string{
+			clouds.PacketAPIKey: "key",
+			"region":            "sjc1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if f.facility != "sjc1" || f.getServices == nil {
+		t.Errorf("wrong finder %v", f)
+	}
+
+	if facilities, plans := f.getServices(); facilities == nil || plans == nil {
+		t.Errorf("services must not be nil")
+	}
+}
+
+func TestPacketFinder(t *testing.T) {
+	facilities := []packngo.Facility{
+		{ID: "f1", Code: "ewr1", Name: "Parsippany, NJ"},
+		{ID: "f2", Code: "sjc1", Name: "Sunnyvale, CA"},
+	}
+	plans := []packngo.Plan{
+		{
+			Slug: "t1.small.x86",
+			Line: "baremetal",
+			Specs: &packngo.Specs{
+				Cpus:   []*packngo.Cpus{{Count: 1}},
+				Memory: &packngo.Memory{Total: "8GB"},
+			},
+			AvailableIn: []packngo.Facility{{URL: "/facilities/f1"}, {URL: "/facilities/f2"}},
+		},
+		{
+			Slug: "c1.small.x86",
+			Line: "baremetal",
+			Specs: &packngo.Specs{
+				Cpus:   []*packngo.Cpus{{Count: 2}, {Count: 2}},
+				Memory: &packngo.Memory{Total: "32GB"},
+			},
+			AvailableIn: []packngo.Facility{{URL: "/facilities/f1"}},
+		},
+		{
+			Slug:        "storage.standard",
+			Line:        "storage",
+			AvailableIn: []packngo.Facility{{URL: "/facilities/f1"}},
+		},
+	}
+
+	testCases := []struct {
+		description   string
+		facilities    []packngo.Facility
+		plans         []packngo.Plan
+		facilitiesErr error
+		plansErr      error
+		expectedErr   error
+	}{
+		{
+			description:   "list facilities",
+			facilitiesErr: fakeErr,
+			expectedErr:   fakeErr,
+		},
+		{
+			description: "list plans",
+			facilities:  facilities,
+			plansErr:    fakeErr,
+			expectedErr: fakeErr,
+		},
+		{
+			description: "success",
+			facilities:  facilities,
+			plans:       plans,
+		},
+	}
+
+	for _, tc := range testCases {
+		facilitySvc := &mockFacilityService{}
+		facilitySvc.On("List", mock.Anything).Return(tc.facilities, tc.facilitiesErr)
+
+		planSvc := &mockPlanService{}
+		planSvc.On("List", mock.Anything).Return(tc.plans, tc.plansErr)
+
+		f := &PacketFinder{
+			facility: "sjc1",
+			getServices: func() (packngo.FacilityService, packngo.PlanService) {
+				return facilitySvc, planSvc
+			},
+		}
+
+		regions, err := f.GetRegions(context.Background())
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		types, err := f.GetTypes(context.Background(), steps.Config{})
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error of types expected %v actual %v", tc.description, tc.expectedErr, err)
+		}
+
+		if tc.expectedErr != nil {
+			continue
+		}
+
+		if regions.Provider != clouds.Packet || len(regions.Regions) != 2 || len(regions.Sizes) != 2 {
+			t.Errorf("%s: wrong regions %v", tc.description, regions)
+		}
+
+		if regions.Regions[0].ID != "ewr1" || len(regions.Regions[0].AvailableSizes) != 2 ||
+			len(regions.Regions[1].AvailableSizes) != 1 {
+			t.Errorf("%s: wrong sizes of regions %v %v", tc.description, regions.Regions[0], regions.Regions[1])
+		}
+
+		if size, ok := regions.Sizes["c1.small.x86"].(Size); !ok || size.CPU != "4" || size.RAM != "32768" {
+			t.Errorf("%s: wrong size of c1.small.x86 %v", tc.description, regions.Sizes["c1.small.x86"])
+		}
+
+		// types are limited to the facility of the account
+		if len(types) != 1 || types[0] != "t1.small.x86" {
+			t.Errorf("%s: wrong types %v", tc.description, types)
+		}
+	}
+
+	if _, err := NewZonesGetter(&model.CloudAccount{Provider: clouds.Packet}, &steps.Config{}); err != ErrUnsupportedProvider {
+		t.Errorf("packet has no zones %v", err)
+	}
+}
+
+func TestSimulatorFinder(t *testing.T) {
+	acc := &model.CloudAccount{
+		Provider: clouds.Simulator,
+	}
+
+	rf, err := NewRegionsGetter(acc, &steps.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	regions, err := rf.GetRegions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(regions.Regions) != 2 || len(regions.Regions[0].AvailableSizes) != 3 || len(regions.Sizes) != 3 {
+		t.Errorf("wrong regions %v", regions)
+	}
+
+	tg, err := NewTypesGetter(acc, &steps.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if types, err := tg.GetTypes(context.Background(), steps.Config{}); err != nil || len(types) != 3 {
+		t.Errorf("wrong types %v error %v", types, err)
+	}
+
+	if _, err := NewSimulatorFinder(&model.CloudAccount{Provider: clouds.AWS}); err != ErrUnsupportedProvider {
+		t.Errorf("wrong error %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package backup takes etcd snapshots of kubes to a store, on demand or
+// by schedule, and restores control planes of kubes from them.
+package backup
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// State is a state of the backup.
+type State string
+
+const (
+	StateCreating  State = "creating"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+)
+
+const (
+	DefaultIntervalHours = 24
+	DefaultRetain        = 7
+)
+
+var (
+	ErrInvalidPolicy = errors.New("invalid backup policy")
+	ErrInvalidState  = errors.New("invalid state")
+)
+
+// Backup is an etcd snapshot of the kube taken on one of its masters.
+type Backup struct {
+	ID     string `json:"id"`
+	KubeID string `json:"kubeId"`
+	Master string `json:"master"`
+	TaskID string `json:"taskId"`
+	// Scheduled backups are taken by policy of the kube and deleted
+	// according to it, backups taken on demand are kept.
+	Scheduled   bool      `json:"scheduled"`
+	State       State     `json:"state"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	CompletedAt time.Time `json:"completedAt,omitempty"`
+}
+
+// Policy schedules backups of the kube.
+type Policy struct {
+	Enabled bool `json:"enabled"`
+	// IntervalHours is a period between scheduled backups.
+	IntervalHours int `json:"intervalHours"`
+	// Retain is a number of scheduled backups kept, older ones are deleted.
+	Retain int `json:"retain"`
+}
+
+// SetDefaults sets zero values of the policy to default ones.
+func (p *Policy) SetDefaults() {
+	if p.IntervalHours == 0 {
+		p.IntervalHours = DefaultIntervalHours
+	}
+	if p.Retain == 0 {
+		p.Retain = DefaultRetain
+	}
+}
+
+// Validate checks interval and retention of the policy are positive.
+func (p Policy) Validate() error {
+	if p.IntervalHours < 1 || p.Retain < 1 {
+		return errors.Wrapf(ErrInvalidPolicy, "interval hours %d, retain %d",
+			p.IntervalHours, p.Retain)
+	}
+
+	return nil
+}
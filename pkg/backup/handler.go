@@ -0,0 +1,451 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type KubeService interface {
+	Get(ctx context.Context, kubeID string) (*model.Kube, error)
+	Create(ctx context.Context, k *model.Kube) error
+}
+
+type ProfileGetter interface {
+	Get(ctx context.Context, id string) (*profile.Profile, error)
+}
+
+type Handler struct {
+	service  *Service
+	kubes    KubeService
+	profiles ProfileGetter
+	repo     storage.Interface
+
+	getWriter func(string) (io.WriteCloser, error)
+	now       func() time.Time
+}
+
+func NewHandler(service *Service, kubes KubeService, profiles ProfileGetter,
+	repo storage.Interface, logDir string) *Handler {
+	return &Handler{
+		service:   service,
+		kubes:     kubes,
+		profiles:  profiles,
+		repo:      repo,
+		getWriter: util.GetWriterFunc(logDir),
+		now:       time.Now,
+	}
+}
+
+func (h *Handler) Register(r *mux.Router) {
+	r.HandleFunc("/kubes/{kubeID}/backups", h.listBackups).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/backups", h.createBackup).Methods(http.MethodPost)
+	r.HandleFunc("/kubes/{kubeID}/backups/policy", h.getPolicy).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/backups/policy", h.putPolicy).Methods(http.MethodPut)
+	r.HandleFunc("/kubes/{kubeID}/backups/{backupID}", h.getBackup).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/backups/{backupID}", h.deleteBackup).Methods(http.MethodDelete)
+	r.HandleFunc("/kubes/{kubeID}/backups/{backupID}/restore", h.restoreBackup).Methods(http.MethodPost)
+}
+
+func (h *Handler) listBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.service.List(r.Context(), mux.Vars(r)["kubeID"])
+	if err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	writeJSON(w, backups)
+}
+
+// createBackup takes etcd snapshot of the kube on demand.
+func (h *Handler) createBackup(w http.ResponseWriter, r *http.Request) {
+	k, ok := h.getKube(w, r)
+	if !ok {
+		return
+	}
+
+	b, err := h.backup(r.Context(), k, false)
+	if err != nil {
+		writeError(w, k.ID, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, b)
+}
+
+func (h *Handler) getBackup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	b, err := h.service.Get(r.Context(), vars["kubeID"], vars["backupID"])
+	if err != nil {
+		writeError(w, vars["backupID"], err)
+		return
+	}
+
+	writeJSON(w, b)
+}
+
+func (h *Handler) deleteBackup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	b, err := h.service.Get(r.Context(), vars["kubeID"], vars["backupID"])
+	if err != nil {
+		writeError(w, vars["backupID"], err)
+		return
+	}
+
+	if b.State == StateCreating {
+		http.Error(w, fmt.Sprintf("backup %s is being created", b.ID), http.StatusConflict)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), b); err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getPolicy(w http.ResponseWriter, r *http.Request) {
+	k, ok := h.getKube(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.GetPolicy(r.Context(), k.ID)
+	if err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	writeJSON(w, p)
+}
+
+// putPolicy sets the backup policy of the kube, zero values get default ones.
+func (h *Handler) putPolicy(w http.ResponseWriter, r *http.Request) {
+	k, ok := h.getKube(w, r)
+	if !ok {
+		return
+	}
+
+	p := Policy{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	p.SetDefaults()
+	if err := h.service.SetPolicy(r.Context(), k.ID, p); err != nil {
+		writeError(w, k.ID, err)
+		return
+	}
+
+	writeJSON(w, p)
+}
+
+// restoreBackup rebuilds etcd of the kube from the backup snapshot on all masters.
+func (h *Handler) restoreBackup(w http.ResponseWriter, r *http.Request) {
+	k, ok := h.getKube(w, r)
+	if !ok {
+		return
+	}
+
+	b, err := h.service.Get(r.Context(), k.ID, mux.Vars(r)["backupID"])
+	if err != nil {
+		writeError(w, mux.Vars(r)["backupID"], err)
+		return
+	}
+
+	restore, err := h.restore(r.Context(), k, b)
+	if err != nil {
+		writeError(w, k.ID, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, restore)
+}
+
+// backup starts etcd snapshot of the kube on its master, the backup
+// is completed once the snapshot is in the store.
+func (h *Handler) backup(ctx context.Context, k *model.Kube, scheduled bool) (*Backup, error) {
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		return nil, errors.Wrapf(ErrInvalidState, "kube %s is %s", k.ID, k.State)
+	}
+
+	master := activeMaster(k)
+	if master == nil {
+		return nil, errors.Wrapf(ErrInvalidState, "kube %s has no active master", k.ID)
+	}
+
+	b := &Backup{
+		ID:        uuid.New()[:8],
+		KubeID:    k.ID,
+		Master:    master.Name,
+		Scheduled: scheduled,
+		State:     StateCreating,
+		CreatedAt: h.now(),
+	}
+
+	config, err := h.taskConfig(ctx, k, *master, b.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := workflows.NewTask(config, workflows.EtcdSnapshot, h.repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "new %s task", workflows.EtcdSnapshot)
+	}
+	b.TaskID = t.ID
+
+	if err := h.service.Save(ctx, b); err != nil {
+		return nil, err
+	}
+
+	writer, err := h.getWriter(util.MakeFileName(t.ID))
+	if err != nil {
+		return nil, errors.Wrapf(err, "get writer for %s", t.ID)
+	}
+
+	result := t.Run(context.Background(), config, writer)
+	created := *b
+	go func() {
+		err := <-result
+		h.completeBackup(created, err)
+	}()
+
+	return b, nil
+}
+
+func (h *Handler) completeBackup(b Backup, taskErr error) {
+	ctx := context.Background()
+
+	b.State = StateCompleted
+	b.CompletedAt = h.now()
+	if taskErr != nil {
+		b.State = StateFailed
+		b.Error = taskErr.Error()
+		logrus.Errorf("backup: kube %s backup %s: %v", b.KubeID, b.ID, taskErr)
+	}
+
+	if err := h.service.Save(ctx, &b); err != nil {
+		logrus.Errorf("backup: %v", err)
+		return
+	}
+
+	if !b.Scheduled {
+		return
+	}
+
+	p, err := h.service.GetPolicy(ctx, b.KubeID)
+	if err != nil {
+		logrus.Errorf("backup: %v", err)
+		return
+	}
+
+	if err := h.service.Prune(ctx, b.KubeID, p.Retain); err != nil {
+		logrus.Errorf("backup: prune backups of %s: %v", b.KubeID, err)
+	}
+}
+
+// restore starts restoring the backup snapshot on every master of the kube,
+// the kube stays restoring until all of them are done.
+func (h *Handler) restore(ctx context.Context, k *model.Kube, b *Backup) (*model.Restore, error) {
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		return nil, errors.Wrapf(ErrInvalidState, "kube %s is %s", k.ID, k.State)
+	}
+
+	if b.State != StateCompleted {
+		return nil, errors.Wrapf(ErrInvalidState, "backup %s is %s", b.ID, b.State)
+	}
+
+	masters := make([]model.Machine, 0, len(k.Masters))
+	for _, m := range k.Masters {
+		if m != nil {
+			masters = append(masters, *m)
+		}
+	}
+	if len(masters) == 0 {
+		return nil, errors.Wrapf(ErrInvalidState, "kube %s has no masters", k.ID)
+	}
+	sort.Slice(masters, func(i, j int) bool {
+		return masters[i].Name < masters[j].Name
+	})
+
+	restore := &model.Restore{
+		BackupID:  b.ID,
+		Phase:     model.RestoreRunning,
+		StartedAt: h.now(),
+	}
+
+	runs := make([]restoreRun, 0, len(masters))
+	for _, m := range masters {
+		config, err := h.taskConfig(ctx, k, m, b.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := workflows.NewTask(config, workflows.EtcdRestore, h.repo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "new %s task", workflows.EtcdRestore)
+		}
+		restore.Tasks = append(restore.Tasks, t.ID)
+
+		runs = append(runs, restoreRun{
+			task:   t,
+			config: config,
+		})
+	}
+
+	k.State = model.StateRestoring
+	k.Restore = restore
+	if err := h.kubes.Create(ctx, k); err != nil {
+		return nil, errors.Wrapf(err, "save kube %s", k.ID)
+	}
+
+	// Tasks are started once the kube is saved restoring
+	results := make([]<-chan error, 0, len(runs))
+	for _, run := range runs {
+		writer, err := h.getWriter(util.MakeFileName(run.task.ID))
+		if err != nil {
+			failed := make(chan error, 1)
+			failed <- errors.Wrapf(err, "get writer for %s", run.task.ID)
+			results = append(results, failed)
+			continue
+		}
+
+		results = append(results, run.task.Run(context.Background(), run.config, writer))
+	}
+
+	started := *restore
+	go h.completeRestore(k.ID, results)
+
+	return &started, nil
+}
+
+type restoreRun struct {
+	task   *workflows.Task
+	config *steps.Config
+}
+
+func (h *Handler) completeRestore(kubeID string, results []<-chan error) {
+	problems := make([]string, 0)
+	for _, result := range results {
+		if err := <-result; err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	ctx := context.Background()
+	k, err := h.kubes.Get(ctx, kubeID)
+	if err != nil {
+		logrus.Errorf("backup: restore kube %s: %v", kubeID, err)
+		return
+	}
+
+	if k.Restore == nil {
+		k.Restore = &model.Restore{}
+	}
+	k.Restore.FinishedAt = h.now()
+	k.Restore.Phase = model.RestoreCompleted
+	k.State = model.StateOperational
+
+	// Health monitor checks degraded kubes, so it reports what is left broken
+	if len(problems) > 0 {
+		k.Restore.Phase = model.RestoreFailed
+		k.Restore.Error = strings.Join(problems, "; ")
+		k.State = model.StateDegraded
+		logrus.Errorf("backup: restore kube %s: %s", kubeID, k.Restore.Error)
+	}
+
+	if err := h.kubes.Create(ctx, k); err != nil {
+		logrus.Errorf("backup: save kube %s: %v", kubeID, err)
+	}
+}
+
+func (h *Handler) taskConfig(ctx context.Context, k *model.Kube, m model.Machine, backupID string) (*steps.Config, error) {
+	kubeProfile, err := h.profiles.Get(ctx, k.ProfileID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
+	config, err := steps.NewConfigFromKube(kubeProfile, k)
+	if err != nil {
+		return nil, errors.Wrap(err, "new config")
+	}
+
+	if err = util.LoadCloudSpecificDataFromKube(k, config); err != nil {
+		return nil, errors.Wrap(err, "load cloud specific data")
+	}
+
+	config.Node = m
+	config.IsMaster = true
+	config.EtcdBackup = backupID
+
+	return config, nil
+}
+
+func (h *Handler) getKube(w http.ResponseWriter, r *http.Request) (*model.Kube, bool) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.kubes.Get(r.Context(), kubeID)
+	if err != nil {
+		writeError(w, kubeID, err)
+		return nil, false
+	}
+
+	return k, true
+}
+
+// activeMaster returns the first active master of the kube by name.
+func activeMaster(k *model.Kube) *model.Machine {
+	var found *model.Machine
+	for _, m := range k.Masters {
+		if m == nil || m.State != model.MachineStateActive {
+			continue
+		}
+		if found == nil || m.Name < found.Name {
+			found = m
+		}
+	}
+
+	return found
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+func writeError(w http.ResponseWriter, entity string, err error) {
+	switch errors.Cause(err) {
+	case sgerrors.ErrNotFound:
+		message.SendNotFound(w, entity, err)
+	case ErrInvalidPolicy:
+		message.SendValidationFailed(w, err)
+	case ErrInvalidState:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		message.SendUnknownError(w, err)
+	}
+}
@@ -0,0 +1,335 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type fakeStep struct {
+	run func(*steps.Config) error
+}
+
+func (s fakeStep) Run(_ context.Context, _ io.Writer, config *steps.Config) error {
+	return s.run(config)
+}
+func (fakeStep) Name() string                                             { return "fake" }
+func (fakeStep) Description() string                                      { return "" }
+func (fakeStep) Depends() []string                                        { return nil }
+func (fakeStep) Rollback(context.Context, io.Writer, *steps.Config) error { return nil }
+
+type fakeKubes struct {
+	m     sync.Mutex
+	kubes map[string]model.Kube
+}
+
+func (f *fakeKubes) Get(ctx context.Context, kubeID string) (*model.Kube, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	k, ok := f.kubes[kubeID]
+	if !ok {
+		return nil, sgerrors.ErrNotFound
+	}
+
+	return &k, nil
+}
+
+func (f *fakeKubes) Create(ctx context.Context, k *model.Kube) error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.kubes[k.ID] = *k
+	return nil
+}
+
+type fakeProfiles struct{}
+
+func (fakeProfiles) Get(ctx context.Context, id string) (*profile.Profile, error) {
+	return &profile.Profile{}, nil
+}
+
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func testKube() model.Kube {
+	return model.Kube{
+		ID:         "kube1234",
+		State:      model.StateOperational,
+		K8SVersion: "1.14.3",
+		Masters: map[string]*model.Machine{
+			"master-2": {Name: "master-2", Role: model.RoleMaster, State: model.MachineStateActive},
+			"master-1": {Name: "master-1", Role: model.RoleMaster, State: model.MachineStateError},
+		},
+	}
+}
+
+func testHandler(k model.Kube) (*Handler, *fakeKubes) {
+	kubes := &fakeKubes{kubes: map[string]model.Kube{k.ID: k}}
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository(), memStore{})
+
+	h := NewHandler(svc, kubes, fakeProfiles{}, memory.NewInMemoryRepository(), "")
+	h.getWriter = func(string) (io.WriteCloser, error) {
+		return &nopCloser{}, nil
+	}
+
+	return h, kubes
+}
+
+func waitBackup(t *testing.T, h *Handler, kubeID, id string) *Backup {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		b, err := h.service.Get(context.Background(), kubeID, id)
+		require.Nil(t, err)
+		if b.State != StateCreating {
+			return b
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("backup %s was not completed", id)
+	return nil
+}
+
+func TestCreateBackup(t *testing.T) {
+	nodes := make(chan string, 1)
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.EtcdSnapshot, []steps.Step{fakeStep{run: func(config *steps.Config) error {
+		nodes <- config.Node.Name + " " + config.EtcdBackup
+		return nil
+	}}})
+
+	h, _ := testHandler(testKube())
+	router := mux.NewRouter()
+	h.Register(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/kubes/kube1234/backups", nil)
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	created := Backup{}
+	require.Nil(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.Equal(t, StateCreating, created.State)
+	require.Equal(t, "master-2", created.Master, "snapshot must be taken on active master")
+	require.False(t, created.Scheduled)
+
+	require.Equal(t, "master-2 "+created.ID, <-nodes)
+	require.Equal(t, StateCompleted, waitBackup(t, h, "kube1234", created.ID).State)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/kubes/kube1234/backups", nil)
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	list := []Backup{}
+	require.Nil(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Len(t, list, 1)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/kubes/kube1234/backups/"+created.ID, nil)
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/kubes/kube1234/backups/"+created.ID, nil)
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCreateBackupFailed(t *testing.T) {
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.EtcdSnapshot, []steps.Step{fakeStep{run: func(*steps.Config) error {
+		return errors.New("etcd is down")
+	}}})
+
+	h, _ := testHandler(testKube())
+	b, err := h.backup(context.Background(), &model.Kube{ID: "kube1234", State: model.StateProvisioning}, false)
+	require.Nil(t, b)
+	require.Equal(t, ErrInvalidState, errors.Cause(err))
+
+	k := testKube()
+	b, err = h.backup(context.Background(), &k, false)
+	require.Nil(t, err)
+
+	failed := waitBackup(t, h, k.ID, b.ID)
+	require.Equal(t, StateFailed, failed.State)
+	require.Contains(t, failed.Error, "etcd is down")
+}
+
+func TestRestoreBackup(t *testing.T) {
+	testCases := []struct {
+		failing       string
+		expectedPhase model.RestorePhase
+		expectedState model.KubeState
+	}{
+		{expectedPhase: model.RestoreCompleted, expectedState: model.StateOperational},
+		{failing: "master-1", expectedPhase: model.RestoreFailed, expectedState: model.StateDegraded},
+	}
+
+	for _, testCase := range testCases {
+		var (
+			m        sync.Mutex
+			restored []string
+			failing  = testCase.failing
+		)
+		workflows.Init()
+		workflows.RegisterWorkFlow(workflows.EtcdRestore, []steps.Step{fakeStep{run: func(config *steps.Config) error {
+			m.Lock()
+			defer m.Unlock()
+			restored = append(restored, config.Node.Name)
+			if config.Node.Name == failing {
+				return errors.New("restore failed")
+			}
+			return nil
+		}}})
+
+		h, kubes := testHandler(testKube())
+		router := mux.NewRouter()
+		h.Register(router)
+
+		require.Nil(t, h.service.Save(context.Background(), &Backup{ID: "b1", KubeID: "kube1234", State: StateCompleted}))
+		require.Nil(t, h.service.Save(context.Background(), &Backup{ID: "b2", KubeID: "kube1234", State: StateFailed}))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/kubes/kube1234/backups/b2/restore", nil)
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusConflict, rec.Code, "failed backup must not be restored")
+
+		rec = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/kubes/kube1234/backups/b1/restore", nil)
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+		restore := model.Restore{}
+		require.Nil(t, json.NewDecoder(rec.Body).Decode(&restore))
+		require.Equal(t, model.RestoreRunning, restore.Phase)
+		require.Len(t, restore.Tasks, 2)
+
+		var k *model.Kube
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			k, _ = kubes.Get(context.Background(), "kube1234")
+			if k.State != model.StateRestoring {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		require.Equal(t, testCase.expectedState, k.State)
+		require.Equal(t, testCase.expectedPhase, k.Restore.Phase)
+		require.Equal(t, "b1", k.Restore.BackupID)
+
+		m.Lock()
+		sort.Strings(restored)
+		require.Equal(t, []string{"master-1", "master-2"}, restored, "all masters must be restored")
+		m.Unlock()
+	}
+}
+
+func TestBackupPolicy(t *testing.T) {
+	h, _ := testHandler(testKube())
+	router := mux.NewRouter()
+	h.Register(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/kubes/kube1234/backups/policy",
+		bytes.NewBufferString(`{"enabled":true,"retain":3}`))
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/kubes/kube1234/backups/policy", nil)
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	p := Policy{}
+	require.Nil(t, json.NewDecoder(rec.Body).Decode(&p))
+	require.Equal(t, Policy{Enabled: true, IntervalHours: DefaultIntervalHours, Retain: 3}, p)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/kubes/kube1234/backups/policy",
+		bytes.NewBufferString(`{"enabled":true,"retain":-1}`))
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/kubes/unknown/backups/policy", nil)
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSchedulerCheck(t *testing.T) {
+	started := make(chan string, 10)
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.EtcdSnapshot, []steps.Step{fakeStep{run: func(config *steps.Config) error {
+		started <- config.EtcdBackup
+		return nil
+	}}})
+
+	h, _ := testHandler(testKube())
+	ctx := context.Background()
+	require.Nil(t, h.service.SetPolicy(ctx, "kube1234", Policy{Enabled: true, IntervalHours: 6, Retain: 1}))
+
+	now := time.Now()
+	s := NewScheduler(h, time.Minute)
+	s.now = func() time.Time { return now }
+
+	require.Nil(t, h.service.Save(ctx, &Backup{
+		ID:        "recent",
+		KubeID:    "kube1234",
+		Scheduled: true,
+		State:     StateCompleted,
+		CreatedAt: now.Add(-time.Hour),
+	}))
+	s.check(ctx)
+	select {
+	case id := <-started:
+		t.Fatalf("unexpected backup %s", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	now = now.Add(6 * time.Hour)
+	s.check(ctx)
+
+	var id string
+	select {
+	case id = <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("backup was not started")
+	}
+
+	b := waitBackup(t, h, "kube1234", id)
+	require.True(t, b.Scheduled)
+
+	// Older scheduled backups are pruned by policy once backup is completed
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := h.service.Get(ctx, "kube1234", "recent"); sgerrors.IsNotFound(err) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("old backup was not pruned")
+}
@@ -0,0 +1,150 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	s3Timeout       = time.Minute * 10
+)
+
+// S3Store keeps snapshots in a bucket of S3-compatible endpoint,
+// objects are addressed by path so that any endpoint works.
+type S3Store struct {
+	endpoint string
+	bucket   string
+	prefix   string
+	region   string
+	signer   *v4.Signer
+	client   *http.Client
+}
+
+// NewS3Store parses uri like s3://access:secret@host:port/bucket/prefix?region=eu-west-1&insecure=true,
+// credentials are taken from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+// environment variables when the uri has none.
+func NewS3Store(uri string) (*S3Store, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse s3 uri")
+	}
+
+	parts := strings.SplitN(strings.Trim(u.Path, "/"), "/", 2)
+	if u.Host == "" || parts[0] == "" {
+		return nil, errors.New("s3 uri must have host and bucket")
+	}
+
+	creds := credentials.NewEnvCredentials()
+	if u.User != nil {
+		secret, _ := u.User.Password()
+		creds = credentials.NewStaticCredentials(u.User.Username(), secret, "")
+	}
+
+	scheme := "https"
+	if u.Query().Get("insecure") == "true" {
+		scheme = "http"
+	}
+
+	region := u.Query().Get("region")
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	s := &S3Store{
+		endpoint: scheme + "://" + u.Host,
+		bucket:   parts[0],
+		region:   region,
+		signer:   v4.NewSigner(creds),
+		client: &http.Client{
+			Timeout: s3Timeout,
+		},
+	}
+	if len(parts) > 1 {
+		s.prefix = parts[1]
+	}
+
+	return s, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return s3Error(resp, key)
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := s3Error(resp, key); err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return s3Error(resp, key)
+}
+
+func (s *S3Store) do(ctx context.Context, method, key string, data []byte) (*http.Response, error) {
+	objectURL := s.endpoint + "/" + path.Join(s.bucket, s.prefix, key)
+	req, err := http.NewRequest(method, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if _, err := s.signer.Sign(req, bytes.NewReader(data), "s3", s.region, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "sign s3 request")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", method, objectURL)
+	}
+
+	return resp, nil
+}
+
+func s3Error(resp *http.Response, key string) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errors.Wrapf(sgerrors.ErrNotFound, "snapshot %s", key)
+	case resp.StatusCode >= http.StatusMultipleChoices:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("s3: %s %s: %s", resp.Status, key, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
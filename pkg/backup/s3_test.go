@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func TestS3Store(t *testing.T) {
+	var (
+		m       sync.Mutex
+		objects = make(map[string][]byte)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		m.Lock()
+		defer m.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := ioutil.ReadAll(r.Body)
+			objects[r.URL.Path] = data
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			if _, ok := objects[r.URL.Path]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	store, err := NewS3Store("s3://access:secret@" + host + "/backups/control?insecure=true")
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	require.Nil(t, store.Put(ctx, "kube/b1.db", []byte("snapshot")))
+	require.Contains(t, objects, "/backups/control/kube/b1.db")
+
+	data, err := store.Get(ctx, "kube/b1.db")
+	require.Nil(t, err)
+	require.Equal(t, "snapshot", string(data))
+
+	require.Nil(t, store.Delete(ctx, "kube/b1.db"))
+	require.Nil(t, store.Delete(ctx, "kube/b1.db"), "missing object must be deleted")
+
+	_, err = store.Get(ctx, "kube/b1.db")
+	require.True(t, sgerrors.IsNotFound(err))
+
+	denied, err := NewS3Store("s3://other:secret@" + host + "/backups?insecure=true")
+	require.Nil(t, err)
+	err = denied.Put(ctx, "kube/b1.db", []byte("snapshot"))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "403")
+}
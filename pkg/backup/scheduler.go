@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// Scheduler regularly takes backups of kubes that have backup policy enabled,
+// a backup is taken when the latest scheduled one is older than policy interval.
+type Scheduler struct {
+	interval time.Duration
+	h        *Handler
+	now      func() time.Time
+}
+
+// NewScheduler makes scheduler that checks policies every interval.
+func NewScheduler(h *Handler, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		h:        h,
+		now:      time.Now,
+	}
+}
+
+// Run checks policies every interval until the context is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) check(ctx context.Context) {
+	policies, err := s.h.service.Policies(ctx)
+	if err != nil {
+		logrus.Errorf("backup scheduler: %v", err)
+		return
+	}
+
+	for kubeID, p := range policies {
+		if !p.Enabled {
+			continue
+		}
+
+		if err := s.checkKube(ctx, kubeID, p); err != nil {
+			logrus.Errorf("backup scheduler: kube %s: %v", kubeID, err)
+		}
+	}
+}
+
+func (s *Scheduler) checkKube(ctx context.Context, kubeID string, p Policy) error {
+	backups, err := s.h.service.List(ctx, kubeID)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range backups {
+		if !b.Scheduled {
+			continue
+		}
+		// Backups go from the latest one, so the first scheduled is enough
+		if b.State == StateCreating ||
+			s.now().Sub(b.CreatedAt) < time.Duration(p.IntervalHours)*time.Hour {
+			return nil
+		}
+		break
+	}
+
+	k, err := s.h.kubes.Get(ctx, kubeID)
+	if sgerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Kubes that are busy get backup on the next check
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		return nil
+	}
+
+	b, err := s.h.backup(ctx, k, true)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("backup scheduler: backup %s of kube %s started", b.ID, kubeID)
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/workflows/steps/etcd"
+)
+
+const DefaultStoragePrefix = "/supergiant/backups/"
+
+// Service keeps backups of kubes and their policies in storage,
+// snapshots of backups are kept in the store.
+type Service struct {
+	prefix  string
+	storage storage.Interface
+	store   Store
+}
+
+func NewService(prefix string, s storage.Interface, store Store) *Service {
+	return &Service{
+		prefix:  prefix,
+		storage: s,
+		store:   store,
+	}
+}
+
+func (s *Service) Save(ctx context.Context, b *Backup) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrapf(s.storage.Put(ctx, s.prefix, backupKey(b.KubeID, b.ID), data),
+		"store backup %s", b.ID)
+}
+
+func (s *Service) Get(ctx context.Context, kubeID, id string) (*Backup, error) {
+	data, err := s.storage.Get(ctx, s.prefix, backupKey(kubeID, id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "get backup %s", id)
+	}
+
+	b := &Backup{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal backup %s", id)
+	}
+
+	return b, nil
+}
+
+// List returns backups of the kube, the latest ones go first.
+func (s *Service) List(ctx context.Context, kubeID string) ([]Backup, error) {
+	rawItems, err := s.storage.GetAll(ctx, s.prefix+backupKey(kubeID, ""))
+	if err != nil {
+		return nil, errors.Wrap(err, "list backups")
+	}
+
+	backups := make([]Backup, 0, len(rawItems))
+	for _, data := range rawItems {
+		if len(data) == 0 {
+			continue
+		}
+
+		b := Backup{}
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, errors.Wrap(err, "unmarshal backup")
+		}
+		backups = append(backups, b)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// Delete removes the backup and its snapshot.
+func (s *Service) Delete(ctx context.Context, b *Backup) error {
+	if err := s.store.Delete(ctx, etcd.SnapshotKey(b.KubeID, b.ID)); err != nil {
+		return errors.Wrapf(err, "delete snapshot of %s", b.ID)
+	}
+
+	return errors.Wrapf(s.storage.Delete(ctx, s.prefix, backupKey(b.KubeID, b.ID)),
+		"delete backup %s", b.ID)
+}
+
+// Prune deletes finished scheduled backups of the kube beyond the latest retained ones.
+func (s *Service) Prune(ctx context.Context, kubeID string, retain int) error {
+	backups, err := s.List(ctx, kubeID)
+	if err != nil {
+		return err
+	}
+
+	kept := 0
+	for i := range backups {
+		b := &backups[i]
+		if !b.Scheduled || b.State == StateCreating {
+			continue
+		}
+
+		if kept < retain {
+			kept++
+			continue
+		}
+
+		if err := s.Delete(ctx, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetPolicy returns policy of the kube, kubes have backups disabled by default.
+func (s *Service) GetPolicy(ctx context.Context, kubeID string) (Policy, error) {
+	item := storedPolicy{}
+
+	data, err := s.storage.Get(ctx, s.prefix, policyKey(kubeID))
+	if sgerrors.IsNotFound(err) {
+		item.Policy.SetDefaults()
+		return item.Policy, nil
+	}
+	if err != nil {
+		return item.Policy, errors.Wrapf(err, "get backup policy of %s", kubeID)
+	}
+
+	return item.Policy, errors.Wrap(json.Unmarshal(data, &item), "unmarshal backup policy")
+}
+
+func (s *Service) SetPolicy(ctx context.Context, kubeID string, p Policy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(storedPolicy{
+		KubeID: kubeID,
+		Policy: p,
+	})
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrapf(s.storage.Put(ctx, s.prefix, policyKey(kubeID), data),
+		"store backup policy of %s", kubeID)
+}
+
+// Policies returns policies of kubes by kube ids.
+func (s *Service) Policies(ctx context.Context) (map[string]Policy, error) {
+	rawItems, err := s.storage.GetAll(ctx, s.prefix+policyKey(""))
+	if err != nil {
+		return nil, errors.Wrap(err, "list backup policies")
+	}
+
+	policies := make(map[string]Policy, len(rawItems))
+	for _, data := range rawItems {
+		if len(data) == 0 {
+			continue
+		}
+
+		item := storedPolicy{}
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, errors.Wrap(err, "unmarshal backup policy")
+		}
+		policies[item.KubeID] = item.Policy
+	}
+
+	return policies, nil
+}
+
+type storedPolicy struct {
+	KubeID string `json:"kubeId"`
+	Policy
+}
+
+func backupKey(kubeID, id string) string {
+	return "kubes/" + kubeID + "/" + id
+}
+
+func policyKey(kubeID string) string {
+	return "policies/" + kubeID
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/workflows/steps/etcd"
+)
+
+type memStore map[string][]byte
+
+func (s memStore) Put(ctx context.Context, key string, data []byte) error {
+	s[key] = data
+	return nil
+}
+
+func (s memStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, sgerrors.ErrNotFound
+	}
+
+	return data, nil
+}
+
+func (s memStore) Delete(ctx context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func TestServicePrune(t *testing.T) {
+	store := memStore{}
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository(), store)
+	ctx := context.Background()
+
+	created := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	backups := []Backup{
+		{ID: "b1", Scheduled: true, State: StateCompleted},
+		{ID: "b2", Scheduled: false, State: StateCompleted},
+		{ID: "b3", Scheduled: true, State: StateFailed},
+		{ID: "b4", Scheduled: true, State: StateCompleted},
+		{ID: "b5", Scheduled: true, State: StateCreating},
+	}
+	for i, b := range backups {
+		b.KubeID = "kube1234"
+		b.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+		require.Nil(t, svc.Save(ctx, &b))
+		store[etcd.SnapshotKey(b.KubeID, b.ID)] = []byte(b.ID)
+	}
+	require.Nil(t, svc.Save(ctx, &Backup{ID: "other", KubeID: "other", Scheduled: true}))
+
+	require.Nil(t, svc.Prune(ctx, "kube1234", 2))
+
+	list, err := svc.List(ctx, "kube1234")
+	require.Nil(t, err)
+
+	ids := make([]string, 0, len(list))
+	for _, b := range list {
+		ids = append(ids, b.ID)
+	}
+	require.Equal(t, []string{"b5", "b4", "b3", "b2"}, ids,
+		"latest scheduled backups and ones taken on demand must be kept")
+	require.NotContains(t, store, etcd.SnapshotKey("kube1234", "b1"))
+
+	_, err = svc.Get(ctx, "kube1234", "b1")
+	require.True(t, sgerrors.IsNotFound(err))
+}
+
+func TestServicePolicies(t *testing.T) {
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository(), memStore{})
+	ctx := context.Background()
+
+	p, err := svc.GetPolicy(ctx, "kube1234")
+	require.Nil(t, err)
+	require.Equal(t, Policy{IntervalHours: DefaultIntervalHours, Retain: DefaultRetain}, p)
+
+	require.NotNil(t, svc.SetPolicy(ctx, "kube1234", Policy{Enabled: true}))
+
+	expected := Policy{Enabled: true, IntervalHours: 6, Retain: 3}
+	require.Nil(t, svc.SetPolicy(ctx, "kube1234", expected))
+
+	p, err = svc.GetPolicy(ctx, "kube1234")
+	require.Nil(t, err)
+	require.Equal(t, expected, p)
+
+	policies, err := svc.Policies(ctx)
+	require.Nil(t, err)
+	require.Equal(t, map[string]Policy{"kube1234": expected}, policies)
+}
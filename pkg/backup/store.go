@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// Store keeps snapshots by keys, keys are relative paths.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore returns store of the uri, s3:// uris stand for S3-compatible
+// endpoints, other ones are directories on local disk.
+func NewStore(uri string) (Store, error) {
+	if strings.HasPrefix(uri, "s3://") {
+		return NewS3Store(uri)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse backup store %s", uri)
+	}
+	if u.Scheme == "file" {
+		uri = u.Path
+	}
+
+	return NewDiskStore(uri)
+}
+
+// DiskStore keeps snapshots in the directory.
+type DiskStore struct {
+	dir string
+}
+
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if dir == "" {
+		return nil, errors.New("backup directory is empty")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "create backup directory %s", dir)
+	}
+
+	return &DiskStore{
+		dir: dir,
+	}, nil
+}
+
+func (s *DiskStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Snapshot is written to temporary file first, so that a broken
+	// write does not leave partial snapshot
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *DiskStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(sgerrors.ErrNotFound, "snapshot %s", key)
+	}
+
+	return data, err
+}
+
+func (s *DiskStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *DiskStore) path(key string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", errors.Errorf("invalid snapshot key %s", key)
+	}
+
+	return path, nil
+}
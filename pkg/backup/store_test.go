@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func TestDiskStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backups")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := NewStore("file://" + dir)
+	require.Nil(t, err)
+	require.IsType(t, &DiskStore{}, store)
+
+	ctx := context.Background()
+	require.Nil(t, store.Put(ctx, "kube/b1.db", []byte("snapshot")))
+
+	info, err := os.Stat(filepath.Join(dir, "kube", "b1.db"))
+	require.Nil(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "snapshots must be private")
+
+	data, err := store.Get(ctx, "kube/b1.db")
+	require.Nil(t, err)
+	require.Equal(t, "snapshot", string(data))
+
+	require.Nil(t, store.Delete(ctx, "kube/b1.db"))
+	require.Nil(t, store.Delete(ctx, "kube/b1.db"), "missing snapshot must be deleted")
+
+	_, err = store.Get(ctx, "kube/b1.db")
+	require.True(t, sgerrors.IsNotFound(err))
+
+	require.NotNil(t, store.Put(ctx, "../b2.db", []byte("snapshot")), "keys must stay in the directory")
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore("s3://access:secret@minio:9000/backups/control?insecure=true")
+	require.Nil(t, err)
+
+	s3, ok := store.(*S3Store)
+	require.True(t, ok)
+	require.Equal(t, "http://minio:9000", s3.endpoint)
+	require.Equal(t, "backups", s3.bucket)
+	require.Equal(t, "control", s3.prefix)
+	require.Equal(t, defaultS3Region, s3.region)
+
+	_, err = NewStore("s3://minio:9000")
+	require.NotNil(t, err, "bucket is required")
+
+	_, err = NewStore("")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// Modes of running provisioning steps on machines
+const (
+	// SSH runs every step over ssh connection from control.
+	SSH = "ssh"
+	// CloudInit renders steps into user data of the machine, cloud-init
+	// runs them on first boot and reports progress back to control.
+	CloudInit = "cloud-init"
+)
+
+var ErrUnknownMode = errors.New("unknown bootstrap mode")
+
+// Validate checks that mode is supported, empty mode stands for ssh.
+func Validate(mode string) error {
+	switch mode {
+	case "", SSH, CloudInit:
+		return nil
+	}
+	return errors.Wrap(ErrUnknownMode, mode)
+}
+
+// NewToken generates a secret machines of the kube use to report
+// bootstrap progress.
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "generate callback token")
+	}
+
+	return hex.EncodeToString(buf), nil
+}
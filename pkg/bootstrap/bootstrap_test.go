@@ -0,0 +1,37 @@
+package bootstrap
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestValidate(t *testing.T) {
+	for _, mode := range []string{"", SSH, CloudInit} {
+		if err := Validate(mode); err != nil {
+			t.Errorf("mode %q: unexpected error %v", mode, err)
+		}
+	}
+
+	if err := Validate("ignition"); errors.Cause(err) != ErrUnknownMode {
+		t.Errorf("wrong error expected %v actual %v", ErrUnknownMode, err)
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	first, err := NewToken()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	second, err := NewToken()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(first) != 64 {
+		t.Errorf("wrong token length %d", len(first))
+	}
+	if first == second {
+		t.Errorf("tokens must differ")
+	}
+}
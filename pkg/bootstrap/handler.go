@@ -0,0 +1,128 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+type KubeGetter interface {
+	Get(ctx context.Context, kubeID string) (*model.Kube, error)
+}
+
+type Handler struct {
+	service *Service
+	kubes   KubeGetter
+}
+
+func NewHandler(service *Service, kubes KubeGetter) *Handler {
+	return &Handler{
+		service: service,
+		kubes:   kubes,
+	}
+}
+
+// RegisterCallback registers endpoint machines report their progress to,
+// it is authenticated with the callback token of the kube instead of
+// user credentials.
+func (h *Handler) RegisterCallback(r *mux.Router) {
+	r.HandleFunc("/bootstrap/{kubeID}/{taskID}", h.Callback).Methods(http.MethodPost)
+}
+
+func (h *Handler) Register(r *mux.Router) {
+	r.HandleFunc("/kubes/{kubeID}/bootstrap", h.ListStatuses).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/bootstrap/{taskID}", h.GetStatus).Methods(http.MethodGet)
+}
+
+// CallbackURL is an url of the endpoint the machine provisioned by the task
+// reports to.
+func CallbackURL(baseURL, kubeID, taskID string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/bootstrap/" + kubeID + "/" + taskID
+}
+
+func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	k, err := h.kubes.Get(r.Context(), vars["kubeID"])
+	if err != nil && errors.Cause(err) != sgerrors.ErrNotFound {
+		logrus.Errorf("bootstrap callback: get kube %s: %v", vars["kubeID"], err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// unknown kubes are not distinguished from wrong tokens
+	if k == nil || !validToken(r, k.CallbackToken) {
+		http.Error(w, "", http.StatusUnauthorized)
+		return
+	}
+
+	report := Report{}
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.Report(r.Context(), k.ID, vars["taskID"], report); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) ListStatuses(w http.ResponseWriter, r *http.Request) {
+	items, err := h.service.List(r.Context(), mux.Vars(r)["kubeID"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	st, err := h.service.Get(r.Context(), vars["kubeID"], vars["taskID"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(st); err != nil {
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func validToken(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch errors.Cause(err) {
+	case sgerrors.ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidReport:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
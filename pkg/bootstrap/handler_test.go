@@ -0,0 +1,128 @@
+package bootstrap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+)
+
+type fakeKubes map[string]*model.Kube
+
+func (f fakeKubes) Get(ctx context.Context, kubeID string) (*model.Kube, error) {
+	if k, ok := f[kubeID]; ok {
+		return k, nil
+	}
+	return nil, sgerrors.ErrNotFound
+}
+
+func TestHandlerCallback(t *testing.T) {
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository())
+	kubes := fakeKubes{
+		"kube":     {ID: "kube", CallbackToken: "secret"},
+		"ssh-kube": {ID: "ssh-kube"},
+	}
+
+	router := mux.NewRouter()
+	NewHandler(svc, kubes).RegisterCallback(router)
+
+	testCases := []struct {
+		description  string
+		kubeID       string
+		token        string
+		body         string
+		expectedCode int
+	}{
+		{
+			description:  "unknown kube",
+			kubeID:       "unknown",
+			token:        "secret",
+			body:         `{"step": "docker", "status": "executing"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			description:  "wrong token",
+			kubeID:       "kube",
+			token:        "wrong",
+			body:         `{"step": "docker", "status": "executing"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			description:  "kube without token",
+			kubeID:       "ssh-kube",
+			token:        "",
+			body:         `{"step": "docker", "status": "executing"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			description:  "invalid json",
+			kubeID:       "kube",
+			token:        "secret",
+			body:         `{`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "invalid status",
+			kubeID:       "kube",
+			token:        "secret",
+			body:         `{"step": "docker", "status": "todo"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "success",
+			kubeID:       "kube",
+			token:        "secret",
+			body:         `{"step": "docker", "status": "success"}`,
+			expectedCode: http.StatusAccepted,
+		},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/bootstrap/"+tc.kubeID+"/task",
+			strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+		require.Equal(t, tc.expectedCode, rec.Code, tc.description)
+	}
+
+	st, err := svc.Get(context.Background(), "kube", "task")
+	require.NoError(t, err)
+	require.Len(t, st.Steps, 1)
+	require.Equal(t, statuses.Success, st.Steps[0].Status)
+}
+
+func TestHandlerGetStatus(t *testing.T) {
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository())
+	_, err := svc.Report(context.Background(), "kube", "task",
+		Report{Step: "docker", Status: statuses.Executing})
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	NewHandler(svc, fakeKubes{}).Register(router)
+
+	for path, code := range map[string]int{
+		"/kubes/kube/bootstrap":         http.StatusOK,
+		"/kubes/kube/bootstrap/task":    http.StatusOK,
+		"/kubes/kube/bootstrap/unknown": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, code, rec.Code, path)
+	}
+}
+
+func TestCallbackURL(t *testing.T) {
+	require.Equal(t, "https://control/bootstrap/kube/task",
+		CallbackURL("https://control/", "kube", "task"))
+}
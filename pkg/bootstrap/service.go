@@ -0,0 +1,166 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+)
+
+const DefaultStoragePrefix = "/supergiant/bootstrap/"
+
+var ErrInvalidReport = errors.New("invalid report")
+
+// Report is sent by a machine when a step starts and finishes, report
+// without a step is about the whole bootstrap.
+type Report struct {
+	Step    string          `json:"step"`
+	Status  statuses.Status `json:"status"`
+	Message string          `json:"message,omitempty"`
+}
+
+type StepStatus struct {
+	Name      string          `json:"name"`
+	Status    statuses.Status `json:"status"`
+	Message   string          `json:"message,omitempty"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// Status is a progress of bootstrap of the machine provisioned by the task.
+type Status struct {
+	KubeID    string          `json:"kubeId"`
+	TaskID    string          `json:"taskId"`
+	Status    statuses.Status `json:"status"`
+	Message   string          `json:"message,omitempty"`
+	Steps     []StepStatus    `json:"steps"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// Service keeps bootstrap statuses reported by machines.
+type Service struct {
+	prefix  string
+	storage storage.Interface
+
+	// reports of a machine are read-modify-write
+	m sync.Mutex
+}
+
+func NewService(prefix string, s storage.Interface) *Service {
+	return &Service{
+		prefix:  prefix,
+		storage: s,
+	}
+}
+
+// Report records status of the step.
+func (s *Service) Report(ctx context.Context, kubeID, taskID string, r Report) (*Status, error) {
+	switch r.Status {
+	case statuses.Executing, statuses.Success, statuses.Error:
+	default:
+		return nil, errors.Wrapf(ErrInvalidReport, "status %q", r.Status)
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	st, err := s.Get(ctx, kubeID, taskID)
+	if err != nil {
+		if errors.Cause(err) != sgerrors.ErrNotFound {
+			return nil, err
+		}
+		st = &Status{
+			KubeID: kubeID,
+			TaskID: taskID,
+			Status: statuses.Executing,
+		}
+	}
+
+	now := time.Now()
+	st.UpdatedAt = now
+
+	if r.Step == "" {
+		st.Status = r.Status
+		st.Message = r.Message
+	} else {
+		st.setStep(StepStatus{
+			Name:      r.Step,
+			Status:    r.Status,
+			Message:   r.Message,
+			UpdatedAt: now,
+		})
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Put(ctx, s.kubePrefix(kubeID), taskID, data); err != nil {
+		return nil, errors.Wrapf(err, "store bootstrap status of task %s", taskID)
+	}
+
+	return st, nil
+}
+
+// Get returns bootstrap status of the machine provisioned by the task.
+func (s *Service) Get(ctx context.Context, kubeID, taskID string) (*Status, error) {
+	data, err := s.storage.Get(ctx, s.kubePrefix(kubeID), taskID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bootstrap status of task %s", taskID)
+	}
+
+	st := &Status{}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, errors.Wrap(err, "unmarshal bootstrap status")
+	}
+
+	return st, nil
+}
+
+// List returns bootstrap statuses of machines of the kube.
+func (s *Service) List(ctx context.Context, kubeID string) ([]Status, error) {
+	rawItems, err := s.storage.GetAll(ctx, s.kubePrefix(kubeID))
+	if err != nil {
+		return nil, errors.Wrap(err, "list bootstrap statuses")
+	}
+
+	items := make([]Status, 0, len(rawItems))
+	for _, data := range rawItems {
+		if len(data) == 0 {
+			continue
+		}
+
+		st := Status{}
+		if err := json.Unmarshal(data, &st); err != nil {
+			return nil, errors.Wrap(err, "unmarshal bootstrap status")
+		}
+		items = append(items, st)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].TaskID < items[j].TaskID
+	})
+
+	return items, nil
+}
+
+func (s *Service) kubePrefix(kubeID string) string {
+	return s.prefix + kubeID + "/"
+}
+
+func (st *Status) setStep(step StepStatus) {
+	for i := range st.Steps {
+		if st.Steps[i].Name == step.Name {
+			st.Steps[i] = step
+			return
+		}
+	}
+	st.Steps = append(st.Steps, step)
+}
@@ -0,0 +1,65 @@
+package bootstrap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+)
+
+func TestServiceReport(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository())
+
+	_, err := svc.Get(ctx, "kube", "task")
+	require.Equal(t, sgerrors.ErrNotFound, errors.Cause(err))
+
+	reports := []Report{
+		{Step: "docker", Status: statuses.Executing},
+		{Step: "docker", Status: statuses.Success},
+		{Step: "kubeadm", Status: statuses.Executing},
+	}
+	for _, r := range reports {
+		_, err := svc.Report(ctx, "kube", "task", r)
+		require.NoError(t, err)
+	}
+
+	st, err := svc.Get(ctx, "kube", "task")
+	require.NoError(t, err)
+	require.Equal(t, statuses.Executing, st.Status)
+	require.Len(t, st.Steps, 2)
+	require.Equal(t, "docker", st.Steps[0].Name)
+	require.Equal(t, statuses.Success, st.Steps[0].Status)
+	require.Equal(t, statuses.Executing, st.Steps[1].Status)
+
+	st, err = svc.Report(ctx, "kube", "task", Report{Status: statuses.Error, Message: "step kubeadm has failed"})
+	require.NoError(t, err)
+	require.Equal(t, statuses.Error, st.Status)
+	require.Equal(t, "step kubeadm has failed", st.Message)
+
+	_, err = svc.Report(ctx, "kube", "task", Report{Step: "docker", Status: statuses.Todo})
+	require.Equal(t, ErrInvalidReport, errors.Cause(err))
+}
+
+func TestServiceList(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository())
+
+	for _, id := range []string{"b", "a"} {
+		_, err := svc.Report(ctx, "kube", id, Report{Step: "docker", Status: statuses.Executing})
+		require.NoError(t, err)
+	}
+	_, err := svc.Report(ctx, "other", "c", Report{Step: "docker", Status: statuses.Executing})
+	require.NoError(t, err)
+
+	items, err := svc.List(ctx, "kube")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, "a", items[0].TaskID)
+	require.Equal(t, "b", items[1].TaskID)
+}
@@ -0,0 +1,62 @@
+package catalog
+
+// Bundled is the catalog used until other one is stored or seeded,
+// kubeadm config templates require kubeadm 1.15 for every release.
+const Bundled = `{
+  "releases": [
+    {
+      "version": "1.11.5",
+      "kubeadm": "1.15.1",
+      "docker": ["17.03.2", "18.06.3"],
+      "containerd": ["1.2.6"],
+      "cni": "0.7.5",
+      "helm": ["2.11.0", "2.14.3"],
+      "etcd": "3.2.18"
+    },
+    {
+      "version": "1.12.7",
+      "kubeadm": "1.15.1",
+      "docker": ["17.03.2", "18.06.3"],
+      "containerd": ["1.2.6"],
+      "cni": "0.7.5",
+      "helm": ["2.11.0", "2.14.3"],
+      "etcd": "3.2.24"
+    },
+    {
+      "version": "1.13.7",
+      "kubeadm": "1.15.1",
+      "docker": ["17.03.2", "18.06.3"],
+      "containerd": ["1.2.6"],
+      "cni": "0.7.5",
+      "helm": ["2.11.0", "2.14.3"],
+      "etcd": "3.2.24"
+    },
+    {
+      "version": "1.14.3",
+      "kubeadm": "1.15.1",
+      "docker": ["18.06.3", "18.09.7"],
+      "containerd": ["1.2.6"],
+      "cni": "0.7.5",
+      "helm": ["2.11.0", "2.14.3"],
+      "etcd": "3.3.10"
+    },
+    {
+      "version": "1.15.1",
+      "kubeadm": "1.15.1",
+      "docker": ["18.06.3", "18.09.7"],
+      "containerd": ["1.2.6"],
+      "cni": "0.7.5",
+      "helm": ["2.11.0", "2.14.3"],
+      "etcd": "3.3.10"
+    }
+  ]
+}`
+
+func init() {
+	c, err := Parse([]byte(Bundled))
+	if err != nil {
+		panic(err)
+	}
+
+	current = c
+}
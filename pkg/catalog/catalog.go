@@ -0,0 +1,189 @@
+package catalog
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+var (
+	ErrInvalidCatalog     = errors.New("invalid version catalog")
+	ErrUnsupportedVersion = errors.New("unsupported version")
+)
+
+// Release describes kubernetes version and versions of components
+// known to work with it.
+type Release struct {
+	Version    string   `json:"version"`
+	Kubeadm    string   `json:"kubeadm"`
+	Docker     []string `json:"docker"`
+	Containerd []string `json:"containerd,omitempty"`
+	CNI        string   `json:"cni"`
+	Helm       []string `json:"helm"`
+	Etcd       string   `json:"etcd"`
+}
+
+type Catalog struct {
+	Releases []Release `json:"releases"`
+}
+
+var (
+	m       sync.RWMutex
+	current Catalog
+)
+
+// Parse decodes and validates the catalog, releases are sorted by version.
+func Parse(data []byte) (Catalog, error) {
+	c := Catalog{}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, errors.Wrap(ErrInvalidCatalog, err.Error())
+	}
+
+	if err := c.Validate(); err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+// Validate checks every release has a version and kubeadm to install it,
+// releases get sorted by version.
+func (c *Catalog) Validate() error {
+	if len(c.Releases) == 0 {
+		return errors.Wrap(ErrInvalidCatalog, "no releases")
+	}
+
+	parsed := make(map[string]*semver.Version, len(c.Releases))
+	for _, r := range c.Releases {
+		v, err := semver.NewVersion(r.Version)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidCatalog, "release %q: %v", r.Version, err)
+		}
+		if _, ok := parsed[r.Version]; ok {
+			return errors.Wrapf(ErrInvalidCatalog, "duplicate release %s", r.Version)
+		}
+		if _, err := semver.NewVersion(r.Kubeadm); err != nil {
+			return errors.Wrapf(ErrInvalidCatalog, "release %s: kubeadm %q: %v", r.Version, r.Kubeadm, err)
+		}
+		if len(r.Docker) == 0 && len(r.Containerd) == 0 {
+			return errors.Wrapf(ErrInvalidCatalog, "release %s: no container runtime", r.Version)
+		}
+
+		parsed[r.Version] = v
+	}
+
+	sort.Slice(c.Releases, func(i, j int) bool {
+		return parsed[c.Releases[i].Version].LessThan(parsed[c.Releases[j].Version])
+	})
+
+	return nil
+}
+
+// Set makes the catalog effective.
+func Set(c Catalog) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	current = c
+
+	return nil
+}
+
+// Current returns effective catalog.
+func Current() Catalog {
+	m.RLock()
+	defer m.RUnlock()
+
+	releases := make([]Release, len(current.Releases))
+	copy(releases, current.Releases)
+
+	return Catalog{Releases: releases}
+}
+
+// Versions returns supported kubernetes versions in ascending order.
+func Versions() []string {
+	m.RLock()
+	defer m.RUnlock()
+
+	versions := make([]string, 0, len(current.Releases))
+	for _, r := range current.Releases {
+		versions = append(versions, r.Version)
+	}
+
+	return versions
+}
+
+// Get returns release of the kubernetes version, the latest release of the same
+// minor version is returned for patch versions missing in the catalog.
+func Get(version string) (Release, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return Release{}, errors.Wrapf(ErrUnsupportedVersion, "%q", version)
+	}
+
+	m.RLock()
+	defer m.RUnlock()
+
+	var (
+		found bool
+		match Release
+	)
+	for _, r := range current.Releases {
+		if r.Version == version {
+			return r, nil
+		}
+
+		rv, err := semver.NewVersion(r.Version)
+		if err != nil {
+			continue
+		}
+		if rv.Major() == v.Major() && rv.Minor() == v.Minor() {
+			found, match = true, r
+		}
+	}
+
+	if !found {
+		return Release{}, errors.Wrapf(sgerrors.ErrNotFound, "release %s", version)
+	}
+
+	return match, nil
+}
+
+// ValidateComponents checks versions of components are compatible with
+// the kubernetes version, empty versions are not checked.
+func ValidateComponents(version, docker, helm string) error {
+	r, err := Get(version)
+	if err != nil {
+		return errors.Wrapf(ErrUnsupportedVersion, "kubernetes %s", version)
+	}
+
+	if docker != "" && !contains(r.Docker, docker) {
+		return errors.Wrapf(ErrUnsupportedVersion, "docker %s for kubernetes %s, supported %v",
+			docker, version, r.Docker)
+	}
+
+	if helm != "" && !contains(r.Helm, helm) {
+		return errors.Wrapf(ErrUnsupportedVersion, "helm %s for kubernetes %s, supported %v",
+			helm, version, r.Helm)
+	}
+
+	return nil
+}
+
+func contains(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
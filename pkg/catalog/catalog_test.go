@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func resetCatalog(t *testing.T) {
+	c, err := Parse([]byte(Bundled))
+	require.Nil(t, err)
+	require.Nil(t, Set(c))
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        string
+		expected    []string
+		hasErr      bool
+	}{
+		{
+			description: "invalid json",
+			data:        `{`,
+			hasErr:      true,
+		},
+		{
+			description: "no releases",
+			data:        `{"releases":[]}`,
+			hasErr:      true,
+		},
+		{
+			description: "invalid version",
+			data:        `{"releases":[{"version":"latest","kubeadm":"1.15.1","docker":["18.06.3"]}]}`,
+			hasErr:      true,
+		},
+		{
+			description: "no kubeadm",
+			data:        `{"releases":[{"version":"1.15.1","docker":["18.06.3"]}]}`,
+			hasErr:      true,
+		},
+		{
+			description: "no runtime",
+			data:        `{"releases":[{"version":"1.15.1","kubeadm":"1.15.1"}]}`,
+			hasErr:      true,
+		},
+		{
+			description: "duplicate",
+			data: `{"releases":[{"version":"1.15.1","kubeadm":"1.15.1","docker":["18.06.3"]},
+				{"version":"1.15.1","kubeadm":"1.15.1","docker":["18.06.3"]}]}`,
+			hasErr: true,
+		},
+		{
+			description: "sorted",
+			data: `{"releases":[{"version":"1.15.1","kubeadm":"1.15.1","docker":["18.06.3"]},
+				{"version":"1.9.11","kubeadm":"1.15.1","containerd":["1.2.6"]}]}`,
+			expected: []string{"1.9.11", "1.15.1"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		c, err := Parse([]byte(testCase.data))
+		if testCase.hasErr {
+			require.Equal(t, ErrInvalidCatalog, errors.Cause(err))
+			continue
+		}
+
+		require.Nil(t, err)
+		for i, r := range c.Releases {
+			require.Equal(t, testCase.expected[i], r.Version)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	defer resetCatalog(t)
+	require.Nil(t, Set(Catalog{Releases: []Release{
+		{Version: "1.14.3", Kubeadm: "1.15.1", Docker: []string{"18.06.3"}, Etcd: "3.3.10"},
+		{Version: "1.14.1", Kubeadm: "1.14.1", Docker: []string{"18.06.3"}},
+		{Version: "1.15.1", Kubeadm: "1.15.1", Docker: []string{"18.09.7"}, Helm: []string{"2.14.3"}},
+	}}))
+
+	require.Equal(t, []string{"1.14.1", "1.14.3", "1.15.1"}, Versions())
+
+	r, err := Get("1.14.1")
+	require.Nil(t, err)
+	require.Equal(t, "1.14.1", r.Kubeadm)
+
+	r, err = Get("1.14.2")
+	require.Nil(t, err, "latest patch release must be used")
+	require.Equal(t, "1.14.3", r.Version)
+
+	_, err = Get("1.13.7")
+	require.True(t, sgerrors.IsNotFound(err))
+
+	_, err = Get("")
+	require.Equal(t, ErrUnsupportedVersion, errors.Cause(err))
+
+	require.Nil(t, ValidateComponents("1.15.1", "18.09.7", "2.14.3"))
+	require.Nil(t, ValidateComponents("1.15.1", "", ""))
+	require.NotNil(t, ValidateComponents("1.15.1", "18.06.3", ""))
+	require.NotNil(t, ValidateComponents("1.15.1", "", "2.11.0"))
+	require.NotNil(t, ValidateComponents("1.13.7", "", ""))
+}
@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Register(r *mux.Router) {
+	r.HandleFunc("/versions", h.GetCatalog).Methods(http.MethodGet)
+	r.HandleFunc("/versions", h.PutCatalog).Methods(http.MethodPut)
+	r.HandleFunc("/versions/refresh", h.RefreshCatalog).Methods(http.MethodPost)
+	r.HandleFunc("/versions/{version}", h.GetRelease).Methods(http.MethodGet)
+}
+
+func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, Current())
+}
+
+// GetRelease returns versions of components compatible with the kubernetes version.
+func (h *Handler) GetRelease(w http.ResponseWriter, r *http.Request) {
+	release, err := Get(mux.Vars(r)["version"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, release)
+}
+
+func (h *Handler) PutCatalog(w http.ResponseWriter, r *http.Request) {
+	c := Catalog{}
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Update(r.Context(), c); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, c)
+}
+
+// RefreshCatalog replaces the catalog with one served by the configured mirror.
+func (h *Handler) RefreshCatalog(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Refresh(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, c)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch errors.Cause(err) {
+	case sgerrors.ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidCatalog, ErrUnsupportedVersion:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case ErrNoMirror:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
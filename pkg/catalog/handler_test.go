@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/storage/memory"
+)
+
+func TestHandler(t *testing.T) {
+	defer resetCatalog(t)
+
+	router := mux.NewRouter()
+	NewHandler(NewService(DefaultStoragePrefix, memory.NewInMemoryRepository(), "", "")).Register(router)
+
+	testCases := []struct {
+		description  string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "release",
+			method:       http.MethodGet,
+			url:          "/versions/1.15.1",
+			expectedCode: http.StatusOK,
+			expectedBody: `"etcd":"3.3.10"`,
+		},
+		{
+			description:  "unknown release",
+			method:       http.MethodGet,
+			url:          "/versions/1.9.1",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			description:  "invalid catalog",
+			method:       http.MethodPut,
+			url:          "/versions",
+			body:         `{"releases":[]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "update",
+			method:       http.MethodPut,
+			url:          "/versions",
+			body:         testCatalog,
+			expectedCode: http.StatusOK,
+		},
+		{
+			description:  "catalog",
+			method:       http.MethodGet,
+			url:          "/versions",
+			expectedCode: http.StatusOK,
+			expectedBody: `"version":"1.16.0"`,
+		},
+		{
+			description:  "no mirror",
+			method:       http.MethodPost,
+			url:          "/versions/refresh",
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		req, _ := http.NewRequest(testCase.method, testCase.url, bytes.NewBufferString(testCase.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, testCase.expectedCode, rec.Code, rec.Body.String())
+		require.Contains(t, rec.Body.String(), testCase.expectedBody)
+	}
+}
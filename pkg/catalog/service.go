@@ -0,0 +1,144 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+)
+
+const (
+	DefaultStoragePrefix = "/supergiant/catalog/"
+
+	catalogKey     = "current"
+	refreshTimeout = time.Second * 30
+)
+
+var ErrNoMirror = errors.New("catalog mirror is not configured")
+
+// Service keeps the catalog in storage. Empty storage is seeded from the file
+// or bundled catalog, the catalog can be refreshed from a mirror.
+type Service struct {
+	prefix    string
+	storage   storage.Interface
+	seedFile  string
+	mirrorURL string
+	client    *http.Client
+}
+
+func NewService(prefix string, s storage.Interface, seedFile, mirrorURL string) *Service {
+	return &Service{
+		prefix:    prefix,
+		storage:   s,
+		seedFile:  seedFile,
+		mirrorURL: mirrorURL,
+		client: &http.Client{
+			Timeout: refreshTimeout,
+		},
+	}
+}
+
+// Load makes stored catalog effective, storage gets seeded when empty.
+func (s *Service) Load(ctx context.Context) error {
+	data, err := s.storage.Get(ctx, s.prefix, catalogKey)
+	if err != nil && !sgerrors.IsNotFound(err) {
+		return errors.Wrap(err, "get catalog")
+	}
+
+	if sgerrors.IsNotFound(err) {
+		c, err := s.seed()
+		if err != nil {
+			return err
+		}
+
+		return s.Update(ctx, c)
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return errors.Wrap(err, "stored catalog")
+	}
+
+	return Set(c)
+}
+
+// Update stores the catalog and makes it effective.
+func (s *Service) Update(ctx context.Context, c Catalog) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Put(ctx, s.prefix, catalogKey, data); err != nil {
+		return errors.Wrap(err, "store catalog")
+	}
+
+	return Set(c)
+}
+
+// Refresh replaces the catalog with one served by the mirror.
+func (s *Service) Refresh(ctx context.Context) (Catalog, error) {
+	if s.mirrorURL == "" {
+		return Catalog{}, ErrNoMirror
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.mirrorURL, nil)
+	if err != nil {
+		return Catalog{}, errors.Wrap(err, "mirror request")
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return Catalog{}, errors.Wrapf(err, "get catalog from %s", s.mirrorURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Catalog{}, errors.Errorf("get catalog from %s: %s", s.mirrorURL, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Catalog{}, errors.Wrapf(err, "read catalog from %s", s.mirrorURL)
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	logrus.Infof("catalog: refreshed %d releases from %s", len(c.Releases), s.mirrorURL)
+
+	return c, s.Update(ctx, c)
+}
+
+func (s *Service) seed() (Catalog, error) {
+	if s.seedFile == "" {
+		return Parse([]byte(Bundled))
+	}
+
+	data, err := ioutil.ReadFile(s.seedFile)
+	if err != nil {
+		return Catalog{}, errors.Wrap(err, "read catalog seed file")
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return Catalog{}, errors.Wrapf(err, "seed file %s", s.seedFile)
+	}
+
+	logrus.Infof("catalog: seeded %d releases from %s", len(c.Releases), s.seedFile)
+
+	return c, nil
+}
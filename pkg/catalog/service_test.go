@@ -0,0 +1,72 @@
+package catalog
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/storage/memory"
+)
+
+const testCatalog = `{"releases":[{"version":"1.16.0","kubeadm":"1.16.0","docker":["18.09.7"],"helm":["2.14.3"]}]}`
+
+func TestServiceLoad(t *testing.T) {
+	defer resetCatalog(t)
+
+	seed, err := ioutil.TempFile("", "catalog")
+	require.Nil(t, err)
+	defer os.Remove(seed.Name())
+	_, err = seed.WriteString(testCatalog)
+	require.Nil(t, err)
+	require.Nil(t, seed.Close())
+
+	repo := memory.NewInMemoryRepository()
+	svc := NewService(DefaultStoragePrefix, repo, seed.Name(), "")
+	require.Nil(t, svc.Load(context.Background()))
+	require.Equal(t, []string{"1.16.0"}, Versions(), "storage must be seeded from the file")
+
+	resetCatalog(t)
+	require.Nil(t, NewService(DefaultStoragePrefix, repo, "", "").Load(context.Background()))
+	require.Equal(t, []string{"1.16.0"}, Versions(), "stored catalog must be loaded")
+
+	require.Nil(t, NewService(DefaultStoragePrefix, memory.NewInMemoryRepository(), "", "").Load(context.Background()))
+	require.Len(t, Versions(), 5, "bundled catalog must be used")
+
+	err = NewService(DefaultStoragePrefix, memory.NewInMemoryRepository(), "/nonexistent", "").Load(context.Background())
+	require.NotNil(t, err)
+}
+
+func TestServiceRefresh(t *testing.T) {
+	defer resetCatalog(t)
+
+	body := testCatalog
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer mirror.Close()
+
+	_, err := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository(), "", "").Refresh(context.Background())
+	require.Equal(t, ErrNoMirror, err)
+
+	repo := memory.NewInMemoryRepository()
+	svc := NewService(DefaultStoragePrefix, repo, "", mirror.URL)
+	c, err := svc.Refresh(context.Background())
+	require.Nil(t, err)
+	require.Len(t, c.Releases, 1)
+	require.Equal(t, []string{"1.16.0"}, Versions())
+
+	data, err := repo.Get(context.Background(), DefaultStoragePrefix, catalogKey)
+	require.Nil(t, err)
+	require.Contains(t, string(data), "1.16.0", "refreshed catalog must be stored")
+
+	body = `{"releases":[]}`
+	_, err = svc.Refresh(context.Background())
+	require.Equal(t, ErrInvalidCatalog, errors.Cause(err))
+	require.Equal(t, []string{"1.16.0"}, Versions(), "invalid catalog must not be applied")
+}
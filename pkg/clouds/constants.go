@@ -13,12 +13,12 @@ const (
 	GCE          Name = "gce"
 	Azure        Name = "azure"
 	OpenStack    Name = "openstack"
+	BYO          Name = "byo"       // machines the user already has
+	Simulator    Name = "simulator" // machines that exist in memory only
 
 	Unknown Name = "unknown"
 )
 
-var versions = []string{"1.11.5", "1.12.7", "1.13.7", "1.14.3", "1.15.1"}
-
 func ToProvider(name string) (Name, error) {
 	switch name {
 	case string(AWS):
@@ -31,19 +31,14 @@ func ToProvider(name string) (Name, error) {
 		return GCE, nil
 	case string(OpenStack):
 		return OpenStack, nil
+	case string(BYO):
+		return BYO, nil
+	case string(Simulator):
+		return Simulator, nil
 	}
 	return Unknown, errors.New("invalid provider")
 }
 
-func GetVersions() []string {
-	if versions == nil {
-		return nil
-	}
-	c := make([]string, len(versions))
-	copy(c, versions)
-	return c
-}
-
 const (
 	OSUser = "supergiant"
 
@@ -79,7 +74,8 @@ const (
 	GCENetworkLink = "gceNetworkLink"
 	GCENetworkName = "gceNetworkName"
 
-	GCEImageFamily = "gceImageFamily"
+	GCEImageFamily  = "gceImageFamily"
+	GCEImageProject = "gceImageProject"
 
 	TagClusterID         = "supergiant.io/cluster-id"
 	TagNodeName          = "Name"
@@ -113,4 +109,52 @@ const (
 	AzureClientSecret   = "clientSecret"
 	AzureVolumeSize     = "azureVolumeSize"
 	AzureVNetCIDR       = "azureVNetCIDR"
+
+	// Credentials of openstack account are the ones of openstack rc file,
+	// the rest is the infrastructure created for the kube.
+	OpenStackAuthURL           = "authUrl"
+	OpenStackUserName          = "userName"
+	OpenStackPassword          = "password"
+	OpenStackDomainName        = "domainName"
+	OpenStackProjectName       = "projectName"
+	OpenStackImage             = "openstackImage"
+	OpenStackSubnetCIDR        = "openstackSubnetCidr"
+	OpenStackExternalNetworkID = "openstackExternalNetworkId"
+	OpenStackNetworkID         = "openstackNetworkId"
+	OpenStackNetworkName       = "openstackNetworkName"
+	OpenStackSubnetID          = "openstackSubnetId"
+	OpenStackRouterID          = "openstackRouterId"
+	OpenStackSecurityGroupID   = "openstackSecurityGroupId"
+	OpenStackSecurityGroupName = "openstackSecurityGroupName"
+	OpenStackKeyPairName       = "openstackKeyPairName"
+	OpenStackLoadBalancerID    = "openstackLoadBalancerId"
+	OpenStackPoolID            = "openstackPoolId"
+	OpenStackLBFloatingIPID    = "openstackLbFloatingIpId"
+
+	// Packet account is api key of the user and the project where
+	// devices are created, the rest is the infrastructure of the kube.
+	PacketAPIKey      = "apiKey"
+	PacketProjectID   = "projectId"
+	PacketSSHKeyID    = "packetSshKeyId"
+	PacketElasticIPID = "packetElasticIpId"
+	PacketElasticIP   = "packetElasticIp"
+
+	// Hosts of byo kube are node profiles with these keys, api endpoint
+	// is a cloud specific setting e.g. a virtual ip in front of masters.
+	BYOPublicIP    = "publicIp"
+	BYOPrivateIP   = "privateIp"
+	BYOSSHUser     = "sshUser"
+	BYOSSHKey      = "sshKey"
+	BYOAPIEndpoint = "byoApiEndpoint"
+
+	// Simulator account chooses how scripts of simulated machines
+	// are run, the network is the only infrastructure of the kube.
+	SimulatorRunner    = "runner"
+	SimulatorNetworkID = "simulatorNetworkId"
+
+	// SimulatorRunnerDry records scripts of the machine, it is the default.
+	SimulatorRunnerDry = "dry"
+	// SimulatorRunnerLocal executes scripts of all machines on the host
+	// where control runs, it is meant for demo environments only.
+	SimulatorRunnerLocal = "local"
 )
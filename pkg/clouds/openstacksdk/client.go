@@ -0,0 +1,180 @@
+package openstacksdk
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// Service types of the catalog that are used by supergiant
+const (
+	ComputeService = "compute"
+
+	defaultDomain = "Default"
+)
+
+// Credentials are taken from cloud account, they are the same as
+// OS_* variables of openstack rc file.
+type Credentials struct {
+	AuthURL     string
+	UserName    string
+	Password    string
+	DomainName  string
+	ProjectName string
+	Region      string
+}
+
+// CredentialsFrom takes credentials from cloud account.
+func CredentialsFrom(creds map[string]string) Credentials {
+	return Credentials{
+		AuthURL:     creds[clouds.OpenStackAuthURL],
+		UserName:    creds[clouds.OpenStackUserName],
+		Password:    creds[clouds.OpenStackPassword],
+		DomainName:  creds[clouds.OpenStackDomainName],
+		ProjectName: creds[clouds.OpenStackProjectName],
+	}
+}
+
+// IsNotFound tells whether resource does not exist anymore.
+func IsNotFound(err error) bool {
+	if sgerrors.IsNotFound(err) {
+		return true
+	}
+	_, ok := errors.Cause(err).(gophercloud.ErrDefault404)
+	return ok
+}
+
+// IsConflict tells whether resource with the same name exists already.
+func IsConflict(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case gophercloud.ErrDefault409:
+		return true
+	case gophercloud.ErrUnexpectedResponseCode:
+		return e.Actual == http.StatusConflict
+	}
+	return false
+}
+
+// Client makes service clients of the project of the user,
+// endpoints of the services are looked up in the region of the client.
+type Client struct {
+	provider *gophercloud.ProviderClient
+	region   string
+}
+
+// New authenticates user in keystone v3 and returns client scoped to the project.
+func New(ctx context.Context, creds Credentials) (*Client, error) {
+	if creds.AuthURL == "" || creds.UserName == "" || creds.Password == "" || creds.ProjectName == "" {
+		return nil, sgerrors.ErrInvalidCredentials
+	}
+
+	domain := creds.DomainName
+	if domain == "" {
+		domain = defaultDomain
+	}
+
+	provider, err := openstack.NewClient(creds.AuthURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticate")
+	}
+	provider.Context = ctx
+
+	err = openstack.AuthenticateV3(provider, &gophercloud.AuthOptions{
+		IdentityEndpoint: creds.AuthURL,
+		Username:         creds.UserName,
+		Password:         creds.Password,
+		DomainName:       domain,
+		TenantName:       creds.ProjectName,
+	}, gophercloud.EndpointOpts{})
+	if err != nil {
+		if _, ok := errors.Cause(err).(gophercloud.ErrDefault401); ok {
+			return nil, errors.Wrap(sgerrors.ErrInvalidCredentials, err.Error())
+		}
+		return nil, errors.Wrap(err, "authenticate")
+	}
+
+	return &Client{
+		provider: provider,
+		region:   creds.Region,
+	}, nil
+}
+
+// WithRegion returns client that uses endpoints of another region.
+func (c *Client) WithRegion(region string) *Client {
+	clone := *c
+	clone.region = region
+	return &clone
+}
+
+// Regions returns regions where compute service is available.
+func (c *Client) Regions() ([]string, error) {
+	result, ok := c.provider.GetAuthResult().(tokens.CreateResult)
+	if !ok {
+		return nil, errors.New("no service catalog")
+	}
+
+	catalog, err := result.ExtractServiceCatalog()
+	if err != nil {
+		return nil, errors.Wrap(err, "service catalog")
+	}
+
+	regions := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, entry := range catalog.Entries {
+		if entry.Type != ComputeService {
+			continue
+		}
+		for _, e := range entry.Endpoints {
+			region := e.RegionID
+			if region == "" {
+				region = e.Region
+			}
+			if e.Interface == "public" && !seen[region] {
+				seen[region] = true
+				regions = append(regions, region)
+			}
+		}
+	}
+
+	sort.Strings(regions)
+	return regions, nil
+}
+
+func (c *Client) endpointOpts() gophercloud.EndpointOpts {
+	return gophercloud.EndpointOpts{
+		Region: c.region,
+	}
+}
+
+// Compute returns client of nova.
+func (c *Client) Compute() (*gophercloud.ServiceClient, error) {
+	client, err := openstack.NewComputeV2(c.provider, c.endpointOpts())
+	return client, errors.Wrapf(err, "compute in region %s", c.region)
+}
+
+// Network returns client of neutron.
+func (c *Client) Network() (*gophercloud.ServiceClient, error) {
+	client, err := openstack.NewNetworkV2(c.provider, c.endpointOpts())
+	return client, errors.Wrapf(err, "network in region %s", c.region)
+}
+
+// Image returns client of glance.
+func (c *Client) Image() (*gophercloud.ServiceClient, error) {
+	client, err := openstack.NewImageServiceV2(c.provider, c.endpointOpts())
+	return client, errors.Wrapf(err, "image in region %s", c.region)
+}
+
+// LoadBalancer returns client of octavia.
+func (c *Client) LoadBalancer() (*gophercloud.ServiceClient, error) {
+	client, err := openstack.NewLoadBalancerV2(c.provider, c.endpointOpts())
+	return client, errors.Wrapf(err, "load balancer in region %s", c.region)
+}
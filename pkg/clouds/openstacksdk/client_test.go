@@ -0,0 +1,113 @@
+package openstacksdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds/openstacksdk/fixtures"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func TestNew(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	fixtures.HandleAuth(t)
+
+	testCases := []struct {
+		description string
+		creds       Credentials
+		expectedErr error
+	}{
+		{
+			description: "no credentials",
+			expectedErr: sgerrors.ErrInvalidCredentials,
+		},
+		{
+			description: "wrong password",
+			creds: Credentials{
+				AuthURL:     fixtures.AuthURL(),
+				UserName:    fixtures.UserName,
+				Password:    "wrong",
+				ProjectName: fixtures.ProjectName,
+			},
+			expectedErr: sgerrors.ErrInvalidCredentials,
+		},
+		{
+			description: "unversioned auth url",
+			creds: Credentials{
+				AuthURL:     th.Endpoint(),
+				UserName:    fixtures.UserName,
+				Password:    fixtures.Password,
+				ProjectName: fixtures.ProjectName,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := New(context.Background(), tc.creds)
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if err == nil && c.provider.Token() == "" {
+			t.Errorf("%s: token is empty", tc.description)
+		}
+	}
+}
+
+func TestRegions(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	fixtures.HandleAuth(t, "RegionTwo", "RegionOne")
+
+	c, err := New(context.Background(), Credentials{
+		AuthURL:     fixtures.AuthURL(),
+		UserName:    fixtures.UserName,
+		Password:    fixtures.Password,
+		ProjectName: fixtures.ProjectName,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	regions, err := c.Regions()
+	if err != nil || len(regions) != 2 || regions[0] != "RegionOne" || regions[1] != "RegionTwo" {
+		t.Errorf("wrong regions %v error %v", regions, err)
+	}
+
+	if _, err := c.WithRegion("RegionThree").Compute(); err == nil {
+		t.Errorf("region without compute must fail")
+	}
+
+	compute, err := c.WithRegion("RegionTwo").Compute()
+	if err != nil || compute.Endpoint != th.Endpoint()+"compute/" {
+		t.Errorf("wrong endpoint of compute %v error %v", compute, err)
+	}
+
+	lb, err := c.WithRegion("RegionTwo").LoadBalancer()
+	if err != nil || lb.ResourceBase != th.Endpoint()+"load-balancer/v2.0/" {
+		t.Errorf("wrong endpoint of load balancer %v error %v", lb, err)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	notFound := gophercloud.ErrDefault404{}
+	notFound.Actual = http.StatusNotFound
+
+	conflict := gophercloud.ErrDefault409{}
+	conflict.Actual = http.StatusConflict
+
+	if !IsNotFound(errors.Wrap(notFound, "get server")) || !IsNotFound(sgerrors.ErrNotFound) {
+		t.Errorf("not found errors must be recognized")
+	}
+
+	if IsNotFound(conflict) || !IsConflict(errors.Wrap(conflict, "create key pair")) {
+		t.Errorf("conflict must be recognized")
+	}
+}
@@ -0,0 +1,119 @@
+// Package fixtures registers responses of openstack apis on the mux
+// of gophercloud testhelper, th.SetupHTTP must be called first.
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+)
+
+// Credentials of the user that keystone fixture accepts
+const (
+	UserName    = "admin"
+	Password    = "secret"
+	ProjectName = "demo"
+	Region      = "RegionOne"
+
+	// ComputePrefix is path of compute service in the catalog
+	ComputePrefix = "/compute"
+)
+
+// AuthURL is keystone url of the fixture.
+func AuthURL() string {
+	return th.Endpoint() + "v3"
+}
+
+// HandleAuth issues token with catalog where services of the regions
+// point to the mux, wrong password is rejected with 401.
+func HandleAuth(t *testing.T, regions ...string) {
+	if len(regions) == 0 {
+		regions = []string{Region}
+	}
+
+	catalog := make([]map[string]interface{}, 0)
+	for _, service := range []string{"compute", "network", "image", "load-balancer"} {
+		endpoints := make([]map[string]string, 0, len(regions))
+		for _, region := range regions {
+			endpoints = append(endpoints, map[string]string{
+				"id":        region + "-" + service,
+				"interface": "public",
+				"region":    region,
+				"region_id": region,
+				"url":       th.Endpoint() + service + "/",
+			})
+		}
+		catalog = append(catalog, map[string]interface{}{
+			"type":      service,
+			"name":      service,
+			"endpoints": endpoints,
+		})
+	}
+
+	th.Mux.HandleFunc("/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodPost)
+
+		req := struct {
+			Auth struct {
+				Identity struct {
+					Password struct {
+						User struct {
+							Name     string `json:"name"`
+							Password string `json:"password"`
+						} `json:"user"`
+					} `json:"password"`
+				} `json:"identity"`
+			} `json:"auth"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode auth request: %v", err)
+		}
+
+		user := req.Auth.Identity.Password.User
+		if user.Name != UserName || user.Password != Password {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"code": 401, "title": "Unauthorized"}}`)
+			return
+		}
+
+		w.Header().Set("X-Subject-Token", "token-of-"+user.Name)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token": map[string]interface{}{
+				"expires_at": "2030-01-01T00:00:00.000000Z",
+				"catalog":    catalog,
+			},
+		})
+	})
+}
+
+// HandleListFlavors lists m1.small and m1.medium flavors of compute
+// service that is served under the prefix.
+func HandleListFlavors(t *testing.T, prefix string) {
+	th.Mux.HandleFunc(prefix+"/flavors/detail", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"flavors": [
+			{"id": "1", "name": "m1.small", "ram": 2048, "vcpus": 1, "disk": 20},
+			{"id": "2", "name": "m1.medium", "ram": 4096, "vcpus": 2, "disk": 40}
+		]}`)
+	})
+}
+
+// HandleListAvailabilityZones lists available zone nova and the one that is down.
+func HandleListAvailabilityZones(t *testing.T, prefix string) {
+	th.Mux.HandleFunc(prefix+"/os-availability-zone", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"availabilityZoneInfo": [
+			{"zoneName": "nova", "zoneState": {"available": true}, "hosts": null},
+			{"zoneName": "maintenance", "zoneState": {"available": false}, "hosts": null}
+		]}`)
+	})
+}
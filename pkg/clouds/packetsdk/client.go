@@ -0,0 +1,43 @@
+package packetsdk
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// consumerToken tells requests of supergiant apart from other clients of packet api.
+const consumerToken = "supergiant"
+
+// BaseURL is the url of packet api, it is changed by tests only.
+var BaseURL = "https://api.packet.net/"
+
+// New returns client of packet api that acts on behalf of the owner of api key.
+func New(apiKey string) *packngo.Client {
+	client, _ := packngo.NewClientWithBaseURL(consumerToken, apiKey, &http.Client{
+		Timeout: time.Minute,
+	}, BaseURL)
+	return client
+}
+
+// IsNotFound tells whether resource does not exist anymore.
+func IsNotFound(err error) bool {
+	return sgerrors.IsNotFound(err) || statusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized tells whether api key has been rejected by packet api.
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+func statusCode(err error) int {
+	apiErr, ok := errors.Cause(err).(*packngo.ErrorResponse)
+	if !ok || apiErr.Response == nil {
+		return 0
+	}
+	return apiErr.Response.StatusCode
+}
@@ -0,0 +1,58 @@
+package packetsdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func TestNew(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors": ["Invalid authentication token"]}`)
+			return
+		}
+
+		if r.URL.Path != "/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": ["Not found"]}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"id": "p1", "name": "kubes"}`)
+	}))
+	defer server.Close()
+
+	baseURL := BaseURL
+	BaseURL = server.URL
+	defer func() { BaseURL = baseURL }()
+
+	project, _, err := New("key").Projects.Get("p1", nil)
+	if err != nil || project.Name != "kubes" {
+		t.Errorf("wrong project %v error %v", project, err)
+	}
+
+	if _, _, err := New("key").Projects.Get("p2", nil); !IsNotFound(err) || IsUnauthorized(err) {
+		t.Errorf("expected not found error actual %v", err)
+	}
+
+	if _, _, err := New("wrong").Projects.Get("p1", nil); !IsUnauthorized(err) || IsNotFound(err) {
+		t.Errorf("expected unauthorized error actual %v", err)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	if !IsNotFound(errors.Wrap(sgerrors.ErrNotFound, "get device")) {
+		t.Errorf("not found error of supergiant must be recognized")
+	}
+
+	if IsNotFound(nil) || IsNotFound(errors.New("get device")) || IsUnauthorized(errors.New("get device")) {
+		t.Errorf("other errors must not be recognized")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,6 +21,9 @@ import (
 
 	"github.com/supergiant/control/pkg/account"
 	"github.com/supergiant/control/pkg/api"
+	"github.com/supergiant/control/pkg/backup"
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/catalog"
 	"github.com/supergiant/control/pkg/jwt"
 	"github.com/supergiant/control/pkg/kube"
 	"github.com/supergiant/control/pkg/profile"
@@ -37,24 +41,36 @@ import (
 	"github.com/supergiant/control/pkg/workflows/steps/authorizedkeys"
 	"github.com/supergiant/control/pkg/workflows/steps/azure"
 	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
+	"github.com/supergiant/control/pkg/workflows/steps/byo"
 	"github.com/supergiant/control/pkg/workflows/steps/certificates"
+	"github.com/supergiant/control/pkg/workflows/steps/certs"
 	"github.com/supergiant/control/pkg/workflows/steps/cloudcontroller"
+	"github.com/supergiant/control/pkg/workflows/steps/cloudinit"
 	"github.com/supergiant/control/pkg/workflows/steps/clustercheck"
 	"github.com/supergiant/control/pkg/workflows/steps/cni"
 	"github.com/supergiant/control/pkg/workflows/steps/configmap"
+	"github.com/supergiant/control/pkg/workflows/steps/containerd"
+	"github.com/supergiant/control/pkg/workflows/steps/containerruntime"
+	"github.com/supergiant/control/pkg/workflows/steps/crio"
 	"github.com/supergiant/control/pkg/workflows/steps/dashboard"
 	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
 	"github.com/supergiant/control/pkg/workflows/steps/docker"
 	"github.com/supergiant/control/pkg/workflows/steps/downloadk8sbinary"
 	"github.com/supergiant/control/pkg/workflows/steps/drain"
+	"github.com/supergiant/control/pkg/workflows/steps/etcd"
 	"github.com/supergiant/control/pkg/workflows/steps/evacuate"
 	"github.com/supergiant/control/pkg/workflows/steps/gce"
+	"github.com/supergiant/control/pkg/workflows/steps/httpproxy"
 	"github.com/supergiant/control/pkg/workflows/steps/install_app"
 	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
 	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
+	"github.com/supergiant/control/pkg/workflows/steps/masters"
 	"github.com/supergiant/control/pkg/workflows/steps/network"
+	"github.com/supergiant/control/pkg/workflows/steps/openstack"
+	"github.com/supergiant/control/pkg/workflows/steps/packet"
 	"github.com/supergiant/control/pkg/workflows/steps/poststart"
 	"github.com/supergiant/control/pkg/workflows/steps/prometheus"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
 	"github.com/supergiant/control/pkg/workflows/steps/ssh"
 	"github.com/supergiant/control/pkg/workflows/steps/storageclass"
 	"github.com/supergiant/control/pkg/workflows/steps/tiller"
@@ -106,6 +122,18 @@ type Config struct {
 	LogDir       string
 
 	SpawnInterval time.Duration
+	// AutoscaleInterval is a period of checking autoscaled node pools,
+	// zero disables autoscaling.
+	AutoscaleInterval time.Duration
+	// ReconcileInterval is a period of applying specs of kubes,
+	// zero disables reconciliation.
+	ReconcileInterval time.Duration
+	// HealthCheckInterval is a period of checking health of kubes,
+	// zero disables health monitoring.
+	HealthCheckInterval time.Duration
+	// CertCheckInterval is a period of gathering expiry dates of certificates
+	// of kubes, zero disables checks.
+	CertCheckInterval time.Duration
 
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
@@ -115,6 +143,25 @@ type Config struct {
 
 	ProxiesPortRange proxy.PortRange
 
+	// HelmRepositories replace urls of default chart repositories by name
+	HelmRepositories map[string]string
+
+	// ExternalURL is an url machines reach control with, they report
+	// progress of cloud-init bootstrap to it
+	ExternalURL string
+
+	// CatalogFile seeds empty storage with the version catalog,
+	// bundled catalog is used otherwise.
+	CatalogFile string
+	// CatalogMirror is an url the version catalog is refreshed from.
+	CatalogMirror string
+
+	// BackupStore is a directory or s3:// uri etcd snapshots are kept in.
+	BackupStore string
+	// BackupScheduleInterval is a period of checking backup policies of kubes,
+	// zero disables scheduled backups.
+	BackupScheduleInterval time.Duration
+
 	Version string
 }
 
@@ -177,6 +224,13 @@ func validate(cfg *Config) error {
 		return errors.New("spawn interval must not be 0")
 	}
 
+	if cfg.ExternalURL != "" {
+		u, err := url.Parse(cfg.ExternalURL)
+		if err != nil || u.Host == "" {
+			return errors.Errorf("external url %s must be absolute", cfg.ExternalURL)
+		}
+	}
+
 	return nil
 }
 
@@ -216,11 +270,28 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 		return nil, errors.Wrap(err, "templatemanager: init")
 	}
 
+	templateService := templatemanager.NewService(templatemanager.DefaultStoragePrefix, repository)
+	if err := templateService.Load(context.Background()); err != nil {
+		return nil, errors.Wrap(err, "templatemanager: load overrides")
+	}
+	templateHandler := templatemanager.NewHandler(templateService)
+	templateHandler.Register(protectedAPI)
+
+	catalogService := catalog.NewService(catalog.DefaultStoragePrefix, repository, cfg.CatalogFile, cfg.CatalogMirror)
+	if err := catalogService.Load(context.Background()); err != nil {
+		return nil, errors.Wrap(err, "catalog: load")
+	}
+	catalogHandler := catalog.NewHandler(catalogService)
+	catalogHandler.Register(protectedAPI)
+
 	digitalocean.Init()
 	certificates.Init()
 	authorizedkeys.Init()
 	cni.Init()
 	docker.Init()
+	containerd.Init()
+	crio.Init()
+	containerruntime.Init()
 	downloadk8sbinary.Init()
 	kubelet.Init()
 	poststart.Init()
@@ -228,20 +299,34 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 	ssh.Init()
 	network.Init()
 	clustercheck.Init()
+
+	backupStore, err := backup.NewStore(cfg.BackupStore)
+	if err != nil {
+		return nil, errors.Wrap(err, "backup: store")
+	}
+
 	cloudcontroller.Init()
 	prometheus.Init()
 	dashboard.Init()
 	gce.Init(accountService)
 	storageclass.Init()
 	drain.Init()
+	masters.Init()
 	kubeadm.Init()
 	bootstraptoken.Init()
 	configmap.Init()
 	upgrade.Init()
+	etcd.Init(backupStore)
+	certs.Init()
 	uncordon.Init()
 	evacuate.Init()
 	install_app.Init()
 	helm.Init()
+	httpproxy.Init()
+	byo.Init()
+
+	bootstrapService := bootstrap.NewService(bootstrap.DefaultStoragePrefix, repository)
+	cloudinit.Init(cfg.ExternalURL, bootstrapService)
 
 	amazon.InitFindAMI(amazon.GetEC2)
 	amazon.InitImportKeyPair(amazon.GetEC2)
@@ -266,6 +351,7 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 	amazon.InitCreateLoadBalancer(amazon.GetELB)
 	amazon.InitDeleteLoadBalancer(amazon.GetELB)
 	amazon.InitRegisterInstance(amazon.GetELB)
+	amazon.InitDeregisterInstance(amazon.GetELB)
 	amazon.InitImportClusterStep(amazon.GetEC2)
 	amazon.InitImportSubnetDescriber(amazon.GetEC2)
 	amazon.InitImportInternetGatewayStep(amazon.GetEC2)
@@ -273,6 +359,9 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 	amazon.InitCreateTagsStep(amazon.GetEC2)
 	apply.Init()
 	azure.Init()
+	openstack.Init()
+	packet.Init()
+	simulator.Init()
 
 	workflows.Init()
 
@@ -284,7 +373,7 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 		return nil, errors.Wrap(err, "new helm service")
 	}
 	if coldstart, err := userService.IsColdStart(context.Background()); err == nil && coldstart {
-		go ensureHelmRepositories(helmService)
+		go ensureHelmRepositories(helmService, cfg.HelmRepositories)
 	} else if err != nil {
 		return nil, err
 	}
@@ -309,6 +398,43 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 		repository, apiProxy, cfg.LogDir)
 	kubeHandler.Register(protectedAPI)
 
+	if err := kubeHandler.ResumeUpgrades(context.Background()); err != nil {
+		logrus.Errorf("resume upgrades: %v", err)
+	}
+
+	if err := kubeHandler.ResumeRotations(context.Background()); err != nil {
+		logrus.Errorf("resume rotations: %v", err)
+	}
+
+	if cfg.AutoscaleInterval > 0 {
+		go kube.NewAutoscaler(kubeHandler, cfg.AutoscaleInterval).Run(context.Background())
+	}
+
+	if cfg.ReconcileInterval > 0 {
+		go kube.NewReconciler(kubeHandler, cfg.ReconcileInterval).Run(context.Background())
+	}
+
+	if cfg.HealthCheckInterval > 0 {
+		go kube.NewHealthMonitor(kubeHandler, cfg.HealthCheckInterval).Run(context.Background())
+	}
+
+	if cfg.CertCheckInterval > 0 {
+		go kube.NewCertMonitor(kubeHandler, cfg.CertCheckInterval).Run(context.Background())
+	}
+
+	backupService := backup.NewService(backup.DefaultStoragePrefix, repository, backupStore)
+	backupHandler := backup.NewHandler(backupService, kubeService, profileService,
+		repository, cfg.LogDir)
+	backupHandler.Register(protectedAPI)
+
+	if cfg.BackupScheduleInterval > 0 {
+		go backup.NewScheduler(backupHandler, cfg.BackupScheduleInterval).Run(context.Background())
+	}
+
+	bootstrapHandler := bootstrap.NewHandler(bootstrapService, kubeService)
+	bootstrapHandler.RegisterCallback(router)
+	bootstrapHandler.Register(protectedAPI)
+
 	authMiddleware := api.Middleware{
 		TokenService: jwtService,
 	}
@@ -327,7 +453,7 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 	return router, nil
 }
 
-func ensureHelmRepositories(svc sghelm.Servicer) {
+func ensureHelmRepositories(svc sghelm.Servicer, overrides map[string]string) {
 	if svc == nil {
 		return
 	}
@@ -338,12 +464,12 @@ func ensureHelmRepositories(svc sghelm.Servicer) {
 			URL:  "https://supergiant.github.io/charts",
 		},
 		{
-			Name: "stable",
-			URL:  "https://kubernetes-charts.storage.googleapis.com",
+			Name: profile.StableRepositoryName,
+			URL:  profile.DefaultStableRepository,
 		},
 	}
 
-	for _, entry := range entries {
+	for _, entry := range withOverrides(entries, overrides) {
 		_, err := svc.CreateRepo(context.Background(), &entry)
 		if err != nil {
 			if !sgerrors.IsAlreadyExists(err) {
@@ -356,6 +482,35 @@ func ensureHelmRepositories(svc sghelm.Servicer) {
 
 }
 
+// withOverrides replaces urls of the entries and adds repositories
+// that are missing.
+func withOverrides(entries []repo.Entry, overrides map[string]string) []repo.Entry {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		found := false
+		for i := range entries {
+			if entries[i].Name == name {
+				entries[i].URL = overrides[name]
+				found = true
+			}
+		}
+
+		if !found {
+			entries = append(entries, repo.Entry{
+				Name: name,
+				URL:  overrides[name],
+			})
+		}
+	}
+
+	return entries
+}
+
 func serveUI(cfg *Config, router *mux.Router) error {
 	statikFS, err := fs.New()
 	if err != nil {
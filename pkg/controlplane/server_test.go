@@ -3,11 +3,13 @@ package controlplane
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"k8s.io/helm/pkg/repo"
 )
 
 func TestNewServer(t *testing.T) {
@@ -140,3 +142,25 @@ func TestNewVersionHandler(t *testing.T) {
 			rec.Body.String(), version)
 	}
 }
+
+func TestWithOverrides(t *testing.T) {
+	entries := []repo.Entry{
+		{Name: "supergiant", URL: "https://supergiant.github.io/charts"},
+		{Name: "stable", URL: "https://kubernetes-charts.storage.googleapis.com"},
+	}
+
+	actual := withOverrides(entries, map[string]string{
+		"stable":   "http://charts.local/stable",
+		"internal": "http://charts.local/internal",
+	})
+
+	expected := []repo.Entry{
+		{Name: "supergiant", URL: "https://supergiant.github.io/charts"},
+		{Name: "stable", URL: "http://charts.local/stable"},
+		{Name: "internal", URL: "http://charts.local/internal"},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("wrong repositories expected %v actual %v", expected, actual)
+	}
+}
@@ -0,0 +1,35 @@
+package cri
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Container runtimes that can run pods on nodes
+const (
+	Docker     = "docker"
+	Containerd = "containerd"
+	CRIO       = "cri-o"
+)
+
+var ErrUnknownRuntime = errors.New("unknown container runtime")
+
+// Validate checks that runtime is supported, empty runtime stands for docker.
+func Validate(runtime string) error {
+	switch runtime {
+	case "", Docker, Containerd, CRIO:
+		return nil
+	}
+	return errors.Wrap(ErrUnknownRuntime, runtime)
+}
+
+// Socket returns CRI socket of the runtime kubelet connects to, docker
+// has no socket as kubelet uses built-in dockershim for it.
+func Socket(runtime string) string {
+	switch runtime {
+	case Containerd:
+		return "/run/containerd/containerd.sock"
+	case CRIO:
+		return "/var/run/crio/crio.sock"
+	}
+	return ""
+}
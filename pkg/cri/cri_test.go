@@ -0,0 +1,35 @@
+package cri
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestValidate(t *testing.T) {
+	for _, runtime := range []string{"", Docker, Containerd, CRIO} {
+		if err := Validate(runtime); err != nil {
+			t.Errorf("runtime %q: unexpected error %v", runtime, err)
+		}
+	}
+
+	if err := Validate("rkt"); errors.Cause(err) != ErrUnknownRuntime {
+		t.Errorf("wrong error expected %v actual %v", ErrUnknownRuntime, err)
+	}
+}
+
+func TestSocket(t *testing.T) {
+	testCases := map[string]string{
+		"":         "",
+		Docker:     "",
+		Containerd: "/run/containerd/containerd.sock",
+		CRIO:       "/var/run/crio/crio.sock",
+	}
+
+	for runtime, expected := range testCases {
+		if socket := Socket(runtime); socket != expected {
+			t.Errorf("runtime %q: wrong socket expected %s actual %s",
+				runtime, expected, socket)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package distro
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Supported operating systems of nodes
+const (
+	Ubuntu = "ubuntu"
+	Debian = "debian"
+	CentOS = "centos"
+	RHEL   = "rhel"
+
+	// Linux is kept for kubes created before distro could be chosen,
+	// it stands for Ubuntu.
+	Linux = "linux"
+)
+
+// Families of operating systems that share package manager
+const (
+	FamilyDebian = "debian"
+	FamilyRedHat = "redhat"
+)
+
+// Platform is the name of operating system in release urls of
+// kubernetes, helm, etc binaries.
+const Platform = "linux"
+
+var ErrUnsupported = errors.New("unsupported operating system")
+
+// AMI is filter of Amazon Machine Images of the distro.
+type AMI struct {
+	Owner string
+	Name  string
+}
+
+// GCEImage is image family of Google Compute Engine.
+type GCEImage struct {
+	Project string
+	Family  string
+}
+
+// AzureImage is reference to image in Azure marketplace.
+type AzureImage struct {
+	Publisher string
+	Offer     string
+	SKU       string
+}
+
+// OpenStackImage is image in glance, images are uploaded by operators
+// of the cloud so they are looked up by conventional name.
+type OpenStackImage struct {
+	Name string
+	// User that has access by ssh on the image
+	User string
+}
+
+// Distro describes version of operating system and images of it
+// in clouds.
+type Distro struct {
+	Name    string
+	Version string
+	Family  string
+
+	// User that has access by ssh on cloud images
+	CloudUser string
+
+	AMI        AMI
+	GCEImage   GCEImage
+	AzureImage AzureImage
+
+	OpenStackImage OpenStackImage
+
+	// PacketOS is slug of the operating system of packet devices
+	PacketOS string
+
+	// KubicRepo is the name of the distro in opensuse kubic repositories
+	// of CRI-O packages
+	KubicRepo string
+}
+
+var defaultVersions = map[string]string{
+	Ubuntu: "xenial",
+	Debian: "stretch",
+	CentOS: "7",
+	RHEL:   "7",
+}
+
+var distros = []Distro{
+	{
+		Name:      Ubuntu,
+		Version:   "xenial",
+		Family:    FamilyDebian,
+		CloudUser: "ubuntu",
+		AMI: AMI{
+			Owner: "099720109477",
+			Name:  "ubuntu/images/hvm-ssd/ubuntu-xenial-16.04-amd64-server-*",
+		},
+		GCEImage:       GCEImage{Project: "ubuntu-os-cloud", Family: "ubuntu-1604-lts"},
+		AzureImage:     AzureImage{Publisher: "Canonical", Offer: "UbuntuServer", SKU: "16.04-LTS"},
+		OpenStackImage: OpenStackImage{Name: "ubuntu-16.04", User: "ubuntu"},
+		PacketOS:       "ubuntu_16_04",
+		KubicRepo:      "xUbuntu_16.04",
+	},
+	{
+		Name:      Ubuntu,
+		Version:   "bionic",
+		Family:    FamilyDebian,
+		CloudUser: "ubuntu",
+		AMI: AMI{
+			Owner: "099720109477",
+			Name:  "ubuntu/images/hvm-ssd/ubuntu-bionic-18.04-amd64-server-*",
+		},
+		GCEImage:       GCEImage{Project: "ubuntu-os-cloud", Family: "ubuntu-1804-lts"},
+		AzureImage:     AzureImage{Publisher: "Canonical", Offer: "UbuntuServer", SKU: "18.04-LTS"},
+		OpenStackImage: OpenStackImage{Name: "ubuntu-18.04", User: "ubuntu"},
+		PacketOS:       "ubuntu_18_04",
+		KubicRepo:      "xUbuntu_18.04",
+	},
+	{
+		Name:      Debian,
+		Version:   "stretch",
+		Family:    FamilyDebian,
+		CloudUser: "admin",
+		AMI: AMI{
+			Owner: "379101102735",
+			Name:  "debian-stretch-hvm-x86_64-gp2-*",
+		},
+		GCEImage:       GCEImage{Project: "debian-cloud", Family: "debian-9"},
+		AzureImage:     AzureImage{Publisher: "credativ", Offer: "Debian", SKU: "9"},
+		OpenStackImage: OpenStackImage{Name: "debian-9", User: "debian"},
+		PacketOS:       "debian_9",
+		KubicRepo:      "Debian_9.0",
+	},
+	{
+		Name:      Debian,
+		Version:   "buster",
+		Family:    FamilyDebian,
+		CloudUser: "admin",
+		AMI: AMI{
+			Owner: "136693071363",
+			Name:  "debian-10-amd64-*",
+		},
+		GCEImage:       GCEImage{Project: "debian-cloud", Family: "debian-10"},
+		AzureImage:     AzureImage{Publisher: "Debian", Offer: "debian-10", SKU: "10"},
+		OpenStackImage: OpenStackImage{Name: "debian-10", User: "debian"},
+		PacketOS:       "debian_10",
+		KubicRepo:      "Debian_10",
+	},
+	{
+		Name:      CentOS,
+		Version:   "7",
+		Family:    FamilyRedHat,
+		CloudUser: "centos",
+		AMI: AMI{
+			Owner: "679593333241",
+			Name:  "CentOS Linux 7 x86_64 HVM EBS *",
+		},
+		GCEImage:       GCEImage{Project: "centos-cloud", Family: "centos-7"},
+		AzureImage:     AzureImage{Publisher: "OpenLogic", Offer: "CentOS", SKU: "7.5"},
+		OpenStackImage: OpenStackImage{Name: "centos-7", User: "centos"},
+		PacketOS:       "centos_7",
+		KubicRepo:      "CentOS_7",
+	},
+	{
+		Name:      RHEL,
+		Version:   "7",
+		Family:    FamilyRedHat,
+		CloudUser: "ec2-user",
+		AMI: AMI{
+			Owner: "309956199498",
+			Name:  "RHEL-7.*_HVM_GA-*-x86_64-*",
+		},
+		GCEImage:       GCEImage{Project: "rhel-cloud", Family: "rhel-7"},
+		AzureImage:     AzureImage{Publisher: "RedHat", Offer: "RHEL", SKU: "7-LVM"},
+		OpenStackImage: OpenStackImage{Name: "rhel-7", User: "cloud-user"},
+		PacketOS:       "rhel_7",
+		KubicRepo:      "CentOS_7",
+	},
+}
+
+// Get returns distro by operating system and its version, empty
+// version stands for default version of the operating system.
+func Get(operatingSystem, version string) (*Distro, error) {
+	name := strings.ToLower(operatingSystem)
+	if name == "" || name == Linux {
+		name = Ubuntu
+	}
+
+	if version == "" {
+		version = defaultVersions[name]
+	}
+
+	for _, d := range distros {
+		if d.Name == name && d.Version == strings.ToLower(version) {
+			result := d
+			return &result, nil
+		}
+	}
+
+	return nil, errors.Wrapf(ErrUnsupported, "%s %s", operatingSystem, version)
+}
@@ -0,0 +1,47 @@
+package distro
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestGet(t *testing.T) {
+	testCases := []struct {
+		operatingSystem string
+		version         string
+		expectedName    string
+		expectedVersion string
+		expectedFamily  string
+		expectedErr     error
+	}{
+		{"", "", Ubuntu, "xenial", FamilyDebian, nil},
+		{Linux, "xenial", Ubuntu, "xenial", FamilyDebian, nil},
+		{"Ubuntu", "bionic", Ubuntu, "bionic", FamilyDebian, nil},
+		{Debian, "", Debian, "stretch", FamilyDebian, nil},
+		{CentOS, "7", CentOS, "7", FamilyRedHat, nil},
+		{RHEL, "", RHEL, "7", FamilyRedHat, nil},
+		{CentOS, "xenial", "", "", "", ErrUnsupported},
+		{"windows", "", "", "", "", ErrUnsupported},
+	}
+
+	for _, testCase := range testCases {
+		d, err := Get(testCase.operatingSystem, testCase.version)
+
+		if errors.Cause(err) != testCase.expectedErr {
+			t.Errorf("%s %s: wrong error expected %v actual %v", testCase.operatingSystem,
+				testCase.version, testCase.expectedErr, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if d.Name != testCase.expectedName || d.Version != testCase.expectedVersion ||
+			d.Family != testCase.expectedFamily {
+			t.Errorf("%s %s: unexpected distro %+v", testCase.operatingSystem,
+				testCase.version, d)
+		}
+	}
+}
@@ -0,0 +1,554 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/supergiant/control/pkg/kubeconfig"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+)
+
+const (
+	autoscalerComponent = "supergiant-autoscaler"
+
+	// Reasons of the events autoscaler records to the kube.
+	EventTriggeredScaleUp = "TriggeredScaleUp"
+	EventFailedScaleUp    = "FailedScaleUp"
+	EventScaleDown        = "ScaleDown"
+	EventFailedScaleDown  = "FailedScaleDown"
+
+	DefaultScaleUpCooldown      = time.Minute * 3
+	DefaultScaleDownCooldown    = time.Minute * 10
+	DefaultScaleDownUtilization = 0.5
+
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// Autoscaler resizes autoscaled node pools of operational kubes. Nodes are
+// added for pods that can't be scheduled and removed when pods request less
+// than ScaleDownUtilization of their cpu and memory.
+type Autoscaler struct {
+	// ScaleUpCooldown gives pods time to be scheduled on new nodes
+	// before the pool is scaled up again.
+	ScaleUpCooldown time.Duration
+	// ScaleDownCooldown is a time after the last change of the pool
+	// no nodes are removed for.
+	ScaleDownCooldown    time.Duration
+	ScaleDownUtilization float64
+
+	interval   time.Duration
+	h          *Handler
+	coreClient func(*model.Kube) (corev1client.CoreV1Interface, error)
+	now        func() time.Time
+
+	lastScaleUp   map[string]time.Time
+	lastScaleDown map[string]time.Time
+}
+
+// NewAutoscaler makes autoscaler that adds and deletes nodes the same way
+// node pools handler does.
+func NewAutoscaler(h *Handler, interval time.Duration) *Autoscaler {
+	return &Autoscaler{
+		ScaleUpCooldown:      DefaultScaleUpCooldown,
+		ScaleDownCooldown:    DefaultScaleDownCooldown,
+		ScaleDownUtilization: DefaultScaleDownUtilization,
+
+		interval:      interval,
+		h:             h,
+		coreClient:    kubeconfig.CoreV1Client,
+		now:           time.Now,
+		lastScaleUp:   make(map[string]time.Time),
+		lastScaleDown: make(map[string]time.Time),
+	}
+}
+
+// Run checks kubes every interval until the context is done.
+func (a *Autoscaler) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.scale(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *Autoscaler) scale(ctx context.Context) {
+	kubes, err := a.h.svc.ListAll(ctx)
+	if err != nil {
+		logrus.Errorf("autoscaler: list kubes: %v", err)
+		return
+	}
+
+	for i := range kubes {
+		k := &kubes[i]
+		// Degraded kubes are scaled too, that is when capacity is needed
+		if (k.State != model.StateOperational && k.State != model.StateDegraded) || len(autoscaledPools(k)) == 0 {
+			continue
+		}
+
+		if err := a.scaleKube(ctx, k); err != nil {
+			logrus.Errorf("autoscaler: kube %s: %v", k.ID, err)
+		}
+	}
+}
+
+func (a *Autoscaler) scaleKube(ctx context.Context, k *model.Kube) error {
+	client, err := a.coreClient(k)
+	if err != nil {
+		return errors.Wrap(err, "build kubernetes client")
+	}
+
+	podList, err := client.Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "list pods")
+	}
+
+	nodeList, err := client.Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "list nodes")
+	}
+
+	pending := unschedulablePods(podList.Items)
+
+	for _, name := range autoscaledPools(k) {
+		pool := k.NodePools[name]
+		limits := pool.Autoscaling
+		key := k.ID + "/" + name
+
+		// Pool that is being resized is left alone until machines are built
+		// or deleted, unhealthy machines are repaired by health monitor.
+		machines := poolMachines(k, name)
+		if inTransition(machines) {
+			continue
+		}
+		nodes := poolNodes(nodeList.Items, name)
+
+		var fit []corev1.Pod
+		fit, pending = podsForPool(pending, pool)
+
+		switch {
+		case len(machines) < limits.Min:
+			err = a.scaleUp(ctx, client, k, pool, machines, limits.Min-len(machines), nil)
+		case len(machines) > limits.Max:
+			err = a.scaleDown(ctx, client, k, pool, machines,
+				nodesToDelete(machines, len(machines)-limits.Max), poolRef())
+		case len(fit) > 0:
+			if len(machines) >= limits.Max || a.since(a.lastScaleUp, key) < a.ScaleUpCooldown {
+				continue
+			}
+
+			count := nodesNeeded(fit, nodes)
+			if count > limits.Max-len(machines) {
+				count = limits.Max - len(machines)
+			}
+			err = a.scaleUp(ctx, client, k, pool, machines, count, fit)
+		default:
+			if len(machines) <= limits.Min ||
+				a.since(a.lastScaleUp, key) < a.ScaleDownCooldown ||
+				a.since(a.lastScaleDown, key) < a.ScaleDownCooldown {
+				continue
+			}
+
+			node := underutilizedNode(nodes, podList.Items, a.ScaleDownUtilization)
+			if node == nil {
+				continue
+			}
+
+			m := machineForNode(machines, node)
+			if m == nil {
+				logrus.Warnf("autoscaler: machine of node %s not found", node.Name)
+				continue
+			}
+			err = a.scaleDown(ctx, client, k, pool, machines, []*model.Machine{m}, corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "Node",
+				Name:       node.Name,
+				UID:        node.UID,
+			})
+		}
+
+		if err != nil {
+			logrus.Errorf("autoscaler: pool %s of kube %s: %v", name, k.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *Autoscaler) scaleUp(ctx context.Context, client corev1client.CoreV1Interface, k *model.Kube,
+	pool profile.NodePool, machines []*model.Machine, count int, pods []corev1.Pod) error {
+	a.lastScaleUp[k.ID+"/"+pool.Name] = a.now()
+
+	pool.Count = len(machines) + count
+	k.NodePools[pool.Name] = pool
+
+	msg := fmt.Sprintf("pool %s is scaled up from %d to %d nodes", pool.Name, len(machines), pool.Count)
+	ref := poolRef()
+	if len(pods) > 0 {
+		msg = fmt.Sprintf("%s for %d unschedulable pods", msg, len(pods))
+		ref = corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  pods[0].Namespace,
+			Name:       pods[0].Name,
+			UID:        pods[0].UID,
+		}
+	}
+
+	err := a.h.svc.Create(ctx, k)
+	if err == nil {
+		_, err = a.h.addPoolNodes(ctx, k, pool, machines, count)
+	}
+
+	if err != nil {
+		a.record(client, ref, corev1.EventTypeWarning, EventFailedScaleUp, fmt.Sprintf("%s: %v", msg, err))
+		return err
+	}
+
+	a.record(client, ref, corev1.EventTypeNormal, EventTriggeredScaleUp, msg)
+	return nil
+}
+
+func (a *Autoscaler) scaleDown(ctx context.Context, client corev1client.CoreV1Interface, k *model.Kube,
+	pool profile.NodePool, machines, remove []*model.Machine, ref corev1.ObjectReference) error {
+	a.lastScaleDown[k.ID+"/"+pool.Name] = a.now()
+
+	pool.Count = len(machines) - len(remove)
+	k.NodePools[pool.Name] = pool
+
+	names := make([]string, 0, len(remove))
+	for _, m := range remove {
+		names = append(names, m.Name)
+	}
+	msg := fmt.Sprintf("pool %s is scaled down from %d to %d nodes, delete %v",
+		pool.Name, len(machines), pool.Count, names)
+
+	err := a.h.svc.Create(ctx, k)
+	if err == nil {
+		_, err = a.h.deletePoolNodes(ctx, k, remove)
+	}
+
+	if err != nil {
+		a.record(client, ref, corev1.EventTypeWarning, EventFailedScaleDown, fmt.Sprintf("%s: %v", msg, err))
+		return err
+	}
+
+	a.record(client, ref, corev1.EventTypeNormal, EventScaleDown, msg)
+	return nil
+}
+
+// record creates an event in the kube, events of cluster wide objects
+// go to the default namespace as kubectl expects.
+func (a *Autoscaler) record(client corev1client.CoreV1Interface, ref corev1.ObjectReference,
+	eventType, reason, msg string) {
+	logrus.Infof("autoscaler: %s %s", reason, msg)
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	now := metav1.NewTime(a.now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", ref.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: ref,
+		Reason:         reason,
+		Message:        msg,
+		Type:           eventType,
+		Source: corev1.EventSource{
+			Component: autoscalerComponent,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	if _, err := client.Events(namespace).Create(event); err != nil {
+		logrus.Errorf("autoscaler: record %s event: %v", reason, err)
+	}
+}
+
+func (a *Autoscaler) since(last map[string]time.Time, key string) time.Duration {
+	t, ok := last[key]
+	if !ok {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return a.now().Sub(t)
+}
+
+// poolRef is an object events of the pool as a whole refer to.
+func poolRef() corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Name:       metav1.NamespaceSystem,
+	}
+}
+
+func autoscaledPools(k *model.Kube) []string {
+	names := make([]string, 0)
+
+	for name, pool := range k.NodePools {
+		if pool.IsAutoscaled() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func inTransition(machines []*model.Machine) bool {
+	for _, m := range machines {
+		switch m.State {
+		case model.MachineStatePlanned, model.MachineStateBuilding, model.MachineStateProvisioning,
+			model.MachineStateDeleting, model.MachineStateUpgrading:
+			return true
+		}
+	}
+
+	return false
+}
+
+func poolNodes(nodes []corev1.Node, poolName string) []corev1.Node {
+	poolNodes := make([]corev1.Node, 0)
+
+	for _, n := range nodes {
+		if n.Labels[profile.NodePoolLabel] == poolName {
+			poolNodes = append(poolNodes, n)
+		}
+	}
+
+	return poolNodes
+}
+
+func machineForNode(machines []*model.Machine, node *corev1.Node) *model.Machine {
+	for _, m := range machines {
+		if m.Name == node.Name {
+			return m
+		}
+
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP && addr.Address == m.PrivateIp {
+				return m
+			}
+		}
+	}
+
+	return nil
+}
+
+func unschedulablePods(pods []corev1.Pod) []corev1.Pod {
+	unschedulable := make([]corev1.Pod, 0)
+
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName != "" {
+			continue
+		}
+
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse &&
+				cond.Reason == corev1.PodReasonUnschedulable {
+				unschedulable = append(unschedulable, pod)
+				break
+			}
+		}
+	}
+
+	return unschedulable
+}
+
+// podsForPool splits pods into ones that can run on nodes of the pool and the rest.
+func podsForPool(pods []corev1.Pod, pool profile.NodePool) ([]corev1.Pod, []corev1.Pod) {
+	fit, rest := make([]corev1.Pod, 0), make([]corev1.Pod, 0)
+
+	for _, pod := range pods {
+		if podFits(pod, pool) {
+			fit = append(fit, pod)
+		} else {
+			rest = append(rest, pod)
+		}
+	}
+
+	return fit, rest
+}
+
+// podFits checks node selector and tolerations of the pod allow it to be
+// scheduled to nodes of the pool.
+func podFits(pod corev1.Pod, pool profile.NodePool) bool {
+	for k, v := range pod.Spec.NodeSelector {
+		if k == profile.NodePoolLabel {
+			if v != pool.Name {
+				return false
+			}
+			continue
+		}
+
+		if label, ok := pool.Labels[k]; !ok || label != v {
+			return false
+		}
+	}
+
+	for _, t := range pool.Taints {
+		taint := corev1.Taint{
+			Key:    t.Key,
+			Value:  t.Value,
+			Effect: corev1.TaintEffect(t.Effect),
+		}
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for i := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[i].ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nodesNeeded estimates how many nodes like the ones of the pool
+// requests of the pods take, it is one when the pool has no nodes.
+func nodesNeeded(pods []corev1.Pod, nodes []corev1.Node) int {
+	if len(nodes) == 0 {
+		return 1
+	}
+	allocatable := nodes[0].Status.Allocatable
+
+	var cpu, memory int64
+	for _, pod := range pods {
+		c, m := podRequests(pod)
+		cpu += c
+		memory += m
+	}
+
+	count := 1
+	for _, n := range []int{
+		ceilDiv(cpu, allocatable.Cpu().MilliValue()),
+		ceilDiv(memory, allocatable.Memory().Value()),
+		ceilDiv(int64(len(pods)), allocatable.Pods().Value()),
+	} {
+		if n > count {
+			count = n
+		}
+	}
+
+	return count
+}
+
+// underutilizedNode returns the least utilized node of ones whose pods
+// request less than threshold of cpu and memory and can be moved elsewhere.
+func underutilizedNode(nodes []corev1.Node, pods []corev1.Pod, threshold float64) *corev1.Node {
+	var (
+		best            *corev1.Node
+		bestUtilization float64
+	)
+
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+
+		utilization, movable := nodeUtilization(node, pods)
+		if !movable || utilization >= threshold {
+			continue
+		}
+
+		if best == nil || utilization < bestUtilization ||
+			(utilization == bestUtilization && node.Name < best.Name) {
+			best, bestUtilization = node, utilization
+		}
+	}
+
+	return best
+}
+
+// nodeUtilization returns the biggest share of cpu and memory of the node
+// requested by pods and whether the pods can be moved to other nodes,
+// pods that have no controller are lost when the node is deleted.
+func nodeUtilization(node *corev1.Node, pods []corev1.Pod) (float64, bool) {
+	var cpu, memory int64
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node.Name ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			continue
+		}
+
+		owner := metav1.GetControllerOf(&pod)
+		if owner == nil {
+			return 0, false
+		}
+		if owner.Kind == "DaemonSet" {
+			continue
+		}
+
+		c, m := podRequests(pod)
+		cpu += c
+		memory += m
+	}
+
+	allocatable := node.Status.Allocatable
+	return math.Max(share(cpu, allocatable.Cpu().MilliValue()),
+		share(memory, allocatable.Memory().Value())), true
+}
+
+// podRequests returns cpu in millicores and memory in bytes requested by the pod.
+func podRequests(pod corev1.Pod) (int64, int64) {
+	var cpu, memory int64
+
+	for _, c := range pod.Spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		memory += c.Resources.Requests.Memory().Value()
+	}
+
+	return cpu, memory
+}
+
+func ceilDiv(a, b int64) int {
+	if b <= 0 {
+		return 0
+	}
+
+	return int((a + b - 1) / b)
+}
+
+func share(a, b int64) float64 {
+	if b <= 0 {
+		return 0
+	}
+
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,350 @@
+package kube
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func autoscaledKube(min, max int) *model.Kube {
+	k := poolKube()
+	pool := k.NodePools["workers"]
+	pool.Autoscaling = &profile.Autoscaling{Enabled: true, Min: min, Max: max}
+	k.NodePools["workers"] = pool
+
+	return k
+}
+
+func poolNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{profile.NodePoolLabel: "workers"},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func testPod(name, node, cpu string, selector map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				Kind:       "ReplicaSet",
+				Name:       "app",
+				Controller: &[]bool{true}[0],
+			}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:     node,
+			NodeSelector: selector,
+			Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse(cpu),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+
+	if node == "" {
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:   corev1.PodScheduled,
+			Status: corev1.ConditionFalse,
+			Reason: corev1.PodReasonUnschedulable,
+		}}
+	}
+
+	return pod
+}
+
+func testAutoscaler(k *model.Kube, objects ...runtime.Object) (*Autoscaler, *mockNodeProvisioner, *fake.Clientset) {
+	svc := new(kubeServiceMock)
+	svc.On(serviceListAll, mock.Anything).Return([]model.Kube{*k}, nil)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+
+	profileSvc := new(mockProfileService)
+	profileSvc.On("Get", mock.Anything, mock.Anything).
+		Return(&profile.Profile{}, nil)
+
+	accService := new(accServiceMock)
+	accService.On("Get", mock.Anything, mock.Anything).
+		Return(&model.CloudAccount{Provider: clouds.DigitalOcean}, nil)
+
+	provisioner := new(mockNodeProvisioner)
+	provisioner.On("ProvisionNodes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]string{"task1"}, nil)
+
+	repo := new(testutils.MockStorage)
+	repo.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+
+	h := &Handler{
+		svc:             svc,
+		accountService:  accService,
+		profileSvc:      profileSvc,
+		nodeProvisioner: provisioner,
+		repo:            repo,
+		getWriter: func(string) (io.WriteCloser, error) {
+			return &bufferCloser{}, nil
+		},
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+	a := NewAutoscaler(h, time.Minute)
+	a.coreClient = func(*model.Kube) (corev1client.CoreV1Interface, error) {
+		return client.CoreV1(), nil
+	}
+
+	return a, provisioner, client
+}
+
+func events(t *testing.T, client *fake.Clientset, reason string) []corev1.Event {
+	list, err := client.CoreV1().Events(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list events %v", err)
+	}
+
+	found := make([]corev1.Event, 0)
+	for _, e := range list.Items {
+		if e.Reason == reason {
+			found = append(found, e)
+		}
+	}
+
+	return found
+}
+
+func TestAutoscalerScaleUp(t *testing.T) {
+	k := autoscaledKube(1, 3)
+	a, provisioner, client := testAutoscaler(k,
+		poolNode("node-1"), poolNode("node-2"),
+		testPod("pending-1", "", "1500m", map[string]string{"role": "worker"}),
+		testPod("pending-2", "", "1500m", nil),
+		testPod("pending-3", "", "100m", map[string]string{"disk": "ssd"}),
+	)
+
+	a.scale(context.Background())
+
+	// 3 cpus are requested by pods that fit, it takes 2 nodes
+	provisioner.AssertCalled(t, "ProvisionNodes", mock.Anything, []profile.NodeProfile{
+		{"pool": "workers", "size": "s-2vcpu-4gb"},
+	}, mock.Anything, mock.Anything)
+
+	if count := k.NodePools["workers"].Count; count != 3 {
+		t.Errorf("expected pool count 3 actual %d", count)
+	}
+
+	if e := events(t, client, EventTriggeredScaleUp); len(e) != 1 || e[0].InvolvedObject.Kind != "Pod" {
+		t.Errorf("wrong scale up events %v", e)
+	}
+
+	// New nodes are not ready yet
+	a.scale(context.Background())
+	if len(provisioner.Calls) != 1 {
+		t.Errorf("pool is scaled up during cooldown")
+	}
+}
+
+func TestAutoscalerScaleUpDegraded(t *testing.T) {
+	k := autoscaledKube(1, 3)
+	k.State = model.StateDegraded
+	k.Nodes["node-1"].State = model.MachineStateNotReady
+	a, provisioner, _ := testAutoscaler(k,
+		poolNode("node-1"), poolNode("node-2"),
+		testPod("pending", "", "1500m", nil),
+	)
+
+	a.scale(context.Background())
+
+	provisioner.AssertNumberOfCalls(t, "ProvisionNodes", 1)
+
+	// Pool is left alone while new machines are provisioned
+	k.Nodes["node-2"].State = model.MachineStateProvisioning
+	a.lastScaleUp = make(map[string]time.Time)
+	a.scale(context.Background())
+
+	provisioner.AssertNumberOfCalls(t, "ProvisionNodes", 1)
+}
+
+func TestAutoscalerSkipsPodsThatDontFit(t *testing.T) {
+	k := autoscaledKube(1, 3)
+	a, provisioner, client := testAutoscaler(k,
+		poolNode("node-1"), poolNode("node-2"),
+		testPod("running", "node-1", "1500m", nil),
+		testPod("running-2", "node-2", "1500m", nil),
+		testPod("pending", "", "100m", map[string]string{"role": "db"}),
+	)
+
+	a.scale(context.Background())
+
+	provisioner.AssertNotCalled(t, "ProvisionNodes", mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything)
+	if e, _ := client.CoreV1().Events(metav1.NamespaceAll).List(metav1.ListOptions{}); len(e.Items) != 0 {
+		t.Errorf("unexpected events %v", e.Items)
+	}
+}
+
+func TestAutoscalerScaleUpToMin(t *testing.T) {
+	k := autoscaledKube(4, 5)
+	a, provisioner, _ := testAutoscaler(k, poolNode("node-1"), poolNode("node-2"))
+
+	a.scale(context.Background())
+
+	provisioner.AssertCalled(t, "ProvisionNodes", mock.Anything, []profile.NodeProfile{
+		{"pool": "workers", "size": "s-2vcpu-4gb"},
+		{"pool": "workers", "size": "s-2vcpu-4gb"},
+	}, mock.Anything, mock.Anything)
+}
+
+func TestAutoscalerScaleDown(t *testing.T) {
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.DeleteNode, []steps.Step{})
+
+	k := autoscaledKube(1, 3)
+	a, provisioner, client := testAutoscaler(k,
+		poolNode("node-1"), poolNode("node-2"),
+		testPod("busy", "node-1", "1500m", nil),
+		testPod("idle", "node-2", "100m", nil),
+	)
+
+	a.scale(context.Background())
+
+	provisioner.AssertNotCalled(t, "ProvisionNodes", mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything)
+
+	if count := k.NodePools["workers"].Count; count != 1 {
+		t.Errorf("expected pool count 1 actual %d", count)
+	}
+
+	if e := events(t, client, EventScaleDown); len(e) != 1 || e[0].InvolvedObject.Name != "node-2" {
+		t.Errorf("wrong scale down events %v", e)
+	}
+}
+
+func TestAutoscalerKeepsMin(t *testing.T) {
+	k := autoscaledKube(2, 3)
+	a, _, client := testAutoscaler(k,
+		poolNode("node-1"), poolNode("node-2"),
+		testPod("idle", "node-2", "100m", nil),
+	)
+
+	a.scale(context.Background())
+
+	if e, _ := client.CoreV1().Events(metav1.NamespaceAll).List(metav1.ListOptions{}); len(e.Items) != 0 {
+		t.Errorf("pool is scaled below min %v", e.Items)
+	}
+}
+
+func TestPodFits(t *testing.T) {
+	pool := profile.NodePool{
+		Name:   "gpu",
+		Labels: map[string]string{"accelerator": "nvidia"},
+		Taints: []profile.Taint{
+			{Key: "dedicated", Value: "gpu", Effect: profile.TaintNoSchedule},
+			{Key: "spot", Effect: profile.TaintPreferNoSchedule},
+		},
+	}
+	toleration := corev1.Toleration{
+		Key:      "dedicated",
+		Operator: corev1.TolerationOpEqual,
+		Value:    "gpu",
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+
+	testCases := []struct {
+		description string
+		selector    map[string]string
+		tolerations []corev1.Toleration
+		expected    bool
+	}{
+		{
+			description: "taint is not tolerated",
+		},
+		{
+			description: "taint is tolerated",
+			tolerations: []corev1.Toleration{toleration},
+			expected:    true,
+		},
+		{
+			description: "pool label",
+			selector:    map[string]string{profile.NodePoolLabel: "gpu", "accelerator": "nvidia"},
+			tolerations: []corev1.Toleration{toleration},
+			expected:    true,
+		},
+		{
+			description: "other pool",
+			selector:    map[string]string{profile.NodePoolLabel: "workers"},
+			tolerations: []corev1.Toleration{toleration},
+		},
+		{
+			description: "label mismatch",
+			selector:    map[string]string{"accelerator": "amd"},
+			tolerations: []corev1.Toleration{toleration},
+		},
+		{
+			description: "tolerate everything",
+			tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			expected:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		pod := corev1.Pod{
+			Spec: corev1.PodSpec{
+				NodeSelector: tc.selector,
+				Tolerations:  tc.tolerations,
+			},
+		}
+
+		if fits := podFits(pod, pool); fits != tc.expected {
+			t.Errorf("%s: expected %v actual %v", tc.description, tc.expected, fits)
+		}
+	}
+}
+
+func TestNodeUtilization(t *testing.T) {
+	node := poolNode("node-1")
+	daemon := testPod("daemon", "node-1", "1", nil)
+	daemon.OwnerReferences[0].Kind = "DaemonSet"
+	bare := testPod("bare", "node-1", "100m", nil)
+	bare.OwnerReferences = nil
+
+	utilization, movable := nodeUtilization(node, []corev1.Pod{*daemon, *testPod("app", "node-1", "500m", nil)})
+	if !movable || utilization != 0.25 {
+		t.Errorf("expected utilization 0.25 actual %v movable %v", utilization, movable)
+	}
+
+	if _, movable = nodeUtilization(node, []corev1.Pod{*bare}); movable {
+		t.Errorf("pod that has no controller must block scale down")
+	}
+}
@@ -3,7 +3,6 @@ package kube
 import (
 	"bytes"
 	"context"
-	"io/ioutil"
 	"path/filepath"
 	"strings"
 
@@ -71,23 +70,12 @@ func (c *Certs) BundleFor(ctx context.Context, name string) (*Bundle, error) {
 }
 
 func (c *Certs) getFile(ctx context.Context, path string) ([]byte, error) {
-	stdout := &bytes.Buffer{}
-	cmd, err := runner.NewCommand(ctx, catCmd(path), stdout, ioutil.Discard)
-
-	if err != nil {
-		return nil, errors.Wrapf(err, "new command")
+	buf := &bytes.Buffer{}
+	if err := c.r.Download(ctx, path, buf); err != nil {
+		return nil, errors.Wrapf(err, "download %s", path)
 	}
 
-	err = c.r.Run(cmd)
-	if err != nil {
-		return nil, errors.Wrapf(err, "run %q", cmd.Script)
-	}
-
-	return stdout.Bytes(), nil
-}
-
-func catCmd(path string) string {
-	return "/usr/bin/cat " + path
+	return buf.Bytes(), nil
 }
 
 func keyName(name string) string {
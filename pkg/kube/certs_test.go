@@ -2,6 +2,7 @@ package kube
 
 import (
 	"context"
+	"io"
 	"strings"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/runner/ssh"
+	"github.com/supergiant/control/pkg/testutils"
 )
 
 var (
@@ -19,20 +21,22 @@ var (
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	path string
 	err  error
 }
 
 func newFakeRunner(path string, err error) *fakeRunner {
-	return &fakeRunner{path, err}
+	return &fakeRunner{path: path, err: err}
 }
 
 func (r *fakeRunner) Run(cmd *runner.Command) error {
-	if cmd.Script == "" {
-		return nil
-	}
+	return nil
+}
 
-	if !strings.HasSuffix(cmd.Script, r.path) {
+func (r *fakeRunner) Download(ctx context.Context, src string, out io.Writer) error {
+	if !strings.HasSuffix(src, r.path) {
 		return nil
 	}
 
@@ -40,9 +44,8 @@ func (r *fakeRunner) Run(cmd *runner.Command) error {
 		return r.err
 	}
 
-	cmd.Out.Write(fakeFile)
-
-	return nil
+	_, err := out.Write(fakeFile)
+	return err
 }
 
 func TestNewCerts(t *testing.T) {
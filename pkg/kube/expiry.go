@@ -0,0 +1,297 @@
+package kube
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// CertExpiryWarning is how long before expiry of certificates the kube
+// is alerted about them.
+const CertExpiryWarning = 30 * 24 * time.Hour
+
+// Reasons of the certificates condition.
+const (
+	ReasonCertsExpireSoon = "ExpireSoon"
+	ReasonCertsValid      = "Valid"
+)
+
+// adminCertName is a name of admin certificate control accesses kubes with.
+const adminCertName = "admin"
+
+// CertMonitor regularly gathers expiry dates of certificates from masters
+// of kubes and alerts kubes that have certificates expiring soon.
+type CertMonitor struct {
+	interval time.Duration
+	h        *Handler
+	now      func() time.Time
+}
+
+// NewCertMonitor makes monitor that checks kubes every interval.
+func NewCertMonitor(h *Handler, interval time.Duration) *CertMonitor {
+	return &CertMonitor{
+		interval: interval,
+		h:        h,
+		now:      time.Now,
+	}
+}
+
+// Run checks kubes every interval until the context is done.
+func (m *CertMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *CertMonitor) check(ctx context.Context) {
+	kubes, err := m.h.svc.ListAll(ctx)
+	if err != nil {
+		logrus.Errorf("cert monitor: list kubes: %v", err)
+		return
+	}
+
+	for _, k := range kubes {
+		if k.State != model.StateOperational && k.State != model.StateDegraded {
+			continue
+		}
+
+		if err := m.h.checkCerts(ctx, k.ID, m.now); err != nil {
+			logrus.Errorf("cert monitor: kube %s: %v", k.ID, err)
+		}
+	}
+}
+
+func (h *Handler) getCertificates(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Certificates == nil {
+		message.SendNotFound(w, "certificates", sgerrors.ErrNotFound)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(k.Certificates); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// checkCertificates gathers expiry dates of certificates of the kube
+// without waiting for the monitor.
+func (h *Handler) checkCertificates(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		http.Error(w, fmt.Sprintf("kube %s is %s", k.ID, k.State), http.StatusConflict)
+		return
+	}
+
+	go func() {
+		if err := h.checkCerts(context.Background(), k.ID, time.Now); err != nil {
+			logrus.Errorf("check certificates of kube %s: %v", k.ID, err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// checkCerts gathers expiry dates of certificates from active masters
+// of the kube and updates the certificates condition.
+func (h *Handler) checkCerts(ctx context.Context, kubeID string, now func() time.Time) error {
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	expiries := make([]model.CertExpiry, 0)
+	problems := make([]string, 0)
+	for _, m := range sortedMasters(k) {
+		if m.State != model.MachineStateActive {
+			continue
+		}
+
+		_, config, err := h.runMasterTask(ctx, k, *m, workflows.CertsExpiry)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("master %s: %v", m.Name, err))
+			continue
+		}
+		expiries = append(expiries, config.CertExpiries...)
+	}
+
+	admin, err := adminCertExpiry(k)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if admin != nil {
+		expiries = append(expiries, *admin)
+	}
+
+	// The kube could have been changed while certificates were gathered.
+	k, err = h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	if k.Certificates == nil {
+		k.Certificates = &model.Certificates{}
+	}
+	k.Certificates.Expiries = expiries
+	k.Certificates.CheckedAt = now()
+	k.Certificates.Error = strings.Join(problems, "; ")
+	setCertsCondition(k, now())
+
+	return errors.Wrapf(h.svc.Create(ctx, k), "update kube %s", k.ID)
+}
+
+// setCertsCondition alerts the kube when the earliest of its certificates
+// expires within the warning period.
+func setCertsCondition(k *model.Kube, now time.Time) {
+	c := model.Condition{
+		Type:               model.ConditionCertsExpiring,
+		Status:             model.ConditionFalse,
+		Reason:             ReasonCertsValid,
+		LastTransitionTime: now,
+	}
+
+	earliest := k.Certificates.Earliest()
+	if earliest != nil && earliest.NotAfter.Sub(now) < CertExpiryWarning {
+		c.Status = model.ConditionTrue
+		c.Reason = ReasonCertsExpireSoon
+		c.Message = fmt.Sprintf("certificate %s expires at %s", expiryName(earliest),
+			earliest.NotAfter.Format(time.RFC3339))
+		logrus.Warnf("kube %s: %s, rotate certificates", k.ID, c.Message)
+	}
+
+	k.Status.SetCondition(c)
+}
+
+// adminCertExpiry returns expiry date of admin certificate control
+// accesses the kube with, kubes without certificate have none.
+func adminCertExpiry(k *model.Kube) (*model.CertExpiry, error) {
+	if k.Auth.AdminCert == "" {
+		return nil, nil
+	}
+
+	cert, err := parseCertPEM(k.Auth.AdminCert)
+	if err != nil {
+		return nil, errors.Wrap(err, "admin certificate")
+	}
+
+	return &model.CertExpiry{
+		Name:     adminCertName,
+		NotAfter: cert.NotAfter.UTC(),
+	}, nil
+}
+
+func parseCertPEM(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("certificate is not pem encoded")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	return cert, errors.Wrap(err, "parse certificate")
+}
+
+func expiryName(e *model.CertExpiry) string {
+	if e.Machine == "" {
+		return e.Name
+	}
+
+	return e.Name + " of " + e.Machine
+}
+
+// runMasterTask runs the workflow on the master, config the task
+// has finished with is returned.
+func (h *Handler) runMasterTask(ctx context.Context, k *model.Kube, m model.Machine, workflow string) (string, *steps.Config, error) {
+	kubeProfile, err := h.profileSvc.Get(ctx, k.ProfileID)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
+	config, err := steps.NewConfigFromKube(kubeProfile, k)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "new config")
+	}
+
+	if err = util.LoadCloudSpecificDataFromKube(k, config); err != nil {
+		return "", nil, errors.Wrap(err, "load cloud specific data")
+	}
+
+	config.Node = m
+	config.IsMaster = true
+
+	t, err := workflows.NewTask(config, workflow, h.repo)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "new %s task", workflow)
+	}
+
+	writer, err := h.getWriter(util.MakeFileName(t.ID))
+	if err != nil {
+		return t.ID, nil, errors.Wrapf(err, "get writer for %s", t.ID)
+	}
+
+	if err = <-t.Run(ctx, config, writer); err != nil {
+		return t.ID, nil, err
+	}
+
+	return t.ID, t.Config, nil
+}
+
+func sortedMasters(k *model.Kube) []*model.Machine {
+	masters := make([]*model.Machine, 0, len(k.Masters))
+	for _, m := range k.Masters {
+		if m != nil {
+			masters = append(masters, m)
+		}
+	}
+
+	sort.Slice(masters, func(i, j int) bool {
+		return masters[i].Name < masters[j].Name
+	})
+
+	return masters
+}
@@ -0,0 +1,131 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/pki"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func certsKube(t *testing.T) *model.Kube {
+	ca, err := pki.NewCAPair(nil)
+	if err != nil {
+		t.Fatalf("create ca %v", err)
+	}
+
+	admin, err := pki.NewAdminPair(ca)
+	if err != nil {
+		t.Fatalf("create admin pair %v", err)
+	}
+
+	k := upgradeKube()
+	k.Auth.CACert = string(ca.Cert)
+	k.Auth.CAKey = string(ca.Key)
+	k.Auth.AdminCert = string(admin.Cert)
+	k.Auth.AdminKey = string(admin.Key)
+
+	return k
+}
+
+func TestCheckCerts(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	expiries := map[string]time.Time{
+		"master-1": now.Add(90 * 24 * time.Hour),
+		"master-2": now.Add(10 * 24 * time.Hour),
+	}
+
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.CertsExpiry, []steps.Step{upgradeStep{run: func(config *steps.Config) error {
+		notAfter, ok := expiries[config.Node.Name]
+		if !ok {
+			return errors.New("unreachable")
+		}
+		config.CertExpiries = []model.CertExpiry{
+			{Name: "apiserver.crt", Machine: config.Node.Name, NotAfter: notAfter},
+		}
+		return nil
+	}}})
+
+	k := certsKube(t)
+	k.Masters["master-3"] = &model.Machine{Name: "master-3", Role: model.RoleMaster, State: model.MachineStateActive}
+	h, _, _, _ := specHandler(k)
+
+	if err := h.checkCerts(context.Background(), k.ID, func() time.Time { return now }); err != nil {
+		t.Fatalf("check certs %v", err)
+	}
+
+	if k.Certificates == nil || !k.Certificates.CheckedAt.Equal(now) {
+		t.Fatalf("certificates were not checked %+v", k.Certificates)
+	}
+
+	names := make(map[string]bool)
+	for _, e := range k.Certificates.Expiries {
+		names[expiryName(&e)] = true
+	}
+	for _, expected := range []string{"apiserver.crt of master-1", "apiserver.crt of master-2", adminCertName} {
+		if !names[expected] {
+			t.Errorf("expiry of %s not found in %v", expected, k.Certificates.Expiries)
+		}
+	}
+
+	if k.Certificates.Error == "" {
+		t.Error("error of unreachable master must be reported")
+	}
+
+	c := k.Status.Condition(model.ConditionCertsExpiring)
+	if c == nil || c.Status != model.ConditionTrue || c.Reason != ReasonCertsExpireSoon {
+		t.Fatalf("kube must be alerted, condition %+v", c)
+	}
+
+	expiries["master-2"] = now.Add(60 * 24 * time.Hour)
+	if err := h.checkCerts(context.Background(), k.ID, func() time.Time { return now }); err != nil {
+		t.Fatalf("check certs %v", err)
+	}
+
+	c = k.Status.Condition(model.ConditionCertsExpiring)
+	if c == nil || c.Status != model.ConditionFalse {
+		t.Errorf("kube must not be alerted, condition %+v", c)
+	}
+}
+
+func TestGetCertificates(t *testing.T) {
+	k := certsKube(t)
+	h, _, _, _ := specHandler(k)
+	router := mux.NewRouter()
+	h.Register(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/kubes/kube1234/certs", nil)
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d actual %d", http.StatusNotFound, rec.Code)
+	}
+
+	k.Certificates = &model.Certificates{
+		Expiries: []model.CertExpiry{{Name: "apiserver.crt", Machine: "master-1"}},
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d actual %d", http.StatusOK, rec.Code)
+	}
+
+	certs := model.Certificates{}
+	if err := json.NewDecoder(rec.Body).Decode(&certs); err != nil {
+		t.Fatalf("decode %v", err)
+	}
+	if len(certs.Expiries) != 1 {
+		t.Errorf("wrong certificates %+v", certs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -18,6 +19,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmddapi "k8s.io/client-go/tools/clientcmd/api"
@@ -66,8 +68,6 @@ type kubeProvisioner interface {
 		clusterProfile *profile.Profile,
 		config *steps.Config,
 		taskIdMap map[string][]string) error
-	UpgradeCluster(context.Context, string, *model.Kube,
-		map[string][]*workflows.Task, *steps.Config)
 }
 
 type ServiceInfo struct {
@@ -115,6 +115,12 @@ type Handler struct {
 	discoverHelmVersion func(kubeConfig *clientcmddapi.Config) (string, error)
 
 	listK8sServices func(*model.Kube, string) (*corev1.ServiceList, error)
+	// rbacClient binds users control issues kubeconfigs for to their roles.
+	rbacClient func(*model.Kube) (rbacv1client.RbacV1Interface, error)
+	// upgradeChecks are run before the kube is upgraded to the version.
+	upgradeChecks func(*model.Kube, string) []model.PreflightCheck
+	// upgradeLock serializes changes of upgrades made by users and by runners.
+	upgradeLock sync.Mutex
 }
 
 // NewHandler constructs a Handler for kubes.
@@ -174,8 +180,10 @@ func NewHandler(
 				LabelSelector: selector,
 			})
 		},
+		rbacClient:          kubeconfig.RbacV1Client,
 		discoverK8SVersion:  discoverK8SVersion,
 		discoverHelmVersion: discoverHelmVersion,
+		upgradeChecks:       preflightChecks,
 		proxies:             proxies,
 	}
 }
@@ -188,6 +196,9 @@ func (h *Handler) Register(r *mux.Router) {
 	r.HandleFunc("/kubes/{kubeID}", h.getKube).Methods(http.MethodGet)
 	r.HandleFunc("/kubes/{kubeID}", h.deleteKube).Methods(http.MethodDelete)
 
+	r.HandleFunc("/kubes/{kubeID}/users", h.listUsers).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/users", h.issueUser).Methods(http.MethodPost)
+	r.HandleFunc("/kubes/{kubeID}/users/{uname}", h.revokeUser).Methods(http.MethodDelete)
 	r.HandleFunc("/kubes/{kubeID}/users/{uname}/kubeconfig", h.getKubeconfig).Methods(http.MethodGet)
 
 	r.HandleFunc("/kubes/{kubeID}/resources", h.listResources).Methods(http.MethodGet)
@@ -198,6 +209,10 @@ func (h *Handler) Register(r *mux.Router) {
 	r.HandleFunc("/kubes/{kubeID}/releases/{releaseName}", h.getRelease).Methods(http.MethodGet)
 	r.HandleFunc("/kubes/{kubeID}/releases/{releaseName}", h.deleteReleases).Methods(http.MethodDelete)
 
+	r.HandleFunc("/kubes/{kubeID}/certs", h.getCertificates).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/certs/check", h.checkCertificates).Methods(http.MethodPost)
+	r.HandleFunc("/kubes/{kubeID}/certs/rotate", h.rotateCertificates).Methods(http.MethodPost)
+	r.HandleFunc("/kubes/{kubeID}/certs/rotate/resume", h.resumeRotation).Methods(http.MethodPost)
 	r.HandleFunc("/kubes/{kubeID}/certs/{cname}", h.getCerts).Methods(http.MethodGet)
 	r.HandleFunc("/kubes/{kubeID}/tasks", h.getTasks).Methods(http.MethodGet)
 
@@ -212,6 +227,18 @@ func (h *Handler) Register(r *mux.Router) {
 	r.HandleFunc("/kubes/{kubeID}/machines", h.addMachine).Methods(http.MethodPost)
 	r.HandleFunc("/kubes/{kubeID}/machines/{nodename}", h.deleteMachine).Methods(http.MethodDelete)
 
+	r.HandleFunc("/kubes/{kubeID}/masters", h.addMasters).Methods(http.MethodPost)
+
+	r.HandleFunc("/kubes/{kubeID}/pools", h.listPools).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/pools/{pool}", h.updatePool).Methods(http.MethodPut)
+
+	r.HandleFunc("/kubes/{kubeID}/spec", h.getSpec).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/spec", h.putSpec).Methods(http.MethodPut)
+
+	r.HandleFunc("/kubes/{kubeID}/health", h.getHealth).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/autorepair", h.getAutoRepair).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/autorepair", h.putAutoRepair).Methods(http.MethodPut)
+
 	r.HandleFunc("/kubes/{kubeID}/spot", h.addSpotMachine).Methods(http.MethodPost)
 	r.HandleFunc("/kubes/{kubeID}/spot/{machineType}/price", h.spotMachinePrice).Methods(http.MethodGet)
 
@@ -220,6 +247,9 @@ func (h *Handler) Register(r *mux.Router) {
 	r.HandleFunc("/kubes/{kubeID}/services", h.getServices).Methods(http.MethodGet)
 	r.HandleFunc("/kubes/{kubeID}/restart", h.restartKubeProvisioning).Methods(http.MethodPost)
 	r.HandleFunc("/kubes/{kubeID}", h.upgradeKube).Methods(http.MethodPatch)
+	r.HandleFunc("/kubes/{kubeID}/upgrade", h.getUpgrade).Methods(http.MethodGet)
+	r.HandleFunc("/kubes/{kubeID}/upgrade/resume", h.resumeUpgrade).Methods(http.MethodPost)
+	r.HandleFunc("/kubes/{kubeID}/upgrade/abort", h.abortUpgrade).Methods(http.MethodPost)
 	r.HandleFunc("/kubes/{kubeID}/apply", h.applyToKube).Methods(http.MethodPost)
 }
 
@@ -321,7 +351,7 @@ func (h *Handler) getKube(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Sync only after cluster becomes operational
-	if k.Provider == clouds.AWS && k.State == model.StateOperational {
+	if k.Provider == clouds.AWS && (k.State == model.StateOperational || k.State == model.StateDegraded) {
 		logrus.Debugf("Get cloud account %s", k.AccountName)
 		acc, err := h.accountService.Get(r.Context(), k.AccountName)
 
@@ -391,16 +421,20 @@ func (h *Handler) deleteKube(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acc, err := h.accountService.Get(r.Context(), k.AccountName)
+	// Machines brought by user are deleted without a cloud account
+	var acc *model.CloudAccount
+	if k.Provider != clouds.BYO {
+		acc, err = h.accountService.Get(r.Context(), k.AccountName)
 
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
+		if err != nil {
+			if sgerrors.IsNotFound(err) {
+				http.NotFound(w, r)
+				return
+			}
+
+			message.SendUnknownError(w, err)
 			return
 		}
-
-		message.SendUnknownError(w, err)
-		return
 	}
 
 	config := &steps.Config{
@@ -430,15 +464,17 @@ func (h *Handler) deleteKube(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = util.FillCloudAccountCredentials(acc, config)
+	if acc != nil {
+		err = util.FillCloudAccountCredentials(acc, config)
 
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
+		if err != nil {
+			if sgerrors.IsNotFound(err) {
+				http.NotFound(w, r)
+				return
+			}
+			message.SendUnknownError(w, err)
 			return
 		}
-		message.SendUnknownError(w, err)
-		return
 	}
 
 	fileName := util.MakeFileName(t.ID)
@@ -450,7 +486,7 @@ func (h *Handler) deleteKube(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx, _ := context.WithTimeout(context.Background(), time.Minute*10)
-	errChan := t.Run(ctx, *config, writer)
+	errChan := t.Run(ctx, config, writer)
 
 	go func(t *workflows.Task) {
 		// Update kube with deleting state
@@ -629,24 +665,44 @@ func (h *Handler) addMachine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acc, err := h.accountService.Get(r.Context(), k.AccountName)
+	// Machines brought by user are provisioned without a cloud account
+	if k.Provider != clouds.BYO {
+		acc, err := h.accountService.Get(r.Context(), k.AccountName)
 
-	if sgerrors.IsNotFound(err) {
-		http.NotFound(w, r)
-		return
-	}
+		if sgerrors.IsNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Get cloud account fill appropriate config structure
+		// with cloud account credentials
+		err = util.FillCloudAccountCredentials(acc, config)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 	}
 
-	// Get cloud account fill appropriate config structure
-	// with cloud account credentials
-	err = util.FillCloudAccountCredentials(acc, config)
+	for _, p := range nodeProfiles {
+		if !isMasterProfile(p) {
+			continue
+		}
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err = checkMastersChange(k, ""); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		break
+	}
+
+	if err = h.prepareMasters(r.Context(), k, config, nodeProfiles); err != nil {
+		message.SendUnknownError(w, err)
 		return
 	}
 
@@ -665,7 +721,7 @@ func (h *Handler) addMachine(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Add tasks ids to kube object
-	k.Tasks[workflows.NodeTask] = append(k.Tasks[workflows.NodeTask], tasks...)
+	addTasks(k, nodeProfiles, tasks)
 
 	if err := h.svc.Create(ctx, k); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -701,9 +757,8 @@ func (h *Handler) deleteMachine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO(stgleb): check whether we will have quorum of master nodes if node is deleted.
-	if _, ok := k.Masters[nodeName]; ok {
-		http.Error(w, "delete master node not allowed", http.StatusMethodNotAllowed)
+	if m, ok := k.Masters[nodeName]; ok {
+		h.deleteMaster(w, r, k, m)
 		return
 	}
 
@@ -714,54 +769,13 @@ func (h *Handler) deleteMachine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acc, err := h.accountService.Get(r.Context(), k.AccountName)
-
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
-			return
-		}
-
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	config := &steps.Config{
-		Kube:     *k,
-		Provider: k.Provider,
-		DrainConfig: steps.DrainConfig{
-			PrivateIP: n.PrivateIp,
-		},
-		CloudAccountName: k.AccountName,
-		Node:             *n,
-		Masters:          steps.NewMap(k.Masters),
-	}
-
-	t, err := workflows.NewTask(config, workflows.DeleteNode, h.repo)
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
-			return
-		}
-
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	err = util.FillCloudAccountCredentials(acc, config)
-
+	t, config, err := h.newDeleteNodeTask(r.Context(), k, n)
 	if err != nil {
 		if sgerrors.IsNotFound(err) {
 			http.NotFound(w, r)
 			return
 		}
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	err = util.LoadCloudSpecificDataFromKube(k, config)
 
-	if err != nil {
 		message.SendUnknownError(w, err)
 		return
 	}
@@ -790,7 +804,7 @@ func (h *Handler) deleteMachine(w http.ResponseWriter, r *http.Request) {
 			logrus.Errorf("update cluster %s caused %v", kubeID, err)
 		}
 
-		err = <-t.Run(context.Background(), *config, writer)
+		err = <-t.Run(context.Background(), config, writer)
 
 		if err != nil {
 			logrus.Errorf("delete node %s from cluster %s caused %v", nodeName, kubeID, err)
@@ -809,6 +823,51 @@ func (h *Handler) deleteMachine(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// newDeleteNodeTask makes a task that drains the node and deletes its machine.
+func (h *Handler) newDeleteNodeTask(ctx context.Context, k *model.Kube, n *model.Machine) (*workflows.Task, *steps.Config, error) {
+	return h.newDeleteTask(ctx, k, n, workflows.DeleteNode)
+}
+
+func (h *Handler) newDeleteTask(ctx context.Context, k *model.Kube, n *model.Machine,
+	workflow string) (*workflows.Task, *steps.Config, error) {
+	// Machines brought by user are deleted without a cloud account
+	var acc *model.CloudAccount
+	if k.Provider != clouds.BYO {
+		var err error
+		if acc, err = h.accountService.Get(ctx, k.AccountName); err != nil {
+			return nil, nil, errors.Wrapf(err, "get account %s", k.AccountName)
+		}
+	}
+
+	config := &steps.Config{
+		Kube:     *k,
+		Provider: k.Provider,
+		DrainConfig: steps.DrainConfig{
+			PrivateIP: n.PrivateIp,
+		},
+		CloudAccountName: k.AccountName,
+		Node:             *n,
+		Masters:          steps.NewMap(k.Masters),
+	}
+
+	t, err := workflows.NewTask(config, workflow, h.repo)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "new %s task", workflow)
+	}
+
+	if acc != nil {
+		if err = util.FillCloudAccountCredentials(acc, config); err != nil {
+			return nil, nil, errors.Wrap(err, "fill cloud account credentials")
+		}
+	}
+
+	if err = util.LoadCloudSpecificDataFromKube(k, config); err != nil {
+		return nil, nil, errors.Wrap(err, "load cloud specific data")
+	}
+
+	return t, config, nil
+}
+
 // TODO(stgleb): Create separte task service to manage task object lifecycle
 func (h *Handler) getKubeTasks(ctx context.Context, kubeID string) ([]*workflows.Task, error) {
 	k, err := h.svc.Get(ctx, kubeID)
@@ -976,7 +1035,7 @@ func (h *Handler) installRelease(w http.ResponseWriter, r *http.Request) {
 			logrus.Errorf("error getting writer %v", err)
 		}
 
-		errCh := installAppTask.Run(context.Background(), *installAppTask.Config, writer)
+		errCh := installAppTask.Run(context.Background(), installAppTask.Config, writer)
 		err = <-errCh
 
 		if err != nil {
@@ -1030,7 +1089,7 @@ func (h *Handler) listReleases(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if k.State != model.StateOperational {
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
 		message.SendNotFound(w, kubeID, errors.New("kube is not operational"))
 		return
 	}
@@ -1311,29 +1370,31 @@ func (h *Handler) restartKubeProvisioning(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	logrus.Debugf("Get cloud account %s", k.AccountName)
-	acc, err := h.accountService.Get(r.Context(), k.AccountName)
+	if k.Provider != clouds.BYO {
+		logrus.Debugf("Get cloud account %s", k.AccountName)
+		acc, err := h.accountService.Get(r.Context(), k.AccountName)
 
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
+		if err != nil {
+			if sgerrors.IsNotFound(err) {
+				http.NotFound(w, r)
+				return
+			}
+
+			message.SendUnknownError(w, err)
 			return
 		}
 
-		message.SendUnknownError(w, err)
-		return
-	}
+		logrus.Debug("Fill config with cloud account credentials")
+		err = util.FillCloudAccountCredentials(acc, config)
 
-	logrus.Debug("Fill config with cloud account credentials")
-	err = util.FillCloudAccountCredentials(acc, config)
-
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
+		if err != nil {
+			if sgerrors.IsNotFound(err) {
+				http.NotFound(w, r)
+				return
+			}
+			message.SendUnknownError(w, err)
 			return
 		}
-		message.SendUnknownError(w, err)
-		return
 	}
 
 	logrus.Debugf("Restart cluster %s provisioning", k.ID)
@@ -1520,7 +1581,7 @@ func (h *Handler) importKube(w http.ResponseWriter, r *http.Request) {
 		config.Nodes = steps.NewMap(workers)
 
 		importTask.Config = config
-		resultChan := importTask.Run(context.Background(), *importTask.Config, writer)
+		resultChan := importTask.Run(context.Background(), importTask.Config, writer)
 		err = <-resultChan
 
 		if err != nil {
@@ -1541,131 +1602,6 @@ func (h *Handler) importKube(w http.ResponseWriter, r *http.Request) {
 	}()
 }
 
-func (h *Handler) upgradeKube(w http.ResponseWriter, r *http.Request) {
-	var err error
-
-	vars := mux.Vars(r)
-	kubeID := vars["kubeID"]
-
-	logrus.Debugf("Get kube %s", kubeID)
-	k, err := h.svc.Get(r.Context(), kubeID)
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			message.SendNotFound(w, kubeID, err)
-			return
-		}
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	if k.State != model.StateOperational {
-		w.WriteHeader(http.StatusNoContent)
-		logrus.Infof("Cluster %s is not operational", k.ID)
-		return
-	}
-
-	logrus.Debugf("Get cloud profile %s", k.ProfileID)
-	kubeProfile, err := h.profileSvc.Get(r.Context(), k.ProfileID)
-
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			message.SendNotFound(w, k.ProfileID, err)
-			return
-		}
-
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	config, err := steps.NewConfigFromKube(kubeProfile, k)
-
-	if err != nil {
-		logrus.Errorf("New config %v", err.Error())
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	// Load things specific to cloud provider
-	err = util.LoadCloudSpecificDataFromKube(k, config)
-
-	if err != nil {
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	nextVersion := findNextMinorVersion(k.K8SVersion, clouds.GetVersions())
-
-	if nextVersion == "" {
-		http.Error(w, fmt.Sprintf("can't upgrade from version %s", k.K8SVersion), http.StatusBadRequest)
-		return
-	}
-
-	if nextVersion == k.K8SVersion {
-		w.WriteHeader(http.StatusNoContent)
-		return
-	}
-
-	config.Kube.K8SVersion = nextVersion
-	tasks := h.makeUpgradeTasks(config, k)
-
-	go h.kubeProvisioner.UpgradeCluster(context.Background(), nextVersion, k, tasks, config)
-	node2TaskMap := mapNode2Task(tasks)
-
-	// here we are ready for async part
-	w.WriteHeader(http.StatusAccepted)
-	if err := json.NewEncoder(w).Encode(node2TaskMap); err != nil {
-		logrus.Errorf("Error encoding task map %v", err)
-	}
-}
-
-func (h *Handler) makeUpgradeTasks(config *steps.Config, k *model.Kube) map[string][]*workflows.Task {
-	masterTasks := make([]*workflows.Task, 0, len(k.Masters))
-	nodeTasks := make([]*workflows.Task, 0, len(k.Nodes))
-	//some clouds (e.g. AWS) requires running tasks before provisioning nodes (creating a VPC, Subnets, SecGroups, etc)
-
-	for _, masterMachine := range k.Masters {
-		masterTask, err := workflows.NewTask(config, workflows.Upgrade, h.repo)
-		if err != nil {
-			logrus.Errorf("Failed to set up task for %s workflow", workflows.ProvisionMaster)
-			continue
-		}
-
-		cfg := *config
-		cfg.Node = *masterMachine
-		cfg.IsMaster = true
-		cfg.IsBootstrap = false
-		masterTask.Config = &cfg
-		// Note(stgleb): Reuse task ID for machine provisioning that will allow to browse
-		// logs of machine upgrade without changes on the UI
-		masterTask.ID = masterMachine.TaskID
-		masterTasks = append(masterTasks, masterTask)
-	}
-
-	for _, nodeMachine := range k.Nodes {
-		nodeTask, err := workflows.NewTask(config, workflows.Upgrade, h.repo)
-		if err != nil {
-			logrus.Errorf("Failed to set up task for %s workflow", workflows.ProvisionNode)
-			continue
-		}
-		cfg := *config
-		cfg.Node = *nodeMachine
-		cfg.IsMaster = false
-		cfg.IsBootstrap = false
-		nodeTask.Config = &cfg
-		// Note(stgleb): Reuse task ID for machine provisioning that will allow to browse
-		// logs of machine upgrade without changes on the UI
-		nodeTask.ID = nodeMachine.ID
-		nodeTasks = append(nodeTasks, nodeTask)
-	}
-
-	taskMap := map[string][]*workflows.Task{
-		workflows.MasterTask: masterTasks,
-		workflows.NodeTask:   nodeTasks,
-	}
-
-	return taskMap
-}
-
 func (h *Handler) applyToKube(w http.ResponseWriter, r *http.Request) {
 	var err error
 
@@ -1690,7 +1626,7 @@ func (h *Handler) applyToKube(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if k.State != model.StateOperational {
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
 		w.WriteHeader(http.StatusNoContent)
 		logrus.Infof("Cluster %s is not operational", k.ID)
 		return
@@ -1750,7 +1686,7 @@ func (h *Handler) applyToKube(w http.ResponseWriter, r *http.Request) {
 
 	applyTask.Config = config
 	go func() {
-		err := <-applyTask.Run(context.Background(), *config, writer)
+		err := <-applyTask.Run(context.Background(), config, writer)
 
 		if err != nil {
 			logrus.Errorf("Error executing apply task %v", err)
@@ -1796,6 +1732,8 @@ func createKube(config *steps.Config, state model.KubeState, profile profile.Pro
 		OperatingSystemVersion: profile.UbuntuVersion,
 		K8SVersion:             profile.K8SVersion,
 		DockerVersion:          profile.DockerVersion,
+		ContainerRuntime:       profile.ContainerRuntime,
+		Mirrors:                profile.Mirrors,
 		HelmVersion:            profile.HelmVersion,
 		RBACEnabled:            profile.RBACEnabled,
 		ExternalDNSName:        config.Kube.ExternalDNSName,
@@ -135,11 +135,6 @@ func (m *mockProvisioner) RestartClusterProvisioning(ctx context.Context,
 	return val
 }
 
-func (m *mockProvisioner) UpgradeCluster(ctx context.Context, nextVersion string, k *model.Kube,
-	tasks map[string][]*workflows.Task, config *steps.Config) {
-	m.Called(ctx, nextVersion, tasks, config)
-}
-
 type bufferCloser struct {
 	bytes.Buffer
 	err error
@@ -1083,13 +1078,15 @@ func TestDeleteNodeFromKube(t *testing.T) {
 			http.StatusInternalServerError,
 		},
 		{
-			"method not allowed",
+			"last master",
 			"test",
 			"test",
 			&model.Kube{
+				State: model.StateOperational,
 				Masters: map[string]*model.Machine{
 					"test": {
-						Name: "test",
+						Name:  "test",
+						State: model.MachineStateActive,
 					},
 				},
 			},
@@ -1098,7 +1095,7 @@ func TestDeleteNodeFromKube(t *testing.T) {
 			nil,
 			nil,
 			nil,
-			http.StatusMethodNotAllowed,
+			http.StatusConflict,
 		},
 		{
 			"node not found",
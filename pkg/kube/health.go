@@ -0,0 +1,371 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/digitalocean/godo"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/clouds/digitaloceansdk"
+	"github.com/supergiant/control/pkg/kubeconfig"
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/amazon"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
+)
+
+// States of cloud instances.
+const (
+	instanceRunning = "running"
+	instanceStopped = "stopped"
+)
+
+// HealthMonitor regularly checks cloud instances, kubelets and API servers
+// of kubes, it updates states of machines and marks kubes degraded
+// when something is wrong with them.
+type HealthMonitor struct {
+	interval   time.Duration
+	h          *Handler
+	coreClient func(*model.Kube) (corev1client.CoreV1Interface, error)
+	// instances returns states of cloud instances of the kube by machine ids,
+	// machines that have no instance are missing.
+	instances func(context.Context, *model.Kube, *model.CloudAccount) (map[string]string, error)
+	now       func() time.Time
+}
+
+// NewHealthMonitor makes monitor that checks kubes every interval.
+func NewHealthMonitor(h *Handler, interval time.Duration) *HealthMonitor {
+	return &HealthMonitor{
+		interval:   interval,
+		h:          h,
+		coreClient: kubeconfig.CoreV1Client,
+		instances:  cloudInstances,
+		now:        time.Now,
+	}
+}
+
+// Run checks kubes every interval until the context is done.
+func (m *HealthMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *HealthMonitor) check(ctx context.Context) {
+	kubes, err := m.h.svc.ListAll(ctx)
+	if err != nil {
+		logrus.Errorf("health monitor: list kubes: %v", err)
+		return
+	}
+
+	for i := range kubes {
+		k := &kubes[i]
+		if k.State != model.StateOperational && k.State != model.StateDegraded {
+			continue
+		}
+
+		if err := m.checkKube(ctx, k); err != nil {
+			logrus.Errorf("health monitor: kube %s: %v", k.ID, err)
+		}
+	}
+}
+
+func (m *HealthMonitor) checkKube(ctx context.Context, k *model.Kube) error {
+	record := model.HealthRecord{
+		Time:     m.now(),
+		Machines: make(map[string]model.MachineState),
+	}
+
+	instances, err := m.cloudInstances(ctx, k)
+	if err != nil {
+		record.Problems = append(record.Problems, fmt.Sprintf("cloud instances: %v", err))
+	}
+
+	var nodes []corev1.Node
+	client, err := m.coreClient(k)
+	if err == nil {
+		var nodeList *corev1.NodeList
+		if nodeList, err = client.Nodes().List(metav1.ListOptions{}); err == nil {
+			nodes = nodeList.Items
+		}
+	}
+
+	if err != nil {
+		record.Problems = append(record.Problems, fmt.Sprintf("api server: %v", err))
+	} else {
+		record.APIServerReachable = true
+	}
+
+	states := make(map[string]model.MachineState)
+	for _, machines := range []map[string]*model.Machine{k.Masters, k.Nodes} {
+		for _, machine := range machines {
+			if machine == nil || !monitored(machine.State) {
+				continue
+			}
+
+			state := machineHealth(machine, instances, nodes, record.APIServerReachable)
+			states[machine.Name] = state
+			if state != model.MachineStateActive {
+				record.Machines[machine.Name] = state
+			}
+		}
+	}
+
+	record.State = model.StateOperational
+	if !record.APIServerReachable || len(record.Machines) > 0 {
+		record.State = model.StateDegraded
+	}
+	sort.Strings(record.Problems)
+
+	// Checks take a while, the kube is read again so that changes made
+	// meanwhile are kept and deleted kubes are not created back.
+	k, err = m.h.svc.Get(ctx, k.ID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "get kube")
+	}
+
+	for _, machines := range []map[string]*model.Machine{k.Masters, k.Nodes} {
+		for _, machine := range machines {
+			if machine == nil || !monitored(machine.State) {
+				continue
+			}
+			state, ok := states[machine.Name]
+			if !ok {
+				continue
+			}
+
+			if state != machine.State {
+				logrus.Infof("health monitor: machine %s of kube %s is %s, was %s",
+					machine.Name, k.ID, state, machine.State)
+				machine.State = state
+			}
+
+			if state != model.MachineStateActive {
+				if machine.UnhealthySince == 0 {
+					machine.UnhealthySince = record.Time.Unix()
+				}
+			} else {
+				machine.UnhealthySince = 0
+			}
+		}
+	}
+
+	// Kubes that have started a task meanwhile keep their state
+	healthy := k.State == model.StateOperational || k.State == model.StateDegraded
+	if healthy {
+		if record.State != k.State {
+			logrus.Infof("health monitor: kube %s is %s, was %s", k.ID, record.State, k.State)
+		}
+		k.State = record.State
+	}
+	k.Health.Record(record)
+
+	if err := m.h.svc.Create(ctx, k); err != nil {
+		return err
+	}
+
+	if healthy && k.AutoRepair != nil && k.AutoRepair.Enabled {
+		return errors.Wrap(m.repair(ctx, k), "auto repair")
+	}
+
+	return nil
+}
+
+func (h *Handler) getHealth(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(k.Health); err != nil {
+		message.SendUnknownError(w, err)
+	}
+}
+
+// cloudInstances returns nil when instances of the provider can't be listed.
+func (m *HealthMonitor) cloudInstances(ctx context.Context, k *model.Kube) (map[string]string, error) {
+	if k.Provider == clouds.BYO {
+		return nil, nil
+	}
+
+	acc, err := m.h.accountService.Get(ctx, k.AccountName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get account %s", k.AccountName)
+	}
+
+	instances, err := m.instances(ctx, k, acc)
+	if errors.Cause(err) == sgerrors.ErrUnsupportedProvider {
+		return nil, nil
+	}
+
+	return instances, err
+}
+
+// monitored checks the machine is not being changed by a workflow.
+func monitored(state model.MachineState) bool {
+	switch state {
+	case model.MachineStateActive, model.MachineStateNotReady,
+		model.MachineStateStopped, model.MachineStateMissing:
+		return true
+	}
+
+	return false
+}
+
+// machineHealth finds out state of the machine, states of cloud instance
+// go first, then Ready condition of the node. Kubelets are not checked when
+// API server is not reachable.
+func machineHealth(machine *model.Machine, instances map[string]string, nodes []corev1.Node,
+	apiServerReachable bool) model.MachineState {
+	if instances != nil {
+		switch instances[machine.ID] {
+		case "":
+			return model.MachineStateMissing
+		case instanceStopped:
+			return model.MachineStateStopped
+		}
+	}
+
+	if !apiServerReachable {
+		return model.MachineStateActive
+	}
+
+	for i := range nodes {
+		if machineForNode([]*model.Machine{machine}, &nodes[i]) == nil {
+			continue
+		}
+
+		if nodeReady(&nodes[i]) {
+			return model.MachineStateActive
+		}
+		break
+	}
+
+	return model.MachineStateNotReady
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// cloudInstances lists instances of the kube in its cloud.
+func cloudInstances(ctx context.Context, k *model.Kube, acc *model.CloudAccount) (map[string]string, error) {
+	config := &steps.Config{}
+	if err := util.FillCloudAccountCredentials(acc, config); err != nil {
+		return nil, errors.Wrap(err, "fill cloud account credentials")
+	}
+
+	switch k.Provider {
+	case clouds.AWS:
+		config.AWSConfig.Region = k.Region
+		return awsInstances(ctx, k, config)
+	case clouds.DigitalOcean:
+		return digitalOceanInstances(ctx, k, config)
+	case clouds.Simulator:
+		instances := make(map[string]string)
+		for _, m := range simulator.Default.ListMachines(k.ID) {
+			instances[m.ID] = instanceRunning
+		}
+		return instances, nil
+	}
+
+	return nil, errors.Wrapf(sgerrors.ErrUnsupportedProvider, "list %s instances", k.Provider)
+}
+
+func awsInstances(ctx context.Context, k *model.Kube, config *steps.Config) (map[string]string, error) {
+	EC2, err := amazon.GetEC2(config.AWSConfig)
+	if err != nil {
+		return nil, errors.Wrap(sgerrors.ErrInvalidCredentials, err.Error())
+	}
+
+	out, err := EC2.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String(fmt.Sprintf("tag:%s", clouds.TagClusterID)),
+				Values: aws.StringSlice([]string{k.ID}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describe instances")
+	}
+
+	instances := make(map[string]string)
+	for _, res := range out.Reservations {
+		for _, instance := range res.Instances {
+			if instance.InstanceId == nil || instance.State == nil {
+				continue
+			}
+
+			switch aws.StringValue(instance.State.Name) {
+			case ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning:
+				instances[*instance.InstanceId] = instanceRunning
+			case ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped:
+				instances[*instance.InstanceId] = instanceStopped
+			}
+		}
+	}
+
+	return instances, nil
+}
+
+func digitalOceanInstances(ctx context.Context, k *model.Kube, config *steps.Config) (map[string]string, error) {
+	client := digitaloceansdk.New(config.DigitalOceanConfig.AccessToken).GetClient()
+
+	droplets, _, err := client.Droplets.ListByTag(ctx, k.ID, &godo.ListOptions{PerPage: 200})
+	if err != nil {
+		return nil, errors.Wrap(err, "list droplets")
+	}
+
+	instances := make(map[string]string)
+	for _, d := range droplets {
+		switch d.Status {
+		case "new", "active":
+			instances[fmt.Sprintf("%d", d.ID)] = instanceRunning
+		case "off":
+			instances[fmt.Sprintf("%d", d.ID)] = instanceStopped
+		}
+	}
+
+	return instances, nil
+}
@@ -0,0 +1,211 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func healthKube() *model.Kube {
+	return &model.Kube{
+		ID:          "kube1234",
+		State:       model.StateOperational,
+		Provider:    clouds.DigitalOcean,
+		AccountName: "test",
+		Masters: map[string]*model.Machine{
+			"master": {ID: "1", Name: "master", State: model.MachineStateActive},
+		},
+		Nodes: map[string]*model.Machine{
+			"node-1": {ID: "2", Name: "node-1", State: model.MachineStateActive},
+			"node-2": {ID: "3", Name: "node-2", PrivateIp: "10.0.0.3", State: model.MachineStateActive},
+			"node-3": {ID: "4", Name: "node-3", State: model.MachineStateProvisioning},
+		},
+	}
+}
+
+func readyNode(name, ip string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func testHealthMonitor(k *model.Kube, instances map[string]string, clientErr error,
+	nodes ...*corev1.Node) *HealthMonitor {
+	svc := new(kubeServiceMock)
+	svc.On(serviceListAll, mock.Anything).Return([]model.Kube{*k}, nil)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+
+	accService := new(accServiceMock)
+	accService.On("Get", mock.Anything, mock.Anything).
+		Return(&model.CloudAccount{Provider: clouds.DigitalOcean}, nil)
+
+	client := fake.NewSimpleClientset()
+	for _, n := range nodes {
+		client.CoreV1().Nodes().Create(n)
+	}
+
+	m := NewHealthMonitor(&Handler{svc: svc, accountService: accService}, time.Minute)
+	m.coreClient = func(*model.Kube) (corev1client.CoreV1Interface, error) {
+		return client.CoreV1(), clientErr
+	}
+	m.instances = func(context.Context, *model.Kube, *model.CloudAccount) (map[string]string, error) {
+		return instances, nil
+	}
+
+	return m
+}
+
+func TestHealthMonitorCheckKube(t *testing.T) {
+	k := healthKube()
+	m := testHealthMonitor(k, map[string]string{"1": instanceRunning, "2": instanceRunning, "3": instanceRunning},
+		nil,
+		readyNode("master", "", corev1.ConditionTrue),
+		readyNode("node-1", "", corev1.ConditionTrue),
+		readyNode("ip-10-0-0-3", "10.0.0.3", corev1.ConditionTrue),
+	)
+
+	if err := m.checkKube(context.Background(), k); err != nil {
+		t.Fatalf("check kube %v", err)
+	}
+
+	if k.State != model.StateOperational || !k.Health.Last().APIServerReachable ||
+		k.Nodes["node-3"].State != model.MachineStateProvisioning {
+		t.Errorf("kube must be healthy %v %v", k.State, k.Health.Last())
+	}
+
+	m = testHealthMonitor(k, map[string]string{"1": instanceRunning, "3": instanceStopped}, nil,
+		readyNode("master", "", corev1.ConditionFalse),
+	)
+	if err := m.checkKube(context.Background(), k); err != nil {
+		t.Fatalf("check kube %v", err)
+	}
+
+	expected := map[string]model.MachineState{
+		"master": model.MachineStateNotReady,
+		"node-1": model.MachineStateMissing,
+		"node-2": model.MachineStateStopped,
+	}
+	for name, state := range expected {
+		if machine := k.Masters[name]; machine != nil && machine.State != state {
+			t.Errorf("expected master %s to be %s actual %s", name, state, machine.State)
+		}
+		if machine := k.Nodes[name]; machine != nil && machine.State != state {
+			t.Errorf("expected node %s to be %s actual %s", name, state, machine.State)
+		}
+	}
+
+	if k.State != model.StateDegraded || len(k.Health.History) != 2 || len(k.Health.Last().Machines) != 3 {
+		t.Errorf("kube must be degraded %v %v", k.State, k.Health.History)
+	}
+}
+
+func TestHealthMonitorAPIServerUnreachable(t *testing.T) {
+	k := healthKube()
+	m := testHealthMonitor(k, nil, errors.New("connection refused"))
+
+	if err := m.checkKube(context.Background(), k); err != nil {
+		t.Fatalf("check kube %v", err)
+	}
+
+	last := k.Health.Last()
+	if k.State != model.StateDegraded || last.APIServerReachable || len(last.Problems) != 1 ||
+		!strings.Contains(last.Problems[0], "connection refused") {
+		t.Errorf("wrong health %v", last)
+	}
+
+	// Kubelets can't be checked without API server
+	if k.Nodes["node-1"].State != model.MachineStateActive {
+		t.Errorf("node state must be kept %s", k.Nodes["node-1"].State)
+	}
+}
+
+func TestHealthMonitorKeepsChanges(t *testing.T) {
+	snapshot := healthKube()
+	k := healthKube()
+	m := testHealthMonitor(k, map[string]string{"1": instanceRunning, "3": instanceRunning}, nil,
+		readyNode("master", "", corev1.ConditionTrue),
+		readyNode("ip-10-0-0-3", "10.0.0.3", corev1.ConditionTrue),
+	)
+
+	// The kube is upgraded and a node is deleted while the check runs
+	k.State = model.StateUpgrading
+	k.Nodes["node-2"].State = model.MachineStateUpgrading
+	delete(k.Nodes, "node-1")
+	k.Tasks = map[string][]string{"upgrade": {"task"}}
+
+	if err := m.checkKube(context.Background(), snapshot); err != nil {
+		t.Fatalf("check kube %v", err)
+	}
+
+	if k.State != model.StateUpgrading || k.Nodes["node-2"].State != model.MachineStateUpgrading ||
+		k.Nodes["node-1"] != nil || len(k.Tasks) != 1 {
+		t.Errorf("changes of the kube must be kept %v %v %v", k.State, k.Nodes, k.Tasks)
+	}
+	if len(k.Health.History) != 1 || k.Health.Last().Machines["node-1"] != model.MachineStateMissing {
+		t.Errorf("health must be recorded %v", k.Health.History)
+	}
+}
+
+func TestHealthMonitorDeletedKube(t *testing.T) {
+	k := healthKube()
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(nil, sgerrors.ErrNotFound)
+	m := testHealthMonitor(k, nil, nil)
+	m.h.svc = svc
+
+	if err := m.checkKube(context.Background(), k); err != nil {
+		t.Fatalf("check kube %v", err)
+	}
+
+	svc.AssertNotCalled(t, serviceCreate, mock.Anything, mock.Anything)
+}
+
+func TestHealthMonitorSkipsKubes(t *testing.T) {
+	k := healthKube()
+	k.State = model.StateProvisioning
+	m := testHealthMonitor(k, nil, nil)
+
+	m.check(context.Background())
+
+	m.h.svc.(*kubeServiceMock).AssertNotCalled(t, serviceCreate, mock.Anything, mock.Anything)
+}
+
+func TestGetHealth(t *testing.T) {
+	k := healthKube()
+	k.Health.Record(model.HealthRecord{Time: time.Now(), State: model.StateOperational})
+
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+
+	h := Handler{svc: svc}
+	router := mux.NewRouter()
+	router.HandleFunc("/kubes/{kubeID}/health", h.getHealth)
+
+	req, _ := http.NewRequest(http.MethodGet, "/kubes/kube1234/health", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"state":"operational"`) {
+		t.Errorf("wrong response %d %s", rec.Code, rec.Body.String())
+	}
+}
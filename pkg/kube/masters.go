@@ -0,0 +1,311 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/copycerts"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const isMasterKey = "isMaster"
+
+var ErrNoQuorum = errors.New("etcd quorum would be lost")
+
+// MastersRequest adds masters of the same size as the first master
+// of the kube profile.
+type MastersRequest struct {
+	Count int `json:"count"`
+}
+
+// addMasters joins new masters to the running kube.
+func (h *Handler) addMasters(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Provider == clouds.BYO {
+		http.Error(w, "machines brought by user are added with their profiles to /machines",
+			http.StatusBadRequest)
+		return
+	}
+
+	req := &MastersRequest{}
+	if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	if req.Count < 1 {
+		http.Error(w, fmt.Sprintf("invalid count of masters %d", req.Count), http.StatusBadRequest)
+		return
+	}
+
+	if err = checkMastersChange(k, ""); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	nodeProfiles, err := h.masterProfiles(r.Context(), k, req.Count)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, k.ProfileID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	tasks, err := h.addNodes(r.Context(), k, nodeProfiles)
+	if err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode(tasks); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// masterProfiles makes count profiles of masters of the same size
+// as the first master of the kube profile.
+func (h *Handler) masterProfiles(ctx context.Context, k *model.Kube, count int) ([]profile.NodeProfile, error) {
+	kubeProfile, err := h.profileSvc.Get(ctx, k.ProfileID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
+	if len(kubeProfile.MasterProfiles) == 0 {
+		return nil, errors.Wrapf(sgerrors.ErrNotFound, "master profile of %s", k.ProfileID)
+	}
+
+	nodeProfiles := make([]profile.NodeProfile, 0, count)
+	for i := 0; i < count; i++ {
+		nodeProfile := profile.NodeProfile{isMasterKey: "true"}
+		for key, value := range kubeProfile.MasterProfiles[0] {
+			nodeProfile[key] = value
+		}
+		nodeProfiles = append(nodeProfiles, nodeProfile)
+	}
+
+	return nodeProfiles, nil
+}
+
+// deleteMaster removes the master and its etcd member from the kube.
+func (h *Handler) deleteMaster(w http.ResponseWriter, r *http.Request, k *model.Kube, m *model.Machine) {
+	if err := checkMastersChange(k, m.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	taskID, err := h.removeMaster(r.Context(), k, m)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, m.Name, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode([]string{taskID}); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// removeMaster runs the workflow that deletes the master, the change
+// must be checked by checkMastersChange first.
+func (h *Handler) removeMaster(ctx context.Context, k *model.Kube, m *model.Machine) (string, error) {
+	// Other masters run commands of the workflow, the deleted one
+	// must not be picked for that
+	state := m.State
+	m.State = model.MachineStateDeleting
+
+	t, config, err := h.newDeleteTask(ctx, k, m, workflows.DeleteMaster)
+	if err != nil {
+		m.State = state
+		return "", err
+	}
+	config.IsMaster = true
+
+	writer, err := h.getWriter(util.MakeFileName(t.ID))
+	if err != nil {
+		m.State = state
+		return "", errors.Wrapf(err, "get writer for %s", t.ID)
+	}
+
+	if err = h.svc.Create(ctx, k); err != nil {
+		return "", errors.Wrapf(err, "update kube %s", k.ID)
+	}
+
+	kubeID, name := k.ID, m.Name
+	go func() {
+		err := <-t.Run(context.Background(), config, writer)
+		if err != nil {
+			logrus.Errorf("delete master %s from kube %s caused %v", name, kubeID, err)
+		}
+
+		if err := h.forgetMaster(kubeID, name, err); err != nil {
+			logrus.Errorf("update kube %s caused %v", kubeID, err)
+		}
+	}()
+
+	return t.ID, nil
+}
+
+// forgetMaster removes the master from the kube object, the master
+// that has failed to be removed is kept in error state. Spec of the kube
+// follows masters removed by users, so that they are not added back.
+func (h *Handler) forgetMaster(kubeID, name string, taskErr error) error {
+	k, err := h.svc.Get(context.Background(), kubeID)
+	if err != nil {
+		return err
+	}
+
+	if taskErr != nil {
+		if m := k.Masters[name]; m != nil {
+			m.State = model.MachineStateError
+		}
+	} else {
+		logrus.Infof("delete master %s from kube %s", name, kubeID)
+		delete(k.Masters, name)
+
+		if k.Spec != nil && k.Spec.Masters > len(k.Masters) {
+			k.Spec.Masters = len(k.Masters)
+			k.Spec.Generation++
+		}
+	}
+
+	return h.svc.Create(context.Background(), k)
+}
+
+// checkMastersChange allows one change of masters at a time, removed
+// is a name of the master to be removed or empty when masters are added.
+// Active masters must make etcd quorum both before and after the removal.
+func checkMastersChange(k *model.Kube, removed string) error {
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		return errors.Errorf("kube %s is %s", k.ID, k.State)
+	}
+
+	members, active := 0, 0
+	for name, m := range k.Masters {
+		if m == nil {
+			continue
+		}
+
+		if m.State == model.MachineStateProvisioning || m.State == model.MachineStateDeleting {
+			return errors.Errorf("master %s is %s", name, m.State)
+		}
+
+		members++
+		if m.State == model.MachineStateActive {
+			active++
+		}
+	}
+
+	if removed == "" {
+		return nil
+	}
+
+	// Member is removed by the cluster it belongs to
+	if quorum := members/2 + 1; active < quorum {
+		return errors.Wrapf(ErrNoQuorum, "%d of %d masters are active, %d needed",
+			active, members, quorum)
+	}
+
+	if m := k.Masters[removed]; m != nil {
+		members--
+		if m.State == model.MachineStateActive {
+			active--
+		}
+	}
+
+	if members == 0 {
+		return errors.Wrapf(ErrNoQuorum, "master %s is the last one", removed)
+	}
+
+	if quorum := members/2 + 1; active < quorum {
+		return errors.Wrapf(ErrNoQuorum, "%d of %d masters that stay are active, %d needed",
+			active, members, quorum)
+	}
+
+	return nil
+}
+
+// prepareMasters lets masters of the profiles join the running kube,
+// certificates uploaded by kubeadm expire, so they are uploaded
+// again with a fresh certificate key. The kube is saved because
+// provisioner reloads it from the storage.
+func (h *Handler) prepareMasters(ctx context.Context, k *model.Kube, config *steps.Config, nodeProfiles []profile.NodeProfile) error {
+	count := 0
+	for _, p := range nodeProfiles {
+		if isMasterProfile(p) {
+			count++
+		}
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	key, err := copycerts.CreateCertificateKey()
+	if err != nil {
+		return errors.Wrap(err, "create certificate key")
+	}
+
+	k.Auth.CertificateKey = key
+	config.Kube.Auth.CertificateKey = key
+	config.UploadCerts = true
+
+	// Spec already has masters added by reconciliation
+	if k.Spec != nil && k.Spec.Masters < len(k.Masters)+count {
+		k.Spec.Masters = len(k.Masters) + count
+		k.Spec.Generation++
+	}
+
+	return errors.Wrap(h.svc.Create(ctx, k), "save kube")
+}
+
+// addTasks saves ids of tasks that provision machines of the profiles to the kube.
+func addTasks(k *model.Kube, nodeProfiles []profile.NodeProfile, tasks []string) {
+	if k.Tasks == nil {
+		k.Tasks = make(map[string][]string)
+	}
+
+	for i, id := range tasks {
+		taskType := workflows.NodeTask
+		if i < len(nodeProfiles) && isMasterProfile(nodeProfiles[i]) {
+			taskType = workflows.MasterTask
+		}
+		k.Tasks[taskType] = append(k.Tasks[taskType], id)
+	}
+}
+
+func isMasterProfile(p profile.NodeProfile) bool {
+	isMaster, _ := strconv.ParseBool(p[isMasterKey])
+	return isMaster
+}
@@ -0,0 +1,289 @@
+package kube
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func mastersKube(states ...model.MachineState) *model.Kube {
+	k := &model.Kube{
+		ID:          "kube1234",
+		State:       model.StateOperational,
+		Provider:    clouds.DigitalOcean,
+		AccountName: "test",
+		ProfileID:   "profile",
+		Masters:     make(map[string]*model.Machine),
+		Nodes:       make(map[string]*model.Machine),
+		Tasks:       make(map[string][]string),
+		Spec:        &model.KubeSpec{Masters: len(states)},
+	}
+
+	for i, state := range states {
+		name := string(rune('a'+i)) + "-master"
+		k.Masters[name] = &model.Machine{Name: name, State: state, Role: model.RoleMaster}
+	}
+
+	return k
+}
+
+func mastersHandler(k *model.Kube) (*Handler, *mockNodeProvisioner) {
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+
+	profileSvc := new(mockProfileService)
+	profileSvc.On("Get", mock.Anything, mock.Anything).
+		Return(&profile.Profile{
+			MasterProfiles: []profile.NodeProfile{{"size": "s-2vcpu-4gb"}},
+		}, nil)
+
+	accService := new(accServiceMock)
+	accService.On("Get", mock.Anything, mock.Anything).
+		Return(&model.CloudAccount{Provider: clouds.DigitalOcean}, nil)
+
+	provisioner := new(mockNodeProvisioner)
+	provisioner.On("ProvisionNodes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]string{"task1", "task2"}, nil)
+
+	repo := new(testutils.MockStorage)
+	repo.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+
+	return &Handler{
+		svc:             svc,
+		accountService:  accService,
+		profileSvc:      profileSvc,
+		nodeProvisioner: provisioner,
+		repo:            repo,
+		getWriter: func(string) (io.WriteCloser, error) {
+			return &bufferCloser{}, nil
+		},
+	}, provisioner
+}
+
+func TestCheckMastersChange(t *testing.T) {
+	testCases := []struct {
+		description string
+		kube        *model.Kube
+		removed     string
+		noQuorum    bool
+		ok          bool
+	}{
+		{
+			description: "add master",
+			kube:        mastersKube(model.MachineStateActive),
+			ok:          true,
+		},
+		{
+			description: "add master to degraded kube",
+			kube: func() *model.Kube {
+				k := mastersKube(model.MachineStateActive, model.MachineStateNotReady)
+				k.State = model.StateDegraded
+				return k
+			}(),
+			ok: true,
+		},
+		{
+			description: "kube is upgrading",
+			kube: func() *model.Kube {
+				k := mastersKube(model.MachineStateActive)
+				k.State = model.StateUpgrading
+				return k
+			}(),
+		},
+		{
+			description: "master is being added",
+			kube:        mastersKube(model.MachineStateActive, model.MachineStateProvisioning),
+		},
+		{
+			description: "remove one of three",
+			kube:        mastersKube(model.MachineStateActive, model.MachineStateActive, model.MachineStateActive),
+			removed:     "a-master",
+			ok:          true,
+		},
+		{
+			description: "remove unhealthy master",
+			kube:        mastersKube(model.MachineStateActive, model.MachineStateActive, model.MachineStateNotReady),
+			removed:     "c-master",
+			ok:          true,
+		},
+		{
+			description: "remove healthy master while another is unhealthy",
+			kube:        mastersKube(model.MachineStateActive, model.MachineStateActive, model.MachineStateNotReady),
+			removed:     "a-master",
+			noQuorum:    true,
+		},
+		{
+			description: "remove unhealthy master without quorum",
+			kube: mastersKube(model.MachineStateActive, model.MachineStateActive,
+				model.MachineStateNotReady, model.MachineStateNotReady),
+			removed:  "c-master",
+			noQuorum: true,
+		},
+		{
+			description: "remove last master",
+			kube:        mastersKube(model.MachineStateActive),
+			removed:     "a-master",
+			noQuorum:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := checkMastersChange(testCase.kube, testCase.removed)
+
+		if testCase.ok != (err == nil) {
+			t.Errorf("%s: unexpected error %v", testCase.description, err)
+		}
+		if testCase.noQuorum && errors.Cause(err) != ErrNoQuorum {
+			t.Errorf("%s: expected error %v actual %v", testCase.description, ErrNoQuorum, err)
+		}
+	}
+}
+
+func TestAddMasters(t *testing.T) {
+	testCases := []struct {
+		description  string
+		kube         *model.Kube
+		body         string
+		expectedCode int
+	}{
+		{
+			description:  "add masters",
+			kube:         mastersKube(model.MachineStateActive),
+			body:         `{"count": 2}`,
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			description:  "invalid count",
+			kube:         mastersKube(model.MachineStateActive),
+			body:         `{"count": 0}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description: "kube is provisioning",
+			kube: func() *model.Kube {
+				k := mastersKube(model.MachineStateActive)
+				k.State = model.StateProvisioning
+				return k
+			}(),
+			body:         `{"count": 1}`,
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		k := testCase.kube
+		h, provisioner := mastersHandler(k)
+
+		router := mux.NewRouter()
+		router.HandleFunc("/kubes/{kubeID}/masters", h.addMasters)
+
+		req, _ := http.NewRequest(http.MethodPost, "/kubes/kube1234/masters",
+			bytes.NewBufferString(testCase.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.expectedCode {
+			t.Errorf("wrong response code expected %d actual %d %s",
+				testCase.expectedCode, rec.Code, rec.Body.String())
+			continue
+		}
+
+		if rec.Code != http.StatusAccepted {
+			continue
+		}
+
+		expected := []profile.NodeProfile{
+			{"size": "s-2vcpu-4gb", isMasterKey: "true"},
+			{"size": "s-2vcpu-4gb", isMasterKey: "true"},
+		}
+		provisioner.AssertCalled(t, "ProvisionNodes", mock.Anything, expected, mock.Anything,
+			mock.MatchedBy(func(config *steps.Config) bool {
+				return config.UploadCerts && config.Kube.Auth.CertificateKey == k.Auth.CertificateKey
+			}))
+
+		if k.Auth.CertificateKey == "" || len(k.Tasks[workflows.MasterTask]) != 2 ||
+			k.Spec.Masters != 3 || k.Spec.Generation != 1 {
+			t.Errorf("wrong kube %v %v %v", k.Auth.CertificateKey, k.Tasks, k.Spec)
+		}
+	}
+}
+
+func TestDeleteMaster(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.DeleteMaster, []steps.Step{upgradeStep{run: func(*steps.Config) error {
+		<-release
+		return nil
+	}}})
+
+	k := mastersKube(model.MachineStateActive, model.MachineStateActive, model.MachineStateActive)
+	h, _ := mastersHandler(k)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/kubes/{kubeID}/machines/{nodename}", h.deleteMachine)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/kubes/kube1234/machines/b-master", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("wrong response code %d %s", rec.Code, rec.Body.String())
+	}
+
+	tasks := []string{}
+	if err := json.NewDecoder(rec.Body).Decode(&tasks); err != nil || len(tasks) != 1 {
+		t.Errorf("wrong tasks %v %v", tasks, err)
+	}
+
+	// Spec is changed once the master has been deleted
+	if k.Masters["b-master"].State != model.MachineStateDeleting || k.Spec.Masters != 3 {
+		t.Errorf("master must be deleting %v %v", k.Masters["b-master"], k.Spec)
+	}
+
+	// Another removal must wait
+	req, _ = http.NewRequest(http.MethodDelete, "/kubes/kube1234/machines/a-master", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("wrong response code %d", rec.Code)
+	}
+}
+
+func TestForgetMaster(t *testing.T) {
+	k := mastersKube(model.MachineStateDeleting, model.MachineStateDeleting, model.MachineStateActive)
+	h, _ := mastersHandler(k)
+
+	if err := h.forgetMaster(k.ID, "a-master", errors.New("etcd")); err != nil {
+		t.Fatalf("forget master %v", err)
+	}
+	if err := h.forgetMaster(k.ID, "b-master", nil); err != nil {
+		t.Fatalf("forget master %v", err)
+	}
+
+	if k.Masters["a-master"].State != model.MachineStateError || k.Masters["b-master"] != nil {
+		t.Errorf("wrong masters %v", k.Masters)
+	}
+	if k.Spec.Masters != 2 || k.Spec.Generation != 1 {
+		t.Errorf("spec must follow deleted masters %v", k.Spec)
+	}
+}
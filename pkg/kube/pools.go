@@ -0,0 +1,361 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// PoolRequest sets the desired state of a node pool, fields that are omitted
+// keep values of the existing pool. Size, zones, labels and taints only
+// affect nodes added after the change.
+type PoolRequest struct {
+	Size   string            `json:"size"`
+	Count  *int              `json:"count"`
+	Zones  []string          `json:"zones"`
+	Labels map[string]string `json:"labels"`
+	Taints []profile.Taint   `json:"taints"`
+
+	Autoscaling *profile.Autoscaling `json:"autoscaling"`
+}
+
+// PoolResponse has the pool and ids of tasks that add or delete its nodes.
+type PoolResponse struct {
+	Pool  profile.NodePool `json:"pool"`
+	Tasks []string         `json:"tasks"`
+}
+
+func (h *Handler) listPools(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	pools := make([]profile.NodePool, 0, len(k.NodePools))
+	for _, pool := range k.NodePools {
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+
+	if err = json.NewEncoder(w).Encode(pools); err != nil {
+		message.SendUnknownError(w, err)
+	}
+}
+
+// updatePool creates or updates the node pool and adds or deletes
+// its nodes to match the desired count.
+func (h *Handler) updatePool(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	kubeID := vars["kubeID"]
+	poolName := vars["pool"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Provider == clouds.BYO {
+		http.Error(w, "node pools are not supported for machines brought by user",
+			http.StatusBadRequest)
+		return
+	}
+
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		http.Error(w, fmt.Sprintf("kube %s is %s", k.ID, k.State), http.StatusConflict)
+		return
+	}
+
+	req := &PoolRequest{}
+	if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	pool := mergePool(k.NodePools[poolName], poolName, req)
+	if err = pool.Validate(); err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	if k.NodePools == nil {
+		k.NodePools = make(map[string]profile.NodePool)
+	}
+	k.NodePools[poolName] = pool
+	if k.Spec != nil {
+		setSpecPool(k.Spec, pool)
+	}
+
+	// Pool is saved first, provisioning takes labels and taints from the kube
+	if err = h.svc.Create(r.Context(), k); err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	resp := PoolResponse{
+		Pool:  pool,
+		Tasks: []string{},
+	}
+
+	machines := poolMachines(k, poolName)
+	if diff := pool.Count - len(machines); diff > 0 {
+		logrus.Infof("add %d nodes to pool %s of kube %s", diff, poolName, kubeID)
+		resp.Tasks, err = h.addPoolNodes(r.Context(), k, pool, machines, diff)
+	} else if diff < 0 {
+		logrus.Infof("delete %d nodes from pool %s of kube %s", -diff, poolName, kubeID)
+		resp.Tasks, err = h.deletePoolNodes(r.Context(), k, nodesToDelete(machines, -diff))
+	}
+
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, poolName, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+func (h *Handler) addPoolNodes(ctx context.Context, k *model.Kube, pool profile.NodePool,
+	machines []*model.Machine, count int) ([]string, error) {
+	// New nodes go to the zones that have the least nodes of the pool
+	zones := make(map[string]int)
+	for _, m := range machines {
+		zones[m.AvailabilityZone]++
+	}
+
+	return h.addNodes(ctx, k, pool.NodeProfiles(k.Provider, zones, count))
+}
+
+// addNodes provisions nodes of the profiles and saves ids of their tasks to the kube.
+func (h *Handler) addNodes(ctx context.Context, k *model.Kube, nodeProfiles []profile.NodeProfile) ([]string, error) {
+	kubeProfile, err := h.profileSvc.Get(ctx, k.ProfileID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
+	config, err := steps.NewConfigFromKube(kubeProfile, k)
+	if err != nil {
+		return nil, errors.Wrap(err, "new config")
+	}
+
+	acc, err := h.accountService.Get(ctx, k.AccountName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get account %s", k.AccountName)
+	}
+
+	if err = util.FillCloudAccountCredentials(acc, config); err != nil {
+		return nil, errors.Wrap(err, "fill cloud account credentials")
+	}
+
+	if err = h.prepareMasters(ctx, k, config, nodeProfiles); err != nil {
+		return nil, errors.Wrap(err, "prepare masters")
+	}
+
+	provisionCtx, cancel := context.WithTimeout(context.Background(), time.Minute*60)
+	time.AfterFunc(time.Minute*60, cancel)
+	tasks, err := h.nodeProvisioner.ProvisionNodes(provisionCtx, nodeProfiles, k, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "provision nodes")
+	}
+
+	addTasks(k, nodeProfiles, tasks)
+
+	if err = h.svc.Create(ctx, k); err != nil {
+		return nil, errors.Wrapf(err, "update kube %s", k.ID)
+	}
+
+	return tasks, nil
+}
+
+// deletePoolNodes drains and deletes the nodes one by one.
+func (h *Handler) deletePoolNodes(ctx context.Context, k *model.Kube, nodes []*model.Machine) ([]string, error) {
+	tasks := make([]*workflows.Task, 0, len(nodes))
+	configs := make([]*steps.Config, 0, len(nodes))
+	writers := make([]io.WriteCloser, 0, len(nodes))
+
+	for _, n := range nodes {
+		t, config, err := h.newDeleteNodeTask(ctx, k, n)
+		if err != nil {
+			return nil, errors.Wrapf(err, "delete node %s", n.Name)
+		}
+
+		writer, err := h.getWriter(util.MakeFileName(t.ID))
+		if err != nil {
+			return nil, errors.Wrapf(err, "get writer for %s", t.ID)
+		}
+
+		tasks = append(tasks, t)
+		configs = append(configs, config)
+		writers = append(writers, writer)
+	}
+
+	taskIDs := make([]string, 0, len(tasks))
+	for i, n := range nodes {
+		n.State = model.MachineStateDeleting
+		taskIDs = append(taskIDs, tasks[i].ID)
+	}
+
+	if err := h.svc.Create(ctx, k); err != nil {
+		return nil, errors.Wrapf(err, "update kube %s", k.ID)
+	}
+
+	go func() {
+		for i, t := range tasks {
+			if err := <-t.Run(context.Background(), configs[i], writers[i]); err != nil {
+				logrus.Errorf("delete node %s from cluster %s caused %v", nodes[i].Name, k.ID, err)
+			}
+
+			if err := h.forgetNode(k.ID, nodes[i].Name); err != nil {
+				logrus.Errorf("update cluster %s caused %v", k.ID, err)
+			}
+		}
+	}()
+
+	return taskIDs, nil
+}
+
+// forgetNode removes the node from the kube object.
+func (h *Handler) forgetNode(kubeID, nodeName string) error {
+	k, err := h.svc.Get(context.Background(), kubeID)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("delete node %s from cluster %s", nodeName, kubeID)
+	delete(k.Nodes, nodeName)
+
+	return h.svc.Create(context.Background(), k)
+}
+
+func mergePool(pool profile.NodePool, name string, req *PoolRequest) profile.NodePool {
+	pool.Name = name
+
+	if req.Size != "" {
+		pool.Size = req.Size
+	}
+	if req.Count != nil {
+		pool.Count = *req.Count
+	}
+	if req.Zones != nil {
+		pool.Zones = req.Zones
+	}
+	if req.Labels != nil {
+		pool.Labels = req.Labels
+	}
+	if req.Taints != nil {
+		pool.Taints = req.Taints
+	}
+	if req.Autoscaling != nil {
+		pool.Autoscaling = req.Autoscaling
+	}
+
+	return pool
+}
+
+// setSpecPool puts the pool to the spec, so the reconciler
+// doesn't revert changes made with the pools API.
+func setSpecPool(spec *model.KubeSpec, pool profile.NodePool) {
+	spec.Generation++
+
+	for i := range spec.NodePools {
+		if spec.NodePools[i].Name == pool.Name {
+			spec.NodePools[i] = pool
+			return
+		}
+	}
+
+	spec.NodePools = append(spec.NodePools, pool)
+}
+
+// poolMachines returns nodes of the pool that are not being deleted.
+func poolMachines(k *model.Kube, poolName string) []*model.Machine {
+	machines := make([]*model.Machine, 0)
+
+	for _, n := range k.Nodes {
+		if n != nil && n.Pool == poolName && n.State != model.MachineStateDeleting {
+			machines = append(machines, n)
+		}
+	}
+
+	return machines
+}
+
+// nodesToDelete picks count nodes: failed ones go first, then the newest
+// ones from the zones that have the most nodes, so the pool stays spread.
+func nodesToDelete(machines []*model.Machine, count int) []*model.Machine {
+	zones := make(map[string]int)
+	for _, m := range machines {
+		zones[m.AvailabilityZone]++
+	}
+
+	left := append([]*model.Machine(nil), machines...)
+	picked := make([]*model.Machine, 0, count)
+
+	for ; count > 0 && len(left) > 0; count-- {
+		best := 0
+		for i := 1; i < len(left); i++ {
+			if deleteBefore(left[i], left[best], zones) {
+				best = i
+			}
+		}
+
+		zones[left[best].AvailabilityZone]--
+		picked = append(picked, left[best])
+		left = append(left[:best], left[best+1:]...)
+	}
+
+	return picked
+}
+
+func deleteBefore(a, b *model.Machine, zones map[string]int) bool {
+	aActive, bActive := a.State == model.MachineStateActive, b.State == model.MachineStateActive
+	if aActive != bActive {
+		return !aActive
+	}
+
+	if zones[a.AvailabilityZone] != zones[b.AvailabilityZone] {
+		return zones[a.AvailabilityZone] > zones[b.AvailabilityZone]
+	}
+
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt > b.CreatedAt
+	}
+
+	return a.Name > b.Name
+}
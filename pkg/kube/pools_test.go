@@ -0,0 +1,303 @@
+package kube
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func poolKube() *model.Kube {
+	return &model.Kube{
+		ID:          "kube1234",
+		State:       model.StateOperational,
+		Provider:    clouds.DigitalOcean,
+		AccountName: "test",
+		Masters: map[string]*model.Machine{
+			"master": {Name: "master", Role: model.RoleMaster},
+		},
+		Nodes: map[string]*model.Machine{
+			"node-1": {Name: "node-1", Pool: "workers", State: model.MachineStateActive, CreatedAt: 1},
+			"node-2": {Name: "node-2", Pool: "workers", State: model.MachineStateActive, CreatedAt: 2},
+			"node-3": {Name: "node-3", State: model.MachineStateActive},
+		},
+		NodePools: map[string]profile.NodePool{
+			"workers": {
+				Name:   "workers",
+				Size:   "s-2vcpu-4gb",
+				Count:  2,
+				Labels: map[string]string{"role": "worker"},
+			},
+		},
+		Tasks: make(map[string][]string),
+	}
+}
+
+func TestUpdatePool(t *testing.T) {
+	notOperational := poolKube()
+	notOperational.State = model.StateProvisioning
+	degraded := poolKube()
+	degraded.State = model.StateDegraded
+
+	testCases := []struct {
+		description string
+		kube        *model.Kube
+		kubeErr     error
+		pool        string
+		body        string
+
+		expectedCode     int
+		expectedProfiles []profile.NodeProfile
+		expectedTasks    int
+	}{
+		{
+			description:  "kube not found",
+			kubeErr:      sgerrors.ErrNotFound,
+			pool:         "workers",
+			body:         `{"count": 3}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			description:  "kube is not operational",
+			kube:         notOperational,
+			pool:         "workers",
+			body:         `{"count": 3}`,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			description:  "invalid json",
+			kube:         poolKube(),
+			pool:         "workers",
+			body:         `{"count": `,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "new pool without size",
+			kube:         poolKube(),
+			pool:         "gpu",
+			body:         `{"count": 1}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "invalid taint",
+			kube:         poolKube(),
+			pool:         "workers",
+			body:         `{"taints": [{"key": "dedicated", "effect": "Never"}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "count is not changed",
+			kube:         poolKube(),
+			pool:         "workers",
+			body:         `{"labels": {"role": "batch"}}`,
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			description:  "scale up",
+			kube:         poolKube(),
+			pool:         "workers",
+			body:         `{"count": 4}`,
+			expectedCode: http.StatusAccepted,
+			expectedProfiles: []profile.NodeProfile{
+				{"pool": "workers", "size": "s-2vcpu-4gb"},
+				{"pool": "workers", "size": "s-2vcpu-4gb"},
+			},
+			expectedTasks: 2,
+		},
+		{
+			description:  "scale up degraded",
+			kube:         degraded,
+			pool:         "workers",
+			body:         `{"count": 3}`,
+			expectedCode: http.StatusAccepted,
+			expectedProfiles: []profile.NodeProfile{
+				{"pool": "workers", "size": "s-2vcpu-4gb"},
+			},
+			expectedTasks: 1,
+		},
+		{
+			description:  "new pool",
+			kube:         poolKube(),
+			pool:         "gpu",
+			body:         `{"size": "g-2vcpu-8gb", "count": 1, "zones": ["fra1"]}`,
+			expectedCode: http.StatusAccepted,
+			expectedProfiles: []profile.NodeProfile{
+				{"pool": "gpu", "size": "g-2vcpu-8gb", "availabilityZone": "fra1"},
+			},
+			expectedTasks: 1,
+		},
+		{
+			description:   "scale down",
+			kube:          poolKube(),
+			pool:          "workers",
+			body:          `{"count": 1}`,
+			expectedCode:  http.StatusAccepted,
+			expectedTasks: 1,
+		},
+	}
+
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.DeleteNode, []steps.Step{})
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		svc := new(kubeServiceMock)
+		svc.On(serviceGet, mock.Anything, mock.Anything).
+			Return(testCase.kube, testCase.kubeErr)
+		svc.On(serviceCreate, mock.Anything, mock.Anything).
+			Return(nil)
+
+		profileSvc := new(mockProfileService)
+		profileSvc.On("Get", mock.Anything, mock.Anything).
+			Return(&profile.Profile{}, nil)
+
+		accService := new(accServiceMock)
+		accService.On("Get", mock.Anything, mock.Anything).
+			Return(&model.CloudAccount{Provider: clouds.DigitalOcean}, nil)
+
+		provisioner := new(mockNodeProvisioner)
+		provisioner.On("ProvisionNodes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]string{"task1", "task2"}[:len(testCase.expectedProfiles)], nil)
+
+		repo := new(testutils.MockStorage)
+		repo.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+
+		h := Handler{
+			svc:             svc,
+			accountService:  accService,
+			profileSvc:      profileSvc,
+			nodeProvisioner: provisioner,
+			repo:            repo,
+			getWriter: func(string) (io.WriteCloser, error) {
+				return &bufferCloser{}, nil
+			},
+		}
+
+		router := mux.NewRouter()
+		router.HandleFunc("/kubes/{kubeID}/pools/{pool}", h.updatePool)
+
+		req, _ := http.NewRequest(http.MethodPut, "/kubes/kube1234/pools/"+testCase.pool,
+			bytes.NewBufferString(testCase.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.expectedCode {
+			t.Errorf("wrong response code expected %d actual %d %s",
+				testCase.expectedCode, rec.Code, rec.Body.String())
+			continue
+		}
+
+		if rec.Code != http.StatusAccepted {
+			continue
+		}
+
+		resp := &PoolResponse{}
+		if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+			t.Fatalf("decode response %v", err)
+		}
+
+		if resp.Pool.Name != testCase.pool || len(resp.Tasks) != testCase.expectedTasks {
+			t.Errorf("wrong response %v", resp)
+		}
+
+		if testCase.expectedProfiles != nil {
+			provisioner.AssertCalled(t, "ProvisionNodes", mock.Anything,
+				testCase.expectedProfiles, mock.Anything, mock.Anything)
+		} else {
+			provisioner.AssertNotCalled(t, "ProvisionNodes", mock.Anything,
+				mock.Anything, mock.Anything, mock.Anything)
+		}
+	}
+}
+
+func TestUpdatePoolKeepsFields(t *testing.T) {
+	count := 3
+	pool := mergePool(poolKube().NodePools["workers"], "workers", &PoolRequest{
+		Count: &count,
+	})
+
+	if pool.Count != 3 || pool.Size != "s-2vcpu-4gb" || pool.Labels["role"] != "worker" {
+		t.Errorf("wrong pool %v", pool)
+	}
+}
+
+func TestNodesToDelete(t *testing.T) {
+	machines := []*model.Machine{
+		{Name: "a-1", AvailabilityZone: "a", State: model.MachineStateActive, CreatedAt: 1},
+		{Name: "a-2", AvailabilityZone: "a", State: model.MachineStateActive, CreatedAt: 4},
+		{Name: "b-1", AvailabilityZone: "b", State: model.MachineStateActive, CreatedAt: 2},
+		{Name: "b-2", AvailabilityZone: "b", State: model.MachineStateError, CreatedAt: 3},
+		{Name: "a-3", AvailabilityZone: "a", State: model.MachineStateActive, CreatedAt: 5},
+	}
+
+	names := make([]string, 0)
+	for _, m := range nodesToDelete(machines, 3) {
+		names = append(names, m.Name)
+	}
+
+	// failed machine goes first, then the newest ones of the biggest zone
+	expected := []string{"b-2", "a-3", "a-2"}
+	for i := range expected {
+		if i >= len(names) || names[i] != expected[i] {
+			t.Fatalf("expected %v actual %v", expected, names)
+		}
+	}
+
+	if len(nodesToDelete(machines, 10)) != len(machines) {
+		t.Errorf("more machines are picked than there are")
+	}
+}
+
+func TestListPools(t *testing.T) {
+	k := poolKube()
+	k.NodePools["gpu"] = profile.NodePool{Name: "gpu", Size: "g-2vcpu-8gb"}
+
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+
+	h := Handler{svc: svc}
+	router := mux.NewRouter()
+	router.HandleFunc("/kubes/{kubeID}/pools", h.listPools)
+
+	req, _ := http.NewRequest(http.MethodGet, "/kubes/kube1234/pools", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	pools := make([]profile.NodePool, 0)
+	if err := json.NewDecoder(rec.Body).Decode(&pools); err != nil {
+		t.Fatalf("decode response %v", err)
+	}
+
+	if len(pools) != 2 || pools[0].Name != "gpu" || pools[1].Name != "workers" {
+		t.Errorf("wrong pools %v", pools)
+	}
+}
+
+func TestSetSpecPool(t *testing.T) {
+	spec := &model.KubeSpec{
+		NodePools:  profile.NodePools{{Name: "workers", Size: "s-2vcpu-4gb", Count: 2}},
+		Generation: 1,
+	}
+
+	setSpecPool(spec, profile.NodePool{Name: "workers", Size: "s-2vcpu-4gb", Count: 4})
+	setSpecPool(spec, profile.NodePool{Name: "gpu", Size: "g-2vcpu-8gb", Count: 1})
+
+	if len(spec.NodePools) != 2 || spec.NodePools[0].Count != 4 || spec.Generation != 3 {
+		t.Errorf("wrong spec %v", spec)
+	}
+}
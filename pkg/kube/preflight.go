@@ -0,0 +1,209 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	policyv1beta1client "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
+
+	"github.com/supergiant/control/pkg/kubeconfig"
+	"github.com/supergiant/control/pkg/model"
+)
+
+// maxKubeletSkew is how many minor versions kubelets may be older than api server.
+const maxKubeletSkew = 2
+
+// preflightChecks checks the kube can be upgraded to the version.
+func preflightChecks(k *model.Kube, version string) []model.PreflightCheck {
+	core, err := kubeconfig.CoreV1Client(k)
+	if err != nil {
+		return failedChecks(fmt.Sprintf("api server: %v", err))
+	}
+
+	policy, err := kubeconfig.PolicyV1beta1Client(k)
+	if err != nil {
+		return failedChecks(fmt.Sprintf("api server: %v", err))
+	}
+
+	return runPreflightChecks(core, policy, k, version)
+}
+
+func runPreflightChecks(core corev1client.CoreV1Interface, policy policyv1beta1client.PolicyV1beta1Interface,
+	k *model.Kube, version string) []model.PreflightCheck {
+	nodeList, err := core.Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return failedChecks(fmt.Sprintf("list nodes: %v", err))
+	}
+
+	return []model.PreflightCheck{
+		checkVersionSkew(nodeList.Items, k.K8SVersion, version),
+		checkComponentHealth(core, k, nodeList.Items),
+		checkDiskSpace(nodeList.Items),
+		checkDisruptionBudgets(policy),
+	}
+}
+
+// failedChecks is a result of checks that can't be run.
+func failedChecks(message string) []model.PreflightCheck {
+	checks := make([]model.PreflightCheck, 0, 4)
+	for _, name := range []string{model.CheckVersionSkew, model.CheckComponentHealth,
+		model.CheckDiskSpace, model.CheckDisruptionBudgets} {
+		checks = append(checks, model.PreflightCheck{
+			Name:    name,
+			Message: message,
+		})
+	}
+
+	return checks
+}
+
+// checkVersionSkew checks kubelets stay supported by api server of the new version
+// and none of them is newer than the current one.
+func checkVersionSkew(nodes []corev1.Node, current, version string) model.PreflightCheck {
+	check := model.PreflightCheck{Name: model.CheckVersionSkew}
+
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		check.Message = fmt.Sprintf("parse version %s: %v", current, err)
+		return check
+	}
+
+	next, err := semver.NewVersion(version)
+	if err != nil {
+		check.Message = fmt.Sprintf("parse version %s: %v", version, err)
+		return check
+	}
+
+	if next.Major() != cur.Major() || next.Minor() > cur.Minor()+1 {
+		check.Message = fmt.Sprintf("can't skip minor versions from %s to %s", current, version)
+		return check
+	}
+
+	problems := make([]string, 0)
+	for _, node := range nodes {
+		kubelet, err := semver.NewVersion(node.Status.NodeInfo.KubeletVersion)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("node %s: unknown kubelet version %q",
+				node.Name, node.Status.NodeInfo.KubeletVersion))
+			continue
+		}
+
+		if kubelet.Major() != cur.Major() || kubelet.Minor() > cur.Minor() {
+			problems = append(problems, fmt.Sprintf("node %s: kubelet %s is newer than %s",
+				node.Name, kubelet, current))
+		}
+
+		if int64(next.Minor())-int64(kubelet.Minor()) > maxKubeletSkew {
+			problems = append(problems, fmt.Sprintf("node %s: kubelet %s is too old for %s",
+				node.Name, kubelet, version))
+		}
+	}
+
+	return checkResult(check, problems)
+}
+
+// checkComponentHealth checks control plane components, nodes and machines of the kube are healthy.
+func checkComponentHealth(core corev1client.CoreV1Interface, k *model.Kube, nodes []corev1.Node) model.PreflightCheck {
+	check := model.PreflightCheck{Name: model.CheckComponentHealth}
+	problems := make([]string, 0)
+
+	components, err := core.ComponentStatuses().List(metav1.ListOptions{})
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("component statuses: %v", err))
+	} else {
+		for _, component := range components.Items {
+			if !componentHealthy(component) {
+				problems = append(problems, fmt.Sprintf("component %s is unhealthy", component.Name))
+			}
+		}
+	}
+
+	for i := range nodes {
+		if !nodeReady(&nodes[i]) {
+			problems = append(problems, fmt.Sprintf("node %s is not ready", nodes[i].Name))
+		}
+	}
+
+	for _, machines := range []map[string]*model.Machine{k.Masters, k.Nodes} {
+		for _, m := range machines {
+			if m != nil && m.State != model.MachineStateActive {
+				problems = append(problems, fmt.Sprintf("machine %s is %s", m.Name, m.State))
+			}
+		}
+	}
+
+	return checkResult(check, problems)
+}
+
+func componentHealthy(component corev1.ComponentStatus) bool {
+	for _, c := range component.Conditions {
+		if c.Type == corev1.ComponentHealthy {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// checkDiskSpace checks no node is running out of disk, images of the new
+// version are pulled during the upgrade.
+func checkDiskSpace(nodes []corev1.Node) model.PreflightCheck {
+	check := model.PreflightCheck{Name: model.CheckDiskSpace}
+	problems := make([]string, 0)
+
+	for _, node := range nodes {
+		for _, c := range node.Status.Conditions {
+			if c.Type == corev1.NodeDiskPressure && c.Status == corev1.ConditionTrue {
+				problems = append(problems, fmt.Sprintf("node %s has disk pressure", node.Name))
+			}
+		}
+	}
+
+	return checkResult(check, problems)
+}
+
+// checkDisruptionBudgets checks pods can be evicted when nodes are drained.
+func checkDisruptionBudgets(policy policyv1beta1client.PolicyV1beta1Interface) model.PreflightCheck {
+	check := model.PreflightCheck{Name: model.CheckDisruptionBudgets}
+
+	budgets, err := policy.PodDisruptionBudgets(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		check.Message = fmt.Sprintf("list pod disruption budgets: %v", err)
+		return check
+	}
+
+	problems := make([]string, 0)
+	for _, pdb := range budgets.Items {
+		if pdb.Status.ExpectedPods > 0 && pdb.Status.PodDisruptionsAllowed == 0 {
+			problems = append(problems, fmt.Sprintf("pod disruption budget %s/%s allows no disruptions",
+				pdb.Namespace, pdb.Name))
+		}
+	}
+
+	return checkResult(check, problems)
+}
+
+// preflightProblems describes checks that have failed.
+func preflightProblems(checks []model.PreflightCheck) string {
+	problems := make([]string, 0)
+	for _, c := range checks {
+		if !c.Passed {
+			problems = append(problems, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+	}
+
+	return strings.Join(problems, "; ")
+}
+
+func checkResult(check model.PreflightCheck, problems []string) model.PreflightCheck {
+	sort.Strings(problems)
+	check.Passed = len(problems) == 0
+	check.Message = strings.Join(problems, ", ")
+
+	return check
+}
@@ -0,0 +1,150 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/supergiant/control/pkg/model"
+)
+
+func kubeletNode(name, version string, conditions ...corev1.NodeCondition) *corev1.Node {
+	n := readyNode(name, "", corev1.ConditionTrue)
+	n.Status.NodeInfo.KubeletVersion = version
+	n.Status.Conditions = append(n.Status.Conditions, conditions...)
+	return n
+}
+
+func healthyComponent(name string, status corev1.ConditionStatus) *corev1.ComponentStatus {
+	return &corev1.ComponentStatus{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Conditions: []corev1.ComponentCondition{{Type: corev1.ComponentHealthy, Status: status}},
+	}
+}
+
+func TestPreflightChecks(t *testing.T) {
+	k := &model.Kube{
+		K8SVersion: "1.13.7",
+		Masters: map[string]*model.Machine{
+			"master": {Name: "master", State: model.MachineStateActive},
+		},
+		Nodes: map[string]*model.Machine{
+			"node-1": {Name: "node-1", State: model.MachineStateActive},
+		},
+	}
+
+	testCases := []struct {
+		description string
+		version     string
+		nodes       []*corev1.Node
+		components  []*corev1.ComponentStatus
+		budgets     []*policyv1beta1.PodDisruptionBudget
+		machine     model.MachineState
+
+		failed   []string
+		messages []string
+	}{
+		{
+			description: "passed",
+			version:     "1.14.3",
+			nodes: []*corev1.Node{
+				kubeletNode("master", "v1.13.7"),
+				kubeletNode("node-1", "v1.12.7"),
+			},
+			components: []*corev1.ComponentStatus{healthyComponent("etcd-0", corev1.ConditionTrue)},
+			budgets: []*policyv1beta1.PodDisruptionBudget{{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Status:     policyv1beta1.PodDisruptionBudgetStatus{ExpectedPods: 2, PodDisruptionsAllowed: 1},
+			}},
+			machine: model.MachineStateActive,
+		},
+		{
+			description: "version skew",
+			version:     "1.14.3",
+			nodes: []*corev1.Node{
+				kubeletNode("master", "v1.13.7"),
+				kubeletNode("node-1", "v1.11.5"),
+			},
+			machine:  model.MachineStateActive,
+			failed:   []string{model.CheckVersionSkew},
+			messages: []string{"node node-1: kubelet 1.11.5 is too old for 1.14.3"},
+		},
+		{
+			description: "skipped minor",
+			version:     "1.15.1",
+			nodes:       []*corev1.Node{kubeletNode("master", "v1.13.7")},
+			machine:     model.MachineStateActive,
+			failed:      []string{model.CheckVersionSkew},
+			messages:    []string{"can't skip minor versions"},
+		},
+		{
+			description: "unhealthy",
+			version:     "1.14.3",
+			nodes: []*corev1.Node{
+				kubeletNode("master", "v1.13.7"),
+				kubeletNode("node-1", "v1.13.7", corev1.NodeCondition{
+					Type:   corev1.NodeDiskPressure,
+					Status: corev1.ConditionTrue,
+				}),
+			},
+			components: []*corev1.ComponentStatus{healthyComponent("scheduler", corev1.ConditionFalse)},
+			budgets: []*policyv1beta1.PodDisruptionBudget{{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+				Status:     policyv1beta1.PodDisruptionBudgetStatus{ExpectedPods: 1},
+			}},
+			machine: model.MachineStateNotReady,
+			failed:  []string{model.CheckComponentHealth, model.CheckDiskSpace, model.CheckDisruptionBudgets},
+			messages: []string{
+				"component scheduler is unhealthy, machine node-1 is notReady",
+				"node node-1 has disk pressure",
+				"pod disruption budget prod/db allows no disruptions",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		client := fake.NewSimpleClientset()
+		for _, n := range testCase.nodes {
+			client.CoreV1().Nodes().Create(n)
+		}
+		for _, c := range testCase.components {
+			client.CoreV1().ComponentStatuses().Create(c)
+		}
+		for _, pdb := range testCase.budgets {
+			client.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace).Create(pdb)
+		}
+		k.Nodes["node-1"].State = testCase.machine
+
+		checks := runPreflightChecks(client.CoreV1(), client.PolicyV1beta1(), k, testCase.version)
+		if len(checks) != 4 {
+			t.Errorf("expected 4 checks actual %v", checks)
+			continue
+		}
+
+		failed := make([]string, 0)
+		for _, c := range checks {
+			if c.Passed {
+				continue
+			}
+
+			failed = append(failed, c.Name)
+			if len(failed) <= len(testCase.messages) && !strings.Contains(c.Message, testCase.messages[len(failed)-1]) {
+				t.Errorf("check %s: message %q must contain %q", c.Name, c.Message, testCase.messages[len(failed)-1])
+			}
+		}
+
+		if strings.Join(failed, ",") != strings.Join(testCase.failed, ",") {
+			t.Errorf("expected failed checks %v actual %v", testCase.failed, failed)
+		}
+
+		if model.PreflightPassed(checks) != (len(testCase.failed) == 0) {
+			t.Errorf("wrong preflight result %v", checks)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package kube
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Reconciler periodically brings kubes that have a spec to their desired state.
+type Reconciler struct {
+	h        *Handler
+	interval time.Duration
+}
+
+// NewReconciler makes reconciler that schedules workflows the same
+// way kube handler does.
+func NewReconciler(h *Handler, interval time.Duration) *Reconciler {
+	return &Reconciler{
+		h:        h,
+		interval: interval,
+	}
+}
+
+// Run reconciles kubes every interval until the context is done.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.reconcile(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) {
+	kubes, err := r.h.svc.ListAll(ctx)
+	if err != nil {
+		logrus.Errorf("reconciler: list kubes: %v", err)
+		return
+	}
+
+	for i := range kubes {
+		if kubes[i].Spec == nil {
+			continue
+		}
+
+		if err := r.h.reconcileSpec(ctx, &kubes[i]); err != nil {
+			logrus.Errorf("reconciler: kube %s: %v", kubes[i].ID, err)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+)
+
+// AutoRepairResponse has the auto repair policy of a kube and its latest repairs.
+type AutoRepairResponse struct {
+	Policy  *model.AutoRepairPolicy `json:"policy"`
+	Repairs []model.Repair          `json:"repairs"`
+}
+
+func (h *Handler) getAutoRepair(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(autoRepairResponse(k)); err != nil {
+		message.SendUnknownError(w, err)
+	}
+}
+
+// putAutoRepair sets the auto repair policy, zero limits get default values.
+func (h *Handler) putAutoRepair(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	policy := &model.AutoRepairPolicy{}
+	if err = json.NewDecoder(r.Body).Decode(policy); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	policy.SetDefaults()
+	if err = policy.Validate(); err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	if k.Provider == clouds.BYO && policy.Enabled {
+		http.Error(w, "machines brought by user can't be replaced", http.StatusBadRequest)
+		return
+	}
+
+	k.AutoRepair = policy
+	if err = h.svc.Create(r.Context(), k); err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(autoRepairResponse(k)); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+func autoRepairResponse(k *model.Kube) AutoRepairResponse {
+	repairs := k.Repairs
+	if repairs == nil {
+		repairs = []model.Repair{}
+	}
+
+	return AutoRepairResponse{
+		Policy:  k.AutoRepair,
+		Repairs: repairs,
+	}
+}
+
+// repair replaces worker nodes that have been unhealthy longer than the policy
+// allows. Nothing is replaced when too many nodes are unhealthy or the kube
+// has had enough repairs in the last hour. Masters are never replaced.
+func (m *HealthMonitor) repair(ctx context.Context, k *model.Kube) error {
+	policy := k.AutoRepair
+	now := m.now()
+
+	unhealthy := make([]*model.Machine, 0)
+	for _, n := range k.Nodes {
+		if n != nil && monitored(n.State) && n.State != model.MachineStateActive {
+			unhealthy = append(unhealthy, n)
+		}
+	}
+
+	if len(unhealthy) > policy.MaxUnhealthy {
+		logrus.Warnf("auto repair: kube %s has %d unhealthy nodes, more than %d, skip repairs",
+			k.ID, len(unhealthy), policy.MaxUnhealthy)
+		return nil
+	}
+
+	budget := policy.MaxRepairsPerHour - k.RepairsSince(now.Add(-time.Hour))
+	candidates := repairCandidates(unhealthy, now.Add(-time.Duration(policy.UnhealthyMinutes)*time.Minute))
+	if len(candidates) > budget {
+		if budget <= 0 {
+			logrus.Warnf("auto repair: kube %s has reached %d repairs per hour",
+				k.ID, policy.MaxRepairsPerHour)
+			return nil
+		}
+		candidates = candidates[:budget]
+	}
+
+	for _, n := range candidates {
+		logrus.Infof("auto repair: replace node %s of kube %s, it is %s since %s",
+			n.Name, k.ID, n.State, time.Unix(n.UnhealthySince, 0))
+
+		if err := m.h.replaceNode(ctx, k, n, now); err != nil {
+			return errors.Wrapf(err, "replace node %s", n.Name)
+		}
+	}
+
+	return nil
+}
+
+// repairCandidates returns nodes that have been unhealthy since before
+// the deadline, the ones that broke first go first.
+func repairCandidates(unhealthy []*model.Machine, deadline time.Time) []*model.Machine {
+	candidates := make([]*model.Machine, 0, len(unhealthy))
+	for _, n := range unhealthy {
+		if n.UnhealthySince != 0 && n.UnhealthySince <= deadline.Unix() {
+			candidates = append(candidates, n)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].UnhealthySince != candidates[j].UnhealthySince {
+			return candidates[i].UnhealthySince < candidates[j].UnhealthySince
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return candidates
+}
+
+// replaceNode drains and deletes the node with DeleteNode workflow,
+// then provisions a new node of the same profile. The node that fails
+// to be deleted is kept in error state and nothing is provisioned, the
+// machine could still be running and its replacement would overgrow the pool.
+func (h *Handler) replaceNode(ctx context.Context, k *model.Kube, n *model.Machine, now time.Time) error {
+	nodeProfile, err := h.replacementProfile(ctx, k, n)
+	if err != nil {
+		return errors.Wrap(err, "replacement profile")
+	}
+
+	t, config, err := h.newDeleteNodeTask(ctx, k, n)
+	if err != nil {
+		return errors.Wrap(err, "new delete node task")
+	}
+
+	writer, err := h.getWriter(util.MakeFileName(t.ID))
+	if err != nil {
+		return errors.Wrapf(err, "get writer for %s", t.ID)
+	}
+
+	k.AddRepair(model.Repair{
+		Time:       now,
+		Machine:    n.Name,
+		State:      n.State,
+		DeleteTask: t.ID,
+	})
+	n.State = model.MachineStateDeleting
+
+	if err = h.svc.Create(ctx, k); err != nil {
+		return errors.Wrapf(err, "update kube %s", k.ID)
+	}
+
+	kubeID, nodeName := k.ID, n.Name
+	go func() {
+		if err := <-t.Run(context.Background(), config, writer); err != nil {
+			logrus.Errorf("auto repair: delete node %s from kube %s caused %v", nodeName, kubeID, err)
+			if err := h.failRepair(kubeID, t.ID, nodeName, errors.Wrap(err, "delete node")); err != nil {
+				logrus.Errorf("auto repair: update kube %s caused %v", kubeID, err)
+			}
+			return
+		}
+
+		if err := h.forgetNode(kubeID, nodeName); err != nil {
+			logrus.Errorf("auto repair: update kube %s caused %v", kubeID, err)
+		}
+
+		if err := h.provisionReplacement(kubeID, t.ID, nodeProfile); err != nil {
+			logrus.Errorf("auto repair: replace node %s of kube %s caused %v", nodeName, kubeID, err)
+		}
+	}()
+
+	return nil
+}
+
+// failRepair records the error of the node that has failed to be deleted.
+func (h *Handler) failRepair(kubeID, deleteTask, nodeName string, repairErr error) error {
+	ctx := context.Background()
+
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	if n := k.Nodes[nodeName]; n != nil {
+		n.State = model.MachineStateError
+	}
+
+	for i := range k.Repairs {
+		if k.Repairs[i].DeleteTask == deleteTask {
+			k.Repairs[i].Error = repairErr.Error()
+		}
+	}
+
+	return errors.Wrapf(h.svc.Create(ctx, k), "update kube %s", kubeID)
+}
+
+// provisionReplacement adds the new node and saves its tasks to the repair.
+func (h *Handler) provisionReplacement(kubeID, deleteTask string, nodeProfile profile.NodeProfile) error {
+	ctx := context.Background()
+
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	var repairErr error
+	tasks, err := h.addNodes(ctx, k, []profile.NodeProfile{nodeProfile})
+	if err != nil {
+		repairErr = errors.Wrap(err, "provision node")
+	}
+
+	for i := range k.Repairs {
+		if k.Repairs[i].DeleteTask != deleteTask {
+			continue
+		}
+
+		k.Repairs[i].ProvisionTasks = tasks
+		if repairErr != nil {
+			k.Repairs[i].Error = repairErr.Error()
+		}
+	}
+
+	if err = h.svc.Create(ctx, k); err != nil {
+		return errors.Wrapf(err, "update kube %s", kubeID)
+	}
+
+	return repairErr
+}
+
+// replacementProfile makes a profile of the node that replaces n, nodes
+// of pools are made from their pool, other nodes from the kube profile
+// that has the same size.
+func (h *Handler) replacementProfile(ctx context.Context, k *model.Kube, n *model.Machine) (profile.NodeProfile, error) {
+	if pool, ok := k.NodePools[n.Pool]; ok && n.Pool != "" {
+		zones := make(map[string]int)
+		for _, m := range poolMachines(k, pool.Name) {
+			if m != n {
+				zones[m.AvailabilityZone]++
+			}
+		}
+
+		return pool.NodeProfiles(k.Provider, zones, 1)[0], nil
+	}
+
+	kubeProfile, err := h.profileSvc.Get(ctx, k.ProfileID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
+	if len(kubeProfile.NodesProfiles) == 0 {
+		return nil, errors.Wrapf(sgerrors.ErrNotFound, "node profiles of %s", k.ProfileID)
+	}
+
+	found := kubeProfile.NodesProfiles[0]
+	for _, p := range kubeProfile.NodesProfiles {
+		if hasValue(p, n.Size) {
+			found = p
+			break
+		}
+	}
+
+	nodeProfile := make(profile.NodeProfile, len(found))
+	for key, value := range found {
+		nodeProfile[key] = value
+	}
+
+	return nodeProfile, nil
+}
+
+func hasValue(p profile.NodeProfile, value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, v := range p {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,325 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+var repairTime = time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func repairKube(unhealthy ...string) *model.Kube {
+	k := &model.Kube{
+		ID:          "kube1234",
+		State:       model.StateDegraded,
+		Provider:    clouds.DigitalOcean,
+		AccountName: "test",
+		ProfileID:   "profile",
+		AutoRepair: &model.AutoRepairPolicy{
+			Enabled:           true,
+			UnhealthyMinutes:  10,
+			MaxUnhealthy:      2,
+			MaxRepairsPerHour: 2,
+		},
+		Masters: map[string]*model.Machine{
+			"master": {Name: "master", State: model.MachineStateNotReady,
+				UnhealthySince: repairTime.Add(-time.Hour).Unix()},
+		},
+		Nodes: map[string]*model.Machine{
+			"node-1": {Name: "node-1", Size: "s-2vcpu-4gb", State: model.MachineStateActive},
+			"node-2": {Name: "node-2", Size: "s-2vcpu-4gb", State: model.MachineStateActive},
+			"node-3": {Name: "node-3", Size: "s-2vcpu-4gb", State: model.MachineStateActive},
+		},
+	}
+
+	for i, name := range unhealthy {
+		k.Nodes[name].State = model.MachineStateNotReady
+		k.Nodes[name].UnhealthySince = repairTime.Add(-time.Duration(20+i) * time.Minute).Unix()
+	}
+
+	return k
+}
+
+func testRepairMonitor(k *model.Kube) (*HealthMonitor, *mockNodeProvisioner, chan struct{}) {
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+
+	profileSvc := new(mockProfileService)
+	profileSvc.On("Get", mock.Anything, mock.Anything).
+		Return(&profile.Profile{
+			NodesProfiles: []profile.NodeProfile{
+				{"size": "s-1vcpu-2gb", "image": "ubuntu"},
+				{"size": "s-2vcpu-4gb", "image": "ubuntu"},
+			},
+		}, nil)
+
+	accService := new(accServiceMock)
+	accService.On("Get", mock.Anything, mock.Anything).
+		Return(&model.CloudAccount{Provider: clouds.DigitalOcean}, nil)
+
+	provisioned := make(chan struct{}, 10)
+	provisioner := new(mockNodeProvisioner)
+	provisioner.On("ProvisionNodes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { provisioned <- struct{}{} }).
+		Return([]string{"task1"}, nil)
+
+	repo := new(testutils.MockStorage)
+	repo.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+
+	h := &Handler{
+		svc:             svc,
+		accountService:  accService,
+		profileSvc:      profileSvc,
+		nodeProvisioner: provisioner,
+		repo:            repo,
+		getWriter: func(string) (io.WriteCloser, error) {
+			return &bufferCloser{}, nil
+		},
+	}
+
+	m := NewHealthMonitor(h, time.Minute)
+	m.now = func() time.Time { return repairTime }
+
+	return m, provisioner, provisioned
+}
+
+type noopStep struct{}
+
+func (noopStep) Run(context.Context, io.Writer, *steps.Config) error      { return nil }
+func (noopStep) Name() string                                             { return "noop" }
+func (noopStep) Description() string                                      { return "" }
+func (noopStep) Depends() []string                                        { return nil }
+func (noopStep) Rollback(context.Context, io.Writer, *steps.Config) error { return nil }
+
+func TestRepairReplacesNode(t *testing.T) {
+	workflows.Init()
+	// Tasks without steps never finish
+	workflows.RegisterWorkFlow(workflows.DeleteNode, []steps.Step{noopStep{}})
+
+	k := repairKube("node-2")
+	m, provisioner, _ := testRepairMonitor(k)
+
+	// Tasks of the replacement are saved last
+	replaced := make(chan struct{}, 1)
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			if len(k.Repairs) == 1 && len(k.Repairs[0].ProvisionTasks) > 0 {
+				replaced <- struct{}{}
+			}
+		}).Return(nil)
+	m.h.svc = svc
+
+	if err := m.repair(context.Background(), k); err != nil {
+		t.Fatalf("repair %v", err)
+	}
+
+	select {
+	case <-replaced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("replacement was not provisioned")
+	}
+
+	provisioner.AssertCalled(t, "ProvisionNodes", mock.Anything,
+		[]profile.NodeProfile{{"size": "s-2vcpu-4gb", "image": "ubuntu"}}, mock.Anything, mock.Anything)
+
+	if len(k.Repairs) != 1 || k.Repairs[0].Machine != "node-2" ||
+		k.Repairs[0].State != model.MachineStateNotReady || k.Repairs[0].DeleteTask == "" {
+		t.Errorf("wrong repairs %v", k.Repairs)
+	}
+
+	// Master must be kept
+	if k.Masters["master"].State != model.MachineStateNotReady {
+		t.Errorf("master must not be repaired")
+	}
+}
+
+func TestRepairKeepsNodeNotDeleted(t *testing.T) {
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.DeleteNode, []steps.Step{upgradeStep{
+		run: func(*steps.Config) error { return errors.New("droplet is locked") },
+	}})
+
+	k := repairKube("node-2")
+	m, provisioner, _ := testRepairMonitor(k)
+
+	failed := make(chan struct{}, 1)
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			if len(k.Repairs) == 1 && k.Repairs[0].Error != "" {
+				failed <- struct{}{}
+			}
+		}).Return(nil)
+	m.h.svc = svc
+
+	if err := m.repair(context.Background(), k); err != nil {
+		t.Fatalf("repair %v", err)
+	}
+
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed repair was not recorded")
+	}
+
+	provisioner.AssertNotCalled(t, "ProvisionNodes", mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything)
+
+	if n := k.Nodes["node-2"]; n == nil || n.State != model.MachineStateError {
+		t.Errorf("node must be kept in error state %v", n)
+	}
+	if !strings.Contains(k.Repairs[0].Error, "droplet is locked") {
+		t.Errorf("wrong repair error %s", k.Repairs[0].Error)
+	}
+}
+
+func TestRepairSkips(t *testing.T) {
+	testCases := []struct {
+		description string
+		kube        *model.Kube
+	}{
+		{
+			description: "not unhealthy long enough",
+			kube: func() *model.Kube {
+				k := repairKube("node-1")
+				k.Nodes["node-1"].UnhealthySince = repairTime.Add(-time.Minute).Unix()
+				return k
+			}(),
+		},
+		{
+			description: "too many unhealthy nodes",
+			kube:        repairKube("node-1", "node-2", "node-3"),
+		},
+		{
+			description: "rate limit",
+			kube: func() *model.Kube {
+				k := repairKube("node-1")
+				k.Repairs = []model.Repair{
+					{Time: repairTime.Add(-time.Minute * 50), Machine: "node-4"},
+					{Time: repairTime.Add(-time.Minute * 20), Machine: "node-5"},
+				}
+				return k
+			}(),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		m, _, _ := testRepairMonitor(testCase.kube)
+		if err := m.repair(context.Background(), testCase.kube); err != nil {
+			t.Errorf("repair %v", err)
+		}
+
+		m.h.svc.(*kubeServiceMock).AssertNotCalled(t, serviceCreate, mock.Anything, mock.Anything)
+	}
+}
+
+func TestRepairCandidates(t *testing.T) {
+	unhealthy := []*model.Machine{
+		{Name: "c", UnhealthySince: 30},
+		{Name: "b", UnhealthySince: 10},
+		{Name: "a", UnhealthySince: 10},
+		{Name: "d", UnhealthySince: 50},
+		{Name: "e"},
+	}
+
+	candidates := repairCandidates(unhealthy, time.Unix(40, 0))
+	names := make([]string, 0, len(candidates))
+	for _, m := range candidates {
+		names = append(names, m.Name)
+	}
+
+	if strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("wrong candidates %v", names)
+	}
+}
+
+func TestReplacementProfile(t *testing.T) {
+	k := repairKube()
+	k.NodePools = map[string]profile.NodePool{
+		"workers": {Name: "workers", Size: "s-4vcpu-8gb", Count: 2, Zones: []string{"a", "b"}},
+	}
+	k.Nodes["node-1"].Pool = "workers"
+	k.Nodes["node-1"].AvailabilityZone = "a"
+	k.Nodes["node-2"].Pool = "workers"
+	k.Nodes["node-2"].AvailabilityZone = "b"
+
+	m, _, _ := testRepairMonitor(k)
+
+	p, err := m.h.replacementProfile(context.Background(), k, k.Nodes["node-2"])
+	if err != nil {
+		t.Fatalf("replacement profile %v", err)
+	}
+	if p[profile.NodePoolKey] != "workers" || p[profile.AvailabilityZoneKey] != "b" {
+		t.Errorf("wrong pool node profile %v", p)
+	}
+
+	p, err = m.h.replacementProfile(context.Background(), k, k.Nodes["node-3"])
+	if err != nil {
+		t.Fatalf("replacement profile %v", err)
+	}
+	if p["size"] != "s-2vcpu-4gb" {
+		t.Errorf("wrong node profile %v", p)
+	}
+}
+
+func TestPutAutoRepair(t *testing.T) {
+	testCases := []struct {
+		body         string
+		expectedCode int
+	}{
+		{`{"enabled": true}`, http.StatusOK},
+		{`{"enabled": true, "maxUnhealthy": -1}`, http.StatusBadRequest},
+		{`{"enabled":`, http.StatusBadRequest},
+	}
+
+	for _, testCase := range testCases {
+		k := repairKube()
+		k.AutoRepair = nil
+
+		svc := new(kubeServiceMock)
+		svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+		svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+
+		h := Handler{svc: svc}
+		router := mux.NewRouter()
+		router.HandleFunc("/kubes/{kubeID}/autorepair", h.putAutoRepair)
+
+		req, _ := http.NewRequest(http.MethodPut, "/kubes/kube1234/autorepair",
+			bytes.NewBufferString(testCase.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.expectedCode {
+			t.Errorf("%s: wrong response code expected %d actual %d", testCase.body,
+				testCase.expectedCode, rec.Code)
+			continue
+		}
+
+		if rec.Code == http.StatusOK && (k.AutoRepair == nil || k.AutoRepair.UnhealthyMinutes != 10) {
+			t.Errorf("default policy must be set %v", k.AutoRepair)
+		}
+	}
+}
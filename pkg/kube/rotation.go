@@ -0,0 +1,254 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/pki"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows"
+)
+
+var ErrInvalidRotation = errors.New("invalid rotation")
+
+// rotateCertificates renews certificates of masters of the kube one by one,
+// admin certificate of control is re-issued once all masters are done.
+func (h *Handler) rotateCertificates(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	rotation, err := h.startRotation(r.Context(), k, &model.CertRotation{})
+	h.sendRotation(w, rotation, err)
+}
+
+// resumeRotation continues the failed rotation from the master
+// it has failed on, masters that have been rotated are skipped.
+func (h *Handler) resumeRotation(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Certificates == nil || k.Certificates.Rotation == nil {
+		message.SendNotFound(w, "rotation", sgerrors.ErrNotFound)
+		return
+	}
+
+	if phase := k.Certificates.Rotation.Phase; phase != model.RotationFailed {
+		http.Error(w, fmt.Sprintf("rotation is %s", phase), http.StatusConflict)
+		return
+	}
+
+	rotation, err := h.startRotation(r.Context(), k, k.Certificates.Rotation)
+	h.sendRotation(w, rotation, err)
+}
+
+func (h *Handler) sendRotation(w http.ResponseWriter, rotation *model.CertRotation, err error) {
+	if err != nil {
+		if errors.Cause(err) == ErrInvalidRotation {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode(rotation); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// startRotation runs the rotation, masters it has rotated are skipped
+// and the master it has failed on is rotated again.
+func (h *Handler) startRotation(ctx context.Context, k *model.Kube, rotation *model.CertRotation) (*model.CertRotation, error) {
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		return nil, errors.Wrapf(ErrInvalidRotation, "kube %s is %s", k.ID, k.State)
+	}
+
+	if k.Auth.CACert == "" || k.Auth.CAKey == "" {
+		return nil, errors.Wrapf(ErrInvalidRotation, "kube %s has no CA", k.ID)
+	}
+
+	masters := sortedMasters(k)
+	if len(masters) == 0 {
+		return nil, errors.Wrapf(ErrInvalidRotation, "kube %s has no masters", k.ID)
+	}
+
+	// Control plane of masters that are not active can't be restarted,
+	// except of the master the rotation has failed on
+	for _, m := range masters {
+		if m.State != model.MachineStateActive && m.Name != rotation.Failed {
+			return nil, errors.Wrapf(ErrInvalidRotation, "master %s is %s", m.Name, m.State)
+		}
+	}
+
+	rotation.Phase = model.RotationRunning
+	rotation.Failed = ""
+	rotation.Error = ""
+	rotation.FinishedAt = time.Time{}
+	if rotation.StartedAt.IsZero() {
+		rotation.StartedAt = time.Now()
+	}
+
+	if k.Certificates == nil {
+		k.Certificates = &model.Certificates{}
+	}
+	k.Certificates.Rotation = rotation
+	k.State = model.StateRotating
+	if err := h.svc.Create(ctx, k); err != nil {
+		return nil, errors.Wrapf(err, "update kube %s", k.ID)
+	}
+
+	started := *rotation
+	go h.runRotation(k.ID)
+
+	return &started, nil
+}
+
+// ResumeRotations runs rotations of kubes that were left rotating when
+// control was stopped, masters that have been rotated are skipped.
+func (h *Handler) ResumeRotations(ctx context.Context) error {
+	kubes, err := h.svc.ListAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list kubes")
+	}
+
+	for i := range kubes {
+		k := &kubes[i]
+		if k.State != model.StateRotating {
+			continue
+		}
+
+		logrus.Infof("rotation: resume rotation of kube %s", k.ID)
+		go h.runRotation(k.ID)
+	}
+
+	return nil
+}
+
+// runRotation renews certificates of masters one by one, the rotation
+// fails on the first master that has not been rotated. Admin certificate
+// is re-issued once all masters have been rotated.
+func (h *Handler) runRotation(kubeID string) {
+	ctx := context.Background()
+
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		logrus.Errorf("rotation: get kube %s: %v", kubeID, err)
+		return
+	}
+
+	for _, m := range sortedMasters(k) {
+		if hasString(kubeRotation(k).Rotated, m.Name) {
+			continue
+		}
+
+		taskID, _, rotateErr := h.runMasterTask(ctx, k, *m, workflows.CertsRotate)
+
+		// The kube is changed by others while the master is rotated
+		k, err = h.svc.Get(ctx, kubeID)
+		if err != nil {
+			logrus.Errorf("rotation: get kube %s: %v", kubeID, err)
+			return
+		}
+		rotation := kubeRotation(k)
+
+		if taskID != "" {
+			rotation.Tasks = append(rotation.Tasks, taskID)
+		}
+
+		if rotateErr != nil {
+			logrus.Errorf("rotation: master %s of kube %s: %v", m.Name, kubeID, rotateErr)
+			if master := k.Masters[m.Name]; master != nil {
+				master.State = model.MachineStateError
+			}
+			rotation.Failed = m.Name
+			h.finishRotation(ctx, k, fmt.Sprintf("rotate master %s: %v", m.Name, rotateErr))
+			return
+		}
+
+		// Master the previous rotation has failed on is fixed
+		if master := k.Masters[m.Name]; master != nil && master.State == model.MachineStateError {
+			master.State = model.MachineStateActive
+		}
+		rotation.Rotated = append(rotation.Rotated, m.Name)
+		if err = h.svc.Create(ctx, k); err != nil {
+			logrus.Errorf("rotation: update kube %s: %v", kubeID, err)
+			return
+		}
+	}
+
+	admin, err := pki.NewAdminPair(&pki.PairPEM{
+		Cert: []byte(k.Auth.CACert),
+		Key:  []byte(k.Auth.CAKey),
+	})
+	if err != nil {
+		h.finishRotation(ctx, k, fmt.Sprintf("issue admin certificate: %v", err))
+		return
+	}
+	k.Auth.AdminCert = string(admin.Cert)
+	k.Auth.AdminKey = string(admin.Key)
+
+	h.finishRotation(ctx, k, "")
+	logrus.Infof("rotation: certificates of kube %s have been rotated", kubeID)
+
+	if err = h.checkCerts(ctx, kubeID, time.Now); err != nil {
+		logrus.Errorf("rotation: check certificates of kube %s: %v", kubeID, err)
+	}
+}
+
+// finishRotation completes the rotation, kubes with failed rotation
+// are degraded until health monitor checks them.
+func (h *Handler) finishRotation(ctx context.Context, k *model.Kube, failure string) {
+	rotation := kubeRotation(k)
+	rotation.FinishedAt = time.Now()
+	rotation.Phase = model.RotationCompleted
+	k.State = model.StateOperational
+
+	if failure != "" {
+		rotation.Phase = model.RotationFailed
+		rotation.Error = failure
+		k.State = model.StateDegraded
+	}
+
+	if err := h.svc.Create(ctx, k); err != nil {
+		logrus.Errorf("rotation: update kube %s: %v", k.ID, err)
+	}
+}
+
+func kubeRotation(k *model.Kube) *model.CertRotation {
+	if k.Certificates == nil {
+		k.Certificates = &model.Certificates{}
+	}
+	if k.Certificates.Rotation == nil {
+		k.Certificates.Rotation = &model.CertRotation{}
+	}
+
+	return k.Certificates.Rotation
+}
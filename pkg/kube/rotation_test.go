@@ -0,0 +1,236 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestRunRotation(t *testing.T) {
+	testCases := []struct {
+		failing         string
+		expectedPhase   model.RotationPhase
+		expectedState   model.KubeState
+		expectedRotated []string
+	}{
+		{
+			expectedPhase:   model.RotationCompleted,
+			expectedState:   model.StateOperational,
+			expectedRotated: []string{"master-1", "master-2"},
+		},
+		{
+			failing:         "master-1",
+			expectedPhase:   model.RotationFailed,
+			expectedState:   model.StateDegraded,
+			expectedRotated: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var (
+			m       sync.Mutex
+			rotated []string
+			failing = testCase.failing
+		)
+		workflows.Init()
+		workflows.RegisterWorkFlow(workflows.CertsRotate, []steps.Step{upgradeStep{run: func(config *steps.Config) error {
+			m.Lock()
+			defer m.Unlock()
+			rotated = append(rotated, config.Node.Name)
+			if config.Node.Name == failing {
+				return errors.New("renew failed")
+			}
+			return nil
+		}}})
+		workflows.RegisterWorkFlow(workflows.CertsExpiry, []steps.Step{noopStep{}})
+
+		k := certsKube(t)
+		adminCert := k.Auth.AdminCert
+		h, _, _, _ := specHandler(k)
+
+		k.State = model.StateRotating
+		k.Certificates = &model.Certificates{
+			Rotation: &model.CertRotation{Phase: model.RotationRunning},
+		}
+
+		h.runRotation(k.ID)
+
+		rotation := k.Certificates.Rotation
+		if rotation.Phase != testCase.expectedPhase || k.State != testCase.expectedState {
+			t.Errorf("expected phase %s state %s actual %s %s", testCase.expectedPhase,
+				testCase.expectedState, rotation.Phase, k.State)
+		}
+
+		if strings.Join(rotation.Rotated, ",") != strings.Join(testCase.expectedRotated, ",") {
+			t.Errorf("expected rotated %v actual %v", testCase.expectedRotated, rotation.Rotated)
+		}
+
+		if rotation.Failed != testCase.failing {
+			t.Errorf("expected failed master %q actual %q", testCase.failing, rotation.Failed)
+		}
+
+		m.Lock()
+		if testCase.failing != "" && len(rotated) != 1 {
+			t.Errorf("rotation must stop on the failed master, rotated %v", rotated)
+		}
+		m.Unlock()
+
+		if renewed := k.Auth.AdminCert != adminCert; renewed != (testCase.failing == "") {
+			t.Errorf("admin certificate renewed %v", renewed)
+		}
+	}
+}
+
+func TestRotateCertificatesConflict(t *testing.T) {
+	k := certsKube(t)
+	k.Masters["master-2"].State = model.MachineStateError
+	h, _, _, _ := specHandler(k)
+	router := mux.NewRouter()
+	h.Register(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/kubes/kube1234/certs/rotate", nil)
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected code %d actual %d", http.StatusConflict, rec.Code)
+	}
+
+	if k.State != model.StateOperational {
+		t.Errorf("kube must stay operational, actual %s", k.State)
+	}
+
+	// Only failed rotations are resumed
+	k.Certificates = &model.Certificates{Rotation: &model.CertRotation{Phase: model.RotationCompleted}}
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/kubes/kube1234/certs/rotate/resume", nil)
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected code %d actual %d", http.StatusConflict, rec.Code)
+	}
+}
+
+// rotationResult is taken by runner of the rotation once certificates
+// of the kube have been checked after the rotation.
+type rotationResult struct {
+	state     model.KubeState
+	adminCert string
+	failed    model.MachineState
+}
+
+func rotationHandler(k *model.Kube) (*Handler, *kubeServiceMock, chan rotationResult, *[]string) {
+	var (
+		m       sync.Mutex
+		rotated []string
+	)
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.CertsRotate, []steps.Step{upgradeStep{run: func(config *steps.Config) error {
+		m.Lock()
+		defer m.Unlock()
+		rotated = append(rotated, config.Node.Name)
+		return nil
+	}}})
+	workflows.RegisterWorkFlow(workflows.CertsExpiry, []steps.Step{noopStep{}})
+
+	h, _, _, _ := specHandler(k)
+	done := make(chan rotationResult, 1)
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, k.ID).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		k := args.Get(1).(*model.Kube)
+		if k.Certificates != nil && k.Certificates.Rotation != nil &&
+			k.Certificates.Rotation.Phase == model.RotationCompleted && !k.Certificates.CheckedAt.IsZero() {
+			select {
+			case done <- rotationResult{k.State, k.Auth.AdminCert, k.Masters["master-2"].State}:
+			default:
+			}
+		}
+	})
+	h.svc = svc
+
+	return h, svc, done, &rotated
+}
+
+func waitRotation(t *testing.T, done chan rotationResult) rotationResult {
+	select {
+	case result := <-done:
+		return result
+	case <-time.After(time.Second * 5):
+		t.Fatal("rotation has not been completed")
+	}
+
+	return rotationResult{}
+}
+
+func TestResumeRotation(t *testing.T) {
+	k := certsKube(t)
+	adminCert := k.Auth.AdminCert
+	k.State = model.StateDegraded
+	k.Masters["master-2"].State = model.MachineStateError
+	k.Certificates = &model.Certificates{
+		Rotation: &model.CertRotation{
+			Phase:   model.RotationFailed,
+			Rotated: []string{"master-1"},
+			Failed:  "master-2",
+			Error:   "rotate master master-2: renew failed",
+		},
+	}
+
+	h, _, done, rotated := rotationHandler(k)
+	router := mux.NewRouter()
+	h.Register(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/kubes/kube1234/certs/rotate/resume", nil)
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected code %d actual %d %s", http.StatusAccepted, rec.Code, rec.Body.String())
+	}
+
+	result := waitRotation(t, done)
+	if result.state != model.StateOperational || result.failed != model.MachineStateActive {
+		t.Errorf("kube must be operational %s master %s", result.state, result.failed)
+	}
+	if result.adminCert == adminCert {
+		t.Errorf("admin certificate must be renewed")
+	}
+	if strings.Join(*rotated, ",") != "master-2" {
+		t.Errorf("rotation must resume from the failed master, rotated %v", *rotated)
+	}
+}
+
+func TestResumeRotations(t *testing.T) {
+	k := certsKube(t)
+	k.State = model.StateRotating
+	k.Certificates = &model.Certificates{
+		Rotation: &model.CertRotation{
+			Phase:   model.RotationRunning,
+			Rotated: []string{"master-1"},
+		},
+	}
+
+	h, svc, done, rotated := rotationHandler(k)
+	svc.On(serviceListAll, mock.Anything).Return([]model.Kube{*k}, nil)
+
+	if err := h.ResumeRotations(context.Background()); err != nil {
+		t.Fatalf("resume rotations %v", err)
+	}
+
+	if result := waitRotation(t, done); result.state != model.StateOperational {
+		t.Errorf("kube must be operational %s", result.state)
+	}
+	if strings.Join(*rotated, ",") != "master-2" {
+		t.Errorf("rotated masters must be skipped, rotated %v", *rotated)
+	}
+}
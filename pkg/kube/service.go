@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
@@ -17,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer/versioning"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	clientcmddapi "k8s.io/client-go/tools/clientcmd/api"
 	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/proto/hapi/chart"
@@ -29,6 +31,7 @@ import (
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/sghelm/proxy"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
 )
 
@@ -229,9 +232,10 @@ func (s Service) ListNodes(ctx context.Context, kube *model.Kube, role string) (
 	return nodeList.Items, nil
 }
 
+// KubeConfigFor returns a kubeconfig of the cluster-admin user or of a user
+// control has issued the kubeconfig for, revoked and expired users have none.
 func (s Service) KubeConfigFor(ctx context.Context, kubeID, user string) ([]byte, error) {
-	// there are certificates only for the cluster-admin user
-	if user != KubernetesAdminUser {
+	if user == "" {
 		return nil, errors.Wrapf(sgerrors.ErrNotFound, "%q user", user)
 	}
 
@@ -240,11 +244,27 @@ func (s Service) KubeConfigFor(ctx context.Context, kubeID, user string) ([]byte
 		return nil, errors.Wrapf(err, "get %s model", kubeID)
 	}
 
-	kubeconfig, err := kubeconfig.AdminKubeConfig(kube)
+	if user == KubernetesAdminUser {
+		conf, err := kubeconfig.AdminKubeConfig(kube)
+		if err != nil {
+			return nil, err
+		}
+		return encodeKubeConfig(conf)
+	}
+
+	u := kube.Users[user]
+	if u == nil || !u.Active(time.Now()) || kube.Auth.IsRevoked(u.Serial) {
+		return nil, errors.Wrapf(sgerrors.ErrNotFound, "%q user", user)
+	}
+
+	conf, err := kubeconfig.UserKubeConfig(kube, u)
 	if err != nil {
 		return nil, err
 	}
+	return encodeKubeConfig(conf)
+}
 
+func encodeKubeConfig(conf clientcmddapi.Config) ([]byte, error) {
 	serializer := kubejson.NewSerializer(kubejson.DefaultMetaFactory, clientcmdlatest.Scheme, clientcmdlatest.Scheme, false)
 	codec := versioning.NewDefaultingCodecForScheme(
 		clientcmdlatest.Scheme,
@@ -253,7 +273,7 @@ func (s Service) KubeConfigFor(ctx context.Context, kubeID, user string) ([]byte
 		schema.GroupVersion{Version: clientcmdlatest.Version},
 		runtime.InternalGroupVersioner,
 	)
-	return runtime.Encode(codec, &kubeconfig)
+	return runtime.Encode(codec, &conf)
 }
 
 // GetCerts returns a keys bundle for provided component name.
@@ -264,11 +284,16 @@ func (s Service) GetCerts(ctx context.Context, kname, cname string) (*Bundle, er
 		return nil, err
 	}
 
-	// TODO(stgleb): pass host info here
-	r, err := ssh.NewRunner(ssh.Config{
-		User: kube.SSHConfig.User,
-		Key:  []byte(kube.SSHConfig.PublicKey),
-	})
+	// Certificates are kept on master machines
+	var master model.Machine
+	for _, m := range kube.Masters {
+		if m != nil && m.State == model.MachineStateActive {
+			master = *m
+			break
+		}
+	}
+
+	r, err := ssh.NewRunner(steps.SSHConfigFor(kube.SSHConfig, master))
 	if err != nil {
 		return nil, errors.Wrap(err, "setup runner")
 	}
@@ -874,6 +874,23 @@ func TestService_KubeConfigFor(t *testing.T) {
 			user:     KubernetesAdminUser,
 			kubeData: []byte(`{"masters":{"m":{"publicIp":"1.2.3.4"}}}`),
 		},
+		{
+			user: "alice",
+			kubeData: []byte(`{"masters":{"m":{"publicIp":"1.2.3.4"}},` +
+				`"users":{"alice":{"name":"alice","serial":"1","expiresAt":"2100-01-01T00:00:00Z"}}}`),
+		},
+		{
+			user: "alice",
+			kubeData: []byte(`{"masters":{"m":{"publicIp":"1.2.3.4"}},"auth":{"revokedSerials":["1"]},` +
+				`"users":{"alice":{"name":"alice","serial":"1","expiresAt":"2100-01-01T00:00:00Z"}}}`),
+			expectedErr: sgerrors.ErrNotFound,
+		},
+		{
+			user: "alice",
+			kubeData: []byte(`{"masters":{"m":{"publicIp":"1.2.3.4"}},` +
+				`"users":{"alice":{"name":"alice","serial":"1","expiresAt":"2000-01-01T00:00:00Z"}}}`),
+			expectedErr: sgerrors.ErrNotFound,
+		},
 	}
 
 	for i, tc := range testCases {
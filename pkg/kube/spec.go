@@ -0,0 +1,707 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/catalog"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/addons"
+)
+
+// Reasons of the spec conditions.
+const (
+	ReasonSpecChanged    = "SpecChanged"
+	ReasonInProgress     = "InProgress"
+	ReasonUpgrading      = "Upgrading"
+	ReasonApplying       = "Applying"
+	ReasonFailed         = "Failed"
+	ReasonConverged      = "Converged"
+	ReasonNotOperational = "NotOperational"
+)
+
+var ErrInvalidSpec = errors.New("invalid kube spec")
+
+// SpecResponse has the desired state of the kube and how far the kube is from it.
+type SpecResponse struct {
+	Spec   *model.KubeSpec  `json:"spec"`
+	Status model.KubeStatus `json:"status"`
+}
+
+// specAction is a change the kube needs to match its spec.
+type specAction struct {
+	description string
+	run         func(ctx context.Context) error
+}
+
+func (h *Handler) getSpec(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Spec == nil {
+		message.SendNotFound(w, "spec", sgerrors.ErrNotFound)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(SpecResponse{Spec: k.Spec, Status: k.Status}); err != nil {
+		message.SendUnknownError(w, err)
+	}
+}
+
+// putSpec saves the desired state of the kube, the reconciler
+// schedules workflows that apply it.
+func (h *Handler) putSpec(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	spec := &model.KubeSpec{}
+	if err = json.NewDecoder(r.Body).Decode(spec); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	if err = validateSpec(k, spec, catalog.Versions()); err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	spec.Generation = 1
+	if k.Spec != nil {
+		spec.Generation = k.Spec.Generation + 1
+	}
+	k.Spec = spec
+
+	now := time.Now()
+	k.Status.SetCondition(model.Condition{
+		Type:               model.ConditionConverging,
+		Status:             model.ConditionTrue,
+		Reason:             ReasonSpecChanged,
+		LastTransitionTime: now,
+	})
+	k.Status.SetCondition(model.Condition{
+		Type:               model.ConditionConverged,
+		Status:             model.ConditionFalse,
+		Reason:             ReasonSpecChanged,
+		LastTransitionTime: now,
+	})
+
+	if err = h.svc.Create(r.Context(), k); err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode(SpecResponse{Spec: k.Spec, Status: k.Status}); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// validateSpec checks the spec can be applied to the kube, fields that
+// are omitted are set to the current state of the kube.
+func validateSpec(k *model.Kube, spec *model.KubeSpec, versions []string) error {
+	if spec.K8SVersion == "" {
+		spec.K8SVersion = k.K8SVersion
+	}
+
+	if spec.K8SVersion != k.K8SVersion {
+		if len(versions) > 0 && !hasString(versions, spec.K8SVersion) {
+			return errors.Wrapf(ErrInvalidSpec, "version %s is not supported", spec.K8SVersion)
+		}
+
+		current, err := semver.NewVersion(k.K8SVersion)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidSpec, "kube version %s", k.K8SVersion)
+		}
+
+		target, err := semver.NewVersion(spec.K8SVersion)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidSpec, "version %s", spec.K8SVersion)
+		}
+
+		if target.LessThan(current) || target.Major() != current.Major() {
+			return errors.Wrapf(ErrInvalidSpec, "can't upgrade from version %s to %s",
+				k.K8SVersion, spec.K8SVersion)
+		}
+	}
+
+	if spec.Masters == 0 {
+		spec.Masters = len(k.Masters)
+	}
+	if spec.Masters < 0 {
+		return errors.Wrapf(ErrInvalidSpec, "invalid number of masters %d", spec.Masters)
+	}
+	if spec.Masters != len(k.Masters) && k.Provider == clouds.BYO {
+		return errors.Wrap(ErrInvalidSpec, "masters brought by user are added with their profiles to /machines")
+	}
+
+	// Pools are deleted only by an empty list of them
+	if spec.NodePools == nil {
+		spec.NodePools = currentPools(k)
+	}
+	if spec.Addons == nil {
+		spec.Addons = append([]string{}, k.Addons...)
+	}
+
+	if len(spec.NodePools) > 0 && k.Provider == clouds.BYO {
+		return errors.Wrap(ErrInvalidSpec, "node pools are not supported for machines brought by user")
+	}
+
+	if err := spec.NodePools.Validate(); err != nil {
+		return errors.Wrap(ErrInvalidSpec, err.Error())
+	}
+
+	for _, addon := range spec.Addons {
+		if !hasString(addons.Default, addon) {
+			return errors.Wrapf(ErrInvalidSpec, "unknown addon %s", addon)
+		}
+	}
+
+	for _, addon := range k.Addons {
+		if !hasString(spec.Addons, addon) {
+			return errors.Wrapf(ErrInvalidSpec, "addon %s can't be removed", addon)
+		}
+	}
+
+	names := make(map[string]bool, len(spec.Releases))
+	for _, rls := range spec.Releases {
+		if rls.Name == "" || rls.RepoName == "" || rls.ChartName == "" {
+			return errors.Wrap(ErrInvalidSpec, "release name, repo name and chart name are required")
+		}
+
+		if names[rls.Name] {
+			return errors.Wrapf(ErrInvalidSpec, "release %s is duplicated", rls.Name)
+		}
+		names[rls.Name] = true
+	}
+
+	return nil
+}
+
+// reconcileSpec schedules workflows that bring the kube closer to its spec
+// and updates conditions of the kube status. Nothing is scheduled while
+// tasks of the kube are running, the version is upgraded before the rest
+// of the spec is applied.
+func (h *Handler) reconcileSpec(ctx context.Context, k *model.Kube) error {
+	if k.Spec == nil {
+		return nil
+	}
+
+	switch {
+	case k.State == model.StateUpgrading:
+		return h.setSpecStatus(ctx, k, model.ConditionTrue, ReasonUpgrading,
+			fmt.Sprintf("kube is being upgraded to %s", k.Spec.K8SVersion))
+	case k.State != model.StateOperational && k.State != model.StateDegraded:
+		return h.setSpecStatus(ctx, k, model.ConditionFalse, ReasonNotOperational,
+			fmt.Sprintf("kube is %s", k.State))
+	}
+
+	busy, err := h.kubeBusy(ctx, k)
+	if err != nil {
+		return errors.Wrap(err, "check kube tasks")
+	}
+	if busy {
+		return h.setSpecStatus(ctx, k, model.ConditionTrue, ReasonInProgress,
+			"waiting for running tasks")
+	}
+
+	actions, err := h.planSpec(ctx, k)
+	if err != nil {
+		h.setSpecStatus(ctx, k, model.ConditionFalse, ReasonFailed, err.Error())
+		return errors.Wrap(err, "plan")
+	}
+
+	if len(actions) == 0 {
+		return h.setSpecStatus(ctx, k, model.ConditionFalse, ReasonConverged, "")
+	}
+
+	descriptions := make([]string, 0, len(actions))
+	for _, a := range actions {
+		logrus.Infof("kube %s spec: %s", k.ID, a.description)
+		if err = a.run(ctx); err != nil {
+			h.setSpecStatus(ctx, k, model.ConditionFalse, ReasonFailed,
+				fmt.Sprintf("%s: %v", a.description, err))
+			return errors.Wrap(err, a.description)
+		}
+		descriptions = append(descriptions, a.description)
+	}
+
+	reason := ReasonApplying
+	if k.State == model.StateUpgrading {
+		reason = ReasonUpgrading
+	}
+
+	return h.setSpecStatus(ctx, k, model.ConditionTrue, reason, strings.Join(descriptions, ", "))
+}
+
+// planSpec compares the spec with the stored kube and the live cluster.
+func (h *Handler) planSpec(ctx context.Context, k *model.Kube) ([]specAction, error) {
+	spec := k.Spec
+
+	if spec.K8SVersion != k.K8SVersion {
+		return []specAction{{
+			description: fmt.Sprintf("upgrade from %s to %s", k.K8SVersion, spec.K8SVersion),
+			run: func(ctx context.Context) error {
+				upgrade, err := h.startUpgrade(ctx, k, UpgradeRequest{Version: spec.K8SVersion})
+				if errors.Cause(err) == ErrPreflightFailed {
+					return errors.Wrap(err, preflightProblems(upgrade.Preflight))
+				}
+
+				return err
+			},
+		}}, nil
+	}
+
+	if actions := h.planMasters(k); len(actions) > 0 {
+		return actions, nil
+	}
+
+	actions := h.planPools(k)
+
+	missing := make([]string, 0)
+	for _, addon := range spec.Addons {
+		if !hasString(k.Addons, addon) {
+			missing = append(missing, addon)
+		}
+	}
+	if len(missing) > 0 {
+		actions = append(actions, specAction{
+			description: fmt.Sprintf("install addons %v", missing),
+			run: func(ctx context.Context) error {
+				return h.installAddons(ctx, k, missing)
+			},
+		})
+	}
+
+	if len(spec.Releases) == 0 {
+		return actions, nil
+	}
+
+	releases, err := h.svc.ListReleases(ctx, k.ID, "", "", 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "list releases")
+	}
+
+	installed := make(map[string]bool, len(releases))
+	for _, rls := range releases {
+		installed[rls.Name] = true
+	}
+
+	for _, rls := range spec.Releases {
+		if installed[rls.Name] {
+			continue
+		}
+
+		rls := rls
+		actions = append(actions, specAction{
+			description: fmt.Sprintf("install release %s", rls.Name),
+			run: func(ctx context.Context) error {
+				_, err := h.svc.InstallRelease(ctx, k.ID, &ReleaseInput{
+					Name:         rls.Name,
+					Namespace:    rls.Namespace,
+					RepoName:     rls.RepoName,
+					ChartName:    rls.ChartName,
+					ChartVersion: rls.ChartVersion,
+					Values:       rls.Values,
+				})
+				return err
+			},
+		})
+	}
+
+	return actions, nil
+}
+
+// currentPools returns pools of the kube sorted by name.
+func currentPools(k *model.Kube) profile.NodePools {
+	pools := make(profile.NodePools, 0, len(k.NodePools))
+	for _, pool := range k.NodePools {
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+
+	return pools
+}
+
+// planMasters adds missing masters or deletes one of extra masters,
+// masters are changed before the rest of the spec is applied. Unhealthy
+// masters are deleted first.
+func (h *Handler) planMasters(k *model.Kube) []specAction {
+	diff := k.Spec.Masters - len(k.Masters)
+	if k.Spec.Masters == 0 || diff == 0 {
+		return nil
+	}
+
+	if diff > 0 {
+		return []specAction{{
+			description: fmt.Sprintf("add %d masters", diff),
+			run: func(ctx context.Context) error {
+				if err := checkMastersChange(k, ""); err != nil {
+					return err
+				}
+
+				nodeProfiles, err := h.masterProfiles(ctx, k, diff)
+				if err != nil {
+					return err
+				}
+
+				_, err = h.addNodes(ctx, k, nodeProfiles)
+				return err
+			},
+		}}
+	}
+
+	masters := sortedMasters(k)
+	if len(masters) == 0 {
+		return nil
+	}
+
+	removed := masters[len(masters)-1]
+	for _, m := range masters {
+		if m.State != model.MachineStateActive {
+			removed = m
+			break
+		}
+	}
+
+	return []specAction{{
+		description: fmt.Sprintf("delete master %s", removed.Name),
+		run: func(ctx context.Context) error {
+			if err := checkMastersChange(k, removed.Name); err != nil {
+				return err
+			}
+
+			_, err := h.removeMaster(ctx, k, removed)
+			return err
+		},
+	}}
+}
+
+// planPools returns actions that add and delete nodes of the pools, pools
+// that are not in the spec are deleted with their nodes. Autoscaled pools
+// keep their size while it is within the limits, specs without pools
+// leave pools of the kube as they are.
+func (h *Handler) planPools(k *model.Kube) []specAction {
+	actions := make([]specAction, 0)
+	if k.Spec.NodePools == nil {
+		return actions
+	}
+
+	desired := make(map[string]profile.NodePool, len(k.Spec.NodePools))
+	for _, pool := range k.Spec.NodePools {
+		desired[pool.Name] = pool
+	}
+
+	names := make([]string, 0, len(desired)+len(k.NodePools))
+	for name := range desired {
+		names = append(names, name)
+	}
+	for name := range k.NodePools {
+		if _, ok := desired[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		name := name
+		machines := poolMachines(k, name)
+
+		pool, ok := desired[name]
+		if !ok {
+			if len(machines) == 0 {
+				actions = append(actions, specAction{
+					description: fmt.Sprintf("delete pool %s", name),
+					run: func(ctx context.Context) error {
+						delete(k.NodePools, name)
+						return h.svc.Create(ctx, k)
+					},
+				})
+				continue
+			}
+
+			actions = append(actions, specAction{
+				description: fmt.Sprintf("delete %d nodes of pool %s", len(machines), name),
+				run: func(ctx context.Context) error {
+					_, err := h.deletePoolNodes(ctx, k, machines)
+					return err
+				},
+			})
+			continue
+		}
+
+		if pool.IsAutoscaled() {
+			pool.Count = len(machines)
+			if pool.Count < pool.Autoscaling.Min {
+				pool.Count = pool.Autoscaling.Min
+			} else if pool.Count > pool.Autoscaling.Max {
+				pool.Count = pool.Autoscaling.Max
+			}
+		}
+
+		diff := pool.Count - len(machines)
+		if reflect.DeepEqual(k.NodePools[name], pool) && diff == 0 {
+			continue
+		}
+
+		description := fmt.Sprintf("update pool %s", name)
+		if diff > 0 {
+			description = fmt.Sprintf("add %d nodes to pool %s", diff, name)
+		} else if diff < 0 {
+			description = fmt.Sprintf("delete %d nodes of pool %s", -diff, name)
+		}
+
+		actions = append(actions, specAction{
+			description: description,
+			run: func(ctx context.Context) error {
+				if k.NodePools == nil {
+					k.NodePools = make(map[string]profile.NodePool)
+				}
+				k.NodePools[name] = pool
+
+				// Pool is saved first, provisioning takes labels and taints from the kube
+				if err := h.svc.Create(ctx, k); err != nil {
+					return errors.Wrapf(err, "update kube %s", k.ID)
+				}
+
+				var err error
+				if diff > 0 {
+					_, err = h.addPoolNodes(ctx, k, pool, machines, diff)
+				} else if diff < 0 {
+					_, err = h.deletePoolNodes(ctx, k, nodesToDelete(machines, -diff))
+				}
+
+				return err
+			},
+		})
+	}
+
+	return actions
+}
+
+// installAddons runs addons workflow on a master of the kube, addons are
+// added to the kube once they are installed.
+func (h *Handler) installAddons(ctx context.Context, k *model.Kube, names []string) error {
+	kubeProfile, err := h.profileSvc.Get(ctx, k.ProfileID)
+	if err != nil {
+		return errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
+	config, err := steps.NewConfigFromKube(kubeProfile, k)
+	if err != nil {
+		return errors.Wrap(err, "new config")
+	}
+
+	if err = util.LoadCloudSpecificDataFromKube(k, config); err != nil {
+		return errors.Wrap(err, "load cloud specific data")
+	}
+
+	master := config.GetMaster()
+	if master == nil {
+		return errors.Wrap(sgerrors.ErrNotFound, "master node")
+	}
+	config.Node = *master
+	config.Kube.Addons = names
+
+	t, err := workflows.NewTask(config, workflows.InstallAddons, h.repo)
+	if err != nil {
+		return errors.Wrap(err, "new task")
+	}
+
+	writer, err := h.getWriter(util.MakeFileName(t.ID))
+	if err != nil {
+		return errors.Wrapf(err, "get writer for %s", t.ID)
+	}
+
+	if k.Tasks == nil {
+		k.Tasks = make(map[string][]string)
+	}
+	k.Tasks[workflows.InstallAddons] = []string{t.ID}
+
+	if err = h.svc.Create(ctx, k); err != nil {
+		return errors.Wrapf(err, "update kube %s", k.ID)
+	}
+
+	kubeID := k.ID
+	go func() {
+		if err := <-t.Run(context.Background(), config, writer); err != nil {
+			logrus.Errorf("install addons %v to kube %s caused %v", names, kubeID, err)
+			return
+		}
+
+		k, err := h.svc.Get(context.Background(), kubeID)
+		if err != nil {
+			logrus.Errorf("get kube %s caused %v", kubeID, err)
+			return
+		}
+
+		for _, name := range names {
+			if !hasString(k.Addons, name) {
+				k.Addons = append(k.Addons, name)
+			}
+		}
+
+		if err = h.svc.Create(context.Background(), k); err != nil {
+			logrus.Errorf("update kube %s caused %v", kubeID, err)
+		}
+	}()
+
+	return nil
+}
+
+// kubeBusy checks whether machines of the kube are changing
+// or tasks of the kube are running, unhealthy machines are not
+// considered changing.
+func (h *Handler) kubeBusy(ctx context.Context, k *model.Kube) (bool, error) {
+	machines := make([]*model.Machine, 0, len(k.Masters)+len(k.Nodes))
+	for _, m := range []map[string]*model.Machine{k.Masters, k.Nodes} {
+		for _, machine := range m {
+			if machine != nil {
+				machines = append(machines, machine)
+			}
+		}
+	}
+	if inTransition(machines) {
+		return true, nil
+	}
+
+	tasks, err := h.getKubeTasks(ctx, k.ID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range tasks {
+		if t.Status == statuses.Todo || t.Status == statuses.Executing {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// setSpecStatus updates conditions of the kube, the kube is converged
+// when it has no changes to apply. The kube is saved only if its status
+// has changed.
+func (h *Handler) setSpecStatus(ctx context.Context, k *model.Kube, converging model.ConditionStatus,
+	reason, msg string) error {
+	now := time.Now()
+	before := model.KubeStatus{
+		ObservedGeneration: k.Status.ObservedGeneration,
+		Conditions:         append([]model.Condition(nil), k.Status.Conditions...),
+	}
+
+	converged := model.ConditionFalse
+	if reason == ReasonConverged {
+		converged = model.ConditionTrue
+		k.Status.ObservedGeneration = k.Spec.Generation
+	}
+
+	k.Status.SetCondition(model.Condition{
+		Type:               model.ConditionConverging,
+		Status:             converging,
+		Reason:             reason,
+		Message:            msg,
+		LastTransitionTime: now,
+	})
+	k.Status.SetCondition(model.Condition{
+		Type:               model.ConditionConverged,
+		Status:             converged,
+		Reason:             reason,
+		Message:            msg,
+		LastTransitionTime: now,
+	})
+
+	if reflect.DeepEqual(before, k.Status) {
+		return nil
+	}
+
+	return h.svc.Create(ctx, k)
+}
+
+// nextVersion returns a version the kube is upgraded to on the way
+// to the target one, minor versions can't be skipped.
+func nextVersion(current, target string, versions []string) (string, error) {
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse version %s", current)
+	}
+
+	tgt, err := semver.NewVersion(target)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse version %s", target)
+	}
+
+	if tgt.Minor() <= cur.Minor()+1 {
+		return target, nil
+	}
+
+	var (
+		next    *semver.Version
+		nextStr string
+	)
+
+	for _, v := range versions {
+		sv, err := semver.NewVersion(v)
+		if err != nil || sv.Major() != cur.Major() || sv.Minor() != cur.Minor()+1 {
+			continue
+		}
+
+		if next == nil || sv.GreaterThan(next) {
+			next, nextStr = sv, v
+		}
+	}
+
+	if next == nil {
+		return "", errors.Errorf("no version to upgrade from %s to %s", current, target)
+	}
+
+	return nextStr, nil
+}
+
+func hasString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,454 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+var testVersions = []string{"1.13.7", "1.14.1", "1.14.3", "1.15.1"}
+
+func specKube() *model.Kube {
+	k := poolKube()
+	k.K8SVersion = "1.13.7"
+	k.Masters["master"].State = model.MachineStateActive
+	k.Spec = &model.KubeSpec{
+		K8SVersion: "1.13.7",
+		Masters:    1,
+		NodePools:  profile.NodePools{k.NodePools["workers"]},
+		Generation: 1,
+	}
+
+	return k
+}
+
+func specHandler(k *model.Kube) (*Handler, *kubeServiceMock, *mockNodeProvisioner, *mockProvisioner) {
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+
+	profileSvc := new(mockProfileService)
+	profileSvc.On("Get", mock.Anything, mock.Anything).
+		Return(&profile.Profile{}, nil)
+
+	accService := new(accServiceMock)
+	accService.On("Get", mock.Anything, mock.Anything).
+		Return(&model.CloudAccount{Provider: clouds.DigitalOcean}, nil)
+
+	nodeProvisioner := new(mockNodeProvisioner)
+	nodeProvisioner.On("ProvisionNodes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]string{}, nil)
+
+	repo := new(testutils.MockStorage)
+	repo.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	repo.On("Get", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("not found"))
+
+	kubeProvisioner := new(mockProvisioner)
+
+	h := &Handler{
+		svc:             svc,
+		accountService:  accService,
+		profileSvc:      profileSvc,
+		nodeProvisioner: nodeProvisioner,
+		kubeProvisioner: kubeProvisioner,
+		repo:            repo,
+		getWriter: func(string) (io.WriteCloser, error) {
+			return &bufferCloser{}, nil
+		},
+	}
+
+	return h, svc, nodeProvisioner, kubeProvisioner
+}
+
+func TestValidateSpec(t *testing.T) {
+	byo := specKube()
+	byo.Provider = clouds.BYO
+
+	withAddon := specKube()
+	withAddon.Addons = []string{"dashboard"}
+
+	testCases := []struct {
+		description string
+		kube        *model.Kube
+		spec        model.KubeSpec
+		expectedErr error
+	}{
+		{
+			description: "defaults",
+			kube:        specKube(),
+		},
+		{
+			description: "upgrade",
+			kube:        specKube(),
+			spec:        model.KubeSpec{K8SVersion: "1.15.1", Masters: 1},
+		},
+		{
+			description: "downgrade",
+			kube:        specKube(),
+			spec:        model.KubeSpec{K8SVersion: "1.12.7"},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "unknown version",
+			kube:        specKube(),
+			spec:        model.KubeSpec{K8SVersion: "1.16.0"},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "masters",
+			kube:        specKube(),
+			spec:        model.KubeSpec{Masters: 3},
+		},
+		{
+			description: "invalid masters",
+			kube:        specKube(),
+			spec:        model.KubeSpec{Masters: -1},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "masters of byo kube",
+			kube:        byo,
+			spec:        model.KubeSpec{Masters: 3, NodePools: profile.NodePools{}},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "invalid pool",
+			kube:        specKube(),
+			spec:        model.KubeSpec{NodePools: profile.NodePools{{Name: "gpu"}}},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "pools of byo kube",
+			kube:        byo,
+			spec:        model.KubeSpec{NodePools: profile.NodePools{{Name: "gpu", Size: "large"}}},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "unknown addon",
+			kube:        specKube(),
+			spec:        model.KubeSpec{Addons: []string{"ingress"}},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "removed addon",
+			kube:        withAddon,
+			spec:        model.KubeSpec{Addons: []string{}},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			description: "omitted addons",
+			kube:        withAddon,
+		},
+		{
+			description: "duplicated release",
+			kube:        specKube(),
+			spec: model.KubeSpec{Releases: []model.ReleaseSpec{
+				{Name: "db", RepoName: "stable", ChartName: "postgresql"},
+				{Name: "db", RepoName: "stable", ChartName: "mysql"},
+			}},
+			expectedErr: ErrInvalidSpec,
+		},
+	}
+
+	for _, tc := range testCases {
+		spec := tc.spec
+		if err := validateSpec(tc.kube, &spec, testVersions); errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: expected error %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if tc.expectedErr == nil && (spec.K8SVersion == "" || spec.Masters == 0) {
+			t.Errorf("%s: defaults are not set %v", tc.description, spec)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	testCases := []struct {
+		current  string
+		target   string
+		expected string
+	}{
+		{"1.13.7", "1.13.9", "1.13.9"},
+		{"1.13.7", "1.14.1", "1.14.1"},
+		{"1.13.7", "1.15.1", "1.14.3"},
+		{"1.14.3", "1.15.1", "1.15.1"},
+	}
+
+	for _, tc := range testCases {
+		if v, err := nextVersion(tc.current, tc.target, testVersions); err != nil || v != tc.expected {
+			t.Errorf("%s to %s: expected %s actual %s %v", tc.current, tc.target, tc.expected, v, err)
+		}
+	}
+
+	if _, err := nextVersion("1.11.5", "1.13.7", testVersions); err == nil {
+		t.Errorf("error expected when there is no intermediate version")
+	}
+}
+
+func TestPutSpec(t *testing.T) {
+	k := specKube()
+	h, _, _, _ := specHandler(k)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/kubes/{kubeID}/spec", h.putSpec)
+
+	for _, tc := range []struct {
+		body         string
+		expectedCode int
+	}{
+		{`{"masters": `, http.StatusBadRequest},
+		{`{"masters": -1}`, http.StatusBadRequest},
+		{`{"nodePools": [{"name": "workers", "size": "s-2vcpu-4gb", "count": 3}]}`, http.StatusAccepted},
+	} {
+		req, _ := http.NewRequest(http.MethodPut, "/kubes/kube1234/spec", bytes.NewBufferString(tc.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tc.expectedCode {
+			t.Errorf("%s: expected code %d actual %d", tc.body, tc.expectedCode, rec.Code)
+		}
+	}
+
+	if k.Spec.Generation != 2 || k.Spec.NodePools[0].Count != 3 {
+		t.Errorf("wrong spec %v", k.Spec)
+	}
+
+	if c := k.Status.Condition(model.ConditionConverging); c == nil || c.Status != model.ConditionTrue {
+		t.Errorf("kube must be converging %v", k.Status)
+	}
+}
+
+func TestPutSpecVersionOnly(t *testing.T) {
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.DeleteNode, []steps.Step{})
+
+	k := specKube()
+	k.Spec = nil
+	h, _, _, _ := specHandler(k)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/kubes/{kubeID}/spec", h.putSpec)
+
+	req, _ := http.NewRequest(http.MethodPut, "/kubes/kube1234/spec", bytes.NewBufferString(`{"K8SVersion": "1.13.7"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected code %d actual %d", http.StatusAccepted, rec.Code)
+	}
+
+	if len(k.Spec.NodePools) != 1 || k.Spec.NodePools[0].Name != "workers" {
+		t.Errorf("pools of the kube must be kept %v", k.Spec.NodePools)
+	}
+
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	for _, n := range k.Nodes {
+		if n.State == model.MachineStateDeleting {
+			t.Errorf("node %s must not be deleted", n.Name)
+		}
+	}
+}
+
+func TestReconcileSpec(t *testing.T) {
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.DeleteNode, []steps.Step{})
+	workflows.RegisterWorkFlow(workflows.Upgrade, []steps.Step{})
+
+	t.Log("converged")
+	k := specKube()
+	h, _, _, _ := specHandler(k)
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	if c := k.Status.Condition(model.ConditionConverged); c == nil || c.Status != model.ConditionTrue ||
+		k.Status.ObservedGeneration != 1 {
+		t.Errorf("kube must be converged %v", k.Status)
+	}
+
+	t.Log("scale up")
+	k = specKube()
+	k.Spec.NodePools[0].Count = 3
+	h, _, nodeProvisioner, _ := specHandler(k)
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	nodeProvisioner.AssertCalled(t, "ProvisionNodes", mock.Anything, []profile.NodeProfile{
+		{"pool": "workers", "size": "s-2vcpu-4gb"},
+	}, mock.Anything, mock.Anything)
+	if c := k.Status.Condition(model.ConditionConverging); c == nil || c.Reason != ReasonApplying {
+		t.Errorf("kube must be converging %v", k.Status)
+	}
+
+	t.Log("scale up degraded")
+	k = specKube()
+	k.State = model.StateDegraded
+	k.Nodes["node-2"].State = model.MachineStateNotReady
+	k.Spec.NodePools[0].Count = 3
+	h, _, nodeProvisioner, _ = specHandler(k)
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	nodeProvisioner.AssertCalled(t, "ProvisionNodes", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	t.Log("removed pool")
+	k = specKube()
+	k.Spec.NodePools = profile.NodePools{}
+	h, _, _, _ = specHandler(k)
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	if k.Nodes["node-1"].State != model.MachineStateDeleting || k.Nodes["node-3"].State != model.MachineStateActive {
+		t.Errorf("nodes of removed pool must be deleted %v", k.Nodes)
+	}
+
+	t.Log("add masters")
+	k = specKube()
+	k.Spec.Masters = 3
+	k.Spec.NodePools[0].Count = 3
+	h, _, nodeProvisioner, _ = specHandler(k)
+	profileSvc := new(mockProfileService)
+	profileSvc.On("Get", mock.Anything, mock.Anything).
+		Return(&profile.Profile{MasterProfiles: []profile.NodeProfile{{"size": "s-2vcpu-4gb"}}}, nil)
+	h.profileSvc = profileSvc
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	// Pools are changed once masters are added
+	nodeProvisioner.AssertCalled(t, "ProvisionNodes", mock.Anything, []profile.NodeProfile{
+		{isMasterKey: "true", "size": "s-2vcpu-4gb"},
+		{isMasterKey: "true", "size": "s-2vcpu-4gb"},
+	}, mock.Anything, mock.Anything)
+	nodeProvisioner.AssertNumberOfCalls(t, "ProvisionNodes", 1)
+	if k.Spec.Masters != 3 || k.Spec.Generation != 1 {
+		t.Errorf("spec must not be changed by reconciliation %v", k.Spec)
+	}
+
+	t.Log("delete master")
+	release := make(chan struct{})
+	workflows.RegisterWorkFlow(workflows.DeleteMaster, []steps.Step{upgradeStep{run: func(*steps.Config) error {
+		<-release
+		return nil
+	}}})
+	k = specKube()
+	k.Masters["master-2"] = &model.Machine{Name: "master-2", Role: model.RoleMaster, State: model.MachineStateActive}
+	k.Masters["master-3"] = &model.Machine{Name: "master-3", Role: model.RoleMaster, State: model.MachineStateNotReady}
+	k.State = model.StateDegraded
+	k.Spec.Masters = 2
+	h, _, _, _ = specHandler(k)
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	if k.Masters["master-3"].State != model.MachineStateDeleting || k.Masters["master-2"].State != model.MachineStateActive {
+		t.Errorf("unhealthy master must be deleted %v", k.Masters)
+	}
+	close(release)
+
+	t.Log("machines are changing")
+	k = specKube()
+	k.Spec.NodePools[0].Count = 3
+	k.Nodes["node-2"].State = model.MachineStateProvisioning
+	h, _, nodeProvisioner, _ = specHandler(k)
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	nodeProvisioner.AssertNotCalled(t, "ProvisionNodes", mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything)
+	if c := k.Status.Condition(model.ConditionConverging); c == nil || c.Reason != ReasonInProgress {
+		t.Errorf("kube must wait for machines %v", k.Status)
+	}
+
+	t.Log("upgrade")
+	k = specKube()
+	k.Spec.K8SVersion = "1.14.3"
+	h, _, _, _ = specHandler(k)
+	h.upgradeChecks = passedChecks
+	upgraded, release := make(chan struct{}, 1), make(chan struct{})
+	defer func() {
+		// Runner stops once the blocked master is upgraded
+		k.Upgrade.Phase = model.UpgradeAborted
+		close(release)
+	}()
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.Upgrade, []steps.Step{upgradeStep{run: func(*steps.Config) error {
+		select {
+		case upgraded <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	}}})
+	if err := h.reconcileSpec(context.Background(), k); err != nil {
+		t.Fatalf("reconcile %v", err)
+	}
+	select {
+	case <-upgraded:
+	case <-time.After(time.Second):
+		t.Errorf("upgrade has not been started")
+	}
+	if k.Upgrade == nil || k.Upgrade.To != "1.14.3" {
+		t.Errorf("wrong upgrade %v", k.Upgrade)
+	}
+	if k.State != model.StateUpgrading {
+		t.Errorf("expected state %s actual %s", model.StateUpgrading, k.State)
+	}
+}
+
+func TestPlanSpecReleases(t *testing.T) {
+	k := specKube()
+	k.Spec.Releases = []model.ReleaseSpec{
+		{Name: "db", RepoName: "stable", ChartName: "postgresql"},
+		{Name: "cache", RepoName: "stable", ChartName: "redis"},
+	}
+	h, svc, _, _ := specHandler(k)
+	svc.rlsInfoList = []*model.ReleaseInfo{{Name: "db"}}
+
+	actions, err := h.planSpec(context.Background(), k)
+	if err != nil {
+		t.Fatalf("plan %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].description != "install release cache" {
+		t.Errorf("wrong actions %v", actions)
+	}
+}
+
+func TestSpecResponse(t *testing.T) {
+	k := specKube()
+	h, _, _, _ := specHandler(k)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/kubes/{kubeID}/spec", h.getSpec)
+
+	req, _ := http.NewRequest(http.MethodGet, "/kubes/kube1234/spec", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	resp := &SpecResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+		t.Fatalf("decode response %v", err)
+	}
+
+	if resp.Spec == nil || resp.Spec.K8SVersion != "1.13.7" {
+		t.Errorf("wrong response %v", resp)
+	}
+}
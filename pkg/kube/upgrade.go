@@ -0,0 +1,623 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/catalog"
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// DefaultMaxUnavailable is a number of workers upgraded at once by default.
+const DefaultMaxUnavailable = 1
+
+var (
+	ErrInvalidUpgrade  = errors.New("invalid upgrade")
+	ErrPreflightFailed = errors.New("preflight checks have failed")
+)
+
+// UpgradeRequest upgrades the kube to the version, it is the next
+// minor version when empty.
+type UpgradeRequest struct {
+	Version        string `json:"version"`
+	MaxUnavailable int    `json:"maxUnavailable"`
+}
+
+func (h *Handler) upgradeKube(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	logrus.Debugf("Get kube %s", kubeID)
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		http.Error(w, fmt.Sprintf("kube %s is %s", k.ID, k.State), http.StatusConflict)
+		return
+	}
+
+	req := &UpgradeRequest{}
+	if err = json.NewDecoder(r.Body).Decode(req); err != nil && err != io.EOF {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	if req.Version == "" {
+		req.Version = findNextMinorVersion(k.K8SVersion, catalog.Versions())
+	}
+
+	if req.Version == "" {
+		http.Error(w, fmt.Sprintf("can't upgrade from version %s", k.K8SVersion), http.StatusBadRequest)
+		return
+	}
+
+	if req.Version == k.K8SVersion {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	upgrade, err := h.startUpgrade(r.Context(), k, *req)
+	switch errors.Cause(err) {
+	case nil:
+		w.WriteHeader(http.StatusAccepted)
+	case ErrInvalidUpgrade:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case ErrPreflightFailed:
+		w.WriteHeader(http.StatusConflict)
+	default:
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(upgrade); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+func (h *Handler) getUpgrade(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Upgrade == nil {
+		message.SendNotFound(w, "upgrade", sgerrors.ErrNotFound)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(k.Upgrade); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// resumeUpgrade continues the paused upgrade, machines that failed
+// to be upgraded are upgraded again.
+func (h *Handler) resumeUpgrade(w http.ResponseWriter, r *http.Request) {
+	h.changeUpgrade(w, r, func(k *model.Kube) error {
+		if k.Upgrade.Phase != model.UpgradePaused {
+			return errors.Errorf("upgrade is %s", k.Upgrade.Phase)
+		}
+
+		k.Upgrade.Phase = model.UpgradeRunning
+		k.Upgrade.Error = ""
+		return nil
+	})
+}
+
+// abortUpgrade stops the upgrade once machines being upgraded are done,
+// the kube keeps the last version all machines have been upgraded to.
+func (h *Handler) abortUpgrade(w http.ResponseWriter, r *http.Request) {
+	h.changeUpgrade(w, r, func(k *model.Kube) error {
+		switch k.Upgrade.Phase {
+		case model.UpgradeRunning:
+			// Runner of the upgrade returns the kube to operational state
+			// after machines being upgraded, nothing is left to wait for
+			// between batches.
+			if !upgradingMachines(k) {
+				k.State = model.StateOperational
+			}
+		case model.UpgradePaused:
+			k.State = model.StateOperational
+		default:
+			return errors.Errorf("upgrade is %s", k.Upgrade.Phase)
+		}
+
+		k.Upgrade.Phase = model.UpgradeAborted
+		return nil
+	})
+}
+
+func (h *Handler) changeUpgrade(w http.ResponseWriter, r *http.Request, change func(*model.Kube) error) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	h.upgradeLock.Lock()
+	defer h.upgradeLock.Unlock()
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Upgrade == nil {
+		message.SendNotFound(w, "upgrade", sgerrors.ErrNotFound)
+		return
+	}
+
+	if err = change(k); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	k.Upgrade.UpdatedAt = time.Now()
+	if err = h.svc.Create(r.Context(), k); err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Upgrade.Phase == model.UpgradeRunning {
+		go h.runUpgrade(k.ID)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode(k.Upgrade); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// startUpgrade runs preflight checks and starts upgrade of the kube.
+// The upgrade with results of the checks is returned even if they fail.
+func (h *Handler) startUpgrade(ctx context.Context, k *model.Kube, req UpgradeRequest) (*model.Upgrade, error) {
+	if req.MaxUnavailable == 0 {
+		req.MaxUnavailable = DefaultMaxUnavailable
+	}
+
+	if req.MaxUnavailable < 0 {
+		return nil, errors.Wrapf(ErrInvalidUpgrade, "max unavailable %d", req.MaxUnavailable)
+	}
+
+	path, err := upgradePath(k.K8SVersion, req.Version, catalog.Versions())
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidUpgrade, err.Error())
+	}
+
+	now := time.Now()
+	upgrade := &model.Upgrade{
+		From:           k.K8SVersion,
+		To:             req.Version,
+		Path:           path,
+		Version:        path[0],
+		MaxUnavailable: req.MaxUnavailable,
+		Phase:          model.UpgradeRunning,
+		Preflight:      h.upgradeChecks(k, path[0]),
+		StartedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if !model.PreflightPassed(upgrade.Preflight) {
+		return upgrade, ErrPreflightFailed
+	}
+
+	k.Upgrade = upgrade
+	k.State = model.StateUpgrading
+	if err = h.svc.Create(ctx, k); err != nil {
+		return nil, errors.Wrapf(err, "update kube %s", k.ID)
+	}
+
+	logrus.Infof("upgrade kube %s from %s to %s through %v", k.ID, upgrade.From, upgrade.To, path)
+	go h.runUpgrade(k.ID)
+
+	return upgrade, nil
+}
+
+// upgradePath returns minor versions the kube is upgraded through to the target version.
+func upgradePath(current, target string, versions []string) ([]string, error) {
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse version %s", current)
+	}
+
+	tgt, err := semver.NewVersion(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse version %s", target)
+	}
+
+	if !tgt.GreaterThan(cur) {
+		return nil, errors.Errorf("can't upgrade from %s to %s", current, target)
+	}
+
+	if !hasString(versions, target) {
+		return nil, errors.Errorf("version %s is not supported", target)
+	}
+
+	path := make([]string, 0)
+	for version := current; version != target; {
+		if version, err = nextVersion(version, target, versions); err != nil {
+			return nil, err
+		}
+		path = append(path, version)
+	}
+
+	return path, nil
+}
+
+// ResumeUpgrades runs upgrades of kubes that were left upgrading when
+// control was stopped. Machines that were being upgraded are marked
+// failed, running upgrades upgrade them again and aborted ones return
+// their kubes to operational state.
+func (h *Handler) ResumeUpgrades(ctx context.Context) error {
+	kubes, err := h.svc.ListAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list kubes")
+	}
+
+	for i := range kubes {
+		k := &kubes[i]
+		if k.State != model.StateUpgrading || k.Upgrade == nil || k.Upgrade.Phase == model.UpgradePaused {
+			continue
+		}
+
+		failUpgradingMachines(k)
+
+		if err = h.svc.Create(ctx, k); err != nil {
+			return errors.Wrapf(err, "update kube %s", k.ID)
+		}
+
+		logrus.Infof("upgrade: resume %s upgrade of kube %s to %s", k.Upgrade.Phase, k.ID, k.Upgrade.To)
+		go h.runUpgrade(k.ID)
+	}
+
+	return nil
+}
+
+// runUpgrade upgrades machines of the kube batch by batch
+// until the upgrade is paused, aborted or completed. The upgrade
+// is paused when the runner fails, so that it could be resumed.
+func (h *Handler) runUpgrade(kubeID string) {
+	ctx := context.Background()
+
+	for {
+		k, err := h.svc.Get(ctx, kubeID)
+		if err != nil {
+			logrus.Errorf("upgrade: get kube %s: %v", kubeID, err)
+			return
+		}
+
+		done, err := h.upgradeBatch(ctx, k)
+		if err != nil {
+			logrus.Errorf("upgrade: kube %s: %v", kubeID, err)
+			if err = h.pauseUpgrade(ctx, kubeID, err); err != nil {
+				logrus.Errorf("upgrade: pause upgrade of kube %s: %v", kubeID, err)
+			}
+			return
+		}
+
+		if done {
+			return
+		}
+	}
+}
+
+// pauseUpgrade pauses the running upgrade with the error,
+// machines left upgrading are marked failed.
+func (h *Handler) pauseUpgrade(ctx context.Context, kubeID string, cause error) error {
+	h.upgradeLock.Lock()
+	defer h.upgradeLock.Unlock()
+
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	if k.Upgrade == nil || k.Upgrade.Phase != model.UpgradeRunning {
+		return nil
+	}
+
+	failUpgradingMachines(k)
+
+	k.Upgrade.Phase = model.UpgradePaused
+	k.Upgrade.Error = cause.Error()
+	k.Upgrade.UpdatedAt = time.Now()
+
+	return errors.Wrapf(h.svc.Create(ctx, k), "update kube %s", k.ID)
+}
+
+// failUpgradingMachines marks machines which upgrade has been interrupted failed.
+func failUpgradingMachines(k *model.Kube) {
+	for _, machines := range []map[string]*model.Machine{k.Masters, k.Nodes} {
+		for _, m := range machines {
+			if m != nil && m.State == model.MachineStateUpgrading {
+				m.State = model.MachineStateError
+			}
+		}
+	}
+}
+
+// upgradingMachines tells some machines of the kube are being upgraded.
+func upgradingMachines(k *model.Kube) bool {
+	for _, machines := range []map[string]*model.Machine{k.Masters, k.Nodes} {
+		for _, m := range machines {
+			if m != nil && m.State == model.MachineStateUpgrading {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// upgradeBatch upgrades the next batch of machines or moves the kube
+// to the next version once all machines are upgraded.
+func (h *Handler) upgradeBatch(ctx context.Context, k *model.Kube) (bool, error) {
+	upgrade := k.Upgrade
+	if upgrade == nil || upgrade.Phase != model.UpgradeRunning {
+		if upgrade != nil && upgrade.Phase == model.UpgradeAborted && k.State == model.StateUpgrading {
+			return true, h.finishAbortedUpgrade(ctx, k.ID)
+		}
+		return true, nil
+	}
+
+	batch, bootstrap := nextUpgradeBatch(k)
+	if len(batch) == 0 {
+		done := false
+		saved, err := h.saveRunningUpgrade(ctx, k.ID, upgrade.Version, func(k *model.Kube) {
+			logrus.Infof("upgrade: kube %s has been upgraded to %s", k.ID, k.Upgrade.Version)
+			k.K8SVersion = k.Upgrade.Version
+			k.Upgrade.Upgraded = nil
+			k.Upgrade.UpdatedAt = time.Now()
+
+			done = k.Upgrade.Version == k.Upgrade.To
+			if done {
+				k.Upgrade.Phase = model.UpgradeCompleted
+				k.State = model.StateOperational
+			} else {
+				k.Upgrade.Version = k.Upgrade.Path[indexOf(k.Upgrade.Path, k.Upgrade.Version)+1]
+			}
+		})
+
+		// Upgrade that has been changed meanwhile is picked up by the next run
+		return done && saved, err
+	}
+
+	saved, err := h.saveRunningUpgrade(ctx, k.ID, upgrade.Version, func(k *model.Kube) {
+		for _, m := range batch {
+			if machine := upgradedMachine(k, m.Name); machine != nil {
+				machine.State = model.MachineStateUpgrading
+			}
+		}
+	})
+	if err != nil || !saved {
+		return false, err
+	}
+
+	errs := make([]error, len(batch))
+	wg := sync.WaitGroup{}
+	for i := range batch {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = h.upgradeMachine(ctx, k, *batch[i], upgrade.Version, bootstrap)
+		}(i)
+	}
+	wg.Wait()
+
+	h.upgradeLock.Lock()
+	defer h.upgradeLock.Unlock()
+
+	// The upgrade could have been aborted meanwhile.
+	kubeID := k.ID
+	k, err = h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return true, errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	upgrade = k.Upgrade
+	if upgrade == nil {
+		return true, nil
+	}
+
+	for i, m := range batch {
+		machine := upgradedMachine(k, m.Name)
+
+		if errs[i] != nil {
+			logrus.Errorf("upgrade: machine %s of kube %s: %v", m.Name, k.ID, errs[i])
+			if machine != nil {
+				machine.State = model.MachineStateError
+			}
+			if upgrade.Phase == model.UpgradeRunning {
+				upgrade.Phase = model.UpgradePaused
+				upgrade.Error = fmt.Sprintf("upgrade machine %s to %s: %v", m.Name, upgrade.Version, errs[i])
+			}
+			continue
+		}
+
+		if machine != nil {
+			machine.State = model.MachineStateActive
+		}
+		upgrade.Upgraded = append(upgrade.Upgraded, m.Name)
+	}
+	upgrade.UpdatedAt = time.Now()
+
+	return false, errors.Wrapf(h.svc.Create(ctx, k), "update kube %s", k.ID)
+}
+
+// saveRunningUpgrade re-reads the kube and saves the change made by
+// the runner only if the upgrade is still running the version, so that
+// the upgrade paused or aborted meanwhile is not overwritten.
+func (h *Handler) saveRunningUpgrade(ctx context.Context, kubeID, version string, change func(*model.Kube)) (bool, error) {
+	h.upgradeLock.Lock()
+	defer h.upgradeLock.Unlock()
+
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return false, errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	if k.Upgrade == nil || k.Upgrade.Phase != model.UpgradeRunning || k.Upgrade.Version != version {
+		return false, nil
+	}
+
+	change(k)
+
+	return true, errors.Wrapf(h.svc.Create(ctx, k), "update kube %s", k.ID)
+}
+
+// finishAbortedUpgrade returns the kube which upgrade has been aborted
+// to operational state.
+func (h *Handler) finishAbortedUpgrade(ctx context.Context, kubeID string) error {
+	h.upgradeLock.Lock()
+	defer h.upgradeLock.Unlock()
+
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return errors.Wrapf(err, "get kube %s", kubeID)
+	}
+
+	if k.Upgrade == nil || k.Upgrade.Phase != model.UpgradeAborted || k.State != model.StateUpgrading {
+		return nil
+	}
+
+	logrus.Infof("upgrade: kube %s has been aborted at %s", k.ID, k.K8SVersion)
+	k.State = model.StateOperational
+
+	return errors.Wrapf(h.svc.Create(ctx, k), "update kube %s", k.ID)
+}
+
+func upgradedMachine(k *model.Kube, name string) *model.Machine {
+	if m := k.Masters[name]; m != nil {
+		return m
+	}
+
+	return k.Nodes[name]
+}
+
+// nextUpgradeBatch returns machines upgraded next, masters go one by one
+// before workers, the first master upgrades the control plane. Machines
+// being provisioned or deleted are skipped.
+func nextUpgradeBatch(k *model.Kube) ([]*model.Machine, bool) {
+	upgrade := k.Upgrade
+
+	masters := notUpgraded(k.Masters, upgrade)
+	if len(masters) > 0 {
+		bootstrap := true
+		for name := range k.Masters {
+			if upgrade.IsUpgraded(name) {
+				bootstrap = false
+			}
+		}
+
+		return masters[:1], bootstrap
+	}
+
+	nodes := notUpgraded(k.Nodes, upgrade)
+	if len(nodes) > upgrade.MaxUnavailable {
+		nodes = nodes[:upgrade.MaxUnavailable]
+	}
+
+	return nodes, false
+}
+
+func notUpgraded(machines map[string]*model.Machine, upgrade *model.Upgrade) []*model.Machine {
+	result := make([]*model.Machine, 0, len(machines))
+	for _, m := range machines {
+		if m == nil || upgrade.IsUpgraded(m.Name) {
+			continue
+		}
+
+		if m.State == model.MachineStateProvisioning || m.State == model.MachineStateDeleting {
+			continue
+		}
+
+		result = append(result, m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// upgradeMachine runs upgrade workflow on the machine.
+func (h *Handler) upgradeMachine(ctx context.Context, k *model.Kube, m model.Machine, version string, bootstrap bool) error {
+	kubeProfile, err := h.profileSvc.Get(ctx, k.ProfileID)
+	if err != nil {
+		return errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
+	config, err := steps.NewConfigFromKube(kubeProfile, k)
+	if err != nil {
+		return errors.Wrap(err, "new config")
+	}
+
+	if err = util.LoadCloudSpecificDataFromKube(k, config); err != nil {
+		return errors.Wrap(err, "load cloud specific data")
+	}
+
+	config.Kube.K8SVersion = version
+	config.Node = m
+	config.IsMaster = m.Role == model.RoleMaster
+	config.IsBootstrap = bootstrap
+
+	t, err := workflows.NewTask(config, workflows.Upgrade, h.repo)
+	if err != nil {
+		return errors.Wrapf(err, "new %s task", workflows.Upgrade)
+	}
+
+	// Note(stgleb): Reuse task ID for machine provisioning that will allow to browse
+	// logs of machine upgrade without changes on the UI
+	if m.TaskID != "" {
+		t.ID = m.TaskID
+	}
+
+	writer, err := h.getWriter(util.MakeFileName(t.ID))
+	if err != nil {
+		return errors.Wrapf(err, "get writer for %s", t.ID)
+	}
+
+	return <-t.Run(ctx, config, writer)
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+
+	return -1
+}
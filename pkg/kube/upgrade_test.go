@@ -0,0 +1,517 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type upgradeStep struct {
+	noopStep
+	run func(*steps.Config) error
+}
+
+func (s upgradeStep) Run(_ context.Context, _ io.Writer, config *steps.Config) error {
+	return s.run(config)
+}
+
+func passedChecks(*model.Kube, string) []model.PreflightCheck {
+	return []model.PreflightCheck{{Name: model.CheckVersionSkew, Passed: true}}
+}
+
+func upgradeKube() *model.Kube {
+	k := poolKube()
+	k.K8SVersion = "1.13.7"
+	k.Masters = map[string]*model.Machine{
+		"master-1": {Name: "master-1", Role: model.RoleMaster, State: model.MachineStateActive},
+		"master-2": {Name: "master-2", Role: model.RoleMaster, State: model.MachineStateActive},
+	}
+	return k
+}
+
+func TestUpgradePath(t *testing.T) {
+	versions := []string{"1.12.7", "1.13.7", "1.14.1", "1.14.3", "1.15.1"}
+
+	testCases := []struct {
+		current  string
+		target   string
+		expected []string
+		hasErr   bool
+	}{
+		{current: "1.13.7", target: "1.14.3", expected: []string{"1.14.3"}},
+		{current: "1.12.7", target: "1.15.1", expected: []string{"1.13.7", "1.14.3", "1.15.1"}},
+		{current: "1.14.1", target: "1.14.3", expected: []string{"1.14.3"}},
+		{current: "1.14.3", target: "1.13.7", hasErr: true},
+		{current: "1.13.7", target: "1.13.7", hasErr: true},
+		{current: "1.13.7", target: "1.14.2", hasErr: true},
+		{current: "1.10.1", target: "1.12.7", hasErr: true},
+	}
+
+	for _, testCase := range testCases {
+		path, err := upgradePath(testCase.current, testCase.target, versions)
+		if (err != nil) != testCase.hasErr {
+			t.Errorf("%s to %s: unexpected error %v", testCase.current, testCase.target, err)
+			continue
+		}
+
+		if strings.Join(path, ",") != strings.Join(testCase.expected, ",") {
+			t.Errorf("%s to %s: expected path %v actual %v",
+				testCase.current, testCase.target, testCase.expected, path)
+		}
+	}
+}
+
+func TestNextUpgradeBatch(t *testing.T) {
+	k := upgradeKube()
+	k.Nodes["node-3"].State = model.MachineStateProvisioning
+	k.Upgrade = &model.Upgrade{MaxUnavailable: 2}
+
+	batches := make([]string, 0)
+	for {
+		batch, bootstrap := nextUpgradeBatch(k)
+		if len(batch) == 0 {
+			break
+		}
+
+		names := make([]string, 0, len(batch))
+		for _, m := range batch {
+			names = append(names, m.Name)
+			k.Upgrade.Upgraded = append(k.Upgrade.Upgraded, m.Name)
+		}
+		if bootstrap {
+			names = append(names, "bootstrap")
+		}
+		batches = append(batches, strings.Join(names, " "))
+	}
+
+	expected := "master-1 bootstrap,master-2,node-1 node-2"
+	if actual := strings.Join(batches, ","); actual != expected {
+		t.Errorf("expected batches %s actual %s", expected, actual)
+	}
+}
+
+func TestRunUpgrade(t *testing.T) {
+	k := upgradeKube()
+	k.Nodes["node-4"] = &model.Machine{Name: "node-4", State: model.MachineStateActive}
+	h, _, _, _ := specHandler(k)
+	k.Upgrade = &model.Upgrade{
+		From:           "1.12.7",
+		To:             "1.14.3",
+		Path:           []string{"1.13.7", "1.14.3"},
+		Version:        "1.13.7",
+		Upgraded:       []string{"master-1", "master-2"},
+		MaxUnavailable: 2,
+		Phase:          model.UpgradeRunning,
+	}
+	k.State = model.StateUpgrading
+
+	var (
+		m          sync.Mutex
+		running    int
+		maxRunning int
+		failing    = "node-2"
+		bootstraps []string
+		upgradedTo = make(map[string][]string)
+		errUpgrade = errors.New("upgrade failed")
+	)
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.Upgrade, []steps.Step{upgradeStep{run: func(config *steps.Config) error {
+		m.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		name := config.Node.Name
+		upgradedTo[name] = append(upgradedTo[name], config.Kube.K8SVersion)
+		if config.IsBootstrap {
+			bootstraps = append(bootstraps, name)
+		}
+		fail := name == failing
+		m.Unlock()
+
+		defer func() {
+			m.Lock()
+			running--
+			m.Unlock()
+		}()
+
+		// Let machines of the batch overlap
+		time.Sleep(10 * time.Millisecond)
+
+		if fail {
+			return errUpgrade
+		}
+		return nil
+	}}})
+
+	h.runUpgrade(k.ID)
+
+	if k.Upgrade.Phase != model.UpgradePaused || !strings.Contains(k.Upgrade.Error, "node-2") {
+		t.Fatalf("upgrade must be paused on node-2 %+v", k.Upgrade)
+	}
+	if k.Nodes["node-2"].State != model.MachineStateError || k.Nodes["node-1"].State != model.MachineStateActive {
+		t.Errorf("wrong states of nodes %s %s", k.Nodes["node-2"].State, k.Nodes["node-1"].State)
+	}
+	if k.K8SVersion != "1.13.7" || k.State != model.StateUpgrading {
+		t.Errorf("kube must not be upgraded %s %s", k.K8SVersion, k.State)
+	}
+	if len(upgradedTo["node-3"]) != 0 {
+		t.Errorf("node-3 must wait for the failed batch")
+	}
+
+	// resume
+	failing = ""
+	k.Upgrade.Phase = model.UpgradeRunning
+	h.runUpgrade(k.ID)
+
+	if k.Upgrade.Phase != model.UpgradeCompleted || k.State != model.StateOperational || k.K8SVersion != "1.14.3" {
+		t.Fatalf("upgrade must be completed %s %s %+v", k.State, k.K8SVersion, k.Upgrade)
+	}
+	if maxRunning != 2 {
+		t.Errorf("expected 2 machines upgraded at once actual %d", maxRunning)
+	}
+	if strings.Join(bootstraps, ",") != "master-1" {
+		t.Errorf("wrong bootstrap masters %v", bootstraps)
+	}
+	if strings.Join(upgradedTo["node-2"], ",") != "1.13.7,1.13.7,1.14.3" ||
+		strings.Join(upgradedTo["master-2"], ",") != "1.14.3" {
+		t.Errorf("wrong upgrades %v", upgradedTo)
+	}
+}
+
+func TestRunUpgradePausesOnError(t *testing.T) {
+	k := upgradeKube()
+	k.Upgrade = &model.Upgrade{
+		To:             "1.14.3",
+		Path:           []string{"1.14.3"},
+		Version:        "1.14.3",
+		MaxUnavailable: 1,
+		Phase:          model.UpgradeRunning,
+	}
+	k.State = model.StateUpgrading
+
+	h, _, _, _ := specHandler(k)
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(errors.New("storage is down")).Once()
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+	h.svc = svc
+
+	h.runUpgrade(k.ID)
+
+	if k.Upgrade.Phase != model.UpgradePaused || !strings.Contains(k.Upgrade.Error, "storage is down") {
+		t.Errorf("upgrade must be paused %+v", k.Upgrade)
+	}
+	for _, m := range k.Masters {
+		if m.State == model.MachineStateUpgrading {
+			t.Errorf("master %s is left upgrading", m.Name)
+		}
+	}
+}
+
+func TestUpgradeBatchKeepsAbort(t *testing.T) {
+	k := upgradeKube()
+	k.Upgrade = &model.Upgrade{
+		To:             "1.14.3",
+		Path:           []string{"1.14.3"},
+		Version:        "1.14.3",
+		MaxUnavailable: 1,
+		Phase:          model.UpgradeRunning,
+	}
+	k.State = model.StateUpgrading
+
+	// The upgrade is aborted after the runner has read the kube
+	aborted := upgradeKube()
+	aborted.Upgrade = &model.Upgrade{Version: "1.14.3", Phase: model.UpgradeAborted}
+	aborted.State = model.StateOperational
+
+	h, _, _, _ := specHandler(k)
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(aborted, nil)
+	h.svc = svc
+
+	done, err := h.upgradeBatch(context.Background(), k)
+	if err != nil || done {
+		t.Errorf("unexpected done %v error %v", done, err)
+	}
+
+	svc.AssertNotCalled(t, serviceCreate, mock.Anything, mock.Anything)
+	for _, m := range aborted.Masters {
+		if m.State == model.MachineStateUpgrading {
+			t.Errorf("master %s of aborted upgrade is upgraded", m.Name)
+		}
+	}
+}
+
+func TestResumeUpgrades(t *testing.T) {
+	running := upgradeKube()
+	running.State = model.StateUpgrading
+	running.Masters["master-1"].State = model.MachineStateUpgrading
+	running.Upgrade = &model.Upgrade{
+		To:             "1.14.3",
+		Path:           []string{"1.14.3"},
+		Version:        "1.14.3",
+		MaxUnavailable: 1,
+		Phase:          model.UpgradeRunning,
+	}
+
+	paused := upgradeKube()
+	paused.ID = "paused"
+	paused.State = model.StateUpgrading
+	paused.Upgrade = &model.Upgrade{Phase: model.UpgradePaused}
+
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.Upgrade, []steps.Step{noopStep{}})
+
+	h, _, _, _ := specHandler(running)
+	completed := make(chan *model.Kube, 1)
+	svc := new(kubeServiceMock)
+	svc.On(serviceListAll, mock.Anything).Return([]model.Kube{*running, *paused}, nil)
+	svc.On(serviceGet, mock.Anything, running.ID).Return(running, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		if k := args.Get(1).(*model.Kube); k.Upgrade.Phase == model.UpgradeCompleted {
+			completed <- k
+		}
+	})
+	h.svc = svc
+
+	if err := h.ResumeUpgrades(context.Background()); err != nil {
+		t.Fatalf("resume upgrades %v", err)
+	}
+
+	select {
+	case k := <-completed:
+		if k.State != model.StateOperational || k.K8SVersion != "1.14.3" {
+			t.Errorf("upgrade must be completed %s %s", k.State, k.K8SVersion)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("upgrade has not been resumed")
+	}
+
+	svc.AssertNotCalled(t, serviceGet, mock.Anything, paused.ID)
+}
+
+func TestUpgradeKube(t *testing.T) {
+	notOperational := upgradeKube()
+	notOperational.State = model.StateUpgrading
+	degraded := upgradeKube()
+	degraded.State = model.StateDegraded
+
+	testCases := []struct {
+		description string
+		kube        *model.Kube
+		body        string
+		checks      func(*model.Kube, string) []model.PreflightCheck
+
+		expectedCode  int
+		expectedState model.KubeState
+	}{
+		{
+			description:  "not operational",
+			kube:         notOperational,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			description:  "invalid version",
+			kube:         upgradeKube(),
+			body:         `{"version":"1.12.7"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "invalid max unavailable",
+			kube:         upgradeKube(),
+			body:         `{"version":"1.15.1","maxUnavailable":-1}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description: "preflight failed",
+			kube:        upgradeKube(),
+			body:        `{"version":"1.15.1"}`,
+			checks: func(*model.Kube, string) []model.PreflightCheck {
+				return []model.PreflightCheck{{Name: model.CheckDisruptionBudgets, Message: "no disruptions"}}
+			},
+			expectedCode:  http.StatusConflict,
+			expectedState: model.StateOperational,
+		},
+		{
+			description: "preflight of degraded kube",
+			kube:        degraded,
+			body:        `{"version":"1.15.1"}`,
+			checks: func(*model.Kube, string) []model.PreflightCheck {
+				return []model.PreflightCheck{{Name: model.CheckDisruptionBudgets, Message: "no disruptions"}}
+			},
+			expectedCode:  http.StatusConflict,
+			expectedState: model.StateDegraded,
+		},
+		{
+			description:   "next minor version",
+			kube:          upgradeKube(),
+			checks:        passedChecks,
+			expectedCode:  http.StatusAccepted,
+			expectedState: model.StateUpgrading,
+		},
+	}
+
+	workflows.Init()
+	release := make(chan struct{})
+	workflows.RegisterWorkFlow(workflows.Upgrade, []steps.Step{upgradeStep{run: func(*steps.Config) error {
+		<-release
+		return nil
+	}}})
+
+	// Kubes are changed by runners until their upgrades are completed
+	started, completed := 0, make(chan struct{}, len(testCases))
+	defer func() {
+		close(release)
+		for i := 0; i < started; i++ {
+			select {
+			case <-completed:
+			case <-time.After(time.Second * 5):
+				t.Errorf("upgrade has not been completed")
+				return
+			}
+		}
+	}()
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		h, _, _, _ := specHandler(testCase.kube)
+		h.upgradeChecks = testCase.checks
+
+		svc := new(kubeServiceMock)
+		svc.On(serviceGet, mock.Anything, mock.Anything).Return(testCase.kube, nil)
+		svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			if k := args.Get(1).(*model.Kube); k.Upgrade != nil && k.Upgrade.Phase == model.UpgradeCompleted {
+				completed <- struct{}{}
+			}
+		})
+		h.svc = svc
+
+		req, _ := http.NewRequest(http.MethodPatch, "/kubes/"+testCase.kube.ID, bytes.NewBufferString(testCase.body))
+		rec := httptest.NewRecorder()
+		router := mux.NewRouter()
+		h.Register(router)
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.expectedCode {
+			t.Errorf("expected code %d actual %d %s", testCase.expectedCode, rec.Code, rec.Body.String())
+			continue
+		}
+
+		if testCase.expectedState == "" {
+			continue
+		}
+
+		if rec.Code == http.StatusAccepted {
+			started++
+		}
+
+		upgrade := &model.Upgrade{}
+		if err := json.NewDecoder(rec.Body).Decode(upgrade); err != nil {
+			t.Errorf("decode upgrade %v", err)
+		}
+		if upgrade.From != "1.13.7" || len(upgrade.Preflight) != 1 {
+			t.Errorf("wrong upgrade %+v", upgrade)
+		}
+		if testCase.kube.State != testCase.expectedState {
+			t.Errorf("expected state %s actual %s", testCase.expectedState, testCase.kube.State)
+		}
+	}
+}
+
+func TestChangeUpgrade(t *testing.T) {
+	testCases := []struct {
+		description string
+		action      string
+		phase       model.UpgradePhase
+		upgrading   bool
+
+		expectedCode  int
+		expectedPhase model.UpgradePhase
+		expectedState model.KubeState
+	}{
+		{
+			description:   "abort paused",
+			action:        "abort",
+			phase:         model.UpgradePaused,
+			expectedCode:  http.StatusAccepted,
+			expectedPhase: model.UpgradeAborted,
+			expectedState: model.StateOperational,
+		},
+		{
+			description:   "abort running",
+			action:        "abort",
+			phase:         model.UpgradeRunning,
+			expectedCode:  http.StatusAccepted,
+			expectedPhase: model.UpgradeAborted,
+			expectedState: model.StateOperational,
+		},
+		{
+			description:   "abort running batch",
+			action:        "abort",
+			phase:         model.UpgradeRunning,
+			upgrading:     true,
+			expectedCode:  http.StatusAccepted,
+			expectedPhase: model.UpgradeAborted,
+			expectedState: model.StateUpgrading,
+		},
+		{
+			description:   "abort completed",
+			action:        "abort",
+			phase:         model.UpgradeCompleted,
+			expectedCode:  http.StatusConflict,
+			expectedPhase: model.UpgradeCompleted,
+			expectedState: model.StateUpgrading,
+		},
+		{
+			description:   "resume running",
+			action:        "resume",
+			phase:         model.UpgradeRunning,
+			expectedCode:  http.StatusConflict,
+			expectedPhase: model.UpgradeRunning,
+			expectedState: model.StateUpgrading,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		k := upgradeKube()
+		k.State = model.StateUpgrading
+		k.Upgrade = &model.Upgrade{Phase: testCase.phase}
+		if testCase.upgrading {
+			k.Masters["master-1"].State = model.MachineStateUpgrading
+		}
+		h, _, _, _ := specHandler(k)
+
+		req, _ := http.NewRequest(http.MethodPost, "/kubes/"+k.ID+"/upgrade/"+testCase.action, nil)
+		rec := httptest.NewRecorder()
+		router := mux.NewRouter()
+		h.Register(router)
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.expectedCode {
+			t.Errorf("expected code %d actual %d %s", testCase.expectedCode, rec.Code, rec.Body.String())
+		}
+		if k.Upgrade.Phase != testCase.expectedPhase || k.State != testCase.expectedState {
+			t.Errorf("expected %s %s actual %s %s", testCase.expectedPhase, testCase.expectedState,
+				k.Upgrade.Phase, k.State)
+		}
+	}
+}
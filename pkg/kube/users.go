@@ -0,0 +1,307 @@
+package kube
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/pki"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// userBindingPrefix prefixes names of bindings control creates for users.
+const userBindingPrefix = "supergiant:user:"
+
+// listUsers returns users issued kubeconfigs for the kube, private keys
+// of users are not exposed.
+func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	names := make([]string, 0, len(k.Users))
+	for name := range k.Users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	users := make([]model.KubeUser, 0, len(names))
+	for _, name := range names {
+		users = append(users, publicUser(k.Users[name]))
+	}
+
+	if err = json.NewEncoder(w).Encode(users); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// issueUser signs a certificate for the user and binds the user to the role,
+// the kubeconfig is then available at /kubes/{kubeID}/users/{uname}/kubeconfig.
+func (h *Handler) issueUser(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	req := model.UserRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+	req.SetDefaults()
+	if err := req.Validate(); err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.State != model.StateOperational && k.State != model.StateDegraded {
+		http.Error(w, fmt.Sprintf("kube %s is %s", k.ID, k.State), http.StatusConflict)
+		return
+	}
+
+	if k.Auth.CACert == "" || k.Auth.CAKey == "" {
+		http.Error(w, fmt.Sprintf("kube %s has no CA", k.ID), http.StatusConflict)
+		return
+	}
+
+	if u := k.Users[req.Name]; u != nil && u.Active(time.Now()) {
+		message.SendAlreadyExists(w, req.Name, sgerrors.ErrAlreadyExists)
+		return
+	}
+
+	u, err := newKubeUser(k, req)
+	if err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	client, err := h.rbacClient(k)
+	if err != nil {
+		message.SendUnknownError(w, errors.Wrap(err, "build rbac client"))
+		return
+	}
+
+	// Binding of the previous kubeconfig could be in another namespace
+	if old := k.Users[u.Name]; old != nil {
+		if err = unbindUser(client, old); err != nil {
+			message.SendUnknownError(w, err)
+			return
+		}
+	}
+
+	if err = bindUser(client, u); err != nil {
+		logrus.Errorf("kubes: %s cluster: bind user %s: %v", k.ID, u.Name, err)
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if k.Users == nil {
+		k.Users = make(map[string]*model.KubeUser)
+	}
+	k.Users[u.Name] = u
+	if err = h.svc.Create(r.Context(), k); err != nil {
+		// Binding of the user that is not stored couldn't be revoked
+		if unbindErr := unbindUser(client, u); unbindErr != nil {
+			logrus.Errorf("kubes: %s cluster: unbind user %s: %v", k.ID, u.Name, unbindErr)
+		}
+		message.SendUnknownError(w, errors.Wrapf(err, "update kube %s", k.ID))
+		return
+	}
+	logrus.Infof("kubes: %s cluster: user %s has been issued a kubeconfig, serial %s", k.ID, u.Name, u.Serial)
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(publicUser(u)); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// revokeUser blacklists the certificate of the user and deletes its binding,
+// revoking a revoked user deletes the binding again. The blacklist is not
+// checked by API server, the certificate stays valid until it expires and
+// only permissions of the deleted binding are taken back, groups of the
+// certificate keep their access.
+func (h *Handler) revokeUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	kubeID := vars["kubeID"]
+	name := vars["uname"]
+
+	k, err := h.svc.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	u := k.Users[name]
+	if u == nil {
+		message.SendNotFound(w, name, sgerrors.ErrNotFound)
+		return
+	}
+
+	if u.RevokedAt == nil {
+		now := time.Now()
+		u.RevokedAt = &now
+	}
+	if !k.Auth.IsRevoked(u.Serial) {
+		k.Auth.RevokedSerials = append(k.Auth.RevokedSerials, u.Serial)
+	}
+	u.Key = ""
+
+	// The serial is blacklisted even if the kube can't be reached
+	if err = h.svc.Create(r.Context(), k); err != nil {
+		message.SendUnknownError(w, errors.Wrapf(err, "update kube %s", k.ID))
+		return
+	}
+
+	client, err := h.rbacClient(k)
+	if err != nil {
+		message.SendUnknownError(w, errors.Wrap(err, "build rbac client"))
+		return
+	}
+
+	if err = unbindUser(client, u); err != nil {
+		logrus.Errorf("kubes: %s cluster: unbind user %s: %v", k.ID, u.Name, err)
+		message.SendUnknownError(w, err)
+		return
+	}
+	logrus.Infof("kubes: %s cluster: user %s has been revoked, serial %s", k.ID, u.Name, u.Serial)
+
+	if err = json.NewEncoder(w).Encode(publicUser(u)); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// newKubeUser signs a certificate of the user with CA of the kube.
+func newKubeUser(k *model.Kube, req model.UserRequest) (*model.KubeUser, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.Wrap(err, "generate subject id")
+	}
+	subject := req.Name + ":" + hex.EncodeToString(id)
+
+	pair, err := pki.NewUserPairWithTTL(subject, req.Groups, time.Duration(req.TTLHours)*time.Hour, &pki.PairPEM{
+		Cert: []byte(k.Auth.CACert),
+		Key:  []byte(k.Auth.CAKey),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "issue certificate for %s", req.Name)
+	}
+
+	cert, err := parseCertPEM(string(pair.Cert))
+	if err != nil {
+		return nil, errors.Wrapf(err, "certificate of %s", req.Name)
+	}
+
+	return &model.KubeUser{
+		Name:      req.Name,
+		Subject:   subject,
+		Groups:    req.Groups,
+		Namespace: req.Namespace,
+		Role:      req.Role,
+		Binding:   userBindingPrefix + req.Name,
+		Serial:    cert.SerialNumber.String(),
+		Cert:      string(pair.Cert),
+		Key:       string(pair.Key),
+		IssuedAt:  time.Now().UTC(),
+		ExpiresAt: cert.NotAfter.UTC(),
+	}, nil
+}
+
+// bindUser binds the user to its cluster role in the namespace,
+// users without namespace are bound cluster wide.
+func bindUser(client rbacv1client.RbacV1Interface, u *model.KubeUser) error {
+	meta := metav1.ObjectMeta{
+		Name:      u.Binding,
+		Namespace: u.Namespace,
+	}
+	subjects := []rbacv1.Subject{{
+		Kind:     rbacv1.UserKind,
+		APIGroup: rbacv1.GroupName,
+		Name:     u.Subject,
+	}}
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     u.Role,
+	}
+
+	// Bindings of revoked users are left when the kube was unreachable,
+	// role of a binding can't be changed so the binding is recreated.
+	if u.Namespace != "" {
+		bindings := client.RoleBindings(u.Namespace)
+		binding := &rbacv1.RoleBinding{ObjectMeta: meta, Subjects: subjects, RoleRef: roleRef}
+		_, err := bindings.Create(binding)
+		if apierrors.IsAlreadyExists(err) {
+			if err = bindings.Delete(u.Binding, &metav1.DeleteOptions{}); err == nil {
+				_, err = bindings.Create(binding)
+			}
+		}
+		return errors.Wrapf(err, "create role binding %s", u.Binding)
+	}
+
+	bindings := client.ClusterRoleBindings()
+	binding := &rbacv1.ClusterRoleBinding{ObjectMeta: meta, Subjects: subjects, RoleRef: roleRef}
+	_, err := bindings.Create(binding)
+	if apierrors.IsAlreadyExists(err) {
+		if err = bindings.Delete(u.Binding, &metav1.DeleteOptions{}); err == nil {
+			_, err = bindings.Create(binding)
+		}
+	}
+	return errors.Wrapf(err, "create cluster role binding %s", u.Binding)
+}
+
+// unbindUser deletes the binding of the user, bindings that have
+// already been deleted are ignored.
+func unbindUser(client rbacv1client.RbacV1Interface, u *model.KubeUser) error {
+	var err error
+	if u.Namespace != "" {
+		err = client.RoleBindings(u.Namespace).Delete(u.Binding, &metav1.DeleteOptions{})
+	} else {
+		err = client.ClusterRoleBindings().Delete(u.Binding, &metav1.DeleteOptions{})
+	}
+
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "delete binding %s", u.Binding)
+	}
+
+	return nil
+}
+
+func publicUser(u *model.KubeUser) model.KubeUser {
+	public := *u
+	public.Key = ""
+
+	return public
+}
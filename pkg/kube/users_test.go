@@ -0,0 +1,188 @@
+package kube
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	"github.com/supergiant/control/pkg/model"
+)
+
+func usersRouter(t *testing.T) (*mux.Router, *model.Kube, *fake.Clientset) {
+	k := certsKube(t)
+	h, _, _, _ := specHandler(k)
+
+	client := fake.NewSimpleClientset()
+	h.rbacClient = func(*model.Kube) (rbacv1client.RbacV1Interface, error) {
+		return client.RbacV1(), nil
+	}
+
+	router := mux.NewRouter()
+	h.Register(router)
+
+	return router, k, client
+}
+
+func serveUsers(router *mux.Router, method, url, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestIssueUser(t *testing.T) {
+	router, k, client := usersRouter(t)
+
+	rec := serveUsers(router, http.MethodPost, "/kubes/kube1234/users",
+		`{"name":"alice","namespace":"team","role":"edit","ttlHours":24}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected code %d actual %d %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	u := model.KubeUser{}
+	if err := json.NewDecoder(rec.Body).Decode(&u); err != nil {
+		t.Fatalf("decode %v", err)
+	}
+	if u.Key != "" || u.Serial == "" || u.Namespace != "team" {
+		t.Errorf("wrong user %+v", u)
+	}
+
+	stored := k.Users["alice"]
+	if stored == nil || stored.Key == "" || stored.Serial != u.Serial {
+		t.Fatalf("user must be stored with its key %+v", stored)
+	}
+	if d := stored.ExpiresAt.Sub(stored.IssuedAt).Hours(); d > 24 || d < 23 {
+		t.Errorf("certificate must expire in 24 hours, expires at %s", stored.ExpiresAt)
+	}
+
+	binding, err := client.RbacV1().RoleBindings("team").Get(stored.Binding, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get role binding %v", err)
+	}
+	if binding.RoleRef.Name != "edit" || len(binding.Subjects) != 1 || binding.Subjects[0].Name != stored.Subject {
+		t.Errorf("wrong role binding %+v", binding)
+	}
+
+	rec = serveUsers(router, http.MethodPost, "/kubes/kube1234/users", `{"name":"alice"}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected code %d actual %d", http.StatusConflict, rec.Code)
+	}
+
+	rec = serveUsers(router, http.MethodPost, "/kubes/kube1234/users", `{"name":"bob","groups":["system:masters"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected code %d actual %d", http.StatusBadRequest, rec.Code)
+	}
+
+	rec = serveUsers(router, http.MethodPost, "/kubes/kube1234/users", `{"name":"bob","groups":["dev"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected code %d actual %d %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	cert, err := parseCertPEM(k.Users["bob"].Cert)
+	if err != nil {
+		t.Fatalf("parse certificate %v", err)
+	}
+	if cert.Subject.CommonName != k.Users["bob"].Subject || len(cert.Subject.Organization) != 1 ||
+		cert.Subject.Organization[0] != "dev" {
+		t.Errorf("wrong certificate subject %+v", cert.Subject)
+	}
+	clusterBinding, err := client.RbacV1().ClusterRoleBindings().Get(k.Users["bob"].Binding, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get cluster role binding %v", err)
+	}
+	if clusterBinding.RoleRef.Name != model.DefaultUserRole {
+		t.Errorf("wrong cluster role binding %+v", clusterBinding)
+	}
+
+	rec = serveUsers(router, http.MethodGet, "/kubes/kube1234/users", "")
+	users := make([]model.KubeUser, 0)
+	if err = json.NewDecoder(rec.Body).Decode(&users); err != nil {
+		t.Fatalf("decode %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "alice" || users[0].Key != "" {
+		t.Errorf("wrong users %+v", users)
+	}
+}
+
+func TestRevokeUser(t *testing.T) {
+	router, k, client := usersRouter(t)
+
+	rec := serveUsers(router, http.MethodPost, "/kubes/kube1234/users", `{"name":"alice","namespace":"team"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected code %d actual %d %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	serial := k.Users["alice"].Serial
+
+	rec = serveUsers(router, http.MethodDelete, "/kubes/kube1234/users/alice", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d actual %d %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	u := k.Users["alice"]
+	if u.RevokedAt == nil || u.Key != "" || !k.Auth.IsRevoked(serial) {
+		t.Errorf("user must be revoked %+v, revoked serials %v", u, k.Auth.RevokedSerials)
+	}
+
+	if _, err := client.RbacV1().RoleBindings("team").Get(u.Binding, metav1.GetOptions{}); err == nil {
+		t.Error("role binding must be deleted")
+	}
+
+	rec = serveUsers(router, http.MethodDelete, "/kubes/kube1234/users/bob", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d actual %d", http.StatusNotFound, rec.Code)
+	}
+
+	rec = serveUsers(router, http.MethodPost, "/kubes/kube1234/users", `{"name":"alice"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("revoked user must be issued again, code %d %s", rec.Code, rec.Body.String())
+	}
+	if k.Users["alice"].Serial == serial || !k.Auth.IsRevoked(serial) {
+		t.Errorf("new certificate must be issued, old serial %s stays revoked", serial)
+	}
+
+	// Binding of the new certificate must not let the revoked one in
+	if k.Users["alice"].Subject == u.Subject {
+		t.Errorf("subject %s of the revoked certificate is reused", u.Subject)
+	}
+}
+
+func TestIssueUserNotSaved(t *testing.T) {
+	k := certsKube(t)
+	h, _, _, _ := specHandler(k)
+
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, mock.Anything).Return(k, nil)
+	svc.On(serviceCreate, mock.Anything, mock.Anything).Return(errors.New("storage is down"))
+	h.svc = svc
+
+	client := fake.NewSimpleClientset()
+	h.rbacClient = func(*model.Kube) (rbacv1client.RbacV1Interface, error) {
+		return client.RbacV1(), nil
+	}
+
+	router := mux.NewRouter()
+	h.Register(router)
+
+	rec := serveUsers(router, http.MethodPost, "/kubes/kube1234/users", `{"name":"alice"}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected code %d actual %d", http.StatusInternalServerError, rec.Code)
+	}
+
+	// Binding of the user that is not stored must not be left
+	bindings, err := client.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list cluster role bindings %v", err)
+	}
+	if len(bindings.Items) != 0 {
+		t.Errorf("binding must be deleted %+v", bindings.Items)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	policyv1beta1client "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmddapi "k8s.io/client-go/tools/clientcmd/api"
@@ -65,8 +67,42 @@ func CoreV1Client(k *model.Kube) (corev1client.CoreV1Interface, error) {
 	return corev1client.NewForConfig(cfg)
 }
 
+func PolicyV1beta1Client(k *model.Kube) (policyv1beta1client.PolicyV1beta1Interface, error) {
+	cfg, err := NewConfigFor(k)
+	if err != nil {
+		return nil, err
+	}
+	return policyv1beta1client.NewForConfig(cfg)
+}
+
+func RbacV1Client(k *model.Kube) (rbacv1client.RbacV1Interface, error) {
+	cfg, err := NewConfigFor(k)
+	if err != nil {
+		return nil, err
+	}
+	return rbacv1client.NewForConfig(cfg)
+}
+
 // adminKubeConfig returns a cluster-admin kubeconfig for provided cluster.
 func AdminKubeConfig(k *model.Kube) (clientcmddapi.Config, error) {
+	if k == nil {
+		return clientcmddapi.Config{}, errors.Wrap(sgerrors.ErrNotFound, "master nodes")
+	}
+
+	return kubeConfig(k, adminContext(k.Name), k.Auth.AdminCert, k.Auth.AdminKey, "")
+}
+
+// UserKubeConfig returns a kubeconfig of the user issued by control,
+// the context of namespaced users defaults to their namespace.
+func UserKubeConfig(k *model.Kube, u *model.KubeUser) (clientcmddapi.Config, error) {
+	if k == nil || u == nil {
+		return clientcmddapi.Config{}, errors.Wrap(sgerrors.ErrNotFound, "user")
+	}
+
+	return kubeConfig(k, u.Name+"@"+k.Name, u.Cert, u.Key, u.Namespace)
+}
+
+func kubeConfig(k *model.Kube, contextName, cert, key, namespace string) (clientcmddapi.Config, error) {
 	// TODO: this should be an address of the master load balancer
 	if k == nil || (k.ExternalDNSName == "" && len(k.Masters) == 0) {
 		// TODO: use another base error, not ErrNotFound
@@ -89,9 +125,9 @@ func AdminKubeConfig(k *model.Kube) (clientcmddapi.Config, error) {
 	// TODO: add validation
 	return clientcmddapi.Config{
 		AuthInfos: map[string]*clientcmddapi.AuthInfo{
-			adminContext(k.Name): {
-				ClientCertificateData: []byte(k.Auth.AdminCert),
-				ClientKeyData:         []byte(k.Auth.AdminKey),
+			contextName: {
+				ClientCertificateData: []byte(cert),
+				ClientKeyData:         []byte(key),
 			},
 		},
 		Clusters: map[string]*clientcmddapi.Cluster{
@@ -101,12 +137,13 @@ func AdminKubeConfig(k *model.Kube) (clientcmddapi.Config, error) {
 			},
 		},
 		Contexts: map[string]*clientcmddapi.Context{
-			adminContext(k.Name): {
-				AuthInfo: adminContext(k.Name),
-				Cluster:  k.Name,
+			contextName: {
+				AuthInfo:  contextName,
+				Cluster:   k.Name,
+				Namespace: namespace,
 			},
 		},
-		CurrentContext: adminContext(k.Name),
+		CurrentContext: contextName,
 	}, nil
 }
 
@@ -83,3 +83,45 @@ func TestDiscoveryClient(t *testing.T) {
 		}
 	}
 }
+
+func TestUserKubeConfig(t *testing.T) {
+	k := &model.Kube{
+		Name:          "test",
+		APIServerPort: 443,
+		Masters: map[string]*model.Machine{
+			"node-1": {
+				Name:     "node-1",
+				PublicIp: "10.20.30.40",
+			},
+		},
+	}
+	u := &model.KubeUser{
+		Name:      "alice",
+		Namespace: "dev",
+		Cert:      "cert",
+		Key:       "key",
+	}
+
+	if _, err := UserKubeConfig(k, nil); errors.Cause(err) != sgerrors.ErrNotFound {
+		t.Errorf("expected error %v actual %v", sgerrors.ErrNotFound, err)
+	}
+
+	conf, err := UserKubeConfig(k, u)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if conf.CurrentContext != "alice@test" {
+		t.Errorf("wrong current context %s", conf.CurrentContext)
+	}
+	if c := conf.Contexts[conf.CurrentContext]; c == nil || c.Namespace != "dev" || c.Cluster != "test" {
+		t.Errorf("wrong context %+v", c)
+	}
+	if a := conf.AuthInfos[conf.CurrentContext]; a == nil || string(a.ClientCertificateData) != "cert" ||
+		string(a.ClientKeyData) != "key" {
+		t.Errorf("wrong auth info %+v", a)
+	}
+	if c := conf.Clusters["test"]; c == nil || c.Server != "https://10.20.30.40:443" {
+		t.Errorf("wrong cluster %+v", c)
+	}
+}
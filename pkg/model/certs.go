@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+)
+
+// CertExpiry is an expiry date of the certificate of the machine,
+// certificates issued by control have no machine.
+type CertExpiry struct {
+	Name     string    `json:"name"`
+	Machine  string    `json:"machine,omitempty"`
+	NotAfter time.Time `json:"notAfter"`
+}
+
+// RotationPhase is a phase of rotating certificates of the kube.
+type RotationPhase string
+
+const (
+	RotationRunning   RotationPhase = "running"
+	RotationCompleted RotationPhase = "completed"
+	RotationFailed    RotationPhase = "failed"
+)
+
+// CertRotation renews control plane certificates on masters of the kube
+// one by one and re-issues admin certificate of control.
+type CertRotation struct {
+	Phase RotationPhase `json:"phase"`
+	// Rotated are masters that have new certificates.
+	Rotated []string `json:"rotated"`
+	// Failed is a master the rotation has failed on,
+	// resumed rotation starts from it.
+	Failed     string    `json:"failed,omitempty"`
+	Tasks      []string  `json:"tasks"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+// Certificates of the kube gathered from its masters.
+type Certificates struct {
+	Expiries  []CertExpiry `json:"expiries"`
+	CheckedAt time.Time    `json:"checkedAt"`
+	// Error is set when certificates of some masters were not gathered.
+	Error string `json:"error,omitempty"`
+	// Rotation is the latest rotation of certificates.
+	Rotation *CertRotation `json:"rotation,omitempty"`
+}
+
+// Earliest returns the certificate that expires first or nil.
+func (c *Certificates) Earliest() *CertExpiry {
+	var earliest *CertExpiry
+	for i := range c.Expiries {
+		if earliest == nil || c.Expiries[i].NotAfter.Before(earliest.NotAfter) {
+			earliest = &c.Expiries[i]
+		}
+	}
+
+	return earliest
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"reflect"
+	"time"
+)
+
+// MaxHealthHistory is a number of health records kept for a kube.
+const MaxHealthHistory = 100
+
+// Health of a kube, history has a record for every change of the health.
+type Health struct {
+	CheckedAt time.Time      `json:"checkedAt"`
+	History   []HealthRecord `json:"history,omitempty"`
+}
+
+// HealthRecord is a result of a health check.
+type HealthRecord struct {
+	Time               time.Time `json:"time"`
+	State              KubeState `json:"state"`
+	APIServerReachable bool      `json:"apiServerReachable"`
+	// Machines maps names of machines that aren't active to their states.
+	Machines map[string]MachineState `json:"machines,omitempty"`
+	Problems []string                `json:"problems,omitempty"`
+}
+
+// Record adds the record to the history unless it is the same as the last
+// one, the oldest records are dropped when the history is too long.
+func (h *Health) Record(r HealthRecord) {
+	h.CheckedAt = r.Time
+
+	if n := len(h.History); n > 0 && h.History[n-1].sameAs(r) {
+		return
+	}
+
+	h.History = append(h.History, r)
+	if len(h.History) > MaxHealthHistory {
+		h.History = h.History[len(h.History)-MaxHealthHistory:]
+	}
+}
+
+// Last returns the latest health record or nil.
+func (h Health) Last() *HealthRecord {
+	if len(h.History) == 0 {
+		return nil
+	}
+
+	return &h.History[len(h.History)-1]
+}
+
+func (r HealthRecord) sameAs(o HealthRecord) bool {
+	r.Time, o.Time = time.Time{}, time.Time{}
+	return reflect.DeepEqual(r, o)
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthRecord(t *testing.T) {
+	start := time.Now()
+	h := &Health{}
+
+	h.Record(HealthRecord{Time: start, State: StateOperational, APIServerReachable: true})
+	h.Record(HealthRecord{Time: start.Add(time.Minute), State: StateOperational, APIServerReachable: true})
+
+	if len(h.History) != 1 || !h.CheckedAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("same records must not be repeated %v", h)
+	}
+
+	h.Record(HealthRecord{
+		Time:     start.Add(time.Minute * 2),
+		State:    StateDegraded,
+		Machines: map[string]MachineState{"node-1": MachineStateNotReady},
+	})
+	if last := h.Last(); len(h.History) != 2 || last.State != StateDegraded {
+		t.Errorf("wrong history %v", h.History)
+	}
+
+	for i := 0; i < MaxHealthHistory*2; i++ {
+		h.Record(HealthRecord{Time: start, State: StateDegraded, Problems: []string{string(rune('a' + i%2))}})
+	}
+	if len(h.History) != MaxHealthHistory {
+		t.Errorf("expected %d records actual %d", MaxHealthHistory, len(h.History))
+	}
+
+	if (Health{}).Last() != nil {
+		t.Errorf("empty history has no last record")
+	}
+}
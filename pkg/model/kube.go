@@ -14,6 +14,12 @@ const (
 	StateDeleting     KubeState = "deleting"
 	StateImporting    KubeState = "importing"
 	StateUpgrading    KubeState = "upgrading"
+	StateRestoring    KubeState = "restoring"
+	StateRotating     KubeState = "rotating"
+	// StateDegraded is set by health monitor to operational kubes
+	// that have unhealthy machines or unreachable API server, degraded
+	// kubes can be changed the same way as operational ones.
+	StateDegraded KubeState = "degraded"
 )
 
 // Kube represents a kubernetes cluster.
@@ -40,6 +46,7 @@ type Kube struct {
 	OperatingSystem        string            `json:"operatingSystem"`
 	OperatingSystemVersion string            `json:"operatingSystemVersion"`
 	DockerVersion          string            `json:"dockerVersion"`
+	ContainerRuntime       string            `json:"containerRuntime,omitempty"`
 	K8SVersion             string            `json:"K8SVersion"`
 	HelmVersion            string            `json:"helmVersion"`
 	Networking             Networking        `json:"networking"`
@@ -55,14 +62,40 @@ type Kube struct {
 
 	Masters map[string]*Machine `json:"masters"`
 	Nodes   map[string]*Machine `json:"nodes"`
+	// NodePools maps pool names to pools, nodes refer to their pool by name.
+	NodePools map[string]profile.NodePool `json:"nodePools,omitempty"`
 	// Store taskIds of tasks that are made to provision this kube
 	Tasks map[string][]string `json:"tasks"`
+	// Spec is a desired state of the kube, it is set when the kube
+	// is managed declaratively.
+	Spec   *KubeSpec  `json:"spec,omitempty"`
+	Status KubeStatus `json:"status"`
+	// Health is updated by health monitor.
+	Health Health `json:"health"`
+	// AutoRepair policy is applied by health monitor, Repairs are
+	// the latest replacements of nodes.
+	AutoRepair *AutoRepairPolicy `json:"autoRepair,omitempty"`
+	Repairs    []Repair          `json:"repairs,omitempty"`
+	// Upgrade is the latest upgrade of the kube.
+	Upgrade *Upgrade `json:"upgrade,omitempty"`
+	// Restore is the latest restore of the kube from a backup.
+	Restore *Restore `json:"restore,omitempty"`
+	// Certificates are expiry dates of certificates and their rotation.
+	Certificates *Certificates `json:"certificates,omitempty"`
+	// Users are issued kubeconfigs, revoked users are kept to
+	// record their access.
+	Users map[string]*KubeUser `json:"users,omitempty"`
 
 	SSHConfig SSHConfig `json:"sshConfig"`
 
 	UserData         string              `json:"userData"`
 	ExposedAddresses []profile.Addresses `json:"exposedAddresses"`
 	Addons           []string            `json:"addons,omitempty"`
+	Mirrors          profile.Mirrors     `json:"mirrors,omitempty"`
+	// BootstrapMode is either ssh(default) or cloud-init, machines bootstrapped
+	// with cloud-init report progress with CallbackToken.
+	BootstrapMode string `json:"bootstrapMode,omitempty"`
+	CallbackToken string `json:"callbackToken,omitempty"`
 }
 
 type SSHConfig struct {
@@ -72,6 +105,20 @@ type SSHConfig struct {
 	BootstrapPublicKey  string `json:"bootstrapPublicKey"`
 	PublicKey           string `json:"publicKey"`
 	Timeout             int    `json:"timeout"`
+	// BootstrapKeyType is an algorithm of generated bootstrap key
+	BootstrapKeyType string `json:"bootstrapKeyType,omitempty"`
+
+	JumpHosts []profile.JumpHost `json:"jumpHosts,omitempty"`
+}
+
+// HostFor returns address that ssh connections to the machine are made to,
+// machines behind jump hosts are reached by their private address.
+func (c SSHConfig) HostFor(m Machine) string {
+	if len(c.JumpHosts) > 0 && m.PrivateIp != "" {
+		return m.PrivateIp
+	}
+
+	return m.PublicIp
 }
 
 // Auth holds all possible auth parameters.
@@ -88,6 +135,8 @@ type Auth struct {
 	AdminKey       string             `json:"adminKey"`
 	CertificateKey string             `json:"certificateKey"`
 	StaticAuth     profile.StaticAuth `json:"staticAuth"`
+	// RevokedSerials are serial numbers of revoked user certificates.
+	RevokedSerials []string `json:"revokedSerials,omitempty"`
 }
 
 type Networking struct {
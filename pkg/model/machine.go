@@ -22,6 +22,10 @@ const (
 	MachineStateActive       MachineState = "active"
 	MachineStateDeleting     MachineState = "deleting"
 	MachineStateUpgrading    MachineState = "upgrading"
+	// States health monitor sets to machines that were active.
+	MachineStateNotReady MachineState = "notReady"
+	MachineStateStopped  MachineState = "stopped"
+	MachineStateMissing  MachineState = "missing"
 
 	RoleMaster Role = "master"
 	RoleNode   Role = "node"
@@ -41,6 +45,15 @@ type Machine struct {
 	State            MachineState `json:"state"`
 	Name             string       `json:"name"`
 	SelfLink         string       `json:"selfLink"`
+	// SSHUser and SSHKey are set for machines that are not reached
+	// with ssh credentials of the kube e.g. brought by user.
+	SSHUser string `json:"sshUser,omitempty"`
+	SSHKey  string `json:"sshKey,omitempty"`
+	// Pool is a name of the node pool the machine belongs to.
+	Pool string `json:"pool,omitempty"`
+	// UnhealthySince is unix time health monitor found
+	// the machine not active at.
+	UnhealthySince int64 `json:"unhealthySince,omitempty"`
 }
 
 func (m Machine) String() string {
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxRepairHistory is a number of repairs kept for a kube.
+const MaxRepairHistory = 50
+
+var ErrInvalidAutoRepair = errors.New("invalid auto repair policy")
+
+// AutoRepairPolicy lets control replace worker nodes that stay unhealthy.
+type AutoRepairPolicy struct {
+	Enabled bool `json:"enabled"`
+	// UnhealthyMinutes is how long a node stays not ready, stopped
+	// or missing before it is replaced.
+	UnhealthyMinutes int `json:"unhealthyMinutes"`
+	// MaxUnhealthy stops repairs when more nodes are unhealthy, that is
+	// rather an outage of the cloud or network than broken nodes.
+	MaxUnhealthy int `json:"maxUnhealthy"`
+	// MaxRepairsPerHour limits how many nodes are replaced in an hour.
+	MaxRepairsPerHour int `json:"maxRepairsPerHour"`
+}
+
+// Repair is a replacement of an unhealthy node.
+type Repair struct {
+	Time    time.Time    `json:"time"`
+	Machine string       `json:"machine"`
+	State   MachineState `json:"state"`
+	// DeleteTask drains and deletes the node, ProvisionTasks add the replacement.
+	DeleteTask     string   `json:"deleteTask,omitempty"`
+	ProvisionTasks []string `json:"provisionTasks,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// SetDefaults sets zero limits of the policy to default values.
+func (p *AutoRepairPolicy) SetDefaults() {
+	if p.UnhealthyMinutes == 0 {
+		p.UnhealthyMinutes = 10
+	}
+	if p.MaxUnhealthy == 0 {
+		p.MaxUnhealthy = 2
+	}
+	if p.MaxRepairsPerHour == 0 {
+		p.MaxRepairsPerHour = 2
+	}
+}
+
+// Validate checks limits of the policy are positive.
+func (p AutoRepairPolicy) Validate() error {
+	if p.UnhealthyMinutes < 1 || p.MaxUnhealthy < 1 || p.MaxRepairsPerHour < 1 {
+		return errors.Wrapf(ErrInvalidAutoRepair, "unhealthy minutes %d, max unhealthy %d, max repairs per hour %d",
+			p.UnhealthyMinutes, p.MaxUnhealthy, p.MaxRepairsPerHour)
+	}
+
+	return nil
+}
+
+// AddRepair adds the repair to the kube, the oldest repairs are dropped.
+func (k *Kube) AddRepair(r Repair) {
+	k.Repairs = append(k.Repairs, r)
+	if len(k.Repairs) > MaxRepairHistory {
+		k.Repairs = k.Repairs[len(k.Repairs)-MaxRepairHistory:]
+	}
+}
+
+// RepairsSince returns a number of repairs started after the time.
+func (k *Kube) RepairsSince(t time.Time) int {
+	count := 0
+	for _, r := range k.Repairs {
+		if r.Time.After(t) {
+			count++
+		}
+	}
+
+	return count
+}
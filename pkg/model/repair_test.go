@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestAutoRepairPolicy(t *testing.T) {
+	p := &AutoRepairPolicy{MaxUnhealthy: 5}
+	p.SetDefaults()
+
+	if p.UnhealthyMinutes != 10 || p.MaxUnhealthy != 5 || p.MaxRepairsPerHour != 2 {
+		t.Errorf("wrong defaults %v", p)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	p.MaxRepairsPerHour = -1
+	if err := p.Validate(); errors.Cause(err) != ErrInvalidAutoRepair {
+		t.Errorf("expected error %v actual %v", ErrInvalidAutoRepair, err)
+	}
+}
+
+func TestKubeAddRepair(t *testing.T) {
+	now := time.Now()
+	k := &Kube{}
+
+	for i := 0; i < MaxRepairHistory+5; i++ {
+		k.AddRepair(Repair{Time: now.Add(-time.Duration(MaxRepairHistory+5-i) * time.Minute)})
+	}
+
+	if len(k.Repairs) != MaxRepairHistory {
+		t.Errorf("expected %d repairs actual %d", MaxRepairHistory, len(k.Repairs))
+	}
+	if count := k.RepairsSince(now.Add(-time.Hour)); count != MaxRepairHistory {
+		t.Errorf("expected %d repairs in the last hour actual %d", MaxRepairHistory, count)
+	}
+	if count := k.RepairsSince(now.Add(-10*time.Minute - time.Second)); count != 10 {
+		t.Errorf("expected 10 repairs actual %d", count)
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// RestorePhase is a phase of restoring the kube from a backup.
+type RestorePhase string
+
+const (
+	RestoreRunning   RestorePhase = "running"
+	RestoreCompleted RestorePhase = "completed"
+	RestoreFailed    RestorePhase = "failed"
+)
+
+// Restore replaces etcd data on every master of the kube with
+// the snapshot of the backup.
+type Restore struct {
+	BackupID string       `json:"backupId"`
+	Phase    RestorePhase `json:"phase"`
+	// Tasks restore etcd on masters.
+	Tasks      []string  `json:"tasks"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
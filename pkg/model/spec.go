@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"github.com/supergiant/control/pkg/profile"
+)
+
+type ConditionType string
+
+type ConditionStatus string
+
+const (
+	// ConditionConverging is true while control changes the kube
+	// to match its spec.
+	ConditionConverging ConditionType = "Converging"
+	// ConditionConverged is true when the kube matches its spec.
+	ConditionConverged ConditionType = "Converged"
+	// ConditionCertsExpiring is true when certificates of the kube
+	// expire soon and have to be rotated.
+	ConditionCertsExpiring ConditionType = "CertificatesExpiring"
+
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// KubeSpec is a desired state of a kube, control schedules workflows
+// that change the kube until it matches the spec.
+type KubeSpec struct {
+	K8SVersion string            `json:"K8SVersion"`
+	Masters    int               `json:"masters"`
+	NodePools  profile.NodePools `json:"nodePools"`
+	Addons     []string          `json:"addons"`
+	Releases   []ReleaseSpec     `json:"releases"`
+	// Generation is increased on every change of the spec.
+	Generation int64 `json:"generation"`
+}
+
+// ReleaseSpec is a helm release that must be installed to the kube.
+type ReleaseSpec struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	RepoName     string `json:"repoName"`
+	ChartName    string `json:"chartName"`
+	ChartVersion string `json:"chartVersion"`
+	Values       string `json:"values"`
+}
+
+// KubeStatus reports how far the kube is from its spec.
+type KubeStatus struct {
+	// ObservedGeneration is a generation of the spec the kube has converged to.
+	ObservedGeneration int64       `json:"observedGeneration"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+}
+
+type Condition struct {
+	Type               ConditionType   `json:"type"`
+	Status             ConditionStatus `json:"status"`
+	Reason             string          `json:"reason,omitempty"`
+	Message            string          `json:"message,omitempty"`
+	LastTransitionTime time.Time       `json:"lastTransitionTime"`
+}
+
+// Condition returns the condition of the type or nil.
+func (s KubeStatus) Condition(t ConditionType) *Condition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			return &s.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// SetCondition adds or updates the condition, transition time is
+// kept unless status of the condition changes.
+func (s *KubeStatus) SetCondition(c Condition) {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type != c.Type {
+			continue
+		}
+
+		if s.Conditions[i].Status == c.Status {
+			c.LastTransitionTime = s.Conditions[i].LastTransitionTime
+		}
+		s.Conditions[i] = c
+		return
+	}
+
+	s.Conditions = append(s.Conditions, c)
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKubeStatusSetCondition(t *testing.T) {
+	start := time.Now()
+	s := &KubeStatus{}
+
+	s.SetCondition(Condition{Type: ConditionConverging, Status: ConditionTrue, LastTransitionTime: start})
+	s.SetCondition(Condition{Type: ConditionConverged, Status: ConditionFalse, LastTransitionTime: start})
+
+	s.SetCondition(Condition{Type: ConditionConverging, Status: ConditionTrue, Reason: "Upgrading",
+		LastTransitionTime: start.Add(time.Minute)})
+	if c := s.Condition(ConditionConverging); c.Reason != "Upgrading" || !c.LastTransitionTime.Equal(start) {
+		t.Errorf("transition time must be kept when status is the same %v", c)
+	}
+
+	s.SetCondition(Condition{Type: ConditionConverged, Status: ConditionTrue,
+		LastTransitionTime: start.Add(time.Minute)})
+	if c := s.Condition(ConditionConverged); !c.LastTransitionTime.Equal(start.Add(time.Minute)) {
+		t.Errorf("transition time must be updated %v", c)
+	}
+
+	if len(s.Conditions) != 2 || s.Condition("Unknown") != nil {
+		t.Errorf("wrong conditions %v", s.Conditions)
+	}
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+)
+
+// UpgradePhase is a phase of the kube upgrade.
+type UpgradePhase string
+
+const (
+	UpgradeRunning   UpgradePhase = "running"
+	UpgradePaused    UpgradePhase = "paused"
+	UpgradeAborted   UpgradePhase = "aborted"
+	UpgradeCompleted UpgradePhase = "completed"
+)
+
+// Names of checks run before the upgrade.
+const (
+	CheckVersionSkew       = "versionSkew"
+	CheckComponentHealth   = "componentHealth"
+	CheckDiskSpace         = "diskSpace"
+	CheckDisruptionBudgets = "disruptionBudgets"
+)
+
+// PreflightCheck is a result of the check run before the upgrade.
+type PreflightCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// Upgrade walks the kube through minor versions to the target one,
+// machines are upgraded to a version before the next one is started.
+type Upgrade struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Path is a list of versions the kube goes through, To is the last one.
+	Path []string `json:"path"`
+	// Version is the version machines are being upgraded to,
+	// Upgraded are the machines that already have it.
+	Version  string   `json:"version"`
+	Upgraded []string `json:"upgraded,omitempty"`
+	// MaxUnavailable is a number of workers upgraded at once,
+	// masters are always upgraded one by one.
+	MaxUnavailable int          `json:"maxUnavailable"`
+	Phase          UpgradePhase `json:"phase"`
+	// Error is the reason of the pause.
+	Error     string           `json:"error,omitempty"`
+	Preflight []PreflightCheck `json:"preflight,omitempty"`
+	StartedAt time.Time        `json:"startedAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// PreflightPassed tells whether all the checks have passed.
+func PreflightPassed(checks []PreflightCheck) bool {
+	for _, c := range checks {
+		if !c.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsUpgraded tells whether the machine has been upgraded to the current version.
+func (u *Upgrade) IsUpgraded(name string) bool {
+	for _, upgraded := range u.Upgraded {
+		if upgraded == name {
+			return true
+		}
+	}
+
+	return false
+}
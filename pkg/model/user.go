@@ -0,0 +1,98 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultUserRole is a cluster role users are bound to by default.
+	DefaultUserRole = "view"
+	// DefaultUserTTLHours is how long kubeconfigs of users are valid by default.
+	DefaultUserTTLHours = 365 * 24
+)
+
+var ErrInvalidUser = errors.New("invalid user")
+
+// UserRequest describes a kubeconfig to issue for a user of the kube.
+type UserRequest struct {
+	Name string `json:"name"`
+	// Groups are put to the certificate, permissions granted to them are
+	// not taken back by revoking the user, system groups are refused.
+	Groups []string `json:"groups"`
+	// Namespace limits access of the user to the namespace,
+	// users without namespace are bound cluster wide.
+	Namespace string `json:"namespace"`
+	// Role is a cluster role the user is bound to.
+	Role     string `json:"role"`
+	TTLHours int    `json:"ttlHours"`
+}
+
+// KubeUser is a user control has issued a kubeconfig for. API server
+// doesn't check revoked serials, revoking the user deletes only its binding,
+// so the certificate keeps permissions granted to its groups until it expires.
+type KubeUser struct {
+	Name string `json:"name"`
+	// Subject is a user name of the certificate and the binding, it is
+	// unique per issue, so certificates of revoked users are not bound
+	// again when the name is issued a new kubeconfig.
+	Subject   string   `json:"subject"`
+	Groups    []string `json:"groups,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Role      string   `json:"role"`
+	// Binding is a name of the role binding, cluster role binding
+	// for users without namespace.
+	Binding string `json:"binding"`
+	Serial  string `json:"serial"`
+	Cert    string `json:"cert,omitempty"`
+	Key     string `json:"key,omitempty"`
+
+	IssuedAt  time.Time  `json:"issuedAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// SetDefaults sets empty role and ttl of the request to default values.
+func (r *UserRequest) SetDefaults() {
+	if r.Role == "" {
+		r.Role = DefaultUserRole
+	}
+	if r.TTLHours == 0 {
+		r.TTLHours = DefaultUserTTLHours
+	}
+}
+
+// Validate checks the user can be issued a kubeconfig and revoked later.
+func (r UserRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" || strings.HasPrefix(r.Name, "system:") {
+		return errors.Wrapf(ErrInvalidUser, "name %q", r.Name)
+	}
+	if r.TTLHours < 1 {
+		return errors.Wrapf(ErrInvalidUser, "ttl hours %d", r.TTLHours)
+	}
+	for _, g := range r.Groups {
+		if strings.TrimSpace(g) == "" || strings.HasPrefix(g, "system:") {
+			return errors.Wrapf(ErrInvalidUser, "group %q", g)
+		}
+	}
+
+	return nil
+}
+
+// Active tells the user can access the kube at the time.
+func (u *KubeUser) Active(now time.Time) bool {
+	return u.RevokedAt == nil && now.Before(u.ExpiresAt)
+}
+
+// IsRevoked tells the certificate with the serial number has been revoked.
+func (a Auth) IsRevoked(serial string) bool {
+	for _, s := range a.RevokedSerials {
+		if s == serial {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestUserRequest(t *testing.T) {
+	r := &UserRequest{Name: "alice", Groups: []string{"dev"}}
+	r.SetDefaults()
+
+	if r.Role != DefaultUserRole || r.TTLHours != DefaultUserTTLHours {
+		t.Errorf("wrong defaults %v", r)
+	}
+	if err := r.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	for _, invalid := range []UserRequest{
+		{Name: "", TTLHours: 1},
+		{Name: "system:admin", TTLHours: 1},
+		{Name: "alice", TTLHours: -1},
+		{Name: "alice", TTLHours: 1, Groups: []string{"dev", "system:masters"}},
+		{Name: "alice", TTLHours: 1, Groups: []string{""}},
+	} {
+		if err := invalid.Validate(); errors.Cause(err) != ErrInvalidUser {
+			t.Errorf("expected error %v for %v actual %v", ErrInvalidUser, invalid, err)
+		}
+	}
+}
+
+func TestKubeUserActive(t *testing.T) {
+	now := time.Now()
+	u := &KubeUser{ExpiresAt: now.Add(time.Hour)}
+
+	if !u.Active(now) {
+		t.Error("user must be active")
+	}
+	if u.Active(now.Add(2 * time.Hour)) {
+		t.Error("expired user must not be active")
+	}
+
+	u.RevokedAt = &now
+	if u.Active(now) {
+		t.Error("revoked user must not be active")
+	}
+}
+
+func TestAuthIsRevoked(t *testing.T) {
+	a := Auth{RevokedSerials: []string{"1", "2"}}
+
+	if !a.IsRevoked("2") || a.IsRevoked("3") {
+		t.Errorf("wrong revoked serials %v", a.RevokedSerials)
+	}
+}
@@ -26,6 +26,16 @@ func NewAdminPair(ca *PairPEM) (*PairPEM, error) {
 
 // NewUserPair creates certificates for a kubernetes user.
 func NewUserPair(userName string, userGroups []string, caEncoded *PairPEM) (*PairPEM, error) {
+	return NewUserPairWithTTL(userName, userGroups, duration365d, caEncoded)
+}
+
+// NewUserPairWithTTL creates certificates for a kubernetes user that
+// are valid for the ttl.
+func NewUserPairWithTTL(userName string, userGroups []string, ttl time.Duration, caEncoded *PairPEM) (*PairPEM, error) {
+	if ttl <= 0 {
+		return nil, errors.Errorf("invalid ttl %s", ttl)
+	}
+
 	ca, err := Decode(caEncoded)
 	if err != nil {
 		return nil, errors.Wrap(err, "decode ca cert/key")
@@ -41,7 +51,7 @@ func NewUserPair(userName string, userGroups []string, caEncoded *PairPEM) (*Pai
 		Organization: userGroups,
 		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 	}
-	cert, err := newSignedCert(cfg, key, ca.Cert, ca.Key)
+	cert, err := newSignedCert(cfg, key, ca.Cert, ca.Key, ttl)
 	if err != nil {
 		return nil, errors.Wrap(err, "sign certificate")
 	}
@@ -53,7 +63,7 @@ func NewUserPair(userName string, userGroups []string, caEncoded *PairPEM) (*Pai
 }
 
 // newSignedCert creates a signed certificate using the given CA certificate and key
-func newSignedCert(cfg certutil.Config, key crypto.Signer, caCert *x509.Certificate, caKey crypto.Signer) (*x509.Certificate, error) {
+func newSignedCert(cfg certutil.Config, key crypto.Signer, caCert *x509.Certificate, caKey crypto.Signer, ttl time.Duration) (*x509.Certificate, error) {
 	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
 	if err != nil {
 		return nil, err
@@ -74,7 +84,7 @@ func newSignedCert(cfg certutil.Config, key crypto.Signer, caCert *x509.Certific
 		IPAddresses:  cfg.AltNames.IPs,
 		SerialNumber: serial,
 		NotBefore:    caCert.NotBefore,
-		NotAfter:     time.Now().Add(duration365d).UTC(),
+		NotAfter:     time.Now().Add(ttl).UTC(),
 		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:  cfg.Usages,
 	}
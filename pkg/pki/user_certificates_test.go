@@ -1,6 +1,11 @@
 package pki
 
-import "testing"
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
 
 func TestNewAdminPair(t *testing.T) {
 	cert, key, _ := newCertificateAuthority()
@@ -44,3 +49,38 @@ func TestNewUserPair(t *testing.T) {
 		t.Errorf("pair pem must not be nil")
 	}
 }
+
+func TestNewUserPairWithTTL(t *testing.T) {
+	cert, key, _ := newCertificateAuthority()
+	pemPair, _ := Encode(&Pair{
+		Cert: cert,
+		Key:  key,
+	})
+
+	if _, err := NewUserPairWithTTL("alice", nil, 0, pemPair); err == nil {
+		t.Error("error expected for zero ttl")
+	}
+
+	pairPem, err := NewUserPairWithTTL("alice", []string{"dev"}, time.Hour, pemPair)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	block, _ := pem.Decode(pairPem.Cert)
+	if block == nil {
+		t.Fatal("certificate is not pem encoded")
+	}
+	userCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse certificate %v", err)
+	}
+
+	if userCert.Subject.CommonName != "alice" || len(userCert.Subject.Organization) != 1 ||
+		userCert.Subject.Organization[0] != "dev" {
+		t.Errorf("wrong subject %v", userCert.Subject)
+	}
+
+	if d := time.Until(userCert.NotAfter); d > time.Hour || d < 50*time.Minute {
+		t.Errorf("certificate must expire in an hour, expires at %s", userCert.NotAfter)
+	}
+}
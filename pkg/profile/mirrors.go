@@ -0,0 +1,128 @@
+package profile
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultDockerRepository     = "https://download.docker.com"
+	DefaultKubernetesRepository = "https://packages.cloud.google.com"
+	DefaultKubicRepository      = "https://download.opensuse.org/repositories/devel:/kubic:/libcontainers:/stable"
+	DefaultBinariesURL          = "https://storage.googleapis.com"
+	DefaultStableRepository     = "https://kubernetes-charts.storage.googleapis.com"
+
+	StableRepositoryName = "stable"
+)
+
+var ErrInvalidMirror = errors.New("invalid mirror")
+
+// Mirrors replace public repositories, registries and download locations
+// for machines that have no access to the internet, empty fields mean
+// public ones are used.
+type Mirrors struct {
+	// PackageRepository is a base url of an apt/yum mirror, it serves
+	// download.docker.com under /docker, packages.cloud.google.com under
+	// /kubernetes and kubic libcontainers stable repository under /kubic.
+	PackageRepository string `json:"packageRepository,omitempty"`
+	// HTTPProxy is used for http and https connections from the machines.
+	HTTPProxy string `json:"httpProxy,omitempty"`
+	// NoProxy is a comma separated list of hosts and cidrs reached directly,
+	// cluster addresses are added to it.
+	NoProxy string `json:"noProxy,omitempty"`
+	// ImageRegistry replaces registry of every image, the rest of image
+	// name stays the same, e.g. quay.io/coreos/flannel becomes
+	// <registry>/coreos/flannel and k8s.gcr.io/pause becomes <registry>/pause.
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+	// BinariesURL replaces storage.googleapis.com kubectl, cni and helm
+	// are downloaded from, the paths stay the same.
+	BinariesURL string `json:"binariesUrl,omitempty"`
+	// HelmRepositories maps names of chart repositories to their urls,
+	// they override public ones e.g. stable.
+	HelmRepositories map[string]string `json:"helmRepositories,omitempty"`
+}
+
+// Validate checks urls of the mirrors.
+func (m Mirrors) Validate() error {
+	urls := map[string]string{
+		"package repository": m.PackageRepository,
+		"http proxy":         m.HTTPProxy,
+		"binaries url":       m.BinariesURL,
+	}
+	for name, u := range m.HelmRepositories {
+		urls["helm repository "+name] = u
+	}
+
+	for name, u := range urls {
+		if u == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Host == "" ||
+			(parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return errors.Wrapf(ErrInvalidMirror, "%s %s", name, u)
+		}
+	}
+
+	if strings.Contains(m.ImageRegistry, "://") {
+		return errors.Wrapf(ErrInvalidMirror, "image registry %s must not have a scheme",
+			m.ImageRegistry)
+	}
+
+	return nil
+}
+
+func (m Mirrors) DockerRepository() string {
+	return m.packages("docker", DefaultDockerRepository)
+}
+
+func (m Mirrors) KubernetesRepository() string {
+	return m.packages("kubernetes", DefaultKubernetesRepository)
+}
+
+func (m Mirrors) KubicRepository() string {
+	return m.packages("kubic", DefaultKubicRepository)
+}
+
+func (m Mirrors) packages(path, defaultURL string) string {
+	if m.PackageRepository == "" {
+		return defaultURL
+	}
+
+	return strings.TrimSuffix(m.PackageRepository, "/") + "/" + path
+}
+
+func (m Mirrors) Binaries() string {
+	if m.BinariesURL == "" {
+		return DefaultBinariesURL
+	}
+
+	return strings.TrimSuffix(m.BinariesURL, "/")
+}
+
+// StableRepository is an url of the stable charts repository.
+func (m Mirrors) StableRepository() string {
+	if u := m.HelmRepositories[StableRepositoryName]; u != "" {
+		return u
+	}
+
+	return DefaultStableRepository
+}
+
+// Image returns name of the image in the registry mirror.
+func (m Mirrors) Image(image string) string {
+	if m.ImageRegistry == "" {
+		return image
+	}
+
+	// first part of the name is a registry when it looks like a host
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		image = parts[1]
+	}
+
+	return strings.TrimSuffix(m.ImageRegistry, "/") + "/" + image
+}
@@ -0,0 +1,117 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMirrorsValidate(t *testing.T) {
+	testCases := []struct {
+		description string
+		mirrors     Mirrors
+		expectedErr error
+	}{
+		{
+			description: "empty",
+		},
+		{
+			description: "valid",
+			mirrors: Mirrors{
+				PackageRepository: "http://mirror.local",
+				HTTPProxy:         "http://proxy.local:3128",
+				ImageRegistry:     "registry.local:5000",
+				BinariesURL:       "https://mirror.local/binaries",
+				HelmRepositories:  map[string]string{"stable": "http://charts.local"},
+			},
+		},
+		{
+			description: "no scheme",
+			mirrors: Mirrors{
+				PackageRepository: "mirror.local",
+			},
+			expectedErr: ErrInvalidMirror,
+		},
+		{
+			description: "invalid helm repository",
+			mirrors: Mirrors{
+				HelmRepositories: map[string]string{"stable": "ftp://charts.local"},
+			},
+			expectedErr: ErrInvalidMirror,
+		},
+		{
+			description: "registry with scheme",
+			mirrors: Mirrors{
+				ImageRegistry: "https://registry.local",
+			},
+			expectedErr: ErrInvalidMirror,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.mirrors.Validate()
+		if errors.Cause(err) != testCase.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v",
+				testCase.description, testCase.expectedErr, err)
+		}
+	}
+}
+
+func TestMirrorsDefaults(t *testing.T) {
+	m := Mirrors{}
+
+	if m.DockerRepository() != DefaultDockerRepository ||
+		m.KubernetesRepository() != DefaultKubernetesRepository ||
+		m.KubicRepository() != DefaultKubicRepository ||
+		m.Binaries() != DefaultBinariesURL ||
+		m.StableRepository() != DefaultStableRepository {
+		t.Errorf("public repositories must be used when mirrors are not set")
+	}
+
+	if image := m.Image("quay.io/coreos/flannel:v0.10.0"); image != "quay.io/coreos/flannel:v0.10.0" {
+		t.Errorf("wrong image %s", image)
+	}
+}
+
+func TestMirrorsRepositories(t *testing.T) {
+	m := Mirrors{
+		PackageRepository: "http://mirror.local/",
+		BinariesURL:       "http://mirror.local/binaries/",
+		HelmRepositories:  map[string]string{"stable": "http://charts.local"},
+	}
+
+	testCases := map[string]string{
+		m.DockerRepository():     "http://mirror.local/docker",
+		m.KubernetesRepository(): "http://mirror.local/kubernetes",
+		m.KubicRepository():      "http://mirror.local/kubic",
+		m.Binaries():             "http://mirror.local/binaries",
+		m.StableRepository():     "http://charts.local",
+	}
+
+	for actual, expected := range testCases {
+		if actual != expected {
+			t.Errorf("wrong url expected %s actual %s", expected, actual)
+		}
+	}
+}
+
+func TestMirrorsImage(t *testing.T) {
+	m := Mirrors{
+		ImageRegistry: "registry.local:5000",
+	}
+
+	testCases := map[string]string{
+		"quay.io/coreos/flannel:v0.10.0":        "registry.local:5000/coreos/flannel:v0.10.0",
+		"docker.io/weaveworks/weave-kube:2.5.1": "registry.local:5000/weaveworks/weave-kube:2.5.1",
+		"calico/node:v3.3.2":                    "registry.local:5000/calico/node:v3.3.2",
+		"k8s.gcr.io/pause:3.1":                  "registry.local:5000/pause:3.1",
+		"localhost/app":                         "registry.local:5000/app",
+		"busybox":                               "registry.local:5000/busybox",
+	}
+
+	for image, expected := range testCases {
+		if actual := m.Image(image); actual != expected {
+			t.Errorf("image %s: expected %s actual %s", image, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package profile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+)
+
+const (
+	// NodePoolLabel is set on every node of a pool in addition to the
+	// labels of the pool.
+	NodePoolLabel = "supergiant.io/node-pool"
+
+	// Keys of node profiles that are made from pools.
+	NodePoolKey         = "pool"
+	SizeKey             = "size"
+	AvailabilityZoneKey = "availabilityZone"
+
+	TaintNoSchedule       = "NoSchedule"
+	TaintPreferNoSchedule = "PreferNoSchedule"
+	TaintNoExecute        = "NoExecute"
+)
+
+var (
+	ErrInvalidNodePool = errors.New("invalid node pool")
+
+	poolNameRegexp   = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	labelKeyRegexp   = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelValueRegexp = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+)
+
+// NodePool is a named group of nodes of the same size that are spread
+// across availability zones and registered with the same labels and taints.
+type NodePool struct {
+	Name string `json:"name"`
+	Size string `json:"size"`
+	// Count is a desired number of nodes in the pool.
+	Count int `json:"count"`
+	// Zones nodes are spread across, empty means the zone of the kube.
+	Zones  []string          `json:"zones,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Taints []Taint           `json:"taints,omitempty"`
+	// Autoscaling lets control change Count of the pool.
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+}
+
+// Autoscaling keeps between Min and Max nodes in the pool, nodes are added
+// for pods that can't be scheduled and removed when they are underutilized.
+type Autoscaling struct {
+	Enabled bool `json:"enabled"`
+	Min     int  `json:"min"`
+	Max     int  `json:"max"`
+}
+
+// Taint is registered on the nodes of the pool when they join the kube.
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// Validate checks the pool name, labels and taints are acceptable
+// for kubernetes.
+func (p NodePool) Validate() error {
+	if len(p.Name) > 63 || !poolNameRegexp.MatchString(p.Name) {
+		return errors.Wrapf(ErrInvalidNodePool, "name %q", p.Name)
+	}
+
+	if p.Size == "" {
+		return errors.Wrapf(ErrInvalidNodePool, "pool %s has no size", p.Name)
+	}
+
+	if p.Count < 0 {
+		return errors.Wrapf(ErrInvalidNodePool, "pool %s count %d", p.Name, p.Count)
+	}
+
+	if a := p.Autoscaling; a != nil && a.Enabled && (a.Min < 0 || a.Max < 1 || a.Min > a.Max) {
+		return errors.Wrapf(ErrInvalidNodePool, "pool %s autoscaling min %d max %d",
+			p.Name, a.Min, a.Max)
+	}
+
+	for k, v := range p.Labels {
+		if !validLabel(k, v) {
+			return errors.Wrapf(ErrInvalidNodePool, "pool %s label %s=%s", p.Name, k, v)
+		}
+	}
+
+	for _, t := range p.Taints {
+		if !validLabel(t.Key, t.Value) {
+			return errors.Wrapf(ErrInvalidNodePool, "pool %s taint %s=%s", p.Name, t.Key, t.Value)
+		}
+
+		switch t.Effect {
+		case TaintNoSchedule, TaintPreferNoSchedule, TaintNoExecute:
+		default:
+			return errors.Wrapf(ErrInvalidNodePool, "pool %s taint %s effect %q",
+				p.Name, t.Key, t.Effect)
+		}
+	}
+
+	return nil
+}
+
+// IsAutoscaled reports whether control changes size of the pool.
+func (p NodePool) IsAutoscaled() bool {
+	return p.Autoscaling != nil && p.Autoscaling.Enabled
+}
+
+// NodeLabels returns labels of the pool nodes in a format of kubelet
+// --node-labels flag.
+func (p NodePool) NodeLabels() string {
+	if p.Name == "" {
+		return ""
+	}
+
+	labels := make([]string, 0, len(p.Labels)+1)
+	for k, v := range p.Labels {
+		if k == NodePoolLabel {
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(labels)
+
+	return strings.Join(append([]string{NodePoolLabel + "=" + p.Name}, labels...), ",")
+}
+
+// NodeProfiles makes profiles of n new nodes of the pool, every node is put
+// into the zone that has the least nodes, zones maps zone to the number
+// of nodes the pool already has there.
+func (p NodePool) NodeProfiles(provider clouds.Name, zones map[string]int, n int) []NodeProfile {
+	counts := make(map[string]int, len(p.Zones))
+	for _, zone := range p.Zones {
+		counts[zone] = zones[zone]
+	}
+
+	sizeKey := SizeKey
+	if provider == clouds.Azure {
+		sizeKey = "vmSize"
+	}
+
+	profiles := make([]NodeProfile, 0, n)
+	for i := 0; i < n; i++ {
+		nodeProfile := NodeProfile{
+			NodePoolKey: p.Name,
+			sizeKey:     p.Size,
+		}
+
+		if zone := leastUsedZone(p.Zones, counts); zone != "" {
+			nodeProfile[AvailabilityZoneKey] = zone
+			counts[zone]++
+		}
+
+		profiles = append(profiles, nodeProfile)
+	}
+
+	return profiles
+}
+
+// NodePools of a kube.
+type NodePools []NodePool
+
+// Validate checks every pool is valid and pool names are unique.
+func (pools NodePools) Validate() error {
+	names := make(map[string]bool, len(pools))
+
+	for _, p := range pools {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+
+		if names[p.Name] {
+			return errors.Wrapf(ErrInvalidNodePool, "pool %s is duplicated", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	return nil
+}
+
+// NodeProfiles makes profiles of all nodes of the pools.
+func (pools NodePools) NodeProfiles(provider clouds.Name) []NodeProfile {
+	profiles := make([]NodeProfile, 0)
+
+	for _, p := range pools {
+		profiles = append(profiles, p.NodeProfiles(provider, nil, p.Count)...)
+	}
+
+	return profiles
+}
+
+func leastUsedZone(zones []string, counts map[string]int) string {
+	var zone string
+
+	for _, z := range zones {
+		if zone == "" || counts[z] < counts[zone] {
+			zone = z
+		}
+	}
+
+	return zone
+}
+
+func validLabel(key, value string) bool {
+	if len(key) > 316 || len(value) > 63 {
+		return false
+	}
+
+	return labelKeyRegexp.MatchString(key) && labelValueRegexp.MatchString(value)
+}
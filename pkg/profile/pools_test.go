@@ -0,0 +1,133 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+)
+
+func TestNodePoolsValidate(t *testing.T) {
+	valid := NodePool{
+		Name:   "gpu",
+		Size:   "p2.xlarge",
+		Count:  2,
+		Labels: map[string]string{"accelerator": "nvidia", "example.com/tier": ""},
+		Taints: []Taint{{Key: "dedicated", Value: "gpu", Effect: TaintNoSchedule}},
+	}
+
+	testCases := []struct {
+		description string
+		pools       NodePools
+		expectedErr error
+	}{
+		{
+			description: "empty",
+		},
+		{
+			description: "valid",
+			pools:       NodePools{valid, {Name: "workers", Size: "m5.large"}},
+		},
+		{
+			description: "invalid name",
+			pools:       NodePools{{Name: "GPU nodes", Size: "p2.xlarge"}},
+			expectedErr: ErrInvalidNodePool,
+		},
+		{
+			description: "no size",
+			pools:       NodePools{{Name: "gpu"}},
+			expectedErr: ErrInvalidNodePool,
+		},
+		{
+			description: "negative count",
+			pools:       NodePools{{Name: "gpu", Size: "p2.xlarge", Count: -1}},
+			expectedErr: ErrInvalidNodePool,
+		},
+		{
+			description: "invalid label",
+			pools: NodePools{{Name: "gpu", Size: "p2.xlarge",
+				Labels: map[string]string{"accelerator": "nvidia tesla"}}},
+			expectedErr: ErrInvalidNodePool,
+		},
+		{
+			description: "invalid taint effect",
+			pools: NodePools{{Name: "gpu", Size: "p2.xlarge",
+				Taints: []Taint{{Key: "dedicated", Effect: "Never"}}}},
+			expectedErr: ErrInvalidNodePool,
+		},
+		{
+			description: "min is greater than max",
+			pools: NodePools{{Name: "gpu", Size: "p2.xlarge",
+				Autoscaling: &Autoscaling{Enabled: true, Min: 3, Max: 2}}},
+			expectedErr: ErrInvalidNodePool,
+		},
+		{
+			description: "autoscaling is disabled",
+			pools: NodePools{{Name: "gpu", Size: "p2.xlarge",
+				Autoscaling: &Autoscaling{Min: 3}}},
+		},
+		{
+			description: "duplicated",
+			pools:       NodePools{valid, valid},
+			expectedErr: ErrInvalidNodePool,
+		},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.pools.Validate(); errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: expected error %v actual %v", tc.description, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestNodePoolNodeLabels(t *testing.T) {
+	p := NodePool{
+		Name:   "gpu",
+		Labels: map[string]string{"disk": "ssd", "accelerator": "nvidia", NodePoolLabel: "other"},
+	}
+
+	expected := "supergiant.io/node-pool=gpu,accelerator=nvidia,disk=ssd"
+	if labels := p.NodeLabels(); labels != expected {
+		t.Errorf("expected labels %s actual %s", expected, labels)
+	}
+
+	if labels := (NodePool{}).NodeLabels(); labels != "" {
+		t.Errorf("node that has no pool must not be labeled %s", labels)
+	}
+}
+
+func TestNodePoolNodeProfiles(t *testing.T) {
+	p := NodePool{
+		Name:  "workers",
+		Size:  "m5.large",
+		Zones: []string{"us-east-1a", "us-east-1b", "us-east-1c"},
+	}
+
+	profiles := p.NodeProfiles(clouds.AWS, map[string]int{"us-east-1a": 2, "us-east-1b": 1}, 4)
+
+	zones := make([]string, 0, len(profiles))
+	for _, nodeProfile := range profiles {
+		if nodeProfile[NodePoolKey] != "workers" || nodeProfile[SizeKey] != "m5.large" {
+			t.Errorf("wrong node profile %v", nodeProfile)
+		}
+		zones = append(zones, nodeProfile[AvailabilityZoneKey])
+	}
+
+	expected := []string{"us-east-1c", "us-east-1b", "us-east-1c", "us-east-1a"}
+	for i := range expected {
+		if zones[i] != expected[i] {
+			t.Fatalf("expected zones %v actual %v", expected, zones)
+		}
+	}
+
+	if nodeProfile := p.NodeProfiles(clouds.Azure, nil, 1)[0]; nodeProfile["vmSize"] != "m5.large" {
+		t.Errorf("wrong azure node profile %v", nodeProfile)
+	}
+
+	pools := NodePools{p, {Name: "gpu", Size: "p2.xlarge", Count: 1}}
+	pools[0].Count = 2
+	if profiles := pools.NodeProfiles(clouds.AWS); len(profiles) != 3 || profiles[2][NodePoolKey] != "gpu" {
+		t.Errorf("wrong node profiles %v", profiles)
+	}
+}
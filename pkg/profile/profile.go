@@ -7,6 +7,9 @@ type Profile struct {
 
 	MasterProfiles []NodeProfile `json:"masterProfiles" valid:"-"`
 	NodesProfiles  []NodeProfile `json:"nodesProfiles" valid:"-"`
+	// NodePools are provisioned in addition to NodesProfiles, their nodes
+	// are labeled and tainted on join.
+	NodePools NodePools `json:"nodePools,omitempty" valid:"-"`
 
 	// StaticAuth represents tokens and basic authentication credentials that
 	// would be set to kube-apiserver on start.
@@ -40,6 +43,20 @@ type Profile struct {
 	Subnets               map[string]string     `json:"subnets" valid:"-"`
 	CloudSpecificSettings CloudSpecificSettings `json:"cloudSpecificSettings" valid:"-"`
 	PublicKey             string                `json:"publicKey" valid:"-"`
+	// SSHKeyType is an algorithm of the bootstrap key: rsa(default), ecdsa or ed25519.
+	SSHKeyType string `json:"sshKeyType,omitempty" valid:"-"`
+	// BootstrapPrivateKey is PEM encoded key used to provision machines
+	// instead of a generated one.
+	BootstrapPrivateKey string `json:"bootstrapPrivateKey,omitempty" valid:"-"`
+	// JumpHosts are used to reach machines that have no public addresses.
+	JumpHosts []JumpHost `json:"jumpHosts,omitempty" valid:"-"`
+	// ContainerRuntime of the nodes: docker(default), containerd or cri-o.
+	ContainerRuntime string `json:"containerRuntime,omitempty" valid:"-"`
+	// Mirrors are used instead of public repositories in air-gapped environments.
+	Mirrors Mirrors `json:"mirrors,omitempty" valid:"-"`
+	// BootstrapMode chooses how steps run on machines: over ssh(default) or
+	// from cloud-init user data.
+	BootstrapMode string `json:"bootstrapMode,omitempty" valid:"-"`
 
 	// ExposedAddresses is a list of cidr/port pairs that will be exposes
 	// by cloud provider security groups.
@@ -55,6 +72,15 @@ type Addresses struct {
 	CIDR string `json:"cidr"`
 }
 
+// JumpHost is a bastion that ssh connections to the machines are tunneled
+// through, empty user and key mean the same ones as for the machines.
+type JumpHost struct {
+	Host       string `json:"host"`
+	Port       string `json:"port"`
+	User       string `json:"user"`
+	PrivateKey string `json:"privateKey"`
+}
+
 // StaticAuth represents tokens and basic authentication credentials.
 type StaticAuth struct {
 	BasicAuth []BasicAuthUser `json:"basicAuth"`
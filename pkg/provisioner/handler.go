@@ -13,6 +13,7 @@ import (
 	"gopkg.in/asaskevich/govalidator.v8"
 
 	"github.com/supergiant/control/pkg/account"
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/message"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
@@ -106,25 +107,28 @@ func (h *Handler) Provision(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acc, err := h.accountGetter.Get(r.Context(), req.CloudAccountName)
+	// Machines brought by user are provisioned without a cloud account
+	if config.Provider != clouds.BYO {
+		acc, err := h.accountGetter.Get(r.Context(), req.CloudAccountName)
 
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			message.SendValidationFailed(w, fmt.Errorf("%s account not found", req.CloudAccountName))
+		if err != nil {
+			if sgerrors.IsNotFound(err) {
+				message.SendValidationFailed(w, fmt.Errorf("%s account not found", req.CloudAccountName))
+				return
+			}
+
+			message.SendUnknownError(w, err)
 			return
 		}
 
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	// Fill config with appropriate cloud account credentials
-	err = util.FillCloudAccountCredentials(acc, config)
+		// Fill config with appropriate cloud account credentials
+		err = util.FillCloudAccountCredentials(acc, config)
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		logrus.Error(errors.Wrap(err, "fill cloud account"))
-		return
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			logrus.Error(errors.Wrap(err, "fill cloud account"))
+			return
+		}
 	}
 
 	// Assign ID to profile
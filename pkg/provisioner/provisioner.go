@@ -71,6 +71,13 @@ func (b *bufferCloser) Close() error {
 // that have been provided for provisionCluster
 func (tp *TaskProvisioner) ProvisionCluster(parentContext context.Context,
 	clusterProfile *profile.Profile, config *steps.Config) (map[string][]*workflows.Task, error) {
+	// Nodes of the pools are provisioned along with the rest of the nodes,
+	// the profile of the caller is saved as it is, so they go to a copy
+	expanded := *clusterProfile
+	expanded.NodesProfiles = append(append([]profile.NodeProfile(nil), clusterProfile.NodesProfiles...),
+		clusterProfile.NodePools.NodeProfiles(clusterProfile.Provider)...)
+	clusterProfile = &expanded
+
 	if err := util.BootstrapKeys(config); err != nil {
 		return nil, errors.Wrap(err, "bootstrap keys")
 	}
@@ -147,8 +154,21 @@ func (tp *TaskProvisioner) ProvisionNodes(parentContext context.Context, nodePro
 		// Protect cloud API with rate limiter
 		tp.rateLimiter.Take()
 
-		// Take node workflow for the provider
-		t, err := workflows.NewTask(config, workflows.ProvisionNode, tp.repository)
+		// Profiles marked as master join the kube as masters
+		config.IsMaster = false
+		err := FillNodeCloudSpecificData(config.Provider, nodeProfile, config)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "fill node profile data to config")
+		}
+
+		// Take node or master workflow for the provider
+		workflow := workflows.NodeWorkflow(&config.Kube)
+		if config.IsMaster {
+			workflow = workflows.MasterWorkflow(&config.Kube)
+		}
+
+		t, err := workflows.NewTask(config, workflow, tp.repository)
 		if err != nil {
 			return nil, errors.Wrap(sgerrors.ErrNotFound, "workflow")
 		}
@@ -162,15 +182,9 @@ func (tp *TaskProvisioner) ProvisionNodes(parentContext context.Context, nodePro
 			return nil, errors.Wrap(err, "get writer")
 		}
 
-		err = FillNodeCloudSpecificData(config.Provider, nodeProfile, config)
-
-		if err != nil {
-			return nil, errors.Wrap(err, "fill node profile data to config")
-		}
-
 		// Put task id to config so that create instance step can use this id when generate node name
 		config.TaskID = t.ID
-		errChan := t.Run(ctx, *config, writer)
+		errChan := t.Run(ctx, config, writer)
 
 		go func(task *workflows.Task, cfg *steps.Config, errChan chan error) {
 			err = <-errChan
@@ -278,9 +292,11 @@ func (tp *TaskProvisioner) UpgradeCluster(parentCtx context.Context, nextVersion
 
 		logrus.Infof("Upgrade worker node %v", nodeTask.Config.Node)
 		tp.upgradeMachine(nodeTask, writer)
-		config.KubeStateChan() <- model.StateOperational
-		config.ConfigChan() <- config
 	}
+
+	// Kube gets the new version once all nodes are upgraded
+	config.KubeStateChan() <- model.StateOperational
+	config.ConfigChan() <- config
 }
 
 // provision do actual provisioning of master and worker nodes
@@ -390,18 +406,18 @@ func (tp *TaskProvisioner) prepare(config *steps.Config, masterCount, nodeCount
 
 	infraTask.Config = config
 	for i := 0; i < masterCount; i++ {
-		t, err := workflows.NewTask(config, workflows.ProvisionMaster, tp.repository)
+		t, err := workflows.NewTask(config, workflows.MasterWorkflow(&config.Kube), tp.repository)
 		if err != nil {
-			logrus.Errorf("Failed to set up task for %s workflow", workflows.ProvisionMaster)
+			logrus.Errorf("Failed to set up task for %s workflow", workflows.MasterWorkflow(&config.Kube))
 			continue
 		}
 		masterTasks = append(masterTasks, t)
 	}
 
 	for i := 0; i < nodeCount; i++ {
-		t, err := workflows.NewTask(config, workflows.ProvisionNode, tp.repository)
+		t, err := workflows.NewTask(config, workflows.NodeWorkflow(&config.Kube), tp.repository)
 		if err != nil {
-			logrus.Errorf("Failed to set up task for %s workflow", workflows.ProvisionNode)
+			logrus.Errorf("Failed to set up task for %s workflow", workflows.NodeWorkflow(&config.Kube))
 			continue
 		}
 		t.Config = config
@@ -438,7 +454,7 @@ func (tp *TaskProvisioner) preProvision(ctx context.Context, preProvisionTask *w
 		return err
 	}
 
-	result := preProvisionTask.Run(ctx, *config, out)
+	result := preProvisionTask.Run(ctx, config, out)
 	err = <-result
 	config.ConfigChan() <- preProvisionTask.Config
 
@@ -482,7 +498,7 @@ func (tp *TaskProvisioner) bootstrapMaster(ctx context.Context,
 	bootstrapTask.Config.IsBootstrap = true
 	bootstrapTask.Config.IsMaster = true
 
-	err = <-bootstrapTask.Run(ctx, *bootstrapTask.Config, out)
+	err = <-bootstrapTask.Run(ctx, bootstrapTask.Config, out)
 	rootConfig.ConfigChan() <- bootstrapTask.Config
 
 	if err != nil {
@@ -555,7 +571,7 @@ func (tp *TaskProvisioner) provisionMasters(ctx context.Context,
 			t.Config.IsMaster = true
 			t.Config.IsBootstrap = false
 
-			result := t.Run(ctx, *t.Config, out)
+			result := t.Run(ctx, t.Config, out)
 			err = <-result
 			errChan <- err
 
@@ -609,12 +625,14 @@ func (tp *TaskProvisioner) provisionNodes(ctx context.Context, profile *profile.
 
 		// Put task id to config so that create instance step can use this id when generate node name
 		nodeTask.Config.TaskID = nodeTask.ID
+		nodeTask.Config.IsMaster = false
+		nodeTask.Config.IsBootstrap = false
 
-		go func(t *workflows.Task) {
-			t.Config.IsMaster = false
-			t.Config.IsBootstrap = false
-			result := t.Run(ctx, *t.Config, out)
-			err = <-result
+		// Node tasks share config, run copies it before the next node fills it
+		result := nodeTask.Run(ctx, nodeTask.Config, out)
+
+		go func(t *workflows.Task, result chan error) {
+			err := <-result
 
 			if err != nil {
 				// Put node to error state
@@ -628,7 +646,7 @@ func (tp *TaskProvisioner) provisionNodes(ctx context.Context, profile *profile.
 			}
 
 			wg.Done()
-		}(nodeTask)
+		}(nodeTask, result)
 	}
 
 	wg.Wait()
@@ -656,7 +674,7 @@ func (tp *TaskProvisioner) waitCluster(ctx context.Context, clusterTask *workflo
 		return errors.New("No master found, cluster deployment failed")
 	}
 	clusterTask.Config = &cfg
-	result := clusterTask.Run(ctx, *clusterTask.Config, out)
+	result := clusterTask.Run(ctx, clusterTask.Config, out)
 	err = <-result
 
 	if err != nil {
@@ -693,7 +711,7 @@ func buildNodeProvisionScript(ctx context.Context, config *steps.Config) {
 	}
 
 	task.Config = &dryConfig
-	resultChan := task.Run(ctx, dryConfig, &bufferCloser{
+	resultChan := task.Run(ctx, &dryConfig, &bufferCloser{
 		Writer: &bytes.Buffer{},
 	})
 
@@ -863,7 +881,7 @@ func (tp *TaskProvisioner) upgradeMachine(task *workflows.Task, writer io.WriteC
 	task.Config.Node.State = model.MachineStateUpgrading
 	task.Config.NodeChan() <- task.Config.Node
 
-	resultChan := task.Run(context.Background(), *task.Config, writer)
+	resultChan := task.Run(context.Background(), task.Config, writer)
 	err := <-resultChan
 
 	if err != nil {
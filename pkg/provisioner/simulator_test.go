@@ -0,0 +1,518 @@
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/apply"
+	"github.com/supergiant/control/pkg/workflows/steps/authorizedkeys"
+	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
+	"github.com/supergiant/control/pkg/workflows/steps/certificates"
+	"github.com/supergiant/control/pkg/workflows/steps/cloudcontroller"
+	"github.com/supergiant/control/pkg/workflows/steps/clustercheck"
+	"github.com/supergiant/control/pkg/workflows/steps/cni"
+	"github.com/supergiant/control/pkg/workflows/steps/configmap"
+	"github.com/supergiant/control/pkg/workflows/steps/containerd"
+	"github.com/supergiant/control/pkg/workflows/steps/containerruntime"
+	"github.com/supergiant/control/pkg/workflows/steps/crio"
+	"github.com/supergiant/control/pkg/workflows/steps/docker"
+	"github.com/supergiant/control/pkg/workflows/steps/downloadk8sbinary"
+	"github.com/supergiant/control/pkg/workflows/steps/drain"
+	"github.com/supergiant/control/pkg/workflows/steps/evacuate"
+	"github.com/supergiant/control/pkg/workflows/steps/helm"
+	"github.com/supergiant/control/pkg/workflows/steps/httpproxy"
+	"github.com/supergiant/control/pkg/workflows/steps/install_app"
+	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
+	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
+	"github.com/supergiant/control/pkg/workflows/steps/masters"
+	"github.com/supergiant/control/pkg/workflows/steps/network"
+	"github.com/supergiant/control/pkg/workflows/steps/poststart"
+	"github.com/supergiant/control/pkg/workflows/steps/prometheus"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
+	"github.com/supergiant/control/pkg/workflows/steps/ssh"
+	"github.com/supergiant/control/pkg/workflows/steps/storageclass"
+	"github.com/supergiant/control/pkg/workflows/steps/tiller"
+	"github.com/supergiant/control/pkg/workflows/steps/uncordon"
+	"github.com/supergiant/control/pkg/workflows/steps/upgrade"
+)
+
+// kubeStore keeps copies of kubes so that monitor of the cluster state
+// and the test do not share maps of machines.
+type kubeStore struct {
+	lock sync.Mutex
+	data map[string][]byte
+}
+
+func (s *kubeStore) Create(ctx context.Context, k *model.Kube) error {
+	data, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data[k.ID] = data
+
+	return nil
+}
+
+func (s *kubeStore) Get(ctx context.Context, id string) (*model.Kube, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.data[id] == nil {
+		return nil, sgerrors.ErrNotFound
+	}
+
+	k := &model.Kube{}
+	return k, json.Unmarshal(s.data[id], k)
+}
+
+// wait returns kube as soon as condition is met.
+func (s *kubeStore) wait(t *testing.T, id string, condition func(*model.Kube) bool) *model.Kube {
+	timeout := time.After(time.Second * 30)
+
+	for {
+		k, err := s.Get(context.Background(), id)
+		if err == nil && condition(k) {
+			return k
+		}
+
+		select {
+		case <-time.After(time.Millisecond * 10):
+		case <-timeout:
+			t.Fatalf("kube %s: condition has not been met, last state %v error %v", id, k, err)
+		}
+	}
+}
+
+func allActive(machines map[string]*model.Machine, count int) bool {
+	if len(machines) != count {
+		return false
+	}
+
+	for _, m := range machines {
+		if m.State != model.MachineStateActive {
+			return false
+		}
+	}
+
+	return true
+}
+
+func setupSimulator(t *testing.T) (*TaskProvisioner, *kubeStore) {
+	if err := templatemanager.Init("../../templates"); err != nil {
+		t.Fatalf("init templates: %v", err)
+	}
+
+	apply.Init()
+	authorizedkeys.Init()
+	bootstraptoken.Init()
+	certificates.Init()
+	cloudcontroller.Init()
+	clustercheck.Init()
+	cni.Init()
+	configmap.Init()
+	containerd.Init()
+	containerruntime.Init()
+	crio.Init()
+	docker.Init()
+	downloadk8sbinary.Init()
+	drain.Init()
+	evacuate.Init()
+	helm.Init()
+	httpproxy.Init()
+	install_app.Init()
+	kubeadm.Init()
+	kubelet.Init()
+	masters.Init()
+	network.Init()
+	poststart.Init()
+	prometheus.Init()
+	ssh.Init()
+	storageclass.Init()
+	tiller.Init()
+	uncordon.Init()
+	upgrade.Init()
+	simulator.Init()
+
+	workflows.Init()
+
+	svc := &kubeStore{
+		data: make(map[string][]byte),
+	}
+
+	return &TaskProvisioner{
+		kubeService: svc,
+		repository:  memory.NewInMemoryRepository(),
+		getWriter: func(string) (io.WriteCloser, error) {
+			return &bufferCloser{ioutil.Discard, nil}, nil
+		},
+		rateLimiter: NewRateLimiter(time.Nanosecond),
+		cancelMap:   make(map[string]func()),
+	}, svc
+}
+
+func simulatorProfile() *profile.Profile {
+	return &profile.Profile{
+		Provider:         clouds.Simulator,
+		Region:           "sim-east-1",
+		K8SVersion:       "1.14.3",
+		K8SServicesCIDR:  "10.3.0.0/16",
+		K8SAPIPort:       443,
+		NetworkProvider:  "Flannel",
+		NetworkType:      "vxlan",
+		CIDR:             "10.0.0.0/16",
+		DockerVersion:    "18.06.3",
+		HelmVersion:      "2.11.0",
+		Arch:             "amd64",
+		OperatingSystem:  "linux",
+		UbuntuVersion:    "bionic",
+		RBACEnabled:      true,
+		MasterProfiles:   []profile.NodeProfile{{"size": "small"}, {"size": "small"}},
+		NodesProfiles:    []profile.NodeProfile{{"size": "medium"}},
+		ContainerRuntime: "docker",
+	}
+}
+
+// configFromKube builds config the way kube handlers do.
+func configFromKube(t *testing.T, p *profile.Profile, k *model.Kube) *steps.Config {
+	config, err := steps.NewConfigFromKube(p, k)
+	if err != nil {
+		t.Fatalf("new config: %v", err)
+	}
+
+	if err := util.LoadCloudSpecificDataFromKube(k, config); err != nil {
+		t.Fatalf("load cloud specific data: %v", err)
+	}
+
+	acc := &model.CloudAccount{
+		Provider:    clouds.Simulator,
+		Credentials: map[string]string{},
+	}
+	if err := util.FillCloudAccountCredentials(acc, config); err != nil {
+		t.Fatalf("fill cloud account credentials: %v", err)
+	}
+
+	return config
+}
+
+func runTask(t *testing.T, tp *TaskProvisioner, workflow string, config *steps.Config) {
+	task, err := workflows.NewTask(config, workflow, tp.repository)
+	if err != nil {
+		t.Fatalf("new %s task: %v", workflow, err)
+	}
+
+	if err := <-task.Run(context.Background(), config, &bufferCloser{ioutil.Discard, nil}); err != nil {
+		t.Fatalf("%s task: %v", workflow, err)
+	}
+}
+
+func TestSimulatorCluster(t *testing.T) {
+	tp, svc := setupSimulator(t)
+	p := simulatorProfile()
+
+	config, err := steps.NewConfig("sim", "simulator", *p)
+	if err != nil {
+		t.Fatalf("new config: %v", err)
+	}
+	config.Provider = clouds.Simulator
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := tp.ProvisionCluster(ctx, p, config); err != nil {
+		t.Fatalf("provision cluster: %v", err)
+	}
+
+	k := svc.wait(t, config.Kube.ID, func(k *model.Kube) bool {
+		return k.State == model.StateOperational || k.State == model.StateFailed
+	})
+	if k.State != model.StateOperational {
+		t.Fatalf("kube has failed %v", k)
+	}
+
+	k = svc.wait(t, k.ID, func(k *model.Kube) bool {
+		return allActive(k.Masters, 2) && allActive(k.Nodes, 1)
+	})
+
+	machines := simulator.Default.ListMachines(k.ID)
+	if len(machines) != 3 {
+		t.Fatalf("wrong machines of kube %v", machines)
+	}
+
+	if k.ExternalDNSName == "" || k.CloudSpec[clouds.SimulatorNetworkID] == "" {
+		t.Errorf("network has not been saved to kube %v", k.CloudSpec)
+	}
+
+	for _, m := range machines {
+		if output, _ := simulator.Default.Output(m.ID); !strings.Contains(output, "kubeadm") {
+			t.Errorf("machine %s has not been provisioned %q", m.Name, output)
+		}
+	}
+
+	// add node
+	config = configFromKube(t, p, k)
+	if _, err := tp.ProvisionNodes(ctx, []profile.NodeProfile{{"size": "large"}}, k, config); err != nil {
+		t.Fatalf("provision nodes: %v", err)
+	}
+
+	k = svc.wait(t, k.ID, func(k *model.Kube) bool {
+		return allActive(k.Nodes, 2)
+	})
+
+	// delete node
+	var node *model.Machine
+	for _, n := range k.Nodes {
+		if n.Size == "large" {
+			node = n
+		}
+	}
+
+	config = configFromKube(t, p, k)
+	config.Node = *node
+	config.DrainConfig.PrivateIP = node.PrivateIp
+	runTask(t, tp, workflows.DeleteNode, config)
+
+	if _, err := simulator.Default.GetMachine(node.ID); !sgerrors.IsNotFound(err) {
+		t.Errorf("machine %s has not been deleted %v", node.Name, err)
+	}
+
+	// upgrade
+	config = configFromKube(t, p, k)
+
+	tasks := map[string][]*workflows.Task{}
+	for role, machines := range map[string]map[string]*model.Machine{
+		workflows.MasterTask: k.Masters,
+		workflows.NodeTask:   k.Nodes,
+	} {
+		for _, m := range machines {
+			if m.ID == node.ID {
+				continue
+			}
+
+			task, err := workflows.NewTask(config, workflows.Upgrade, tp.repository)
+			if err != nil {
+				t.Fatalf("new upgrade task: %v", err)
+			}
+
+			task.Config = configFromKube(t, p, k)
+			task.Config.Kube.K8SVersion = "1.15.1"
+			task.Config.Node = *m
+			task.Config.IsMaster = role == workflows.MasterTask
+
+			tasks[role] = append(tasks[role], task)
+		}
+	}
+
+	tp.UpgradeCluster(ctx, "1.15.1", k, tasks, config)
+
+	k = svc.wait(t, k.ID, func(k *model.Kube) bool {
+		return k.State == model.StateOperational && allActive(k.Masters, 2)
+	})
+
+	for _, task := range tasks[workflows.NodeTask] {
+		if output, _ := simulator.Default.Output(task.Config.Node.ID); !strings.Contains(output, "1.15.1") {
+			t.Errorf("machine %s has not been upgraded", task.Config.Node.Name)
+		}
+	}
+
+	// delete cluster
+	runTask(t, tp, workflows.DeleteCluster, configFromKube(t, p, k))
+
+	if machines := simulator.Default.ListMachines(k.ID); len(machines) != 0 {
+		t.Errorf("machines of kube are left %v", machines)
+	}
+}
+
+func TestSimulatorClusterFailed(t *testing.T) {
+	tp, svc := setupSimulator(t)
+	p := simulatorProfile()
+
+	config, err := steps.NewConfig("failed", "simulator", *p)
+	if err != nil {
+		t.Fatalf("new config: %v", err)
+	}
+	config.Provider = clouds.Simulator
+
+	simulator.Default.Fail(sgerrors.ErrTimeoutExceeded)
+	defer simulator.Default.Fail(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := tp.ProvisionCluster(ctx, p, config); err != nil {
+		t.Fatalf("provision cluster: %v", err)
+	}
+
+	k := svc.wait(t, config.Kube.ID, func(k *model.Kube) bool {
+		return k.State == model.StateFailed
+	})
+
+	for _, m := range k.Masters {
+		if m.State == model.MachineStateActive {
+			t.Errorf("machine %s must not be active", m.Name)
+		}
+	}
+}
+
+func TestSimulatorNodePool(t *testing.T) {
+	tp, svc := setupSimulator(t)
+	p := simulatorProfile()
+	p.NodePools = profile.NodePools{
+		{
+			Name:   "batch",
+			Size:   "large",
+			Count:  2,
+			Labels: map[string]string{"workload": "batch"},
+			Taints: []profile.Taint{{Key: "dedicated", Value: "batch", Effect: profile.TaintNoSchedule}},
+		},
+	}
+
+	config, err := steps.NewConfig("pools", "simulator", *p)
+	if err != nil {
+		t.Fatalf("new config: %v", err)
+	}
+	config.Provider = clouds.Simulator
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := tp.ProvisionCluster(ctx, p, config); err != nil {
+		t.Fatalf("provision cluster: %v", err)
+	}
+
+	// Nodes of the pool are kept in the pool of the saved profile only
+	if len(p.NodesProfiles) != 1 {
+		t.Errorf("pool nodes are added to node profiles %v", p.NodesProfiles)
+	}
+
+	k := svc.wait(t, config.Kube.ID, func(k *model.Kube) bool {
+		return k.State == model.StateOperational && allActive(k.Nodes, 3)
+	})
+
+	// scale the pool up the way the pools handler does
+	config = configFromKube(t, p, k)
+	nodeProfiles := k.NodePools["batch"].NodeProfiles(clouds.Simulator, nil, 1)
+	if _, err := tp.ProvisionNodes(ctx, nodeProfiles, k, config); err != nil {
+		t.Fatalf("provision nodes: %v", err)
+	}
+
+	k = svc.wait(t, k.ID, func(k *model.Kube) bool {
+		return allActive(k.Nodes, 4)
+	})
+
+	pooled := 0
+	for _, n := range k.Nodes {
+		output, _ := simulator.Default.Output(n.ID)
+		labeled := strings.Contains(output, "node-labels: \"supergiant.io/node-pool=batch,workload=batch\"") &&
+			strings.Contains(output, "effect: NoSchedule")
+
+		if n.Pool == "batch" {
+			pooled++
+		}
+
+		if (n.Pool == "batch") != labeled || (n.Pool == "batch") != (n.Size == "large") {
+			t.Errorf("wrong node %s of pool %q labeled %v", n.Name, n.Pool, labeled)
+		}
+	}
+
+	if pooled != 3 {
+		t.Errorf("expected 3 nodes of the pool actual %d", pooled)
+	}
+
+	runTask(t, tp, workflows.DeleteCluster, configFromKube(t, p, k))
+}
+
+func TestSimulatorMasters(t *testing.T) {
+	tp, svc := setupSimulator(t)
+	p := simulatorProfile()
+
+	config, err := steps.NewConfig("masters", "simulator", *p)
+	if err != nil {
+		t.Fatalf("new config: %v", err)
+	}
+	config.Provider = clouds.Simulator
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := tp.ProvisionCluster(ctx, p, config); err != nil {
+		t.Fatalf("provision cluster: %v", err)
+	}
+
+	k := svc.wait(t, config.Kube.ID, func(k *model.Kube) bool {
+		return k.State == model.StateOperational && allActive(k.Masters, 2) && allActive(k.Nodes, 1)
+	})
+
+	// add master the way the masters handler does
+	k.Auth.CertificateKey = "fresh-key"
+	if err := svc.Create(ctx, k); err != nil {
+		t.Fatalf("save kube: %v", err)
+	}
+	config = configFromKube(t, p, k)
+	config.UploadCerts = true
+	if _, err := tp.ProvisionNodes(ctx, []profile.NodeProfile{{"size": "small", "isMaster": "true"}}, k, config); err != nil {
+		t.Fatalf("provision masters: %v", err)
+	}
+
+	k = svc.wait(t, k.ID, func(k *model.Kube) bool {
+		return allActive(k.Masters, 3)
+	})
+
+	uploaded := 0
+	for _, m := range k.Masters {
+		output, _ := simulator.Default.Output(m.ID)
+		if strings.Contains(output, "--certificate-key fresh-key") {
+			uploaded++
+		}
+	}
+	if uploaded != 1 {
+		t.Errorf("certificates must be uploaded on one master, uploaded on %d", uploaded)
+	}
+
+	// delete master the way the machines handler does
+	var master *model.Machine
+	for _, m := range k.Masters {
+		if master == nil || m.Name > master.Name {
+			master = m
+		}
+	}
+	master.State = model.MachineStateDeleting
+
+	config = configFromKube(t, p, k)
+	config.Node = *master
+	config.IsMaster = true
+	config.DrainConfig.PrivateIP = master.PrivateIp
+	runTask(t, tp, workflows.DeleteMaster, config)
+
+	if _, err := simulator.Default.GetMachine(master.ID); !sgerrors.IsNotFound(err) {
+		t.Errorf("machine %s has not been deleted %v", master.Name, err)
+	}
+
+	removed := false
+	for _, m := range k.Masters {
+		output, _ := simulator.Default.Output(m.ID)
+		removed = removed || strings.Contains(output, "https://"+master.PrivateIp+":2380")
+	}
+	if !removed {
+		t.Errorf("etcd member of %s has not been removed", master.Name)
+	}
+
+	runTask(t, tp, workflows.DeleteCluster, configFromKube(t, p, k))
+}
@@ -39,6 +39,16 @@ func FillNodeCloudSpecificData(provider clouds.Name, nodeProfile profile.NodePro
 		config.IsMaster, _ = strconv.ParseBool(nodeProfile["isMaster"])
 	}
 
+	// Labels and taints of the pool are applied when the node joins
+	config.NodePool = profile.NodePool{}
+	if name := nodeProfile[profile.NodePoolKey]; name != "" {
+		pool, ok := config.Kube.NodePools[name]
+		if !ok {
+			return errors.Wrapf(sgerrors.ErrNotFound, "node pool %s", name)
+		}
+		config.NodePool = pool
+	}
+
 	switch provider {
 	case clouds.AWS:
 		return util.BindParams(nodeProfile, &config.AWSConfig)
@@ -48,10 +58,17 @@ func FillNodeCloudSpecificData(provider clouds.Name, nodeProfile profile.NodePro
 		return util.BindParams(nodeProfile, &config.DigitalOceanConfig)
 	case clouds.Packet:
 		return util.BindParams(nodeProfile, &config.PacketConfig)
+	case clouds.Simulator:
+		return util.BindParams(nodeProfile, &config.SimulatorConfig)
 	case clouds.OpenStack:
 		return util.BindParams(nodeProfile, &config.OSConfig)
 	case clouds.Azure:
 		return util.BindParams(nodeProfile, &config.AzureConfig)
+	case clouds.BYO:
+		// Nothing is shared among hosts, credentials of one host
+		// must not be left for the next one
+		config.BYOConfig = steps.BYOConfig{}
+		return util.BindParams(nodeProfile, &config.BYOConfig)
 	default:
 		return sgerrors.ErrUnknownProvider
 	}
@@ -98,9 +115,43 @@ func MergeConfig(source *steps.Config, destination *steps.Config) error {
 			return errors.Wrapf(err, "Merge config")
 		}
 	case clouds.Packet:
-		return nil
+		data, err := json.Marshal(&source.PacketConfig)
+
+		if err != nil {
+			return errors.Wrapf(err, "merge config marshall config1")
+		}
+
+		err = json.Unmarshal(data, &destination.PacketConfig)
+
+		if err != nil {
+			return errors.Wrapf(err, "Merge config")
+		}
+	case clouds.Simulator:
+		data, err := json.Marshal(&source.SimulatorConfig)
+
+		if err != nil {
+			return errors.Wrapf(err, "merge config marshall config1")
+		}
+
+		err = json.Unmarshal(data, &destination.SimulatorConfig)
+
+		if err != nil {
+			return errors.Wrapf(err, "Merge config")
+		}
 	case clouds.OpenStack:
-		return nil
+		data, err := json.Marshal(&source.OSConfig)
+
+		if err != nil {
+			return errors.Wrapf(err, "merge config marshall config1")
+		}
+
+		err = json.Unmarshal(data, &destination.OSConfig)
+
+		if err != nil {
+			return errors.Wrapf(err, "Merge config")
+		}
+	case clouds.BYO:
+		// hosts have nothing in common but the shared items
 	case clouds.Azure:
 		data, err := json.Marshal(&source.AzureConfig)
 
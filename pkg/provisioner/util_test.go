@@ -6,6 +6,7 @@ import (
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -88,3 +89,67 @@ func TestGrabTaskIds(t *testing.T) {
 			len(masterTasks)+len(nodeTasks)+1, len(taskIds))
 	}
 }
+
+func TestFillNodeCloudSpecificDataNodePool(t *testing.T) {
+	cfg := &steps.Config{}
+	cfg.Kube.NodePools = map[string]profile.NodePool{
+		"gpu": {Name: "gpu", Size: "p2.xlarge"},
+	}
+
+	err := FillNodeCloudSpecificData(clouds.AWS, profile.NodeProfile{
+		profile.NodePoolKey: "gpu",
+		profile.SizeKey:     "p2.xlarge",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if cfg.NodePool.Name != "gpu" || cfg.AWSConfig.InstanceType != "p2.xlarge" {
+		t.Errorf("wrong node pool %v instance type %s", cfg.NodePool, cfg.AWSConfig.InstanceType)
+	}
+
+	// pool of the previous node must not be used for the next one
+	if err = FillNodeCloudSpecificData(clouds.AWS, profile.NodeProfile{}, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if cfg.NodePool.Name != "" {
+		t.Errorf("unexpected node pool %v", cfg.NodePool)
+	}
+
+	err = FillNodeCloudSpecificData(clouds.AWS, profile.NodeProfile{
+		profile.NodePoolKey: "unknown",
+	}, cfg)
+	if !sgerrors.IsNotFound(err) {
+		t.Errorf("expected not found error actual %v", err)
+	}
+}
+
+func TestFillNodeCloudSpecificDataBYO(t *testing.T) {
+	cfg := &steps.Config{}
+
+	err := FillNodeCloudSpecificData(clouds.BYO, profile.NodeProfile{
+		clouds.BYOPublicIP: "1.2.3.4",
+		clouds.BYOSSHUser:  "ubuntu",
+		clouds.BYOSSHKey:   "key",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if cfg.BYOConfig.PublicIP != "1.2.3.4" || cfg.BYOConfig.SSHUser != "ubuntu" || cfg.BYOConfig.SSHKey != "key" {
+		t.Errorf("wrong host %v", cfg.BYOConfig)
+	}
+
+	// credentials of the previous host must not be used for the next one
+	err = FillNodeCloudSpecificData(clouds.BYO, profile.NodeProfile{
+		clouds.BYOPrivateIP: "10.0.0.5",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if cfg.BYOConfig != (steps.BYOConfig{PrivateIP: "10.0.0.5"}) {
+		t.Errorf("wrong host %v", cfg.BYOConfig)
+	}
+}
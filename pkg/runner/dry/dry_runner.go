@@ -2,6 +2,11 @@ package dry
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
 	"github.com/supergiant/control/pkg/runner"
 )
 
@@ -22,6 +27,45 @@ func (r *DryRunner) Run(cmd *runner.Command) (err error) {
 	return nil
 }
 
+// Upload writes script that creates the file with the content, heredoc
+// is left unquoted so shell expressions in the content get evaluated
+// when script is executed on the machine.
+func (r *DryRunner) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	if err := dst.Validate(); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+
+	fmt.Fprintf(r.output, "\nsudo mkdir -p %s\n", runner.Quote(dst.Dir()))
+	fmt.Fprintf(r.output, "sudo bash -c \"cat > %s\" <<EOF\n%sEOF\n", runner.Quote(dst.Path), data)
+	fmt.Fprintf(r.output, "sudo chmod %s %s\n", dst.Perm(), runner.Quote(dst.Path))
+
+	if dst.Owner != "" {
+		fmt.Fprintf(r.output, "sudo chown %s %s\n", runner.Quote(dst.Owner), runner.Quote(dst.Path))
+	}
+
+	return nil
+}
+
+// Download writes command that prints the file, there is nothing to copy
+// in dry run so out is left untouched.
+func (r *DryRunner) Download(ctx context.Context, src string, out io.Writer) error {
+	if src == "" {
+		return runner.ErrEmptyPath
+	}
+
+	fmt.Fprintf(r.output, "\nsudo cat %s\n", runner.Quote(src))
+	return nil
+}
+
 func (r *DryRunner) GetOutput() string {
 	return r.output.String()
 }
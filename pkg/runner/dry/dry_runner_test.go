@@ -0,0 +1,57 @@
+package dry
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+func TestDryRunnerUpload(t *testing.T) {
+	r := NewDryRunner()
+
+	err := r.Upload(context.Background(), strings.NewReader("node-ip: $(hostname -i)"),
+		runner.File{Path: "/etc/supergiant/kubeadm.conf", Mode: 0600, Owner: "root:root"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	out := r.GetOutput()
+	for _, expected := range []string{
+		"sudo mkdir -p '/etc/supergiant'",
+		"sudo bash -c \"cat > '/etc/supergiant/kubeadm.conf'\" <<EOF\nnode-ip: $(hostname -i)\nEOF",
+		"sudo chmod 0600 '/etc/supergiant/kubeadm.conf'",
+		"sudo chown 'root:root' '/etc/supergiant/kubeadm.conf'",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %s", expected, out)
+		}
+	}
+}
+
+func TestDryRunnerUploadEmptyPath(t *testing.T) {
+	r := NewDryRunner()
+
+	if err := r.Upload(context.Background(), strings.NewReader(""), runner.File{}); err != runner.ErrEmptyPath {
+		t.Errorf("expected error %v actual %v", runner.ErrEmptyPath, err)
+	}
+}
+
+func TestDryRunnerDownload(t *testing.T) {
+	r := NewDryRunner()
+	out := &bytes.Buffer{}
+
+	if err := r.Download(context.Background(), "/etc/kubernetes/pki/ca.crt", out); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("dry run must not write file content, actual %s", out.String())
+	}
+
+	if !strings.Contains(r.GetOutput(), "sudo cat '/etc/kubernetes/pki/ca.crt'") {
+		t.Errorf("wrong output %s", r.GetOutput())
+	}
+}
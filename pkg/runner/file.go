@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrEmptyPath = errors.New("empty file path")
+)
+
+// File is a destination of the upload along with attributes it gets
+// once content is copied.
+type File struct {
+	Path string
+	Mode os.FileMode
+	// Owner is user[:group] that file is chowned to, empty
+	// value leaves file owned by the user of runner.
+	Owner string
+}
+
+// Validate checks that file can be copied.
+func (f File) Validate() error {
+	if strings.TrimSpace(f.Path) == "" {
+		return ErrEmptyPath
+	}
+
+	return nil
+}
+
+// Dir returns directory that contains the file.
+func (f File) Dir() string {
+	return path.Dir(f.Path)
+}
+
+// Perm returns permission bits of the file in octal notation, files
+// with no mode set are readable by everyone and writable by the owner.
+func (f File) Perm() string {
+	if f.Mode == 0 {
+		return "0644"
+	}
+
+	return fmt.Sprintf("%04o", f.Mode.Perm())
+}
+
+// Quote makes the string safe to be used as a single shell word.
+func Quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
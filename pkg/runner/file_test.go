@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFilePerm(t *testing.T) {
+	testCases := []struct {
+		mode     os.FileMode
+		expected string
+	}{
+		{0, "0644"},
+		{0600, "0600"},
+		{0755 | os.ModeDir, "0755"},
+	}
+
+	for _, testCase := range testCases {
+		f := File{Path: "/etc/file", Mode: testCase.mode}
+
+		if perm := f.Perm(); perm != testCase.expected {
+			t.Errorf("wrong permissions for mode %v expected %s actual %s",
+				testCase.mode, testCase.expected, perm)
+		}
+	}
+}
+
+func TestFileValidate(t *testing.T) {
+	if err := (File{Path: " "}).Validate(); err != ErrEmptyPath {
+		t.Errorf("expected error %v actual %v", ErrEmptyPath, err)
+	}
+
+	if err := (File{Path: "/etc/file"}).Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	testCases := []struct {
+		in       string
+		expected string
+	}{
+		{"/etc/kubernetes/pki/ca.key", `'/etc/kubernetes/pki/ca.key'`},
+		{"/tmp/it's", `'/tmp/it'"'"'s'`},
+	}
+
+	for _, testCase := range testCases {
+		if out := Quote(testCase.in); out != testCase.expected {
+			t.Errorf("wrong quoting of %s expected %s actual %s",
+				testCase.in, testCase.expected, out)
+		}
+	}
+}
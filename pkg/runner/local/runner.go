@@ -0,0 +1,104 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+// Runner executes commands and copies files on the machine where
+// control runs.
+type Runner struct{}
+
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+func (r *Runner) Run(cmd *runner.Command) error {
+	if cmd == nil {
+		return errors.New("nil command")
+	}
+
+	if cmd.Ctx == nil {
+		return runner.ErrNilContext
+	}
+
+	c := exec.CommandContext(cmd.Ctx, "bash", "-c", cmd.Script)
+	c.Stdout = cmd.Out
+	c.Stderr = cmd.Err
+
+	return c.Run()
+}
+
+// Upload writes content to the file, owner is changed with chown
+// so it accepts the same user[:group] notation as other runners.
+func (r *Runner) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	if err := dst.Validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dst.Dir(), 0755); err != nil {
+		return errors.Wrapf(err, "create directory %s", dst.Dir())
+	}
+
+	mode := dst.Mode.Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+
+	f, err := os.OpenFile(dst.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return errors.Wrapf(err, "open %s", dst.Path)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, content); err != nil {
+		return errors.Wrapf(err, "write %s", dst.Path)
+	}
+
+	// Mode of existing file is not changed by open
+	if err := f.Chmod(mode); err != nil {
+		return errors.Wrapf(err, "chmod %s", dst.Path)
+	}
+
+	if dst.Owner != "" {
+		stderr := &bytes.Buffer{}
+		c := exec.CommandContext(ctx, "chown", dst.Owner, dst.Path)
+		c.Stdout = ioutil.Discard
+		c.Stderr = stderr
+
+		if err := c.Run(); err != nil {
+			return errors.Wrapf(err, "chown %s: %s", dst.Path,
+				strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	return f.Close()
+}
+
+// Download copies content of the file to out.
+func (r *Runner) Download(ctx context.Context, src string, out io.Writer) error {
+	if strings.TrimSpace(src) == "" {
+		return runner.ErrEmptyPath
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "open %s", src)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(out, f); err != nil {
+		return errors.Wrapf(err, "read %s", src)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+func TestRunnerRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd, _ := runner.NewCommand(context.Background(), "echo hello", out, &bytes.Buffer{})
+
+	if err := NewRunner().Run(cmd); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if out.String() != "hello\n" {
+		t.Errorf("wrong output %q", out.String())
+	}
+}
+
+func TestRunnerUploadDownload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-runner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewRunner()
+	dst := runner.File{
+		Path: filepath.Join(dir, "pki", "ca.key"),
+		Mode: 0600,
+	}
+
+	if err := r.Upload(context.Background(), strings.NewReader("key"), dst); err != nil {
+		t.Fatalf("upload %v", err)
+	}
+
+	info, err := os.Stat(dst.Path)
+	if err != nil {
+		t.Fatalf("stat %v", err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("wrong mode expected %v actual %v", os.FileMode(0600), info.Mode().Perm())
+	}
+
+	out := &bytes.Buffer{}
+	if err := r.Download(context.Background(), dst.Path, out); err != nil {
+		t.Fatalf("download %v", err)
+	}
+
+	if out.String() != "key" {
+		t.Errorf("wrong content expected %s actual %s", "key", out.String())
+	}
+}
+
+func TestRunnerDownloadNotFound(t *testing.T) {
+	err := NewRunner().Download(context.Background(), "/not/found", &bytes.Buffer{})
+
+	if err == nil {
+		t.Error("error must not be nil")
+	}
+}
@@ -1,6 +1,15 @@
 package runner
 
+import (
+	"context"
+	"io"
+)
+
 // Runner is interface for running command in different environment
 type Runner interface {
 	Run(command *Command) error
+	// Upload copies content to the file in runner environment
+	Upload(ctx context.Context, content io.Reader, dst File) error
+	// Download writes content of the file in runner environment to out
+	Download(ctx context.Context, src string, out io.Writer) error
 }
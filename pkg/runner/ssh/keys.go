@@ -0,0 +1,57 @@
+package ssh
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+// oidEd25519 identifies ed25519 keys, RFC 8410.
+var oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+
+// pkcs8 is PrivateKeyInfo of RFC 5208, x509 package of go 1.12
+// doesn't parse ed25519 keys, so they are parsed here.
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// ParsePrivateKey returns signer for PEM encoded private key. Along with
+// RSA, ECDSA and OpenSSH formats supported by ssh package it accepts
+// ed25519 keys in PKCS#8 form as written by openssl.
+func ParsePrivateKey(pemBytes []byte) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(pemBytes)
+	if err == nil {
+		return signer, nil
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, err
+	}
+
+	var info pkcs8
+	if _, pkcsErr := asn1.Unmarshal(block.Bytes, &info); pkcsErr != nil {
+		return nil, err
+	}
+
+	if !info.Algo.Algorithm.Equal(oidEd25519) {
+		return nil, err
+	}
+
+	// Private key is the seed wrapped in one more octet string
+	var seed []byte
+	if _, seedErr := asn1.Unmarshal(info.PrivateKey, &seed); seedErr != nil {
+		return nil, err
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, err
+	}
+
+	return ssh.NewSignerFromKey(ed25519.NewKeyFromSeed(seed))
+}
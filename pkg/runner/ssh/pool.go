@@ -0,0 +1,106 @@
+package ssh
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool keeps a single authenticated ssh client per user and host, so
+// consecutive commands of a task reuse the same connection instead of
+// dialing the machine over and over again.
+type Pool struct {
+	m       sync.Mutex
+	clients map[string]*connection
+	// dials are connections being established, callers of the same
+	// key wait for the dial instead of dialing the host again.
+	dials map[string]*dialCall
+}
+
+type dialCall struct {
+	done chan struct{}
+	c    *connection
+	err  error
+}
+
+func NewPool() *Pool {
+	return &Pool{
+		clients: make(map[string]*connection),
+		dials:   make(map[string]*dialCall),
+	}
+}
+
+// client returns connection for the key from the pool or dials a new one,
+// the pool is not locked while dialing so unreachable hosts don't block
+// connections to other hosts.
+func (p *Pool) client(ctx context.Context, key string, dial func(context.Context) (*connection, error)) (*connection, error) {
+	p.m.Lock()
+	if c := p.clients[key]; c != nil {
+		p.m.Unlock()
+		return c, nil
+	}
+
+	if call := p.dials[key]; call != nil {
+		p.m.Unlock()
+
+		select {
+		case <-call.done:
+			return call.c, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &dialCall{done: make(chan struct{})}
+	p.dials[key] = call
+	p.m.Unlock()
+
+	call.c, call.err = dial(ctx)
+
+	p.m.Lock()
+	delete(p.dials, key)
+	if call.err == nil {
+		p.clients[key] = call.c
+	}
+	p.m.Unlock()
+	close(call.done)
+
+	return call.c, call.err
+}
+
+// evict closes the connection and removes it from the pool, connection
+// will be established again on the next call of client.
+func (p *Pool) evict(key string, c *connection) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	// Client may have been already replaced by someone else
+	if p.clients[key] == c {
+		delete(p.clients, key)
+	}
+	c.Close()
+}
+
+// Close closes all connections kept by the pool, pool stays usable
+// and dials new connections on demand.
+func (p *Pool) Close() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	var err error
+	for key, c := range p.clients {
+		if closeErr := c.Close(); closeErr != nil {
+			err = closeErr
+		}
+		delete(p.clients, key)
+	}
+
+	return err
+}
+
+// Len returns amount of connections kept by the pool.
+func (p *Pool) Len() int {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	return len(p.clients)
+}
@@ -0,0 +1,174 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+func TestPooledRunnerReusesConnection(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	host, port := srv.hostPort()
+	pool := NewPool()
+	defer pool.Close()
+
+	cfg := Config{
+		Host:    host,
+		Port:    port,
+		User:    "root",
+		Timeout: 1,
+		Key:     []byte(testPrivateKey),
+	}
+
+	for i := 0; i < 3; i++ {
+		// Each step of the task creates its own runner
+		r, err := NewPooledRunner(cfg, pool)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		out := &bytes.Buffer{}
+		cmd, _ := runner.NewCommand(context.Background(), "echo hello", out, &bytes.Buffer{})
+
+		if err := r.Run(cmd); err != nil {
+			t.Fatalf("run command %v", err)
+		}
+
+		if out.String() != "echo hello" {
+			t.Errorf("wrong output expected %s actual %s", "echo hello", out.String())
+		}
+	}
+
+	if srv.acceptedCount() != 1 {
+		t.Errorf("expected single connection actual %d", srv.acceptedCount())
+	}
+
+	if pool.Len() != 1 {
+		t.Errorf("expected single client in pool actual %d", pool.Len())
+	}
+}
+
+func TestPooledRunnerReconnect(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	host, port := srv.hostPort()
+	pool := NewPool()
+	defer pool.Close()
+
+	r, err := NewPooledRunner(Config{
+		Host:    host,
+		Port:    port,
+		User:    "root",
+		Timeout: 1,
+		Key:     []byte(testPrivateKey),
+	}, pool)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	cmd, _ := runner.NewCommand(context.Background(), "uptime", out, &bytes.Buffer{})
+
+	if err := r.Run(cmd); err != nil {
+		t.Fatalf("run command %v", err)
+	}
+
+	srv.dropConnections()
+
+	if err := r.Run(cmd); err != nil {
+		t.Fatalf("run command after connection has been dropped %v", err)
+	}
+
+	if srv.acceptedCount() != 2 {
+		t.Errorf("expected two connections actual %d", srv.acceptedCount())
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	host, port := srv.hostPort()
+	pool := NewPool()
+
+	r, err := NewPooledRunner(Config{
+		Host:    host,
+		Port:    port,
+		User:    "root",
+		Timeout: 1,
+		Key:     []byte(testPrivateKey),
+	}, pool)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	cmd, _ := runner.NewCommand(context.Background(), "uptime", out, &bytes.Buffer{})
+
+	if err := r.Run(cmd); err != nil {
+		t.Fatalf("run command %v", err)
+	}
+
+	pool.Close()
+
+	if pool.Len() != 0 {
+		t.Errorf("pool must be empty after close, actual %d", pool.Len())
+	}
+}
+
+func TestPoolDialsOutsideLock(t *testing.T) {
+	pool := NewPool()
+	blocked := make(chan struct{})
+	dials := make(chan struct{}, 2)
+
+	slowDial := func(context.Context) (*connection, error) {
+		dials <- struct{}{}
+		<-blocked
+		return nil, errors.New("unreachable")
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := pool.client(context.Background(), "root@unreachable", slowDial)
+		errs <- err
+	}()
+	<-dials
+
+	// Callers wait for the dial in flight instead of dialing again
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pool.client(ctx, "root@unreachable", slowDial); err != context.Canceled {
+		t.Errorf("wrong error of the waiting caller %v", err)
+	}
+
+	// Other hosts are dialed while the unreachable one is retried
+	done := make(chan error)
+	go func() {
+		_, err := pool.client(context.Background(), "root@other", func(context.Context) (*connection, error) {
+			return nil, errors.New("refused")
+		})
+		done <- err
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dial of other host is blocked by the unreachable one")
+	}
+
+	close(blocked)
+	if err := <-errs; err == nil {
+		t.Error("dial error expected")
+	}
+
+	if len(dials) != 0 {
+		t.Errorf("unreachable host must be dialed once, dialed %d more times", len(dials))
+	}
+}
@@ -2,10 +2,12 @@ package ssh
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
 
 	"github.com/supergiant/control/pkg/runner"
@@ -22,6 +24,19 @@ type Config struct {
 	User    string `json:"user"`
 	Timeout int    `json:"timeout"`
 	Key     []byte `json:"key"`
+
+	// JumpHosts are bastions that connection to the host is tunneled
+	// through, they are dialed in the order they are listed.
+	JumpHosts []JumpHost `json:"jumpHosts"`
+}
+
+// JumpHost is a bastion host, empty user and key are taken from the
+// config of the target host.
+type JumpHost struct {
+	Host string `json:"host"`
+	Port string `json:"port"`
+	User string `json:"user"`
+	Key  []byte `json:"key"`
 }
 
 // Runner is implementation of runner interface for ssh
@@ -29,6 +44,8 @@ type Runner struct {
 	host    string
 	port    string
 	sshConf *ssh.ClientConfig
+	jumps   []hop
+	pool    *Pool
 }
 
 // NewRunner creates ssh runner object. It requires two io.Writer
@@ -48,9 +65,39 @@ func NewRunner(config Config) (runner.Runner, error) {
 		r.port = DefaultPort
 	}
 
+	for _, jumpHost := range config.JumpHosts {
+		h, err := jumpHop(config, jumpHost)
+		if err != nil {
+			return nil, errors.Wrapf(err, "jump host %s", jumpHost.Host)
+		}
+		r.jumps = append(r.jumps, h)
+	}
+
 	return r, nil
 }
 
+// NewPooledRunner creates ssh runner that takes connections from the pool,
+// so all runners that share the pool reuse a single connection per host.
+func NewPooledRunner(config Config, pool *Pool) (runner.Runner, error) {
+	r, err := NewRunner(config)
+	if err != nil {
+		return nil, err
+	}
+
+	r.(*Runner).pool = pool
+	return r, nil
+}
+
+// RunnerFor creates runner that takes connections from the pool,
+// runners made without pool dial a connection per session.
+func RunnerFor(config Config, pool *Pool) (runner.Runner, error) {
+	if pool == nil {
+		return NewRunner(config)
+	}
+
+	return NewPooledRunner(config, pool)
+}
+
 //TODO(stgleb): Add  more context like env variables?
 // Run executes a single command on ssh session.
 //
@@ -62,17 +109,11 @@ func (r *Runner) Run(cmd *runner.Command) (err error) {
 		return nil
 	}
 
-	c, err := connectionWithBackOff(cmd.Ctx, r.host, r.port, r.sshConf,
-		time.Second*10, 5)
-
-	if err != nil {
-		return errors.Wrap(err, "ssh: establishing connection")
-	}
-
-	session, err := c.NewSession()
+	session, release, err := r.newSession(cmd.Ctx)
 	if err != nil {
-		return errors.Wrap(err, "ssh: creating new session")
+		return err
 	}
+	defer release()
 	defer session.Close()
 
 	session.Stdout = cmd.Out
@@ -97,3 +138,92 @@ func (r *Runner) Run(cmd *runner.Command) (err error) {
 	// We can close session multiple times
 	return session.Close()
 }
+
+// newSession opens session on the connection to the host, release func
+// must be called once session is not needed anymore.
+func (r *Runner) newSession(ctx context.Context) (*ssh.Session, func(), error) {
+	if r.pool == nil {
+		c, err := r.dial(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "ssh: establishing connection")
+		}
+
+		session, err := c.NewSession()
+		if err != nil {
+			c.Close()
+			return nil, nil, errors.Wrap(err, "ssh: creating new session")
+		}
+
+		// Connection is not shared, close it along with the session
+		return session, func() { c.Close() }, nil
+	}
+
+	key := r.poolKey()
+	// Pooled connection may have been dropped since the last command,
+	// in that case evict it and try once again with a fresh one.
+	for attempt := 0; ; attempt++ {
+		c, err := r.pool.client(ctx, key, r.dial)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "ssh: establishing connection")
+		}
+
+		session, err := c.NewSession()
+		if err == nil {
+			return session, func() {}, nil
+		}
+
+		r.pool.evict(key, c)
+		if attempt > 0 {
+			return nil, nil, errors.Wrap(err, "ssh: creating new session")
+		}
+		logrus.Debugf("ssh: connection to %s:%s has been dropped, reconnect: %v",
+			r.host, r.port, err)
+	}
+}
+
+// dial connects to the host directly or through the chain of jump hosts.
+func (r *Runner) dial(ctx context.Context) (*connection, error) {
+	target := hop{host: r.host, port: r.port, conf: r.sshConf}
+
+	if len(r.jumps) == 0 {
+		c, err := connectionWithBackOff(ctx, target.host, target.port, target.conf,
+			time.Second*10, 5)
+		if err != nil {
+			return nil, err
+		}
+
+		return &connection{Client: c}, nil
+	}
+
+	first := r.jumps[0]
+	c, err := connectionWithBackOff(ctx, first.host, first.port, first.conf,
+		time.Second*10, 5)
+	if err != nil {
+		return nil, errors.Wrapf(err, "jump host %s", first.addr())
+	}
+
+	conn := &connection{Client: c}
+	for _, next := range append(r.jumps[1:], target) {
+		c, err := tunnelWithBackOff(ctx, conn.Client, next,
+			time.Second*10, 5)
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "tunnel to %s", next.addr())
+		}
+
+		conn.jumps = append(conn.jumps, conn.Client)
+		conn.Client = c
+	}
+
+	return conn, nil
+}
+
+func (r *Runner) poolKey() string {
+	key := fmt.Sprintf("%s@%s:%s", r.sshConf.User, r.host, r.port)
+
+	for _, jump := range r.jumps {
+		key = fmt.Sprintf("%s via %s@%s", key, jump.conf.User, jump.addr())
+	}
+
+	return key
+}
@@ -1,6 +1,8 @@
 package ssh
 
 import (
+	"bytes"
+	"context"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -59,3 +61,54 @@ func TestRunner_New(t *testing.T) {
 		}
 	}
 }
+
+func TestRunnerJumpHosts(t *testing.T) {
+	target := newTestServer(t)
+	defer target.Close()
+
+	bastion := newTestServer(t)
+	defer bastion.Close()
+
+	host, port := target.hostPort()
+	jumpHost, jumpPort := bastion.hostPort()
+
+	r, err := NewRunner(Config{
+		Host:    host,
+		Port:    port,
+		User:    "root",
+		Timeout: 1,
+		Key:     []byte(testPrivateKey),
+		JumpHosts: []JumpHost{
+			{
+				Host: jumpHost,
+				Port: jumpPort,
+				User: "bastion",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	cmd, _ := runner.NewCommand(context.Background(), "hostname", out, &bytes.Buffer{})
+
+	require.NoError(t, r.Run(cmd))
+	require.Equal(t, "hostname", out.String())
+	require.Equal(t, 1, bastion.acceptedCount(), "bastion connections")
+	require.Equal(t, 1, target.acceptedCount(), "target connections")
+}
+
+func TestRunnerJumpHostError(t *testing.T) {
+	_, err := NewRunner(Config{
+		Host: "10.0.0.1",
+		User: "root",
+		Key:  []byte(testPrivateKey),
+		JumpHosts: []JumpHost{
+			{
+				Host: "1.2.3.4",
+				Key:  []byte("12345"),
+			},
+		},
+	})
+
+	require.Error(t, err)
+}
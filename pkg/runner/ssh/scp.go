@@ -0,0 +1,206 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+// Upload copies content to the file on the machine using scp protocol,
+// file is written with sudo so it can be put to directories owned by root.
+func (r *Runner) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	if err := dst.Validate(); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return errors.Wrap(err, "scp: read content")
+	}
+
+	script := fmt.Sprintf("sudo mkdir -p %s && sudo scp -qt %s",
+		runner.Quote(dst.Dir()), runner.Quote(dst.Path))
+
+	err = r.scp(ctx, script, func(in io.Writer, out *bufio.Reader) error {
+		if err := readAck(out); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(in, "C%s %d %s\n", dst.Perm(), len(data),
+			path.Base(dst.Path)); err != nil {
+			return err
+		}
+
+		if err := readAck(out); err != nil {
+			return err
+		}
+
+		if _, err := in.Write(append(data, 0)); err != nil {
+			return err
+		}
+
+		return readAck(out)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "scp: upload %s", dst.Path)
+	}
+
+	// scp does not change mode of existing files, so attributes are set explicitly
+	attrs := fmt.Sprintf("sudo chmod %s %s", dst.Perm(), runner.Quote(dst.Path))
+	if dst.Owner != "" {
+		attrs = fmt.Sprintf("%s && sudo chown %s %s", attrs,
+			runner.Quote(dst.Owner), runner.Quote(dst.Path))
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd, err := runner.NewCommand(ctx, attrs, ioutil.Discard, stderr)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Run(cmd); err != nil {
+		return errors.Wrapf(err, "scp: set attributes of %s: %s", dst.Path,
+			strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// Download copies content of the file on the machine to out using scp protocol.
+func (r *Runner) Download(ctx context.Context, src string, out io.Writer) error {
+	if strings.TrimSpace(src) == "" {
+		return runner.ErrEmptyPath
+	}
+
+	script := fmt.Sprintf("sudo scp -qf %s", runner.Quote(src))
+
+	err := r.scp(ctx, script, func(in io.Writer, scpOut *bufio.Reader) error {
+		if _, err := in.Write([]byte{0}); err != nil {
+			return err
+		}
+
+		size, err := readHeader(scpOut)
+		if err != nil {
+			return err
+		}
+
+		if _, err := in.Write([]byte{0}); err != nil {
+			return err
+		}
+
+		if _, err := io.CopyN(out, scpOut, size); err != nil {
+			return err
+		}
+
+		if err := readAck(scpOut); err != nil {
+			return err
+		}
+
+		_, err = in.Write([]byte{0})
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "scp: download %s", src)
+	}
+
+	return nil
+}
+
+// scp starts remote scp with the script and runs transfer over its
+// input and output.
+func (r *Runner) scp(ctx context.Context, script string, transfer func(io.Writer, *bufio.Reader) error) error {
+	session, release, err := r.newSession(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	defer session.Close()
+
+	in, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	out, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr := &bytes.Buffer{}
+	session.Stderr = stderr
+
+	if err := session.Start(script); err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		err := transfer(in, bufio.NewReader(out))
+		in.Close()
+
+		if waitErr := session.Wait(); err == nil {
+			err = waitErr
+		}
+		errCh <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+		<-errCh
+		return ctx.Err()
+	case err := <-errCh:
+		if err != nil && stderr.Len() > 0 {
+			return errors.Wrap(err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	}
+}
+
+// readAck reads response of the remote scp, zero byte means success
+// others are followed by the error message.
+func readAck(r *bufio.Reader) error {
+	b, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if b == 0 {
+		return nil
+	}
+
+	msg, _ := r.ReadString('\n')
+	return errors.Errorf("scp: %s", strings.TrimSpace(msg))
+}
+
+// readHeader reads file header C<mode> <size> <name> sent by the
+// remote scp and returns size of the file.
+func readHeader(r *bufio.Reader) (int64, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+
+	if line[0] == 1 || line[0] == 2 {
+		return 0, errors.Errorf("scp: %s", strings.TrimSpace(line[1:]))
+	}
+
+	fields := strings.Fields(line)
+	if line[0] != 'C' || len(fields) != 3 {
+		return 0, errors.Errorf("scp: unexpected header %q", line)
+	}
+
+	return strconv.ParseInt(fields[1], 10, 64)
+}
@@ -25,7 +25,7 @@ func getSshConfig(config Config) (*ssh.ClientConfig, error) {
 		return nil, ErrHostNotSpecified
 	}
 
-	key, err := ssh.ParsePrivateKey(config.Key)
+	key, err := ParsePrivateKey(config.Key)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +47,101 @@ func getSshConfig(config Config) (*ssh.ClientConfig, error) {
 	}, nil
 }
 
+// hop is a single host in the chain of ssh connections.
+type hop struct {
+	host string
+	port string
+	conf *ssh.ClientConfig
+}
+
+func (h hop) addr() string {
+	return fmt.Sprintf("%s:%s", h.host, h.port)
+}
+
+func jumpHop(config Config, jumpHost JumpHost) (hop, error) {
+	jumpConfig := Config{
+		Host:    jumpHost.Host,
+		Port:    jumpHost.Port,
+		User:    jumpHost.User,
+		Timeout: config.Timeout,
+		Key:     jumpHost.Key,
+	}
+
+	if jumpConfig.Port == "" {
+		jumpConfig.Port = DefaultPort
+	}
+
+	if jumpConfig.User == "" {
+		jumpConfig.User = config.User
+	}
+
+	if len(jumpConfig.Key) == 0 {
+		jumpConfig.Key = config.Key
+	}
+
+	sshConfig, err := getSshConfig(jumpConfig)
+	if err != nil {
+		return hop{}, err
+	}
+
+	return hop{
+		host: jumpConfig.Host,
+		port: jumpConfig.Port,
+		conf: sshConfig,
+	}, nil
+}
+
+// connection is ssh client of the target host along with clients of jump
+// hosts that connection is tunneled through.
+type connection struct {
+	*ssh.Client
+	jumps []*ssh.Client
+}
+
+// Close closes connection to the target host and then jump hosts
+// in reverse order.
+func (c *connection) Close() error {
+	err := c.Client.Close()
+
+	for i := len(c.jumps) - 1; i >= 0; i-- {
+		if closeErr := c.jumps[i].Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// tunnel opens ssh connection to the host through already established
+// connection to the jump host.
+func tunnel(jump *ssh.Client, h hop) (*ssh.Client, error) {
+	conn, err := jump.Dial("tcp", h.addr())
+	if err != nil {
+		return nil, err
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, h.addr(), h.conf)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
 func connectionWithBackOff(ctx context.Context, host, port string, config *ssh.ClientConfig, timeout time.Duration, attemptCount int) (*ssh.Client, error) {
+	return dialWithBackOff(ctx, fmt.Sprintf("%s:%s", host, port), func(addr string) (*ssh.Client, error) {
+		return ssh.Dial("tcp", addr, config)
+	}, timeout, attemptCount)
+}
+
+func tunnelWithBackOff(ctx context.Context, jump *ssh.Client, h hop, timeout time.Duration, attemptCount int) (*ssh.Client, error) {
+	return dialWithBackOff(ctx, h.addr(), func(string) (*ssh.Client, error) {
+		return tunnel(jump, h)
+	}, timeout, attemptCount)
+}
+
+func dialWithBackOff(ctx context.Context, addr string, dial func(string) (*ssh.Client, error), timeout time.Duration, attemptCount int) (*ssh.Client, error) {
 	var (
 		counter = 0
 		c       *ssh.Client
@@ -59,12 +153,11 @@ func connectionWithBackOff(ctx context.Context, host, port string, config *ssh.C
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
-			c, err = ssh.Dial("tcp", fmt.Sprintf("%s:%s", host, port), config)
+			c, err = dial(addr)
 
 			if err != nil {
 				logrus.Debugf("connect to %s failed, try again in %v seconds, reason: %v",
-					fmt.Sprintf("%s:%s", host, port),
-					timeout, err)
+					addr, timeout, err)
 				time.Sleep(timeout)
 				timeout = timeout * 2
 			} else {
@@ -0,0 +1,106 @@
+package templatemanager
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+type OverrideRequest struct {
+	Scope
+	Body string `json:"body"`
+}
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Register(r *mux.Router) {
+	r.HandleFunc("/templates/{name}", h.GetTemplate).Methods(http.MethodGet)
+	r.HandleFunc("/templates/{name}", h.PutTemplate).Methods(http.MethodPut)
+	r.HandleFunc("/templates/{name}/versions", h.GetVersions).Methods(http.MethodGet)
+}
+
+// GetTemplate returns template effective for the kube or profile
+// passed in query, global one is returned otherwise.
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	tpl, err := h.service.Get(r.Context(), mux.Vars(r)["name"], scopeFromQuery(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(tpl); err != nil {
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) PutTemplate(w http.ResponseWriter, r *http.Request) {
+	req := &OverrideRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	o, err := h.service.Override(r.Context(), mux.Vars(r)["name"], req.Scope, req.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetVersions(w http.ResponseWriter, r *http.Request) {
+	scope := scopeFromQuery(r)
+	if err := scope.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.service.Versions(r.Context(), mux.Vars(r)["name"], scope)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(versions); err != nil {
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func scopeFromQuery(r *http.Request) Scope {
+	return Scope{
+		KubeID:    r.URL.Query().Get("kubeId"),
+		ProfileID: r.URL.Query().Get("profileId"),
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch errors.Cause(err) {
+	case sgerrors.ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidTemplate, ErrInvalidScope:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		logrus.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
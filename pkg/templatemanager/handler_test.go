@@ -0,0 +1,116 @@
+package templatemanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/storage/memory"
+)
+
+func TestHandlerPutTemplate(t *testing.T) {
+	name := "testHandlerPutTemplate"
+	setSource(name, "built-in")
+	defer DeleteTemplate(name)
+	defer resetOverrides(name)
+
+	router := mux.NewRouter()
+	NewHandler(NewService(DefaultStoragePrefix, memory.NewInMemoryRepository())).Register(router)
+
+	testCases := []struct {
+		description  string
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{
+			description:  "invalid json",
+			name:         name,
+			body:         `{`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "unknown template",
+			name:         "unknown",
+			body:         `{"body": "echo"}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			description:  "invalid template",
+			name:         name,
+			body:         `{"body": "{{ .Broken"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "invalid scope",
+			name:         name,
+			body:         `{"body": "echo", "kubeId": "kube", "profileId": "profile"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "success",
+			name:         name,
+			body:         `{"body": "echo {{ .Kube }}", "kubeId": "kube"}`,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/templates/"+testCase.name,
+			bytes.NewBufferString(testCase.body))
+
+		router.ServeHTTP(rr, req)
+		require.Equal(t, testCase.expectedCode, rr.Code, testCase.description)
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/templates/"+name+"?kubeId=kube", nil)
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	o := &Override{}
+	require.Nil(t, json.NewDecoder(rr.Body).Decode(o))
+	require.Equal(t, 1, o.Version)
+	require.Equal(t, "kube", o.Scope.KubeID)
+	require.Equal(t, "echo {{ .Kube }}", o.Body)
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/templates/"+name+"/versions?kubeId=kube", nil)
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var versions []Override
+	require.Nil(t, json.NewDecoder(rr.Body).Decode(&versions))
+	require.Len(t, versions, 1)
+}
+
+func TestHandlerGetTemplate(t *testing.T) {
+	name := "testHandlerGetTemplate"
+	setSource(name, "built-in")
+	defer DeleteTemplate(name)
+
+	router := mux.NewRouter()
+	NewHandler(NewService(DefaultStoragePrefix, memory.NewInMemoryRepository())).Register(router)
+
+	testCases := []struct {
+		url          string
+		expectedCode int
+	}{
+		{"/templates/" + name, http.StatusOK},
+		{"/templates/unknown", http.StatusNotFound},
+		{"/templates/" + name + "?kubeId=kube&profileId=profile", http.StatusBadRequest},
+		{"/templates/" + name + "/versions?kubeId=kube&profileId=profile", http.StatusBadRequest},
+	}
+
+	for _, testCase := range testCases {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, testCase.url, nil))
+		require.Equal(t, testCase.expectedCode, rr.Code, testCase.url)
+	}
+}
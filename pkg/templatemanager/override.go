@@ -0,0 +1,134 @@
+package templatemanager
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+var ErrInvalidScope = errors.New("override can be scoped either to kube or to profile")
+
+var overrides = make(map[string]*override)
+
+// Scope limits override to a single kube or to kubes created from a profile,
+// empty scope overrides template globally.
+type Scope struct {
+	KubeID    string `json:"kubeId,omitempty"`
+	ProfileID string `json:"profileId,omitempty"`
+}
+
+func (s Scope) Validate() error {
+	if s.KubeID != "" && s.ProfileID != "" {
+		return ErrInvalidScope
+	}
+	return nil
+}
+
+// Path is used to group versions of the override in storage.
+func (s Scope) Path() string {
+	switch {
+	case s.KubeID != "":
+		return "kube/" + s.KubeID
+	case s.ProfileID != "":
+		return "profile/" + s.ProfileID
+	default:
+		return "global"
+	}
+}
+
+// Override is a version of the template body that replaces built-in one.
+type Override struct {
+	Name      string    `json:"name"`
+	Scope     Scope     `json:"scope"`
+	Version   int       `json:"version"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type override struct {
+	Override
+	tpl *template.Template
+}
+
+// Parse validates body of the override.
+func (o Override) Parse() (*template.Template, error) {
+	tpl, err := template.New(o.Name).Parse(o.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s template", o.Name)
+	}
+	return tpl, nil
+}
+
+// SetOverride makes override effective for tasks started after the call,
+// older versions of override are ignored.
+func SetOverride(o Override) error {
+	if err := o.Scope.Validate(); err != nil {
+		return err
+	}
+
+	tpl, err := o.Parse()
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	key := overrideKey(o.Name, o.Scope)
+	if current := overrides[key]; current != nil && current.Version > o.Version {
+		return nil
+	}
+	overrides[key] = &override{Override: o, tpl: tpl}
+
+	return nil
+}
+
+// Resolve returns template that overrides the named one for the kube,
+// kube overrides take precedence over profile ones and they over global.
+func Resolve(name, kubeID, profileID string) (*template.Template, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	if o := lookup(name, kubeID, profileID); o != nil {
+		return o.tpl, nil
+	}
+	return nil, sgerrors.ErrNotFound
+}
+
+// ResolveOverride returns override that is effective for the kube.
+func ResolveOverride(name, kubeID, profileID string) (*Override, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	if o := lookup(name, kubeID, profileID); o != nil {
+		result := o.Override
+		return &result, nil
+	}
+	return nil, sgerrors.ErrNotFound
+}
+
+func lookup(name, kubeID, profileID string) *override {
+	scopes := make([]Scope, 0, 3)
+	if kubeID != "" {
+		scopes = append(scopes, Scope{KubeID: kubeID})
+	}
+	if profileID != "" {
+		scopes = append(scopes, Scope{ProfileID: profileID})
+	}
+	scopes = append(scopes, Scope{})
+
+	for _, scope := range scopes {
+		if o := overrides[overrideKey(name, scope)]; o != nil {
+			return o
+		}
+	}
+	return nil
+}
+
+func overrideKey(name string, scope Scope) string {
+	return fmt.Sprintf("%s/%s", name, scope.Path())
+}
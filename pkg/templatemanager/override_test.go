@@ -0,0 +1,97 @@
+package templatemanager
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func TestScopeValidate(t *testing.T) {
+	require.Nil(t, Scope{}.Validate())
+	require.Nil(t, Scope{KubeID: "kube"}.Validate())
+	require.Nil(t, Scope{ProfileID: "profile"}.Validate())
+	require.Equal(t, ErrInvalidScope, Scope{KubeID: "kube", ProfileID: "profile"}.Validate())
+}
+
+func TestSetOverride(t *testing.T) {
+	name := "testSetOverride"
+	defer resetOverrides(name)
+
+	require.Nil(t, SetOverride(Override{Name: name, Version: 2, Body: "v2"}))
+	require.Nil(t, SetOverride(Override{Name: name, Version: 1, Body: "v1"}))
+
+	o, err := ResolveOverride(name, "", "")
+	require.Nil(t, err)
+	require.Equal(t, 2, o.Version, "older version must not replace newer one")
+
+	require.NotNil(t, SetOverride(Override{Name: name, Version: 3, Body: "{{ .Broken"}))
+	require.Equal(t, ErrInvalidScope, SetOverride(Override{
+		Name:  name,
+		Scope: Scope{KubeID: "kube", ProfileID: "profile"},
+		Body:  "v3",
+	}))
+}
+
+func TestResolve(t *testing.T) {
+	name := "testResolve"
+	defer resetOverrides(name)
+
+	_, err := Resolve(name, "kube", "profile")
+	require.True(t, sgerrors.IsNotFound(err))
+
+	testCases := []struct {
+		override  Override
+		kubeID    string
+		profileID string
+		expected  string
+	}{
+		{
+			override: Override{Name: name, Body: "global"},
+			kubeID:   "kube",
+			expected: "global",
+		},
+		{
+			override:  Override{Name: name, Scope: Scope{ProfileID: "profile"}, Body: "profile"},
+			kubeID:    "kube",
+			profileID: "profile",
+			expected:  "profile",
+		},
+		{
+			override:  Override{Name: name, Scope: Scope{KubeID: "kube"}, Body: "kube"},
+			kubeID:    "kube",
+			profileID: "profile",
+			expected:  "kube",
+		},
+		{
+			override:  Override{Name: name, Scope: Scope{KubeID: "other"}, Body: "other"},
+			kubeID:    "another",
+			profileID: "profile",
+			expected:  "profile",
+		},
+	}
+
+	for _, testCase := range testCases {
+		require.Nil(t, SetOverride(testCase.override))
+
+		tpl, err := Resolve(name, testCase.kubeID, testCase.profileID)
+		require.Nil(t, err)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, tpl.Execute(buf, nil))
+		require.Equal(t, testCase.expected, buf.String())
+	}
+}
+
+func resetOverrides(name string) {
+	m.Lock()
+	defer m.Unlock()
+
+	for key, o := range overrides {
+		if o.Name == name {
+			delete(overrides, key)
+		}
+	}
+}
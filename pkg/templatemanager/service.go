@@ -0,0 +1,189 @@
+package templatemanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+)
+
+const DefaultStoragePrefix = "/supergiant/templates/"
+
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// Service keeps versions of template overrides in storage, every stored
+// version becomes effective without restart.
+type Service struct {
+	prefix  string
+	storage storage.Interface
+
+	// versionLock lets one override be stored at a time,
+	// so that concurrent overrides don't get the same version.
+	versionLock sync.Mutex
+}
+
+func NewService(prefix string, s storage.Interface) *Service {
+	return &Service{
+		prefix:  prefix,
+		storage: s,
+	}
+}
+
+// Load applies latest versions of overrides kept in storage.
+func (s *Service) Load(ctx context.Context) error {
+	items, err := s.list(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	for _, o := range items {
+		logrus.Debugf("templatemanager: override %s %s version %d",
+			o.Name, o.Scope.Path(), o.Version)
+		if err := SetOverride(o); err != nil {
+			return errors.Wrapf(err, "load override %s %s version %d",
+				o.Name, o.Scope.Path(), o.Version)
+		}
+	}
+
+	return nil
+}
+
+// Get returns template effective for the scope, built-in template
+// has zero version.
+func (s *Service) Get(ctx context.Context, name string, scope Scope) (*Override, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+
+	if o, err := ResolveOverride(name, scope.KubeID, scope.ProfileID); err == nil {
+		return o, nil
+	}
+
+	src, err := GetSource(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "template %s", name)
+	}
+
+	return &Override{
+		Name: name,
+		Body: src,
+	}, nil
+}
+
+// Override stores new version of the template for the scope.
+func (s *Service) Override(ctx context.Context, name string, scope Scope, body string) (*Override, error) {
+	if _, err := GetSource(name); err != nil {
+		return nil, errors.Wrapf(err, "template %s", name)
+	}
+
+	o := Override{
+		Name:      name,
+		Scope:     scope,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := o.Parse(); err != nil {
+		return nil, errors.Wrap(ErrInvalidTemplate, err.Error())
+	}
+
+	s.versionLock.Lock()
+	defer s.versionLock.Unlock()
+
+	versions, err := s.Versions(ctx, name, scope)
+	if err != nil {
+		return nil, err
+	}
+	o.Version = 1
+	if len(versions) > 0 {
+		o.Version = versions[len(versions)-1].Version + 1
+	}
+
+	// Version stored meanwhile by another instance is not overwritten
+	for {
+		stored, err := s.storage.Get(ctx, s.prefix, versionKey(o))
+		if sgerrors.IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "get override %s version %d", name, o.Version)
+		}
+		if len(stored) == 0 {
+			break
+		}
+		o.Version++
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Put(ctx, s.prefix, versionKey(o), data); err != nil {
+		return nil, errors.Wrapf(err, "store override %s", name)
+	}
+
+	if err := SetOverride(o); err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// Versions returns stored versions of the override sorted by version.
+func (s *Service) Versions(ctx context.Context, name string, scope Scope) ([]Override, error) {
+	items, err := s.list(ctx, overrideKey(name, scope)+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Override, 0, len(items))
+	for _, o := range items {
+		if o.Name == name && o.Scope == scope {
+			versions = append(versions, o)
+		}
+	}
+
+	return versions, nil
+}
+
+func (s *Service) list(ctx context.Context, keyPrefix string) ([]Override, error) {
+	rawItems, err := s.storage.GetAll(ctx, s.prefix+keyPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "list overrides")
+	}
+
+	items := make([]Override, 0, len(rawItems))
+	for _, data := range rawItems {
+		if len(data) == 0 {
+			continue
+		}
+
+		o := Override{}
+		if err := json.Unmarshal(data, &o); err != nil {
+			return nil, errors.Wrap(err, "unmarshal override")
+		}
+		items = append(items, o)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Version < items[j].Version
+	})
+
+	return items, nil
+}
+
+func versionKey(o Override) string {
+	return fmt.Sprintf("%s/%010d", overrideKey(o.Name, o.Scope), o.Version)
+}
@@ -0,0 +1,172 @@
+package templatemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/testutils/storage"
+)
+
+func TestServiceOverride(t *testing.T) {
+	name := "testServiceOverride"
+	setSource(name, "built-in")
+	defer DeleteTemplate(name)
+	defer resetOverrides(name)
+
+	svc := NewService(DefaultStoragePrefix, memory.NewInMemoryRepository())
+
+	tpl, err := svc.Get(context.Background(), name, Scope{KubeID: "kube"})
+	require.Nil(t, err)
+	require.Equal(t, 0, tpl.Version)
+	require.Equal(t, "built-in", tpl.Body)
+
+	for i, body := range []string{"v1", "v2"} {
+		o, err := svc.Override(context.Background(), name, Scope{KubeID: "kube"}, body)
+		require.Nil(t, err)
+		require.Equal(t, i+1, o.Version)
+	}
+
+	tpl, err = svc.Get(context.Background(), name, Scope{KubeID: "kube"})
+	require.Nil(t, err)
+	require.Equal(t, 2, tpl.Version)
+	require.Equal(t, "v2", tpl.Body)
+
+	tpl, err = svc.Get(context.Background(), name, Scope{KubeID: "other"})
+	require.Nil(t, err)
+	require.Equal(t, "built-in", tpl.Body, "override must not affect other kubes")
+
+	versions, err := svc.Versions(context.Background(), name, Scope{KubeID: "kube"})
+	require.Nil(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, "v1", versions[0].Body)
+}
+
+func TestServiceOverrideConcurrent(t *testing.T) {
+	name := "testServiceOverrideConcurrent"
+	setSource(name, "built-in")
+	defer DeleteTemplate(name)
+	defer resetOverrides(name)
+
+	repo := memory.NewInMemoryRepository()
+	svc := NewService(DefaultStoragePrefix, repo)
+	scope := Scope{ProfileID: "profile"}
+
+	// Version stored by another instance of control
+	stored := Override{Name: name, Scope: scope, Version: 1, Body: "body"}
+	data, err := json.Marshal(stored)
+	require.Nil(t, err)
+	require.Nil(t, repo.Put(context.Background(), DefaultStoragePrefix, versionKey(stored), data))
+
+	count := 10
+	versions := make(chan int, count)
+	wg := sync.WaitGroup{}
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o, err := svc.Override(context.Background(), name, scope, "body")
+			require.Nil(t, err)
+			versions <- o.Version
+		}()
+	}
+	wg.Wait()
+	close(versions)
+
+	seen := make(map[int]bool)
+	for v := range versions {
+		require.False(t, seen[v], "version %d is stored twice", v)
+		require.NotEqual(t, 1, v, "version of another instance is overwritten")
+		seen[v] = true
+	}
+	require.Len(t, seen, count)
+}
+
+func TestServiceOverrideErrors(t *testing.T) {
+	name := "testServiceOverrideErrors"
+	setSource(name, "built-in")
+	defer DeleteTemplate(name)
+	defer resetOverrides(name)
+
+	testCases := []struct {
+		description string
+		name        string
+		scope       Scope
+		body        string
+		storage     *storage.Fake
+		expected    error
+	}{
+		{
+			description: "unknown template",
+			name:        "unknown",
+			storage:     &storage.Fake{},
+			expected:    sgerrors.ErrNotFound,
+		},
+		{
+			description: "invalid scope",
+			name:        name,
+			scope:       Scope{KubeID: "kube", ProfileID: "profile"},
+			storage:     &storage.Fake{},
+			expected:    ErrInvalidScope,
+		},
+		{
+			description: "invalid template",
+			name:        name,
+			body:        "{{ .Broken",
+			storage:     &storage.Fake{},
+			expected:    ErrInvalidTemplate,
+		},
+		{
+			description: "storage error",
+			name:        name,
+			body:        "body",
+			storage:     &storage.Fake{PutErr: errors.New("put")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		svc := NewService(DefaultStoragePrefix, testCase.storage)
+
+		_, err := svc.Override(context.Background(), testCase.name, testCase.scope, testCase.body)
+		require.NotNil(t, err, testCase.description)
+
+		if testCase.expected != nil {
+			require.Equal(t, testCase.expected, pkgerrors.Cause(err), testCase.description)
+		}
+	}
+
+	_, err := ResolveOverride(name, "", "")
+	require.True(t, sgerrors.IsNotFound(err), "failed override must not be applied")
+}
+
+func TestServiceLoad(t *testing.T) {
+	name := "testServiceLoad"
+	setSource(name, "built-in")
+	defer DeleteTemplate(name)
+
+	repo := memory.NewInMemoryRepository()
+	svc := NewService(DefaultStoragePrefix, repo)
+
+	_, err := svc.Override(context.Background(), name, Scope{ProfileID: "profile"}, "v1")
+	require.Nil(t, err)
+	_, err = svc.Override(context.Background(), name, Scope{ProfileID: "profile"}, "v2")
+	require.Nil(t, err)
+
+	// Simulate restart of the service
+	resetOverrides(name)
+	defer resetOverrides(name)
+
+	require.Nil(t, NewService(DefaultStoragePrefix, repo).Load(context.Background()))
+
+	o, err := ResolveOverride(name, "kube", "profile")
+	require.Nil(t, err)
+	require.Equal(t, 2, o.Version)
+	require.Equal(t, "v2", o.Body)
+}
@@ -18,10 +18,13 @@ import (
 var (
 	m           sync.RWMutex
 	templateMap map[string]*template.Template
+	// sources keeps bodies of the loaded templates to show them in api
+	sources map[string]string
 )
 
 func init() {
 	templateMap = make(map[string]*template.Template)
+	sources = make(map[string]string)
 }
 
 func Init(templateDir string) error {
@@ -44,6 +47,16 @@ func GetTemplate(templateName string) (*template.Template, error) {
 	}
 }
 
+// GetSource returns body of the built-in or custom template.
+func GetSource(templateName string) (string, error) {
+	m.RLock()
+	defer m.RUnlock()
+	if src, ok := sources[templateName]; ok {
+		return src, nil
+	}
+	return "", sgerrors.ErrNotFound
+}
+
 func SetTemplate(templateName string, tpl *template.Template) {
 	m.Lock()
 	defer m.Unlock()
@@ -54,6 +67,13 @@ func DeleteTemplate(templateName string) {
 	m.Lock()
 	defer m.Unlock()
 	delete(templateMap, templateName)
+	delete(sources, templateName)
+}
+
+func setSource(templateName, src string) {
+	m.Lock()
+	defer m.Unlock()
+	sources[templateName] = src
 }
 
 func addDefaultTpls() error {
@@ -64,6 +84,7 @@ func addDefaultTpls() error {
 		}
 		logrus.Debugf("templatemanager: adding default template: %q", name)
 		SetTemplate(name, t)
+		setSource(name, tpl)
 	}
 	return nil
 }
@@ -103,7 +124,8 @@ func addCustomTpls(dirname string) error {
 			}
 
 			logrus.Debugf("templatemanager: adding custom template: %q", name)
-			templateMap[name] = t
+			SetTemplate(name, t)
+			setSource(name, string(data))
 		}
 	}
 
@@ -1,7 +1,9 @@
 package testutils
 
 import (
+	"context"
 	"io"
+	"io/ioutil"
 	"strings"
 
 	"github.com/supergiant/control/pkg/runner"
@@ -19,3 +21,45 @@ func (m *MockRunner) Run(command *runner.Command) error {
 	_, err := io.Copy(command.Out, strings.NewReader(command.Script))
 	return err
 }
+
+func (m *MockRunner) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	return m.Err
+}
+
+func (m *MockRunner) Download(ctx context.Context, src string, out io.Writer) error {
+	return m.Err
+}
+
+// FakeTransfer implements uploads and downloads of runner.Runner for fake
+// runners of tests, uploaded files are kept by their path.
+type FakeTransfer struct {
+	Err   error
+	Files map[string]string
+}
+
+func (f *FakeTransfer) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	if f.Err != nil {
+		return f.Err
+	}
+
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	if f.Files == nil {
+		f.Files = make(map[string]string)
+	}
+	f.Files[dst.Path] = string(data)
+
+	return nil
+}
+
+func (f *FakeTransfer) Download(ctx context.Context, src string, out io.Writer) error {
+	if f.Err != nil {
+		return f.Err
+	}
+
+	_, err := io.WriteString(out, f.Files[src])
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/digitalocean/godo"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
@@ -18,6 +19,8 @@ import (
 
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/clouds/digitaloceansdk"
+	"github.com/supergiant/control/pkg/clouds/openstacksdk"
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows/steps"
@@ -36,6 +39,9 @@ type CloudAccountValidatorImpl struct {
 	aws          func(map[string]string) error
 	gce          func(map[string]string) error
 	azure        func(map[string]string) error
+	openstack    func(map[string]string) error
+	packet       func(map[string]string) error
+	simulator    func(map[string]string) error
 }
 
 func NewCloudAccountValidator() *CloudAccountValidatorImpl {
@@ -44,6 +50,9 @@ func NewCloudAccountValidator() *CloudAccountValidatorImpl {
 		aws:          validateAWSCredentials,
 		gce:          validateGCECredentials,
 		azure:        validateAzureCredentials,
+		openstack:    validateOpenStackCredentials,
+		packet:       validatePacketCredentials,
+		simulator:    validateSimulatorCredentials,
 	}
 }
 
@@ -57,6 +66,12 @@ func (v *CloudAccountValidatorImpl) ValidateCredentials(cloudAccount *model.Clou
 		return v.gce(cloudAccount.Credentials)
 	case clouds.Azure:
 		return v.azure(cloudAccount.Credentials)
+	case clouds.OpenStack:
+		return v.openstack(cloudAccount.Credentials)
+	case clouds.Packet:
+		return v.packet(cloudAccount.Credentials)
+	case clouds.Simulator:
+		return v.simulator(cloudAccount.Credentials)
 	}
 
 	return sgerrors.ErrUnsupportedProvider
@@ -161,3 +176,71 @@ func validateAzureCredentials(creds map[string]string) error {
 
 	return nil
 }
+
+func validateOpenStackCredentials(creds map[string]string) error {
+	if creds == nil {
+		return ErrInvalidCredentials
+	}
+
+	for _, k := range []string{
+		clouds.OpenStackAuthURL,
+		clouds.OpenStackUserName,
+		clouds.OpenStackPassword,
+		clouds.OpenStackProjectName,
+	} {
+		creds[k] = strings.TrimSpace(creds[k])
+		if creds[k] == "" {
+			return errors.Wrapf(ErrInvalidCredentials, "openstack: %s should be provided", k)
+		}
+	}
+
+	client, err := openstacksdk.New(context.Background(), openstacksdk.CredentialsFrom(creds))
+	if err != nil {
+		return err
+	}
+
+	// token is scoped to the project, but the user may lack access to compute
+	compute, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	_, err = flavors.ListDetail(compute, nil).AllPages()
+	return errors.Wrap(err, "list flavors")
+}
+
+func validatePacketCredentials(creds map[string]string) error {
+	if creds == nil {
+		return ErrInvalidCredentials
+	}
+
+	for _, k := range []string{
+		clouds.PacketAPIKey,
+		clouds.PacketProjectID,
+	} {
+		creds[k] = strings.TrimSpace(creds[k])
+		if creds[k] == "" {
+			return errors.Wrapf(ErrInvalidCredentials, "packet: %s should be provided", k)
+		}
+	}
+
+	// api key may be valid while project belongs to someone else
+	_, _, err := packetsdk.New(creds[clouds.PacketAPIKey]).Projects.Get(creds[clouds.PacketProjectID], nil)
+	if packetsdk.IsUnauthorized(err) {
+		return errors.Wrap(sgerrors.ErrInvalidCredentials, err.Error())
+	}
+	if packetsdk.IsNotFound(err) {
+		return errors.Wrapf(sgerrors.ErrInvalidCredentials, "packet: project %s not found", creds[clouds.PacketProjectID])
+	}
+	return err
+}
+
+// validateSimulatorCredentials checks runner of simulated machines,
+// there is nothing to authenticate against.
+func validateSimulatorCredentials(creds map[string]string) error {
+	switch creds[clouds.SimulatorRunner] {
+	case "", clouds.SimulatorRunnerDry, clouds.SimulatorRunnerLocal:
+		return nil
+	}
+	return errors.Wrapf(ErrInvalidCredentials, "simulator: unknown runner %s", creds[clouds.SimulatorRunner])
+}
@@ -1,9 +1,17 @@
 package util
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/pkg/errors"
+
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/clouds/openstacksdk/fixtures"
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
 )
@@ -84,6 +92,42 @@ func TestValidateCredentials(t *testing.T) {
 			},
 			expectedError: sgerrors.ErrInvalidCredentials,
 		},
+		{
+			description: "openstack",
+			cloudAccount: &model.CloudAccount{
+				Name:        "test",
+				Provider:    clouds.OpenStack,
+				Credentials: map[string]string{},
+			},
+			getCreds: func(map[string]string) error {
+				return nil
+			},
+			expectedError: nil,
+		},
+		{
+			description: "packet",
+			cloudAccount: &model.CloudAccount{
+				Name:        "test",
+				Provider:    clouds.Packet,
+				Credentials: map[string]string{},
+			},
+			getCreds: func(map[string]string) error {
+				return sgerrors.ErrInvalidCredentials
+			},
+			expectedError: sgerrors.ErrInvalidCredentials,
+		},
+		{
+			description: "simulator",
+			cloudAccount: &model.CloudAccount{
+				Name:        "test",
+				Provider:    clouds.Simulator,
+				Credentials: map[string]string{},
+			},
+			getCreds: func(map[string]string) error {
+				return nil
+			},
+			expectedError: nil,
+		},
 		{
 			description: "aws invalid creads",
 			cloudAccount: &model.CloudAccount{
@@ -104,6 +148,9 @@ func TestValidateCredentials(t *testing.T) {
 			digitalOcean: testCase.getCreds,
 			aws:          testCase.getCreds,
 			gce:          testCase.getCreds,
+			openstack:    testCase.getCreds,
+			packet:       testCase.getCreds,
+			simulator:    testCase.getCreds,
 		}
 
 		err := validator.ValidateCredentials(testCase.cloudAccount)
@@ -132,4 +179,101 @@ func TestNewCloudAccountValidator(t *testing.T) {
 	if validator.digitalOcean == nil {
 		t.Errorf("digitalocean must not be nil")
 	}
+
+	if validator.openstack == nil {
+		t.Errorf("openstack must not be nil")
+	}
+
+	if validator.packet == nil {
+		t.Errorf("packet must not be nil")
+	}
+
+	if validator.simulator == nil {
+		t.Errorf("simulator must not be nil")
+	}
+}
+
+func TestValidateOpenStackCredentials(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	fixtures.HandleAuth(t)
+	fixtures.HandleListFlavors(t, fixtures.ComputePrefix)
+
+	creds := map[string]string{
+		clouds.OpenStackAuthURL:     fixtures.AuthURL(),
+		clouds.OpenStackUserName:    fixtures.UserName,
+		clouds.OpenStackPassword:    fixtures.Password,
+		clouds.OpenStackProjectName: fixtures.ProjectName,
+	}
+
+	if err := validateOpenStackCredentials(creds); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	creds[clouds.OpenStackPassword] = "wrong"
+	if err := validateOpenStackCredentials(creds); !sgerrors.IsInvalidCredentials(err) {
+		t.Errorf("wrong password must be rejected %v", err)
+	}
+
+	creds[clouds.OpenStackProjectName] = " "
+	if err := validateOpenStackCredentials(creds); errors.Cause(err) != ErrInvalidCredentials {
+		t.Errorf("missing project must be rejected %v", err)
+	}
+}
+
+func TestValidatePacketCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path != "/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprint(w, `{"id": "p1"}`)
+	}))
+	defer server.Close()
+
+	baseURL := packetsdk.BaseURL
+	packetsdk.BaseURL = server.URL
+	defer func() { packetsdk.BaseURL = baseURL }()
+
+	creds := map[string]string{
+		clouds.PacketAPIKey:    "key",
+		clouds.PacketProjectID: "p1",
+	}
+
+	if err := validatePacketCredentials(creds); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	creds[clouds.PacketProjectID] = "unknown"
+	if err := validatePacketCredentials(creds); !sgerrors.IsInvalidCredentials(err) {
+		t.Errorf("unknown project must be rejected %v", err)
+	}
+
+	creds[clouds.PacketAPIKey] = "wrong"
+	if err := validatePacketCredentials(creds); !sgerrors.IsInvalidCredentials(err) {
+		t.Errorf("wrong api key must be rejected %v", err)
+	}
+
+	creds[clouds.PacketAPIKey] = " "
+	if err := validatePacketCredentials(creds); errors.Cause(err) != ErrInvalidCredentials {
+		t.Errorf("missing api key must be rejected %v", err)
+	}
+}
+
+func TestValidateSimulatorCredentials(t *testing.T) {
+	for _, runner := range []string{"", clouds.SimulatorRunnerDry, clouds.SimulatorRunnerLocal} {
+		if err := validateSimulatorCredentials(map[string]string{clouds.SimulatorRunner: runner}); err != nil {
+			t.Errorf("runner %q: unexpected error %v", runner, err)
+		}
+	}
+
+	if err := validateSimulatorCredentials(map[string]string{clouds.SimulatorRunner: "ssh"}); errors.Cause(err) != ErrInvalidCredentials {
+		t.Errorf("unknown runner must be rejected %v", err)
+	}
 }
@@ -1,21 +1,47 @@
 package util
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/binary"
 	"encoding/pem"
 
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/ssh"
 
+	sshrunner "github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 const keySize = 4096
 
-// Create bootstrap key pair and save to config ssh section
+// Algorithms of generated bootstrap keys
+const (
+	KeyTypeRSA     = "rsa"
+	KeyTypeECDSA   = "ecdsa"
+	KeyTypeED25519 = "ed25519"
+)
+
+var ErrUnknownKeyType = errors.New("unknown key type")
+
+// Create bootstrap key pair and save to config ssh section, key supplied
+// by user is kept and only its public part is derived.
 func BootstrapKeys(config *steps.Config) error {
-	private, public, err := generateKeyPair(keySize)
+	if config.Kube.SSHConfig.BootstrapPrivateKey != "" {
+		public, err := PublicKey(config.Kube.SSHConfig.BootstrapPrivateKey)
+		if err != nil {
+			return errors.Wrap(err, "bootstrap private key")
+		}
+
+		config.Kube.SSHConfig.BootstrapPublicKey = public
+		return nil
+	}
+
+	private, public, err := GenerateKeyPair(config.Kube.SSHConfig.BootstrapKeyType)
 
 	if err != nil {
 		return err
@@ -27,21 +53,68 @@ func BootstrapKeys(config *steps.Config) error {
 	return nil
 }
 
-func generateKeyPair(size int) (string, string, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, size)
+// GenerateKeyPair returns PEM encoded private key and public key in
+// authorized_keys format, empty key type stands for RSA.
+func GenerateKeyPair(keyType string) (string, string, error) {
+	var (
+		privateKeyPem []byte
+		publicKey     interface{}
+	)
+
+	switch keyType {
+	case "", KeyTypeRSA:
+		privateKey, err := rsa.GenerateKey(rand.Reader, keySize)
+		if err != nil {
+			return "", "", err
+		}
+
+		privateKeyPem = encodePrivateKeyToPEM(privateKey)
+		publicKey = &privateKey.PublicKey
+	case KeyTypeECDSA:
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+
+		der, err := x509.MarshalECPrivateKey(privateKey)
+		if err != nil {
+			return "", "", err
+		}
+
+		privateKeyPem = pem.EncodeToMemory(&pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: der,
+		})
+		publicKey = &privateKey.PublicKey
+	case KeyTypeED25519:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+
+		privateKeyPem = encodeED25519ToPEM(public, private)
+		publicKey = public
+	default:
+		return "", "", errors.Wrap(ErrUnknownKeyType, keyType)
+	}
+
+	pubKeyBytes, err := generatePublicKey(publicKey)
 
 	if err != nil {
 		return "", "", err
 	}
 
-	privateKeyPem := encodePrivateKeyToPEM(privateKey)
-	publicKey, err := generatePublicKey(&privateKey.PublicKey)
+	return string(privateKeyPem), string(pubKeyBytes), nil
+}
 
+// PublicKey returns public key in authorized_keys format for PEM encoded private key.
+func PublicKey(privateKey string) (string, error) {
+	signer, err := sshrunner.ParsePrivateKey([]byte(privateKey))
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
 
-	return string(privateKeyPem), string(publicKey), nil
+	return string(ssh.MarshalAuthorizedKey(signer.PublicKey())), nil
 }
 
 // encodePrivateKeyToPEM encodes Private Key from RSA to PEM format
@@ -62,14 +135,68 @@ func encodePrivateKeyToPEM(privateKey *rsa.PrivateKey) []byte {
 	return privatePEM
 }
 
-func generatePublicKey(publicKey *rsa.PublicKey) ([]byte, error) {
-	publicRsaKey, err := ssh.NewPublicKey(publicKey)
+// encodeED25519ToPEM encodes ed25519 key to unencrypted OpenSSH format
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+func encodeED25519ToPEM(public ed25519.PublicKey, private ed25519.PrivateKey) []byte {
+	const magic = "openssh-key-v1\x00"
+
+	check := make([]byte, 4)
+	rand.Read(check)
+
+	sshPublic := ssh.Marshal(struct {
+		KeyType string
+		Pub     []byte
+	}{ssh.KeyAlgoED25519, public})
+
+	privateBlock := ssh.Marshal(struct {
+		Check1  uint32
+		Check2  uint32
+		KeyType string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+	}{
+		Check1:  binary.BigEndian.Uint32(check),
+		Check2:  binary.BigEndian.Uint32(check),
+		KeyType: ssh.KeyAlgoED25519,
+		Pub:     public,
+		Priv:    private,
+	})
+
+	// Private block is padded to the cipher block size, 8 for none
+	for i := 1; len(privateBlock)%8 != 0; i++ {
+		privateBlock = append(privateBlock, byte(i))
+	}
+
+	key := ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       sshPublic,
+		PrivKeyBlock: privateBlock,
+	})
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte(magic), key...),
+	})
+}
+
+func generatePublicKey(publicKey interface{}) ([]byte, error) {
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
 
 	if err != nil {
 		return nil, err
 	}
 
-	pubKeyBytes := ssh.MarshalAuthorizedKey(publicRsaKey)
+	pubKeyBytes := ssh.MarshalAuthorizedKey(sshPublicKey)
 
 	return pubKeyBytes, nil
 }
@@ -79,12 +79,37 @@ func UpdateKubeWithCloudSpecificData(k *model.Kube, config *steps.Config) {
 		cloudSpecificSettings[clouds.GCENetworkLink] = config.GCEConfig.NetworkLink
 
 		cloudSpecificSettings[clouds.GCEImageFamily] = config.GCEConfig.ImageFamily
+		cloudSpecificSettings[clouds.GCEImageProject] = config.GCEConfig.ImageProject
 	case clouds.DigitalOcean:
 		cloudSpecificSettings[clouds.DigitalOceanExternalLoadBalancerID] = config.DigitalOceanConfig.ExternalLoadBalancerID
 		cloudSpecificSettings[clouds.DigitalOceanInternalLoadBalancerID] = config.DigitalOceanConfig.InternalLoadBalancerID
 	case clouds.Azure:
 		cloudSpecificSettings[clouds.AzureVNetCIDR] = config.AzureConfig.VNetCIDR
 		cloudSpecificSettings[clouds.AzureVolumeSize] = config.AzureConfig.VolumeSize
+	case clouds.OpenStack:
+		cloudSpecificSettings[clouds.OpenStackImage] = config.OSConfig.Image
+		cloudSpecificSettings[clouds.OpenStackSubnetCIDR] = config.OSConfig.SubnetCIDR
+		cloudSpecificSettings[clouds.OpenStackExternalNetworkID] = config.OSConfig.ExternalNetworkID
+		cloudSpecificSettings[clouds.OpenStackNetworkID] = config.OSConfig.NetworkID
+		cloudSpecificSettings[clouds.OpenStackNetworkName] = config.OSConfig.NetworkName
+		cloudSpecificSettings[clouds.OpenStackSubnetID] = config.OSConfig.SubnetID
+		cloudSpecificSettings[clouds.OpenStackRouterID] = config.OSConfig.RouterID
+		cloudSpecificSettings[clouds.OpenStackSecurityGroupID] = config.OSConfig.SecurityGroupID
+		cloudSpecificSettings[clouds.OpenStackSecurityGroupName] = config.OSConfig.SecurityGroupName
+		cloudSpecificSettings[clouds.OpenStackKeyPairName] = config.OSConfig.KeyPairName
+		cloudSpecificSettings[clouds.OpenStackLoadBalancerID] = config.OSConfig.LoadBalancerID
+		cloudSpecificSettings[clouds.OpenStackPoolID] = config.OSConfig.PoolID
+		cloudSpecificSettings[clouds.OpenStackLBFloatingIPID] = config.OSConfig.LBFloatingIPID
+	case clouds.Packet:
+		cloudSpecificSettings[clouds.PacketSSHKeyID] = config.PacketConfig.SSHKeyID
+		cloudSpecificSettings[clouds.PacketElasticIPID] = config.PacketConfig.ElasticIPID
+		cloudSpecificSettings[clouds.PacketElasticIP] = config.PacketConfig.ElasticIP
+	case clouds.Simulator:
+		cloudSpecificSettings[clouds.SimulatorNetworkID] = config.SimulatorConfig.NetworkID
+	case clouds.BYO:
+		if endpoint := config.Kube.CloudSpec[clouds.BYOAPIEndpoint]; endpoint != "" {
+			cloudSpecificSettings[clouds.BYOAPIEndpoint] = endpoint
+		}
 	}
 
 	k.CloudSpec = cloudSpecificSettings
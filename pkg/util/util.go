@@ -118,6 +118,12 @@ func FillCloudAccountCredentials(cloudAccount *model.CloudAccount, config *steps
 		return BindParams(cloudAccount.Credentials, &config.GCEConfig)
 	case clouds.Azure:
 		return BindParams(cloudAccount.Credentials, &config.AzureConfig)
+	case clouds.OpenStack:
+		return BindParams(cloudAccount.Credentials, &config.OSConfig)
+	case clouds.Packet:
+		return BindParams(cloudAccount.Credentials, &config.PacketConfig)
+	case clouds.Simulator:
+		return BindParams(cloudAccount.Credentials, &config.SimulatorConfig)
 	default:
 		return sgerrors.ErrUnknownProvider
 	}
@@ -190,7 +196,10 @@ func LoadCloudSpecificDataFromKube(k *model.Kube, config *steps.Config) error {
 
 		config.GCEConfig.NetworkLink = k.CloudSpec[clouds.GCENetworkLink]
 		config.GCEConfig.NetworkName = k.CloudSpec[clouds.GCENetworkName]
-		config.GCEConfig.ImageFamily = k.CloudSpec[clouds.GCEImageFamily]
+		if family := k.CloudSpec[clouds.GCEImageFamily]; family != "" {
+			config.GCEConfig.ImageFamily = family
+			config.GCEConfig.ImageProject = k.CloudSpec[clouds.GCEImageProject]
+		}
 
 		config.GCEConfig.AZs = k.Subnets
 
@@ -206,6 +215,33 @@ func LoadCloudSpecificDataFromKube(k *model.Kube, config *steps.Config) error {
 		config.AzureConfig.Location = k.Region
 		config.AzureConfig.VNetCIDR = k.CloudSpec[clouds.AzureVNetCIDR]
 		config.AzureConfig.VolumeSize = k.CloudSpec[clouds.AzureVolumeSize]
+	case clouds.OpenStack:
+		config.OSConfig.Region = k.Region
+		if image := k.CloudSpec[clouds.OpenStackImage]; image != "" {
+			config.OSConfig.Image = image
+		}
+		config.OSConfig.SubnetCIDR = k.CloudSpec[clouds.OpenStackSubnetCIDR]
+		config.OSConfig.ExternalNetworkID = k.CloudSpec[clouds.OpenStackExternalNetworkID]
+		config.OSConfig.NetworkID = k.CloudSpec[clouds.OpenStackNetworkID]
+		config.OSConfig.NetworkName = k.CloudSpec[clouds.OpenStackNetworkName]
+		config.OSConfig.SubnetID = k.CloudSpec[clouds.OpenStackSubnetID]
+		config.OSConfig.RouterID = k.CloudSpec[clouds.OpenStackRouterID]
+		config.OSConfig.SecurityGroupID = k.CloudSpec[clouds.OpenStackSecurityGroupID]
+		config.OSConfig.SecurityGroupName = k.CloudSpec[clouds.OpenStackSecurityGroupName]
+		config.OSConfig.KeyPairName = k.CloudSpec[clouds.OpenStackKeyPairName]
+		config.OSConfig.LoadBalancerID = k.CloudSpec[clouds.OpenStackLoadBalancerID]
+		config.OSConfig.PoolID = k.CloudSpec[clouds.OpenStackPoolID]
+		config.OSConfig.LBFloatingIPID = k.CloudSpec[clouds.OpenStackLBFloatingIPID]
+	case clouds.Packet:
+		config.PacketConfig.Facility = k.Region
+		config.PacketConfig.SSHKeyID = k.CloudSpec[clouds.PacketSSHKeyID]
+		config.PacketConfig.ElasticIPID = k.CloudSpec[clouds.PacketElasticIPID]
+		config.PacketConfig.ElasticIP = k.CloudSpec[clouds.PacketElasticIP]
+	case clouds.Simulator:
+		config.SimulatorConfig.Region = k.Region
+		config.SimulatorConfig.NetworkID = k.CloudSpec[clouds.SimulatorNetworkID]
+	case clouds.BYO:
+		// ssh credentials of the hosts are kept in the kube itself
 	default:
 		return errors.Wrapf(sgerrors.ErrUnsupportedProvider, "Load cloud specific data from kube %s", k.ID)
 	}
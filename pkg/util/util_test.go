@@ -513,7 +513,7 @@ func TestGeneratePublicKey(t *testing.T) {
 }
 
 func TestGenerateKeyPair(t *testing.T) {
-	privateKey, _, err := generateKeyPair(keySize)
+	privateKey, _, err := GenerateKeyPair(KeyTypeRSA)
 
 	if err != nil {
 		t.Errorf("generate key pair %v", err)
@@ -527,3 +527,64 @@ func TestGenerateKeyPair(t *testing.T) {
 
 	privateKeyRSA.Validate()
 }
+
+func TestGenerateKeyPairTypes(t *testing.T) {
+	for _, keyType := range []string{KeyTypeRSA, KeyTypeECDSA, KeyTypeED25519} {
+		privateKey, publicKey, err := GenerateKeyPair(keyType)
+		if err != nil {
+			t.Errorf("generate %s key pair %v", keyType, err)
+			continue
+		}
+
+		// Runner must be able to authenticate with the generated key
+		derived, err := PublicKey(privateKey)
+		if err != nil {
+			t.Errorf("parse %s private key %v", keyType, err)
+			continue
+		}
+
+		if derived != publicKey {
+			t.Errorf("%s public key mismatch expected %s actual %s",
+				keyType, publicKey, derived)
+		}
+	}
+
+	if _, _, err := GenerateKeyPair("dsa"); errors.Cause(err) != ErrUnknownKeyType {
+		t.Errorf("expected error %v actual %v", ErrUnknownKeyType, err)
+	}
+}
+
+func TestBootstrapKeys(t *testing.T) {
+	cfg := &steps.Config{}
+	cfg.Kube.SSHConfig.BootstrapKeyType = KeyTypeED25519
+
+	if err := BootstrapKeys(cfg); err != nil {
+		t.Fatalf("bootstrap keys %v", err)
+	}
+
+	if !strings.HasPrefix(cfg.Kube.SSHConfig.BootstrapPublicKey, "ssh-ed25519 ") {
+		t.Errorf("wrong public key type %s", cfg.Kube.SSHConfig.BootstrapPublicKey)
+	}
+
+	// Key supplied by user is kept
+	cfg = &steps.Config{}
+	cfg.Kube.SSHConfig.BootstrapPrivateKey = privateKeyBytes
+
+	if err := BootstrapKeys(cfg); err != nil {
+		t.Fatalf("bootstrap keys %v", err)
+	}
+
+	if cfg.Kube.SSHConfig.BootstrapPrivateKey != privateKeyBytes {
+		t.Errorf("supplied private key has been replaced")
+	}
+
+	if strings.TrimSpace(cfg.Kube.SSHConfig.BootstrapPublicKey) != expectedPublicKey {
+		t.Errorf("wrong public key expected %s actual %s",
+			expectedPublicKey, cfg.Kube.SSHConfig.BootstrapPublicKey)
+	}
+
+	cfg.Kube.SSHConfig.BootstrapPrivateKey = "invalid"
+	if err := BootstrapKeys(cfg); err == nil {
+		t.Errorf("error must not be nil for invalid key")
+	}
+}
@@ -142,7 +142,7 @@ func (h *TaskHandler) RestartTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task.Run(context.Background(), *task.Config, writer)
+	task.Run(context.Background(), task.Config, writer)
 	w.WriteHeader(http.StatusAccepted)
 }
 
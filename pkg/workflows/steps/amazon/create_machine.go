@@ -1,7 +1,10 @@
 package amazon
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"strconv"
@@ -76,6 +79,7 @@ func (s *StepCreateInstance) Run(ctx context.Context, w io.Writer, cfg *steps.Co
 		Size:     cfg.AWSConfig.InstanceType,
 		Provider: clouds.AWS,
 		State:    model.MachineStatePlanned,
+		Pool:     cfg.NodePool.Name,
 	}
 
 	// Update node state in cluster
@@ -156,6 +160,14 @@ func (s *StepCreateInstance) Run(ctx context.Context, w io.Writer, cfg *steps.Co
 		},
 	}
 
+	if cfg.UserData != "" {
+		userData, err := encodeUserData(cfg.UserData)
+		if err != nil {
+			return errors.Wrap(err, "encode user data")
+		}
+		runInstanceInput.UserData = aws.String(userData)
+	}
+
 	res, err := ec2Svc.RunInstancesWithContext(ctx, runInstanceInput)
 	if err != nil {
 		cfg.Node.State = model.MachineStateError
@@ -173,6 +185,7 @@ func (s *StepCreateInstance) Run(ctx context.Context, w io.Writer, cfg *steps.Co
 		Provider: clouds.AWS,
 		Size:     cfg.AWSConfig.InstanceType,
 		State:    model.MachineStateBuilding,
+		Pool:     cfg.NodePool.Name,
 	}
 
 	// Update node state in cluster
@@ -255,6 +268,22 @@ func (s *StepCreateInstance) Rollback(ctx context.Context, w io.Writer, cfg *ste
 	return nil
 }
 
+// encodeUserData compresses user data to fit into 16KB allowed by EC2,
+// cloud-init unpacks it on its own.
+func encodeUserData(userData string) (string, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+
+	if _, err := w.Write([]byte(userData)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
 func findInstanceWithPublicAddr(reservations []*ec2.Reservation) *ec2.Instance {
 	for _, r := range reservations {
 		for _, i := range r.Instances {
@@ -2,7 +2,10 @@ package amazon
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"io/ioutil"
 	"strings"
 	"testing"
 	"time"
@@ -334,3 +337,34 @@ func TestStepCreateInstance_Rollback(t *testing.T) {
 		t.Errorf("Unexpected error %v", err)
 	}
 }
+
+func TestEncodeUserData(t *testing.T) {
+	userData := strings.Repeat("sudo apt-get install -y docker-ce\n", 100)
+
+	encoded, err := encodeUserData(userData)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("decode base64 %v", err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip reader %v", err)
+	}
+
+	decoded, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read gzip %v", err)
+	}
+
+	if string(decoded) != userData {
+		t.Errorf("wrong user data %s", decoded)
+	}
+	if len(encoded) >= len(userData) {
+		t.Errorf("user data is not compressed")
+	}
+}
@@ -0,0 +1,107 @@
+package amazon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestInitDeregisterInstance(t *testing.T) {
+	InitDeregisterInstance(GetELB)
+
+	if s := steps.GetStep(DeregisterInstanceStepName); s == nil {
+		t.Errorf("Step %s not found", DeregisterInstanceStepName)
+	}
+}
+
+func TestDeregisterInstanceStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+
+		getSvcErr  error
+		externalLB string
+		internalLB string
+		deregErr   error
+
+		expectedCalls int
+		errMsg        string
+	}{
+		{
+			description: "Error getting ELB svc",
+			getSvcErr:   errors.New("error1"),
+			errMsg:      "error1",
+		},
+		{
+			description:   "error deregistering",
+			externalLB:    "external",
+			internalLB:    "internal",
+			deregErr:      errors.New("error2"),
+			expectedCalls: 1,
+			errMsg:        "error2",
+		},
+		{
+			description:   "imported kube without internal load balancer",
+			externalLB:    "external",
+			expectedCalls: 1,
+		},
+		{
+			description:   "success",
+			externalLB:    "external",
+			internalLB:    "internal",
+			expectedCalls: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+		svc := new(mockELBService)
+		svc.On("DeregisterInstancesFromLoadBalancerWithContext", mock.Anything,
+			mock.Anything, mock.Anything).Return(&elb.DeregisterInstancesFromLoadBalancerOutput{}, testCase.deregErr)
+
+		step := &DeregisterInstanceStep{
+			getLoadBalancerService: func(cfg steps.AWSConfig) (LoadBalancerDeregister, error) {
+				return svc, testCase.getSvcErr
+			},
+		}
+
+		config := &steps.Config{
+			Node: model.Machine{ID: "i-1234"},
+			AWSConfig: steps.AWSConfig{
+				ExternalLoadBalancerName: testCase.externalLB,
+				InternalLoadBalancerName: testCase.internalLB,
+			},
+		}
+
+		err := step.Run(context.Background(), &bytes.Buffer{}, config)
+
+		if err != nil && testCase.errMsg == "" {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+
+		if err != nil && !strings.Contains(err.Error(), testCase.errMsg) {
+			t.Errorf("Wrong error must contain %s actual %s",
+				testCase.errMsg, err.Error())
+			continue
+		}
+
+		svc.AssertNumberOfCalls(t, "DeregisterInstancesFromLoadBalancerWithContext", testCase.expectedCalls)
+	}
+}
+
+func TestDeregisterInstanceStep_Name(t *testing.T) {
+	step := &DeregisterInstanceStep{}
+
+	if step.Name() != DeregisterInstanceStepName {
+		t.Errorf("Wrong step name expected %s actual %s",
+			DeregisterInstanceStepName, step.Name())
+	}
+}
@@ -9,6 +9,7 @@ var (
 	ErrCreateSubnet   = errors.New("aws: create subnet")
 	ErrCreateInstance = errors.New("aws: create instance")
 	ErrImportKeyPair  = errors.New("aws: import keypair")
+	ErrKeyType        = errors.New("aws: unsupported key type")
 	ErrNoPublicIP     = errors.New("aws: no public IP assigned")
 	ErrDeleteCluster  = errors.New("aws: delete cluster")
 	ErrDeleteNode     = errors.New("aws: delete node")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -12,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/supergiant/control/pkg/distro"
 	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -59,8 +61,8 @@ func (s *FindAMIStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config) e
 	logrus.Debugf("Found image id %s", cfg.AWSConfig.ImageID)
 
 	if err != nil {
-		logrus.Errorf("[%s] - failed to find AMI for Ubuntu: %v",
-			s.Name(), err)
+		logrus.Errorf("[%s] - failed to find AMI for %s: %v",
+			s.Name(), cfg.Kube.OperatingSystem, err)
 		return errors.Wrap(err, "failed to find AMI")
 	}
 
@@ -92,6 +94,11 @@ func (*FindAMIStep) Rollback(context.Context, io.Writer, *steps.Config) error {
 }
 
 func (s *FindAMIStep) FindAMI(ctx context.Context, w io.Writer, finder ImageFinder, config *steps.Config) error {
+	d, err := distro.Get(config.Kube.OperatingSystem, config.Kube.OperatingSystemVersion)
+	if err != nil {
+		return err
+	}
+
 	// TODO: should it be configurable?
 	out, err := finder.DescribeImagesWithContext(ctx, &ec2.DescribeImagesInput{
 		Filters: []*ec2.Filter{
@@ -113,17 +120,17 @@ func (s *FindAMIStep) FindAMI(ctx context.Context, w io.Writer, finder ImageFind
 					aws.String("ebs"),
 				},
 			},
-			//Owner should be Canonical
+			//Owner should be vendor of the distro
 			{
 				Name: aws.String("owner-id"),
 				Values: []*string{
-					aws.String("099720109477"),
+					aws.String(d.AMI.Owner),
 				},
 			},
 			{
-				Name: aws.String("description"),
+				Name: aws.String("name"),
 				Values: []*string{
-					aws.String("Canonical, Ubuntu, 16.04*"),
+					aws.String(d.AMI.Name),
 				},
 			},
 		},
@@ -134,11 +141,13 @@ func (s *FindAMIStep) FindAMI(ctx context.Context, w io.Writer, finder ImageFind
 
 	log := util.GetLogger(w)
 
+	// Use the most recent image of the distro
+	sort.SliceStable(out.Images, func(i, j int) bool {
+		return aws.StringValue(out.Images[i].CreationDate) > aws.StringValue(out.Images[j].CreationDate)
+	})
+
 	for _, img := range out.Images {
-		if img.Description == nil {
-			continue
-		}
-		if strings.Contains(*img.Description, "UNSUPPORTED") {
+		if strings.Contains(aws.StringValue(img.Description), "UNSUPPORTED") {
 			continue
 		}
 
@@ -146,7 +155,7 @@ func (s *FindAMIStep) FindAMI(ctx context.Context, w io.Writer, finder ImageFind
 		config.AWSConfig.DeviceName = *img.RootDeviceName
 
 		logMessage := fmt.Sprintf("[%s] - using AMI (ID: %s) %s with root device name %s",
-			s.Name(), *img.ImageId, *img.Description, *img.RootDeviceName)
+			s.Name(), *img.ImageId, aws.StringValue(img.Name), *img.RootDeviceName)
 		log.Info(logMessage)
 		logrus.Info(logMessage)
 
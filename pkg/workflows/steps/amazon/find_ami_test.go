@@ -16,12 +16,14 @@ import (
 )
 
 type mockImageService struct {
+	input  *ec2.DescribeImagesInput
 	output *ec2.DescribeImagesOutput
 	err    error
 }
 
 func (m *mockImageService) DescribeImagesWithContext(ctx aws.Context, input *ec2.DescribeImagesInput,
 	opts ...request.Option) (*ec2.DescribeImagesOutput, error) {
+	m.input = input
 	return m.output, m.err
 }
 
@@ -111,6 +113,88 @@ func TestFindAMIStep_Run(t *testing.T) {
 	}
 }
 
+func TestFindAMIDistro(t *testing.T) {
+	testCases := []struct {
+		operatingSystem string
+		version         string
+		expectedOwner   string
+		expectedName    string
+		expectedErr     bool
+	}{
+		{
+			operatingSystem: "linux",
+			expectedOwner:   "099720109477",
+			expectedName:    "ubuntu/images/hvm-ssd/ubuntu-xenial-16.04-amd64-server-*",
+		},
+		{
+			operatingSystem: "centos",
+			version:         "7",
+			expectedOwner:   "679593333241",
+			expectedName:    "CentOS Linux 7 x86_64 HVM EBS *",
+		},
+		{
+			operatingSystem: "debian",
+			version:         "buster",
+			expectedOwner:   "136693071363",
+			expectedName:    "debian-10-amd64-*",
+		},
+		{
+			operatingSystem: "windows",
+			expectedErr:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		svc := &mockImageService{
+			output: &ec2.DescribeImagesOutput{
+				Images: []*ec2.Image{
+					{
+						ImageId:        aws.String("old"),
+						CreationDate:   aws.String("2019-01-01T00:00:00.000Z"),
+						RootDeviceName: aws.String("/dev/sda1"),
+					},
+					{
+						ImageId:        aws.String("new"),
+						CreationDate:   aws.String("2019-06-01T00:00:00.000Z"),
+						RootDeviceName: aws.String("/dev/sda1"),
+					},
+				},
+			},
+		}
+
+		config := &steps.Config{}
+		config.Kube.OperatingSystem = testCase.operatingSystem
+		config.Kube.OperatingSystemVersion = testCase.version
+
+		err := (&FindAMIStep{}).FindAMI(context.Background(), &buffer.Buffer{}, svc, config)
+		if testCase.expectedErr {
+			if err == nil {
+				t.Errorf("%s: error must not be nil", testCase.operatingSystem)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.operatingSystem, err)
+			continue
+		}
+
+		filters := map[string]string{}
+		for _, filter := range svc.input.Filters {
+			filters[aws.StringValue(filter.Name)] = aws.StringValue(filter.Values[0])
+		}
+
+		if filters["owner-id"] != testCase.expectedOwner || filters["name"] != testCase.expectedName {
+			t.Errorf("%s: wrong filters %v", testCase.operatingSystem, filters)
+		}
+
+		if config.AWSConfig.ImageID != "new" {
+			t.Errorf("%s: the most recent image must be used, actual %s",
+				testCase.operatingSystem, config.AWSConfig.ImageID)
+		}
+	}
+}
+
 func TestNewFindAMIStep(t *testing.T) {
 	step := NewFindAMIStep(GetEC2)
 
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
 
 	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/workflows/steps"
@@ -65,6 +66,10 @@ func (s *KeyPairStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config) e
 		return errors.New("Cluster ID is too short")
 	}
 
+	if err := checkKeyType(cfg.Kube.SSHConfig.BootstrapPublicKey); err != nil {
+		return errors.Wrap(err, ImportKeyPairStepName)
+	}
+
 	// NOTE(stgleb): Add unique part to key pair name that allows to
 	// create cluster with the same name and avoid name collision of key pairs.
 	bootstrapKeyPairName := util.MakeKeyName(fmt.Sprintf("%s-%s",
@@ -105,6 +110,22 @@ func (s *KeyPairStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config) e
 	return nil
 }
 
+// checkKeyType verifies that EC2 is able to import the public key,
+// only rsa and ed25519 keys are accepted by ImportKeyPair.
+func checkKeyType(publicKey string) error {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return errors.Wrap(err, "parse bootstrap public key")
+	}
+
+	switch key.Type() {
+	case ssh.KeyAlgoRSA, ssh.KeyAlgoED25519:
+		return nil
+	}
+
+	return errors.Wrap(ErrKeyType, key.Type())
+}
+
 func (s *KeyPairStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
 	return nil
 }
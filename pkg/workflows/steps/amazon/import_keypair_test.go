@@ -50,11 +50,17 @@ func (m *mockKeyPairSvc) WaitUntilKeyPairExists(req *ec2.DescribeKeyPairsInput)
 	return val
 }
 
+const (
+	ed25519PublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIA4PjaCZ6b0yCxBz4g5WNsF/rEbuBN7qdxyhg0cgJUFX user@host"
+	ecdsaPublicKey   = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBFiATlsZdVw8X61MTNb+dp6iYlQsK/lFlq4uJ77HLCHSCw7thCRRjnxmxgd/l/uiIuF7N/px2GIlB2rnYWrmvN4= root@vm"
+)
+
 func TestImportKeyPair_Run(t *testing.T) {
 	testCases := []struct {
 		description string
 		getSvcErr   error
 		clusterId   string
+		publicKey   string
 
 		importOut *ec2.ImportKeyPairOutput
 		importErr error
@@ -71,6 +77,18 @@ func TestImportKeyPair_Run(t *testing.T) {
 			clusterId:   "124",
 			errMsg:      "too short",
 		},
+		{
+			description: "ecdsa key",
+			clusterId:   "12345678",
+			publicKey:   ecdsaPublicKey,
+			errMsg:      ErrKeyType.Error(),
+		},
+		{
+			description: "invalid key",
+			clusterId:   "12345678",
+			publicKey:   "ssh-rsa",
+			errMsg:      "parse bootstrap public key",
+		},
 		{
 			description: "import error",
 			clusterId:   "12345678",
@@ -107,10 +125,17 @@ func TestImportKeyPair_Run(t *testing.T) {
 			mock.Anything).
 			Return(testCase.waitErr)
 
+		if testCase.publicKey == "" {
+			testCase.publicKey = ed25519PublicKey
+		}
+
 		config := &steps.Config{
 			Kube: model.Kube{
 				Name: "test",
 				ID:   testCase.clusterId,
+				SSHConfig: model.SSHConfig{
+					BootstrapPublicKey: testCase.publicKey,
+				},
 			},
 			AWSConfig: steps.AWSConfig{},
 		}
@@ -4,15 +4,25 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/runner"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
-const StepName = "apply"
+const (
+	StepName = "apply"
+
+	manifestPath = "/etc/supergiant/apply.yaml"
+)
+
+type Config struct {
+	Path string
+}
 
 type Step struct {
 	script *template.Template
@@ -41,7 +51,15 @@ func New(script *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.script, config.Runner, out, config.ApplyConfig)
+	err := config.Runner.Upload(ctx, strings.NewReader(config.ApplyConfig.Data),
+		runner.File{Path: manifestPath, Mode: 0600, Owner: "root:root"})
+	if err != nil {
+		return errors.Wrap(err, "upload manifest")
+	}
+
+	err = steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, Config{
+		Path: manifestPath,
+	})
 
 	if err != nil {
 		return errors.Wrap(err, "apply changes")
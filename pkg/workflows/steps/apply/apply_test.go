@@ -0,0 +1,74 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/templates"
+)
+
+type fakeRunner struct {
+	testutils.FakeTransfer
+
+	errMsg string
+}
+
+func (f *fakeRunner) Run(command *runner.Command) error {
+	if len(f.errMsg) > 0 {
+		return errors.New(f.errMsg)
+	}
+
+	_, err := io.Copy(command.Out, strings.NewReader(command.Script))
+	return err
+}
+
+func TestApply(t *testing.T) {
+	tpl := template.Must(template.New(StepName).Parse(templates.Default[StepName]))
+	r := &fakeRunner{}
+	out := &bytes.Buffer{}
+	manifest := "kind: Namespace\nmetadata:\n  name: \"$(rm -rf /)\"\n"
+
+	err := New(tpl).Run(context.Background(), out, &steps.Config{
+		Runner: r,
+		ApplyConfig: steps.ApplyConfig{
+			Data: manifest,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if r.Files[manifestPath] != manifest {
+		t.Errorf("wrong manifest expected %s actual %s", manifest, r.Files[manifestPath])
+	}
+
+	if strings.Contains(out.String(), "Namespace") {
+		t.Errorf("manifest must not be a part of the script %s", out.String())
+	}
+
+	if !strings.Contains(out.String(), "kubectl apply -f "+manifestPath) {
+		t.Errorf("apply command not found in %s", out.String())
+	}
+}
+
+func TestApplyError(t *testing.T) {
+	errMsg := "error has occurred"
+	tpl := template.Must(template.New(StepName).Parse(""))
+
+	err := New(tpl).Run(context.Background(), &bytes.Buffer{}, &steps.Config{
+		Runner: &fakeRunner{errMsg: errMsg},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), errMsg) {
+		t.Errorf("expected error %s actual %v", errMsg, err)
+	}
+}
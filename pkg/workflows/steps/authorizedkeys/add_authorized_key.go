@@ -3,12 +3,14 @@ package authorizedkeys
 import (
 	"context"
 	"fmt"
-	"github.com/supergiant/control/pkg/clouds"
 	"io"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/workflows/steps"
@@ -40,12 +42,17 @@ func (s *Step) Run(ctx context.Context, w io.Writer, cfg *steps.Config) error {
 
 	log.Infof("[%s] - adding user's public key to the node", s.Name())
 	if cfg == nil || cfg.Kube.SSHConfig.PublicKey != "" {
-		err := steps.RunTemplate(ctx, s.script, cfg.Runner, w, struct {
+		publicKey, err := authorizedKey(cfg.Kube.SSHConfig.PublicKey)
+		if err != nil {
+			return errors.Wrap(err, "add authorized key step: parse public key")
+		}
+
+		err = steps.RunTemplate(ctx, steps.Template(cfg, s.script), cfg.Runner, w, struct {
 			PublicKey          string
 			BootstrapPublicKey string
 			UserName           string
 		}{
-			PublicKey:          cfg.Kube.SSHConfig.PublicKey,
+			PublicKey:          publicKey,
 			BootstrapPublicKey: cfg.Kube.SSHConfig.BootstrapPublicKey,
 			UserName:           clouds.OSUser,
 		})
@@ -59,6 +66,18 @@ func (s *Step) Run(ctx context.Context, w io.Writer, cfg *steps.Config) error {
 	return nil
 }
 
+// authorizedKey checks that key is a valid public key of any type supported
+// by ssh (rsa, ecdsa, ed25519) and returns it without the comment, so
+// nothing but the key itself gets to the script.
+func authorizedKey(key string) (string, error) {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))), nil
+}
+
 func (*Step) Name() string {
 	return StepName
 }
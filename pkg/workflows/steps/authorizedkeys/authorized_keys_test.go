@@ -15,10 +15,13 @@ import (
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
@@ -94,7 +97,7 @@ func TestAuthorizedKeysErr(t *testing.T) {
 		t.Errorf("Unexpected error %v", err)
 	}
 
-	cfg.Kube.SSHConfig.PublicKey = "key"
+	cfg.Kube.SSHConfig.PublicKey = ed25519PublicKey
 	cfg.Runner = r
 	cfg.AddMaster(&model.Machine{
 		State:     model.MachineStateActive,
@@ -112,6 +115,61 @@ func TestAuthorizedKeysErr(t *testing.T) {
 	}
 }
 
+const (
+	ed25519PublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIA4PjaCZ6b0yCxBz4g5WNsF/rEbuBN7qdxyhg0cgJUFX user@host"
+	ecdsaPublicKey   = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBFiATlsZdVw8X61MTNb+dp6iYlQsK/lFlq4uJ77HLCHSCw7thCRRjnxmxgd/l/uiIuF7N/px2GIlB2rnYWrmvN4= root@vm"
+)
+
+func TestAuthorizedKeysTypes(t *testing.T) {
+	tpl, err := template.New(StepName).Parse("{{ .PublicKey }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		key      string
+		expected string
+		hasErr   bool
+	}{
+		{
+			key:      ed25519PublicKey,
+			expected: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIA4PjaCZ6b0yCxBz4g5WNsF/rEbuBN7qdxyhg0cgJUFX",
+		},
+		{
+			key:      ecdsaPublicKey,
+			expected: "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBFiATlsZdVw8X61MTNb+dp6iYlQsK/lFlq4uJ77HLCHSCw7thCRRjnxmxgd/l/uiIuF7N/px2GIlB2rnYWrmvN4=",
+		},
+		{
+			key:    "ssh-rsa AAAA\nEOF\"; rm -rf /",
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		cfg, _ := steps.NewConfig("", "", profile.Profile{})
+		cfg.Kube.SSHConfig.PublicKey = testCase.key
+		cfg.Runner = &fakeRunner{}
+		output := &bytes.Buffer{}
+
+		err := (&Step{tpl}).Run(context.Background(), output, cfg)
+		if testCase.hasErr {
+			if err == nil {
+				t.Errorf("error must not be nil for key %s", testCase.key)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+			continue
+		}
+
+		if !strings.HasSuffix(output.String(), testCase.expected) {
+			t.Errorf("key %s not found in %s", testCase.expected, output.String())
+		}
+	}
+}
+
 func TestStepName(t *testing.T) {
 	s := Step{}
 
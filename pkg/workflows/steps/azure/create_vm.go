@@ -2,6 +2,7 @@ package azure
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"strconv"
@@ -15,6 +16,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/distro"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/util"
@@ -66,6 +68,7 @@ func (s *CreateVMStep) Run(ctx context.Context, output io.Writer, config *steps.
 		Size:     config.AzureConfig.VMSize,
 		Provider: clouds.Azure,
 		State:    model.MachineStatePlanned,
+		Pool:     config.NodePool.Name,
 	}
 
 	// Update node state in cluster
@@ -152,6 +155,12 @@ func (s *CreateVMStep) setupVM(ctx context.Context, config *steps.Config, vmName
 	}
 
 	volumeSize32 := int32(volumeSize)
+
+	image, err := imageReference(config.Kube)
+	if err != nil {
+		return err
+	}
+
 	vmClient := s.sdk.VMClient(config.GetAzureAuthorizer(), config.AzureConfig.SubscriptionID)
 	f, err := vmClient.CreateOrUpdate(
 		ctx,
@@ -167,12 +176,7 @@ func (s *CreateVMStep) setupVM(ctx context.Context, config *steps.Config, vmName
 					VMSize: compute.VirtualMachineSizeTypes(config.AzureConfig.VMSize),
 				},
 				StorageProfile: &compute.StorageProfile{
-					ImageReference: &compute.ImageReference{
-						Publisher: to.StringPtr(UbuntuPublisher),
-						Offer:     to.StringPtr(UbuntuOffer),
-						Sku:       to.StringPtr(UbuntuSKU),
-						Version:   to.StringPtr("latest"),
-					},
+					ImageReference: image,
 					OsDisk: &compute.OSDisk{
 						CreateOption: compute.DiskCreateOptionTypesFromImage,
 						Caching:      compute.CachingTypesReadWrite,
@@ -186,6 +190,7 @@ func (s *CreateVMStep) setupVM(ctx context.Context, config *steps.Config, vmName
 				OsProfile: &compute.OSProfile{
 					ComputerName:  to.StringPtr(vmName),
 					AdminUsername: to.StringPtr(clouds.OSUser),
+					CustomData:    toCustomData(config.UserData),
 					LinuxConfiguration: &compute.LinuxConfiguration{
 						DisablePasswordAuthentication: to.BoolPtr(true),
 						SSH: &compute.SSHConfiguration{
@@ -384,3 +389,37 @@ func getPrivateIP(nic network.Interface) string {
 	}
 	return ""
 }
+
+// imageReference returns image of the kube distro, kubes created before
+// distro could be chosen keep Ubuntu 18.04.
+func imageReference(k model.Kube) (*compute.ImageReference, error) {
+	image := distro.AzureImage{
+		Publisher: UbuntuPublisher,
+		Offer:     UbuntuOffer,
+		SKU:       UbuntuSKU,
+	}
+
+	if k.OperatingSystem != "" && k.OperatingSystem != distro.Linux {
+		d, err := distro.Get(k.OperatingSystem, k.OperatingSystemVersion)
+		if err != nil {
+			return nil, err
+		}
+		image = d.AzureImage
+	}
+
+	return &compute.ImageReference{
+		Publisher: to.StringPtr(image.Publisher),
+		Offer:     to.StringPtr(image.Offer),
+		Sku:       to.StringPtr(image.SKU),
+		Version:   to.StringPtr("latest"),
+	}, nil
+}
+
+// toCustomData encodes user data as azure expects it, machines without
+// user data have no custom data.
+func toCustomData(userData string) *string {
+	if userData == "" {
+		return nil
+	}
+	return to.StringPtr(base64.StdEncoding.EncodeToString([]byte(userData)))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -40,3 +41,54 @@ func TestCreateVMStep_Run(t *testing.T) {
 		require.Equalf(t, tc.expectedErr, errors.Cause(err), "TC: %s", tc.name)
 	}
 }
+
+func TestImageReference(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		operatingSystem string
+		version         string
+		expectedSKU     string
+		expectedErr     bool
+	}{
+		{
+			name:            "legacy",
+			operatingSystem: "linux",
+			version:         "xenial",
+			expectedSKU:     UbuntuSKU,
+		},
+		{
+			name:            "ubuntu",
+			operatingSystem: "ubuntu",
+			version:         "xenial",
+			expectedSKU:     "16.04-LTS",
+		},
+		{
+			name:            "centos",
+			operatingSystem: "centos",
+			expectedSKU:     "7.5",
+		},
+		{
+			name:            "unsupported",
+			operatingSystem: "centos",
+			version:         "xenial",
+			expectedErr:     true,
+		},
+	} {
+		image, err := imageReference(model.Kube{
+			OperatingSystem:        tc.operatingSystem,
+			OperatingSystemVersion: tc.version,
+		})
+		if tc.expectedErr {
+			require.NotNil(t, err, tc.name)
+			continue
+		}
+
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.expectedSKU, *image.Sku, tc.name)
+	}
+}
+
+func TestToCustomData(t *testing.T) {
+	require.Nil(t, toCustomData(""))
+	require.Equal(t, "IyEvYmluL2Jhc2g=", *toCustomData("#!/bin/bash"))
+}
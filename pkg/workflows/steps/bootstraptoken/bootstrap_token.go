@@ -52,7 +52,7 @@ func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 
 		logrus.Debug("Create bootstrap token")
 		// NOTE(stgleb): Reuse KubeadmConfig.Token field to avoid
-		err = steps.RunTemplate(ctx, s.script, config.Runner, out, struct {
+		err = steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, struct {
 			IsBootstrap    bool
 			Token          string
 			CertificateKey string
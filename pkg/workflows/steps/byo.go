@@ -0,0 +1,78 @@
+package steps
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/profile"
+)
+
+var (
+	ErrNoMasters   = errors.New("no master hosts")
+	ErrHostAddress = errors.New("host has no address")
+	ErrHostKey     = errors.New("host has no ssh key")
+	ErrBYOMode     = errors.New("bootstrap mode is not supported for machines brought by user")
+)
+
+// PublicAddress is an address ssh connections are made to, machines
+// that have only one address use it for everything.
+func (c BYOConfig) PublicAddress() string {
+	if c.PublicIP != "" {
+		return c.PublicIP
+	}
+	return c.PrivateIP
+}
+
+// PrivateAddress is an address machines of the kube talk to each other by.
+func (c BYOConfig) PrivateAddress() string {
+	if c.PrivateIP != "" {
+		return c.PrivateIP
+	}
+	return c.PublicIP
+}
+
+// validateHosts checks that every host brought by user has an address and
+// an ssh key, the hosts are reached over ssh by byo.CheckHostsStep.
+func validateHosts(p profile.Profile) error {
+	if len(p.MasterProfiles) == 0 {
+		return ErrNoMasters
+	}
+
+	// machines exist already so there is no user data to pass
+	if p.BootstrapMode == bootstrap.CloudInit {
+		return errors.Wrap(ErrBYOMode, p.BootstrapMode)
+	}
+
+	hosts := append(append([]profile.NodeProfile{}, p.MasterProfiles...), p.NodesProfiles...)
+	for i, host := range hosts {
+		if host[clouds.BYOPublicIP] == "" && host[clouds.BYOPrivateIP] == "" {
+			return errors.Wrapf(ErrHostAddress, "host %d", i)
+		}
+		if host[clouds.BYOSSHKey] == "" && p.BootstrapPrivateKey == "" {
+			return errors.Wrapf(ErrHostKey, "host %d", i)
+		}
+	}
+
+	return nil
+}
+
+// apiEndpoints returns external and internal addresses of kube api of
+// machines brought by user, they are addresses of the bootstrap master
+// unless there is an endpoint in front of masters.
+func apiEndpoints(p profile.Profile) (string, string) {
+	if p.Provider != clouds.BYO || len(p.MasterProfiles) == 0 {
+		return "", ""
+	}
+
+	if endpoint := p.CloudSpecificSettings[clouds.BYOAPIEndpoint]; endpoint != "" {
+		return endpoint, endpoint
+	}
+
+	master := BYOConfig{
+		PublicIP:  p.MasterProfiles[0][clouds.BYOPublicIP],
+		PrivateIP: p.MasterProfiles[0][clouds.BYOPrivateIP],
+	}
+
+	return master.PublicAddress(), master.PrivateAddress()
+}
@@ -0,0 +1,180 @@
+package byo
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/dry"
+	"github.com/supergiant/control/pkg/runner/local"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func newConfig(t *testing.T) *steps.Config {
+	cfg, err := steps.NewConfig("test", "", profile.Profile{
+		Provider: clouds.BYO,
+		MasterProfiles: []profile.NodeProfile{
+			{clouds.BYOPublicIP: "1.2.3.4", clouds.BYOPrivateIP: "10.0.0.4"},
+		},
+		NodesProfiles: []profile.NodeProfile{
+			{clouds.BYOPrivateIP: "10.0.0.5", clouds.BYOSSHUser: "ubuntu", clouds.BYOSSHKey: "node key"},
+		},
+		BootstrapPrivateKey: "key",
+	})
+	if err != nil {
+		t.Fatalf("new config: %v", err)
+	}
+
+	cfg.TaskID = "1234abcd"
+	return cfg
+}
+
+func TestRegisterMachine(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.BYOConfig = steps.BYOConfig{
+		PrivateIP: "10.0.0.5",
+		SSHUser:   "ubuntu",
+		SSHKey:    "node key",
+	}
+
+	s := NewRegisterMachineStep()
+	if err := s.Run(context.Background(), &bytes.Buffer{}, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	n := <-cfg.NodeChan()
+	if n.Name != "test-node-1234" || n.Role != model.RoleNode || n.State != model.MachineStateProvisioning {
+		t.Errorf("wrong machine %v", n)
+	}
+
+	// the only address is used to connect to the machine
+	if n.PublicIp != "10.0.0.5" || n.PrivateIp != "10.0.0.5" {
+		t.Errorf("wrong addresses %s %s", n.PublicIp, n.PrivateIp)
+	}
+
+	if n.SSHUser != "ubuntu" || n.SSHKey != "node key" {
+		t.Errorf("wrong ssh credentials %s %s", n.SSHUser, n.SSHKey)
+	}
+
+	if len(cfg.GetNodes()) != 1 {
+		t.Errorf("machine has not been added to nodes")
+	}
+
+	cfg.BYOConfig = steps.BYOConfig{}
+	if err := s.Run(context.Background(), &bytes.Buffer{}, cfg); errors.Cause(err) != steps.ErrHostAddress {
+		t.Errorf("wrong error expected %v actual %v", steps.ErrHostAddress, err)
+	}
+}
+
+func TestCheckHosts(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.Kube.Masters = map[string]*model.Machine{
+		"master": {Name: "master", PublicIp: "1.2.3.4", PrivateIp: "10.0.0.4"},
+	}
+	cfg.Kube.Nodes = map[string]*model.Machine{
+		"node": {Name: "node", PrivateIp: "10.0.0.5"},
+	}
+
+	hosts := make([]string, 0)
+	s := &CheckHostsStep{
+		getRunner: func(m model.Machine, config *steps.Config) (runner.Runner, error) {
+			hosts = append(hosts, m.PublicIp)
+			return local.NewRunner(), nil
+		},
+	}
+
+	out := &bytes.Buffer{}
+	if err := s.Run(context.Background(), out, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if strings.Join(hosts, ",") != "1.2.3.4,10.0.0.5" {
+		t.Errorf("wrong hosts have been checked %v", hosts)
+	}
+
+	if !strings.Contains(out.String(), "10.0.0.5: ") || !strings.Contains(out.String(), "is reachable") {
+		t.Errorf("host check is not in output %s", out.String())
+	}
+
+	s.getRunner = func(model.Machine, *steps.Config) (runner.Runner, error) {
+		return &testutils.MockRunner{Err: errors.New("connection refused")}, nil
+	}
+
+	if err := s.Run(context.Background(), &bytes.Buffer{}, cfg); err == nil {
+		t.Errorf("unreachable host must fail the check")
+	}
+}
+
+func TestResetMachine(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+	tpl, err := templatemanager.GetTemplate(resetTemplateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newConfig(t)
+	cfg.Node = model.Machine{Name: "test-node-1234", PublicIp: "10.0.0.5"}
+
+	r := dry.NewDryRunner()
+	s := NewResetMachineStep(tpl)
+	s.getRunner = func(m model.Machine, config *steps.Config) (runner.Runner, error) {
+		if m.Name != cfg.Node.Name {
+			t.Errorf("wrong machine is reset %s", m.Name)
+		}
+		return r, nil
+	}
+
+	if err := s.Run(context.Background(), &bytes.Buffer{}, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if !strings.Contains(r.GetOutput(), "kubeadm reset -f") {
+		t.Errorf("kubeadm is not reset %s", r.GetOutput())
+	}
+}
+
+func TestResetCluster(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+	tpl, err := templatemanager.GetTemplate(resetTemplateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newConfig(t)
+	cfg.AddMaster(&model.Machine{ID: "1.2.3.4", Name: "test-master-1234"})
+	cfg.AddNode(&model.Machine{ID: "10.0.0.5", Name: "test-node-5678"})
+	cfg.AddNode(&model.Machine{ID: "10.0.0.6", Name: "test-node-9012"})
+
+	reset := make([]string, 0)
+	s := NewResetClusterStep(tpl)
+	s.getRunner = func(m model.Machine, config *steps.Config) (runner.Runner, error) {
+		reset = append(reset, m.Name)
+		if m.Name == "test-node-5678" {
+			return &testutils.MockRunner{Err: errors.New("connection refused")}, nil
+		}
+		return dry.NewDryRunner(), nil
+	}
+
+	err = s.Run(context.Background(), &bytes.Buffer{}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "test-node-5678") {
+		t.Errorf("failed machine must be reported, actual %v", err)
+	}
+
+	// machines that are left must be reset anyway, masters go last
+	if len(reset) != 3 || reset[2] != "test-master-1234" {
+		t.Errorf("wrong machines have been reset %v", reset)
+	}
+}
@@ -0,0 +1,78 @@
+package byo
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// CheckHostsStep makes sure that every host brought by user is reachable
+// before provisioning of the kube starts.
+type CheckHostsStep struct {
+	getRunner runnerFn
+}
+
+func NewCheckHostsStep() *CheckHostsStep {
+	return &CheckHostsStep{
+		getRunner: sshRunner,
+	}
+}
+
+func (s *CheckHostsStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if config.DryRun {
+		return nil
+	}
+
+	for _, machines := range []map[string]*model.Machine{config.Kube.Masters, config.Kube.Nodes} {
+		for _, m := range machines {
+			if err := s.check(ctx, out, *m, config); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *CheckHostsStep) check(ctx context.Context, out io.Writer, m model.Machine, config *steps.Config) error {
+	// planned machines have only addresses the hosts were registered with
+	m.PublicIp = steps.BYOConfig{PublicIP: m.PublicIp, PrivateIP: m.PrivateIp}.PublicAddress()
+
+	r, err := s.getRunner(m, config)
+	if err != nil {
+		return errors.Wrapf(err, "check host %s", m.PublicIp)
+	}
+
+	cmd, err := runner.NewCommand(ctx, fmt.Sprintf("echo %s: $(hostname) is reachable", m.PublicIp), out, out)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Run(cmd); err != nil {
+		return errors.Wrapf(err, "check host %s", m.PublicIp)
+	}
+
+	return nil
+}
+
+func (s *CheckHostsStep) Name() string {
+	return CheckHostsStepName
+}
+
+func (s *CheckHostsStep) Description() string {
+	return "Check that hosts brought by user are reachable over ssh"
+}
+
+func (s *CheckHostsStep) Depends() []string {
+	return nil
+}
+
+func (s *CheckHostsStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package byo
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/ssh"
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	CheckHostsStepName      = "byo_check_hosts"
+	RegisterMachineStepName = "byo_register_machine"
+	ResetMachineStepName    = "byo_reset_machine"
+	ResetClusterStepName    = "byo_reset_cluster"
+
+	resetTemplateName = "kubeadm_reset"
+)
+
+type runnerFn func(model.Machine, *steps.Config) (runner.Runner, error)
+
+func Init() {
+	tpl, err := tm.GetTemplate(resetTemplateName)
+
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", resetTemplateName))
+	}
+
+	steps.RegisterStep(CheckHostsStepName, NewCheckHostsStep())
+	steps.RegisterStep(RegisterMachineStepName, NewRegisterMachineStep())
+	steps.RegisterStep(ResetMachineStepName, NewResetMachineStep(tpl))
+	steps.RegisterStep(ResetClusterStepName, NewResetClusterStep(tpl))
+}
+
+// sshRunner connects to the machine with its own credentials when
+// it has them and with ones of the kube otherwise.
+func sshRunner(m model.Machine, config *steps.Config) (runner.Runner, error) {
+	r, err := ssh.RunnerFor(steps.SSHConfigFor(config.Kube.SSHConfig, m), config.SSHPool)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ssh to %s", m.Name)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,80 @@
+package byo
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// RegisterMachineStep adds machine brought by user to the kube, it is done
+// instead of creating the machine in a cloud.
+type RegisterMachineStep struct{}
+
+func NewRegisterMachineStep() *RegisterMachineStep {
+	return &RegisterMachineStep{}
+}
+
+func (s *RegisterMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if config.DryRun {
+		return nil
+	}
+
+	host := config.BYOConfig
+
+	if host.PublicAddress() == "" {
+		return errors.Wrapf(steps.ErrHostAddress, "register machine of task %s", config.TaskID)
+	}
+
+	config.Node = model.Machine{
+		// address is the only thing that identifies the machine
+		ID:        host.PublicAddress(),
+		TaskID:    config.TaskID,
+		Role:      model.ToRole(config.IsMaster),
+		CreatedAt: time.Now().Unix(),
+		Provider:  clouds.BYO,
+		PublicIp:  host.PublicAddress(),
+		PrivateIp: host.PrivateAddress(),
+		State:     model.MachineStateProvisioning,
+		Name:      util.MakeNodeName(config.Kube.Name, config.TaskID, config.IsMaster),
+		SSHUser:   host.SSHUser,
+		SSHKey:    host.SSHKey,
+		Pool:      config.NodePool.Name,
+	}
+
+	// Update node state in cluster
+	config.NodeChan() <- config.Node
+
+	if config.IsMaster {
+		config.AddMaster(&config.Node)
+	} else {
+		config.AddNode(&config.Node)
+	}
+
+	logrus.Infof("Machine %s has been registered", config.Node.Name)
+
+	return nil
+}
+
+func (s *RegisterMachineStep) Name() string {
+	return RegisterMachineStepName
+}
+
+func (s *RegisterMachineStep) Description() string {
+	return "Register machine brought by user"
+}
+
+func (s *RegisterMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *RegisterMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,111 @@
+package byo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// ResetMachineStep reverts what kubeadm has done to the machine, machines
+// brought by user are left running when they are deleted from the kube.
+type ResetMachineStep struct {
+	script    *template.Template
+	getRunner runnerFn
+}
+
+func NewResetMachineStep(script *template.Template) *ResetMachineStep {
+	return &ResetMachineStep{
+		script:    script,
+		getRunner: sshRunner,
+	}
+}
+
+func (s *ResetMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	return reset(ctx, out, config, config.Node, s.script, s.getRunner)
+}
+
+func (s *ResetMachineStep) Name() string {
+	return ResetMachineStepName
+}
+
+func (s *ResetMachineStep) Description() string {
+	return "Reset kubeadm on the machine brought by user"
+}
+
+func (s *ResetMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *ResetMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+// ResetClusterStep resets every machine of the kube, nodes go first.
+type ResetClusterStep struct {
+	script    *template.Template
+	getRunner runnerFn
+}
+
+func NewResetClusterStep(script *template.Template) *ResetClusterStep {
+	return &ResetClusterStep{
+		script:    script,
+		getRunner: sshRunner,
+	}
+}
+
+func (s *ResetClusterStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	var failed []string
+
+	for _, machines := range []map[string]*model.Machine{config.GetNodes(), config.GetMasters()} {
+		for _, m := range machines {
+			if err := reset(ctx, out, config, *m, s.script, s.getRunner); err != nil {
+				// the rest of machines are reset anyway
+				fmt.Fprintf(out, "%v\n", err)
+				failed = append(failed, m.Name)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("reset machines %v", failed)
+	}
+
+	return nil
+}
+
+func (s *ResetClusterStep) Name() string {
+	return ResetClusterStepName
+}
+
+func (s *ResetClusterStep) Description() string {
+	return "Reset kubeadm on all machines brought by user"
+}
+
+func (s *ResetClusterStep) Depends() []string {
+	return nil
+}
+
+func (s *ResetClusterStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func reset(ctx context.Context, out io.Writer, config *steps.Config, m model.Machine,
+	script *template.Template, getRunner runnerFn) error {
+	r, err := getRunner(m, config)
+	if err != nil {
+		return errors.Wrapf(err, "reset machine %s", m.Name)
+	}
+
+	err = steps.RunTemplate(ctx, steps.Template(config, script), r, out, m)
+	if err != nil {
+		return errors.Wrapf(err, "reset machine %s", m.Name)
+	}
+
+	return nil
+}
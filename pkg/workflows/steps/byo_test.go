@@ -0,0 +1,110 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/profile"
+)
+
+func TestNewConfigBYO(t *testing.T) {
+	testCases := []struct {
+		description string
+		profile     profile.Profile
+
+		expectedErr      error
+		expectedExternal string
+		expectedInternal string
+	}{
+		{
+			description: "no masters",
+			profile: profile.Profile{
+				NodesProfiles: []profile.NodeProfile{{clouds.BYOPublicIP: "1.2.3.5"}},
+			},
+			expectedErr: ErrNoMasters,
+		},
+		{
+			description: "no address",
+			profile: profile.Profile{
+				MasterProfiles: []profile.NodeProfile{{clouds.BYOSSHKey: "key"}},
+			},
+			expectedErr: ErrHostAddress,
+		},
+		{
+			description: "no key",
+			profile: profile.Profile{
+				MasterProfiles: []profile.NodeProfile{{clouds.BYOPublicIP: "1.2.3.4"}},
+			},
+			expectedErr: ErrHostKey,
+		},
+		{
+			description: "cloud-init",
+			profile: profile.Profile{
+				MasterProfiles:      []profile.NodeProfile{{clouds.BYOPublicIP: "1.2.3.4"}},
+				BootstrapPrivateKey: "key",
+				BootstrapMode:       bootstrap.CloudInit,
+			},
+			expectedErr: ErrBYOMode,
+		},
+		{
+			description: "bootstrap master",
+			profile: profile.Profile{
+				MasterProfiles: []profile.NodeProfile{
+					{
+						clouds.BYOPublicIP:  "1.2.3.4",
+						clouds.BYOPrivateIP: "10.0.0.4",
+						clouds.BYOSSHKey:    "key",
+					},
+				},
+				NodesProfiles: []profile.NodeProfile{
+					{
+						clouds.BYOPrivateIP: "10.0.0.5",
+						clouds.BYOSSHKey:    "key",
+					},
+				},
+			},
+			expectedExternal: "1.2.3.4",
+			expectedInternal: "10.0.0.4",
+		},
+		{
+			description: "api endpoint",
+			profile: profile.Profile{
+				MasterProfiles: []profile.NodeProfile{{clouds.BYOPrivateIP: "10.0.0.4"}},
+				CloudSpecificSettings: profile.CloudSpecificSettings{
+					clouds.BYOAPIEndpoint: "api.example.com",
+				},
+				BootstrapPrivateKey: "key",
+			},
+			expectedExternal: "api.example.com",
+			expectedInternal: "api.example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc.profile.Provider = clouds.BYO
+
+		cfg, err := NewConfig("test", "", tc.profile)
+
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if cfg.Kube.ExternalDNSName != tc.expectedExternal {
+			t.Errorf("%s: wrong external address expected %s actual %s",
+				tc.description, tc.expectedExternal, cfg.Kube.ExternalDNSName)
+		}
+
+		if cfg.Kube.InternalDNSName != tc.expectedInternal {
+			t.Errorf("%s: wrong internal address expected %s actual %s",
+				tc.description, tc.expectedInternal, cfg.Kube.InternalDNSName)
+		}
+	}
+}
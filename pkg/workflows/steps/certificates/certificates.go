@@ -4,26 +4,46 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/runner"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
-const StepName = "certificates"
+const (
+	StepName = "certificates"
+	// CAKeyStepName uploads CA key over ssh to the bootstrap master
+	// of a kube bootstrapped with cloud-init.
+	CAKeyStepName = "certificates_ca_key"
+)
+
+const (
+	caCertPath = "/etc/kubernetes/pki/ca.crt"
+	caKeyPath  = "/etc/kubernetes/pki/ca.key"
+)
 
 type Config struct {
 	IsBootstrap bool
-	CACert      string
-	CAKey       string
+	// WaitCAKey is set when CA key is not put to user data,
+	// the script waits for control to upload it.
+	WaitCAKey bool
 }
 
 type Step struct {
 	template *template.Template
 }
 
+// upload is a file the step writes on the machine.
+type upload struct {
+	content string
+	file    runner.File
+}
+
 func (s *Step) Rollback(context.Context, io.Writer, *steps.Config) error {
 	return nil
 }
@@ -35,6 +55,7 @@ func Init() {
 	}
 
 	steps.RegisterStep(StepName, New(tpl))
+	steps.RegisterStep(CAKeyStepName, &CAKeyStep{})
 }
 
 func New(tpl *template.Template) *Step {
@@ -44,11 +65,39 @@ func New(tpl *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.template, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(ctx, steps.Template(config, s.template), config.Runner, out, toStepCfg(config))
 	if err != nil {
 		return errors.Wrap(err, "write certificates step")
 	}
 
+	if !config.IsBootstrap {
+		return nil
+	}
+
+	// Use CA generated on control side
+	files := []upload{
+		{
+			content: config.Kube.Auth.CACert,
+			file:    runner.File{Path: caCertPath, Mode: 0644, Owner: "root:root"},
+		},
+	}
+
+	// Steps of cloud-init are rendered into user data that can be read
+	// from metadata of the machine, CA key is uploaded by CAKeyStep
+	if config.Kube.BootstrapMode != bootstrap.CloudInit {
+		files = append(files, upload{
+			content: config.Kube.Auth.CAKey,
+			file:    runner.File{Path: caKeyPath, Mode: 0600, Owner: "root:root"},
+		})
+	}
+
+	for _, f := range files {
+		err := config.Runner.Upload(ctx, strings.NewReader(f.content), f.file)
+		if err != nil {
+			return errors.Wrapf(err, "write certificates step: upload %s", f.file.Path)
+		}
+	}
+
 	return nil
 }
 
@@ -67,7 +116,40 @@ func (s *Step) Depends() []string {
 func toStepCfg(c *steps.Config) Config {
 	return Config{
 		IsBootstrap: c.IsBootstrap,
-		CACert:      c.Kube.Auth.CACert,
-		CAKey:       c.Kube.Auth.CAKey,
+		WaitCAKey:   c.IsBootstrap && c.Kube.BootstrapMode == bootstrap.CloudInit,
+	}
+}
+
+// CAKeyStep uploads CA key to the bootstrap master, nodes and masters
+// that join the kube never get the key.
+type CAKeyStep struct{}
+
+func (s *CAKeyStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if !config.IsBootstrap {
+		return nil
+	}
+
+	err := config.Runner.Upload(ctx, strings.NewReader(config.Kube.Auth.CAKey),
+		runner.File{Path: caKeyPath, Mode: 0600, Owner: "root:root"})
+	if err != nil {
+		return errors.Wrapf(err, "upload %s", caKeyPath)
 	}
+
+	return nil
+}
+
+func (s *CAKeyStep) Name() string {
+	return CAKeyStepName
+}
+
+func (s *CAKeyStep) Description() string {
+	return "Upload CA key to the bootstrap master"
+}
+
+func (s *CAKeyStep) Depends() []string {
+	return nil
+}
+
+func (s *CAKeyStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
 }
@@ -10,16 +10,21 @@ import (
 	"text/template"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
 
+	"github.com/supergiant/control/pkg/bootstrap"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/pki"
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
@@ -114,6 +119,64 @@ func TestWriteCertificates(t *testing.T) {
 	output.Reset()
 }
 
+func TestWriteCertificatesCloudInit(t *testing.T) {
+	if err := templatemanager.Init("../../../../templates"); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	r := &fakeRunner{}
+	output := new(bytes.Buffer)
+	cfg := &steps.Config{
+		IsBootstrap: true,
+		IsMaster:    true,
+		Kube: model.Kube{
+			BootstrapMode: bootstrap.CloudInit,
+			Auth: model.Auth{
+				CACert: "ca-cert",
+				CAKey:  "ca-key",
+			},
+		},
+		Runner: r,
+	}
+
+	err := New(tpl).Run(context.Background(), output, cfg)
+	require.Nil(t, err)
+
+	require.Equal(t, "ca-cert", r.Files[caCertPath])
+	_, uploaded := r.Files[caKeyPath]
+	require.False(t, uploaded, "CA key must not be a part of user data")
+	require.Contains(t, output.String(), "sudo test -f "+caKeyPath)
+
+	err = (&CAKeyStep{}).Run(context.Background(), output, cfg)
+	require.Nil(t, err)
+	require.Equal(t, "ca-key", r.Files[caKeyPath])
+}
+
+func TestCAKeyStepNode(t *testing.T) {
+	r := &fakeRunner{}
+	cfg := &steps.Config{
+		IsMaster: true,
+		Kube: model.Kube{
+			Auth: model.Auth{CAKey: "ca-key"},
+		},
+		Runner: r,
+	}
+
+	err := (&CAKeyStep{}).Run(context.Background(), ioutil.Discard, cfg)
+	require.Nil(t, err)
+	require.Empty(t, r.Files, "CA key is uploaded to the bootstrap master only")
+
+	r.Err = errors.New("upload")
+	cfg.IsBootstrap = true
+	err = (&CAKeyStep{}).Run(context.Background(), ioutil.Discard, cfg)
+	require.NotNil(t, err)
+}
+
 func TestWriteCertificatesError(t *testing.T) {
 	errMsg := "error has occurred"
 
@@ -0,0 +1,167 @@
+// Package certs has steps that gather expiry dates of control plane
+// certificates of masters and renew them.
+package certs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	ExpiryStepName = "certs_expiry"
+	RotateStepName = "certs_rotate"
+
+	// marker starts lines of the expiry script output that have expiry dates.
+	marker = "CERT_EXPIRY"
+	// notAfterLayout is a layout of dates printed by openssl.
+	notAfterLayout = "Jan _2 15:04:05 2006 MST"
+)
+
+type Config struct {
+	Marker   string
+	UserName string
+}
+
+func Init() {
+	expiryTpl, err := tm.GetTemplate(ExpiryStepName)
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", ExpiryStepName))
+	}
+
+	rotateTpl, err := tm.GetTemplate(RotateStepName)
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", RotateStepName))
+	}
+
+	steps.RegisterStep(ExpiryStepName, NewExpiryStep(expiryTpl))
+	steps.RegisterStep(RotateStepName, NewRotateStep(rotateTpl))
+}
+
+// ExpiryStep gathers expiry dates of certificates of the master
+// to the config.
+type ExpiryStep struct {
+	script *template.Template
+}
+
+func NewExpiryStep(script *template.Template) *ExpiryStep {
+	return &ExpiryStep{
+		script: script,
+	}
+}
+
+func (s *ExpiryStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	buf := &bytes.Buffer{}
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner,
+		io.MultiWriter(out, buf), toStepCfg(config))
+	if err != nil {
+		return errors.Wrap(err, ExpiryStepName)
+	}
+
+	expiries, err := parseExpiries(buf.String(), config.Node.Name)
+	if err != nil {
+		return errors.Wrap(err, ExpiryStepName)
+	}
+	config.CertExpiries = expiries
+
+	return nil
+}
+
+func (s *ExpiryStep) Name() string {
+	return ExpiryStepName
+}
+
+func (s *ExpiryStep) Description() string {
+	return "gather expiry dates of certificates"
+}
+
+func (s *ExpiryStep) Depends() []string {
+	return nil
+}
+
+func (s *ExpiryStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+// RotateStep renews certificates of the master and restarts
+// control plane components.
+type RotateStep struct {
+	script *template.Template
+}
+
+func NewRotateStep(script *template.Template) *RotateStep {
+	return &RotateStep{
+		script: script,
+	}
+}
+
+func (s *RotateStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
+	if err != nil {
+		return errors.Wrap(err, RotateStepName)
+	}
+
+	return nil
+}
+
+func (s *RotateStep) Name() string {
+	return RotateStepName
+}
+
+func (s *RotateStep) Description() string {
+	return "renew certificates of control plane"
+}
+
+func (s *RotateStep) Depends() []string {
+	return nil
+}
+
+func (s *RotateStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func toStepCfg(config *steps.Config) Config {
+	return Config{
+		Marker:   marker,
+		UserName: clouds.OSUser,
+	}
+}
+
+// parseExpiries reads lines like "CERT_EXPIRY apiserver.crt Jul  1 12:00:00 2020 GMT"
+// of the script output.
+func parseExpiries(output, machine string) ([]model.CertExpiry, error) {
+	expiries := make([]model.CertExpiry, 0)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 3)
+		if len(parts) != 3 || parts[0] != marker {
+			continue
+		}
+
+		notAfter, err := time.Parse(notAfterLayout, strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "expiry date of %s", parts[1])
+		}
+
+		expiries = append(expiries, model.CertExpiry{
+			Name:     parts[1],
+			Machine:  machine,
+			NotAfter: notAfter.UTC(),
+		})
+	}
+
+	return expiries, scanner.Err()
+}
@@ -0,0 +1,117 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type fakeRunner struct {
+	testutils.MockRunner
+	output string
+}
+
+func (f *fakeRunner) Run(command *runner.Command) error {
+	_, err := io.Copy(command.Out, strings.NewReader(f.output))
+	return err
+}
+
+func TestExpiryStep(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(ExpiryStepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	config := &steps.Config{
+		Node: model.Machine{Name: "master-1"},
+		Runner: &fakeRunner{output: "CERT_EXPIRY apiserver.crt Jul  1 12:00:00 2020 GMT\n" +
+			"unrelated output\n" +
+			"CERT_EXPIRY etcd/server.crt Dec 24 08:30:00 2020 GMT\n"},
+	}
+
+	output := &bytes.Buffer{}
+	if err := NewExpiryStep(tpl).Run(context.Background(), output, config); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := []model.CertExpiry{
+		{Name: "apiserver.crt", Machine: "master-1", NotAfter: time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{Name: "etcd/server.crt", Machine: "master-1", NotAfter: time.Date(2020, 12, 24, 8, 30, 0, 0, time.UTC)},
+	}
+	if len(config.CertExpiries) != len(expected) {
+		t.Fatalf("expected expiries %v actual %v", expected, config.CertExpiries)
+	}
+	for i := range expected {
+		actual := config.CertExpiries[i]
+		if actual.Name != expected[i].Name || actual.Machine != expected[i].Machine ||
+			!actual.NotAfter.Equal(expected[i].NotAfter) {
+			t.Errorf("expected expiry %v actual %v", expected[i], actual)
+		}
+	}
+
+	if !strings.Contains(output.String(), "unrelated output") {
+		t.Errorf("script output must be written to the log %s", output.String())
+	}
+}
+
+func TestExpiryStepInvalidDate(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(ExpiryStepName)
+	config := &steps.Config{
+		Runner: &fakeRunner{output: "CERT_EXPIRY apiserver.crt unable to load certificate\n"},
+	}
+
+	if err := NewExpiryStep(tpl).Run(context.Background(), &bytes.Buffer{}, config); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestRotateStep(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(RotateStepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	output := &bytes.Buffer{}
+	config := &steps.Config{
+		Runner: &testutils.MockRunner{},
+	}
+	if err := NewRotateStep(tpl).Run(context.Background(), output, config); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, expected := range []string{"kubeadm alpha certs renew all", "/home/supergiant/.kube/config"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("%s not found in output %s", expected, output.String())
+		}
+	}
+
+	errExpected := errors.New("error has occurred")
+	config.Runner = &testutils.MockRunner{Err: errExpected}
+	err := NewRotateStep(tpl).Run(context.Background(), &bytes.Buffer{}, config)
+	if errors.Cause(err) != errExpected {
+		t.Errorf("wrong error expected %v actual %v", errExpected, err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -17,6 +18,7 @@ const StepName = "cloudcontroller"
 type Config struct {
 	Provider      string
 	DOAccessToken string
+	Mirrors       profile.Mirrors
 }
 
 type Step struct {
@@ -42,7 +44,7 @@ func Init() {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), s.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(context.Background(), steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
 
 	if err != nil {
 		return errors.Wrap(err, "install cloud-controller-manager")
@@ -71,5 +73,6 @@ func toStepCfg(c *steps.Config) Config {
 	return Config{
 		Provider:      string(c.Kube.Provider),
 		DOAccessToken: c.DigitalOceanConfig.AccessToken,
+		Mirrors:       c.Kube.Mirrors,
 	}
 }
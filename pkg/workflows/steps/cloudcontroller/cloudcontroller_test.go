@@ -14,10 +14,13 @@ import (
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
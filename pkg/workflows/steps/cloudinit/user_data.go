@@ -0,0 +1,176 @@
+package cloudinit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner/dry"
+	"github.com/supergiant/control/pkg/sgerrors"
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	UserDataStepName = "cloudinit_user_data"
+	WaitStepName     = "cloudinit_wait"
+
+	templateName = "cloudinit"
+)
+
+var (
+	ErrCallbackURL = errors.New("callback url is not set")
+
+	userDataTpl *template.Template
+	callbackURL string
+)
+
+// Script is a script of the step run by cloud-init.
+type Script struct {
+	Name   string
+	Script string
+}
+
+type Config struct {
+	Provider    string
+	CallbackURL string
+	Token       string
+	User        string
+	Steps       []Script
+}
+
+// UserDataStep renders scripts of provisioning steps into user data of
+// the machine instead of running them over ssh.
+type UserDataStep struct {
+	script *template.Template
+	steps  []steps.Step
+}
+
+// Init registers the wait step, baseURL is an url control is reached
+// from machines with.
+func Init(baseURL string, statuses StatusGetter) {
+	tpl, err := tm.GetTemplate(templateName)
+
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", templateName))
+	}
+
+	userDataTpl = tpl
+	callbackURL = baseURL
+
+	steps.RegisterStep(WaitStepName, NewWait(statuses))
+}
+
+// NewUserData returns step that renders scripts of the steps, they
+// must be run in dry run mode.
+func NewUserData(scriptSteps []steps.Step) *UserDataStep {
+	return &UserDataStep{
+		script: userDataTpl,
+		steps:  scriptSteps,
+	}
+}
+
+func (s *UserDataStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if s.script == nil {
+		return errors.Wrapf(sgerrors.ErrNotFound, "template %s", templateName)
+	}
+	if callbackURL == "" {
+		return ErrCallbackURL
+	}
+
+	scripts, err := s.render(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	err = steps.Template(config, s.script).Execute(buf, Config{
+		Provider:    string(config.Provider),
+		CallbackURL: bootstrap.CallbackURL(callbackURL, config.Kube.ID, config.TaskID),
+		Token:       config.Kube.CallbackToken,
+		User:        config.Kube.SSHConfig.User,
+		Steps:       scripts,
+	})
+	if err != nil {
+		return errors.Wrap(err, "render user data")
+	}
+
+	config.UserData = buf.String()
+
+	return nil
+}
+
+// render runs the steps with dry runner, addresses and id of the machine
+// are left to be found out by the machine itself.
+func (s *UserDataStep) render(ctx context.Context, config *steps.Config) ([]Script, error) {
+	runner, dryRun, node := config.Runner, config.DryRun, config.Node
+	defer func() {
+		config.Runner, config.DryRun, config.Node = runner, dryRun, node
+	}()
+
+	config.DryRun = true
+	config.Node = model.Machine{
+		Name:      nodeName(config),
+		TaskID:    config.TaskID,
+		ID:        "${INSTANCE_ID}",
+		PrivateIp: "${NODE_IP}",
+		PublicIp:  "${PUBLIC_IP}",
+	}
+
+	scripts := make([]Script, 0, len(s.steps))
+	for _, step := range s.steps {
+		r := dry.NewDryRunner()
+		config.Runner = r
+
+		if err := step.Run(ctx, ioutil.Discard, config); err != nil {
+			return nil, errors.Wrapf(err, "render %s step", step.Name())
+		}
+
+		if strings.TrimSpace(r.GetOutput()) == "" {
+			continue
+		}
+
+		scripts = append(scripts, Script{
+			Name:   step.Name(),
+			Script: r.GetOutput(),
+		})
+	}
+
+	return scripts, nil
+}
+
+func (s *UserDataStep) Name() string {
+	return UserDataStepName
+}
+
+func (s *UserDataStep) Description() string {
+	return "Render provisioning steps into cloud-init user data"
+}
+
+func (s *UserDataStep) Depends() []string {
+	return nil
+}
+
+func (s *UserDataStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+// nodeName is the name create machine step gives to the machine.
+func nodeName(config *steps.Config) string {
+	name := config.Kube.Name
+	if config.Provider == clouds.GCE {
+		name = strings.ToLower(name)
+	}
+
+	return util.MakeNodeName(name, config.TaskID, config.IsMaster)
+}
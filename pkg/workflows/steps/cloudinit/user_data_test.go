@@ -0,0 +1,143 @@
+package cloudinit
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type fakeStep struct {
+	name   string
+	script string
+	err    error
+}
+
+func (s *fakeStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.script == "" {
+		return nil
+	}
+
+	cmd, err := runner.NewCommand(ctx, s.script+" "+config.Node.PrivateIp, out, out)
+	if err != nil {
+		return err
+	}
+	return config.Runner.Run(cmd)
+}
+
+func (s *fakeStep) Name() string {
+	return s.name
+}
+
+func (s *fakeStep) Description() string {
+	return ""
+}
+
+func (s *fakeStep) Depends() []string {
+	return nil
+}
+
+func (s *fakeStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func initUserData(t *testing.T, baseURL string) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	Init(baseURL, nil)
+}
+
+func TestUserDataRun(t *testing.T) {
+	initUserData(t, "https://control.local")
+	defer initUserData(t, "")
+
+	config := &steps.Config{
+		Provider: clouds.AWS,
+		TaskID:   "1234567890",
+		IsMaster: true,
+		Kube: model.Kube{
+			ID:            "kube",
+			Name:          "test",
+			CallbackToken: "secret",
+			SSHConfig: model.SSHConfig{
+				User: "ubuntu",
+			},
+		},
+		Node: model.Machine{
+			Name: "previous",
+		},
+	}
+
+	step := NewUserData([]steps.Step{
+		&fakeStep{name: "docker", script: "install docker"},
+		&fakeStep{name: "nothing"},
+		&fakeStep{name: "kubeadm", script: "kubeadm init"},
+	})
+
+	require.NoError(t, step.Run(context.Background(), ioutil.Discard, config))
+
+	for _, expected := range []string{
+		"CALLBACK_URL=https://control.local/bootstrap/kube/1234567890",
+		"CALLBACK_TOKEN=secret",
+		"http://169.254.169.254/latest/meta-data/public-ipv4",
+		"install docker ${NODE_IP}",
+		"kubeadm init ${NODE_IP}",
+		"for STEP in docker kubeadm; do",
+		"sudo -u ubuntu -H",
+	} {
+		require.Contains(t, config.UserData, expected)
+	}
+	require.False(t, strings.Contains(config.UserData, "nothing.sh"))
+
+	// config is left as it was
+	require.Equal(t, "previous", config.Node.Name)
+	require.False(t, config.DryRun)
+	require.Nil(t, config.Runner)
+}
+
+func TestUserDataRunError(t *testing.T) {
+	initUserData(t, "")
+
+	config := &steps.Config{TaskID: "1234567890"}
+	step := NewUserData([]steps.Step{&fakeStep{name: "docker"}})
+
+	err := step.Run(context.Background(), ioutil.Discard, config)
+	require.Equal(t, ErrCallbackURL, errors.Cause(err))
+
+	initUserData(t, "https://control.local")
+	defer initUserData(t, "")
+
+	stepErr := errors.New("docker")
+	step = NewUserData([]steps.Step{&fakeStep{name: "docker", err: stepErr}})
+
+	err = step.Run(context.Background(), ioutil.Discard, config)
+	require.Equal(t, stepErr, errors.Cause(err))
+	require.Empty(t, config.UserData)
+}
+
+func TestNodeName(t *testing.T) {
+	config := &steps.Config{
+		Provider: clouds.GCE,
+		TaskID:   "1234567890",
+		Kube: model.Kube{
+			Name: "Test",
+		},
+	}
+
+	require.Equal(t, "test-node-1234", nodeName(config))
+}
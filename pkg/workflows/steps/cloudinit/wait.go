@@ -0,0 +1,114 @@
+package cloudinit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	defaultTimeout  = time.Minute * 30
+	defaultInterval = time.Second * 10
+)
+
+var ErrBootstrapFailed = errors.New("bootstrap has failed")
+
+type StatusGetter interface {
+	Get(ctx context.Context, kubeID, taskID string) (*bootstrap.Status, error)
+}
+
+// WaitStep waits until machine reports that its steps have finished.
+type WaitStep struct {
+	statuses StatusGetter
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func NewWait(statuses StatusGetter) *WaitStep {
+	return &WaitStep{
+		statuses: statuses,
+		timeout:  defaultTimeout,
+		interval: defaultInterval,
+	}
+}
+
+func (s *WaitStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	reported := make(map[string]statuses.Status)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		st, err := s.statuses.Get(ctx, config.Kube.ID, config.TaskID)
+		if err != nil && errors.Cause(err) != sgerrors.ErrNotFound {
+			return errors.Wrap(err, "get bootstrap status")
+		}
+
+		if st != nil {
+			// copy progress of the machine to the task log
+			for _, step := range st.Steps {
+				if reported[step.Name] != step.Status {
+					reported[step.Name] = step.Status
+					fmt.Fprintf(out, "%s: %s %s\n", step.Name, step.Status, step.Message)
+				}
+			}
+
+			switch st.Status {
+			case statuses.Success:
+				s.markActive(config)
+				return nil
+			case statuses.Error:
+				config.Node.State = model.MachineStateError
+				config.NodeChan() <- config.Node
+				return errors.Wrap(ErrBootstrapFailed, st.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "wait for bootstrap of %s", config.Node.Name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// markActive does what post start step does for machines provisioned
+// over ssh.
+func (s *WaitStep) markActive(config *steps.Config) {
+	config.Node.State = model.MachineStateActive
+
+	if config.IsMaster {
+		config.AddMaster(&config.Node)
+	} else {
+		config.AddNode(&config.Node)
+	}
+
+	config.NodeChan() <- config.Node
+}
+
+func (s *WaitStep) Name() string {
+	return WaitStepName
+}
+
+func (s *WaitStep) Description() string {
+	return "Wait until cloud-init runs provisioning steps"
+}
+
+func (s *WaitStep) Depends() []string {
+	return nil
+}
+
+func (s *WaitStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package cloudinit
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// fakeStatuses returns statuses one by one, the last one is kept
+type fakeStatuses struct {
+	items []*bootstrap.Status
+}
+
+func (f *fakeStatuses) Get(ctx context.Context, kubeID, taskID string) (*bootstrap.Status, error) {
+	if len(f.items) == 0 {
+		return nil, sgerrors.ErrNotFound
+	}
+
+	st := f.items[0]
+	if len(f.items) > 1 {
+		f.items = f.items[1:]
+	}
+	if st == nil {
+		return nil, sgerrors.ErrNotFound
+	}
+	return st, nil
+}
+
+func newWaitConfig(t *testing.T) *steps.Config {
+	cfg, err := steps.NewConfig("test", "test", profile.Profile{
+		MasterProfiles: []profile.NodeProfile{{}},
+	})
+	require.NoError(t, err)
+
+	cfg.IsMaster = true
+	cfg.Node = model.Machine{
+		Name:  "test-master-1234",
+		State: model.MachineStateProvisioning,
+	}
+	return cfg
+}
+
+func TestWaitRun(t *testing.T) {
+	executing := &bootstrap.Status{
+		Status: statuses.Executing,
+		Steps: []bootstrap.StepStatus{
+			{Name: "docker", Status: statuses.Executing},
+		},
+	}
+	done := &bootstrap.Status{
+		Status: statuses.Success,
+		Steps: []bootstrap.StepStatus{
+			{Name: "docker", Status: statuses.Success},
+		},
+	}
+
+	step := NewWait(&fakeStatuses{items: []*bootstrap.Status{nil, executing, done}})
+	step.interval = time.Millisecond
+
+	cfg := newWaitConfig(t)
+	out := &bytes.Buffer{}
+
+	require.NoError(t, step.Run(context.Background(), out, cfg))
+	require.Equal(t, model.MachineStateActive, cfg.Node.State)
+	require.Len(t, cfg.GetMasters(), 1)
+	require.Equal(t, model.MachineStateActive, (<-cfg.NodeChan()).State)
+	require.Contains(t, out.String(), "docker: executing")
+	require.Contains(t, out.String(), "docker: success")
+}
+
+func TestWaitRunError(t *testing.T) {
+	failed := &bootstrap.Status{
+		Status:  statuses.Error,
+		Message: "step kubeadm has failed",
+	}
+
+	step := NewWait(&fakeStatuses{items: []*bootstrap.Status{failed}})
+	step.interval = time.Millisecond
+
+	cfg := newWaitConfig(t)
+
+	err := step.Run(context.Background(), &bytes.Buffer{}, cfg)
+	require.Equal(t, ErrBootstrapFailed, errors.Cause(err))
+	require.Equal(t, model.MachineStateError, cfg.Node.State)
+}
+
+func TestWaitRunTimeout(t *testing.T) {
+	step := NewWait(&fakeStatuses{})
+	step.interval = time.Millisecond
+	step.timeout = time.Millisecond * 10
+
+	err := step.Run(context.Background(), &bytes.Buffer{}, newWaitConfig(t))
+	require.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+}
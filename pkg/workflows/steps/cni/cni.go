@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -41,7 +42,11 @@ func New(script *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.script, config.Runner, out, nil)
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, struct {
+		Mirrors profile.Mirrors
+	}{
+		Mirrors: config.Kube.Mirrors,
+	})
 
 	if err != nil {
 		return errors.Wrap(err, "install cni step")
@@ -15,10 +15,13 @@ import (
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
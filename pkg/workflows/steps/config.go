@@ -9,10 +9,15 @@ import (
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/catalog"
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/cri"
+	"github.com/supergiant/control/pkg/distro"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage"
 )
@@ -58,6 +63,7 @@ type GCEConfig struct {
 	ServiceAccount
 
 	// This comes from profile
+	ImageProject     string `json:"imageProject"`
 	ImageFamily      string `json:"imageFamily"`
 	Region           string `json:"region"`
 	AvailabilityZone string `json:"availabilityZone"`
@@ -106,9 +112,73 @@ type AzureConfig struct {
 	VNetCIDR string `json:"vNetCIDR"`
 }
 
-type PacketConfig struct{}
+// PacketConfig describes devices of the kube, they are deployed to
+// a single facility of packet project.
+type PacketConfig struct {
+	// These come from cloud account
+	APIKey    string `json:"apiKey"`
+	ProjectID string `json:"projectId"`
+
+	// These come from profile
+	Facility        string `json:"facility"`
+	Plan            string `json:"size"`
+	OperatingSystem string `json:"operatingSystem"`
 
-type OSConfig struct{}
+	SSHKeyID    string `json:"sshKeyId"`
+	ElasticIPID string `json:"elasticIpId"`
+	ElasticIP   string `json:"elasticIp"`
+}
+
+type OSConfig struct {
+	// These come from cloud account
+	AuthURL     string `json:"authUrl"`
+	UserName    string `json:"userName"`
+	Password    string `json:"password"`
+	DomainName  string `json:"domainName"`
+	ProjectName string `json:"projectName"`
+
+	// These come from profile
+	Region           string `json:"region"`
+	AvailabilityZone string `json:"availabilityZone"`
+	Flavor           string `json:"size"`
+	Image            string `json:"image"`
+	SubnetCIDR       string `json:"subnetCidr"`
+
+	ExternalNetworkID string `json:"externalNetworkId"`
+	NetworkID         string `json:"networkId"`
+	NetworkName       string `json:"networkName"`
+	SubnetID          string `json:"subnetId"`
+	RouterID          string `json:"routerId"`
+	SecurityGroupID   string `json:"securityGroupId"`
+	SecurityGroupName string `json:"securityGroupName"`
+	KeyPairName       string `json:"keyPairName"`
+
+	LoadBalancerID string `json:"loadBalancerId"`
+	PoolID         string `json:"poolId"`
+	LBFloatingIPID string `json:"lbFloatingIpId"`
+}
+
+// BYOConfig is a machine brought by user, it is registered
+// instead of being created.
+type BYOConfig struct {
+	PublicIP  string `json:"publicIp"`
+	PrivateIP string `json:"privateIp"`
+	SSHUser   string `json:"sshUser"`
+	SSHKey    string `json:"sshKey"`
+}
+
+// SimulatorConfig describes machines of the kube that are kept in memory
+// of control, scripts of the machines are either recorded or run locally.
+type SimulatorConfig struct {
+	// This comes from cloud account
+	Runner string `json:"runner"`
+
+	// These come from profile
+	Region string `json:"region"`
+	Size   string `json:"size"`
+
+	NetworkID string `json:"networkId"`
+}
 
 type AWSConfig struct {
 	KeyID                  string `json:"access_key"`
@@ -157,20 +227,51 @@ type InstallAppConfig struct {
 	Values       string `json:"values"`
 }
 
+// Map of machines is shared among copies of config that tasks of
+// the kube run with, so is the lock guarding it.
 type Map struct {
+	m        *sync.RWMutex
 	internal map[string]*model.Machine
 }
 
 func (m *Map) UnmarshalJSON(b []byte) error {
+	if m.m == nil {
+		m.m = &sync.RWMutex{}
+	}
+	defer m.lock()()
+
 	return json.Unmarshal(b, &m.internal)
 }
 
 func (m *Map) MarshalJSON() ([]byte, error) {
+	defer m.rlock()()
+
 	return json.Marshal(m.internal)
 }
 
+// lock locks the map and returns the function unlocking it,
+// maps made without NewMap are not guarded.
+func (m *Map) lock() func() {
+	if m.m == nil {
+		return func() {}
+	}
+
+	m.m.Lock()
+	return m.m.Unlock
+}
+
+func (m *Map) rlock() func() {
+	if m.m == nil {
+		return func() {}
+	}
+
+	m.m.RLock()
+	return m.m.RUnlock
+}
+
 func NewMap(m map[string]*model.Machine) Map {
 	return Map{
+		m:        &sync.RWMutex{},
 		internal: m,
 	}
 }
@@ -180,38 +281,54 @@ type Config struct {
 
 	DryRun             bool `json:"dryRun"`
 	TaskID             string
-	IsMaster           bool         `json:"isMaster"`
-	IsBootstrap        bool         `json:"IsBootstrap"`
-	IsImport           bool         `json:"isImport"`
-	DigitalOceanConfig DOConfig     `json:"digitalOceanConfig"`
-	AWSConfig          AWSConfig    `json:"awsConfig"`
-	GCEConfig          GCEConfig    `json:"gceConfig"`
-	AzureConfig        AzureConfig  `json:"azureConfig"`
-	OSConfig           OSConfig     `json:"osConfig"`
-	PacketConfig       PacketConfig `json:"packetConfig"`
-
-	DrainConfig DrainConfig `json:"drainConfig"`
-	ConfigMap   ConfigMap   `json:"configMap"`
-	ApplyConfig ApplyConfig `json:"applyConfig"`
-	InstallAppConfig   InstallAppConfig   `json:"installAppConfig"`
+	IsMaster           bool            `json:"isMaster"`
+	IsBootstrap        bool            `json:"IsBootstrap"`
+	IsImport           bool            `json:"isImport"`
+	UploadCerts        bool            `json:"uploadCerts"`
+	EtcdBackup         string          `json:"etcdBackup,omitempty"`
+	DigitalOceanConfig DOConfig        `json:"digitalOceanConfig"`
+	AWSConfig          AWSConfig       `json:"awsConfig"`
+	GCEConfig          GCEConfig       `json:"gceConfig"`
+	AzureConfig        AzureConfig     `json:"azureConfig"`
+	OSConfig           OSConfig        `json:"osConfig"`
+	PacketConfig       PacketConfig    `json:"packetConfig"`
+	BYOConfig          BYOConfig       `json:"byoConfig"`
+	SimulatorConfig    SimulatorConfig `json:"simulatorConfig"`
+
+	DrainConfig      DrainConfig      `json:"drainConfig"`
+	ConfigMap        ConfigMap        `json:"configMap"`
+	ApplyConfig      ApplyConfig      `json:"applyConfig"`
+	InstallAppConfig InstallAppConfig `json:"installAppConfig"`
+
+	// UserData is passed to the machine being created, it runs
+	// provisioning steps when kube is bootstrapped with cloud-init.
+	UserData string `json:"userData,omitempty"`
+
+	// CertExpiries are gathered from the machine by the certificates
+	// expiry step.
+	CertExpiries []model.CertExpiry `json:"certExpiries,omitempty"`
 
 	Provider clouds.Name `json:"provider"`
 
-	Node             model.Machine `json:"node"`
-	CloudAccountID   string        `json:"cloudAccountId" valid:"required, length(1|32)"`
-	CloudAccountName string        `json:"cloudAccountName" valid:"required, length(1|32)"`
-	Timeout          time.Duration `json:"timeout"`
-	Runner           runner.Runner `json:"-"`
+	Node model.Machine `json:"node"`
+	// NodePool of the node being provisioned, empty for masters
+	// and nodes that don't belong to any pool.
+	NodePool         profile.NodePool `json:"nodePool"`
+	CloudAccountID   string           `json:"cloudAccountId" valid:"required, length(1|32)"`
+	CloudAccountName string           `json:"cloudAccountName" valid:"required, length(1|32)"`
+	Timeout          time.Duration    `json:"timeout"`
+	Runner           runner.Runner    `json:"-"`
+	// SSHPool keeps ssh connections to machines for the life of a task
+	SSHPool *ssh.Pool `json:"-"`
 
 	repository storage.Interface `json:"-"`
 
-	m1      sync.RWMutex
 	Masters Map `json:"masters"`
+	Nodes   Map `json:"nodes"`
 
-	m2    sync.RWMutex
-	Nodes Map `json:"nodes"`
-
-	authorizerMux  sync.RWMutex
+	// authorizerMux is shared by copies of the config, configs
+	// made without constructors don't guard the authorizer.
+	authorizerMux  *sync.RWMutex
 	azureAthorizer autorest.Authorizer
 
 	nodeChan      chan model.Machine
@@ -229,17 +346,52 @@ func NewConfig(clusterName, cloudAccountName string, profile profile.Profile) (*
 		return nil, err
 	}
 
-	var user = "root"
+	d, err := distro.Get(profile.OperatingSystem, profile.UbuntuVersion)
+	if err != nil {
+		return nil, err
+	}
 
-	if profile.Provider == clouds.AWS {
-		//on aws default user name on ubuntu images are not root but ubuntu
-		//https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/AccessingInstancesLinux.html
-		// TODO: this should be set by provisioner
-		user = "ubuntu"
-	} else if profile.Provider == clouds.Azure {
-		user = clouds.OSUser
+	if err := cri.Validate(profile.ContainerRuntime); err != nil {
+		return nil, err
 	}
 
+	if err := profile.Mirrors.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := bootstrap.Validate(profile.BootstrapMode); err != nil {
+		return nil, err
+	}
+
+	if err := profile.NodePools.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validateVersions(profile); err != nil {
+		return nil, err
+	}
+
+	if profile.Provider == clouds.BYO {
+		if err := validateHosts(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	var callbackToken string
+	if profile.BootstrapMode == bootstrap.CloudInit {
+		if callbackToken, err = bootstrap.NewToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	var user = CloudUser(profile.Provider, d)
+
+	if user == "" {
+		user = "root"
+	}
+
+	externalDNSName, internalDNSName := apiEndpoints(profile)
+
 	return &Config{
 		Kube: model.Kube{
 			Name:       clusterName,
@@ -249,6 +401,10 @@ func NewConfig(clusterName, cloudAccountName string, profile profile.Profile) (*
 				User:      user,
 				Timeout:   30,
 				PublicKey: profile.PublicKey,
+				JumpHosts: profile.JumpHosts,
+
+				BootstrapKeyType:    profile.SSHKeyType,
+				BootstrapPrivateKey: profile.BootstrapPrivateKey,
 			},
 			Auth: model.Auth{
 				Username:   profile.User,
@@ -261,16 +417,24 @@ func NewConfig(clusterName, cloudAccountName string, profile profile.Profile) (*
 				Type:     profile.NetworkType,
 				CIDR:     profile.CIDR,
 			},
-			Arch:             profile.Arch,
-			OperatingSystem:  profile.OperatingSystem,
-			DockerVersion:    profile.DockerVersion,
-			HelmVersion:      profile.HelmVersion,
-			ExposedAddresses: profile.ExposedAddresses,
-			APIServerPort:    ensurePort(profile.K8SAPIPort),
-			Provider:         profile.Provider,
-			RBACEnabled:      profile.RBACEnabled,
-			ServicesCIDR:     profile.K8SServicesCIDR,
-			Addons:           profile.Addons,
+			Arch:                   profile.Arch,
+			OperatingSystem:        profile.OperatingSystem,
+			OperatingSystemVersion: profile.UbuntuVersion,
+			DockerVersion:          profile.DockerVersion,
+			ContainerRuntime:       profile.ContainerRuntime,
+			HelmVersion:            profile.HelmVersion,
+			ExposedAddresses:       profile.ExposedAddresses,
+			APIServerPort:          ensurePort(profile.K8SAPIPort),
+			Provider:               profile.Provider,
+			RBACEnabled:            profile.RBACEnabled,
+			ServicesCIDR:           profile.K8SServicesCIDR,
+			Addons:                 profile.Addons,
+			Mirrors:                profile.Mirrors,
+			BootstrapMode:          profile.BootstrapMode,
+			CallbackToken:          callbackToken,
+			NodePools:              nodePools(profile.NodePools),
+			ExternalDNSName:        externalDNSName,
+			InternalDNSName:        internalDNSName,
 		},
 		Provider: profile.Provider,
 		DigitalOceanConfig: DOConfig{
@@ -289,7 +453,8 @@ func NewConfig(clusterName, cloudAccountName string, profile profile.Profile) (*
 		},
 		GCEConfig: GCEConfig{
 			AvailabilityZone:   profile.Zone,
-			ImageFamily:        "ubuntu-1604-lts",
+			ImageProject:       d.GCEImage.Project,
+			ImageFamily:        d.GCEImage.Family,
 			Region:             profile.Region,
 			InstanceGroupLinks: make(map[string]string),
 			InstanceGroupNames: make(map[string]string),
@@ -301,39 +466,70 @@ func NewConfig(clusterName, cloudAccountName string, profile profile.Profile) (*
 			// TODO(stgleb): this should be passed from the UI
 			VolumeSize: "30",
 		},
-
-		Masters: Map{
-			internal: make(map[string]*model.Machine, len(profile.MasterProfiles)),
+		OSConfig: OSConfig{
+			Region:           profile.Region,
+			AvailabilityZone: profile.Zone,
+			Image:            openStackImage(profile.CloudSpecificSettings[clouds.OpenStackImage], d),
+			SubnetCIDR:       profile.CloudSpecificSettings[clouds.OpenStackSubnetCIDR],
+		},
+		PacketConfig: PacketConfig{
+			Facility:        profile.Region,
+			OperatingSystem: d.PacketOS,
 		},
-		Nodes: Map{
-			internal: make(map[string]*model.Machine, len(profile.NodesProfiles)),
+		SimulatorConfig: SimulatorConfig{
+			Region: profile.Region,
 		},
+
+		Masters:          NewMap(make(map[string]*model.Machine, len(profile.MasterProfiles))),
+		Nodes:            NewMap(make(map[string]*model.Machine, len(profile.NodesProfiles))),
 		Timeout:          time.Minute * 60,
 		CloudAccountName: cloudAccountName,
 
 		nodeChan:      make(chan model.Machine, len(profile.MasterProfiles)+len(profile.NodesProfiles)),
 		kubeStateChan: make(chan model.KubeState, 2),
 		configChan:    make(chan *Config),
+		authorizerMux: &sync.RWMutex{},
 	}, nil
 }
 
+// CloudUser returns user that has ssh access to cloud images of the distro,
+// empty user means that provider does not restrict it.
+func CloudUser(provider clouds.Name, d *distro.Distro) string {
+	switch provider {
+	case clouds.AWS:
+		//on aws default user name is not root and depends on the image
+		//https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/AccessingInstancesLinux.html
+		return d.CloudUser
+	case clouds.Azure:
+		return clouds.OSUser
+	case clouds.OpenStack:
+		return d.OpenStackImage.User
+	}
+	return ""
+}
+
+// openStackImage returns image the profile asks for, conventional
+// name of the distro image is used otherwise.
+func openStackImage(image string, d *distro.Distro) string {
+	if image != "" {
+		return image
+	}
+	return d.OpenStackImage.Name
+}
+
 // TODO(stgleb): Compare that to LoadCloudSpecificDataFromKube
 func NewConfigFromKube(profile *profile.Profile, k *model.Kube) (*Config, error) {
 	if k == nil {
 		return nil, errors.Wrapf(sgerrors.ErrNilEntity, "kube must not be nil")
 	}
 
-	var user string
-
-	if profile.Provider == clouds.AWS {
-		//on aws default user name on ubuntu images are not root but ubuntu
-		//https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/AccessingInstancesLinux.html
-		// TODO: this should be set by provisioner
-		user = "ubuntu"
-	} else if profile.Provider == clouds.Azure {
-		user = clouds.OSUser
+	d, err := distro.Get(k.OperatingSystem, k.OperatingSystemVersion)
+	if err != nil {
+		return nil, err
 	}
 
+	var user = CloudUser(profile.Provider, d)
+
 	cfg := &Config{
 		Provider: profile.Provider,
 		DigitalOceanConfig: DOConfig{
@@ -357,23 +553,50 @@ func NewConfigFromKube(profile *profile.Profile, k *model.Kube) (*Config, error)
 		},
 		GCEConfig: GCEConfig{
 			AvailabilityZone: profile.Zone,
+			ImageProject:     d.GCEImage.Project,
+			ImageFamily:      d.GCEImage.Family,
 		},
 		AzureConfig: AzureConfig{
 			Location:   profile.Region,
 			VNetCIDR:   k.CloudSpec[clouds.AzureVNetCIDR],
 			VolumeSize: k.CloudSpec[clouds.AzureVolumeSize],
 		},
-		Masters: Map{
-			internal: make(map[string]*model.Machine, len(profile.MasterProfiles)),
+		OSConfig: OSConfig{
+			Region:            profile.Region,
+			AvailabilityZone:  profile.Zone,
+			Image:             openStackImage(k.CloudSpec[clouds.OpenStackImage], d),
+			SubnetCIDR:        k.CloudSpec[clouds.OpenStackSubnetCIDR],
+			ExternalNetworkID: k.CloudSpec[clouds.OpenStackExternalNetworkID],
+			NetworkID:         k.CloudSpec[clouds.OpenStackNetworkID],
+			NetworkName:       k.CloudSpec[clouds.OpenStackNetworkName],
+			SubnetID:          k.CloudSpec[clouds.OpenStackSubnetID],
+			RouterID:          k.CloudSpec[clouds.OpenStackRouterID],
+			SecurityGroupID:   k.CloudSpec[clouds.OpenStackSecurityGroupID],
+			SecurityGroupName: k.CloudSpec[clouds.OpenStackSecurityGroupName],
+			KeyPairName:       k.CloudSpec[clouds.OpenStackKeyPairName],
+			LoadBalancerID:    k.CloudSpec[clouds.OpenStackLoadBalancerID],
+			PoolID:            k.CloudSpec[clouds.OpenStackPoolID],
+			LBFloatingIPID:    k.CloudSpec[clouds.OpenStackLBFloatingIPID],
 		},
-		Nodes: Map{
-			internal: make(map[string]*model.Machine, len(profile.NodesProfiles)),
+		PacketConfig: PacketConfig{
+			Facility:        profile.Region,
+			OperatingSystem: d.PacketOS,
+			SSHKeyID:        k.CloudSpec[clouds.PacketSSHKeyID],
+			ElasticIPID:     k.CloudSpec[clouds.PacketElasticIPID],
+			ElasticIP:       k.CloudSpec[clouds.PacketElasticIP],
 		},
+		SimulatorConfig: SimulatorConfig{
+			Region:    profile.Region,
+			NetworkID: k.CloudSpec[clouds.SimulatorNetworkID],
+		},
+		Masters:          NewMap(make(map[string]*model.Machine, len(profile.MasterProfiles))),
+		Nodes:            NewMap(make(map[string]*model.Machine, len(profile.NodesProfiles))),
 		Timeout:          time.Minute * 60,
 		CloudAccountName: k.AccountName,
 		nodeChan:         make(chan model.Machine, len(profile.MasterProfiles)+len(profile.NodesProfiles)),
 		kubeStateChan:    make(chan model.KubeState, 5),
 		configChan:       make(chan *Config),
+		authorizerMux:    &sync.RWMutex{},
 	}
 
 	// Restore all masters and workers from kube
@@ -392,24 +615,36 @@ func NewConfigFromKube(profile *profile.Profile, k *model.Kube) (*Config, error)
 		User:      user,
 		Timeout:   10,
 		PublicKey: profile.PublicKey,
+		JumpHosts: k.SSHConfig.JumpHosts,
+
+		BootstrapKeyType: k.SSHConfig.BootstrapKeyType,
+	}
+
+	// There is no cloud account to take ssh credentials from
+	if k.Provider == clouds.BYO {
+		cfg.Kube.SSHConfig.User = k.SSHConfig.User
+		cfg.Kube.SSHConfig.BootstrapPrivateKey = k.SSHConfig.BootstrapPrivateKey
 	}
 
 	return cfg, nil
 }
 
 // AddMaster to map of master, map is used because it is reference and can be shared among
-// goroutines that run multiple tasks of cluster deployment
+// goroutines that run multiple tasks of cluster deployment. The map keeps a copy of
+// the machine, steps keep changing the node of their config.
 func (c *Config) AddMaster(n *model.Machine) {
-	c.m1.Lock()
-	defer c.m1.Unlock()
-	c.Masters.internal[n.ID] = n
+	defer c.Masters.lock()()
+
+	m := *n
+	c.Masters.internal[n.ID] = &m
 }
 
 // AddNode to map of nodes in cluster
 func (c *Config) AddNode(n *model.Machine) {
-	c.m2.Lock()
-	defer c.m2.Unlock()
-	c.Nodes.internal[n.ID] = n
+	defer c.Nodes.lock()()
+
+	m := *n
+	c.Nodes.internal[n.ID] = &m
 }
 
 // GetMaster returns first master in master map or nil
@@ -419,8 +654,7 @@ func (c *Config) GetMaster() *model.Machine {
 		return &c.Node
 	}
 
-	c.m1.RLock()
-	defer c.m1.RUnlock()
+	defer c.Masters.rlock()()
 
 	if len(c.Masters.internal) == 0 {
 		return nil
@@ -436,9 +670,26 @@ func (c *Config) GetMaster() *model.Machine {
 	return nil
 }
 
+// GetOtherMaster returns an active master other than the node of the config,
+// masters are sorted by name so every step picks the same one.
+func (c *Config) GetOtherMaster() *model.Machine {
+	defer c.Masters.rlock()()
+
+	var found *model.Machine
+	for _, m := range c.Masters.internal {
+		if m == nil || m.State != model.MachineStateActive || m.Name == c.Node.Name {
+			continue
+		}
+		if found == nil || m.Name < found.Name {
+			found = m
+		}
+	}
+
+	return found
+}
+
 func (c *Config) GetMasters() map[string]*model.Machine {
-	c.m1.RLock()
-	defer c.m1.RUnlock()
+	defer c.Masters.rlock()()
 
 	m := make(map[string]*model.Machine, len(c.Masters.internal))
 
@@ -450,8 +701,7 @@ func (c *Config) GetMasters() map[string]*model.Machine {
 }
 
 func (c *Config) GetNodes() map[string]*model.Machine {
-	c.m2.RLock()
-	defer c.m2.RUnlock()
+	defer c.Nodes.rlock()()
 
 	m := make(map[string]*model.Machine, len(c.Nodes.internal))
 
@@ -464,8 +714,7 @@ func (c *Config) GetNodes() map[string]*model.Machine {
 
 // GetMaster returns first master in master map or nil
 func (c *Config) GetNode() *model.Machine {
-	c.m2.RLock()
-	defer c.m2.RUnlock()
+	defer c.Nodes.rlock()()
 
 	if len(c.Nodes.internal) == 0 {
 		return nil
@@ -506,15 +755,19 @@ func (c *Config) SetConfigChan(configChan chan *Config) {
 }
 
 func (c *Config) SetAzureAuthorizer(a autorest.Authorizer) {
-	c.authorizerMux.Lock()
-	defer c.authorizerMux.Unlock()
+	if c.authorizerMux != nil {
+		c.authorizerMux.Lock()
+		defer c.authorizerMux.Unlock()
+	}
 
 	c.azureAthorizer = a
 }
 
 func (c *Config) GetAzureAuthorizer() autorest.Authorizer {
-	c.authorizerMux.RLock()
-	defer c.authorizerMux.RUnlock()
+	if c.authorizerMux != nil {
+		c.authorizerMux.RLock()
+		defer c.authorizerMux.RUnlock()
+	}
 
 	return c.azureAthorizer
 }
@@ -526,6 +779,34 @@ func ensurePort(p int64) int64 {
 	return p
 }
 
+func nodePools(pools profile.NodePools) map[string]profile.NodePool {
+	if len(pools) == 0 {
+		return nil
+	}
+
+	m := make(map[string]profile.NodePool, len(pools))
+	for _, p := range pools {
+		m[p.Name] = p
+	}
+
+	return m
+}
+
+// validateVersions checks components of the profile are compatible
+// with its kubernetes version according to the version catalog.
+func validateVersions(p profile.Profile) error {
+	if p.K8SVersion == "" {
+		return nil
+	}
+
+	docker := p.DockerVersion
+	if p.ContainerRuntime != "" && p.ContainerRuntime != cri.Docker {
+		docker = ""
+	}
+
+	return catalog.ValidateComponents(p.K8SVersion, docker, p.HelmVersion)
+}
+
 func validateAddons(in []string) error {
 	invalid := make([]string, 0)
 	for _, addon := range in {
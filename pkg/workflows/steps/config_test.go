@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/cri"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
 )
@@ -77,6 +78,50 @@ func TestNewConfig(t *testing.T) {
 	}
 }
 
+func TestNewConfigVersions(t *testing.T) {
+	testCases := []struct {
+		description string
+		profile     profile.Profile
+		hasErr      bool
+	}{
+		{
+			description: "no version",
+			profile:     profile.Profile{DockerVersion: "1.18.1"},
+		},
+		{
+			description: "compatible",
+			profile:     profile.Profile{K8SVersion: "1.15.1", DockerVersion: "18.09.7", HelmVersion: "2.11.0"},
+		},
+		{
+			description: "unsupported kubernetes",
+			profile:     profile.Profile{K8SVersion: "1.9.11"},
+			hasErr:      true,
+		},
+		{
+			description: "incompatible docker",
+			profile:     profile.Profile{K8SVersion: "1.12.7", DockerVersion: "18.09.7"},
+			hasErr:      true,
+		},
+		{
+			description: "docker is not used",
+			profile: profile.Profile{K8SVersion: "1.12.7", DockerVersion: "18.09.7",
+				ContainerRuntime: cri.Containerd},
+		},
+		{
+			description: "incompatible helm",
+			profile:     profile.Profile{K8SVersion: "1.15.1", HelmVersion: "2.8.2"},
+			hasErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		_, err := NewConfig("test", "", testCase.profile)
+		if (err != nil) != testCase.hasErr {
+			t.Errorf("%s: unexpected error %v", testCase.description, err)
+		}
+	}
+}
+
 func TestAddMaster(t *testing.T) {
 	n := &model.Machine{
 		Role: model.RoleMaster,
@@ -115,6 +160,37 @@ func TestAddNode(t *testing.T) {
 	}
 }
 
+func TestAddNodeShared(t *testing.T) {
+	cfg := &Config{
+		Node:  model.Machine{ID: "1", State: model.MachineStateProvisioning},
+		Nodes: NewMap(make(map[string]*model.Machine)),
+	}
+	cfg.AddNode(&cfg.Node)
+
+	// Tasks of the kube run with copies of the config sharing the map
+	taskCfg := *cfg
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		taskCfg.Node.State = model.MachineStateActive
+		taskCfg.AddNode(&taskCfg.Node)
+	}()
+
+	if _, err := json.Marshal(cfg); err != nil {
+		t.Fatalf("marshal config %v", err)
+	}
+	<-done
+
+	if n := cfg.GetNodes()[""]; n == nil || n.State != model.MachineStateActive {
+		t.Errorf("node must be updated in the shared map %+v", n)
+	}
+
+	cfg.Node.State = model.MachineStateError
+	if n := cfg.GetNodes()[""]; n.State != model.MachineStateActive {
+		t.Errorf("map must keep a copy of the node, actual state %s", n.State)
+	}
+}
+
 func TestConfigGetMaster(t *testing.T) {
 	n := &model.Machine{
 		Name:  "master-1",
@@ -234,6 +310,31 @@ func TestConfigGetMasters(t *testing.T) {
 	}
 }
 
+func TestConfigGetOtherMaster(t *testing.T) {
+	cfg := &Config{
+		Node: model.Machine{Name: "master-a", State: model.MachineStateDeleting},
+		Masters: Map{
+			internal: map[string]*model.Machine{
+				"a": {Name: "master-a", State: model.MachineStateActive},
+				"b": {Name: "master-b", State: model.MachineStateNotReady},
+				"d": {Name: "master-d", State: model.MachineStateActive},
+				"c": {Name: "master-c", State: model.MachineStateActive},
+			},
+		},
+	}
+
+	if m := cfg.GetOtherMaster(); m == nil || m.Name != "master-c" {
+		t.Errorf("expected master-c actual %v", m)
+	}
+
+	cfg.Masters.internal = map[string]*model.Machine{
+		"a": {Name: "master-a", State: model.MachineStateActive},
+	}
+	if m := cfg.GetOtherMaster(); m != nil {
+		t.Errorf("unexpected master %v", m)
+	}
+}
+
 func TestConfigGetNodes(t *testing.T) {
 	testCases := []struct {
 		cfg           *Config
@@ -0,0 +1,86 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/cri"
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/profile"
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const StepName = "containerd"
+
+type Config struct {
+	Arch string
+	// Distro is used in urls of docker repositories containerd comes from
+	Distro  string
+	Socket  string
+	Mirrors profile.Mirrors
+}
+
+type Step struct {
+	script *template.Template
+}
+
+func Init() {
+	tpl, err := tm.GetTemplate(StepName)
+
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", StepName))
+	}
+
+	steps.RegisterStep(StepName, New(tpl))
+}
+
+func New(tpl *template.Template) *Step {
+	return &Step{
+		script: tpl,
+	}
+}
+
+func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
+	if err != nil {
+		return errors.Wrap(err, "install containerd step")
+	}
+
+	return nil
+}
+
+func (s *Step) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func (s *Step) Name() string {
+	return StepName
+}
+
+func (s *Step) Description() string {
+	return "Install containerd"
+}
+
+func (s *Step) Depends() []string {
+	return nil
+}
+
+func toStepCfg(c *steps.Config) Config {
+	cfg := Config{
+		Arch:    c.Kube.Arch,
+		Distro:  distro.Ubuntu,
+		Socket:  cri.Socket(cri.Containerd),
+		Mirrors: c.Kube.Mirrors,
+	}
+
+	if d, err := distro.Get(c.Kube.OperatingSystem, c.Kube.OperatingSystemVersion); err == nil {
+		cfg.Distro = d.Name
+	}
+
+	return cfg
+}
@@ -0,0 +1,66 @@
+package containerd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestInstallContainerd(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	output := &bytes.Buffer{}
+	config := &steps.Config{
+		Kube: model.Kube{
+			Arch:            "amd64",
+			OperatingSystem: "debian",
+		},
+		Runner: &testutils.MockRunner{},
+	}
+
+	if err := New(tpl).Run(context.Background(), output, config); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, expected := range []string{"download.docker.com/linux/debian",
+		"unix:///run/containerd/containerd.sock"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("%s not found in output %s", expected, output.String())
+		}
+	}
+}
+
+func TestContainerdError(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	errExpected := errors.New("error has occurred")
+
+	config := &steps.Config{
+		Runner: &testutils.MockRunner{
+			Err: errExpected,
+		},
+	}
+
+	err := New(tpl).Run(context.Background(), &bytes.Buffer{}, config)
+	if errors.Cause(err) != errExpected {
+		t.Errorf("wrong error expected %v actual %v", errExpected, err)
+	}
+}
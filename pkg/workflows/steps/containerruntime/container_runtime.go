@@ -0,0 +1,71 @@
+package containerruntime
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/cri"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/containerd"
+	"github.com/supergiant/control/pkg/workflows/steps/crio"
+	"github.com/supergiant/control/pkg/workflows/steps/docker"
+)
+
+const StepName = "container_runtime"
+
+// Step installs container runtime chosen for the kube.
+type Step struct {
+}
+
+func Init() {
+	steps.RegisterStep(StepName, &Step{})
+}
+
+func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if config == nil {
+		return errors.New("invalid config")
+	}
+
+	stepName, err := stepFor(config.Kube.ContainerRuntime)
+	if err != nil {
+		return err
+	}
+
+	step := steps.GetStep(stepName)
+	if step == nil {
+		return errors.Wrapf(sgerrors.ErrNotFound, "%s step", stepName)
+	}
+
+	return step.Run(ctx, out, config)
+}
+
+func (s *Step) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func (s *Step) Name() string {
+	return StepName
+}
+
+func (s *Step) Description() string {
+	return "Install container runtime"
+}
+
+func (s *Step) Depends() []string {
+	return nil
+}
+
+func stepFor(runtime string) (string, error) {
+	switch runtime {
+	case "", cri.Docker:
+		return docker.StepName, nil
+	case cri.Containerd:
+		return containerd.StepName, nil
+	case cri.CRIO:
+		return crio.StepName, nil
+	}
+	return "", errors.Wrap(cri.ErrUnknownRuntime, runtime)
+}
@@ -0,0 +1,87 @@
+package containerruntime
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/cri"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/containerd"
+	"github.com/supergiant/control/pkg/workflows/steps/crio"
+	"github.com/supergiant/control/pkg/workflows/steps/docker"
+)
+
+type fakeStep struct {
+	name   string
+	called bool
+}
+
+func (f *fakeStep) Run(context.Context, io.Writer, *steps.Config) error {
+	f.called = true
+	return nil
+}
+
+func (f *fakeStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func (f *fakeStep) Name() string {
+	return f.name
+}
+
+func (f *fakeStep) Description() string {
+	return ""
+}
+
+func (f *fakeStep) Depends() []string {
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		runtime  string
+		stepName string
+	}{
+		{"", docker.StepName},
+		{cri.Docker, docker.StepName},
+		{cri.Containerd, containerd.StepName},
+		{cri.CRIO, crio.StepName},
+	}
+
+	for _, testCase := range testCases {
+		fake := &fakeStep{name: testCase.stepName}
+		steps.RegisterStep(testCase.stepName, fake)
+
+		config := &steps.Config{
+			Kube: model.Kube{
+				ContainerRuntime: testCase.runtime,
+			},
+		}
+
+		if err := (&Step{}).Run(context.Background(), &bytes.Buffer{}, config); err != nil {
+			t.Errorf("runtime %q: unexpected error %v", testCase.runtime, err)
+		}
+
+		if !fake.called {
+			t.Errorf("runtime %q: step %s was not called", testCase.runtime, testCase.stepName)
+		}
+	}
+}
+
+func TestRunUnknownRuntime(t *testing.T) {
+	config := &steps.Config{
+		Kube: model.Kube{
+			ContainerRuntime: "rkt",
+		},
+	}
+
+	err := (&Step{}).Run(context.Background(), &bytes.Buffer{}, config)
+	if errors.Cause(err) != cri.ErrUnknownRuntime {
+		t.Errorf("wrong error expected %v actual %v", cri.ErrUnknownRuntime, err)
+	}
+}
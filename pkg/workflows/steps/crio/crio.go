@@ -0,0 +1,100 @@
+package crio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/cri"
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/profile"
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const StepName = "crio"
+
+type Config struct {
+	// Version of cri-o follows minor version of kubernetes
+	Version   string
+	KubicRepo string
+	Socket    string
+	Mirrors   profile.Mirrors
+}
+
+type Step struct {
+	script *template.Template
+}
+
+func Init() {
+	tpl, err := tm.GetTemplate(StepName)
+
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", StepName))
+	}
+
+	steps.RegisterStep(StepName, New(tpl))
+}
+
+func New(tpl *template.Template) *Step {
+	return &Step{
+		script: tpl,
+	}
+}
+
+func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	cfg, err := toStepCfg(config)
+	if err != nil {
+		return errors.Wrap(err, "install cri-o step")
+	}
+
+	err = steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, cfg)
+	if err != nil {
+		return errors.Wrap(err, "install cri-o step")
+	}
+
+	return nil
+}
+
+func (s *Step) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func (s *Step) Name() string {
+	return StepName
+}
+
+func (s *Step) Description() string {
+	return "Install cri-o"
+}
+
+func (s *Step) Depends() []string {
+	return nil
+}
+
+func toStepCfg(c *steps.Config) (Config, error) {
+	d, err := distro.Get(c.Kube.OperatingSystem, c.Kube.OperatingSystemVersion)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		Version:   minorVersion(c.Kube.K8SVersion),
+		KubicRepo: d.KubicRepo,
+		Socket:    cri.Socket(cri.CRIO),
+		Mirrors:   c.Kube.Mirrors,
+	}, nil
+}
+
+// minorVersion returns 1.15 for 1.15.1
+func minorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,74 @@
+package crio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestInstallCRIO(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	output := &bytes.Buffer{}
+	config := &steps.Config{
+		Kube: model.Kube{
+			K8SVersion:             "1.15.1",
+			OperatingSystem:        "ubuntu",
+			OperatingSystemVersion: "bionic",
+		},
+		Runner: &testutils.MockRunner{},
+	}
+
+	if err := New(tpl).Run(context.Background(), output, config); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, expected := range []string{"CRIO_VERSION=1.15", "stable/xUbuntu_18.04",
+		"unix:///var/run/crio/crio.sock"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("%s not found in output %s", expected, output.String())
+		}
+	}
+}
+
+func TestCRIOUnknownDistro(t *testing.T) {
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	config := &steps.Config{
+		Kube: model.Kube{
+			OperatingSystem: "windows",
+		},
+		Runner: &testutils.MockRunner{},
+	}
+
+	if err := New(tpl).Run(context.Background(), &bytes.Buffer{}, config); err == nil {
+		t.Errorf("error must not be nil")
+	}
+}
+
+func TestMinorVersion(t *testing.T) {
+	testCases := map[string]string{
+		"1.15.1": "1.15",
+		"1.14":   "1.14",
+		"1":      "1",
+		"":       "",
+	}
+
+	for version, expected := range testCases {
+		if actual := minorVersion(version); actual != expected {
+			t.Errorf("version %q: expected %s actual %s", version, expected, actual)
+		}
+	}
+}
@@ -8,12 +8,17 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 const StepName = "dashboard"
 
+type Config struct {
+	Mirrors profile.Mirrors
+}
+
 type Step struct {
 	script *template.Template
 }
@@ -41,7 +46,7 @@ func New(script *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.script, config.Runner, out, nil)
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
 
 	if err != nil {
 		return errors.Wrap(err, "install kubernetes dashboard")
@@ -61,3 +66,9 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return nil
 }
+
+func toStepCfg(c *steps.Config) Config {
+	return Config{
+		Mirrors: c.Kube.Mirrors,
+	}
+}
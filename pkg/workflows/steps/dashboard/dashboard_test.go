@@ -1,6 +1,7 @@
 package dashboard
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"io/ioutil"
@@ -9,13 +10,19 @@ import (
 	"text/template"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
 
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
@@ -28,6 +35,36 @@ func (f *fakeRunner) Run(command *runner.Command) error {
 	return err
 }
 
+func TestDashboardImageRegistry(t *testing.T) {
+	if err := templatemanager.Init("../../../../templates"); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	output := new(bytes.Buffer)
+	cfg := &steps.Config{
+		Kube: model.Kube{
+			Mirrors: profile.Mirrors{ImageRegistry: "registry.local:5000"},
+		},
+		Runner: &fakeRunner{},
+	}
+
+	err := New(tpl).Run(context.Background(), output, cfg)
+	require.Nil(t, err)
+
+	for _, image := range []string{
+		"registry.local:5000/heapster",
+		"registry.local:5000/addon-resizer",
+		"registry.local:5000/kubernetes-dashboard-amd64",
+	} {
+		require.Contains(t, output.String(), "repository="+image)
+	}
+}
+
 func TestStepName(t *testing.T) {
 	s := Step{}
 
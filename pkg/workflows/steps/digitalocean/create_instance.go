@@ -71,7 +71,8 @@ func (s *CreateInstanceStep) Run(ctx context.Context, output io.Writer, config *
 		Image: godo.DropletCreateImage{
 			Slug: config.DigitalOceanConfig.Image,
 		},
-		Tags: tags,
+		Tags:     tags,
+		UserData: config.UserData,
 	}
 
 	role := model.RoleMaster
@@ -87,6 +88,7 @@ func (s *CreateInstanceStep) Run(ctx context.Context, output io.Writer, config *
 		Region:   config.DigitalOceanConfig.Region,
 		State:    model.MachineStateBuilding,
 		Name:     config.DigitalOceanConfig.Name,
+		Pool:     config.NodePool.Name,
 	}
 
 	// Update node state in cluster
@@ -143,6 +143,34 @@ func TestFingerPrint3(t *testing.T) {
 	}
 }
 
+func TestFingerPrintKeyTypes(t *testing.T) {
+	testCases := []struct {
+		publicKey string
+		expected  string
+	}{
+		{
+			publicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIA4PjaCZ6b0yCxBz4g5WNsF/rEbuBN7qdxyhg0cgJUFX user@host\n",
+			expected:  "5d:38:85:7a:25:43:8f:df:cc:25:ab:0c:82:a2:40:76",
+		},
+		{
+			publicKey: "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBFiATlsZdVw8X61MTNb+dp6iYlQsK/lFlq4uJ77HLCHSCw7thCRRjnxmxgd/l/uiIuF7N/px2GIlB2rnYWrmvN4= root@vm",
+			expected:  "52:27:97:c0:bb:43:2d:b7:71:9e:54:ea:1c:7e:01:d9",
+		},
+	}
+
+	for _, testCase := range testCases {
+		fg, err := fingerprint(testCase.publicKey)
+
+		if err != nil {
+			t.Error(err)
+		}
+
+		if fg != testCase.expected {
+			t.Errorf("Wrong fingerprint expected %s actual %s", testCase.expected, fg)
+		}
+	}
+}
+
 func TestCreateKey(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -8,6 +8,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -17,6 +19,9 @@ const StepName = "docker"
 type Config struct {
 	Version string
 	Arch    string
+	// Distro is used in urls of docker repositories
+	Distro  string
+	Mirrors profile.Mirrors
 }
 
 type Step struct {
@@ -40,7 +45,7 @@ func New(tpl *template.Template) *Step {
 }
 
 func (t *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), t.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(context.Background(), steps.Template(config, t.script), config.Runner, out, toStepCfg(config))
 	if err != nil {
 		return errors.Wrap(err, "install docker step")
 	}
@@ -65,8 +70,16 @@ func (s *Step) Depends() []string {
 }
 
 func toStepCfg(c *steps.Config) Config {
-	return Config{
+	cfg := Config{
 		Version: c.Kube.DockerVersion,
 		Arch:    c.Kube.Arch,
+		Distro:  distro.Ubuntu,
+		Mirrors: c.Kube.Mirrors,
 	}
+
+	if d, err := distro.Get(c.Kube.OperatingSystem, c.Kube.OperatingSystemVersion); err == nil {
+		cfg.Distro = d.Name
+	}
+
+	return cfg
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -18,6 +20,7 @@ type Config struct {
 	K8SVersion      string
 	Arch            string
 	OperatingSystem string
+	Mirrors         profile.Mirrors
 }
 
 type Step struct {
@@ -41,7 +44,7 @@ func New(tpl *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), s.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(context.Background(), steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
 	if err != nil {
 		return errors.Wrap(err, "download k8s binary step")
 	}
@@ -69,6 +72,7 @@ func toStepCfg(c *steps.Config) Config {
 	return Config{
 		K8SVersion:      c.Kube.K8SVersion,
 		Arch:            c.Kube.Arch,
-		OperatingSystem: c.Kube.OperatingSystem,
+		OperatingSystem: distro.Platform,
+		Mirrors:         c.Kube.Mirrors,
 	}
 }
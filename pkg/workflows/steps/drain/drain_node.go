@@ -9,18 +9,21 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/sgerrors"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
 )
 
 const StepName = "drain"
 
 type Step struct {
 	script    *template.Template
-	getRunner func(string, *steps.Config) (runner.Runner, error)
+	getRunner func(model.Machine, *steps.Config) (runner.Runner, error)
 }
 
 func (s *Step) Rollback(context.Context, io.Writer, *steps.Config) error {
@@ -40,23 +43,23 @@ func Init() {
 func New(script *template.Template) *Step {
 	t := &Step{
 		script: script,
-		getRunner: func(masterIp string, config *steps.Config) (runner.Runner, error) {
-			if config.Provider == clouds.AWS {
-				//on aws default user name on ubuntu images are not root but ubuntu
-				//https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/AccessingInstancesLinux.html
-				config.Kube.SSHConfig.User = "ubuntu"
+		getRunner: func(master model.Machine, config *steps.Config) (runner.Runner, error) {
+			if config.Provider == clouds.Simulator {
+				return simulator.NewRunner(config.SimulatorConfig, master.ID)
 			}
 
-			cfg := ssh.Config{
-				Host:    masterIp,
-				Port:    config.Kube.SSHConfig.Port,
-				User:    config.Kube.SSHConfig.User,
-				Timeout: 10,
-				Key:     []byte(config.Kube.SSHConfig.BootstrapPrivateKey),
+			if config.Provider == clouds.AWS {
+				d, err := distro.Get(config.Kube.OperatingSystem, config.Kube.OperatingSystemVersion)
+				if err != nil {
+					return nil, err
+				}
+				config.Kube.SSHConfig.User = steps.CloudUser(config.Provider, d)
 			}
 
-			sshRunner, err := ssh.NewRunner(cfg)
+			cfg := steps.SSHConfigFor(config.Kube.SSHConfig, master)
+			cfg.Timeout = 10
 
+			sshRunner, err := ssh.RunnerFor(cfg, config.SSHPool)
 			if err != nil {
 				return nil, errors.Wrapf(err, "create ssh runner")
 			}
@@ -75,13 +78,13 @@ func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 		return errors.Wrapf(sgerrors.ErrNotFound, "master node not found")
 	}
 
-	r, err := s.getRunner(masterNode.PublicIp, config)
+	r, err := s.getRunner(*masterNode, config)
 
 	if err != nil {
 		return errors.Wrapf(err, "get runner")
 	}
 
-	err = steps.RunTemplate(ctx, s.script, r, out, config.DrainConfig)
+	err = steps.RunTemplate(ctx, steps.Template(config, s.script), r, out, config.DrainConfig)
 
 	if err != nil {
 		return errors.Wrap(err, "drain step")
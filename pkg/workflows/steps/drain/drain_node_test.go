@@ -16,6 +16,7 @@ import (
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
@@ -50,6 +51,8 @@ ftubvUfbIYGZDXxwqpBifsVm22U3lTggt1MSzSFzUaS6RkvOw+wW2g==
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
@@ -104,7 +107,7 @@ func TestDrain(t *testing.T) {
 
 	task := &Step{
 		script: tpl,
-		getRunner: func(masterIP string, config *steps.Config) (runner.Runner, error) {
+		getRunner: func(master model.Machine, config *steps.Config) (runner.Runner, error) {
 			return r, nil
 		},
 	}
@@ -134,7 +137,7 @@ func TestErrors(t *testing.T) {
 
 	task := &Step{
 		script: proxyTemplate,
-		getRunner: func(masterIP string, config *steps.Config) (runner.Runner, error) {
+		getRunner: func(master model.Machine, config *steps.Config) (runner.Runner, error) {
 			return r, nil
 		},
 	}
@@ -215,7 +218,7 @@ func TestNew(t *testing.T) {
 		},
 	}
 
-	if _, err := s.getRunner("10.20.30.40", cfg); err != nil {
+	if _, err := s.getRunner(model.Machine{PublicIp: "10.20.30.40"}, cfg); err != nil {
 		t.Errorf("Unexpected error when get runner %v", err)
 	}
 }
@@ -235,7 +238,7 @@ func TestNewErr(t *testing.T) {
 
 	cfg := &steps.Config{}
 
-	if _, err := s.getRunner("10.20.30.40", cfg); err == nil {
+	if _, err := s.getRunner(model.Machine{PublicIp: "10.20.30.40"}, cfg); err == nil {
 		t.Errorf("Error must not be nil")
 	}
 }
@@ -0,0 +1,238 @@
+// Package etcd has steps that save snapshots of etcd running on masters
+// of a kube to a backup store and restore them from it.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/catalog"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/runner"
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	SnapshotStepName = "etcd_snapshot"
+	RestoreStepName  = "etcd_restore"
+
+	// SnapshotPath is where the snapshot is kept on the master while
+	// it is transferred.
+	SnapshotPath = "/var/lib/supergiant/etcd/snapshot.db"
+)
+
+// Store keeps snapshots by their keys.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+type Config struct {
+	EtcdVersion  string
+	Mirrors      profile.Mirrors
+	SnapshotPath string
+
+	Name           string
+	PrivateIP      string
+	InitialCluster string
+	Token          string
+}
+
+func Init(store Store) {
+	snapshotTpl, err := tm.GetTemplate(SnapshotStepName)
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", SnapshotStepName))
+	}
+
+	restoreTpl, err := tm.GetTemplate(RestoreStepName)
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", RestoreStepName))
+	}
+
+	steps.RegisterStep(SnapshotStepName, NewSnapshotStep(snapshotTpl, store))
+	steps.RegisterStep(RestoreStepName, NewRestoreStep(restoreTpl, store))
+}
+
+// SnapshotKey is a key of the backup snapshot in the store.
+func SnapshotKey(kubeID, backupID string) string {
+	return fmt.Sprintf("%s/%s.db", kubeID, backupID)
+}
+
+// SnapshotStep saves snapshot of etcd on the master to the store
+// by the backup of the config.
+type SnapshotStep struct {
+	script *template.Template
+	store  Store
+}
+
+func NewSnapshotStep(script *template.Template, store Store) *SnapshotStep {
+	return &SnapshotStep{
+		script: script,
+		store:  store,
+	}
+}
+
+func (s *SnapshotStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if config.EtcdBackup == "" {
+		return errors.Errorf("%s: no backup", SnapshotStepName)
+	}
+
+	cfg, err := toStepCfg(config)
+	if err != nil {
+		return errors.Wrap(err, SnapshotStepName)
+	}
+
+	if err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, cfg); err != nil {
+		return errors.Wrap(err, SnapshotStepName)
+	}
+
+	buf := &bytes.Buffer{}
+	downloadErr := config.Runner.Download(ctx, SnapshotPath, buf)
+
+	// Snapshot has secrets of the kube, it is not left on the master
+	if err := removeSnapshot(ctx, config.Runner); err != nil {
+		return errors.Wrap(err, SnapshotStepName)
+	}
+
+	if downloadErr != nil {
+		return errors.Wrap(downloadErr, SnapshotStepName)
+	}
+
+	key := SnapshotKey(config.Kube.ID, config.EtcdBackup)
+	if err := s.store.Put(ctx, key, buf.Bytes()); err != nil {
+		return errors.Wrapf(err, "%s: store %s", SnapshotStepName, key)
+	}
+
+	fmt.Fprintf(out, "snapshot %s of %d bytes stored\n", key, buf.Len())
+
+	return nil
+}
+
+func (s *SnapshotStep) Name() string {
+	return SnapshotStepName
+}
+
+func (s *SnapshotStep) Description() string {
+	return "save etcd snapshot"
+}
+
+func (s *SnapshotStep) Depends() []string {
+	return nil
+}
+
+func (s *SnapshotStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+// RestoreStep replaces etcd data on the master with the snapshot
+// of the backup of the config.
+type RestoreStep struct {
+	script *template.Template
+	store  Store
+}
+
+func NewRestoreStep(script *template.Template, store Store) *RestoreStep {
+	return &RestoreStep{
+		script: script,
+		store:  store,
+	}
+}
+
+func (s *RestoreStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if config.EtcdBackup == "" {
+		return errors.Errorf("%s: no backup", RestoreStepName)
+	}
+
+	cfg, err := toStepCfg(config)
+	if err != nil {
+		return errors.Wrap(err, RestoreStepName)
+	}
+
+	key := SnapshotKey(config.Kube.ID, config.EtcdBackup)
+	data, err := s.store.Get(ctx, key)
+	if err != nil {
+		return errors.Wrapf(err, "%s: get %s", RestoreStepName, key)
+	}
+
+	err = config.Runner.Upload(ctx, bytes.NewReader(data), runner.File{
+		Path:  SnapshotPath,
+		Mode:  0600,
+		Owner: "root:root",
+	})
+	if err != nil {
+		return errors.Wrap(err, RestoreStepName)
+	}
+
+	if err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, cfg); err != nil {
+		return errors.Wrap(err, RestoreStepName)
+	}
+
+	return nil
+}
+
+func (s *RestoreStep) Name() string {
+	return RestoreStepName
+}
+
+func (s *RestoreStep) Description() string {
+	return "restore etcd from snapshot"
+}
+
+func (s *RestoreStep) Depends() []string {
+	return nil
+}
+
+func (s *RestoreStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func toStepCfg(config *steps.Config) (Config, error) {
+	release, err := catalog.Get(config.Kube.K8SVersion)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "etcd for kubernetes %s", config.Kube.K8SVersion)
+	}
+
+	return Config{
+		EtcdVersion:    release.Etcd,
+		Mirrors:        config.Kube.Mirrors,
+		SnapshotPath:   SnapshotPath,
+		Name:           config.Node.Name,
+		PrivateIP:      config.Node.PrivateIp,
+		InitialCluster: initialCluster(config.GetMasters()),
+		Token:          "restore-" + config.EtcdBackup,
+	}, nil
+}
+
+// initialCluster lists etcd members restored on masters, members are
+// named after machines.
+func initialCluster(masters map[string]*model.Machine) string {
+	members := make([]string, 0, len(masters))
+	for _, m := range masters {
+		if m == nil {
+			continue
+		}
+		members = append(members, fmt.Sprintf("%s=https://%s:2380", m.Name, m.PrivateIp))
+	}
+	sort.Strings(members)
+
+	return strings.Join(members, ",")
+}
+
+func removeSnapshot(ctx context.Context, r runner.Runner) error {
+	cmd, err := runner.NewCommand(ctx, "sudo rm -f "+runner.Quote(SnapshotPath), ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrapf(r.Run(cmd), "remove %s", SnapshotPath)
+}
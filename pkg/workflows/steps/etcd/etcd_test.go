@@ -0,0 +1,188 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type fakeRunner struct {
+	scripts  []string
+	snapshot []byte
+	uploaded []byte
+	file     runner.File
+}
+
+func (f *fakeRunner) Run(command *runner.Command) error {
+	f.scripts = append(f.scripts, command.Script)
+	_, err := io.Copy(command.Out, strings.NewReader(command.Script))
+	return err
+}
+
+func (f *fakeRunner) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	data, err := ioutil.ReadAll(content)
+	f.uploaded, f.file = data, dst
+	return err
+}
+
+func (f *fakeRunner) Download(ctx context.Context, src string, out io.Writer) error {
+	if f.snapshot == nil {
+		return errors.New("no such file")
+	}
+
+	_, err := out.Write(f.snapshot)
+	return err
+}
+
+type memStore map[string][]byte
+
+func (s memStore) Put(ctx context.Context, key string, data []byte) error {
+	s[key] = data
+	return nil
+}
+
+func (s memStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, sgerrors.ErrNotFound
+	}
+
+	return data, nil
+}
+
+func testConfig(r runner.Runner) *steps.Config {
+	masters := map[string]*model.Machine{
+		"master-2": {Name: "master-2", PrivateIp: "10.0.0.2"},
+		"master-1": {Name: "master-1", PrivateIp: "10.0.0.1"},
+	}
+
+	return &steps.Config{
+		Kube: model.Kube{
+			ID:         "kube1234",
+			K8SVersion: "1.14.3",
+			Masters:    masters,
+		},
+		Node:       *masters["master-1"],
+		Masters:    steps.NewMap(masters),
+		EtcdBackup: "b1",
+		Runner:     r,
+	}
+}
+
+func TestSnapshotStep(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(SnapshotStepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	r := &fakeRunner{snapshot: []byte("snapshot")}
+	store := memStore{}
+	output := &bytes.Buffer{}
+
+	if err := NewSnapshotStep(tpl, store).Run(context.Background(), output, testConfig(r)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if data := string(store[SnapshotKey("kube1234", "b1")]); data != "snapshot" {
+		t.Errorf("expected snapshot stored actual %q", data)
+	}
+
+	for _, expected := range []string{"etcd/v3.3.10", "snapshot save " + SnapshotPath} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("%s not found in output %s", expected, output.String())
+		}
+	}
+
+	if last := r.scripts[len(r.scripts)-1]; !strings.Contains(last, "rm -f") {
+		t.Errorf("snapshot must be removed from the master, last script %s", last)
+	}
+}
+
+func TestSnapshotStepDownloadError(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(SnapshotStepName)
+	r := &fakeRunner{}
+	store := memStore{}
+
+	if err := NewSnapshotStep(tpl, store).Run(context.Background(), &bytes.Buffer{}, testConfig(r)); err == nil {
+		t.Fatal("error expected")
+	}
+
+	if len(store) != 0 {
+		t.Errorf("nothing must be stored %v", store)
+	}
+
+	if last := r.scripts[len(r.scripts)-1]; !strings.Contains(last, "rm -f") {
+		t.Errorf("snapshot must be removed from the master, last script %s", last)
+	}
+}
+
+func TestRestoreStep(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(RestoreStepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	r := &fakeRunner{}
+	store := memStore{SnapshotKey("kube1234", "b1"): []byte("snapshot")}
+	output := &bytes.Buffer{}
+
+	if err := NewRestoreStep(tpl, store).Run(context.Background(), output, testConfig(r)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if string(r.uploaded) != "snapshot" || r.file.Path != SnapshotPath || r.file.Mode != 0600 {
+		t.Errorf("wrong upload %q to %+v", r.uploaded, r.file)
+	}
+
+	for _, expected := range []string{
+		"--name master-1",
+		"--initial-cluster master-1=https://10.0.0.1:2380,master-2=https://10.0.0.2:2380",
+		"--initial-cluster-token restore-b1",
+		"--initial-advertise-peer-urls https://10.0.0.1:2380",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("%s not found in output %s", expected, output.String())
+		}
+	}
+}
+
+func TestRestoreStepNoSnapshot(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(RestoreStepName)
+	r := &fakeRunner{}
+
+	err := NewRestoreStep(tpl, memStore{}).Run(context.Background(), &bytes.Buffer{}, testConfig(r))
+	if !sgerrors.IsNotFound(errors.Cause(err)) {
+		t.Errorf("not found error expected actual %v", err)
+	}
+
+	if len(r.scripts) != 0 {
+		t.Errorf("nothing must be run on the master %v", r.scripts)
+	}
+}
@@ -42,7 +42,12 @@ func NewCreateInstanceStep(period, timeout time.Duration) *CreateInstanceStep {
 
 			return &computeService{
 				getFromFamily: func(ctx context.Context, config steps.GCEConfig) (*compute.Image, error) {
-					return client.Images.GetFromFamily("ubuntu-os-cloud", config.ImageFamily).Do()
+					project := config.ImageProject
+					// Kubes created before distro could be chosen run ubuntu
+					if project == "" {
+						project = "ubuntu-os-cloud"
+					}
+					return client.Images.GetFromFamily(project, config.ImageFamily).Do()
 				},
 				getMachineTypes: func(ctx context.Context,
 					config steps.GCEConfig) (*compute.MachineType, error) {
@@ -183,6 +188,17 @@ func (s *CreateInstanceStep) Run(ctx context.Context, output io.Writer,
 		},
 	}
 
+	if config.UserData != "" {
+		// cloud-init of ubuntu images reads user-data key of metadata, it is
+		// kept when metadata is replaced after the instance is created
+		userData := &compute.MetadataItems{
+			Key:   "user-data",
+			Value: &config.UserData,
+		}
+		instance.Metadata.Items = append(instance.Metadata.Items, userData)
+		metadata.Items = append(metadata.Items, userData)
+	}
+
 	// create the instance.
 	_, err = svc.insertInstance(ctx, config.GCEConfig, instance)
 
@@ -226,6 +242,7 @@ func (s *CreateInstanceStep) Run(ctx context.Context, output io.Writer,
 		// cluster wide and we need az to delete instance.
 		// TODO(stgleb): consider adding AZ to node struct
 		Region: config.GCEConfig.AvailabilityZone,
+		Pool:   config.NodePool.Name,
 	}
 
 	// Update node state in cluster
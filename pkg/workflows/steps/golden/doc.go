@@ -0,0 +1,6 @@
+// Package golden keeps rendered scripts of the template steps for a matrix
+// of profiles, tests compare them with the current templates so changes of
+// the provisioning scripts are visible in review.
+//
+// Run go test ./pkg/workflows/steps/golden -update to regenerate the files.
+package golden
@@ -0,0 +1,334 @@
+package golden
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/cri"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/runner/dry"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/apply"
+	"github.com/supergiant/control/pkg/workflows/steps/authorizedkeys"
+	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
+	"github.com/supergiant/control/pkg/workflows/steps/certificates"
+	"github.com/supergiant/control/pkg/workflows/steps/cloudcontroller"
+	"github.com/supergiant/control/pkg/workflows/steps/clustercheck"
+	"github.com/supergiant/control/pkg/workflows/steps/cni"
+	"github.com/supergiant/control/pkg/workflows/steps/containerd"
+	"github.com/supergiant/control/pkg/workflows/steps/crio"
+	"github.com/supergiant/control/pkg/workflows/steps/dashboard"
+	"github.com/supergiant/control/pkg/workflows/steps/docker"
+	"github.com/supergiant/control/pkg/workflows/steps/downloadk8sbinary"
+	"github.com/supergiant/control/pkg/workflows/steps/drain"
+	"github.com/supergiant/control/pkg/workflows/steps/evacuate"
+	"github.com/supergiant/control/pkg/workflows/steps/helm"
+	"github.com/supergiant/control/pkg/workflows/steps/httpproxy"
+	"github.com/supergiant/control/pkg/workflows/steps/install_app"
+	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
+	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
+	"github.com/supergiant/control/pkg/workflows/steps/network"
+	"github.com/supergiant/control/pkg/workflows/steps/poststart"
+	"github.com/supergiant/control/pkg/workflows/steps/prometheus"
+	"github.com/supergiant/control/pkg/workflows/steps/storageclass"
+	"github.com/supergiant/control/pkg/workflows/steps/tiller"
+	"github.com/supergiant/control/pkg/workflows/steps/uncordon"
+	"github.com/supergiant/control/pkg/workflows/steps/upgrade"
+	"github.com/supergiant/control/templates"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var (
+	providers        = []clouds.Name{clouds.AWS, clouds.DigitalOcean, clouds.GCE}
+	networkProviders = []string{"Flannel", "Calico", "Weave"}
+	rbac             = []bool{true, false}
+	roles            = []string{"bootstrap", "master", "node"}
+	k8sVersions      = []string{"1.14.3", "1.15.1"}
+	operatingSystems = []string{"linux", "centos"}
+	runtimes         = []string{cri.Docker, cri.Containerd, cri.CRIO}
+
+	// mirrors are set for air-gapped cases
+	mirrors = profile.Mirrors{
+		PackageRepository: "http://mirror.golden.local",
+		HTTPProxy:         "http://proxy.golden.local:3128",
+		NoProxy:           "mirror.golden.local,registry.golden.local",
+		ImageRegistry:     "registry.golden.local:5000",
+		BinariesURL:       "http://mirror.golden.local/binaries",
+		HelmRepositories: map[string]string{
+			"stable":     "http://charts.golden.local/stable",
+			"supergiant": "http://charts.golden.local/supergiant",
+		},
+	}
+
+	// skipped are template steps that do not run on the machine
+	// being provisioned, drain connects to a master on its own.
+	skipped = map[string]bool{
+		drain.StepName: true,
+	}
+)
+
+// testCase is a single combination of the matrix.
+type testCase struct {
+	provider        clouds.Name
+	networkProvider string
+	rbac            bool
+	role            string
+	k8sVersion      string
+	operatingSystem string
+	runtime         string
+	airGapped       bool
+}
+
+func (c testCase) String() string {
+	rbac := "norbac"
+	if c.rbac {
+		rbac = "rbac"
+	}
+
+	parts := []string{string(c.provider), strings.ToLower(c.networkProvider),
+		rbac, c.role, c.k8sVersion, c.operatingSystem, c.runtime}
+	if c.airGapped {
+		parts = append(parts, "airgapped")
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func (c testCase) config(t *testing.T) *steps.Config {
+	cfg, err := steps.NewConfig("golden", "", profile.Profile{
+		Provider:         c.provider,
+		K8SVersion:       c.k8sVersion,
+		K8SServicesCIDR:  "10.3.0.0/16",
+		K8SAPIPort:       443,
+		NetworkProvider:  c.networkProvider,
+		NetworkType:      "vxlan",
+		CIDR:             "10.0.0.0/16",
+		DockerVersion:    "18.06.3",
+		ContainerRuntime: c.runtime,
+		HelmVersion:      "2.11.0",
+		Arch:             "amd64",
+		OperatingSystem:  c.operatingSystem,
+		RBACEnabled:      c.rbac,
+		Mirrors:          c.mirrors(),
+		PublicKey:        "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIA4PjaCZ6b0yCxBz4g5WNsF/rEbuBN7qdxyhg0cgJUFX user@host",
+	})
+	if err != nil {
+		t.Fatalf("new config for %s: %v", c, err)
+	}
+
+	cfg.DryRun = true
+	cfg.IsBootstrap = c.role == "bootstrap"
+	cfg.IsMaster = c.role != "node"
+
+	cfg.Kube.ID = "golden"
+	cfg.Kube.ExternalDNSName = "external.golden.local"
+	cfg.Kube.InternalDNSName = "internal.golden.local"
+	cfg.Kube.BootstrapToken = "abcdef.0123456789abcdef"
+	cfg.Kube.Auth.CACert = "ca-cert"
+	cfg.Kube.Auth.CAKey = "ca-key"
+	cfg.Kube.Auth.CACertHash = "sha256:cacerthash"
+	cfg.Kube.Auth.CertificateKey = "certificatekey"
+	cfg.Kube.Auth.AdminCert = "admin-cert"
+	cfg.Kube.Auth.AdminKey = "admin-key"
+
+	cfg.Node = model.Machine{
+		ID:        "node-id",
+		Name:      "golden-" + c.role,
+		PublicIp:  "203.0.113.10",
+		PrivateIp: "10.0.0.10",
+		Provider:  c.provider,
+		Role:      model.RoleNode,
+		State:     model.MachineStateProvisioning,
+	}
+	if cfg.IsMaster {
+		cfg.Node.Role = model.RoleMaster
+	}
+
+	cfg.ApplyConfig.Data = "kind: Namespace\n"
+	cfg.InstallAppConfig = steps.InstallAppConfig{
+		Name:      "app",
+		RepoName:  "stable",
+		ChartName: "app",
+		Namespace: "default",
+	}
+
+	return cfg
+}
+
+func (c testCase) mirrors() profile.Mirrors {
+	if c.airGapped {
+		return mirrors
+	}
+
+	return profile.Mirrors{}
+}
+
+func testCases() []testCase {
+	var cases []testCase
+
+	for _, provider := range providers {
+		for _, networkProvider := range networkProviders {
+			for _, enabled := range rbac {
+				for _, role := range roles {
+					for _, version := range k8sVersions {
+						for _, operatingSystem := range operatingSystems {
+							for _, runtime := range runtimes {
+								cases = append(cases, testCase{
+									provider:        provider,
+									networkProvider: networkProvider,
+									rbac:            enabled,
+									role:            role,
+									k8sVersion:      version,
+									operatingSystem: operatingSystem,
+									runtime:         runtime,
+								})
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// air-gapped machines are covered by a subset of the matrix
+	for _, networkProvider := range networkProviders {
+		for _, role := range roles {
+			for _, operatingSystem := range operatingSystems {
+				for _, runtime := range runtimes {
+					cases = append(cases, testCase{
+						provider:        clouds.AWS,
+						networkProvider: networkProvider,
+						rbac:            true,
+						role:            role,
+						k8sVersion:      k8sVersions[len(k8sVersions)-1],
+						operatingSystem: operatingSystem,
+						runtime:         runtime,
+						airGapped:       true,
+					})
+				}
+			}
+		}
+	}
+
+	return cases
+}
+
+func setup(t *testing.T) []string {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatalf("init templates: %v", err)
+	}
+
+	apply.Init()
+	authorizedkeys.Init()
+	bootstraptoken.Init()
+	certificates.Init()
+	cloudcontroller.Init()
+	clustercheck.Init()
+	cni.Init()
+	dashboard.Init()
+	containerd.Init()
+	crio.Init()
+	docker.Init()
+	downloadk8sbinary.Init()
+	evacuate.Init()
+	helm.Init()
+	httpproxy.Init()
+	install_app.Init()
+	kubeadm.Init()
+	kubelet.Init()
+	network.Init()
+	poststart.Init()
+	prometheus.Init()
+	storageclass.Init()
+	tiller.Init()
+	uncordon.Init()
+	upgrade.Init()
+
+	// Every template that backs a registered step is rendered, so new
+	// templates are covered once their step is initialized above.
+	var names []string
+	for name := range templates.Default {
+		if skipped[name] || steps.GetStep(name) == nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// render runs the step for every case and groups cases by their output.
+func render(t *testing.T, name string) []byte {
+	var (
+		outputs []string
+		cases   = make(map[string][]string)
+	)
+
+	for _, c := range testCases() {
+		r := dry.NewDryRunner()
+		cfg := c.config(t)
+		cfg.Runner = r
+
+		out := &bytes.Buffer{}
+		if err := steps.GetStep(name).Run(context.Background(), out, cfg); err != nil {
+			t.Errorf("run step %s for %s: %v", name, c, err)
+			continue
+		}
+
+		// Bootstrap token is generated by the step itself
+		script := strings.Replace(r.GetOutput(), cfg.Kube.BootstrapToken, "<bootstrap-token>", -1)
+
+		if _, ok := cases[script]; !ok {
+			outputs = append(outputs, script)
+		}
+		cases[script] = append(cases[script], c.String())
+	}
+
+	buf := &bytes.Buffer{}
+	for _, script := range outputs {
+		for _, c := range cases[script] {
+			fmt.Fprintf(buf, "### case: %s\n", c)
+		}
+		fmt.Fprintf(buf, "%s\n", script)
+	}
+
+	return buf.Bytes()
+}
+
+func TestTemplates(t *testing.T) {
+	for _, name := range setup(t) {
+		t.Run(name, func(t *testing.T) {
+			actual := render(t, name)
+			path := filepath.Join("testdata", name+".golden")
+
+			if *update {
+				if err := ioutil.WriteFile(path, actual, 0644); err != nil {
+					t.Fatalf("update golden file %s: %v", path, err)
+				}
+				return
+			}
+
+			expected, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file %s: %v, run tests with -update to create it", path, err)
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("rendered %s differs from %s, run tests with -update "+
+					"and review the diff", name, path)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/poststart"
@@ -21,6 +23,7 @@ type Config struct {
 	HelmVersion     string
 	OperatingSystem string
 	Arch            string
+	Mirrors         profile.Mirrors
 }
 
 type Step struct {
@@ -46,7 +49,7 @@ func New(script *template.Template) *Step {
 }
 
 func (j *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), j.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(context.Background(), steps.Template(config, j.script), config.Runner, out, toStepCfg(config))
 
 	if err != nil {
 		return errors.Wrap(err, "install helm step")
@@ -74,7 +77,8 @@ func (s *Step) Depends() []string {
 func toStepCfg(c *steps.Config) Config {
 	return Config{
 		HelmVersion:     c.Kube.HelmVersion,
-		OperatingSystem: c.Kube.OperatingSystem,
+		OperatingSystem: distro.Platform,
 		Arch:            c.Kube.Arch,
+		Mirrors:         c.Kube.Mirrors,
 	}
 }
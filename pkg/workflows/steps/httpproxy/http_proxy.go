@@ -0,0 +1,91 @@
+package httpproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const StepName = "http_proxy"
+
+type Config struct {
+	HTTPProxy string
+	NoProxy   string
+}
+
+type Step struct {
+	script *template.Template
+}
+
+func Init() {
+	tpl, err := tm.GetTemplate(StepName)
+
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", StepName))
+	}
+
+	steps.RegisterStep(StepName, New(tpl))
+}
+
+func New(tpl *template.Template) *Step {
+	return &Step{
+		script: tpl,
+	}
+}
+
+func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
+	if err != nil {
+		return errors.Wrap(err, "configure proxy step")
+	}
+
+	return nil
+}
+
+func (s *Step) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func (s *Step) Name() string {
+	return StepName
+}
+
+func (s *Step) Description() string {
+	return "Configure http proxy"
+}
+
+func (s *Step) Depends() []string {
+	return nil
+}
+
+func toStepCfg(c *steps.Config) Config {
+	return Config{
+		HTTPProxy: c.Kube.Mirrors.HTTPProxy,
+		NoProxy:   noProxy(c),
+	}
+}
+
+// noProxy adds addresses of the cluster to the ones set by user,
+// machines reach each other and services directly.
+func noProxy(c *steps.Config) string {
+	hosts := []string{"localhost", "127.0.0.1", ".svc", ".cluster.local"}
+	if c.Kube.Mirrors.NoProxy != "" {
+		hosts = append(strings.Split(c.Kube.Mirrors.NoProxy, ","), hosts...)
+	}
+
+	for _, host := range []string{c.Kube.InternalDNSName, c.Kube.ExternalDNSName,
+		c.Kube.ServicesCIDR, c.Kube.Networking.CIDR, c.Node.PrivateIp} {
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return strings.Join(hosts, ",")
+}
@@ -0,0 +1,78 @@
+package httpproxy
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestConfigureProxy(t *testing.T) {
+	if err := templatemanager.Init(""); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	testCases := []struct {
+		description string
+		mirrors     profile.Mirrors
+		expected    []string
+	}{
+		{
+			description: "no proxy",
+		},
+		{
+			description: "proxy",
+			mirrors: profile.Mirrors{
+				HTTPProxy: "http://proxy.local:3128",
+				NoProxy:   "mirror.local",
+			},
+			expected: []string{
+				"HTTP_PROXY=http://proxy.local:3128",
+				"NO_PROXY=mirror.local,localhost,127.0.0.1,.svc,.cluster.local,internal.local,10.3.0.0/16,10.0.0.10",
+				`Acquire::http::Proxy "http://proxy.local:3128";`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		output := &bytes.Buffer{}
+		config := &steps.Config{
+			Kube: model.Kube{
+				InternalDNSName: "internal.local",
+				ServicesCIDR:    "10.3.0.0/16",
+				Mirrors:         testCase.mirrors,
+			},
+			Node: model.Machine{
+				PrivateIp: "10.0.0.10",
+			},
+			Runner: &testutils.MockRunner{},
+		}
+
+		if err := New(tpl).Run(context.Background(), output, config); err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.description, err)
+			continue
+		}
+
+		if len(testCase.expected) == 0 && strings.TrimSpace(output.String()) != "" {
+			t.Errorf("%s: output must be empty %s", testCase.description, output.String())
+		}
+
+		for _, expected := range testCase.expected {
+			if !strings.Contains(output.String(), expected) {
+				t.Errorf("%s: %s not found in output %s", testCase.description,
+					expected, output.String())
+			}
+		}
+	}
+}
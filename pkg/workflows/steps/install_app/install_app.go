@@ -41,7 +41,7 @@ func New(script *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.script, config.Runner, out, config.InstallAppConfig)
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, config.InstallAppConfig)
 
 	if err != nil {
 		return errors.Wrap(err, "install app step")
@@ -9,14 +9,23 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/supergiant/control/pkg/catalog"
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/cri"
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/runner"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/docker"
+	"github.com/supergiant/control/pkg/workflows/steps/containerruntime"
 )
 
 const (
 	StepName = "kubeadm"
+	// ConfigTemplateName is a template of kubeadm config file
+	ConfigTemplateName = "kubeadm_config"
+
+	configPath = "/etc/supergiant/kubeadm.conf"
 )
 
 type Config struct {
@@ -36,10 +45,19 @@ type Config struct {
 	APIServerPort   int64
 	NodeIp          string
 	ProviderID      string
+	// OSFamily chooses package manager kubeadm is installed with
+	OSFamily string
+	// CRISocket is empty for docker, kubeadm detects it on its own
+	CRISocket string
+	Mirrors   profile.Mirrors
+	// NodeLabels and Taints of the node pool are registered on join
+	NodeLabels string
+	Taints     []profile.Taint
 }
 
 type Step struct {
-	script *template.Template
+	script     *template.Template
+	kubeadmCfg *template.Template
 }
 
 func Init() {
@@ -49,12 +67,19 @@ func Init() {
 		panic(fmt.Sprintf("template %s not found", StepName))
 	}
 
-	steps.RegisterStep(StepName, New(tpl))
+	cfgTpl, err := tm.GetTemplate(ConfigTemplateName)
+
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", ConfigTemplateName))
+	}
+
+	steps.RegisterStep(StepName, New(tpl, cfgTpl))
 }
 
-func New(script *template.Template) *Step {
+func New(script, kubeadmCfg *template.Template) *Step {
 	t := &Step{
-		script: script,
+		script:     script,
+		kubeadmCfg: kubeadmCfg,
 	}
 
 	return t
@@ -65,7 +90,18 @@ func (t *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 		config.Kube.ID, config.IsBootstrap, config.Kube.ExternalDNSName,
 		config.Kube.InternalDNSName)
 
-	err := steps.RunTemplate(ctx, t.script, config.Runner, out, toStepCfg(config))
+	cfg, err := toStepCfg(config)
+	if err != nil {
+		return errors.Wrap(err, "kubeadm step")
+	}
+
+	err = steps.UploadTemplate(ctx, steps.Template(config, t.kubeadmCfg), config.Runner,
+		runner.File{Path: configPath, Mode: 0600, Owner: "root:root"}, cfg)
+	if err != nil {
+		return errors.Wrap(err, "upload kubeadm config")
+	}
+
+	err = steps.RunTemplate(ctx, steps.Template(config, t.script), config.Runner, out, cfg)
 
 	if err != nil {
 		return errors.Wrap(err, "kubeadm step")
@@ -87,7 +123,7 @@ func (t *Step) Description() string {
 }
 
 func (s *Step) Depends() []string {
-	return []string{docker.StepName}
+	return []string{containerruntime.StepName}
 }
 
 // TODO: cloud profiles is deprecated by kubernetes, use controller-managers
@@ -112,9 +148,19 @@ func toProviderID(cloudName clouds.Name, id string) string {
 	return ""
 }
 
-func toStepCfg(c *steps.Config) Config {
+func toStepCfg(c *steps.Config) (Config, error) {
+	release, err := catalog.Get(c.Kube.K8SVersion)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "kubeadm for kubernetes %s", c.Kube.K8SVersion)
+	}
+
+	d, err := distro.Get(c.Kube.OperatingSystem, c.Kube.OperatingSystemVersion)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "kubeadm")
+	}
+
 	return Config{
-		KubeadmVersion:  "1.15.1", // TODO(stgleb): get it from available versions once we have them
+		KubeadmVersion:  release.Kubeadm,
 		K8SVersion:      c.Kube.K8SVersion,
 		IsBootstrap:     c.IsBootstrap,
 		IsMaster:        c.IsMaster,
@@ -130,5 +176,10 @@ func toStepCfg(c *steps.Config) Config {
 		APIServerPort:   c.Kube.APIServerPort,
 		NodeIp:          c.Node.PrivateIp,
 		ProviderID:      toProviderID(c.Kube.Provider, c.Node.ID),
-	}
+		OSFamily:        d.Family,
+		CRISocket:       cri.Socket(c.Kube.ContainerRuntime),
+		Mirrors:         c.Kube.Mirrors,
+		NodeLabels:      c.NodePool.NodeLabels(),
+		Taints:          c.NodePool.Taints,
+	}, nil
 }
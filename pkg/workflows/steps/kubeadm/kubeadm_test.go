@@ -13,15 +13,20 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/distro"
 	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/docker"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
@@ -48,6 +53,12 @@ func TestKubeadm(t *testing.T) {
 		t.Fatal("template not found")
 	}
 
+	cfgTpl, _ := templatemanager.GetTemplate(ConfigTemplateName)
+
+	if cfgTpl == nil {
+		t.Fatal("config template not found")
+	}
+
 	output := new(bytes.Buffer)
 
 	cfg := &steps.Config{
@@ -55,6 +66,7 @@ func TestKubeadm(t *testing.T) {
 		IsMaster:    true,
 		IsBootstrap: true,
 		Kube: model.Kube{
+			K8SVersion: "1.15.1",
 			Networking: model.Networking{
 				CIDR: "10.0.0.0/24",
 			},
@@ -70,17 +82,114 @@ func TestKubeadm(t *testing.T) {
 
 	task := &Step{
 		tpl,
+		cfgTpl,
 	}
 
 	err = task.Run(context.Background(), output, cfg)
 	require.Nil(t, err)
 
-	if !strings.Contains(output.String(), cfg.Kube.Networking.CIDR) {
-		t.Errorf("CIDR %s not found in %s", cfg.Kube.Networking.CIDR, output.String())
+	kubeadmConf, ok := r.Files[configPath]
+	if !ok {
+		t.Fatalf("kubeadm config %s has not been uploaded", configPath)
 	}
 
-	if !strings.Contains(output.String(), cfg.Kube.InternalDNSName) {
-		t.Errorf("LoadBalancerHost %s not found in %s", cfg.Kube.InternalDNSName, output.String())
+	if !strings.Contains(kubeadmConf, cfg.Kube.Networking.CIDR) {
+		t.Errorf("CIDR %s not found in %s", cfg.Kube.Networking.CIDR, kubeadmConf)
+	}
+
+	if !strings.Contains(kubeadmConf, "kind: InitConfiguration") {
+		t.Errorf("init configuration not found in %s", kubeadmConf)
+	}
+
+	if !strings.Contains(kubeadmConf, cfg.Kube.InternalDNSName) {
+		t.Errorf("LoadBalancerHost %s not found in %s", cfg.Kube.InternalDNSName, kubeadmConf)
+	}
+
+	if strings.Contains(output.String(), "kind: InitConfiguration") {
+		t.Errorf("kubeadm config must not be a part of the script %s", output.String())
+	}
+}
+
+func TestKubeadmNodePool(t *testing.T) {
+	if err := templatemanager.Init("../../../../templates"); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	cfgTpl, _ := templatemanager.GetTemplate(ConfigTemplateName)
+
+	r := &fakeRunner{}
+	cfg := &steps.Config{
+		Provider: clouds.AWS,
+		Kube: model.Kube{
+			K8SVersion:      "1.15.1",
+			InternalDNSName: "internal.dns.name",
+		},
+		Runner: r,
+		NodePool: profile.NodePool{
+			Name:   "gpu",
+			Labels: map[string]string{"accelerator": "nvidia", "disk": "ssd"},
+			Taints: []profile.Taint{
+				{Key: "dedicated", Value: "gpu", Effect: profile.TaintNoSchedule},
+			},
+		},
+	}
+
+	err := New(tpl, cfgTpl).Run(context.Background(), ioutil.Discard, cfg)
+	require.Nil(t, err)
+
+	expected := `    node-labels: "supergiant.io/node-pool=gpu,accelerator=nvidia,disk=ssd"
+  taints:
+  - key: "dedicated"
+    value: "gpu"
+    effect: NoSchedule
+discovery:`
+	if !strings.Contains(r.Files[configPath], expected) {
+		t.Errorf("labels and taints not found in %s", r.Files[configPath])
+	}
+
+	cfg.NodePool = profile.NodePool{}
+	err = New(tpl, cfgTpl).Run(context.Background(), ioutil.Discard, cfg)
+	require.Nil(t, err)
+
+	if strings.Contains(r.Files[configPath], "node-labels") ||
+		strings.Contains(r.Files[configPath], "taints") {
+		t.Errorf("unexpected labels or taints in %s", r.Files[configPath])
+	}
+}
+
+func TestKubeadmOSFamily(t *testing.T) {
+	if err := templatemanager.Init("../../../../templates"); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	cfgTpl, _ := templatemanager.GetTemplate(ConfigTemplateName)
+
+	testCases := []struct {
+		os         string
+		expected   string
+		unexpected string
+	}{
+		{distro.Ubuntu, "apt-get install -y kubelet=1.15.1-00", "yum"},
+		{distro.CentOS, "yum install -y kubelet-1.15.1", "apt-get"},
+	}
+
+	for _, testCase := range testCases {
+		output := new(bytes.Buffer)
+		cfg := &steps.Config{
+			Kube: model.Kube{
+				K8SVersion:      "1.15.1",
+				OperatingSystem: testCase.os,
+			},
+			Runner: &fakeRunner{},
+		}
+
+		err := New(steps.Template(cfg, tpl), cfgTpl).Run(context.Background(), output, cfg)
+		require.Nil(t, err, testCase.os)
+
+		require.Contains(t, output.String(), testCase.expected, testCase.os)
+		require.NotContains(t, output.String(), testCase.unexpected, testCase.os)
 	}
 }
 
@@ -95,11 +204,15 @@ func TestStartKubeadmError(t *testing.T) {
 
 	output := new(bytes.Buffer)
 	config := &steps.Config{
+		Kube: model.Kube{
+			K8SVersion: "1.15.1",
+		},
 		Runner: r,
 	}
 
 	j := &Step{
 		kubeletScriptTemplate,
+		kubeletScriptTemplate,
 	}
 
 	err = j.Run(context.Background(), output, config)
@@ -115,6 +228,23 @@ func TestStartKubeadmError(t *testing.T) {
 	}
 }
 
+func TestKubeadmUnsupportedVersion(t *testing.T) {
+	tpl, err := template.New(StepName).Parse("")
+	require.Nil(t, err)
+
+	r := &fakeRunner{}
+	cfg := &steps.Config{
+		Kube: model.Kube{
+			K8SVersion: "1.9.11",
+		},
+		Runner: r,
+	}
+
+	err = New(tpl, tpl).Run(context.Background(), ioutil.Discard, cfg)
+	require.True(t, sgerrors.IsNotFound(err), "unexpected error %v", err)
+	require.Empty(t, r.Files, "nothing must be uploaded")
+}
+
 func TestStepName(t *testing.T) {
 	s := Step{}
 
@@ -142,17 +272,24 @@ func TestStep_Rollback(t *testing.T) {
 
 func TestNew(t *testing.T) {
 	tpl := template.New("test")
-	s := New(tpl)
+	cfgTpl := template.New("config")
+	s := New(tpl, cfgTpl)
 
 	if s.script != tpl {
 		t.Errorf("Wrong template expected %v actual %v", tpl, s.script)
 	}
+
+	if s.kubeadmCfg != cfgTpl {
+		t.Errorf("Wrong config template expected %v actual %v", cfgTpl, s.kubeadmCfg)
+	}
 }
 
 func TestInit(t *testing.T) {
 	templatemanager.SetTemplate(StepName, &template.Template{})
+	templatemanager.SetTemplate(ConfigTemplateName, &template.Template{})
 	Init()
 	templatemanager.DeleteTemplate(StepName)
+	templatemanager.DeleteTemplate(ConfigTemplateName)
 
 	s := steps.GetStep(StepName)
 
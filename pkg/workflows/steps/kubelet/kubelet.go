@@ -5,19 +5,24 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/runner"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/docker"
+	"github.com/supergiant/control/pkg/workflows/steps/containerruntime"
 	"github.com/supergiant/control/pkg/workflows/util"
 )
 
 const (
 	StepName = "kubelet"
 
+	adminCertPath = "/etc/kubernetes/pki/admin.crt"
+	adminKeyPath  = "/etc/kubernetes/pki/admin.key"
+
 	// LabelNodeRole specifies the role of a node
 	LabelNodeRole = "kubernetes.io/role"
 )
@@ -36,11 +41,6 @@ type Config struct {
 	// TODO: this shouldn't be a part of SANs
 	// https://kubernetes.io/docs/setup/certificates/#all-certificates
 	KubernetesSvcIP string `json:"kubernetesSvcIp"`
-
-	AdminCert string `json:"adminCert"`
-	AdminKey  string `json:"adminKey"`
-	CACert    string `json:"caCert"`
-	CAKey     string `json:"caKey"`
 }
 
 type Step struct {
@@ -71,7 +71,23 @@ func (t *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 		return errors.Wrap(err, "build step config")
 	}
 
-	err = steps.RunTemplate(ctx, t.script, config.Runner, out, c)
+	// Workers use admin credentials to get kubelet certificate signed,
+	// they are removed by the script once certificate is approved.
+	if !config.IsMaster {
+		err = config.Runner.Upload(ctx, strings.NewReader(config.Kube.Auth.AdminCert),
+			runner.File{Path: adminCertPath, Mode: 0644, Owner: "root:root"})
+		if err != nil {
+			return errors.Wrap(err, "upload admin certificate")
+		}
+
+		err = config.Runner.Upload(ctx, strings.NewReader(config.Kube.Auth.AdminKey),
+			runner.File{Path: adminKeyPath, Mode: 0600, Owner: "root:root"})
+		if err != nil {
+			return errors.Wrap(err, "upload admin key")
+		}
+	}
+
+	err = steps.RunTemplate(ctx, steps.Template(config, t.script), config.Runner, out, c)
 	if err != nil {
 		return errors.Wrap(err, "install kubelet step")
 	}
@@ -92,7 +108,7 @@ func (t *Step) Description() string {
 }
 
 func (s *Step) Depends() []string {
-	return []string{docker.StepName}
+	return []string{containerruntime.StepName}
 }
 
 func toStepCfg(c *steps.Config) (Config, error) {
@@ -111,10 +127,6 @@ func toStepCfg(c *steps.Config) (Config, error) {
 		NodeName:         c.Node.Name,
 		PrivateIP:        c.Node.PrivateIp,
 		PublicIP:         c.Node.PublicIp,
-		CACert:           c.Kube.Auth.CACert,
-		CAKey:            c.Kube.Auth.CAKey,
-		AdminCert:        c.Kube.Auth.AdminCert,
-		AdminKey:         c.Kube.Auth.AdminKey,
 		APIServerPort:    c.Kube.APIServerPort,
 		UserName:         c.Kube.SSHConfig.User,
 		ServicesCIDR:     c.Kube.ServicesCIDR,
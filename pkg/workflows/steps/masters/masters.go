@@ -0,0 +1,173 @@
+// Package masters has steps that add masters to a running kube and remove
+// them from it, the steps run their commands on another master of the kube.
+package masters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/ssh"
+	"github.com/supergiant/control/pkg/sgerrors"
+	tm "github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
+)
+
+const (
+	UploadCertsStepName      = "upload_certs"
+	RemoveEtcdMemberStepName = "remove_etcd_member"
+)
+
+type getRunnerFn func(model.Machine, *steps.Config) (runner.Runner, error)
+
+func Init() {
+	uploadTpl, err := tm.GetTemplate(UploadCertsStepName)
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", UploadCertsStepName))
+	}
+
+	removeTpl, err := tm.GetTemplate(RemoveEtcdMemberStepName)
+	if err != nil {
+		panic(fmt.Sprintf("template %s not found", RemoveEtcdMemberStepName))
+	}
+
+	steps.RegisterStep(UploadCertsStepName, NewUploadCertsStep(uploadTpl))
+	steps.RegisterStep(RemoveEtcdMemberStepName, NewRemoveEtcdMemberStep(removeTpl))
+}
+
+// UploadCertsStep uploads control plane certificates with the certificate
+// key of the kube, so that the new master can join the kube.
+type UploadCertsStep struct {
+	script    *template.Template
+	getRunner getRunnerFn
+}
+
+func NewUploadCertsStep(script *template.Template) *UploadCertsStep {
+	return &UploadCertsStep{
+		script:    script,
+		getRunner: masterRunner,
+	}
+}
+
+func (s *UploadCertsStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	// Masters of a new kube get certificates uploaded by the bootstrap master
+	if !config.IsMaster || !config.UploadCerts {
+		return nil
+	}
+
+	err := runOnOtherMaster(ctx, out, config, s.script, s.getRunner, struct {
+		CertificateKey string
+	}{
+		CertificateKey: config.Kube.Auth.CertificateKey,
+	})
+	if err != nil {
+		return errors.Wrap(err, UploadCertsStepName)
+	}
+
+	return nil
+}
+
+func (s *UploadCertsStep) Name() string {
+	return UploadCertsStepName
+}
+
+func (s *UploadCertsStep) Description() string {
+	return "upload control plane certificates for the new master"
+}
+
+func (s *UploadCertsStep) Depends() []string {
+	return nil
+}
+
+func (s *UploadCertsStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+// RemoveEtcdMemberStep removes etcd member of the master being deleted,
+// otherwise etcd keeps waiting for it and loses quorum sooner.
+type RemoveEtcdMemberStep struct {
+	script    *template.Template
+	getRunner getRunnerFn
+}
+
+func NewRemoveEtcdMemberStep(script *template.Template) *RemoveEtcdMemberStep {
+	return &RemoveEtcdMemberStep{
+		script:    script,
+		getRunner: masterRunner,
+	}
+}
+
+func (s *RemoveEtcdMemberStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if !config.IsMaster {
+		return nil
+	}
+
+	err := runOnOtherMaster(ctx, out, config, s.script, s.getRunner, struct {
+		PrivateIP string
+	}{
+		PrivateIP: config.Node.PrivateIp,
+	})
+	if err != nil {
+		return errors.Wrap(err, RemoveEtcdMemberStepName)
+	}
+
+	return nil
+}
+
+func (s *RemoveEtcdMemberStep) Name() string {
+	return RemoveEtcdMemberStepName
+}
+
+func (s *RemoveEtcdMemberStep) Description() string {
+	return "remove etcd member of the master"
+}
+
+func (s *RemoveEtcdMemberStep) Depends() []string {
+	return nil
+}
+
+func (s *RemoveEtcdMemberStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func runOnOtherMaster(ctx context.Context, out io.Writer, config *steps.Config,
+	script *template.Template, getRunner getRunnerFn, data interface{}) error {
+	master := config.GetOtherMaster()
+	if master == nil {
+		return errors.Wrapf(sgerrors.ErrNotFound, "active master other than %s", config.Node.Name)
+	}
+
+	r, err := getRunner(*master, config)
+	if err != nil {
+		return errors.Wrapf(err, "get runner for %s", master.Name)
+	}
+
+	return steps.RunTemplate(ctx, steps.Template(config, script), r, out, data)
+}
+
+func masterRunner(master model.Machine, config *steps.Config) (runner.Runner, error) {
+	if config.Provider == clouds.Simulator {
+		return simulator.NewRunner(config.SimulatorConfig, master.ID)
+	}
+
+	if config.Provider == clouds.AWS {
+		d, err := distro.Get(config.Kube.OperatingSystem, config.Kube.OperatingSystemVersion)
+		if err != nil {
+			return nil, err
+		}
+		config.Kube.SSHConfig.User = steps.CloudUser(config.Provider, d)
+	}
+
+	cfg := steps.SSHConfigFor(config.Kube.SSHConfig, master)
+	cfg.Timeout = 10
+
+	return ssh.RunnerFor(cfg, config.SSHPool)
+}
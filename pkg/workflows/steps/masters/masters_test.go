@@ -0,0 +1,129 @@
+package masters
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type fakeRunner struct {
+	testutils.FakeTransfer
+
+	errMsg string
+}
+
+func (f *fakeRunner) Run(command *runner.Command) error {
+	if len(f.errMsg) > 0 {
+		return errors.New(f.errMsg)
+	}
+
+	_, err := io.Copy(command.Out, strings.NewReader(command.Script))
+	return err
+}
+
+func testConfig(t *testing.T) *steps.Config {
+	cfg, err := steps.NewConfig("", "", profile.Profile{})
+	if err != nil {
+		t.Fatalf("new config %v", err)
+	}
+
+	cfg.IsMaster = true
+	cfg.Node = model.Machine{Name: "master-1", PrivateIp: "10.0.0.1", State: model.MachineStateDeleting}
+	cfg.Kube.Auth.CertificateKey = "abcdef"
+	cfg.Masters = steps.NewMap(map[string]*model.Machine{
+		"master-1": {Name: "master-1", PrivateIp: "10.0.0.1", State: model.MachineStateDeleting},
+		"master-2": {Name: "master-2", PrivateIp: "10.0.0.2", State: model.MachineStateActive},
+	})
+
+	return cfg
+}
+
+func TestSteps(t *testing.T) {
+	if err := templatemanager.Init("../../../../templates"); err != nil {
+		t.Fatal(err)
+	}
+
+	uploadTpl, _ := templatemanager.GetTemplate(UploadCertsStepName)
+	removeTpl, _ := templatemanager.GetTemplate(RemoveEtcdMemberStepName)
+	if uploadTpl == nil || removeTpl == nil {
+		t.Fatal("template not found")
+	}
+
+	var runOn string
+	getRunner := func(master model.Machine, config *steps.Config) (runner.Runner, error) {
+		runOn = master.Name
+		return &fakeRunner{}, nil
+	}
+
+	testCases := []struct {
+		description string
+		step        steps.Step
+		uploadCerts bool
+		expected    string
+	}{
+		{
+			description: "upload certs",
+			step:        &UploadCertsStep{script: uploadTpl, getRunner: getRunner},
+			uploadCerts: true,
+			expected:    "--certificate-key abcdef",
+		},
+		{
+			description: "masters of new kube",
+			step:        &UploadCertsStep{script: uploadTpl, getRunner: getRunner},
+		},
+		{
+			description: "remove etcd member",
+			step:        &RemoveEtcdMemberStep{script: removeTpl, getRunner: getRunner},
+			expected:    "https://10.0.0.1:2380",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		runOn = ""
+		cfg := testConfig(t)
+		cfg.UploadCerts = testCase.uploadCerts
+		output := new(bytes.Buffer)
+
+		if err := testCase.step.Run(context.Background(), output, cfg); err != nil {
+			t.Errorf("unexpected error %v", err)
+			continue
+		}
+
+		if testCase.expected == "" {
+			if runOn != "" {
+				t.Errorf("step must be skipped")
+			}
+			continue
+		}
+
+		if runOn != "master-2" || !strings.Contains(output.String(), testCase.expected) {
+			t.Errorf("expected %s to run on master-2 actual %s %s", testCase.expected, runOn, output.String())
+		}
+	}
+}
+
+func TestNoOtherMaster(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Masters = steps.NewMap(map[string]*model.Machine{
+		"master-1": {Name: "master-1", State: model.MachineStateDeleting},
+	})
+
+	step := &RemoveEtcdMemberStep{}
+	if err := step.Run(context.Background(), new(bytes.Buffer), cfg); !sgerrors.IsNotFound(err) {
+		t.Errorf("expected not found error actual %v", err)
+	}
+}
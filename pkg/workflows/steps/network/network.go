@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
@@ -19,6 +20,7 @@ type Config struct {
 	IsBootstrap     bool
 	CIDR            string
 	NetworkProvider string
+	Mirrors         profile.Mirrors
 }
 
 type Step struct {
@@ -46,7 +48,7 @@ func (t *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 		return nil
 	}
 
-	err := steps.RunTemplate(context.Background(), t.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(context.Background(), steps.Template(config, t.script), config.Runner, out, toStepCfg(config))
 	if err != nil {
 		return errors.Wrap(err, "configure network step")
 	}
@@ -75,5 +77,6 @@ func toStepCfg(c *steps.Config) Config {
 		IsBootstrap:     c.IsBootstrap,
 		CIDR:            c.Kube.Networking.CIDR,
 		NetworkProvider: c.Kube.Networking.Provider,
+		Mirrors:         c.Kube.Mirrors,
 	}
 }
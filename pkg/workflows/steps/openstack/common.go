@@ -0,0 +1,153 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/clouds/openstacksdk"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	CreateNetworkStepName       = "createNetworkOpenStack"
+	CreateSecurityGroupStepName = "createSecurityGroupOpenStack"
+	ImportKeyPairStepName       = "importKeyPairOpenStack"
+	CreateLoadBalancerStepName  = "createLoadBalancerOpenStack"
+	CreateMachineStepName       = "createMachineOpenStack"
+	RegisterMemberStepName      = "registerMemberOpenStack"
+
+	DeleteMachineStepName         = "deleteMachineOpenStack"
+	DeleteClusterMachinesStepName = "deleteClusterMachinesOpenStack"
+	DeleteInfraStepName           = "deleteInfraOpenStack"
+
+	// Subnet of the kube unless profile tells otherwise, it must
+	// not overlap with pod and service networks
+	defaultSubnetCIDR = "172.20.0.0/16"
+)
+
+func Init() {
+	steps.RegisterStep(CreateNetworkStepName, NewCreateNetworkStep())
+	steps.RegisterStep(CreateSecurityGroupStepName, NewCreateSecurityGroupStep())
+	steps.RegisterStep(ImportKeyPairStepName, NewImportKeyPairStep())
+	steps.RegisterStep(CreateLoadBalancerStepName, NewCreateLoadBalancerStep(time.Minute*10, time.Second*10))
+	steps.RegisterStep(CreateMachineStepName, NewCreateMachineStep(time.Minute*10, time.Second*5))
+	steps.RegisterStep(RegisterMemberStepName, NewRegisterMemberStep())
+
+	steps.RegisterStep(DeleteMachineStepName, NewDeleteMachineStep())
+	steps.RegisterStep(DeleteClusterMachinesStepName, NewDeleteClusterMachinesStep(time.Minute*5, time.Second*5))
+	steps.RegisterStep(DeleteInfraStepName, NewDeleteInfraStep(time.Minute*5, time.Second*5))
+}
+
+// services are clients of openstack services in the region of the kube.
+type services struct {
+	compute      *gophercloud.ServiceClient
+	network      *gophercloud.ServiceClient
+	image        *gophercloud.ServiceClient
+	loadBalancer *gophercloud.ServiceClient
+}
+
+// newServices authenticates with credentials of cloud account,
+// services are looked up in the region of the kube.
+func newServices(ctx context.Context, cfg steps.OSConfig) (*services, error) {
+	client, err := openstacksdk.New(ctx, openstacksdk.Credentials{
+		AuthURL:     cfg.AuthURL,
+		UserName:    cfg.UserName,
+		Password:    cfg.Password,
+		DomainName:  cfg.DomainName,
+		ProjectName: cfg.ProjectName,
+		Region:      cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &services{}
+	if svc.compute, err = client.Compute(); err != nil {
+		return nil, err
+	}
+	if svc.network, err = client.Network(); err != nil {
+		return nil, err
+	}
+	if svc.image, err = client.Image(); err != nil {
+		return nil, err
+	}
+	if svc.loadBalancer, err = client.LoadBalancer(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// resourceName is the name of network, router, key pair, etc. of the kube.
+func resourceName(config *steps.Config) string {
+	return fmt.Sprintf("%s-%s", config.Kube.Name, config.Kube.ID)
+}
+
+// clusterMetadata marks servers of the kube.
+func clusterMetadata(config *steps.Config) map[string]string {
+	return map[string]string{
+		clouds.TagClusterID: config.Kube.ID,
+	}
+}
+
+// listServers returns servers of the project that have all metadata items.
+func listServers(compute *gophercloud.ServiceClient, metadata map[string]string) ([]servers.Server, error) {
+	pages, err := servers.List(compute, servers.ListOpts{}).AllPages()
+	if err != nil {
+		return nil, errors.Wrap(err, "list servers")
+	}
+
+	all, err := servers.ExtractServers(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "list servers")
+	}
+
+	list := make([]servers.Server, 0, len(all))
+	for _, s := range all {
+		if hasMetadata(s, metadata) {
+			list = append(list, s)
+		}
+	}
+
+	return list, nil
+}
+
+func hasMetadata(s servers.Server, metadata map[string]string) bool {
+	for k, v := range metadata {
+		if s.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// listPorts returns ports of the device e.g. server or load balancer.
+func listPorts(network *gophercloud.ServiceClient, deviceID string) ([]ports.Port, error) {
+	pages, err := ports.List(network, ports.ListOpts{DeviceID: deviceID}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "list ports of %s", deviceID)
+	}
+
+	return ports.ExtractPorts(pages)
+}
+
+// createFloatingIP allocates address in external network and associates it with the port.
+func createFloatingIP(network *gophercloud.ServiceClient, externalNetworkID, portID string) (*floatingips.FloatingIP, error) {
+	ip, err := floatingips.Create(network, floatingips.CreateOpts{
+		FloatingNetworkID: externalNetworkID,
+		PortID:            portID,
+	}).Extract()
+	if err != nil {
+		return nil, errors.Wrapf(err, "create floating ip for port %s", portID)
+	}
+
+	return ip, nil
+}
@@ -0,0 +1,135 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// getFakeServices points clients of all services to the mux of testhelper,
+// paths of services differ by versions of their resource base.
+func getFakeServices(context.Context, steps.OSConfig) (*services, error) {
+	versioned := func(version string) *gophercloud.ServiceClient {
+		c := client.ServiceClient()
+		c.ResourceBase = c.Endpoint + version
+		return c
+	}
+
+	return &services{
+		compute:      client.ServiceClient(),
+		network:      versioned("v2.0/"),
+		image:        versioned("v2/"),
+		loadBalancer: versioned("v2.0/"),
+	}, nil
+}
+
+func newConfig(t *testing.T) *steps.Config {
+	cfg, err := steps.NewConfig("test", "", profile.Profile{
+		Provider:        clouds.OpenStack,
+		Region:          "RegionOne",
+		OperatingSystem: "ubuntu",
+		UbuntuVersion:   "bionic",
+		MasterProfiles:  []profile.NodeProfile{{"size": "m1.medium"}},
+		NodesProfiles:   []profile.NodeProfile{{"size": "m1.small"}},
+	})
+	if err != nil {
+		t.Fatalf("new config: %v", err)
+	}
+
+	cfg.TaskID = "1234abcd"
+	cfg.Kube.ID = "kube1234"
+	cfg.Kube.APIServerPort = 443
+	cfg.Kube.SSHConfig.BootstrapPublicKey = "ssh-rsa AAAA"
+
+	return cfg
+}
+
+// handleCreate checks request to create resource and responds with it.
+func handleCreate(t *testing.T, path, request, response string) {
+	th.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodPost)
+		th.TestJSONRequest(t, r, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, response)
+	})
+}
+
+// handleDelete responds to deletion of the resource, resource that has
+// been deleted already is not found.
+func handleDelete(t *testing.T, path string) *int32 {
+	deleted := new(int32)
+
+	th.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodDelete)
+
+		if atomic.AddInt32(deleted, 1) > 1 {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"NeutronError": {"type": "NotFound"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return deleted
+}
+
+func TestNewServices(t *testing.T) {
+	if _, err := newServices(context.Background(), steps.OSConfig{}); !sgerrors.IsInvalidCredentials(err) {
+		t.Errorf("wrong error expected %v actual %v", sgerrors.ErrInvalidCredentials, err)
+	}
+
+	for _, s := range []struct {
+		name        string
+		getServices func(context.Context, steps.OSConfig) (*services, error)
+	}{
+		{CreateNetworkStepName, NewCreateNetworkStep().getServices},
+		{CreateSecurityGroupStepName, NewCreateSecurityGroupStep().getServices},
+		{ImportKeyPairStepName, NewImportKeyPairStep().getServices},
+		{CreateLoadBalancerStepName, NewCreateLoadBalancerStep(time.Second, time.Second).getServices},
+		{CreateMachineStepName, NewCreateMachineStep(time.Second, time.Second).getServices},
+		{RegisterMemberStepName, NewRegisterMemberStep().getServices},
+		{DeleteMachineStepName, NewDeleteMachineStep().getServices},
+		{DeleteClusterMachinesStepName, NewDeleteClusterMachinesStep(time.Second, time.Second).getServices},
+		{DeleteInfraStepName, NewDeleteInfraStep(time.Second, time.Second).getServices},
+	} {
+		if s.getServices == nil {
+			t.Errorf("%s: services getter must not be nil", s.name)
+		}
+	}
+}
+
+func TestListServers(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	th.Mux.HandleFunc("/servers/detail", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"servers": [
+			{"id": "s1", "name": "test-master-1234", "metadata": {"supergiant.io/cluster-id": "kube1234"}},
+			{"id": "s2", "name": "another-node-5678", "metadata": {"supergiant.io/cluster-id": "kube5678"}},
+			{"id": "s3", "name": "manual"}
+		]}`)
+	})
+
+	svc, _ := getFakeServices(context.Background(), steps.OSConfig{})
+	list, err := listServers(svc.compute, map[string]string{clouds.TagClusterID: "kube1234"})
+	if err != nil || len(list) != 1 || list[0].ID != "s1" {
+		t.Errorf("wrong servers %v error %v", list, err)
+	}
+}
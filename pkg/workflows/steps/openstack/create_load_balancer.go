@@ -0,0 +1,135 @@
+package openstack
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/pools"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// Provisioning statuses of octavia objects
+const (
+	loadBalancerActive = "ACTIVE"
+	loadBalancerError  = "ERROR"
+)
+
+// CreateLoadBalancerStep creates octavia load balancer in front of kube api
+// of masters, it is reachable from outside by floating ip.
+type CreateLoadBalancerStep struct {
+	Timeout     time.Duration
+	CheckPeriod time.Duration
+
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewCreateLoadBalancerStep(timeout, checkPeriod time.Duration) *CreateLoadBalancerStep {
+	return &CreateLoadBalancerStep{
+		Timeout:     timeout,
+		CheckPeriod: checkPeriod,
+		getServices: newServices,
+	}
+}
+
+func (s *CreateLoadBalancerStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, CreateLoadBalancerStepName)
+	}
+
+	name := resourceName(config)
+
+	lb, err := loadbalancers.Create(svc.loadBalancer, loadbalancers.CreateOpts{
+		Name:        name,
+		VipSubnetID: config.OSConfig.SubnetID,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: create load balancer %s", CreateLoadBalancerStepName, name)
+	}
+	config.OSConfig.LoadBalancerID = lb.ID
+
+	logrus.Infof("Wait until load balancer %s become active", lb.ID)
+	if err := s.waitActive(ctx, svc.loadBalancer, lb.ID); err != nil {
+		return errors.Wrap(err, CreateLoadBalancerStepName)
+	}
+
+	listener, err := listeners.Create(svc.loadBalancer, listeners.CreateOpts{
+		Name:           name,
+		Protocol:       listeners.ProtocolTCP,
+		ProtocolPort:   int(config.Kube.APIServerPort),
+		LoadbalancerID: lb.ID,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: create listener %s", CreateLoadBalancerStepName, name)
+	}
+
+	// load balancer is immutable until the change is applied
+	if err := s.waitActive(ctx, svc.loadBalancer, lb.ID); err != nil {
+		return errors.Wrap(err, CreateLoadBalancerStepName)
+	}
+
+	pool, err := pools.Create(svc.loadBalancer, pools.CreateOpts{
+		Name:       name,
+		Protocol:   pools.ProtocolTCP,
+		LBMethod:   pools.LBMethodRoundRobin,
+		ListenerID: listener.ID,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: create pool %s", CreateLoadBalancerStepName, name)
+	}
+	config.OSConfig.PoolID = pool.ID
+
+	if err := s.waitActive(ctx, svc.loadBalancer, lb.ID); err != nil {
+		return errors.Wrap(err, CreateLoadBalancerStepName)
+	}
+
+	ip, err := createFloatingIP(svc.network, config.OSConfig.ExternalNetworkID, lb.VipPortID)
+	if err != nil {
+		return errors.Wrap(err, CreateLoadBalancerStepName)
+	}
+	config.OSConfig.LBFloatingIPID = ip.ID
+
+	config.Kube.ExternalDNSName = ip.FloatingIP
+	config.Kube.InternalDNSName = lb.VipAddress
+
+	return nil
+}
+
+func (s *CreateLoadBalancerStep) waitActive(ctx context.Context, client *gophercloud.ServiceClient, id string) error {
+	return steps.WaitFor(ctx, s.Timeout, s.CheckPeriod, func() (bool, error) {
+		lb, err := loadbalancers.Get(client, id).Extract()
+		if err != nil {
+			return false, errors.Wrapf(err, "get load balancer %s", id)
+		}
+
+		logrus.Debugf("Load balancer %s status %s", id, lb.ProvisioningStatus)
+		if lb.ProvisioningStatus == loadBalancerError {
+			return false, errors.Errorf("load balancer %s is in error state", id)
+		}
+
+		return lb.ProvisioningStatus == loadBalancerActive, nil
+	})
+}
+
+func (s *CreateLoadBalancerStep) Name() string {
+	return CreateLoadBalancerStepName
+}
+
+func (s *CreateLoadBalancerStep) Description() string {
+	return "Create load balancer in OpenStack"
+}
+
+func (s *CreateLoadBalancerStep) Depends() []string {
+	return nil
+}
+
+func (s *CreateLoadBalancerStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
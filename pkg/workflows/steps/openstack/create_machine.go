@@ -0,0 +1,232 @@
+package openstack
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// CreateMachineStep creates server in the network of the kube, server gets
+// floating ip for supergiant to reach it over ssh.
+type CreateMachineStep struct {
+	Timeout     time.Duration
+	CheckPeriod time.Duration
+
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+// Statuses of nova server
+const (
+	serverActive = "ACTIVE"
+	serverError  = "ERROR"
+)
+
+func NewCreateMachineStep(timeout, checkPeriod time.Duration) *CreateMachineStep {
+	return &CreateMachineStep{
+		Timeout:     timeout,
+		CheckPeriod: checkPeriod,
+		getServices: newServices,
+	}
+}
+
+func (s *CreateMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, CreateMachineStepName)
+	}
+
+	flavor, err := findFlavor(svc.compute, config.OSConfig.Flavor)
+	if err != nil {
+		return errors.Wrap(err, CreateMachineStepName)
+	}
+
+	imageID, err := findImage(svc.image, config.OSConfig.Image)
+	if err != nil {
+		return errors.Wrap(err, CreateMachineStepName)
+	}
+
+	role := model.ToRole(config.IsMaster)
+	metadata := clusterMetadata(config)
+	metadata[clouds.TagKubernetesCluster] = config.Kube.Name
+	metadata["Role"] = string(role)
+
+	config.Node = model.Machine{
+		TaskID:   config.TaskID,
+		Role:     role,
+		Provider: clouds.OpenStack,
+		Size:     flavor.Name,
+		Region:   config.OSConfig.Region,
+		State:    model.MachineStateBuilding,
+		Name:     util.MakeNodeName(config.Kube.Name, config.TaskID, config.IsMaster),
+		Pool:     config.NodePool.Name,
+	}
+
+	// Update node state in cluster
+	config.NodeChan() <- config.Node
+
+	server, err := servers.Create(svc.compute, keypairs.CreateOptsExt{
+		CreateOptsBuilder: servers.CreateOpts{
+			Name:             config.Node.Name,
+			FlavorRef:        flavor.ID,
+			ImageRef:         imageID,
+			Networks:         []servers.Network{{UUID: config.OSConfig.NetworkID}},
+			SecurityGroups:   []string{config.OSConfig.SecurityGroupName},
+			AvailabilityZone: config.OSConfig.AvailabilityZone,
+			Metadata:         metadata,
+			UserData:         []byte(config.UserData),
+		},
+		KeyName: config.OSConfig.KeyPairName,
+	}).Extract()
+	if err != nil {
+		config.Node.State = model.MachineStateError
+		config.NodeChan() <- config.Node
+		return errors.Wrapf(err, "%s: create server %s", CreateMachineStepName, config.Node.Name)
+	}
+	config.Node.ID = server.ID
+
+	err = steps.WaitFor(ctx, s.Timeout, s.CheckPeriod, func() (bool, error) {
+		server, err = servers.Get(svc.compute, config.Node.ID).Extract()
+		if err != nil {
+			return false, errors.Wrapf(err, "get server %s", config.Node.ID)
+		}
+
+		if server.Status == serverError {
+			return false, errors.Errorf("server %s is in error state", server.ID)
+		}
+
+		return server.Status == serverActive, nil
+	})
+	if err != nil {
+		config.Node.State = model.MachineStateError
+		config.NodeChan() <- config.Node
+		return errors.Wrap(err, CreateMachineStepName)
+	}
+
+	ip, err := s.assignFloatingIP(svc.network, server.ID, config.OSConfig.ExternalNetworkID)
+	if err != nil {
+		config.Node.State = model.MachineStateError
+		config.NodeChan() <- config.Node
+		return errors.Wrap(err, CreateMachineStepName)
+	}
+
+	config.Node.CreatedAt = server.Created.Unix()
+	config.Node.PublicIp = ip
+	config.Node.PrivateIp = fixedAddress(server, config.OSConfig.NetworkName)
+	config.Node.State = model.MachineStateProvisioning
+
+	// Update node state in cluster
+	config.NodeChan() <- config.Node
+
+	if config.IsMaster {
+		config.AddMaster(&config.Node)
+	} else {
+		config.AddNode(&config.Node)
+	}
+
+	logrus.Infof("Node has been created %v", config.Node)
+
+	return nil
+}
+
+func (s *CreateMachineStep) assignFloatingIP(client *gophercloud.ServiceClient, serverID, networkID string) (string, error) {
+	ports, err := listPorts(client, serverID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ports) == 0 {
+		return "", errors.Errorf("server %s has no ports", serverID)
+	}
+
+	ip, err := createFloatingIP(client, networkID, ports[0].ID)
+	if err != nil {
+		return "", err
+	}
+
+	return ip.FloatingIP, nil
+}
+
+// findFlavor looks flavor up by either name or id.
+func findFlavor(client *gophercloud.ServiceClient, nameOrID string) (*flavors.Flavor, error) {
+	pages, err := flavors.ListDetail(client, nil).AllPages()
+	if err != nil {
+		return nil, errors.Wrap(err, "list flavors")
+	}
+
+	list, err := flavors.ExtractFlavors(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "list flavors")
+	}
+
+	for i := range list {
+		if list[i].Name == nameOrID || list[i].ID == nameOrID {
+			return &list[i], nil
+		}
+	}
+
+	return nil, errors.Wrapf(sgerrors.ErrNotFound, "flavor %s", nameOrID)
+}
+
+// findImage looks image up in glance by name.
+func findImage(client *gophercloud.ServiceClient, name string) (string, error) {
+	pages, err := images.List(client, images.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return "", errors.Wrapf(err, "find image %s", name)
+	}
+
+	list, err := images.ExtractImages(pages)
+	if err != nil {
+		return "", errors.Wrapf(err, "find image %s", name)
+	}
+
+	if len(list) == 0 {
+		return "", errors.Wrapf(sgerrors.ErrNotFound, "image %s", name)
+	}
+
+	return list[0].ID, nil
+}
+
+// fixedAddress returns ipv4 address of the server in the network.
+func fixedAddress(server *servers.Server, network string) string {
+	addresses, _ := server.Addresses[network].([]interface{})
+
+	for _, item := range addresses {
+		addr, _ := item.(map[string]interface{})
+		if addr["version"] == float64(4) && addr["OS-EXT-IPS:type"] != "floating" {
+			ip, _ := addr["addr"].(string)
+			return ip
+		}
+	}
+
+	return ""
+}
+
+func (s *CreateMachineStep) Name() string {
+	return CreateMachineStepName
+}
+
+func (s *CreateMachineStep) Description() string {
+	return "Create server in OpenStack"
+}
+
+func (s *CreateMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *CreateMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,194 @@
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/clouds/openstacksdk/fixtures"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func newMachineConfig(t *testing.T) *steps.Config {
+	cfg := newConfig(t)
+	cfg.OSConfig.ExternalNetworkID = "ext"
+	cfg.OSConfig.NetworkID = "net1"
+	cfg.OSConfig.NetworkName = "test-kube1234"
+	cfg.OSConfig.SubnetID = "sub1"
+	cfg.OSConfig.SecurityGroupName = "test-kube1234"
+	cfg.OSConfig.KeyPairName = "test-kube1234"
+	cfg.OSConfig.PoolID = "pool1"
+
+	return cfg
+}
+
+func handleImages(t *testing.T, name string) {
+	th.Mux.HandleFunc("/v2/images", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+		th.TestFormValues(t, r, map[string]string{"name": name})
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"images": [{"id": "img1", "name": %q}]}`, name)
+	})
+}
+
+func TestCreateMachine(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	cfg := newMachineConfig(t)
+	cfg.IsMaster = true
+	cfg.OSConfig.Flavor = "m1.medium"
+	cfg.UserData = "#!/bin/bash"
+
+	fixtures.HandleListFlavors(t, "")
+	handleImages(t, cfg.OSConfig.Image)
+
+	th.Mux.HandleFunc("/servers", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodPost)
+
+		req := struct {
+			Server struct {
+				Name           string              `json:"name"`
+				FlavorRef      string              `json:"flavorRef"`
+				ImageRef       string              `json:"imageRef"`
+				KeyName        string              `json:"key_name"`
+				UserData       string              `json:"user_data"`
+				Metadata       map[string]string   `json:"metadata"`
+				Networks       []map[string]string `json:"networks"`
+				SecurityGroups []map[string]string `json:"security_groups"`
+			} `json:"server"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode server: %v", err)
+		}
+
+		s := req.Server
+		if s.Name != "test-master-1234" || s.FlavorRef != "2" || s.ImageRef != "img1" || s.KeyName != "test-kube1234" {
+			t.Errorf("wrong server %v", s)
+		}
+		if len(s.Networks) != 1 || s.Networks[0]["uuid"] != "net1" ||
+			len(s.SecurityGroups) != 1 || s.SecurityGroups[0]["name"] != "test-kube1234" {
+			t.Errorf("wrong network or security group of server %v", s)
+		}
+		if s.Metadata[clouds.TagClusterID] != "kube1234" || s.Metadata["Role"] != string(model.RoleMaster) {
+			t.Errorf("server is not marked with cluster id and role %v", s.Metadata)
+		}
+		if s.UserData != "IyEvYmluL2Jhc2g=" {
+			t.Errorf("user data must be base64 encoded %s", s.UserData)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"server": {"id": "srv1"}}`)
+	})
+	th.Mux.HandleFunc("/servers/srv1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"server": {"id": "srv1", "status": "ACTIVE", "created": "2019-08-01T10:00:00Z",
+			"addresses": {"test-kube1234": [
+				{"addr": "fd00::10", "version": 6, "OS-EXT-IPS:type": "fixed"},
+				{"addr": "172.20.0.10", "version": 4, "OS-EXT-IPS:type": "fixed"}
+			]}}}`)
+	})
+	th.Mux.HandleFunc("/v2.0/ports", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+		th.TestFormValues(t, r, map[string]string{"device_id": "srv1"})
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ports": [{"id": "port1", "device_id": "srv1"}]}`)
+	})
+	handleCreate(t, "/v2.0/floatingips",
+		`{"floatingip": {"floating_network_id": "ext", "port_id": "port1"}}`,
+		`{"floatingip": {"id": "fip2", "floating_ip_address": "203.0.113.20", "port_id": "port1"}}`)
+	handleCreate(t, "/v2.0/lbaas/pools/pool1/members",
+		`{"member": {"address": "172.20.0.10", "protocol_port": 443, "subnet_id": "sub1"}}`,
+		`{"member": {"id": "member1", "address": "172.20.0.10"}}`)
+
+	s := NewCreateMachineStep(time.Second, time.Millisecond)
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if n := <-cfg.NodeChan(); n.State != model.MachineStateBuilding {
+		t.Errorf("wrong state of machine %s", n.State)
+	}
+
+	n := <-cfg.NodeChan()
+	if n.State != model.MachineStateProvisioning || n.ID != "srv1" || n.Size != "m1.medium" {
+		t.Errorf("wrong machine %v", n)
+	}
+
+	if n.PublicIp != "203.0.113.20" || n.PrivateIp != "172.20.0.10" {
+		t.Errorf("wrong addresses of machine %s %s", n.PublicIp, n.PrivateIp)
+	}
+
+	if n.CreatedAt != time.Date(2019, 8, 1, 10, 0, 0, 0, time.UTC).Unix() {
+		t.Errorf("wrong creation time %d", n.CreatedAt)
+	}
+
+	if len(cfg.GetMasters()) != 1 {
+		t.Errorf("machine has not been added to masters")
+	}
+
+	member := NewRegisterMemberStep()
+	member.getServices = getFakeServices
+
+	if err := member.Run(context.Background(), nil, cfg); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCreateMachineError(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	cfg := newMachineConfig(t)
+	cfg.OSConfig.Flavor = "m1.small"
+
+	fixtures.HandleListFlavors(t, "")
+	handleImages(t, cfg.OSConfig.Image)
+	th.Mux.HandleFunc("/servers", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	s := NewCreateMachineStep(time.Second, time.Millisecond)
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err == nil {
+		t.Fatalf("error must be returned")
+	}
+
+	<-cfg.NodeChan()
+	if n := <-cfg.NodeChan(); n.State != model.MachineStateError {
+		t.Errorf("wrong state of machine %s", n.State)
+	}
+
+	cfg.OSConfig.Flavor = "m1.huge"
+	if err := s.Run(context.Background(), nil, cfg); err == nil {
+		t.Errorf("unknown flavor must fail")
+	}
+}
+
+func TestRegisterMemberNode(t *testing.T) {
+	s := NewRegisterMemberStep()
+	s.getServices = func(context.Context, steps.OSConfig) (*services, error) {
+		t.Fatalf("nodes must not be added to the pool")
+		return nil, nil
+	}
+
+	cfg := newMachineConfig(t)
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package openstack
+
+import (
+	"context"
+	"io"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/external"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// CreateNetworkStep creates network with subnet of the kube and router
+// that connects it to external network floating ips are allocated from.
+type CreateNetworkStep struct {
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewCreateNetworkStep() *CreateNetworkStep {
+	return &CreateNetworkStep{
+		getServices: newServices,
+	}
+}
+
+func (s *CreateNetworkStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, CreateNetworkStepName)
+	}
+
+	if config.OSConfig.ExternalNetworkID == "" {
+		id, err := findExternalNetwork(svc.network)
+		if err != nil {
+			return errors.Wrap(err, CreateNetworkStepName)
+		}
+		config.OSConfig.ExternalNetworkID = id
+	}
+
+	name := resourceName(config)
+	adminStateUp := true
+
+	network, err := networks.Create(svc.network, networks.CreateOpts{
+		Name:         name,
+		AdminStateUp: &adminStateUp,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: create network %s", CreateNetworkStepName, name)
+	}
+	config.OSConfig.NetworkID = network.ID
+	config.OSConfig.NetworkName = network.Name
+
+	if config.OSConfig.SubnetCIDR == "" {
+		config.OSConfig.SubnetCIDR = defaultSubnetCIDR
+	}
+
+	subnet, err := subnets.Create(svc.network, subnets.CreateOpts{
+		NetworkID: network.ID,
+		Name:      name,
+		CIDR:      config.OSConfig.SubnetCIDR,
+		IPVersion: gophercloud.IPv4,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: create subnet %s", CreateNetworkStepName, name)
+	}
+	config.OSConfig.SubnetID = subnet.ID
+
+	router, err := routers.Create(svc.network, routers.CreateOpts{
+		Name: name,
+		GatewayInfo: &routers.GatewayInfo{
+			NetworkID: config.OSConfig.ExternalNetworkID,
+		},
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: create router %s", CreateNetworkStepName, name)
+	}
+	config.OSConfig.RouterID = router.ID
+
+	_, err = routers.AddInterface(svc.network, router.ID, routers.AddInterfaceOpts{
+		SubnetID: subnet.ID,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: add subnet %s to router %s", CreateNetworkStepName, subnet.ID, router.ID)
+	}
+
+	logrus.Infof("Network %s with subnet %s has been created", network.ID, config.OSConfig.SubnetCIDR)
+
+	return nil
+}
+
+// findExternalNetwork returns id of network that floating ips are allocated from.
+func findExternalNetwork(client *gophercloud.ServiceClient) (string, error) {
+	isExternal := true
+
+	pages, err := networks.List(client, external.ListOptsExt{
+		ListOptsBuilder: networks.ListOpts{},
+		External:        &isExternal,
+	}).AllPages()
+	if err != nil {
+		return "", errors.Wrap(err, "find external network")
+	}
+
+	list, err := networks.ExtractNetworks(pages)
+	if err != nil {
+		return "", errors.Wrap(err, "find external network")
+	}
+
+	if len(list) == 0 {
+		return "", errors.Wrap(sgerrors.ErrNotFound, "external network")
+	}
+
+	return list[0].ID, nil
+}
+
+func (s *CreateNetworkStep) Name() string {
+	return CreateNetworkStepName
+}
+
+func (s *CreateNetworkStep) Description() string {
+	return "Create network, subnet and router in OpenStack"
+}
+
+func (s *CreateNetworkStep) Depends() []string {
+	return nil
+}
+
+func (s *CreateNetworkStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
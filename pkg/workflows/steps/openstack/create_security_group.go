@@ -0,0 +1,91 @@
+package openstack
+
+import (
+	"context"
+	"io"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// CreateSecurityGroupStep creates security group that machines of the kube share.
+type CreateSecurityGroupStep struct {
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewCreateSecurityGroupStep() *CreateSecurityGroupStep {
+	return &CreateSecurityGroupStep{
+		getServices: newServices,
+	}
+}
+
+func (s *CreateSecurityGroupStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, CreateSecurityGroupStepName)
+	}
+
+	name := resourceName(config)
+
+	group, err := groups.Create(svc.network, groups.CreateOpts{
+		Name:        name,
+		Description: "machines of kube " + config.Kube.ID,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: create security group %s", CreateSecurityGroupStepName, name)
+	}
+	config.OSConfig.SecurityGroupID = group.ID
+	config.OSConfig.SecurityGroupName = group.Name
+
+	apiPort := int(config.Kube.APIServerPort)
+
+	for _, rule := range []rules.CreateOpts{
+		{
+			// machines talk to each other on any port
+			RemoteGroupID: group.ID,
+		},
+		{
+			Protocol:       rules.ProtocolTCP,
+			PortRangeMin:   22,
+			PortRangeMax:   22,
+			RemoteIPPrefix: "0.0.0.0/0",
+		},
+		{
+			// load balancer proxies connections from its own address,
+			// so api can not be limited to exposed addresses
+			Protocol:       rules.ProtocolTCP,
+			PortRangeMin:   apiPort,
+			PortRangeMax:   apiPort,
+			RemoteIPPrefix: "0.0.0.0/0",
+		},
+	} {
+		rule.SecGroupID = group.ID
+		rule.Direction = rules.DirIngress
+		rule.EtherType = rules.EtherType4
+
+		if _, err := rules.Create(svc.network, rule).Extract(); err != nil {
+			return errors.Wrapf(err, "%s: create rule of security group %s", CreateSecurityGroupStepName, group.ID)
+		}
+	}
+
+	return nil
+}
+
+func (s *CreateSecurityGroupStep) Name() string {
+	return CreateSecurityGroupStepName
+}
+
+func (s *CreateSecurityGroupStep) Description() string {
+	return "Create security group in OpenStack"
+}
+
+func (s *CreateSecurityGroupStep) Depends() []string {
+	return nil
+}
+
+func (s *CreateSecurityGroupStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,205 @@
+package openstack
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds/openstacksdk"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// DeleteClusterMachinesStep deletes all servers of the kube and waits
+// until they are gone, ports of servers keep the network in use.
+type DeleteClusterMachinesStep struct {
+	Timeout     time.Duration
+	CheckPeriod time.Duration
+
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewDeleteClusterMachinesStep(timeout, checkPeriod time.Duration) *DeleteClusterMachinesStep {
+	return &DeleteClusterMachinesStep{
+		Timeout:     timeout,
+		CheckPeriod: checkPeriod,
+		getServices: newServices,
+	}
+}
+
+func (s *DeleteClusterMachinesStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, DeleteClusterMachinesStepName)
+	}
+
+	list, err := listServers(svc.compute, clusterMetadata(config))
+	if err != nil {
+		return errors.Wrap(err, DeleteClusterMachinesStepName)
+	}
+
+	for _, server := range list {
+		logrus.Infof("Delete server %s", server.Name)
+		if err := deleteServer(svc, server.ID); err != nil {
+			return errors.Wrap(err, DeleteClusterMachinesStepName)
+		}
+	}
+
+	err = steps.WaitFor(ctx, s.Timeout, s.CheckPeriod, func() (bool, error) {
+		list, err := listServers(svc.compute, clusterMetadata(config))
+		return len(list) == 0, err
+	})
+	if err != nil {
+		return errors.Wrap(err, DeleteClusterMachinesStepName)
+	}
+
+	return nil
+}
+
+func (s *DeleteClusterMachinesStep) Name() string {
+	return DeleteClusterMachinesStepName
+}
+
+func (s *DeleteClusterMachinesStep) Description() string {
+	return "Delete servers of the kube in OpenStack"
+}
+
+func (s *DeleteClusterMachinesStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteClusterMachinesStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+// DeleteInfraStep deletes what infra steps have created in reverse order,
+// resources that are gone already are skipped.
+type DeleteInfraStep struct {
+	Timeout     time.Duration
+	CheckPeriod time.Duration
+
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewDeleteInfraStep(timeout, checkPeriod time.Duration) *DeleteInfraStep {
+	return &DeleteInfraStep{
+		Timeout:     timeout,
+		CheckPeriod: checkPeriod,
+		getServices: newServices,
+	}
+}
+
+func (s *DeleteInfraStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, DeleteInfraStepName)
+	}
+
+	cfg := config.OSConfig
+
+	if err := s.deleteLoadBalancer(ctx, svc, cfg); err != nil {
+		return errors.Wrap(err, DeleteInfraStepName)
+	}
+
+	if cfg.KeyPairName != "" {
+		err := keypairs.Delete(svc.compute, cfg.KeyPairName).ExtractErr()
+		if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete key pair %s", DeleteInfraStepName, cfg.KeyPairName)
+		}
+	}
+
+	if cfg.RouterID != "" {
+		if cfg.SubnetID != "" {
+			_, err := routers.RemoveInterface(svc.network, cfg.RouterID, routers.RemoveInterfaceOpts{
+				SubnetID: cfg.SubnetID,
+			}).Extract()
+			if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+				return errors.Wrapf(err, "%s: remove subnet %s from router %s",
+					DeleteInfraStepName, cfg.SubnetID, cfg.RouterID)
+			}
+		}
+
+		err := routers.Delete(svc.network, cfg.RouterID).ExtractErr()
+		if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete router %s", DeleteInfraStepName, cfg.RouterID)
+		}
+	}
+
+	if cfg.SubnetID != "" {
+		err := subnets.Delete(svc.network, cfg.SubnetID).ExtractErr()
+		if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete subnet %s", DeleteInfraStepName, cfg.SubnetID)
+		}
+	}
+
+	if cfg.NetworkID != "" {
+		err := networks.Delete(svc.network, cfg.NetworkID).ExtractErr()
+		if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete network %s", DeleteInfraStepName, cfg.NetworkID)
+		}
+	}
+
+	if cfg.SecurityGroupID != "" {
+		err := groups.Delete(svc.network, cfg.SecurityGroupID).ExtractErr()
+		if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete security group %s", DeleteInfraStepName, cfg.SecurityGroupID)
+		}
+	}
+
+	return nil
+}
+
+func (s *DeleteInfraStep) deleteLoadBalancer(ctx context.Context, svc *services, cfg steps.OSConfig) error {
+	if cfg.LBFloatingIPID != "" {
+		err := floatingips.Delete(svc.network, cfg.LBFloatingIPID).ExtractErr()
+		if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "delete floating ip %s", cfg.LBFloatingIPID)
+		}
+	}
+
+	if cfg.LoadBalancerID == "" {
+		return nil
+	}
+
+	// listeners, pools and members are deleted along with load balancer
+	err := loadbalancers.Delete(svc.loadBalancer, cfg.LoadBalancerID, loadbalancers.DeleteOpts{
+		Cascade: true,
+	}).ExtractErr()
+	if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+		return errors.Wrapf(err, "delete load balancer %s", cfg.LoadBalancerID)
+	}
+
+	// port of load balancer keeps the subnet in use until it is deleted
+	return steps.WaitFor(ctx, s.Timeout, s.CheckPeriod, func() (bool, error) {
+		_, err := loadbalancers.Get(svc.loadBalancer, cfg.LoadBalancerID).Extract()
+		if openstacksdk.IsNotFound(err) {
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "get load balancer %s", cfg.LoadBalancerID)
+	})
+}
+
+func (s *DeleteInfraStep) Name() string {
+	return DeleteInfraStepName
+}
+
+func (s *DeleteInfraStep) Description() string {
+	return "Delete load balancer, network and security group of the kube in OpenStack"
+}
+
+func (s *DeleteInfraStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteInfraStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
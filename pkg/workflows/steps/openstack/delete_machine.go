@@ -0,0 +1,138 @@
+package openstack
+
+import (
+	"context"
+	"io"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/pools"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds/openstacksdk"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// DeleteMachineStep deletes server along with its floating ip, masters
+// are removed from the pool of api load balancer.
+type DeleteMachineStep struct {
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewDeleteMachineStep() *DeleteMachineStep {
+	return &DeleteMachineStep{
+		getServices: newServices,
+	}
+}
+
+func (s *DeleteMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, DeleteMachineStepName)
+	}
+
+	serverID := config.Node.ID
+	if serverID == "" {
+		// machine has failed before server id is known
+		list, err := listServers(svc.compute, clusterMetadata(config))
+		if err != nil {
+			return errors.Wrap(err, DeleteMachineStepName)
+		}
+
+		for _, server := range list {
+			if server.Name == config.Node.Name {
+				serverID = server.ID
+			}
+		}
+	}
+
+	if serverID == "" {
+		logrus.Infof("Server %s does not exist", config.Node.Name)
+		return nil
+	}
+
+	if config.Node.Role == model.RoleMaster && config.OSConfig.PoolID != "" {
+		if err := removeMember(svc.loadBalancer, config.OSConfig.PoolID, config.Node.PrivateIp); err != nil {
+			return errors.Wrap(err, DeleteMachineStepName)
+		}
+	}
+
+	if err := deleteServer(svc, serverID); err != nil {
+		return errors.Wrap(err, DeleteMachineStepName)
+	}
+
+	return nil
+}
+
+func (s *DeleteMachineStep) Name() string {
+	return DeleteMachineStepName
+}
+
+func (s *DeleteMachineStep) Description() string {
+	return "Delete server in OpenStack"
+}
+
+func (s *DeleteMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+func removeMember(client *gophercloud.ServiceClient, poolID, address string) error {
+	pages, err := pools.ListMembers(client, poolID, pools.ListMembersOpts{Address: address}).AllPages()
+	if err != nil {
+		return errors.Wrapf(steps.IgnoreNotFound(err, openstacksdk.IsNotFound), "list members of pool %s", poolID)
+	}
+
+	members, err := pools.ExtractMembers(pages)
+	if err != nil {
+		return errors.Wrapf(err, "list members of pool %s", poolID)
+	}
+
+	for _, member := range members {
+		if member.Address != address {
+			continue
+		}
+		err := pools.DeleteMember(client, poolID, member.ID).ExtractErr()
+		if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "delete member %s of pool %s", member.ID, poolID)
+		}
+	}
+
+	return nil
+}
+
+// deleteServer releases floating ips of the server, they outlive it otherwise.
+func deleteServer(svc *services, id string) error {
+	ports, err := listPorts(svc.network, id)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range ports {
+		pages, err := floatingips.List(svc.network, floatingips.ListOpts{PortID: port.ID}).AllPages()
+		if err != nil {
+			return errors.Wrapf(err, "list floating ips of port %s", port.ID)
+		}
+
+		ips, err := floatingips.ExtractFloatingIPs(pages)
+		if err != nil {
+			return errors.Wrapf(err, "list floating ips of port %s", port.ID)
+		}
+
+		for _, ip := range ips {
+			err := floatingips.Delete(svc.network, ip.ID).ExtractErr()
+			if steps.IgnoreNotFound(err, openstacksdk.IsNotFound) != nil {
+				return errors.Wrapf(err, "delete floating ip %s", ip.ID)
+			}
+		}
+	}
+
+	err = servers.Delete(svc.compute, id).ExtractErr()
+	return errors.Wrapf(steps.IgnoreNotFound(err, openstacksdk.IsNotFound), "delete server %s", id)
+}
@@ -0,0 +1,220 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+
+	"github.com/supergiant/control/pkg/model"
+)
+
+// handleList responds to listing of the resources with the body.
+func handleList(t *testing.T, path string, query map[string]string, body string) {
+	th.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+		if query != nil {
+			th.TestFormValues(t, r, query)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	})
+}
+
+func TestDeleteMachine(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	handleList(t, "/v2.0/lbaas/pools/pool1/members", nil, `{"members": [
+		{"id": "member1", "address": "172.20.0.10"},
+		{"id": "member2", "address": "172.20.0.11"}
+	]}`)
+	handleList(t, "/v2.0/ports", map[string]string{"device_id": "srv1"},
+		`{"ports": [{"id": "port1", "device_id": "srv1"}]}`)
+	handleList(t, "/v2.0/floatingips", map[string]string{"port_id": "port1"},
+		`{"floatingips": [{"id": "fip2", "port_id": "port1"}]}`)
+
+	member := handleDelete(t, "/v2.0/lbaas/pools/pool1/members/member1")
+	ip := handleDelete(t, "/v2.0/floatingips/fip2")
+	server := handleDelete(t, "/servers/srv1")
+
+	cfg := newMachineConfig(t)
+	cfg.Node = model.Machine{
+		ID:        "srv1",
+		Name:      "test-master-1234",
+		Role:      model.RoleMaster,
+		PrivateIp: "172.20.0.10",
+	}
+
+	s := NewDeleteMachineStep()
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// deleted server is skipped
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	for name, deleted := range map[string]*int32{"member": member, "floating ip": ip, "server": server} {
+		if atomic.LoadInt32(deleted) != 2 {
+			t.Errorf("%s of the machine has not been deleted", name)
+		}
+	}
+}
+
+func TestDeleteMachineByName(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	handleList(t, "/servers/detail", nil, `{"servers": [
+		{"id": "srv1", "name": "test-master-1234", "metadata": {"supergiant.io/cluster-id": "kube1234"}},
+		{"id": "srv2", "name": "test-node-1234", "metadata": {"supergiant.io/cluster-id": "kube1234"}}
+	]}`)
+	handleList(t, "/v2.0/ports", map[string]string{"device_id": "srv2"}, `{"ports": []}`)
+	server := handleDelete(t, "/servers/srv2")
+
+	cfg := newMachineConfig(t)
+	// machine has failed before server id is known
+	cfg.Node = model.Machine{
+		Name: "test-node-1234",
+		Role: model.RoleNode,
+	}
+
+	s := NewDeleteMachineStep()
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if atomic.LoadInt32(server) != 1 {
+		t.Errorf("server has not been deleted")
+	}
+
+	// server that has not been created is skipped
+	cfg.Node.Name = "test-node-5678"
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDeleteClusterMachines(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	var lists int32
+	th.Mux.HandleFunc("/servers/detail", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// servers are gone on the next check
+		if atomic.AddInt32(&lists, 1) > 1 {
+			fmt.Fprint(w, `{"servers": []}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"servers": [
+			{"id": "srv1", "name": "test-master-1234", "metadata": {"supergiant.io/cluster-id": "kube1234"}},
+			{"id": "srv2", "name": "test-node-1234", "metadata": {"supergiant.io/cluster-id": "kube1234"}},
+			{"id": "srv3", "name": "another-node-5678", "metadata": {"supergiant.io/cluster-id": "kube5678"}}
+		]}`)
+	})
+	handleList(t, "/v2.0/ports", nil, `{"ports": []}`)
+
+	deleted := []*int32{
+		handleDelete(t, "/servers/srv1"),
+		handleDelete(t, "/servers/srv2"),
+	}
+
+	s := NewDeleteClusterMachinesStep(time.Second, time.Millisecond)
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, newConfig(t)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for i, d := range deleted {
+		if atomic.LoadInt32(d) != 1 {
+			t.Errorf("server %d has not been deleted", i+1)
+		}
+	}
+}
+
+func TestDeleteInfra(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	var lbDeleted int32
+	th.Mux.HandleFunc("/v2.0/lbaas/loadbalancers/lb1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			th.TestFormValues(t, r, map[string]string{"cascade": "true"})
+			atomic.AddInt32(&lbDeleted, 1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		th.TestMethod(t, r, http.MethodGet)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	var detached int32
+	th.Mux.HandleFunc("/v2.0/routers/router1/remove_router_interface", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodPut)
+		th.TestJSONRequest(t, r, `{"subnet_id": "sub1"}`)
+
+		if atomic.AddInt32(&detached, 1) > 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": "router1", "subnet_id": "sub1"}`)
+	})
+
+	deleted := map[string]*int32{}
+	for _, path := range []string{
+		"/v2.0/floatingips/fip1",
+		"/os-keypairs/test-kube1234",
+		"/v2.0/routers/router1",
+		"/v2.0/subnets/sub1",
+		"/v2.0/networks/net1",
+		"/v2.0/security-groups/sg1",
+	} {
+		deleted[path] = handleDelete(t, path)
+	}
+
+	cfg := newMachineConfig(t)
+	cfg.OSConfig.LBFloatingIPID = "fip1"
+	cfg.OSConfig.LoadBalancerID = "lb1"
+	cfg.OSConfig.RouterID = "router1"
+	cfg.OSConfig.SecurityGroupID = "sg1"
+
+	s := NewDeleteInfraStep(time.Second, time.Millisecond)
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if atomic.LoadInt32(&lbDeleted) != 1 || atomic.LoadInt32(&detached) != 1 {
+		t.Errorf("load balancer has not been deleted or subnet has not been detached")
+	}
+
+	for path, d := range deleted {
+		if atomic.LoadInt32(d) != 1 {
+			t.Errorf("%s has not been deleted", path)
+		}
+	}
+
+	// resources that have gone already are skipped
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
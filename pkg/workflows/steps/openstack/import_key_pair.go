@@ -0,0 +1,68 @@
+package openstack
+
+import (
+	"context"
+	"io"
+
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds/openstacksdk"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// ImportKeyPairStep imports bootstrap key that supergiant uses to reach
+// machines over ssh, key of the user is added by authorized keys step.
+type ImportKeyPairStep struct {
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewImportKeyPairStep() *ImportKeyPairStep {
+	return &ImportKeyPairStep{
+		getServices: newServices,
+	}
+}
+
+func (s *ImportKeyPairStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, ImportKeyPairStepName)
+	}
+
+	opts := keypairs.CreateOpts{
+		Name:      resourceName(config),
+		PublicKey: config.Kube.SSHConfig.BootstrapPublicKey,
+	}
+
+	_, err = keypairs.Create(svc.compute, opts).Extract()
+	// key pair is left by previous attempt to provision the kube,
+	// bootstrap key is generated anew though
+	if openstacksdk.IsConflict(err) {
+		if err = keypairs.Delete(svc.compute, opts.Name).ExtractErr(); err == nil {
+			_, err = keypairs.Create(svc.compute, opts).Extract()
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: import key pair %s", ImportKeyPairStepName, opts.Name)
+	}
+
+	config.OSConfig.KeyPairName = opts.Name
+
+	return nil
+}
+
+func (s *ImportKeyPairStep) Name() string {
+	return ImportKeyPairStepName
+}
+
+func (s *ImportKeyPairStep) Description() string {
+	return "Import bootstrap key pair to OpenStack"
+}
+
+func (s *ImportKeyPairStep) Depends() []string {
+	return nil
+}
+
+func (s *ImportKeyPairStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,267 @@
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+)
+
+func TestCreateNetwork(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	th.Mux.HandleFunc("/v2.0/networks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodGet {
+			th.TestFormValues(t, r, map[string]string{"router:external": "true"})
+			fmt.Fprint(w, `{"networks": [{"id": "ext", "name": "public", "router:external": true}]}`)
+			return
+		}
+
+		th.TestMethod(t, r, http.MethodPost)
+		th.TestJSONRequest(t, r, `{"network": {"name": "test-kube1234", "admin_state_up": true}}`)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"network": {"id": "net1", "name": "test-kube1234"}}`)
+	})
+	handleCreate(t, "/v2.0/subnets",
+		`{"subnet": {"network_id": "net1", "name": "test-kube1234", "cidr": "172.20.0.0/16", "ip_version": 4}}`,
+		`{"subnet": {"id": "sub1", "network_id": "net1", "cidr": "172.20.0.0/16"}}`)
+	handleCreate(t, "/v2.0/routers",
+		`{"router": {"name": "test-kube1234", "external_gateway_info": {"network_id": "ext"}}}`,
+		`{"router": {"id": "router1", "name": "test-kube1234"}}`)
+	th.Mux.HandleFunc("/v2.0/routers/router1/add_router_interface", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodPut)
+		th.TestJSONRequest(t, r, `{"subnet_id": "sub1"}`)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": "router1", "subnet_id": "sub1", "port_id": "port1"}`)
+	})
+
+	cfg := newConfig(t)
+	s := NewCreateNetworkStep()
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	osCfg := cfg.OSConfig
+	if osCfg.ExternalNetworkID != "ext" || osCfg.NetworkID != "net1" || osCfg.NetworkName != "test-kube1234" {
+		t.Errorf("wrong network %v", osCfg)
+	}
+
+	if osCfg.SubnetID != "sub1" || osCfg.SubnetCIDR != defaultSubnetCIDR || osCfg.RouterID != "router1" {
+		t.Errorf("wrong subnet or router %v", osCfg)
+	}
+}
+
+func TestCreateNetworkError(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	th.Mux.HandleFunc("/v2.0/networks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"networks": []}`)
+	})
+
+	cfg := newConfig(t)
+	s := NewCreateNetworkStep()
+	s.getServices = getFakeServices
+
+	// floating ips can not be allocated without external network
+	if err := s.Run(context.Background(), nil, cfg); err == nil {
+		t.Errorf("error must be returned")
+	}
+}
+
+func TestCreateSecurityGroup(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	handleCreate(t, "/v2.0/security-groups",
+		`{"security_group": {"name": "test-kube1234", "description": "machines of kube kube1234"}}`,
+		`{"security_group": {"id": "sg1", "name": "test-kube1234"}}`)
+
+	var (
+		m     sync.Mutex
+		rules []map[string]interface{}
+	)
+	th.Mux.HandleFunc("/v2.0/security-group-rules", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodPost)
+
+		req := struct {
+			Rule map[string]interface{} `json:"security_group_rule"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode rule: %v", err)
+		}
+
+		m.Lock()
+		rules = append(rules, req.Rule)
+		m.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"security_group_rule": {"id": "rule"}}`)
+	})
+
+	cfg := newConfig(t)
+	s := NewCreateSecurityGroupStep()
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if cfg.OSConfig.SecurityGroupID != "sg1" || cfg.OSConfig.SecurityGroupName != "test-kube1234" {
+		t.Errorf("wrong security group %v", cfg.OSConfig)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if len(rules) != 3 {
+		t.Fatalf("wrong count of rules %d", len(rules))
+	}
+
+	if rules[0]["remote_group_id"] != "sg1" || rules[0]["protocol"] != nil {
+		t.Errorf("machines must talk to each other on any port %v", rules[0])
+	}
+
+	for _, rule := range rules {
+		if rule["security_group_id"] != "sg1" || rule["direction"] != "ingress" || rule["ethertype"] != "IPv4" {
+			t.Errorf("wrong rule %v", rule)
+		}
+	}
+
+	if rules[2]["port_range_min"] != float64(443) || rules[2]["port_range_max"] != float64(443) {
+		t.Errorf("api port is not open %v", rules[2])
+	}
+}
+
+func TestImportKeyPairExists(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	var created int32
+	th.Mux.HandleFunc("/os-keypairs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodPost)
+		th.TestJSONRequest(t, r, `{"keypair": {"name": "test-kube1234", "public_key": "ssh-rsa AAAA"}}`)
+
+		// key pair is left by previous attempt to provision the kube
+		if atomic.AddInt32(&created, 1) == 1 {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"conflictingRequest": {"code": 409}}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"keypair": {"name": "test-kube1234", "public_key": "ssh-rsa AAAA"}}`)
+	})
+	deleted := handleDelete(t, "/os-keypairs/test-kube1234")
+
+	cfg := newConfig(t)
+	s := NewImportKeyPairStep()
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if atomic.LoadInt32(&created) != 2 || atomic.LoadInt32(deleted) != 1 {
+		t.Errorf("key pair has not been replaced")
+	}
+
+	if cfg.OSConfig.KeyPairName != "test-kube1234" {
+		t.Errorf("wrong key pair name %s", cfg.OSConfig.KeyPairName)
+	}
+}
+
+// handleLoadBalancer creates load balancer that becomes active on the second check.
+func handleLoadBalancer(t *testing.T) {
+	handleCreate(t, "/v2.0/lbaas/loadbalancers",
+		`{"loadbalancer": {"name": "test-kube1234", "vip_subnet_id": "sub1"}}`,
+		`{"loadbalancer": {"id": "lb1", "vip_address": "172.20.0.5", "vip_port_id": "vip1",
+			"provisioning_status": "PENDING_CREATE"}}`)
+
+	var checks int32
+	th.Mux.HandleFunc("/v2.0/lbaas/loadbalancers/lb1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, http.MethodGet)
+
+		status := "ACTIVE"
+		if atomic.AddInt32(&checks, 1) == 1 {
+			status = "PENDING_CREATE"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"loadbalancer": {"id": "lb1", "provisioning_status": %q}}`, status)
+	})
+}
+
+func TestCreateLoadBalancer(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	handleLoadBalancer(t)
+	handleCreate(t, "/v2.0/lbaas/listeners",
+		`{"listener": {"name": "test-kube1234", "protocol": "TCP", "protocol_port": 443, "loadbalancer_id": "lb1"}}`,
+		`{"listener": {"id": "listener1"}}`)
+	handleCreate(t, "/v2.0/lbaas/pools",
+		`{"pool": {"name": "test-kube1234", "protocol": "TCP", "lb_algorithm": "ROUND_ROBIN", "listener_id": "listener1"}}`,
+		`{"pool": {"id": "pool1"}}`)
+	handleCreate(t, "/v2.0/floatingips",
+		`{"floatingip": {"floating_network_id": "ext", "port_id": "vip1"}}`,
+		`{"floatingip": {"id": "fip1", "floating_ip_address": "203.0.113.10", "port_id": "vip1"}}`)
+
+	cfg := newConfig(t)
+	cfg.OSConfig.ExternalNetworkID = "ext"
+	cfg.OSConfig.SubnetID = "sub1"
+
+	s := NewCreateLoadBalancerStep(time.Second, time.Millisecond)
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if cfg.OSConfig.LoadBalancerID != "lb1" || cfg.OSConfig.PoolID != "pool1" || cfg.OSConfig.LBFloatingIPID != "fip1" {
+		t.Errorf("wrong load balancer %v", cfg.OSConfig)
+	}
+
+	if cfg.Kube.ExternalDNSName != "203.0.113.10" || cfg.Kube.InternalDNSName != "172.20.0.5" {
+		t.Errorf("wrong addresses of load balancer %s %s", cfg.Kube.ExternalDNSName, cfg.Kube.InternalDNSName)
+	}
+}
+
+func TestCreateLoadBalancerError(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+
+	handleLoadBalancer(t)
+	th.Mux.HandleFunc("/v2.0/lbaas/listeners", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	cfg := newConfig(t)
+	cfg.OSConfig.SubnetID = "sub1"
+
+	s := NewCreateLoadBalancerStep(time.Second, time.Millisecond)
+	s.getServices = getFakeServices
+
+	if err := s.Run(context.Background(), nil, cfg); err == nil {
+		t.Errorf("error must be returned")
+	}
+
+	// load balancer is deleted along with the kube
+	if cfg.OSConfig.LoadBalancerID != "lb1" {
+		t.Errorf("load balancer id must be kept")
+	}
+}
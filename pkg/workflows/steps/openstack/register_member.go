@@ -0,0 +1,61 @@
+package openstack
+
+import (
+	"context"
+	"io"
+
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/pools"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// RegisterMemberStep adds master to the pool of api load balancer.
+type RegisterMemberStep struct {
+	getServices func(context.Context, steps.OSConfig) (*services, error)
+}
+
+func NewRegisterMemberStep() *RegisterMemberStep {
+	return &RegisterMemberStep{
+		getServices: newServices,
+	}
+}
+
+func (s *RegisterMemberStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if !config.IsMaster || config.OSConfig.PoolID == "" {
+		return nil
+	}
+
+	svc, err := s.getServices(ctx, config.OSConfig)
+	if err != nil {
+		return errors.Wrap(err, RegisterMemberStepName)
+	}
+
+	_, err = pools.CreateMember(svc.loadBalancer, config.OSConfig.PoolID, pools.CreateMemberOpts{
+		Address:      config.Node.PrivateIp,
+		ProtocolPort: int(config.Kube.APIServerPort),
+		SubnetID:     config.OSConfig.SubnetID,
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "%s: add member %s to pool %s", RegisterMemberStepName,
+			config.Node.PrivateIp, config.OSConfig.PoolID)
+	}
+
+	return nil
+}
+
+func (s *RegisterMemberStep) Name() string {
+	return RegisterMemberStepName
+}
+
+func (s *RegisterMemberStep) Description() string {
+	return "Add master to the pool of OpenStack load balancer"
+}
+
+func (s *RegisterMemberStep) Depends() []string {
+	return nil
+}
+
+func (s *RegisterMemberStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,69 @@
+package packet
+
+import (
+	"context"
+	"io"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// AssignElasticIPStep routes kube api address to the master unless
+// another master serves it already.
+type AssignElasticIPStep struct {
+	getServices func(string) (ProjectIPService, DeviceIPService)
+}
+
+func NewAssignElasticIPStep() *AssignElasticIPStep {
+	return &AssignElasticIPStep{
+		getServices: func(apiKey string) (ProjectIPService, DeviceIPService) {
+			client := packetsdk.New(apiKey)
+			return client.ProjectIPs, client.DeviceIPs
+		},
+	}
+}
+
+func (s *AssignElasticIPStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if !config.IsMaster || config.PacketConfig.ElasticIPID == "" {
+		return nil
+	}
+
+	projectIPs, deviceIPs := s.getServices(config.PacketConfig.APIKey)
+
+	assignments, err := listAssignments(projectIPs, deviceIPs, config.PacketConfig.ElasticIPID)
+	if err != nil {
+		return errors.Wrap(err, AssignElasticIPStepName)
+	}
+
+	if len(assignments) > 0 {
+		return nil
+	}
+
+	_, _, err = deviceIPs.Assign(config.Node.ID, &packngo.AddressStruct{
+		Address: config.PacketConfig.ElasticIP + "/32",
+	})
+	if err != nil {
+		return errors.Wrapf(err, "%s: assign elastic ip to %s", AssignElasticIPStepName, config.Node.Name)
+	}
+
+	return nil
+}
+
+func (s *AssignElasticIPStep) Name() string {
+	return AssignElasticIPStepName
+}
+
+func (s *AssignElasticIPStep) Description() string {
+	return "Assign elastic ip of kube api to master in Packet"
+}
+
+func (s *AssignElasticIPStep) Depends() []string {
+	return nil
+}
+
+func (s *AssignElasticIPStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,115 @@
+package packet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestAssignElasticIPStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		isMaster    bool
+		assignments []packngo.Href
+		getErr      error
+		assignErr   error
+		expectedErr error
+		assigned    bool
+	}{
+		{
+			description: "node",
+		},
+		{
+			description: "first master",
+			isMaster:    true,
+			assigned:    true,
+		},
+		{
+			description: "address is served by another master",
+			isMaster:    true,
+			assignments: []packngo.Href{{Href: "/ips/a1"}},
+		},
+		{
+			description: "get error",
+			isMaster:    true,
+			getErr:      fakeErr,
+			expectedErr: fakeErr,
+		},
+		{
+			description: "assign error",
+			isMaster:    true,
+			assignErr:   fakeErr,
+			expectedErr: fakeErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		projectIPs := &mockProjectIPService{}
+		projectIPs.On("Get", "ip1", mock.Anything).Return(&packngo.IPAddressReservation{
+			Assignments: tc.assignments,
+		}, tc.getErr)
+
+		deviceIPs := &mockDeviceIPService{}
+		deviceIPs.On("Get", "a1", mock.Anything).Return(assignment("a1", "d0"), nil)
+		deviceIPs.On("Assign", "d1", mock.Anything).Return(assignment("a2", "d1"), tc.assignErr)
+
+		step := NewAssignElasticIPStep()
+		step.getServices = func(string) (ProjectIPService, DeviceIPService) {
+			return projectIPs, deviceIPs
+		}
+
+		cfg := &steps.Config{
+			IsMaster: tc.isMaster,
+			Node: model.Machine{
+				ID:   "d1",
+				Name: "test-master-1234",
+			},
+			PacketConfig: steps.PacketConfig{
+				ElasticIPID: "ip1",
+				ElasticIP:   "147.75.0.10",
+			},
+		}
+
+		err := step.Run(context.Background(), nil, cfg)
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if tc.assigned {
+			deviceIPs.AssertCalled(t, "Assign", "d1", &packngo.AddressStruct{Address: "147.75.0.10/32"})
+		} else if err == nil {
+			deviceIPs.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything)
+		}
+	}
+}
+
+func TestAssignElasticIPStep(t *testing.T) {
+	step := NewAssignElasticIPStep()
+
+	if step.getServices == nil {
+		t.Fatalf("get services must not be nil")
+	}
+
+	if projectIPs, deviceIPs := step.getServices("key"); projectIPs == nil || deviceIPs == nil {
+		t.Errorf("ip services must not be nil")
+	}
+
+	if step.Name() != AssignElasticIPStepName {
+		t.Errorf("wrong step name expected %s actual %s", AssignElasticIPStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package packet
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	ImportSSHKeyStepName     = "importSSHKeyPacket"
+	ReserveElasticIPStepName = "reserveElasticIPPacket"
+	CreateMachineStepName    = "createDevicePacket"
+	AssignElasticIPStepName  = "assignElasticIPPacket"
+
+	DeleteMachineStepName         = "deleteDevicePacket"
+	DeleteClusterMachinesStepName = "deleteClusterDevicesPacket"
+	DeleteInfraStepName           = "deleteInfraPacket"
+)
+
+// States of the device
+const (
+	deviceActive = "active"
+	deviceFailed = "failed"
+)
+
+func Init() {
+	steps.RegisterStep(ImportSSHKeyStepName, NewImportSSHKeyStep())
+	steps.RegisterStep(ReserveElasticIPStepName, NewReserveElasticIPStep())
+	steps.RegisterStep(CreateMachineStepName, NewCreateMachineStep(time.Minute*20, time.Second*10))
+	steps.RegisterStep(AssignElasticIPStepName, NewAssignElasticIPStep())
+
+	steps.RegisterStep(DeleteMachineStepName, NewDeleteMachineStep())
+	steps.RegisterStep(DeleteClusterMachinesStepName, NewDeleteClusterMachinesStep(time.Minute*10, time.Second*10))
+	steps.RegisterStep(DeleteInfraStepName, NewDeleteInfraStep())
+}
+
+type SSHKeyService interface {
+	Create(*packngo.SSHKeyCreateRequest) (*packngo.SSHKey, *packngo.Response, error)
+	Delete(string) (*packngo.Response, error)
+}
+
+type DeviceService interface {
+	List(string, *packngo.ListOptions) ([]packngo.Device, *packngo.Response, error)
+	Get(string, *packngo.GetOptions) (*packngo.Device, *packngo.Response, error)
+	Create(*packngo.DeviceCreateRequest) (*packngo.Device, *packngo.Response, error)
+	Delete(string) (*packngo.Response, error)
+}
+
+// ProjectIPService reserves and releases elastic addresses of the project.
+type ProjectIPService interface {
+	Get(string, *packngo.GetOptions) (*packngo.IPAddressReservation, *packngo.Response, error)
+	Request(string, *packngo.IPReservationRequest) (*packngo.IPAddressReservation, *packngo.Response, error)
+	Remove(string) (*packngo.Response, error)
+}
+
+// DeviceIPService routes elastic addresses to devices.
+type DeviceIPService interface {
+	Get(string, *packngo.GetOptions) (*packngo.IPAddressAssignment, *packngo.Response, error)
+	Assign(string, *packngo.AddressStruct) (*packngo.IPAddressAssignment, *packngo.Response, error)
+	Unassign(string) (*packngo.Response, error)
+}
+
+// resourceName is the label of ssh key, comment of elastic ip, etc. of the kube.
+func resourceName(config *steps.Config) string {
+	return fmt.Sprintf("%s-%s", config.Kube.Name, config.Kube.ID)
+}
+
+// clusterTag marks devices of the kube, packet tags are plain strings.
+func clusterTag(config *steps.Config) string {
+	return fmt.Sprintf("%s=%s", clouds.TagClusterID, config.Kube.ID)
+}
+
+// listDevices returns devices of the project that have the tag.
+func listDevices(svc DeviceService, projectID, tag string) ([]packngo.Device, error) {
+	all, _, err := svc.List(projectID, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "list devices")
+	}
+
+	devices := make([]packngo.Device, 0, len(all))
+	for _, d := range all {
+		for _, t := range d.Tags {
+			if t == tag {
+				devices = append(devices, d)
+				break
+			}
+		}
+	}
+
+	return devices, nil
+}
+
+// listAssignments returns assignments of the elastic address, the
+// reservation refers to them by links.
+func listAssignments(projectIPs ProjectIPService, deviceIPs DeviceIPService, reservationID string) ([]*packngo.IPAddressAssignment, error) {
+	reservation, _, err := projectIPs.Get(reservationID, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get elastic ip %s", reservationID)
+	}
+
+	assignments := make([]*packngo.IPAddressAssignment, 0, len(reservation.Assignments))
+	for _, href := range reservation.Assignments {
+		a, _, err := deviceIPs.Get(path.Base(href.Href), nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get assignment %s", href.Href)
+		}
+		assignments = append(assignments, a)
+	}
+
+	return assignments, nil
+}
+
+// assignedTo is the id of the device address is assigned to.
+func assignedTo(a *packngo.IPAddressAssignment) string {
+	return path.Base(a.AssignedTo.Href)
+}
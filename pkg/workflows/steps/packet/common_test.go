@@ -0,0 +1,212 @@
+package packet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+var fakeErr = errors.New("fake error")
+
+type mockSSHKeyService struct {
+	mock.Mock
+}
+
+func (m *mockSSHKeyService) Create(req *packngo.SSHKeyCreateRequest) (*packngo.SSHKey, *packngo.Response, error) {
+	args := m.Called(req)
+	val, ok := args.Get(0).(*packngo.SSHKey)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockSSHKeyService) Delete(id string) (*packngo.Response, error) {
+	args := m.Called(id)
+	return nil, args.Error(0)
+}
+
+type mockDeviceService struct {
+	mock.Mock
+}
+
+func (m *mockDeviceService) List(projectID string, opts *packngo.ListOptions) ([]packngo.Device, *packngo.Response, error) {
+	args := m.Called(projectID, opts)
+	val, ok := args.Get(0).([]packngo.Device)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockDeviceService) Get(id string, opts *packngo.GetOptions) (*packngo.Device, *packngo.Response, error) {
+	args := m.Called(id, opts)
+	val, ok := args.Get(0).(*packngo.Device)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockDeviceService) Create(req *packngo.DeviceCreateRequest) (*packngo.Device, *packngo.Response, error) {
+	args := m.Called(req)
+	val, ok := args.Get(0).(*packngo.Device)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockDeviceService) Delete(id string) (*packngo.Response, error) {
+	args := m.Called(id)
+	return nil, args.Error(0)
+}
+
+type mockProjectIPService struct {
+	mock.Mock
+}
+
+func (m *mockProjectIPService) Get(id string, opts *packngo.GetOptions) (*packngo.IPAddressReservation, *packngo.Response, error) {
+	args := m.Called(id, opts)
+	val, ok := args.Get(0).(*packngo.IPAddressReservation)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockProjectIPService) Request(projectID string, req *packngo.IPReservationRequest) (*packngo.IPAddressReservation, *packngo.Response, error) {
+	args := m.Called(projectID, req)
+	val, ok := args.Get(0).(*packngo.IPAddressReservation)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockProjectIPService) Remove(id string) (*packngo.Response, error) {
+	args := m.Called(id)
+	return nil, args.Error(0)
+}
+
+type mockDeviceIPService struct {
+	mock.Mock
+}
+
+func (m *mockDeviceIPService) Get(id string, opts *packngo.GetOptions) (*packngo.IPAddressAssignment, *packngo.Response, error) {
+	args := m.Called(id, opts)
+	val, ok := args.Get(0).(*packngo.IPAddressAssignment)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockDeviceIPService) Assign(deviceID string, req *packngo.AddressStruct) (*packngo.IPAddressAssignment, *packngo.Response, error) {
+	args := m.Called(deviceID, req)
+	val, ok := args.Get(0).(*packngo.IPAddressAssignment)
+	if !ok {
+		return nil, nil, args.Error(1)
+	}
+	return val, nil, args.Error(1)
+}
+
+func (m *mockDeviceIPService) Unassign(id string) (*packngo.Response, error) {
+	args := m.Called(id)
+	return nil, args.Error(0)
+}
+
+// errNotFound is returned by packet api for resources that have gone.
+func errNotFound(path string) error {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	return &packngo.ErrorResponse{
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Request:    r,
+		},
+		Errors: []string{"Not found"},
+	}
+}
+
+// assignment routes elastic address to the device.
+func assignment(id, deviceID string) *packngo.IPAddressAssignment {
+	a := &packngo.IPAddressAssignment{
+		AssignedTo: packngo.Href{Href: "/devices/" + deviceID},
+	}
+	a.ID = id
+	return a
+}
+
+// device is tagged as a machine of the kube.
+func device(id, hostname, kubeID string) packngo.Device {
+	d := packngo.Device{}
+	d.ID = id
+	d.Hostname = hostname
+	d.Tags = []string{"supergiant.io/cluster-id=" + kubeID, "role=node"}
+	return d
+}
+
+func TestInit(t *testing.T) {
+	Init()
+
+	for _, name := range []string{
+		ImportSSHKeyStepName,
+		ReserveElasticIPStepName,
+		CreateMachineStepName,
+		AssignElasticIPStepName,
+		DeleteMachineStepName,
+		DeleteClusterMachinesStepName,
+		DeleteInfraStepName,
+	} {
+		if s := steps.GetStep(name); s == nil {
+			t.Errorf("%s must not be nil", name)
+		}
+	}
+}
+
+func TestListDevices(t *testing.T) {
+	svc := &mockDeviceService{}
+	svc.On("List", "project", mock.Anything).Return([]packngo.Device{
+		device("d1", "test-master-1234", "kube1234"),
+		device("d2", "another-node-5678", "kube5678"),
+		{DeviceRaw: packngo.DeviceRaw{ID: "d3", Hostname: "manual"}},
+	}, nil).Once()
+
+	devices, err := listDevices(svc, "project", "supergiant.io/cluster-id=kube1234")
+	if err != nil || len(devices) != 1 || devices[0].ID != "d1" {
+		t.Errorf("wrong devices %v error %v", devices, err)
+	}
+
+	failed := errors.New("list")
+	svc.On("List", "project", mock.Anything).Return(nil, failed)
+
+	if _, err := listDevices(svc, "project", "supergiant.io/cluster-id=kube1234"); errors.Cause(err) != failed {
+		t.Errorf("wrong error expected %v actual %v", failed, err)
+	}
+}
+
+func TestListAssignments(t *testing.T) {
+	projectIPs := &mockProjectIPService{}
+	projectIPs.On("Get", "ip1", mock.Anything).Return(&packngo.IPAddressReservation{
+		Assignments: []packngo.Href{{Href: "/ips/a1"}},
+	}, nil)
+	projectIPs.On("Get", "ip2", mock.Anything).Return(nil, errNotFound("/ips/ip2"))
+
+	deviceIPs := &mockDeviceIPService{}
+	deviceIPs.On("Get", "a1", mock.Anything).Return(assignment("a1", "d1"), nil)
+
+	assignments, err := listAssignments(projectIPs, deviceIPs, "ip1")
+	if err != nil || len(assignments) != 1 || assignedTo(assignments[0]) != "d1" {
+		t.Errorf("wrong assignments %v error %v", assignments, err)
+	}
+
+	if _, err := listAssignments(projectIPs, deviceIPs, "ip2"); err == nil {
+		t.Errorf("error must be returned")
+	}
+}
@@ -0,0 +1,163 @@
+package packet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// billingCycle of devices, nodes of the kube come and go.
+const billingCycle = "hourly"
+
+// CreateMachineStep deploys device in the facility of the kube and waits
+// until it is active, bare metal takes several minutes to come up.
+type CreateMachineStep struct {
+	Timeout     time.Duration
+	CheckPeriod time.Duration
+
+	getService func(string) DeviceService
+}
+
+func NewCreateMachineStep(timeout, checkPeriod time.Duration) *CreateMachineStep {
+	return &CreateMachineStep{
+		Timeout:     timeout,
+		CheckPeriod: checkPeriod,
+		getService: func(apiKey string) DeviceService {
+			return packetsdk.New(apiKey).Devices
+		},
+	}
+}
+
+func (s *CreateMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc := s.getService(config.PacketConfig.APIKey)
+	role := model.ToRole(config.IsMaster)
+
+	config.Node = model.Machine{
+		TaskID:   config.TaskID,
+		Role:     role,
+		Provider: clouds.Packet,
+		Size:     config.PacketConfig.Plan,
+		Region:   config.PacketConfig.Facility,
+		State:    model.MachineStateBuilding,
+		Name:     util.MakeNodeName(config.Kube.Name, config.TaskID, config.IsMaster),
+		Pool:     config.NodePool.Name,
+	}
+
+	// Update node state in cluster
+	config.NodeChan() <- config.Node
+
+	userData := config.UserData
+	if config.IsMaster && config.PacketConfig.ElasticIP != "" {
+		userData = bindElasticIP(userData, config.PacketConfig.ElasticIP)
+	}
+
+	// devices of the kube get bootstrap key only rather than all keys of the project
+	req := &packngo.DeviceCreateRequest{
+		Hostname:     config.Node.Name,
+		Plan:         config.PacketConfig.Plan,
+		Facility:     []string{config.PacketConfig.Facility},
+		OS:           config.PacketConfig.OperatingSystem,
+		BillingCycle: billingCycle,
+		ProjectID:    config.PacketConfig.ProjectID,
+		UserData:     userData,
+		Tags:         []string{clusterTag(config), fmt.Sprintf("role=%s", role)},
+	}
+	if config.PacketConfig.SSHKeyID != "" {
+		req.ProjectSSHKeys = []string{config.PacketConfig.SSHKeyID}
+	}
+
+	device, _, err := svc.Create(req)
+	if err != nil {
+		config.Node.State = model.MachineStateError
+		config.NodeChan() <- config.Node
+		return errors.Wrapf(err, "%s: create device %s", CreateMachineStepName, config.Node.Name)
+	}
+	config.Node.ID = device.ID
+
+	err = steps.WaitFor(ctx, s.Timeout, s.CheckPeriod, func() (bool, error) {
+		device, _, err = svc.Get(config.Node.ID, nil)
+		if err != nil {
+			return false, err
+		}
+
+		if device.State == deviceFailed {
+			return false, errors.Errorf("device %s has failed", device.ID)
+		}
+
+		return device.State == deviceActive, nil
+	})
+	if err != nil {
+		config.Node.State = model.MachineStateError
+		config.NodeChan() <- config.Node
+		return errors.Wrapf(err, "%s: wait for device %s", CreateMachineStepName, config.Node.Name)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, device.Created)
+	if err != nil {
+		createdAt = time.Now()
+	}
+
+	network := device.GetNetworkInfo()
+	config.Node.CreatedAt = createdAt.Unix()
+	config.Node.PublicIp = network.PublicIPv4
+	config.Node.PrivateIp = network.PrivateIPv4
+	config.Node.State = model.MachineStateProvisioning
+
+	// Update node state in cluster
+	config.NodeChan() <- config.Node
+
+	if config.IsMaster {
+		config.AddMaster(&config.Node)
+	} else {
+		config.AddNode(&config.Node)
+	}
+
+	logrus.Infof("Node has been created %v", config.Node)
+
+	return nil
+}
+
+// bindElasticIP makes master accept traffic of kube api address, packet
+// routes the address to the device but it is up to the os to bind it.
+// All masters bind it so that the address can be moved among them.
+func bindElasticIP(userData, ip string) string {
+	bind := fmt.Sprintf("ip addr add %s/32 dev lo\n", ip)
+
+	if !strings.HasPrefix(userData, "#!") {
+		return "#!/bin/bash\n" + bind + userData
+	}
+
+	lines := strings.SplitN(userData, "\n", 2)
+	if len(lines) == 1 {
+		return lines[0] + "\n" + bind
+	}
+	return lines[0] + "\n" + bind + lines[1]
+}
+
+func (s *CreateMachineStep) Name() string {
+	return CreateMachineStepName
+}
+
+func (s *CreateMachineStep) Description() string {
+	return "Create device in Packet"
+}
+
+func (s *CreateMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *CreateMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
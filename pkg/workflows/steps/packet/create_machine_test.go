@@ -0,0 +1,192 @@
+package packet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func newDevice(state string) *packngo.Device {
+	d := &packngo.Device{}
+	d.ID = "d1"
+	d.State = state
+	d.Created = "2019-08-01T10:00:00Z"
+	d.Network = []*packngo.IPAddressAssignment{
+		{IpAddressCommon: packngo.IpAddressCommon{Address: "147.75.0.20", AddressFamily: 4, Public: true, Management: true}},
+		{IpAddressCommon: packngo.IpAddressCommon{Address: "2604:1380::1", AddressFamily: 6, Public: true, Management: true}},
+		{IpAddressCommon: packngo.IpAddressCommon{Address: "10.80.0.3", AddressFamily: 4, Management: true}},
+	}
+	return d
+}
+
+func TestCreateMachineStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		isMaster    bool
+		device      *packngo.Device
+		createErr   error
+		timeout     time.Duration
+		expectError bool
+		expectedErr error
+	}{
+		{
+			description: "create error",
+			createErr:   fakeErr,
+			expectError: true,
+			expectedErr: fakeErr,
+		},
+		{
+			description: "device has failed",
+			device:      newDevice("failed"),
+			expectError: true,
+		},
+		{
+			description: "timeout exceeded",
+			device:      newDevice("provisioning"),
+			timeout:     time.Millisecond * 10,
+			expectError: true,
+			expectedErr: sgerrors.ErrTimeoutExceeded,
+		},
+		{
+			description: "node",
+			device:      newDevice("active"),
+		},
+		{
+			description: "master",
+			isMaster:    true,
+			device:      newDevice("active"),
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &mockDeviceService{}
+		svc.On("Create", mock.Anything).Return(newDevice("queued"), tc.createErr)
+		svc.On("Get", "d1", mock.Anything).Return(tc.device, nil)
+
+		timeout := tc.timeout
+		if timeout == 0 {
+			timeout = time.Second
+		}
+
+		step := NewCreateMachineStep(timeout, time.Millisecond)
+		step.getService = func(string) DeviceService {
+			return svc
+		}
+
+		cfg := &steps.Config{
+			TaskID:   "1234abcd",
+			IsMaster: tc.isMaster,
+			Kube: model.Kube{
+				ID:   "kube1234",
+				Name: "test",
+			},
+			PacketConfig: steps.PacketConfig{
+				ProjectID:       "project",
+				Facility:        "ewr1",
+				Plan:            "t1.small.x86",
+				OperatingSystem: "ubuntu_18_04",
+				SSHKeyID:        "key1",
+				ElasticIP:       "147.75.0.10",
+			},
+			UserData: "#!/bin/bash",
+			Masters:  steps.NewMap(make(map[string]*model.Machine)),
+			Nodes:    steps.NewMap(make(map[string]*model.Machine)),
+		}
+		cfg.SetNodeChan(make(chan model.Machine, 2))
+
+		err := step.Run(context.Background(), nil, cfg)
+		if (err != nil) != tc.expectError {
+			t.Errorf("%s: unexpected error %v", tc.description, err)
+			continue
+		}
+
+		if tc.expectedErr != nil && errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+		}
+
+		if n := <-cfg.NodeChan(); n.State != model.MachineStateBuilding {
+			t.Errorf("%s: wrong state of machine %s", tc.description, n.State)
+		}
+
+		n := <-cfg.NodeChan()
+		if err != nil {
+			if n.State != model.MachineStateError {
+				t.Errorf("%s: wrong state of failed machine %s", tc.description, n.State)
+			}
+			continue
+		}
+
+		if n.State != model.MachineStateProvisioning || n.ID != "d1" || n.Size != "t1.small.x86" || n.Region != "ewr1" {
+			t.Errorf("%s: wrong machine %v", tc.description, n)
+		}
+
+		if n.PublicIp != "147.75.0.20" || n.PrivateIp != "10.80.0.3" {
+			t.Errorf("%s: wrong addresses of machine %s %s", tc.description, n.PublicIp, n.PrivateIp)
+		}
+
+		if n.CreatedAt != time.Date(2019, 8, 1, 10, 0, 0, 0, time.UTC).Unix() {
+			t.Errorf("%s: wrong creation time %d", tc.description, n.CreatedAt)
+		}
+
+		req := svc.Calls[0].Arguments.Get(0).(*packngo.DeviceCreateRequest)
+		if req.ProjectID != "project" || req.BillingCycle != billingCycle || req.OS != "ubuntu_18_04" ||
+			len(req.ProjectSSHKeys) != 1 || req.ProjectSSHKeys[0] != "key1" {
+			t.Errorf("%s: wrong request %v", tc.description, req)
+		}
+
+		if len(req.Tags) != 2 || req.Tags[0] != "supergiant.io/cluster-id=kube1234" {
+			t.Errorf("%s: device is not tagged with kube id %v", tc.description, req.Tags)
+		}
+
+		if tc.isMaster {
+			if len(cfg.GetMasters()) != 1 || req.UserData != bindElasticIP("#!/bin/bash", "147.75.0.10") {
+				t.Errorf("%s: master must be added and bind kube api address", tc.description)
+			}
+		} else if len(cfg.GetNodes()) != 1 || req.UserData != "#!/bin/bash" {
+			t.Errorf("%s: node must be added with user data intact", tc.description)
+		}
+	}
+}
+
+func TestCreateMachineStep(t *testing.T) {
+	step := NewCreateMachineStep(time.Second, time.Millisecond)
+
+	if step.getService == nil || step.getService("key") == nil {
+		t.Errorf("device service must not be nil")
+	}
+
+	if step.Name() != CreateMachineStepName {
+		t.Errorf("wrong step name expected %s actual %s", CreateMachineStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestBindElasticIP(t *testing.T) {
+	for _, tc := range []struct {
+		userData string
+		expected string
+	}{
+		{"", "#!/bin/bash\nip addr add 1.2.3.4/32 dev lo\n"},
+		{"#!/bin/bash", "#!/bin/bash\nip addr add 1.2.3.4/32 dev lo\n"},
+		{"#!/bin/sh\nexit 0", "#!/bin/sh\nip addr add 1.2.3.4/32 dev lo\nexit 0"},
+	} {
+		if actual := bindElasticIP(tc.userData, "1.2.3.4"); actual != tc.expected {
+			t.Errorf("user data %q: expected %q actual %q", tc.userData, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package packet
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// DeleteClusterMachinesStep deletes all devices of the kube and waits
+// until they are gone, elastic ip can not be released while assigned.
+type DeleteClusterMachinesStep struct {
+	Timeout     time.Duration
+	CheckPeriod time.Duration
+
+	getService func(string) DeviceService
+}
+
+func NewDeleteClusterMachinesStep(timeout, checkPeriod time.Duration) *DeleteClusterMachinesStep {
+	return &DeleteClusterMachinesStep{
+		Timeout:     timeout,
+		CheckPeriod: checkPeriod,
+		getService: func(apiKey string) DeviceService {
+			return packetsdk.New(apiKey).Devices
+		},
+	}
+}
+
+func (s *DeleteClusterMachinesStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc := s.getService(config.PacketConfig.APIKey)
+
+	devices, err := listDevices(svc, config.PacketConfig.ProjectID, clusterTag(config))
+	if err != nil {
+		return errors.Wrap(err, DeleteClusterMachinesStepName)
+	}
+
+	for _, device := range devices {
+		logrus.Infof("Delete device %s", device.Hostname)
+		if _, err := svc.Delete(device.ID); steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete device %s", DeleteClusterMachinesStepName, device.Hostname)
+		}
+	}
+
+	err = steps.WaitFor(ctx, s.Timeout, s.CheckPeriod, func() (bool, error) {
+		devices, err := listDevices(svc, config.PacketConfig.ProjectID, clusterTag(config))
+		return len(devices) == 0, err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "%s: wait for devices to be deleted", DeleteClusterMachinesStepName)
+	}
+
+	return nil
+}
+
+func (s *DeleteClusterMachinesStep) Name() string {
+	return DeleteClusterMachinesStepName
+}
+
+func (s *DeleteClusterMachinesStep) Description() string {
+	return "Delete devices of the kube in Packet"
+}
+
+func (s *DeleteClusterMachinesStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteClusterMachinesStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
+
+// DeleteInfraStep releases elastic ip and removes bootstrap key of the kube,
+// resources that are gone already are skipped.
+type DeleteInfraStep struct {
+	getServices func(string) (ProjectIPService, DeviceIPService, SSHKeyService)
+}
+
+func NewDeleteInfraStep() *DeleteInfraStep {
+	return &DeleteInfraStep{
+		getServices: func(apiKey string) (ProjectIPService, DeviceIPService, SSHKeyService) {
+			client := packetsdk.New(apiKey)
+			return client.ProjectIPs, client.DeviceIPs, client.SSHKeys
+		},
+	}
+}
+
+func (s *DeleteInfraStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	cfg := config.PacketConfig
+	projectIPs, deviceIPs, sshKeys := s.getServices(cfg.APIKey)
+
+	if cfg.ElasticIPID != "" {
+		assignments, err := listAssignments(projectIPs, deviceIPs, cfg.ElasticIPID)
+		if steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+			return errors.Wrap(err, DeleteInfraStepName)
+		}
+
+		for _, a := range assignments {
+			if _, err := deviceIPs.Unassign(a.ID); steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+				return errors.Wrapf(err, "%s: unassign elastic ip %s", DeleteInfraStepName, a.ID)
+			}
+		}
+
+		if _, err := projectIPs.Remove(cfg.ElasticIPID); steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: release elastic ip %s", DeleteInfraStepName, cfg.ElasticIPID)
+		}
+	}
+
+	if cfg.SSHKeyID != "" {
+		if _, err := sshKeys.Delete(cfg.SSHKeyID); steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete ssh key %s", DeleteInfraStepName, cfg.SSHKeyID)
+		}
+	}
+
+	return nil
+}
+
+func (s *DeleteInfraStep) Name() string {
+	return DeleteInfraStepName
+}
+
+func (s *DeleteInfraStep) Description() string {
+	return "Release elastic ip and delete ssh key of the kube in Packet"
+}
+
+func (s *DeleteInfraStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteInfraStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,211 @@
+package packet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestDeleteClusterMachinesStep_Run(t *testing.T) {
+	devices := []packngo.Device{
+		device("d1", "test-master-1234", "kube1234"),
+		device("d2", "test-node-1234", "kube1234"),
+		device("d3", "another-node-5678", "kube5678"),
+	}
+
+	testCases := []struct {
+		description string
+		listErr     error
+		deleteErr   error
+		gone        bool
+		expectedErr error
+	}{
+		{
+			description: "list error",
+			listErr:     fakeErr,
+			expectedErr: fakeErr,
+		},
+		{
+			description: "delete error",
+			deleteErr:   fakeErr,
+			expectedErr: fakeErr,
+		},
+		{
+			description: "devices are not deleted in time",
+			expectedErr: sgerrors.ErrTimeoutExceeded,
+		},
+		{
+			description: "devices are deleted",
+			gone:        true,
+		},
+		{
+			description: "devices are gone already",
+			deleteErr:   errNotFound("/devices/d1"),
+			gone:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &mockDeviceService{}
+		if tc.gone {
+			svc.On("List", "project", mock.Anything).Return(devices, tc.listErr).Once()
+			svc.On("List", "project", mock.Anything).Return([]packngo.Device{devices[2]}, nil)
+		} else {
+			svc.On("List", "project", mock.Anything).Return(devices, tc.listErr)
+		}
+		svc.On("Delete", mock.Anything).Return(tc.deleteErr)
+
+		step := NewDeleteClusterMachinesStep(time.Millisecond*10, time.Millisecond)
+		step.getService = func(string) DeviceService {
+			return svc
+		}
+
+		err := step.Run(context.Background(), nil, &steps.Config{
+			Kube: model.Kube{
+				ID: "kube1234",
+			},
+			PacketConfig: steps.PacketConfig{
+				ProjectID: "project",
+			},
+		})
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if tc.listErr == nil && tc.deleteErr != fakeErr {
+			svc.AssertCalled(t, "Delete", "d1")
+			svc.AssertCalled(t, "Delete", "d2")
+		}
+		svc.AssertNotCalled(t, "Delete", "d3")
+	}
+}
+
+func TestDeleteClusterMachinesStep(t *testing.T) {
+	step := NewDeleteClusterMachinesStep(time.Second, time.Second)
+
+	if step.getService == nil || step.getService("key") == nil {
+		t.Errorf("device service must not be nil")
+	}
+
+	if step.Name() != DeleteClusterMachinesStepName {
+		t.Errorf("wrong step name expected %s actual %s", DeleteClusterMachinesStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDeleteInfraStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		cfg         steps.PacketConfig
+		getErr      error
+		removeErr   error
+		deleteErr   error
+		expectedErr error
+	}{
+		{
+			description: "infra has not been created",
+		},
+		{
+			description: "delete infra",
+			cfg:         steps.PacketConfig{ElasticIPID: "ip1", SSHKeyID: "key1"},
+		},
+		{
+			description: "infra is gone",
+			cfg:         steps.PacketConfig{ElasticIPID: "ip1", SSHKeyID: "key1"},
+			getErr:      errNotFound("/ips/ip1"),
+			removeErr:   errNotFound("/ips/ip1"),
+			deleteErr:   errNotFound("/ssh-keys/key1"),
+		},
+		{
+			description: "release error",
+			cfg:         steps.PacketConfig{ElasticIPID: "ip1", SSHKeyID: "key1"},
+			removeErr:   fakeErr,
+			expectedErr: fakeErr,
+		},
+		{
+			description: "delete key error",
+			cfg:         steps.PacketConfig{SSHKeyID: "key1"},
+			deleteErr:   fakeErr,
+			expectedErr: fakeErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		projectIPs := &mockProjectIPService{}
+		projectIPs.On("Get", "ip1", mock.Anything).Return(&packngo.IPAddressReservation{
+			Assignments: []packngo.Href{{Href: "/ips/a1"}},
+		}, tc.getErr)
+		projectIPs.On("Remove", "ip1").Return(tc.removeErr)
+
+		deviceIPs := &mockDeviceIPService{}
+		deviceIPs.On("Get", "a1", mock.Anything).Return(assignment("a1", "d1"), nil)
+		deviceIPs.On("Unassign", "a1").Return(nil)
+
+		sshKeys := &mockSSHKeyService{}
+		sshKeys.On("Delete", "key1").Return(tc.deleteErr)
+
+		step := NewDeleteInfraStep()
+		step.getServices = func(string) (ProjectIPService, DeviceIPService, SSHKeyService) {
+			return projectIPs, deviceIPs, sshKeys
+		}
+
+		err := step.Run(context.Background(), nil, &steps.Config{
+			PacketConfig: tc.cfg,
+		})
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if tc.cfg.ElasticIPID == "" {
+			projectIPs.AssertNotCalled(t, "Remove", mock.Anything)
+		} else if tc.getErr == nil {
+			// address can not be released while assigned
+			deviceIPs.AssertCalled(t, "Unassign", "a1")
+		}
+
+		if tc.cfg.SSHKeyID == "" {
+			sshKeys.AssertNotCalled(t, "Delete", mock.Anything)
+		}
+	}
+}
+
+func TestDeleteInfraStep(t *testing.T) {
+	step := NewDeleteInfraStep()
+
+	if step.getServices == nil {
+		t.Fatalf("get services must not be nil")
+	}
+
+	if projectIPs, deviceIPs, sshKeys := step.getServices("key"); projectIPs == nil || deviceIPs == nil || sshKeys == nil {
+		t.Errorf("services must not be nil")
+	}
+
+	if step.Name() != DeleteInfraStepName {
+		t.Errorf("wrong step name expected %s actual %s", DeleteInfraStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
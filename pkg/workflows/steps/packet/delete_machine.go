@@ -0,0 +1,111 @@
+package packet
+
+import (
+	"context"
+	"io"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// DeleteMachineStep deletes device, kube api address is moved to
+// another master if the device has served it.
+type DeleteMachineStep struct {
+	getServices func(string) (DeviceService, ProjectIPService, DeviceIPService)
+}
+
+func NewDeleteMachineStep() *DeleteMachineStep {
+	return &DeleteMachineStep{
+		getServices: func(apiKey string) (DeviceService, ProjectIPService, DeviceIPService) {
+			client := packetsdk.New(apiKey)
+			return client.Devices, client.ProjectIPs, client.DeviceIPs
+		},
+	}
+}
+
+func (s *DeleteMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	deviceSvc, projectIPs, deviceIPs := s.getServices(config.PacketConfig.APIKey)
+
+	deviceID := config.Node.ID
+	if deviceID == "" {
+		// machine has failed before device id is known
+		devices, err := listDevices(deviceSvc, config.PacketConfig.ProjectID, clusterTag(config))
+		if err != nil {
+			return errors.Wrap(err, DeleteMachineStepName)
+		}
+
+		for _, device := range devices {
+			if device.Hostname == config.Node.Name {
+				deviceID = device.ID
+			}
+		}
+	}
+
+	if deviceID == "" {
+		logrus.Infof("Device %s does not exist", config.Node.Name)
+		return nil
+	}
+
+	servesAPI := false
+	if config.Node.Role == model.RoleMaster && config.PacketConfig.ElasticIPID != "" {
+		assignments, err := listAssignments(projectIPs, deviceIPs, config.PacketConfig.ElasticIPID)
+		if steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+			return errors.Wrap(err, DeleteMachineStepName)
+		}
+
+		for _, a := range assignments {
+			servesAPI = servesAPI || assignedTo(a) == deviceID
+		}
+	}
+
+	// address is unassigned along with the device
+	if _, err := deviceSvc.Delete(deviceID); steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+		return errors.Wrapf(err, "%s: delete device %s", DeleteMachineStepName, deviceID)
+	}
+
+	if servesAPI {
+		if err := s.moveElasticIP(deviceIPs, config, deviceID); err != nil {
+			return errors.Wrapf(err, "%s: move elastic ip", DeleteMachineStepName)
+		}
+	}
+
+	return nil
+}
+
+func (s *DeleteMachineStep) moveElasticIP(deviceIPs DeviceIPService, config *steps.Config, deletedID string) error {
+	for _, master := range config.GetMasters() {
+		if master.ID == "" || master.ID == deletedID || master.State == model.MachineStateDeleting {
+			continue
+		}
+
+		logrus.Infof("Move kube api address %s to %s", config.PacketConfig.ElasticIP, master.Name)
+		_, _, err := deviceIPs.Assign(master.ID, &packngo.AddressStruct{
+			Address: config.PacketConfig.ElasticIP + "/32",
+		})
+		return err
+	}
+
+	logrus.Warnf("No master is left to serve kube api address %s", config.PacketConfig.ElasticIP)
+	return nil
+}
+
+func (s *DeleteMachineStep) Name() string {
+	return DeleteMachineStepName
+}
+
+func (s *DeleteMachineStep) Description() string {
+	return "Delete device in Packet"
+}
+
+func (s *DeleteMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,147 @@
+package packet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestDeleteMachineStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		node        model.Machine
+		servedBy    string
+		deleteErr   error
+		expectedErr error
+		deleted     string
+		movedTo     string
+	}{
+		{
+			description: "node",
+			node:        model.Machine{ID: "d1", Role: model.RoleNode},
+			deleted:     "d1",
+		},
+		{
+			description: "machine has failed before device id is known",
+			node:        model.Machine{Name: "test-node-1234", Role: model.RoleNode},
+			deleted:     "d2",
+		},
+		{
+			description: "device has not been created",
+			node:        model.Machine{Name: "test-node-5678", Role: model.RoleNode},
+		},
+		{
+			description: "device is gone",
+			node:        model.Machine{ID: "d1", Role: model.RoleNode},
+			deleteErr:   errNotFound("/devices/d1"),
+			deleted:     "d1",
+		},
+		{
+			description: "delete error",
+			node:        model.Machine{ID: "d1", Role: model.RoleNode},
+			deleteErr:   fakeErr,
+			expectedErr: fakeErr,
+			deleted:     "d1",
+		},
+		{
+			description: "master that serves kube api",
+			node:        model.Machine{ID: "d1", Role: model.RoleMaster},
+			servedBy:    "d1",
+			deleted:     "d1",
+			movedTo:     "d3",
+		},
+		{
+			description: "another master serves kube api",
+			node:        model.Machine{ID: "d1", Role: model.RoleMaster},
+			servedBy:    "d3",
+			deleted:     "d1",
+		},
+	}
+
+	for _, tc := range testCases {
+		deviceSvc := &mockDeviceService{}
+		deviceSvc.On("List", "project", mock.Anything).Return([]packngo.Device{
+			device("d1", "test-master-1234", "kube1234"),
+			device("d2", "test-node-1234", "kube1234"),
+		}, nil)
+		deviceSvc.On("Delete", mock.Anything).Return(tc.deleteErr)
+
+		projectIPs := &mockProjectIPService{}
+		projectIPs.On("Get", "ip1", mock.Anything).Return(&packngo.IPAddressReservation{
+			Assignments: []packngo.Href{{Href: "/ips/a1"}},
+		}, nil)
+
+		deviceIPs := &mockDeviceIPService{}
+		deviceIPs.On("Get", "a1", mock.Anything).Return(assignment("a1", tc.servedBy), nil)
+		deviceIPs.On("Assign", mock.Anything, mock.Anything).Return(assignment("a2", tc.movedTo), nil)
+
+		step := NewDeleteMachineStep()
+		step.getServices = func(string) (DeviceService, ProjectIPService, DeviceIPService) {
+			return deviceSvc, projectIPs, deviceIPs
+		}
+
+		cfg := &steps.Config{
+			Kube: model.Kube{
+				ID: "kube1234",
+			},
+			Node: tc.node,
+			PacketConfig: steps.PacketConfig{
+				ProjectID:   "project",
+				ElasticIPID: "ip1",
+				ElasticIP:   "147.75.0.10",
+			},
+			Masters: steps.NewMap(map[string]*model.Machine{
+				"test-master-1234": {ID: "d1", Name: "test-master-1234"},
+				"test-master-5678": {ID: "d3", Name: "test-master-5678"},
+			}),
+		}
+
+		err := step.Run(context.Background(), nil, cfg)
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if tc.deleted == "" {
+			deviceSvc.AssertNotCalled(t, "Delete", mock.Anything)
+		} else {
+			deviceSvc.AssertCalled(t, "Delete", tc.deleted)
+		}
+
+		if tc.movedTo == "" {
+			deviceIPs.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything)
+		} else {
+			deviceIPs.AssertCalled(t, "Assign", tc.movedTo, &packngo.AddressStruct{Address: "147.75.0.10/32"})
+		}
+	}
+}
+
+func TestDeleteMachineStep(t *testing.T) {
+	step := NewDeleteMachineStep()
+
+	if step.getServices == nil {
+		t.Fatalf("get services must not be nil")
+	}
+
+	if devices, projectIPs, deviceIPs := step.getServices("key"); devices == nil || projectIPs == nil || deviceIPs == nil {
+		t.Errorf("services must not be nil")
+	}
+
+	if step.Name() != DeleteMachineStepName {
+		t.Errorf("wrong step name expected %s actual %s", DeleteMachineStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package packet
+
+import (
+	"context"
+	"io"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// ImportSSHKeyStep adds bootstrap key to the project, devices of the kube
+// get this key only rather than all keys of the project.
+type ImportSSHKeyStep struct {
+	getService func(string) SSHKeyService
+}
+
+func NewImportSSHKeyStep() *ImportSSHKeyStep {
+	return &ImportSSHKeyStep{
+		getService: func(apiKey string) SSHKeyService {
+			return packetsdk.New(apiKey).SSHKeys
+		},
+	}
+}
+
+func (s *ImportSSHKeyStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	svc := s.getService(config.PacketConfig.APIKey)
+
+	// bootstrap key is generated anew on every attempt
+	if config.PacketConfig.SSHKeyID != "" {
+		_, err := svc.Delete(config.PacketConfig.SSHKeyID)
+		if steps.IgnoreNotFound(err, packetsdk.IsNotFound) != nil {
+			return errors.Wrapf(err, "%s: delete ssh key %s", ImportSSHKeyStepName, config.PacketConfig.SSHKeyID)
+		}
+	}
+
+	key, _, err := svc.Create(&packngo.SSHKeyCreateRequest{
+		Label:     resourceName(config),
+		Key:       config.Kube.SSHConfig.BootstrapPublicKey,
+		ProjectID: config.PacketConfig.ProjectID,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "%s: create ssh key", ImportSSHKeyStepName)
+	}
+	config.PacketConfig.SSHKeyID = key.ID
+
+	return nil
+}
+
+func (s *ImportSSHKeyStep) Name() string {
+	return ImportSSHKeyStepName
+}
+
+func (s *ImportSSHKeyStep) Description() string {
+	return "Import bootstrap ssh key to Packet project"
+}
+
+func (s *ImportSSHKeyStep) Depends() []string {
+	return nil
+}
+
+func (s *ImportSSHKeyStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,116 @@
+package packet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestImportSSHKeyStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		oldKeyID    string
+		deleteErr   error
+		createErr   error
+		expectedErr error
+	}{
+		{
+			description: "create key",
+		},
+		{
+			description: "key of previous attempt is replaced",
+			oldKeyID:    "old",
+		},
+		{
+			description: "key of previous attempt is gone",
+			oldKeyID:    "old",
+			deleteErr:   errNotFound("/ssh-keys/old"),
+		},
+		{
+			description: "delete error",
+			oldKeyID:    "old",
+			deleteErr:   fakeErr,
+			expectedErr: fakeErr,
+		},
+		{
+			description: "create error",
+			createErr:   fakeErr,
+			expectedErr: fakeErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &mockSSHKeyService{}
+		svc.On("Delete", tc.oldKeyID).Return(tc.deleteErr)
+		svc.On("Create", mock.Anything).Return(&packngo.SSHKey{ID: "key1"}, tc.createErr)
+
+		step := NewImportSSHKeyStep()
+		step.getService = func(string) SSHKeyService {
+			return svc
+		}
+
+		cfg := &steps.Config{
+			Kube: model.Kube{
+				ID:   "kube1234",
+				Name: "test",
+				SSHConfig: model.SSHConfig{
+					BootstrapPublicKey: "ssh-rsa AAAA",
+				},
+			},
+			PacketConfig: steps.PacketConfig{
+				ProjectID: "project",
+				SSHKeyID:  tc.oldKeyID,
+			},
+		}
+
+		err := step.Run(context.Background(), nil, cfg)
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if cfg.PacketConfig.SSHKeyID != "key1" {
+			t.Errorf("%s: wrong key id %s", tc.description, cfg.PacketConfig.SSHKeyID)
+		}
+
+		svc.AssertCalled(t, "Create", &packngo.SSHKeyCreateRequest{
+			Label:     "test-kube1234",
+			Key:       "ssh-rsa AAAA",
+			ProjectID: "project",
+		})
+
+		if tc.oldKeyID == "" {
+			svc.AssertNotCalled(t, "Delete", mock.Anything)
+		}
+	}
+}
+
+func TestImportSSHKeyStep(t *testing.T) {
+	step := NewImportSSHKeyStep()
+
+	if step.getService == nil || step.getService("key") == nil {
+		t.Errorf("ssh key service must not be nil")
+	}
+
+	if step.Name() != ImportSSHKeyStepName {
+		t.Errorf("wrong step name expected %s actual %s", ImportSSHKeyStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package packet
+
+import (
+	"context"
+	"io"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds/packetsdk"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// ReserveElasticIPStep reserves address of kube api, there is no load
+// balancer in packet so the address is routed to one of masters.
+type ReserveElasticIPStep struct {
+	getService func(string) ProjectIPService
+}
+
+func NewReserveElasticIPStep() *ReserveElasticIPStep {
+	return &ReserveElasticIPStep{
+		getService: func(apiKey string) ProjectIPService {
+			return packetsdk.New(apiKey).ProjectIPs
+		},
+	}
+}
+
+func (s *ReserveElasticIPStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if config.PacketConfig.ElasticIPID == "" {
+		facility := config.PacketConfig.Facility
+		reservation, _, err := s.getService(config.PacketConfig.APIKey).Request(config.PacketConfig.ProjectID,
+			&packngo.IPReservationRequest{
+				Type:        "public_ipv4",
+				Quantity:    1,
+				Description: resourceName(config),
+				Facility:    &facility,
+			})
+		if err != nil {
+			return errors.Wrapf(err, "%s: reserve elastic ip", ReserveElasticIPStepName)
+		}
+
+		config.PacketConfig.ElasticIPID = reservation.ID
+		config.PacketConfig.ElasticIP = reservation.Address
+	}
+
+	config.Kube.ExternalDNSName = config.PacketConfig.ElasticIP
+	config.Kube.InternalDNSName = config.PacketConfig.ElasticIP
+
+	return nil
+}
+
+func (s *ReserveElasticIPStep) Name() string {
+	return ReserveElasticIPStepName
+}
+
+func (s *ReserveElasticIPStep) Description() string {
+	return "Reserve elastic ip of kube api in Packet"
+}
+
+func (s *ReserveElasticIPStep) Depends() []string {
+	return nil
+}
+
+func (s *ReserveElasticIPStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,119 @@
+package packet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestReserveElasticIPStep_Run(t *testing.T) {
+	reservation := &packngo.IPAddressReservation{}
+	reservation.ID = "ip1"
+	reservation.Address = "147.75.0.10"
+
+	testCases := []struct {
+		description string
+		cfg         steps.PacketConfig
+		requestErr  error
+		expectedErr error
+		expectedIP  string
+	}{
+		{
+			description: "reserve address",
+			cfg:         steps.PacketConfig{ProjectID: "project", Facility: "ewr1"},
+			expectedIP:  "147.75.0.10",
+		},
+		{
+			description: "address of previous attempt is kept",
+			cfg: steps.PacketConfig{
+				ProjectID:   "project",
+				Facility:    "ewr1",
+				ElasticIPID: "ip0",
+				ElasticIP:   "147.75.0.5",
+			},
+			expectedIP: "147.75.0.5",
+		},
+		{
+			description: "reserve error",
+			cfg:         steps.PacketConfig{ProjectID: "project", Facility: "ewr1"},
+			requestErr:  fakeErr,
+			expectedErr: fakeErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &mockProjectIPService{}
+		svc.On("Request", "project", mock.Anything).Return(reservation, tc.requestErr)
+
+		step := NewReserveElasticIPStep()
+		step.getService = func(string) ProjectIPService {
+			return svc
+		}
+
+		cfg := &steps.Config{
+			Kube: model.Kube{
+				ID:   "kube1234",
+				Name: "test",
+			},
+			PacketConfig: tc.cfg,
+		}
+
+		err := step.Run(context.Background(), nil, cfg)
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("%s: wrong error expected %v actual %v", tc.description, tc.expectedErr, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if cfg.Kube.ExternalDNSName != tc.expectedIP || cfg.Kube.InternalDNSName != tc.expectedIP {
+			t.Errorf("%s: wrong kube api address %s %s", tc.description,
+				cfg.Kube.ExternalDNSName, cfg.Kube.InternalDNSName)
+		}
+
+		if tc.cfg.ElasticIPID != "" {
+			svc.AssertNotCalled(t, "Request", mock.Anything, mock.Anything)
+			continue
+		}
+
+		facility := "ewr1"
+		svc.AssertCalled(t, "Request", "project", &packngo.IPReservationRequest{
+			Type:        "public_ipv4",
+			Quantity:    1,
+			Description: "test-kube1234",
+			Facility:    &facility,
+		})
+
+		if cfg.PacketConfig.ElasticIPID != "ip1" {
+			t.Errorf("%s: wrong elastic ip id %s", tc.description, cfg.PacketConfig.ElasticIPID)
+		}
+	}
+}
+
+func TestReserveElasticIPStep(t *testing.T) {
+	step := NewReserveElasticIPStep()
+
+	if step.getService == nil || step.getService("key") == nil {
+		t.Errorf("ip service must not be nil")
+	}
+
+	if step.Name() != ReserveElasticIPStepName {
+		t.Errorf("wrong step name expected %s actual %s", ReserveElasticIPStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -46,7 +46,7 @@ func New(script *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
 	if err != nil {
 		return errors.Wrap(err, "run post start script step")
 	}
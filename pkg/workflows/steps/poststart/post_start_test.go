@@ -16,11 +16,14 @@ import (
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg  string
 	timeout time.Duration
 }
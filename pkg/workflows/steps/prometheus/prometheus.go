@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -16,6 +17,7 @@ const StepName = "prometheus"
 
 type Config struct {
 	RBACEnabled bool
+	Mirrors     profile.Mirrors
 }
 
 type Step struct {
@@ -45,7 +47,7 @@ func New(script *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, toStepCfg(config))
 
 	if err != nil {
 		return errors.Wrap(err, "install prometheus step")
@@ -69,5 +71,6 @@ func (s *Step) Depends() []string {
 func toStepCfg(c *steps.Config) Config {
 	return Config{
 		RBACEnabled: c.Kube.RBACEnabled,
+		Mirrors:     c.Kube.Mirrors,
 	}
 }
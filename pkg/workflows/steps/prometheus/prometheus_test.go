@@ -15,10 +15,13 @@ import (
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
@@ -122,6 +125,40 @@ func TestPrometheusRBACEnabled(t *testing.T) {
 	}
 }
 
+func TestPrometheusImageRegistry(t *testing.T) {
+	if err := templatemanager.Init("../../../../templates"); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	output := new(bytes.Buffer)
+	cfg := &steps.Config{
+		Kube: model.Kube{
+			Mirrors: profile.Mirrors{ImageRegistry: "registry.local:5000"},
+		},
+		Runner: &fakeRunner{},
+	}
+
+	if err := New(tpl).Run(context.Background(), output, cfg); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	for _, image := range []string{
+		"prometheusOperator.image.repository=registry.local:5000/coreos/prometheus-operator",
+		"prometheus.prometheusSpec.image.repository=registry.local:5000/prometheus/prometheus",
+		"grafana.image.repository=registry.local:5000/grafana/grafana",
+		"kube-state-metrics.image.repository=registry.local:5000/kube-state-metrics",
+	} {
+		if !strings.Contains(output.String(), image) {
+			t.Errorf("not found %s in %s", image, output.String())
+		}
+	}
+}
+
 func TestPrometheusErr(t *testing.T) {
 	errMsg := "error has occurred"
 
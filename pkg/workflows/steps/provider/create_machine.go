@@ -12,8 +12,12 @@ import (
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/amazon"
 	"github.com/supergiant/control/pkg/workflows/steps/azure"
+	"github.com/supergiant/control/pkg/workflows/steps/byo"
 	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
 	"github.com/supergiant/control/pkg/workflows/steps/gce"
+	"github.com/supergiant/control/pkg/workflows/steps/openstack"
+	"github.com/supergiant/control/pkg/workflows/steps/packet"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
 )
 
 const (
@@ -70,6 +74,14 @@ func createMachineStepFor(provider clouds.Name) (steps.Step, error) {
 		return steps.GetStep(gce.CreateInstanceStepName), nil
 	case clouds.Azure:
 		return steps.GetStep(azure.CreateVMStepName), nil
+	case clouds.OpenStack:
+		return steps.GetStep(openstack.CreateMachineStepName), nil
+	case clouds.Packet:
+		return steps.GetStep(packet.CreateMachineStepName), nil
+	case clouds.Simulator:
+		return steps.GetStep(simulator.CreateMachineStepName), nil
+	case clouds.BYO:
+		return steps.GetStep(byo.RegisterMachineStepName), nil
 	}
 	return nil, errors.New(fmt.Sprintf("unknown provider: %s", provider))
 }
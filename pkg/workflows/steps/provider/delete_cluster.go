@@ -11,8 +11,12 @@ import (
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/amazon"
 	"github.com/supergiant/control/pkg/workflows/steps/azure"
+	"github.com/supergiant/control/pkg/workflows/steps/byo"
 	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
 	"github.com/supergiant/control/pkg/workflows/steps/gce"
+	"github.com/supergiant/control/pkg/workflows/steps/openstack"
+	"github.com/supergiant/control/pkg/workflows/steps/packet"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
 )
 
 const (
@@ -91,6 +95,24 @@ func cleanUpStepsFor(provider clouds.Name) ([]steps.Step, error) {
 			steps.GetStep(azure.GetAuthorizerStepName),
 			steps.GetStep(azure.DeleteClusterStepName),
 		}, nil
+	case clouds.OpenStack:
+		return []steps.Step{
+			steps.GetStep(openstack.DeleteClusterMachinesStepName),
+			steps.GetStep(openstack.DeleteInfraStepName),
+		}, nil
+	case clouds.Packet:
+		return []steps.Step{
+			steps.GetStep(packet.DeleteClusterMachinesStepName),
+			steps.GetStep(packet.DeleteInfraStepName),
+		}, nil
+	case clouds.Simulator:
+		return []steps.Step{
+			steps.GetStep(simulator.DeleteClusterStepName),
+		}, nil
+	case clouds.BYO:
+		return []steps.Step{
+			steps.GetStep(byo.ResetClusterStepName),
+		}, nil
 	}
 	return nil, errors.New(fmt.Sprintf("unknown provider: %s", provider))
 }
@@ -11,8 +11,12 @@ import (
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/amazon"
 	"github.com/supergiant/control/pkg/workflows/steps/azure"
+	"github.com/supergiant/control/pkg/workflows/steps/byo"
 	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
 	"github.com/supergiant/control/pkg/workflows/steps/gce"
+	"github.com/supergiant/control/pkg/workflows/steps/openstack"
+	"github.com/supergiant/control/pkg/workflows/steps/packet"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
 )
 
 const (
@@ -61,6 +65,15 @@ func deleteMachineStepFor(provider clouds.Name) (steps.Step, error) {
 		return steps.GetStep(gce.DeleteNodeStepName), nil
 	case clouds.Azure:
 		return steps.GetStep(azure.DeleteVMStepName), nil
+	case clouds.OpenStack:
+		return steps.GetStep(openstack.DeleteMachineStepName), nil
+	case clouds.Packet:
+		return steps.GetStep(packet.DeleteMachineStepName), nil
+	case clouds.Simulator:
+		return steps.GetStep(simulator.DeleteMachineStepName), nil
+	case clouds.BYO:
+		// machine is not ours to destroy
+		return steps.GetStep(byo.ResetMachineStepName), nil
 	}
 	return nil, errors.New(fmt.Sprintf("unknown provider: %s", provider))
 }
@@ -66,6 +66,14 @@ func postStartCluster(provider clouds.Name) ([]steps.Step, error) {
 	case clouds.GCE:
 		// TODO(stgleb): Add non-bootstrap master instances to instance groups
 		return []steps.Step{}, nil
+	case clouds.OpenStack:
+		return []steps.Step{}, nil
+	case clouds.Packet:
+		return []steps.Step{}, nil
+	case clouds.Simulator:
+		return []steps.Step{}, nil
+	case clouds.BYO:
+		return []steps.Step{}, nil
 	}
 	return nil, errors.Wrapf(fmt.Errorf("unknown provider: %s", provider), PostStartCluster)
 }
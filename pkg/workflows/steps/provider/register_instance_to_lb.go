@@ -10,6 +10,8 @@ import (
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/amazon"
+	"github.com/supergiant/control/pkg/workflows/steps/openstack"
+	"github.com/supergiant/control/pkg/workflows/steps/packet"
 )
 
 const (
@@ -37,6 +39,17 @@ func (s *RegisterInstanceToLoadBalancer) Run(ctx context.Context, out io.Writer,
 		return nil
 	case clouds.Azure:
 		return nil
+	case clouds.OpenStack:
+		step = steps.GetStep(openstack.RegisterMemberStepName)
+	case clouds.Packet:
+		// Elastic ip stands for load balancer
+		step = steps.GetStep(packet.AssignElasticIPStepName)
+	case clouds.Simulator:
+		// Kube api address of simulated network needs no registration
+		return nil
+	case clouds.BYO:
+		// There is no load balancer in front of machines brought by user
+		return nil
 	default:
 		return errors.Wrapf(fmt.Errorf("unknown provider: %s", cfg.Provider), RegisterInstanceStepName)
 	}
@@ -0,0 +1,263 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/dry"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// Network is the address space of simulated kube, machines get addresses
+// from it and kube api is served at the first one.
+type Network struct {
+	ID     string `json:"id"`
+	KubeID string `json:"kubeId"`
+	Name   string `json:"name"`
+	CIDR   string `json:"cidr"`
+
+	ExternalAddress string `json:"externalAddress"`
+	InternalAddress string `json:"internalAddress"`
+
+	index    int
+	assigned int
+}
+
+// Machine is a machine of simulated kube, scripts run on it are
+// recorded unless they are run locally.
+type Machine struct {
+	model.Machine
+
+	KubeID    string `json:"kubeId"`
+	NetworkID string `json:"networkId"`
+
+	seq    int
+	m      sync.Mutex
+	output *dry.DryRunner
+}
+
+// Cloud keeps networks and machines of simulated kubes in memory,
+// they are gone when control restarts.
+type Cloud struct {
+	m sync.RWMutex
+
+	err      error
+	count    int
+	networks map[string]*Network
+	machines map[string]*Machine
+}
+
+// Default is the cloud steps registered by Init work with.
+var Default = NewCloud()
+
+func NewCloud() *Cloud {
+	return &Cloud{
+		networks: make(map[string]*Network),
+		machines: make(map[string]*Machine),
+	}
+}
+
+// Fail makes cloud return err for machines created after the call,
+// nil makes it work again.
+func (c *Cloud) Fail(err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.err = err
+}
+
+func (c *Cloud) CreateNetwork(kubeID, name string) (Network, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.count++
+	n := &Network{
+		ID:     fmt.Sprintf("network-%d", c.count),
+		KubeID: kubeID,
+		Name:   name,
+		index:  len(c.networks)%254 + 1,
+	}
+	n.CIDR = fmt.Sprintf("10.%d.0.0/16", n.index)
+	n.ExternalAddress = fmt.Sprintf("100.64.%d.1", n.index)
+	n.InternalAddress = fmt.Sprintf("10.%d.0.1", n.index)
+
+	c.networks[n.ID] = n
+
+	return *n, nil
+}
+
+func (c *Cloud) GetNetwork(id string) (Network, error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	n := c.networks[id]
+	if n == nil {
+		return Network{}, errors.Wrapf(sgerrors.ErrNotFound, "network %s", id)
+	}
+	return *n, nil
+}
+
+func (c *Cloud) DeleteNetwork(id string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.networks[id] == nil {
+		return errors.Wrapf(sgerrors.ErrNotFound, "network %s", id)
+	}
+
+	for _, machine := range c.machines {
+		if machine.NetworkID == id {
+			return errors.Errorf("network %s has machine %s", id, machine.Name)
+		}
+	}
+
+	delete(c.networks, id)
+	return nil
+}
+
+// CreateMachine starts machine in the network, id, addresses and
+// creation time of the machine are set by the cloud.
+func (c *Cloud) CreateMachine(networkID string, m model.Machine) (model.Machine, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.err != nil {
+		return model.Machine{}, c.err
+	}
+
+	n := c.networks[networkID]
+	if n == nil {
+		return model.Machine{}, errors.Wrapf(sgerrors.ErrNotFound, "network %s", networkID)
+	}
+
+	// the first address of the network is kube api
+	n.assigned++
+	host := n.assigned + 1
+
+	c.count++
+	m.ID = fmt.Sprintf("machine-%d", c.count)
+	m.PrivateIp = fmt.Sprintf("10.%d.%d.%d", n.index, host/256, host%256)
+	m.PublicIp = fmt.Sprintf("100.64.%d.%d", n.index, host%256)
+	m.CreatedAt = time.Now().Unix()
+
+	c.machines[m.ID] = &Machine{
+		Machine:   m,
+		KubeID:    n.KubeID,
+		NetworkID: n.ID,
+		seq:       c.count,
+		output:    dry.NewDryRunner(),
+	}
+
+	return m, nil
+}
+
+func (c *Cloud) GetMachine(id string) (model.Machine, error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	m := c.machines[id]
+	if m == nil {
+		return model.Machine{}, errors.Wrapf(sgerrors.ErrNotFound, "machine %s", id)
+	}
+	return m.Machine, nil
+}
+
+// ListMachines returns machines of the kube in order of creation.
+func (c *Cloud) ListMachines(kubeID string) []model.Machine {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	found := make([]*Machine, 0)
+	for _, m := range c.machines {
+		if m.KubeID == kubeID {
+			found = append(found, m)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].seq < found[j].seq
+	})
+
+	machines := make([]model.Machine, 0, len(found))
+	for _, m := range found {
+		machines = append(machines, m.Machine)
+	}
+
+	return machines
+}
+
+func (c *Cloud) DeleteMachine(id string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.machines[id] == nil {
+		return errors.Wrapf(sgerrors.ErrNotFound, "machine %s", id)
+	}
+
+	delete(c.machines, id)
+	return nil
+}
+
+// Output returns scripts that have been recorded on the machine.
+func (c *Cloud) Output(id string) (string, error) {
+	c.m.RLock()
+	m := c.machines[id]
+	c.m.RUnlock()
+
+	if m == nil {
+		return "", errors.Wrapf(sgerrors.ErrNotFound, "machine %s", id)
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	return m.output.GetOutput(), nil
+}
+
+// Recorder returns runner that records scripts on the machine, they
+// can be looked at with Output later.
+func (c *Cloud) Recorder(id string) (runner.Runner, error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	m := c.machines[id]
+	if m == nil {
+		return nil, errors.Wrapf(sgerrors.ErrNotFound, "machine %s", id)
+	}
+
+	return &recorder{machine: m}, nil
+}
+
+// recorder serializes runs on the machine so that output is not mixed
+// when several tasks, e.g. provisioning and drain, use it.
+type recorder struct {
+	machine *Machine
+}
+
+func (r *recorder) Run(cmd *runner.Command) error {
+	r.machine.m.Lock()
+	defer r.machine.m.Unlock()
+
+	return r.machine.output.Run(cmd)
+}
+
+func (r *recorder) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	r.machine.m.Lock()
+	defer r.machine.m.Unlock()
+
+	return r.machine.output.Upload(ctx, content, dst)
+}
+
+func (r *recorder) Download(ctx context.Context, src string, out io.Writer) error {
+	r.machine.m.Lock()
+	defer r.machine.m.Unlock()
+
+	return r.machine.output.Download(ctx, src, out)
+}
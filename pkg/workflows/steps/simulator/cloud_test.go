@@ -0,0 +1,130 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func TestCloud_CreateNetwork(t *testing.T) {
+	cloud := NewCloud()
+
+	first, _ := cloud.CreateNetwork("kube1234", "test-kube1234")
+	second, _ := cloud.CreateNetwork("kube5678", "test-kube5678")
+
+	if first.ID == second.ID || first.CIDR == second.CIDR {
+		t.Errorf("networks must not overlap %v %v", first, second)
+	}
+
+	if first.CIDR != "10.1.0.0/16" || first.InternalAddress != "10.1.0.1" || first.ExternalAddress != "100.64.1.1" {
+		t.Errorf("wrong network %v", first)
+	}
+
+	if n, err := cloud.GetNetwork(first.ID); err != nil || n.KubeID != "kube1234" {
+		t.Errorf("wrong network %v error %v", n, err)
+	}
+}
+
+func TestCloud_CreateMachine(t *testing.T) {
+	cloud, network, machines := newCloud(t, "kube1234", "test-master-1234", "test-node-1234")
+
+	for _, m := range machines {
+		if m.ID == "" || m.CreatedAt == 0 {
+			t.Errorf("wrong machine %v", m)
+		}
+
+		// the first address is taken by kube api
+		if m.PrivateIp == network.InternalAddress {
+			t.Errorf("machine %s is assigned kube api address", m.Name)
+		}
+	}
+
+	if machines[0].PrivateIp == machines[1].PrivateIp || machines[0].PublicIp == machines[1].PublicIp {
+		t.Errorf("address is assigned twice %v %v", machines[0], machines[1])
+	}
+
+	if _, err := cloud.CreateMachine("network-0", model.Machine{}); !sgerrors.IsNotFound(err) {
+		t.Errorf("wrong error of unknown network %v", err)
+	}
+
+	fakeErr := errors.New("out of capacity")
+	cloud.Fail(fakeErr)
+	if _, err := cloud.CreateMachine(network.ID, model.Machine{}); err != fakeErr {
+		t.Errorf("wrong error expected %v actual %v", fakeErr, err)
+	}
+
+	cloud.Fail(nil)
+	if _, err := cloud.CreateMachine(network.ID, model.Machine{}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCloud_ListMachines(t *testing.T) {
+	cloud, _, machines := newCloud(t, "kube1234", "a", "b", "c")
+
+	other, _ := cloud.CreateNetwork("kube5678", "test-kube5678")
+	if _, err := cloud.CreateMachine(other.ID, model.Machine{Name: "d"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	listed := cloud.ListMachines("kube1234")
+	if len(listed) != len(machines) {
+		t.Fatalf("wrong machines %v", listed)
+	}
+
+	for i := range listed {
+		if listed[i].ID != machines[i].ID {
+			t.Errorf("machines are not in order of creation %v", listed)
+		}
+	}
+}
+
+func TestCloud_DeleteNetwork(t *testing.T) {
+	cloud, network, machines := newCloud(t, "kube1234", "test-node-1234")
+
+	if err := cloud.DeleteNetwork(network.ID); err == nil || sgerrors.IsNotFound(err) {
+		t.Errorf("network with machines must not be deleted %v", err)
+	}
+
+	if err := cloud.DeleteMachine(machines[0].ID); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := cloud.DeleteNetwork(network.ID); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if err := cloud.DeleteNetwork(network.ID); !sgerrors.IsNotFound(err) {
+		t.Errorf("wrong error of deleted network %v", err)
+	}
+}
+
+func TestCloud_Recorder(t *testing.T) {
+	cloud, _, machines := newCloud(t, "kube1234", "test-node-1234")
+
+	r, err := cloud.Recorder(machines[0].ID)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err = r.Run(&runner.Command{
+		Ctx:    context.Background(),
+		Script: "kubeadm join",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if output, _ := cloud.Output(machines[0].ID); !strings.Contains(output, "kubeadm join") {
+		t.Errorf("script has not been recorded %q", output)
+	}
+
+	if _, err := cloud.Output("machine-0"); !sgerrors.IsNotFound(err) {
+		t.Errorf("wrong error of unknown machine %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package simulator
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/local"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	CreateNetworkStepName = "createNetworkSimulator"
+	CreateMachineStepName = "createMachineSimulator"
+
+	DeleteMachineStepName = "deleteMachineSimulator"
+	DeleteClusterStepName = "deleteClusterSimulator"
+)
+
+func Init() {
+	steps.RegisterStep(CreateNetworkStepName, NewCreateNetworkStep(Default))
+	steps.RegisterStep(CreateMachineStepName, NewCreateMachineStep(Default))
+
+	steps.RegisterStep(DeleteMachineStepName, NewDeleteMachineStep(Default))
+	steps.RegisterStep(DeleteClusterStepName, NewDeleteClusterStep(Default))
+}
+
+// NewRunner returns runner of the machine, it replaces ssh for
+// simulated kubes.
+func NewRunner(cfg steps.SimulatorConfig, machineID string) (runner.Runner, error) {
+	return runnerFor(Default, cfg, machineID)
+}
+
+func runnerFor(cloud *Cloud, cfg steps.SimulatorConfig, machineID string) (runner.Runner, error) {
+	switch cfg.Runner {
+	case "", clouds.SimulatorRunnerDry:
+		return cloud.Recorder(machineID)
+	case clouds.SimulatorRunnerLocal:
+		return local.NewRunner(), nil
+	}
+	return nil, errors.Errorf("unknown simulator runner %s", cfg.Runner)
+}
@@ -0,0 +1,101 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner/local"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// newCloud returns cloud with network of the kube and machines with
+// given names in it.
+func newCloud(t *testing.T, kubeID string, names ...string) (*Cloud, Network, []model.Machine) {
+	cloud := NewCloud()
+
+	network, err := cloud.CreateNetwork(kubeID, "test-"+kubeID)
+	if err != nil {
+		t.Fatalf("create network %v", err)
+	}
+
+	machines := make([]model.Machine, 0, len(names))
+	for _, name := range names {
+		m, err := cloud.CreateMachine(network.ID, model.Machine{Name: name})
+		if err != nil {
+			t.Fatalf("create machine %s %v", name, err)
+		}
+		machines = append(machines, m)
+	}
+
+	return cloud, network, machines
+}
+
+func TestInit(t *testing.T) {
+	Init()
+
+	for _, name := range []string{
+		CreateNetworkStepName,
+		CreateMachineStepName,
+		DeleteMachineStepName,
+		DeleteClusterStepName,
+	} {
+		if s := steps.GetStep(name); s == nil {
+			t.Errorf("step %s has not been registered", name)
+		}
+	}
+}
+
+func TestRunnerFor(t *testing.T) {
+	cloud, _, machines := newCloud(t, "kube1234", "test-node-1234")
+
+	testCases := []struct {
+		description string
+		runner      string
+		machineID   string
+		expectError bool
+		isLocal     bool
+	}{
+		{
+			description: "dry runner by default",
+			machineID:   machines[0].ID,
+		},
+		{
+			description: "dry runner",
+			runner:      clouds.SimulatorRunnerDry,
+			machineID:   machines[0].ID,
+		},
+		{
+			description: "dry runner of unknown machine",
+			machineID:   "machine-0",
+			expectError: true,
+		},
+		{
+			description: "local runner",
+			runner:      clouds.SimulatorRunnerLocal,
+			isLocal:     true,
+		},
+		{
+			description: "unknown runner",
+			runner:      "ssh",
+			machineID:   machines[0].ID,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := runnerFor(cloud, steps.SimulatorConfig{Runner: tc.runner}, tc.machineID)
+		if (err != nil) != tc.expectError {
+			t.Errorf("%s: unexpected error %v", tc.description, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if _, ok := r.(*local.Runner); ok != tc.isLocal {
+			t.Errorf("%s: wrong runner %T", tc.description, r)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package simulator
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// CreateMachineStep creates machine in the network of the kube, states
+// of the machine are reported the same way real clouds do.
+type CreateMachineStep struct {
+	cloud *Cloud
+}
+
+func NewCreateMachineStep(cloud *Cloud) *CreateMachineStep {
+	return &CreateMachineStep{
+		cloud: cloud,
+	}
+}
+
+func (s *CreateMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	config.Node = model.Machine{
+		TaskID:   config.TaskID,
+		Role:     model.ToRole(config.IsMaster),
+		Provider: clouds.Simulator,
+		Size:     config.SimulatorConfig.Size,
+		Region:   config.SimulatorConfig.Region,
+		State:    model.MachineStateBuilding,
+		Name:     util.MakeNodeName(config.Kube.Name, config.TaskID, config.IsMaster),
+		Pool:     config.NodePool.Name,
+	}
+
+	// Update node state in cluster
+	config.NodeChan() <- config.Node
+
+	machine, err := s.cloud.CreateMachine(config.SimulatorConfig.NetworkID, config.Node)
+	if err != nil {
+		config.Node.State = model.MachineStateError
+		config.NodeChan() <- config.Node
+		return errors.Wrap(err, CreateMachineStepName)
+	}
+
+	config.Node = machine
+	config.Node.State = model.MachineStateProvisioning
+
+	// Update node state in cluster
+	config.NodeChan() <- config.Node
+
+	if config.IsMaster {
+		config.AddMaster(&config.Node)
+	} else {
+		config.AddNode(&config.Node)
+	}
+
+	logrus.Infof("Node has been created %v", config.Node)
+
+	return nil
+}
+
+func (s *CreateMachineStep) Name() string {
+	return CreateMachineStepName
+}
+
+func (s *CreateMachineStep) Description() string {
+	return "Create machine in simulator"
+}
+
+func (s *CreateMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *CreateMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
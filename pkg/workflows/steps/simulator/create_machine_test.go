@@ -0,0 +1,120 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestCreateMachineStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		isMaster    bool
+		networkID   string
+		cloudErr    error
+		expectError bool
+	}{
+		{
+			description: "master",
+			isMaster:    true,
+		},
+		{
+			description: "node",
+		},
+		{
+			description: "network is gone",
+			networkID:   "network-0",
+			expectError: true,
+		},
+		{
+			description: "cloud error",
+			cloudErr:    errors.New("out of capacity"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		cloud, network, _ := newCloud(t, "kube1234")
+		cloud.Fail(tc.cloudErr)
+
+		networkID := tc.networkID
+		if networkID == "" {
+			networkID = network.ID
+		}
+
+		cfg := &steps.Config{
+			TaskID:   "1234abcd",
+			IsMaster: tc.isMaster,
+			Kube: model.Kube{
+				ID:   "kube1234",
+				Name: "test",
+			},
+			SimulatorConfig: steps.SimulatorConfig{
+				Region:    "sim-east-1",
+				Size:      "small",
+				NetworkID: networkID,
+			},
+			Masters: steps.NewMap(make(map[string]*model.Machine)),
+			Nodes:   steps.NewMap(make(map[string]*model.Machine)),
+		}
+		cfg.SetNodeChan(make(chan model.Machine, 2))
+
+		err := NewCreateMachineStep(cloud).Run(context.Background(), nil, cfg)
+		if (err != nil) != tc.expectError {
+			t.Errorf("%s: unexpected error %v", tc.description, err)
+			continue
+		}
+
+		if n := <-cfg.NodeChan(); n.State != model.MachineStateBuilding {
+			t.Errorf("%s: wrong state of machine %s", tc.description, n.State)
+		}
+
+		n := <-cfg.NodeChan()
+		if err != nil {
+			if n.State != model.MachineStateError {
+				t.Errorf("%s: wrong state of failed machine %s", tc.description, n.State)
+			}
+			if len(cloud.ListMachines("kube1234")) != 0 {
+				t.Errorf("%s: machine must not be created", tc.description)
+			}
+			continue
+		}
+
+		if n.State != model.MachineStateProvisioning || n.Provider != clouds.Simulator ||
+			n.Size != "small" || n.Region != "sim-east-1" || n.Role != model.ToRole(tc.isMaster) {
+			t.Errorf("%s: wrong machine %v", tc.description, n)
+		}
+
+		if m, err := cloud.GetMachine(n.ID); err != nil || m.Name != n.Name || m.PrivateIp != n.PrivateIp {
+			t.Errorf("%s: wrong machine in cloud %v error %v", tc.description, m, err)
+		}
+
+		if tc.isMaster && len(cfg.GetMasters()) != 1 || !tc.isMaster && len(cfg.GetNodes()) != 1 {
+			t.Errorf("%s: machine has not been added to kube", tc.description)
+		}
+	}
+}
+
+func TestCreateMachineStep(t *testing.T) {
+	step := NewCreateMachineStep(Default)
+
+	if step.cloud != Default {
+		t.Errorf("wrong cloud")
+	}
+
+	if step.Name() != CreateMachineStepName {
+		t.Errorf("wrong step name expected %s actual %s", CreateMachineStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// CreateNetworkStep creates network of the kube, network that exists
+// already is reused when provisioning is restarted.
+type CreateNetworkStep struct {
+	cloud *Cloud
+}
+
+func NewCreateNetworkStep(cloud *Cloud) *CreateNetworkStep {
+	return &CreateNetworkStep{
+		cloud: cloud,
+	}
+}
+
+func (s *CreateNetworkStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	network, err := s.cloud.GetNetwork(config.SimulatorConfig.NetworkID)
+	if steps.IgnoreNotFound(err, sgerrors.IsNotFound) != nil {
+		return errors.Wrap(err, CreateNetworkStepName)
+	}
+
+	if err != nil {
+		name := fmt.Sprintf("%s-%s", config.Kube.Name, config.Kube.ID)
+		if network, err = s.cloud.CreateNetwork(config.Kube.ID, name); err != nil {
+			return errors.Wrap(err, CreateNetworkStepName)
+		}
+		logrus.Infof("Network %s %s has been created", network.Name, network.CIDR)
+	}
+
+	config.SimulatorConfig.NetworkID = network.ID
+	config.Kube.ExternalDNSName = network.ExternalAddress
+	config.Kube.InternalDNSName = network.InternalAddress
+
+	return nil
+}
+
+func (s *CreateNetworkStep) Name() string {
+	return CreateNetworkStepName
+}
+
+func (s *CreateNetworkStep) Description() string {
+	return "Create network of the kube in simulator"
+}
+
+func (s *CreateNetworkStep) Depends() []string {
+	return nil
+}
+
+func (s *CreateNetworkStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestCreateNetworkStep_Run(t *testing.T) {
+	cloud, network, _ := newCloud(t, "kube1234")
+
+	testCases := []struct {
+		description string
+		networkID   string
+		created     bool
+	}{
+		{
+			description: "create network",
+			created:     true,
+		},
+		{
+			description: "network of previous attempt is kept",
+			networkID:   network.ID,
+		},
+		{
+			description: "network of previous attempt is gone",
+			networkID:   "network-0",
+			created:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := &steps.Config{
+			Kube: model.Kube{
+				ID:   "kube1234",
+				Name: "test",
+			},
+			SimulatorConfig: steps.SimulatorConfig{
+				NetworkID: tc.networkID,
+			},
+		}
+
+		if err := NewCreateNetworkStep(cloud).Run(context.Background(), nil, cfg); err != nil {
+			t.Errorf("%s: unexpected error %v", tc.description, err)
+			continue
+		}
+
+		n, err := cloud.GetNetwork(cfg.SimulatorConfig.NetworkID)
+		if err != nil {
+			t.Errorf("%s: network does not exist %v", tc.description, err)
+			continue
+		}
+
+		if created := n.ID != network.ID; created != tc.created {
+			t.Errorf("%s: wrong network %v", tc.description, n)
+		}
+
+		if tc.created && (n.KubeID != "kube1234" || n.Name != "test-kube1234") {
+			t.Errorf("%s: wrong network %v", tc.description, n)
+		}
+
+		if cfg.Kube.ExternalDNSName != n.ExternalAddress || cfg.Kube.InternalDNSName != n.InternalAddress {
+			t.Errorf("%s: wrong kube api addresses %s %s", tc.description,
+				cfg.Kube.ExternalDNSName, cfg.Kube.InternalDNSName)
+		}
+	}
+}
+
+func TestCreateNetworkStep(t *testing.T) {
+	step := NewCreateNetworkStep(Default)
+
+	if step.cloud != Default {
+		t.Errorf("wrong cloud")
+	}
+
+	if step.Name() != CreateNetworkStepName {
+		t.Errorf("wrong step name expected %s actual %s", CreateNetworkStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package simulator
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// DeleteClusterStep deletes machines and network of the kube.
+type DeleteClusterStep struct {
+	cloud *Cloud
+}
+
+func NewDeleteClusterStep(cloud *Cloud) *DeleteClusterStep {
+	return &DeleteClusterStep{
+		cloud: cloud,
+	}
+}
+
+func (s *DeleteClusterStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	for _, machine := range s.cloud.ListMachines(config.Kube.ID) {
+		logrus.Infof("Delete machine %s", machine.Name)
+		if err := s.cloud.DeleteMachine(machine.ID); steps.IgnoreNotFound(err, sgerrors.IsNotFound) != nil {
+			return errors.Wrap(err, DeleteClusterStepName)
+		}
+	}
+
+	if config.SimulatorConfig.NetworkID != "" {
+		if err := s.cloud.DeleteNetwork(config.SimulatorConfig.NetworkID); steps.IgnoreNotFound(err, sgerrors.IsNotFound) != nil {
+			return errors.Wrap(err, DeleteClusterStepName)
+		}
+	}
+
+	return nil
+}
+
+func (s *DeleteClusterStep) Name() string {
+	return DeleteClusterStepName
+}
+
+func (s *DeleteClusterStep) Description() string {
+	return "Delete machines and network of the kube in simulator"
+}
+
+func (s *DeleteClusterStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteClusterStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,104 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestDeleteClusterStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		networkID   func(own, other Network) string
+		expectError bool
+	}{
+		{
+			description: "delete machines and network",
+			networkID: func(own, _ Network) string {
+				return own.ID
+			},
+		},
+		{
+			description: "network has not been created",
+			networkID: func(Network, Network) string {
+				return ""
+			},
+		},
+		{
+			description: "network is gone",
+			networkID: func(Network, Network) string {
+				return "network-0"
+			},
+		},
+		{
+			description: "network is in use",
+			networkID: func(_, other Network) string {
+				return other.ID
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		cloud, own, _ := newCloud(t, "kube1234", "test-master-1234", "test-node-1234")
+
+		other, _ := cloud.CreateNetwork("kube5678", "test-kube5678")
+		if _, err := cloud.CreateMachine(other.ID, model.Machine{Name: "test-master-5678"}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		networkID := tc.networkID(own, other)
+		cfg := &steps.Config{
+			Kube: model.Kube{
+				ID: "kube1234",
+			},
+			SimulatorConfig: steps.SimulatorConfig{
+				NetworkID: networkID,
+			},
+		}
+
+		err := NewDeleteClusterStep(cloud).Run(context.Background(), nil, cfg)
+		if (err != nil) != tc.expectError {
+			t.Errorf("%s: unexpected error %v", tc.description, err)
+			continue
+		}
+
+		if left := cloud.ListMachines("kube1234"); len(left) != 0 {
+			t.Errorf("%s: machines are left %v", tc.description, left)
+		}
+
+		if len(cloud.ListMachines("kube5678")) != 1 {
+			t.Errorf("%s: machine of another kube has been deleted", tc.description)
+		}
+
+		if _, err := cloud.GetNetwork(other.ID); err != nil {
+			t.Errorf("%s: network of another kube has been deleted", tc.description)
+		}
+
+		if _, err := cloud.GetNetwork(own.ID); (err == nil) != (networkID != own.ID) {
+			t.Errorf("%s: wrong network state %v", tc.description, err)
+		}
+	}
+}
+
+func TestDeleteClusterStep(t *testing.T) {
+	step := NewDeleteClusterStep(Default)
+
+	if step.cloud != Default {
+		t.Errorf("wrong cloud")
+	}
+
+	if step.Name() != DeleteClusterStepName {
+		t.Errorf("wrong step name expected %s actual %s", DeleteClusterStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package simulator
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// DeleteMachineStep deletes machine of the kube, machine that has
+// failed before its id is known is looked up by name.
+type DeleteMachineStep struct {
+	cloud *Cloud
+}
+
+func NewDeleteMachineStep(cloud *Cloud) *DeleteMachineStep {
+	return &DeleteMachineStep{
+		cloud: cloud,
+	}
+}
+
+func (s *DeleteMachineStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	id := config.Node.ID
+	if id == "" {
+		for _, machine := range s.cloud.ListMachines(config.Kube.ID) {
+			if machine.Name == config.Node.Name {
+				id = machine.ID
+			}
+		}
+	}
+
+	if id == "" {
+		logrus.Infof("Machine %s does not exist", config.Node.Name)
+		return nil
+	}
+
+	if err := s.cloud.DeleteMachine(id); steps.IgnoreNotFound(err, sgerrors.IsNotFound) != nil {
+		return errors.Wrap(err, DeleteMachineStepName)
+	}
+
+	return nil
+}
+
+func (s *DeleteMachineStep) Name() string {
+	return DeleteMachineStepName
+}
+
+func (s *DeleteMachineStep) Description() string {
+	return "Delete machine in simulator"
+}
+
+func (s *DeleteMachineStep) Depends() []string {
+	return nil
+}
+
+func (s *DeleteMachineStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	return nil
+}
@@ -0,0 +1,87 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestDeleteMachineStep_Run(t *testing.T) {
+	testCases := []struct {
+		description string
+		node        func(machines []model.Machine) model.Machine
+		left        int
+	}{
+		{
+			description: "delete machine",
+			node: func(machines []model.Machine) model.Machine {
+				return machines[1]
+			},
+			left: 1,
+		},
+		{
+			description: "machine has failed before its id is known",
+			node: func(machines []model.Machine) model.Machine {
+				return model.Machine{Name: machines[1].Name}
+			},
+			left: 1,
+		},
+		{
+			description: "machine has not been created",
+			node: func([]model.Machine) model.Machine {
+				return model.Machine{Name: "test-node-5678"}
+			},
+			left: 2,
+		},
+		{
+			description: "machine is gone",
+			node: func([]model.Machine) model.Machine {
+				return model.Machine{ID: "machine-0", Name: "test-node-5678"}
+			},
+			left: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		cloud, _, machines := newCloud(t, "kube1234", "test-master-1234", "test-node-1234")
+
+		cfg := &steps.Config{
+			Kube: model.Kube{
+				ID: "kube1234",
+			},
+			Node: tc.node(machines),
+		}
+
+		if err := NewDeleteMachineStep(cloud).Run(context.Background(), nil, cfg); err != nil {
+			t.Errorf("%s: unexpected error %v", tc.description, err)
+			continue
+		}
+
+		left := cloud.ListMachines("kube1234")
+		if len(left) != tc.left || left[0].ID != machines[0].ID {
+			t.Errorf("%s: wrong machines are left %v", tc.description, left)
+		}
+	}
+}
+
+func TestDeleteMachineStep(t *testing.T) {
+	step := NewDeleteMachineStep(Default)
+
+	if step.cloud != Default {
+		t.Errorf("wrong cloud")
+	}
+
+	if step.Name() != DeleteMachineStepName {
+		t.Errorf("wrong step name expected %s actual %s", DeleteMachineStepName, step.Name())
+	}
+
+	if step.Description() == "" || step.Depends() != nil {
+		t.Errorf("wrong description or dependencies of step %s", step.Name())
+	}
+
+	if err := step.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -6,9 +6,11 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/runner/dry"
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
 )
 
 const StepName = "ssh"
@@ -29,16 +31,17 @@ func (s *Step) Run(ctx context.Context, writer io.Writer, config *steps.Config)
 		return nil
 	}
 
-	cfg := ssh.Config{
-		Host:    config.Node.PublicIp,
-		Port:    config.Kube.SSHConfig.Port,
-		User:    config.Kube.SSHConfig.User,
-		Timeout: config.Kube.SSHConfig.Timeout,
-		// TODO(stgleb): Use secure storage for private keys instead carrying them in plain text
-		Key: []byte(config.Kube.SSHConfig.BootstrapPrivateKey),
+	// Simulated machines can not be reached over ssh
+	if config.Provider == clouds.Simulator {
+		if config.Runner, err = simulator.NewRunner(config.SimulatorConfig, config.Node.ID); err != nil {
+			return errors.Wrap(err, "ssh config step")
+		}
+		return nil
 	}
 
-	config.Runner, err = ssh.NewRunner(cfg)
+	cfg := steps.SSHConfigFor(config.Kube.SSHConfig, config.Node)
+
+	config.Runner, err = ssh.RunnerFor(cfg, config.SSHPool)
 	if err != nil {
 		return errors.Wrap(err, "ssh config step")
 	}
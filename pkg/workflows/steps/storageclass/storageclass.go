@@ -43,7 +43,7 @@ func (s *Step) Run(ctx context.Context, w io.Writer, cfg *steps.Config) error {
 
 	log.Infof("[%s] - applying default storage class", s.Name())
 
-	err := steps.RunTemplate(ctx, s.script, cfg.Runner, w, cfg)
+	err := steps.RunTemplate(ctx, steps.Template(cfg, s.script), cfg.Runner, w, cfg)
 	if err != nil {
 		return errors.Wrap(err, "apply default storage class step")
 	}
@@ -15,12 +15,15 @@ import (
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
+	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/clustercheck"
 )
 
 //TODO cleanup all fakerunners and move them to separate package
 type fakeRunner struct {
+	testutils.FakeTransfer
+
 	errMsg string
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/profile"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/poststart"
@@ -18,7 +19,9 @@ const (
 )
 
 type Config struct {
-	RBACEnabled     bool
+	RBACEnabled bool
+	HelmVersion string
+	Mirrors     profile.Mirrors
 }
 
 type Step struct {
@@ -44,7 +47,7 @@ func New(script *template.Template) *Step {
 }
 
 func (j *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), j.script, config.Runner, out, toStepCfg(config))
+	err := steps.RunTemplate(context.Background(), steps.Template(config, j.script), config.Runner, out, toStepCfg(config))
 
 	if err != nil {
 		return errors.Wrap(err, "install tiller step")
@@ -71,6 +74,8 @@ func (s *Step) Depends() []string {
 
 func toStepCfg(c *steps.Config) Config {
 	return Config{
-		RBACEnabled:     c.Kube.RBACEnabled,
+		RBACEnabled: c.Kube.RBACEnabled,
+		HelmVersion: c.Kube.HelmVersion,
+		Mirrors:     c.Kube.Mirrors,
 	}
 }
@@ -42,7 +42,7 @@ func New(script *template.Template) *Step {
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
 	if !config.IsMaster {
-		err := steps.RunTemplate(ctx, s.script, config.Runner, out, struct{ PrivateIP string }{config.Node.PrivateIp})
+		err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, struct{ PrivateIP string }{config.Node.PrivateIp})
 
 		if err != nil {
 			return errors.Wrap(err, "uncordon step has failed")
@@ -41,7 +41,7 @@ func New(script *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(ctx, s.script, config.Runner, out, struct {
+	err := steps.RunTemplate(ctx, steps.Template(config, s.script), config.Runner, out, struct {
 		K8SVersion  string
 		IsBootstrap bool
 		IsMaster    bool
@@ -5,10 +5,44 @@ import (
 	"context"
 	"io"
 	"text/template"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/distro"
+	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/ssh"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/templatemanager"
 )
 
+// Template returns template that is effective for the kube of the task.
+// Variant of the template for OS family of the kube, e.g. docker_redhat,
+// is used when there is one, overrides take precedence over built-in
+// templates and tpl is returned when template is not overridden.
+func Template(config *Config, tpl *template.Template) *template.Template {
+	if config == nil || tpl == nil {
+		return tpl
+	}
+
+	name := tpl.Name()
+	d, err := distro.Get(config.Kube.OperatingSystem, config.Kube.OperatingSystemVersion)
+	if err == nil && d.Family != distro.FamilyDebian {
+		variant := name + "_" + d.Family
+		if variantTpl, err := templatemanager.GetTemplate(variant); err == nil {
+			name, tpl = variant, variantTpl
+		}
+	}
+
+	override, err := templatemanager.Resolve(name, config.Kube.ID, config.Kube.ProfileID)
+	if err != nil {
+		return tpl
+	}
+
+	return override
+}
+
 func RunTemplate(ctx context.Context, tpl *template.Template, r runner.Runner, output io.Writer, cfg interface{}) error {
 	resultChan := make(chan error)
 
@@ -47,3 +81,77 @@ func RunTemplate(ctx context.Context, tpl *template.Template, r runner.Runner, o
 
 	return nil
 }
+
+// UploadTemplate renders the template with cfg and uploads result to the file.
+func UploadTemplate(ctx context.Context, tpl *template.Template, r runner.Runner, dst runner.File, cfg interface{}) error {
+	buffer := new(bytes.Buffer)
+	if err := tpl.Execute(buffer, cfg); err != nil {
+		return errors.Wrapf(err, "execute template %s", tpl.Name())
+	}
+
+	return r.Upload(ctx, buffer, dst)
+}
+
+// SSHConfigFor returns config of ssh connection to the machine.
+func SSHConfigFor(sshConfig model.SSHConfig, m model.Machine) ssh.Config {
+	cfg := ssh.Config{
+		Host:    sshConfig.HostFor(m),
+		Port:    sshConfig.Port,
+		User:    sshConfig.User,
+		Timeout: sshConfig.Timeout,
+		// TODO(stgleb): Use secure storage for private keys instead carrying them in plain text
+		Key: []byte(sshConfig.BootstrapPrivateKey),
+	}
+
+	// machine has its own credentials when it was brought by user
+	if m.SSHUser != "" {
+		cfg.User = m.SSHUser
+	}
+	if m.SSHKey != "" {
+		cfg.Key = []byte(m.SSHKey)
+	}
+
+	for _, jumpHost := range sshConfig.JumpHosts {
+		cfg.JumpHosts = append(cfg.JumpHosts, ssh.JumpHost{
+			Host: jumpHost.Host,
+			Port: jumpHost.Port,
+			User: jumpHost.User,
+			Key:  []byte(jumpHost.PrivateKey),
+		})
+	}
+
+	return cfg
+}
+
+// WaitFor checks condition every period until it is met or timeout exceeds,
+// error of the condition stops waiting.
+func WaitFor(ctx context.Context, timeout, period time.Duration, condition func() (bool, error)) error {
+	after := time.After(timeout)
+
+	for {
+		done, err := condition()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-time.After(period):
+		case <-after:
+			return sgerrors.ErrTimeoutExceeded
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// IgnoreNotFound treats resources that have gone already as deleted,
+// isNotFound tells not found errors of the cloud apart.
+func IgnoreNotFound(err error, isNotFound func(error) bool) error {
+	if err != nil && isNotFound(err) {
+		return nil
+	}
+	return err
+}
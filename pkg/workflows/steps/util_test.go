@@ -3,6 +3,7 @@ package steps
 import (
 	"bytes"
 	"context"
+	"io"
 	"strings"
 	"testing"
 	"text/template"
@@ -11,7 +12,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/templatemanager"
 )
 
 type mockRunner struct {
@@ -30,6 +35,16 @@ func (m *mockRunner) Run(command *runner.Command) error {
 	return val
 }
 
+func (m *mockRunner) Upload(ctx context.Context, content io.Reader, dst runner.File) error {
+	args := m.Called(ctx, content, dst)
+	return args.Error(0)
+}
+
+func (m *mockRunner) Download(ctx context.Context, src string, out io.Writer) error {
+	args := m.Called(ctx, src, out)
+	return args.Error(0)
+}
+
 func TestRunTemplateSuccess(t *testing.T) {
 	r := &mockRunner{}
 	r.On("Run", mock.Anything).Return(nil)
@@ -126,3 +141,147 @@ func TestRunTemplateErrTemplate(t *testing.T) {
 			actualErr, "can't evaluate field")
 	}
 }
+
+func TestTemplate(t *testing.T) {
+	tpl, _ := template.New("testTemplate").Parse("default")
+
+	err := templatemanager.SetOverride(templatemanager.Override{
+		Name:    "testTemplate",
+		Scope:   templatemanager.Scope{KubeID: "kube"},
+		Version: 1,
+		Body:    "override",
+	})
+	if err != nil {
+		t.Fatalf("set override: %v", err)
+	}
+
+	for kubeID, expected := range map[string]string{
+		"kube":  "override",
+		"other": "default",
+	} {
+		buf := &bytes.Buffer{}
+		cfg := &Config{Kube: model.Kube{ID: kubeID}}
+
+		if err := Template(cfg, tpl).Execute(buf, nil); err != nil {
+			t.Fatalf("execute template: %v", err)
+		}
+
+		if buf.String() != expected {
+			t.Errorf("kube %s: expected %q actual %q", kubeID, expected, buf.String())
+		}
+	}
+
+	if Template(nil, tpl) != tpl {
+		t.Errorf("template must not be resolved without config")
+	}
+}
+
+func TestSSHConfigFor(t *testing.T) {
+	machine := model.Machine{
+		PublicIp:  "1.2.3.4",
+		PrivateIp: "10.0.0.2",
+	}
+
+	sshConfig := model.SSHConfig{
+		User:                "root",
+		Port:                "22",
+		Timeout:             10,
+		BootstrapPrivateKey: "private key",
+	}
+
+	cfg := SSHConfigFor(sshConfig, machine)
+
+	if cfg.Host != machine.PublicIp {
+		t.Errorf("wrong host expected %s actual %s", machine.PublicIp, cfg.Host)
+	}
+
+	if len(cfg.JumpHosts) != 0 {
+		t.Errorf("unexpected jump hosts %v", cfg.JumpHosts)
+	}
+
+	sshConfig.JumpHosts = []profile.JumpHost{
+		{
+			Host:       "bastion.example.com",
+			User:       "bastion",
+			PrivateKey: "bastion key",
+		},
+	}
+
+	cfg = SSHConfigFor(sshConfig, machine)
+
+	if cfg.Host != machine.PrivateIp {
+		t.Errorf("wrong host expected %s actual %s", machine.PrivateIp, cfg.Host)
+	}
+
+	if len(cfg.JumpHosts) != 1 {
+		t.Errorf("wrong count of jump hosts expected 1 actual %d", len(cfg.JumpHosts))
+		return
+	}
+
+	if cfg.JumpHosts[0].Host != "bastion.example.com" || cfg.JumpHosts[0].User != "bastion" ||
+		string(cfg.JumpHosts[0].Key) != "bastion key" {
+		t.Errorf("wrong jump host %v", cfg.JumpHosts[0])
+	}
+
+	machine.SSHUser = "ubuntu"
+	machine.SSHKey = "machine key"
+
+	cfg = SSHConfigFor(sshConfig, machine)
+
+	if cfg.User != "ubuntu" || string(cfg.Key) != "machine key" {
+		t.Errorf("credentials of the machine must be used, actual %s %s", cfg.User, cfg.Key)
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	calls := 0
+	err := WaitFor(context.Background(), time.Second, time.Millisecond, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("wrong result calls %d error %v", calls, err)
+	}
+
+	err = WaitFor(context.Background(), time.Millisecond*10, time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+	if !sgerrors.IsTimeoutExceeded(err) {
+		t.Errorf("wrong error expected %v actual %v", sgerrors.ErrTimeoutExceeded, err)
+	}
+
+	failed := errors.New("failed")
+	err = WaitFor(context.Background(), time.Second, time.Millisecond, func() (bool, error) {
+		return false, failed
+	})
+	if err != failed {
+		t.Errorf("wrong error expected %v actual %v", failed, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = WaitFor(ctx, time.Second, time.Second, func() (bool, error) {
+		return false, nil
+	})
+	if err != context.Canceled {
+		t.Errorf("wrong error expected %v actual %v", context.Canceled, err)
+	}
+}
+
+func TestIgnoreNotFound(t *testing.T) {
+	failed := errors.New("failed")
+
+	for _, tc := range []struct {
+		err      error
+		expected error
+	}{
+		{nil, nil},
+		{sgerrors.ErrNotFound, nil},
+		{errors.Wrap(sgerrors.ErrNotFound, "delete machine"), nil},
+		{failed, failed},
+	} {
+		if err := IgnoreNotFound(tc.err, sgerrors.IsNotFound); err != tc.expected {
+			t.Errorf("wrong error expected %v actual %v", tc.expected, err)
+		}
+	}
+}
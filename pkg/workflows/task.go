@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage"
 	"github.com/supergiant/control/pkg/util"
@@ -84,8 +85,11 @@ func newTask(workflowType string, workflow Workflow, repository storage.Interfac
 	}
 }
 
-// Run executes all steps of workflow and tracks the progress in persistent storage
-func (t *Task) Run(ctx context.Context, config steps.Config, out io.WriteCloser) chan error {
+// Run executes all steps of workflow and tracks the progress in persistent storage,
+// the task runs with its own copy of the config.
+func (t *Task) Run(ctx context.Context, config *steps.Config, out io.WriteCloser) chan error {
+	cfg := *config
+
 	errChan := make(chan error, 1)
 
 	if t.Status == statuses.Success {
@@ -110,7 +114,17 @@ func (t *Task) Run(ctx context.Context, config steps.Config, out io.WriteCloser)
 			}
 		}()
 
-		t.Config = &config
+		t.Config = &cfg
+
+		// All steps of the task share ssh connections to the machines,
+		// they are closed once the task is over.
+		pool := ssh.NewPool()
+		t.Config.SSHPool = pool
+		defer func() {
+			if err := pool.Close(); err != nil {
+				logrus.Debugf("close ssh connections of task %s: %v", t.ID, err)
+			}
+		}()
 
 		// Save task state before first step
 		if err := t.sync(ctx); err != nil {
@@ -147,7 +147,7 @@ func TestTaskRunError(t *testing.T) {
 	task, err := NewTask(&steps.Config{}, "mock", s)
 
 	buffer := &bufferCloser{}
-	errChan := task.Run(context.Background(), steps.Config{}, buffer)
+	errChan := task.Run(context.Background(), &steps.Config{}, buffer)
 
 	err = <-errChan
 
@@ -195,7 +195,7 @@ func TestTaskRunSuccess(t *testing.T) {
 	task, err := NewTask(&steps.Config{}, "mock", s)
 
 	buffer := &bufferCloser{}
-	errChan := task.Run(context.Background(), steps.Config{}, buffer)
+	errChan := task.Run(context.Background(), &steps.Config{}, buffer)
 
 	err = <-errChan
 
@@ -243,7 +243,7 @@ func TestWorkflowRestart(t *testing.T) {
 	}
 
 	buffer := &bufferCloser{}
-	errChan := task.Run(context.Background(), steps.Config{}, buffer)
+	errChan := task.Run(context.Background(), &steps.Config{}, buffer)
 	err = <-errChan
 
 	if err == nil {
@@ -267,7 +267,7 @@ func TestWorkflowRestart(t *testing.T) {
 	}
 
 	buffer.Reset()
-	errChan = task.Run(context.Background(), steps.Config{}, buffer)
+	errChan = task.Run(context.Background(), &steps.Config{}, buffer)
 	err = <-errChan
 
 	if err != nil {
@@ -291,7 +291,7 @@ func TestRollback(t *testing.T) {
 	require.False(t, mockStep.rollback)
 
 	buffer := &bufferCloser{}
-	errChan := task.Run(context.Background(), steps.Config{}, buffer)
+	errChan := task.Run(context.Background(), &steps.Config{}, buffer)
 	err = <-errChan
 	require.Error(t, err)
 
@@ -334,7 +334,7 @@ func TestPanicHandler(t *testing.T) {
 			step,
 		},
 	}
-	errChan := task.Run(context.Background(), steps.Config{}, &bufferCloser{})
+	errChan := task.Run(context.Background(), &steps.Config{}, &bufferCloser{})
 
 	err := <-errChan
 	require.Error(t, err)
@@ -2,8 +2,10 @@ package workflows
 
 import (
 	"encoding/json"
+
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 func DeserializeTask(data []byte, repository storage.Interface) (*Task, error) {
@@ -20,14 +22,8 @@ func DeserializeTask(data []byte, repository storage.Interface) (*Task, error) {
 	// NOTE(stgleb): If step has failed on machine creation state
 	// public ip will be blank and lead to error when restart
 	// TODO(stgleb): Move ssh runner creation to task Restart method
-	if task.Config != nil && task.Config.Node.PublicIp != "" {
-		cfg := ssh.Config{
-			Host:    task.Config.Node.PublicIp,
-			Port:    task.Config.Kube.SSHConfig.Port,
-			User:    task.Config.Kube.SSHConfig.User,
-			Timeout: task.Config.Kube.SSHConfig.Timeout,
-			Key:     []byte(task.Config.Kube.SSHConfig.BootstrapPrivateKey),
-		}
+	if task.Config != nil && task.Config.Kube.SSHConfig.HostFor(task.Config.Node) != "" {
+		cfg := steps.SSHConfigFor(task.Config.Kube.SSHConfig, task.Config.Node)
 
 		task.Config.Runner, err = ssh.NewRunner(cfg)
 
@@ -3,6 +3,9 @@ package workflows
 import (
 	"sync"
 
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/addons"
@@ -11,24 +14,33 @@ import (
 	"github.com/supergiant/control/pkg/workflows/steps/authorizedkeys"
 	"github.com/supergiant/control/pkg/workflows/steps/azure"
 	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
+	"github.com/supergiant/control/pkg/workflows/steps/byo"
 	"github.com/supergiant/control/pkg/workflows/steps/certificates"
+	"github.com/supergiant/control/pkg/workflows/steps/certs"
 	"github.com/supergiant/control/pkg/workflows/steps/cloudcontroller"
+	"github.com/supergiant/control/pkg/workflows/steps/cloudinit"
 	"github.com/supergiant/control/pkg/workflows/steps/clustercheck"
 	"github.com/supergiant/control/pkg/workflows/steps/configmap"
+	"github.com/supergiant/control/pkg/workflows/steps/containerruntime"
 	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
-	"github.com/supergiant/control/pkg/workflows/steps/docker"
 	"github.com/supergiant/control/pkg/workflows/steps/downloadk8sbinary"
 	"github.com/supergiant/control/pkg/workflows/steps/drain"
+	"github.com/supergiant/control/pkg/workflows/steps/etcd"
 	"github.com/supergiant/control/pkg/workflows/steps/evacuate"
 	"github.com/supergiant/control/pkg/workflows/steps/gce"
 	"github.com/supergiant/control/pkg/workflows/steps/helm"
+	"github.com/supergiant/control/pkg/workflows/steps/httpproxy"
 	"github.com/supergiant/control/pkg/workflows/steps/install_app"
 	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
 	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
+	"github.com/supergiant/control/pkg/workflows/steps/masters"
 	"github.com/supergiant/control/pkg/workflows/steps/network"
+	"github.com/supergiant/control/pkg/workflows/steps/openstack"
+	"github.com/supergiant/control/pkg/workflows/steps/packet"
 	"github.com/supergiant/control/pkg/workflows/steps/poststart"
 	"github.com/supergiant/control/pkg/workflows/steps/prometheus"
 	"github.com/supergiant/control/pkg/workflows/steps/provider"
+	"github.com/supergiant/control/pkg/workflows/steps/simulator"
 	"github.com/supergiant/control/pkg/workflows/steps/ssh"
 	"github.com/supergiant/control/pkg/workflows/steps/storageclass"
 	"github.com/supergiant/control/pkg/workflows/steps/tiller"
@@ -56,14 +68,28 @@ const (
 	DigitalOceanInfra = "digitaloceanInfra"
 	GCEInfra          = "gceInfra"
 	AzureInfra        = "azureInfra"
+	BYOInfra          = "byoInfra"
+	OpenStackInfra    = "openstackInfra"
+	PacketInfra       = "packetInfra"
+	SimulatorInfra    = "simulatorInfra"
 	InstallApp        = "installApp"
+	InstallAddons     = "installAddons"
 
 	ProvisionMaster = "ProvisionMaster"
 	ProvisionNode   = "ProvisionNode"
+	CloudInitMaster = "CloudInitMaster"
+	CloudInitNode   = "CloudInitNode"
+	BYOMaster       = "BYOMaster"
+	BYONode         = "BYONode"
 	DeleteNode      = "DeleteNode"
+	DeleteMaster    = "DeleteMaster"
 	DeleteCluster   = "DeleteCluster"
 	ImportCluster   = "ImportCluster"
 	Upgrade         = "Upgrade"
+	EtcdSnapshot    = "EtcdSnapshot"
+	EtcdRestore     = "EtcdRestore"
+	CertsExpiry     = "CertsExpiry"
+	CertsRotate     = "CertsRotate"
 	ApplyYaml       = "ApplyYaml"
 )
 
@@ -118,14 +144,31 @@ func Init() {
 		steps.GetStep(azure.CreateLBStepName),
 	}
 
-	masterWorkflow := []steps.Step{
-		// TODO(stgleb): Provider steps should also register itsels it step map
-		provider.StepCreateMachine{},
-		&provider.RegisterInstanceToLoadBalancer{},
-		steps.GetStep(ssh.StepName),
+	openstackInfra := []steps.Step{
+		steps.GetStep(openstack.CreateNetworkStepName),
+		steps.GetStep(openstack.CreateSecurityGroupStepName),
+		steps.GetStep(openstack.ImportKeyPairStepName),
+		steps.GetStep(openstack.CreateLoadBalancerStepName),
+	}
+
+	packetInfra := []steps.Step{
+		steps.GetStep(packet.ImportSSHKeyStepName),
+		steps.GetStep(packet.ReserveElasticIPStepName),
+	}
+
+	simulatorInfra := []steps.Step{
+		steps.GetStep(simulator.CreateNetworkStepName),
+	}
+
+	byoInfra := []steps.Step{
+		steps.GetStep(byo.CheckHostsStepName),
+	}
+
+	masterScripts := []steps.Step{
 		steps.GetStep(authorizedkeys.StepName),
+		steps.GetStep(httpproxy.StepName),
 		steps.GetStep(downloadk8sbinary.StepName),
-		steps.GetStep(docker.StepName),
+		steps.GetStep(containerruntime.StepName),
 		steps.GetStep(certificates.StepName),
 		steps.GetStep(kubeadm.StepName),
 		steps.GetStep(bootstraptoken.StepName),
@@ -136,19 +179,65 @@ func Init() {
 		steps.GetStep(helm.StepName),
 	}
 
-	nodeWorkflow := []steps.Step{
-		// TODO(stgleb): Provider steps should also register theirself it step map
-		provider.StepCreateMachine{},
-		steps.GetStep(ssh.StepName),
+	nodeScripts := []steps.Step{
 		steps.GetStep(authorizedkeys.StepName),
+		steps.GetStep(httpproxy.StepName),
 		steps.GetStep(downloadk8sbinary.StepName),
-		steps.GetStep(docker.StepName),
+		steps.GetStep(containerruntime.StepName),
 		steps.GetStep(certificates.StepName),
 		steps.GetStep(kubeadm.StepName),
 		steps.GetStep(kubelet.StepName),
 		steps.GetStep(poststart.StepName),
 	}
 
+	// Masters that join a running kube need control plane
+	// certificates uploaded again, the step does nothing otherwise
+	masterWorkflow := append([]steps.Step{
+		steps.GetStep(masters.UploadCertsStepName),
+		// TODO(stgleb): Provider steps should also register itsels it step map
+		provider.StepCreateMachine{},
+		&provider.RegisterInstanceToLoadBalancer{},
+		steps.GetStep(ssh.StepName),
+	}, masterScripts...)
+
+	nodeWorkflow := append([]steps.Step{
+		// TODO(stgleb): Provider steps should also register theirself it step map
+		provider.StepCreateMachine{},
+		steps.GetStep(ssh.StepName),
+	}, nodeScripts...)
+
+	// Machines run the same steps from user data when kube is
+	// bootstrapped with cloud-init
+	cloudInitMaster := []steps.Step{
+		steps.GetStep(masters.UploadCertsStepName),
+		cloudinit.NewUserData(masterScripts),
+		provider.StepCreateMachine{},
+		&provider.RegisterInstanceToLoadBalancer{},
+		// CA key is kept out of user data
+		steps.GetStep(ssh.StepName),
+		steps.GetStep(certificates.CAKeyStepName),
+		steps.GetStep(cloudinit.WaitStepName),
+	}
+
+	cloudInitNode := []steps.Step{
+		cloudinit.NewUserData(nodeScripts),
+		provider.StepCreateMachine{},
+		steps.GetStep(cloudinit.WaitStepName),
+	}
+
+	// Machines brought by user are neither created nor put behind
+	// a load balancer
+	byoMaster := append([]steps.Step{
+		steps.GetStep(masters.UploadCertsStepName),
+		steps.GetStep(byo.RegisterMachineStepName),
+		steps.GetStep(ssh.StepName),
+	}, masterScripts...)
+
+	byoNode := append([]steps.Step{
+		steps.GetStep(byo.RegisterMachineStepName),
+		steps.GetStep(ssh.StepName),
+	}, nodeScripts...)
+
 	postProvision := []steps.Step{
 		steps.GetStep(ssh.StepName),
 		steps.GetStep(cloudcontroller.StepName),
@@ -169,6 +258,15 @@ func Init() {
 		provider.StepDeleteMachine{},
 	}
 
+	// Master stops serving kube api before it is drained, its etcd
+	// member is removed before the machine goes away
+	deleteMasterWorkflow := []steps.Step{
+		&provider.DeregisterInstanceFromLoadBalancer{},
+		steps.GetStep(drain.StepName),
+		steps.GetStep(masters.RemoveEtcdMemberStepName),
+		provider.StepDeleteMachine{},
+	}
+
 	deleteClusterWorkflow := []steps.Step{
 		provider.DeleteCluster{},
 	}
@@ -180,6 +278,26 @@ func Init() {
 		steps.GetStep(uncordon.StepName),
 	}
 
+	etcdSnapshot := []steps.Step{
+		steps.GetStep(ssh.StepName),
+		steps.GetStep(etcd.SnapshotStepName),
+	}
+
+	etcdRestore := []steps.Step{
+		steps.GetStep(ssh.StepName),
+		steps.GetStep(etcd.RestoreStepName),
+	}
+
+	certsExpiry := []steps.Step{
+		steps.GetStep(ssh.StepName),
+		steps.GetStep(certs.ExpiryStepName),
+	}
+
+	certsRotate := []steps.Step{
+		steps.GetStep(ssh.StepName),
+		steps.GetStep(certs.RotateStepName),
+	}
+
 	apply := []steps.Step{
 		steps.GetStep(ssh.StepName),
 		steps.GetStep(apply.StepName),
@@ -190,6 +308,11 @@ func Init() {
 		steps.GetStep(install_app.StepName),
 	}
 
+	installAddons := []steps.Step{
+		steps.GetStep(ssh.StepName),
+		addons.Step{},
+	}
+
 	m.Lock()
 	defer m.Unlock()
 
@@ -197,16 +320,52 @@ func Init() {
 	workflowMap[DigitalOceanInfra] = digitalOceanInfra
 	workflowMap[GCEInfra] = gceInfra
 	workflowMap[AzureInfra] = azureInfra
+	workflowMap[OpenStackInfra] = openstackInfra
+	workflowMap[PacketInfra] = packetInfra
+	workflowMap[SimulatorInfra] = simulatorInfra
+	workflowMap[BYOInfra] = byoInfra
 
 	workflowMap[ProvisionMaster] = masterWorkflow
 	workflowMap[ProvisionNode] = nodeWorkflow
+	workflowMap[CloudInitMaster] = cloudInitMaster
+	workflowMap[CloudInitNode] = cloudInitNode
+	workflowMap[BYOMaster] = byoMaster
+	workflowMap[BYONode] = byoNode
 	workflowMap[DeleteNode] = deleteMachineWorkflow
+	workflowMap[DeleteMaster] = deleteMasterWorkflow
 	workflowMap[DeleteCluster] = deleteClusterWorkflow
 	workflowMap[PostProvision] = postProvision
 	workflowMap[ImportCluster] = importClusterWorkflow
 	workflowMap[Upgrade] = upgradeNode
+	workflowMap[EtcdSnapshot] = etcdSnapshot
+	workflowMap[EtcdRestore] = etcdRestore
+	workflowMap[CertsExpiry] = certsExpiry
+	workflowMap[CertsRotate] = certsRotate
 	workflowMap[ApplyYaml] = apply
 	workflowMap[InstallApp] = installApp
+	workflowMap[InstallAddons] = installAddons
+}
+
+// MasterWorkflow returns workflow that provisions masters of the kube.
+func MasterWorkflow(k *model.Kube) string {
+	if k.Provider == clouds.BYO {
+		return BYOMaster
+	}
+	if k.BootstrapMode == bootstrap.CloudInit {
+		return CloudInitMaster
+	}
+	return ProvisionMaster
+}
+
+// NodeWorkflow returns workflow that provisions nodes of the kube.
+func NodeWorkflow(k *model.Kube) string {
+	if k.Provider == clouds.BYO {
+		return BYONode
+	}
+	if k.BootstrapMode == bootstrap.CloudInit {
+		return CloudInitNode
+	}
+	return ProvisionNode
 }
 
 func RegisterWorkFlow(workflowName string, workflow Workflow) {
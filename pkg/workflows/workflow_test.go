@@ -0,0 +1,36 @@
+package workflows
+
+import (
+	"testing"
+
+	"github.com/supergiant/control/pkg/bootstrap"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+)
+
+func TestProvisionWorkflows(t *testing.T) {
+	testCases := []struct {
+		provider clouds.Name
+		mode     string
+		master   string
+		node     string
+	}{
+		{clouds.AWS, "", ProvisionMaster, ProvisionNode},
+		{clouds.AWS, bootstrap.SSH, ProvisionMaster, ProvisionNode},
+		{clouds.AWS, bootstrap.CloudInit, CloudInitMaster, CloudInitNode},
+		{clouds.BYO, "", BYOMaster, BYONode},
+	}
+
+	for _, tc := range testCases {
+		k := &model.Kube{Provider: tc.provider, BootstrapMode: tc.mode}
+
+		if actual := MasterWorkflow(k); actual != tc.master {
+			t.Errorf("mode %q: wrong master workflow expected %s actual %s",
+				tc.mode, tc.master, actual)
+		}
+		if actual := NodeWorkflow(k); actual != tc.node {
+			t.Errorf("mode %q: wrong node workflow expected %s actual %s",
+				tc.mode, tc.node, actual)
+		}
+	}
+}
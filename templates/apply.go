@@ -1,7 +1,6 @@
 package templates
 
 const applyTpl = `
-sudo bash -c "cat <<EOF | kubectl apply -f -
-{{ .Data }}
-EOF"
+sudo kubectl apply -f {{ .Path }}
+sudo rm -f {{ .Path }}
 `
@@ -7,12 +7,13 @@ sudo mkdir -p /etc/kubernetes
 sudo mkdir -p /etc/kubernetes/pki
 sudo mkdir -p /etc/kubernetes/pki/etcd
 
-# Use CA generated on control side
-sudo bash -c "cat > /etc/kubernetes/pki/ca.crt <<EOF
-{{ .CACert }}EOF"
-
-sudo bash -c "cat > /etc/kubernetes/pki/ca.key <<EOF
-{{ .CAKey }}EOF"
-
+{{ end }}
+{{ if .WaitCAKey }}
+# CA key is not a part of user data, control uploads it over ssh
+for i in $(seq 1 90); do
+    sudo test -f /etc/kubernetes/pki/ca.key && break
+    sleep 10
+done
+sudo test -f /etc/kubernetes/pki/ca.key
 {{ end }}
 `
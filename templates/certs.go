@@ -0,0 +1,53 @@
+package templates
+
+// certsExpiryTpl prints expiry dates of control plane certificates of
+// the master, certificates embedded in kubeconfigs included.
+const certsExpiryTpl = `
+cd /etc/kubernetes/pki
+for crt in *.crt etcd/*.crt
+do
+	sudo test -f $crt || continue
+	echo "{{ .Marker }} $crt $(sudo openssl x509 -noout -enddate -in $crt | cut -d= -f2)"
+done
+
+for conf in admin.conf controller-manager.conf scheduler.conf
+do
+	sudo test -f /etc/kubernetes/$conf || continue
+	echo "{{ .Marker }} $conf $(sudo grep client-certificate-data /etc/kubernetes/$conf | awk '{ print $2 }' | \
+		base64 -d | openssl x509 -noout -enddate | cut -d= -f2)"
+done
+`
+
+// certsRotateTpl renews control plane certificates of the master and
+// restarts components so that they pick up new ones.
+const certsRotateTpl = `
+set -e
+
+sudo kubeadm alpha certs renew all
+
+MANIFESTS=/etc/kubernetes/manifests
+STOPPED=/etc/kubernetes/manifests.rotate
+
+# kubelet stops static pods once their manifests are removed
+sudo mkdir -p $STOPPED
+sudo mv $MANIFESTS/*.yaml $STOPPED/
+for i in $(seq 1 60)
+do
+	pgrep -x 'kube-apiserver|kube-controller|kube-scheduler|etcd' > /dev/null || break
+	sleep 2
+done
+
+sudo mv $STOPPED/*.yaml $MANIFESTS/
+sudo systemctl restart kubelet
+
+sudo cp /etc/kubernetes/admin.conf $HOME/.kube/config
+sudo cp /etc/kubernetes/admin.conf /home/{{ .UserName }}/.kube/config
+sudo chown {{ .UserName }} /home/{{ .UserName }}/.kube/config
+
+for i in $(seq 1 60)
+do
+	sudo kubectl --kubeconfig=/etc/kubernetes/admin.conf get --raw /healthz > /dev/null 2>&1 && break
+	sleep 5
+done
+sudo kubectl --kubeconfig=/etc/kubernetes/admin.conf get --raw /healthz
+`
@@ -53,7 +53,7 @@ spec:
         - key: "node-role.kubernetes.io/master"
           effect: NoSchedule
       containers:
-      - image: digitalocean/digitalocean-cloud-controller-manager:v0.1.9
+      - image: {{ .Mirrors.Image "digitalocean/digitalocean-cloud-controller-manager:v0.1.9" }}
         name: digitalocean-cloud-controller-manager
         command:
           - "/bin/digitalocean-cloud-controller-manager"
@@ -0,0 +1,65 @@
+package templates
+
+const cloudInitTpl = `#!/bin/bash
+# Provisioning steps run by cloud-init, progress is reported to supergiant
+exec > >(tee -a /var/log/supergiant-bootstrap.log) 2>&1
+
+CALLBACK_URL={{ .CallbackURL }}
+CALLBACK_TOKEN={{ .Token }}
+STEPS_DIR=/var/lib/supergiant/steps
+
+report() {
+	curl -fsS -m 10 --retry 5 --retry-delay 3 -X POST \
+		-H "Authorization: Bearer ${CALLBACK_TOKEN}" \
+		-H "Content-Type: application/json" \
+		-d "{\"step\": \"$1\", \"status\": \"$2\", \"message\": \"$3\"}" \
+		${CALLBACK_URL} > /dev/null || echo "report $1 $2 has failed"
+}
+
+# addresses and id of the machine are not known until it is created
+NODE_IP=$(hostname -I | awk '{print $1}')
+{{- if eq .Provider "aws" }}
+PUBLIC_IP=$(curl -fs http://169.254.169.254/latest/meta-data/public-ipv4)
+INSTANCE_ID=$(curl -fs http://169.254.169.254/latest/meta-data/instance-id)
+{{- else if eq .Provider "digitalocean" }}
+PUBLIC_IP=$(curl -fs http://169.254.169.254/metadata/v1/interfaces/public/0/ipv4/address)
+INSTANCE_ID=$(curl -fs http://169.254.169.254/metadata/v1/id)
+{{- else if eq .Provider "gce" }}
+PUBLIC_IP=$(curl -fs -H "Metadata-Flavor: Google" http://169.254.169.254/computeMetadata/v1/instance/network-interfaces/0/access-configs/0/external-ip)
+INSTANCE_ID=$(curl -fs -H "Metadata-Flavor: Google" http://169.254.169.254/computeMetadata/v1/instance/id)
+{{- else if eq .Provider "openstack" }}
+PUBLIC_IP=$(curl -fs http://169.254.169.254/latest/meta-data/public-ipv4)
+INSTANCE_ID=$(curl -fs http://169.254.169.254/openstack/latest/meta_data.json | grep -o '"uuid": *"[^"]*"' | cut -d'"' -f4)
+{{- else if eq .Provider "packet" }}
+NODE_IP=$(curl -fs https://metadata.packet.net/2009-04-04/meta-data/local-ipv4)
+PUBLIC_IP=$(curl -fs https://metadata.packet.net/2009-04-04/meta-data/public-ipv4)
+INSTANCE_ID=$(curl -fs https://metadata.packet.net/2009-04-04/meta-data/instance-id)
+{{- else if eq .Provider "azure" }}
+PUBLIC_IP=$(curl -fs -H "Metadata: true" "http://169.254.169.254/metadata/instance/network/interface/0/ipv4/ipAddress/0/publicIpAddress?api-version=2017-08-01&format=text")
+INSTANCE_ID=$(curl -fs -H "Metadata: true" "http://169.254.169.254/metadata/instance/compute/vmId?api-version=2017-08-01&format=text")
+{{- end }}
+PUBLIC_IP=${PUBLIC_IP:-${NODE_IP}}
+
+mkdir -p ${STEPS_DIR}
+{{ range $index, $step := .Steps }}
+cat > ${STEPS_DIR}/{{ $step.Name }}.sh << 'SUPERGIANT_STEP'
+{{ $step.Script }}
+SUPERGIANT_STEP
+{{ end }}
+chown -R {{ .User }} ${STEPS_DIR}
+chmod -R go-rwx ${STEPS_DIR}
+
+# steps run as the user they are run over ssh otherwise
+for STEP in{{ range .Steps }} {{ .Name }}{{ end }}; do
+	report ${STEP} executing
+	if ! sudo -u {{ .User }} -H env NODE_IP="${NODE_IP}" PUBLIC_IP="${PUBLIC_IP}" \
+		INSTANCE_ID="${INSTANCE_ID}" bash ${STEPS_DIR}/${STEP}.sh; then
+		report ${STEP} error "see /var/log/supergiant-bootstrap.log"
+		report "" error "step ${STEP} has failed"
+		exit 1
+	fi
+	report ${STEP} success
+done
+
+report "" success
+`
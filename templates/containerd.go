@@ -0,0 +1,84 @@
+package templates
+
+const containerdTpl = `
+ARCH={{ .Arch }}
+
+# containerd needs these modules and settings for pod networking
+sudo modprobe overlay
+sudo modprobe br_netfilter
+sudo tee /etc/modules-load.d/containerd.conf > /dev/null << 'EOF'
+overlay
+br_netfilter
+EOF
+sudo tee /etc/sysctl.d/99-kubernetes-cri.conf > /dev/null << 'EOF'
+net.bridge.bridge-nf-call-iptables  = 1
+net.ipv4.ip_forward                 = 1
+net.bridge.bridge-nf-call-ip6tables = 1
+EOF
+sudo sysctl --system
+
+sudo apt-get update -y
+sudo apt-get install -y apt-transport-https ca-certificates curl gnupg-agent software-properties-common lsb-release
+
+curl -fsSL {{ .Mirrors.DockerRepository }}/linux/{{ .Distro }}/gpg | sudo apt-key add -
+
+sudo add-apt-repository \
+	"deb [arch=${ARCH}] {{ .Mirrors.DockerRepository }}/linux/{{ .Distro }} \
+	$(lsb_release -cs) \
+	stable"
+
+sudo apt-get update -y
+sudo apt-get install -y containerd.io
+
+# containerd.io package disables cri plugin, default config enables it
+sudo mkdir -p /etc/containerd
+containerd config default | sudo tee /etc/containerd/config.toml > /dev/null
+{{ if .Mirrors.ImageRegistry }}
+sudo sed -i 's|sandbox_image = .*|sandbox_image = "{{ .Mirrors.Image "k8s.gcr.io/pause:3.1" }}"|' /etc/containerd/config.toml
+{{ end }}
+sudo systemctl restart containerd
+
+sudo tee /etc/crictl.yaml > /dev/null << 'EOF'
+runtime-endpoint: unix://{{ .Socket }}
+EOF
+`
+
+const containerdRedHatTpl = `
+# containerd needs these modules and settings for pod networking
+sudo modprobe overlay
+sudo modprobe br_netfilter
+sudo tee /etc/modules-load.d/containerd.conf > /dev/null << 'EOF'
+overlay
+br_netfilter
+EOF
+sudo tee /etc/sysctl.d/99-kubernetes-cri.conf > /dev/null << 'EOF'
+net.bridge.bridge-nf-call-iptables  = 1
+net.ipv4.ip_forward                 = 1
+net.bridge.bridge-nf-call-ip6tables = 1
+EOF
+sudo sysctl --system
+
+sudo yum install -y yum-utils device-mapper-persistent-data lvm2
+sudo tee /etc/yum.repos.d/docker-ce.repo > /dev/null << 'EOF'
+[docker-ce-stable]
+name=Docker CE Stable - $basearch
+baseurl={{ .Mirrors.DockerRepository }}/linux/centos/$releasever/$basearch/stable
+enabled=1
+gpgcheck=1
+gpgkey={{ .Mirrors.DockerRepository }}/linux/centos/gpg
+EOF
+sudo yum install -y containerd.io
+
+# containerd.io package disables cri plugin, default config enables it
+sudo mkdir -p /etc/containerd
+containerd config default | sudo tee /etc/containerd/config.toml > /dev/null
+{{ if .Mirrors.ImageRegistry }}
+sudo sed -i 's|sandbox_image = .*|sandbox_image = "{{ .Mirrors.Image "k8s.gcr.io/pause:3.1" }}"|' /etc/containerd/config.toml
+{{ end }}
+sudo systemctl enable containerd
+sudo systemctl restart containerd
+
+sudo tee /etc/crictl.yaml > /dev/null << 'EOF'
+runtime-endpoint: unix://{{ .Socket }}
+EOF
+`
@@ -0,0 +1,74 @@
+package templates
+
+const crioTpl = `
+CRIO_VERSION={{ .Version }}
+REPO={{ .Mirrors.KubicRepository }}/{{ .KubicRepo }}
+
+# cri-o needs these modules and settings for pod networking
+sudo modprobe overlay
+sudo modprobe br_netfilter
+sudo tee /etc/modules-load.d/crio.conf > /dev/null << 'EOF'
+overlay
+br_netfilter
+EOF
+sudo tee /etc/sysctl.d/99-kubernetes-cri.conf > /dev/null << 'EOF'
+net.bridge.bridge-nf-call-iptables  = 1
+net.ipv4.ip_forward                 = 1
+net.bridge.bridge-nf-call-ip6tables = 1
+EOF
+sudo sysctl --system
+
+sudo apt-get update -y
+sudo apt-get install -y apt-transport-https ca-certificates curl gnupg-agent
+
+echo "deb ${REPO}/ /" | sudo tee /etc/apt/sources.list.d/devel:kubic:libcontainers:stable.list
+curl -fsSL ${REPO}/Release.key | sudo apt-key add -
+
+sudo apt-get update -y
+sudo apt-get install -y cri-o-${CRIO_VERSION}
+
+{{ if .Mirrors.ImageRegistry }}
+sudo sed -i 's|^#\? \?pause_image = .*|pause_image = "{{ .Mirrors.Image "k8s.gcr.io/pause:3.1" }}"|' /etc/crio/crio.conf
+{{ end }}
+sudo systemctl daemon-reload
+sudo systemctl enable crio
+sudo systemctl restart crio
+
+sudo tee /etc/crictl.yaml > /dev/null << 'EOF'
+runtime-endpoint: unix://{{ .Socket }}
+EOF
+`
+
+const crioRedHatTpl = `
+CRIO_VERSION={{ .Version }}
+REPO={{ .Mirrors.KubicRepository }}/{{ .KubicRepo }}
+
+# cri-o needs these modules and settings for pod networking
+sudo modprobe overlay
+sudo modprobe br_netfilter
+sudo tee /etc/modules-load.d/crio.conf > /dev/null << 'EOF'
+overlay
+br_netfilter
+EOF
+sudo tee /etc/sysctl.d/99-kubernetes-cri.conf > /dev/null << 'EOF'
+net.bridge.bridge-nf-call-iptables  = 1
+net.ipv4.ip_forward                 = 1
+net.bridge.bridge-nf-call-ip6tables = 1
+EOF
+sudo sysctl --system
+
+sudo curl -sSL -o /etc/yum.repos.d/devel:kubic:libcontainers:stable.repo \
+	${REPO}/devel:kubic:libcontainers:stable.repo
+sudo yum install -y "cri-o-${CRIO_VERSION}*"
+
+{{ if .Mirrors.ImageRegistry }}
+sudo sed -i 's|^#\? \?pause_image = .*|pause_image = "{{ .Mirrors.Image "k8s.gcr.io/pause:3.1" }}"|' /etc/crio/crio.conf
+{{ end }}
+sudo systemctl daemon-reload
+sudo systemctl enable crio
+sudo systemctl restart crio
+
+sudo tee /etc/crictl.yaml > /dev/null << 'EOF'
+runtime-endpoint: unix://{{ .Socket }}
+EOF
+`
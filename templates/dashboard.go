@@ -10,12 +10,15 @@ package templates
 const dashboardTpl = `
 sudo /usr/bin/helm install stable/heapster \
    -n heapster \
-   --namespace kube-system
+   --namespace kube-system{{ if .Mirrors.ImageRegistry }} \
+   --set image.repository={{ .Mirrors.Image "k8s.gcr.io/heapster" }} \
+   --set resizer.image.repository={{ .Mirrors.Image "k8s.gcr.io/addon-resizer" }}{{ end }}
 
 sudo /usr/bin/helm install stable/kubernetes-dashboard \
    -n kubernetes-dashboard \
    --namespace kube-system \
    --set enableSkipLogin=true \
    --set enableInsecureLogin=true \
-   --set rbac.clusterAdminRole=true
+   --set rbac.clusterAdminRole=true{{ if .Mirrors.ImageRegistry }} \
+   --set image.repository={{ .Mirrors.Image "k8s.gcr.io/kubernetes-dashboard-amd64" }}{{ end }}
 `
@@ -5,13 +5,13 @@ DOCKER_VERSION={{ .Version }}
 ARCH={{ .Arch }}
 
 sudo apt-get update -y
-sudo apt-get install -y apt-transport-https ca-certificates curl gnupg-agent software-properties-common
+sudo apt-get install -y apt-transport-https ca-certificates curl gnupg-agent software-properties-common lsb-release
 
-curl -fsSL https://download.docker.com/linux/ubuntu/gpg | sudo apt-key add -
+curl -fsSL {{ .Mirrors.DockerRepository }}/linux/{{ .Distro }}/gpg | sudo apt-key add -
 sudo apt-key fingerprint 0EBFCD88
 
 sudo add-apt-repository \
-	"deb [arch=${ARCH}] https://download.docker.com/linux/ubuntu \
+	"deb [arch=${ARCH}] {{ .Mirrors.DockerRepository }}/linux/{{ .Distro }} \
 	$(lsb_release -cs) \
 	stable"
 
@@ -30,3 +30,32 @@ fi
 
 sudo apt-get install -y docker-ce=${FULL_DOCKER_VERSION} containerd.io
 `
+
+const dockerRedHatTpl = `
+DOCKER_VERSION={{ .Version }}
+
+sudo yum install -y yum-utils device-mapper-persistent-data lvm2
+sudo tee /etc/yum.repos.d/docker-ce.repo > /dev/null << 'EOF'
+[docker-ce-stable]
+name=Docker CE Stable - $basearch
+baseurl={{ .Mirrors.DockerRepository }}/linux/centos/$releasever/$basearch/stable
+enabled=1
+gpgcheck=1
+gpgkey={{ .Mirrors.DockerRepository }}/linux/centos/gpg
+EOF
+
+# show available docker versions:
+# yum list docker-ce --showduplicates
+
+FULL_DOCKER_VERSION=$(yum list docker-ce --showduplicates -q | awk '/docker-ce/ {print $2}' | sed 's/^[0-9]*://' | grep "^${DOCKER_VERSION}" | sort -rV | head -n 1)
+if [ -z "${FULL_DOCKER_VERSION}" ]; then
+	echo "package for the ${DOCKER_VERSION} docker version not found"
+	echo "Available packages:"
+	yum list docker-ce --showduplicates -q | awk '/docker-ce/ {print $2}'
+	exit 1
+fi
+
+sudo yum install -y docker-ce-${FULL_DOCKER_VERSION} containerd.io
+sudo systemctl enable docker
+sudo systemctl start docker
+`
@@ -0,0 +1,67 @@
+package templates
+
+// etcdctlInstall downloads etcdctl of the etcd version run by kubeadm,
+// masters have no etcdctl installed.
+const etcdctlInstall = `
+set -e
+
+ETCD_DIR=/opt/etcd/v{{ .EtcdVersion }}
+if [ ! -x $ETCD_DIR/etcdctl ]
+then
+	sudo mkdir -p $ETCD_DIR
+	curl -sSL {{ .Mirrors.Binaries }}/etcd/v{{ .EtcdVersion }}/etcd-v{{ .EtcdVersion }}-linux-amd64.tar.gz | \
+		sudo tar -xz -C $ETCD_DIR --strip-components=1
+fi
+
+ETCDCTL="sudo env ETCDCTL_API=3 $ETCD_DIR/etcdctl"
+`
+
+// etcdSnapshotTpl saves snapshot of etcd member running on the master.
+const etcdSnapshotTpl = etcdctlInstall + `
+sudo mkdir -p $(dirname {{ .SnapshotPath }})
+
+$ETCDCTL --endpoints=https://127.0.0.1:2379 \
+--cacert=/etc/kubernetes/pki/etcd/ca.crt \
+--cert=/etc/kubernetes/pki/etcd/healthcheck-client.crt \
+--key=/etc/kubernetes/pki/etcd/healthcheck-client.key \
+snapshot save {{ .SnapshotPath }}
+
+$ETCDCTL snapshot status {{ .SnapshotPath }} -w table
+`
+
+// etcdRestoreTpl replaces data of etcd member running on the master with
+// the snapshot, every master of the kube restores the same snapshot to
+// form a new etcd cluster.
+const etcdRestoreTpl = etcdctlInstall + `
+MANIFESTS=/etc/kubernetes/manifests
+STOPPED=/etc/kubernetes/manifests.restore
+
+sudo rm -rf /var/lib/etcd.restore
+$ETCDCTL snapshot restore {{ .SnapshotPath }} \
+--name {{ .Name }} \
+--initial-cluster {{ .InitialCluster }} \
+--initial-cluster-token {{ .Token }} \
+--initial-advertise-peer-urls https://{{ .PrivateIP }}:2380 \
+--data-dir /var/lib/etcd.restore
+
+# kubelet stops static pods once their manifests are removed
+sudo mkdir -p $STOPPED
+sudo mv $MANIFESTS/kube-apiserver.yaml $MANIFESTS/etcd.yaml $STOPPED/
+for i in $(seq 1 60)
+do
+	pgrep -x etcd > /dev/null || break
+	sleep 2
+done
+
+sudo rm -rf /var/lib/etcd.old
+sudo mv /var/lib/etcd /var/lib/etcd.old
+sudo mv /var/lib/etcd.restore /var/lib/etcd
+
+sudo sed -i \
+-e 's|--name=.*|--name={{ .Name }}|' \
+-e 's|--initial-cluster=.*|--initial-cluster={{ .InitialCluster }}|' \
+$STOPPED/etcd.yaml
+sudo mv $STOPPED/etcd.yaml $STOPPED/kube-apiserver.yaml $MANIFESTS/
+sudo rm -f {{ .SnapshotPath }}
+sudo systemctl restart kubelet
+`
@@ -0,0 +1,80 @@
+package templates
+
+const httpProxyTpl = `{{ if .HTTPProxy }}
+# shell sessions and sudo
+sudo sed -i '/_proxy=/Id' /etc/environment
+sudo tee -a /etc/environment > /dev/null << 'EOF'
+http_proxy={{ .HTTPProxy }}
+https_proxy={{ .HTTPProxy }}
+no_proxy={{ .NoProxy }}
+HTTP_PROXY={{ .HTTPProxy }}
+HTTPS_PROXY={{ .HTTPProxy }}
+NO_PROXY={{ .NoProxy }}
+EOF
+
+# downloads made in the current session
+for CURLRC in $HOME/.curlrc /root/.curlrc; do
+sudo tee ${CURLRC} > /dev/null << 'EOF'
+proxy = "{{ .HTTPProxy }}"
+noproxy = "{{ .NoProxy }}"
+EOF
+done
+sudo tee -a /etc/wgetrc > /dev/null << 'EOF'
+use_proxy = on
+http_proxy = {{ .HTTPProxy }}
+https_proxy = {{ .HTTPProxy }}
+no_proxy = {{ .NoProxy }}
+EOF
+
+# container runtime and kubelet
+sudo mkdir -p /etc/systemd/system.conf.d
+sudo tee /etc/systemd/system.conf.d/proxy.conf > /dev/null << 'EOF'
+[Manager]
+DefaultEnvironment="HTTP_PROXY={{ .HTTPProxy }}" "HTTPS_PROXY={{ .HTTPProxy }}" "NO_PROXY={{ .NoProxy }}"
+EOF
+sudo systemctl daemon-reexec
+
+sudo tee /etc/apt/apt.conf.d/95proxy > /dev/null << 'EOF'
+Acquire::http::Proxy "{{ .HTTPProxy }}";
+Acquire::https::Proxy "{{ .HTTPProxy }}";
+EOF
+{{ end }}`
+
+// httpProxyRedHatTpl differs from httpProxyTpl only by package manager settings
+const httpProxyRedHatTpl = `{{ if .HTTPProxy }}
+# shell sessions and sudo
+sudo sed -i '/_proxy=/Id' /etc/environment
+sudo tee -a /etc/environment > /dev/null << 'EOF'
+http_proxy={{ .HTTPProxy }}
+https_proxy={{ .HTTPProxy }}
+no_proxy={{ .NoProxy }}
+HTTP_PROXY={{ .HTTPProxy }}
+HTTPS_PROXY={{ .HTTPProxy }}
+NO_PROXY={{ .NoProxy }}
+EOF
+
+# downloads made in the current session
+for CURLRC in $HOME/.curlrc /root/.curlrc; do
+sudo tee ${CURLRC} > /dev/null << 'EOF'
+proxy = "{{ .HTTPProxy }}"
+noproxy = "{{ .NoProxy }}"
+EOF
+done
+sudo tee -a /etc/wgetrc > /dev/null << 'EOF'
+use_proxy = on
+http_proxy = {{ .HTTPProxy }}
+https_proxy = {{ .HTTPProxy }}
+no_proxy = {{ .NoProxy }}
+EOF
+
+# container runtime and kubelet
+sudo mkdir -p /etc/systemd/system.conf.d
+sudo tee /etc/systemd/system.conf.d/proxy.conf > /dev/null << 'EOF'
+[Manager]
+DefaultEnvironment="HTTP_PROXY={{ .HTTPProxy }}" "HTTPS_PROXY={{ .HTTPProxy }}" "NO_PROXY={{ .NoProxy }}"
+EOF
+sudo systemctl daemon-reexec
+
+sudo sed -i '/^proxy=/d' /etc/yum.conf
+echo "proxy={{ .HTTPProxy }}" | sudo tee -a /etc/yum.conf > /dev/null
+{{ end }}`
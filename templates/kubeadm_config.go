@@ -0,0 +1,132 @@
+package templates
+
+const kubeadmConfigTpl = `{{ if .IsMaster }}
+{{- if .IsBootstrap }}
+---
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: InitConfiguration
+localAPIEndpoint:
+  bindPort: {{ .APIServerPort }}
+nodeRegistration:
+  {{- if .CRISocket }}
+  criSocket: {{ .CRISocket }}
+  {{- end }}
+  kubeletExtraArgs:
+    node-ip: {{ .NodeIp }}
+    {{ if .Provider }}cloud-provider: {{ .Provider }}{{ end }}
+    {{ if .ProviderID }}provider-id: {{ .ProviderID }}{{ end }}
+certificateKey: {{ .CertificateKey }}
+---
+apiVersion: kubeadm.k8s.io/v1beta1
+kind: ClusterConfiguration
+kubernetesVersion: v{{ .K8SVersion }}
+clusterName: kubernetes
+{{- if .Mirrors.ImageRegistry }}
+imageRepository: {{ .Mirrors.ImageRegistry }}
+{{- end }}
+controlPlaneEndpoint: {{ .InternalDNSName }}:{{ .APIServerPort }}
+certificatesDir: /etc/kubernetes/pki
+apiServer:
+  certSANs:
+  - {{ .ExternalDNSName }}
+  - {{ .InternalDNSName }}
+  extraArgs:
+    authorization-mode: Node,RBAC
+    {{ if .Provider }}cloud-provider: {{ .Provider }}{{ end }}
+    kubelet-preferred-address-types: InternalIP,Hostname,ExternalIP
+  timeoutForControlPlane: 8m0s
+controllerManager:
+  extraArgs:
+    {{ if .Provider }}cloud-provider: {{ .Provider }}{{ end }}
+dns:
+  type: CoreDNS
+etcd:
+  local:
+    dataDir: /var/lib/etcd
+networking:
+  dnsDomain: cluster.local
+  podSubnet: {{ .CIDR }}
+  serviceSubnet: {{ .ServiceCIDR }}
+{{- else }}
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: JoinConfiguration
+nodeRegistration:
+  {{- if .CRISocket }}
+  criSocket: {{ .CRISocket }}
+  {{- end }}
+  kubeletExtraArgs:
+    node-ip: {{ .NodeIp }}
+    {{ if .Provider }}cloud-provider: {{ .Provider }}{{ end }}
+    {{ if .ProviderID }}provider-id: {{ .ProviderID }}{{ end }}
+discovery:
+  bootstrapToken:
+    token: {{ .Token }}
+    apiServerEndpoint: {{ .InternalDNSName }}:{{ .APIServerPort }}
+    caCertHashes: [{{ .CACertHash }}]
+controlPlane:
+  localAPIEndpoint:
+    bindPort: {{ .APIServerPort }}
+  certificateKey: {{ .CertificateKey }}
+---
+apiVersion: kubeadm.k8s.io/v1beta1
+kind: ClusterConfiguration
+kubernetesVersion: v{{ .K8SVersion }}
+clusterName: kubernetes
+{{- if .Mirrors.ImageRegistry }}
+imageRepository: {{ .Mirrors.ImageRegistry }}
+{{- end }}
+controlPlaneEndpoint: {{ .InternalDNSName }}:{{ .APIServerPort }}
+certificatesDir: /etc/kubernetes/pki
+apiServer:
+  certSANs:
+  - {{ .ExternalDNSName }}
+  - {{ .InternalDNSName }}
+  extraArgs:
+    authorization-mode: Node,RBAC
+    {{ if .Provider }}cloud-provider: {{ .Provider }}{{ end }}
+  timeoutForControlPlane: 8m0s
+controllerManager:
+  extraArgs:
+    {{ if .Provider }}cloud-provider: {{ .Provider }}{{ end }}
+dns:
+  type: CoreDNS
+etcd:
+  local:
+    dataDir: /var/lib/etcd
+networking:
+  dnsDomain: cluster.local
+  podSubnet: {{ .CIDR }}
+  serviceSubnet: {{ .ServiceCIDR }}
+{{- end }}
+{{- else }}
+apiVersion: kubeadm.k8s.io/v1beta1
+kind: JoinConfiguration
+nodeRegistration:
+  {{- if .CRISocket }}
+  criSocket: {{ .CRISocket }}
+  {{- end }}
+  kubeletExtraArgs:
+    node-ip: {{ .NodeIp }}
+    {{ if .Provider }}cloud-provider: {{ .Provider }}{{ end }}
+    {{ if .ProviderID }}provider-id: {{ .ProviderID }}{{ end }}
+    {{- if .NodeLabels }}
+    node-labels: "{{ .NodeLabels }}"
+    {{- end }}
+  {{- if .Taints }}
+  taints:
+  {{- range .Taints }}
+  - key: "{{ .Key }}"
+    {{- if .Value }}
+    value: "{{ .Value }}"
+    {{- end }}
+    effect: {{ .Effect }}
+  {{- end }}
+  {{- end }}
+discovery:
+  bootstrapToken:
+    token: {{ .Token }}
+    apiServerEndpoint: {{ .InternalDNSName }}:{{ .APIServerPort }}
+    caCertHashes:
+    - {{ .CACertHash }}
+{{- end }}
+`
@@ -0,0 +1,24 @@
+package templates
+
+const kubeadmResetTpl = `
+# {{ .Name }} is kept running, only kubernetes is removed from it
+if command -v kubeadm > /dev/null
+then
+	sudo kubeadm reset -f
+fi
+
+sudo systemctl stop kubelet || true
+sudo rm -rf /etc/kubernetes /var/lib/kubelet /var/lib/etcd /etc/cni/net.d /var/lib/cni $HOME/.kube
+
+# Only rules of kubernetes and network plugins are removed, rules of the
+# machine itself, like the ones that keep ssh open, stay
+if command -v iptables-save > /dev/null
+then
+	sudo iptables-save | grep -v -E 'KUBE-|CNI-|cali-|cali:|WEAVE|FLANNEL|flanneld' | sudo iptables-restore
+fi
+
+for IFACE in cni0 flannel.1 weave datapath vxlan.calico kube-ipvs0 $(ip -o link show | awk -F': ' '{print $2}' | grep -E '^(cali|vethwe)' | cut -d@ -f1)
+do
+	sudo ip link delete ${IFACE} 2> /dev/null || true
+done
+`
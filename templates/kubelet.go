@@ -29,12 +29,6 @@ sudo openssl req -new -key /etc/kubernetes/pki/kubelet.key -out /etc/kubernetes/
 sudo openssl x509 -req -in /etc/kubernetes/pki/kubelet.csr -CA /etc/kubernetes/pki/ca.crt -CAkey /etc/kubernetes/pki/ca.key -CAcreateserial -out /etc/kubernetes/pki/kubelet.crt -days 365 -extensions v3_req -extfile /etc/kubernetes/pki/openssl.cnf
 {{ else }}
 
-sudo bash -c "cat > /etc/kubernetes/pki/admin.crt <<EOF
-{{ .AdminCert }}EOF"
-
-sudo bash -c "cat > /etc/kubernetes/pki/admin.key <<EOF
-{{ .AdminKey }}EOF"
-
 sudo kubectl --kubeconfig=/home/{{ .UserName }}/.kube/config config set-cluster kubernetes --server='https://{{ .LoadBalancerHost }}:{{ .APIServerPort }}' --certificate-authority=/etc/kubernetes/pki/ca.crt --embed-certs=true
 sudo kubectl --kubeconfig=/home/{{ .UserName }}/.kube/config config set-credentials kubernetes --client-certificate=/etc/kubernetes/pki/admin.crt --client-key=/etc/kubernetes/pki/admin.key --embed-certs=true
 sudo kubectl --kubeconfig=/home/{{ .UserName }}/.kube/config config set-context kubernetes --cluster=kubernetes --user=kubernetes
@@ -0,0 +1,41 @@
+package templates
+
+// uploadCertsTpl runs on a master of the kube, certificates uploaded
+// by kubeadm are deleted in two hours, so every master that joins
+// later needs them uploaded again.
+const uploadCertsTpl = `
+set -e
+
+sudo kubeadm init phase upload-certs --upload-certs --certificate-key {{ .CertificateKey }}
+`
+
+// removeEtcdMemberTpl runs on a master that stays in the kube, etcd
+// member of the removed master is found by its address.
+const removeEtcdMemberTpl = `
+set -e
+
+KUBECTL="sudo kubectl --kubeconfig=/etc/kubernetes/admin.conf -n kube-system"
+ETCD_POD=$($KUBECTL get pods -l component=etcd -o wide --no-headers | grep Running | grep -v " {{ .PrivateIP }} " | head -n 1 | awk '{ print $1 }')
+
+if [ -z "$ETCD_POD" ]
+then
+	echo "no running etcd pod found"
+	exit 1
+fi
+
+ETCDCTL="$KUBECTL exec $ETCD_POD -- env ETCDCTL_API=3 etcdctl \
+--endpoints=https://127.0.0.1:2379 \
+--cacert=/etc/kubernetes/pki/etcd/ca.crt \
+--cert=/etc/kubernetes/pki/etcd/peer.crt \
+--key=/etc/kubernetes/pki/etcd/peer.key"
+
+MEMBER_ID=$($ETCDCTL member list | grep "https://{{ .PrivateIP }}:2380" | cut -d ',' -f 1)
+
+if [ -z "$MEMBER_ID" ]
+then
+	echo "etcd member {{ .PrivateIP }} not found"
+	exit 0
+fi
+
+$ETCDCTL member remove $MEMBER_ID
+`
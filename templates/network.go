@@ -115,7 +115,7 @@ spec:
       serviceAccountName: flannel
       initContainers:
       - name: install-cni
-        image: quay.io/coreos/flannel:v0.10.0-amd64
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-amd64" }}
         command:
         - cp
         args:
@@ -129,7 +129,7 @@ spec:
           mountPath: /etc/kube-flannel/
       containers:
       - name: kube-flannel
-        image: quay.io/coreos/flannel:v0.10.0-amd64
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-amd64" }}
         command:
         - /opt/bin/flanneld
         args:
@@ -193,7 +193,7 @@ spec:
       serviceAccountName: flannel
       initContainers:
       - name: install-cni
-        image: quay.io/coreos/flannel:v0.10.0-arm64
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-arm64" }}
         command:
         - cp
         args:
@@ -207,7 +207,7 @@ spec:
           mountPath: /etc/kube-flannel/
       containers:
       - name: kube-flannel
-        image: quay.io/coreos/flannel:v0.10.0-arm64
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-arm64" }}
         command:
         - /opt/bin/flanneld
         args:
@@ -271,7 +271,7 @@ spec:
       serviceAccountName: flannel
       initContainers:
       - name: install-cni
-        image: quay.io/coreos/flannel:v0.10.0-arm
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-arm" }}
         command:
         - cp
         args:
@@ -285,7 +285,7 @@ spec:
           mountPath: /etc/kube-flannel/
       containers:
       - name: kube-flannel
-        image: quay.io/coreos/flannel:v0.10.0-arm
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-arm" }}
         command:
         - /opt/bin/flanneld
         args:
@@ -349,7 +349,7 @@ spec:
       serviceAccountName: flannel
       initContainers:
       - name: install-cni
-        image: quay.io/coreos/flannel:v0.10.0-ppc64le
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-ppc64le" }}
         command:
         - cp
         args:
@@ -363,7 +363,7 @@ spec:
           mountPath: /etc/kube-flannel/
       containers:
       - name: kube-flannel
-        image: quay.io/coreos/flannel:v0.10.0-ppc64le
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-ppc64le" }}
         command:
         - /opt/bin/flanneld
         args:
@@ -427,7 +427,7 @@ spec:
       serviceAccountName: flannel
       initContainers:
       - name: install-cni
-        image: quay.io/coreos/flannel:v0.10.0-s390x
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-s390x" }}
         command:
         - cp
         args:
@@ -441,7 +441,7 @@ spec:
           mountPath: /etc/kube-flannel/
       containers:
       - name: kube-flannel
-        image: quay.io/coreos/flannel:v0.10.0-s390x
+        image: {{ .Mirrors.Image "quay.io/coreos/flannel:v0.10.0-s390x" }}
         command:
         - /opt/bin/flanneld
         args:
@@ -665,7 +665,7 @@ spec:
           operator: Exists
       serviceAccountName: calico-node
       containers:
-      - image: calico/typha:v3.3.2
+      - image: {{ .Mirrors.Image "calico/typha:v3.3.2" }}
         name: calico-typha
         ports:
         - containerPort: 5473
@@ -752,7 +752,7 @@ spec:
       terminationGracePeriodSeconds: 0
       containers:
         - name: calico-node
-          image: calico/node:v3.3.2
+          image: {{ .Mirrors.Image "calico/node:v3.3.2" }}
           env:
             - name: DATASTORE_TYPE
               value: "kubernetes"
@@ -829,7 +829,7 @@ spec:
               name: var-lib-calico
               readOnly: false
         - name: install-cni
-          image: calico/cni:v3.3.2
+          image: {{ .Mirrors.Image "calico/cni:v3.3.2" }}
           command: ["/install-cni.sh"]
           env:
             - name: CNI_CONF_NAME
@@ -1191,7 +1191,7 @@ items:
                     fieldRef:
                       apiVersion: v1
                       fieldPath: spec.nodeName
-              image: 'docker.io/weaveworks/weave-kube:2.5.1'
+              image: {{ .Mirrors.Image "docker.io/weaveworks/weave-kube:2.5.1" }}
               readinessProbe:
                 httpGet:
                   host: 127.0.0.1
@@ -1224,7 +1224,7 @@ items:
                     fieldRef:
                       apiVersion: v1
                       fieldPath: spec.nodeName
-              image: 'docker.io/weaveworks/weave-npc:2.5.1'
+              image: {{ .Mirrors.Image "docker.io/weaveworks/weave-npc:2.5.1" }}
               resources:
                 requests:
                   cpu: 10m
@@ -9,5 +9,13 @@ sudo /usr/bin/helm install stable/prometheus-operator \
     --set grafana.rbac.create={{ .RBACEnabled }} \
     --set kube-state-metrics.rbac.create={{ .RBACEnabled }} \
     --set prometheus-node-exporter.rbac.create={{ .RBACEnabled }} \
-    --set exporter-kubelets.https=true
+    --set exporter-kubelets.https=true{{ if .Mirrors.ImageRegistry }} \
+    --set prometheusOperator.image.repository={{ .Mirrors.Image "quay.io/coreos/prometheus-operator" }} \
+    --set prometheusOperator.configmapReloadImage.repository={{ .Mirrors.Image "quay.io/coreos/configmap-reload" }} \
+    --set prometheusOperator.prometheusConfigReloaderImage.repository={{ .Mirrors.Image "quay.io/coreos/prometheus-config-reloader" }} \
+    --set prometheus.prometheusSpec.image.repository={{ .Mirrors.Image "quay.io/prometheus/prometheus" }} \
+    --set alertmanager.alertmanagerSpec.image.repository={{ .Mirrors.Image "quay.io/prometheus/alertmanager" }} \
+    --set grafana.image.repository={{ .Mirrors.Image "grafana/grafana" }} \
+    --set kube-state-metrics.image.repository={{ .Mirrors.Image "k8s.gcr.io/kube-state-metrics" }} \
+    --set prometheus-node-exporter.image.repository={{ .Mirrors.Image "quay.io/prometheus/node-exporter" }}{{ end }}
 `
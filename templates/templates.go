@@ -4,21 +4,38 @@ var Default = map[string]string{
 	"add_authorized_keys":        addAuthorizedKeysTpl,
 	"bootstrap_token":            bootstrapTokenTpl,
 	"certificates":               certificatesTpl,
+	"certs_expiry":               certsExpiryTpl,
+	"certs_rotate":               certsRotateTpl,
 	"cloudcontroller":            cloudcontrollerTpl,
 	"clustercheck":               clustercheckTpl,
+	"cloudinit":                  cloudInitTpl,
 	"cni":                        cniTpl,
+	"containerd":                 containerdTpl,
+	"containerd_redhat":          containerdRedHatTpl,
+	"crio":                       crioTpl,
+	"crio_redhat":                crioRedHatTpl,
 	"dashboard":                  dashboardTpl,
 	"docker":                     dockerTpl,
+	"docker_redhat":              dockerRedHatTpl,
 	"download_kubernetes_binary": downloadKubernetesBinaryTpl,
 	"drain":                      drainTpl,
+	"etcd_restore":               etcdRestoreTpl,
+	"etcd_snapshot":              etcdSnapshotTpl,
+	"http_proxy":                 httpProxyTpl,
+	"http_proxy_redhat":          httpProxyRedHatTpl,
 	"kubeadm":                    kubeadmTpl,
+	"kubeadm_config":             kubeadmConfigTpl,
+	"kubeadm_reset":              kubeadmResetTpl,
 	"kubelet":                    kubelet,
 	"network":                    networkTpl,
 	"poststart":                  poststartTpl,
 	"prometheus":                 prometheusTpl,
+	"remove_etcd_member":         removeEtcdMemberTpl,
 	"storageclass":               storageclassTpl,
 	"tiller":                     tillerTpl,
+	"upload_certs":               uploadCertsTpl,
 	"upgrade":                    upgradeTpl,
+	"upgrade_redhat":             upgradeRedHatTpl,
 	"evacuate":                   evacuateTpl,
 	"uncordon":                   uncordonTpl,
 	"apply":                      applyTpl,
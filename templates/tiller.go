@@ -8,5 +8,7 @@ sudo kubectl create serviceaccount -n kube-system tiller
 sudo kubectl create clusterrolebinding tiller-binding --clusterrole=cluster-admin --serviceaccount kube-system:tiller
 {{ end }}
 
-sudo /usr/bin/helm init --automount-service-account-token --wait
+sudo /usr/bin/helm init --automount-service-account-token --wait \
+--stable-repo-url {{ .Mirrors.StableRepository }}{{ if .Mirrors.ImageRegistry }} \
+--tiller-image {{ .Mirrors.Image (printf "gcr.io/kubernetes-helm/tiller:v%s" .HelmVersion) }}{{ end }}
 `
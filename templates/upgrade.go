@@ -25,3 +25,22 @@ sudo apt-get update && sudo apt-get install -y kubelet={{ .K8SVersion }}-00 kube
 sudo apt-mark hold kubelet kubectl
 sudo systemctl restart kubelet
 `
+
+const upgradeRedHatTpl = `
+sudo yum install -y kubeadm-{{ .K8SVersion }} --disableexcludes=kubernetes
+
+{{ if .IsBootstrap }}
+	sudo kubeadm upgrade plan
+	sudo kubeadm upgrade apply -y v{{ .K8SVersion }}
+{{ else }}
+	{{ if .IsMaster }}
+		sudo kubeadm upgrade node experimental-control-plane
+	{{ else }}
+		sudo kubeadm upgrade node config --kubelet-version v{{ .K8SVersion }}
+	{{ end }}
+{{ end }}
+
+sudo yum install -y kubelet-{{ .K8SVersion }} kubectl-{{ .K8SVersion }} --disableexcludes=kubernetes
+sudo systemctl daemon-reload
+sudo systemctl restart kubelet
+`